@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+// defaultArchiveDir is kept next to the config file
+const defaultArchiveDir = "archive"
+
+var (
+	flagArchiveDir     string
+	flagArchiveRestore bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [CLIENT]",
+	Short: "Back up the .torrent files of the torrent client's queue",
+	Long: `This command exports the .torrent file and metadata (save path, label and tags) of torrents to an archive directory,
+so removed torrents can be re-added without re-downloading their metadata. Use --restore to re-add archived torrents.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("archive")
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		if flagArchiveRestore && len(hashes) == 0 {
+			log.Fatal("Restoring requires the torrents to be given with --hash or --hashes-file")
+		}
+
+		dir := flagArchiveDir
+		if dir == "" {
+			dir = config.StatePath(defaultArchiveDir)
+		}
+
+		// load client object
+		clientName := args[0]
+		c, _, _, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		if flagArchiveRestore {
+			var restored, failed int
+			for _, hash := range hashes {
+				if _, ok := torrents[hash]; ok {
+					log.Infof("Torrent already in client, skipping restore: %s", hash)
+					continue
+				}
+
+				meta, data, err := readArchivedTorrent(dir, hash)
+				if err != nil {
+					log.WithError(err).Errorf("Failed reading archived torrent: %s", hash)
+					failed++
+					continue
+				}
+
+				log.Info("-----")
+				log.Infof("Restoring torrent: %q to %q (label: %s)", meta.Name, meta.SavePath, meta.Label)
+				if flagDryRun {
+					log.Warn("Dry-run enabled, skipping restore...")
+					restored++
+					continue
+				}
+
+				if err := c.AddTorrent(ctx, data, client.AddTorrentOptions{
+					Label:    meta.Label,
+					Tags:     meta.Tags,
+					SavePath: meta.SavePath,
+				}); err != nil {
+					log.WithError(err).Errorf("Failed restoring torrent: %q", meta.Name)
+					failed++
+					continue
+				}
+
+				log.Info("Restored")
+				restored++
+			}
+
+			log.Info("-----")
+			log.Infof("Restored %d torrent(s), %d failed", restored, failed)
+			return
+		}
+
+		archiver, err := newTorrentArchiver(c, dir)
+		if err != nil {
+			log.WithError(err).Fatal("Failed initializing archive")
+		}
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		if flagDryRun {
+			log.Infof("[DRY-RUN] Would archive %d torrent(s) to %q", len(torrents), dir)
+			return
+		}
+
+		var archived, failed int
+		for _, t := range torrents {
+			if err := archiver.archive(ctx, t); err != nil {
+				log.WithError(err).Errorf("Failed archiving torrent: %q", t.Name)
+				failed++
+				continue
+			}
+			archived++
+		}
+
+		log.Infof("Archived %d torrent(s) to %q, %d failed", archived, dir, failed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+
+	archiveCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	archiveCmd.Flags().StringVar(&flagArchiveDir, "dir", "", "Archive directory (default: archive next to the config file)")
+	archiveCmd.Flags().BoolVar(&flagArchiveRestore, "restore", false, "Re-add the archived torrents given with --hash or --hashes-file")
+	archiveCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	archiveCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// archivedTorrent is the metadata stored next to an archived .torrent file, as required to re-add it
+type archivedTorrent struct {
+	Hash       string    `json:"hash"`
+	Name       string    `json:"name"`
+	SavePath   string    `json:"save_path"`
+	Label      string    `json:"label,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	Tracker    string    `json:"tracker,omitempty"`
+	Size       int64     `json:"size"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// torrentArchiver exports the .torrent file and metadata of torrents to dir, named after their hash
+type torrentArchiver struct {
+	c   client.ExportInterface
+	dir string
+}
+
+// newTorrentArchiver returns nil when dir is empty
+func newTorrentArchiver(c client.Interface, dir string) (*torrentArchiver, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	ce, ok := c.(client.ExportInterface)
+	if !ok {
+		return nil, client.Unsupported(c, "Archiving torrents")
+	}
+
+	return &torrentArchiver{c: ce, dir: dir}, nil
+}
+
+func (a *torrentArchiver) archive(ctx context.Context, t config.Torrent) error {
+	data, err := a.c.ExportTorrent(ctx, t.Hash)
+	if err != nil {
+		return err
+	}
+
+	meta, err := json.MarshalIndent(archivedTorrent{
+		Hash:       t.Hash,
+		Name:       t.Name,
+		SavePath:   t.Path,
+		Label:      t.Label,
+		Tags:       t.TagsSlice(),
+		Tracker:    t.TrackerName,
+		Size:       t.TotalBytes,
+		ArchivedAt: time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+
+	if err := os.MkdirAll(a.dir, 0700); err != nil {
+		return fmt.Errorf("create archive directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(a.dir, t.Hash+".torrent"), data, 0600); err != nil {
+		return fmt.Errorf("write torrent file: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(a.dir, t.Hash+".json"), meta, 0600); err != nil {
+		return fmt.Errorf("write metadata: %w", err)
+	}
+
+	return nil
+}
+
+// readArchivedTorrent returns the metadata and .torrent file archived for hash
+func readArchivedTorrent(dir string, hash string) (archivedTorrent, []byte, error) {
+	var meta archivedTorrent
+
+	raw, err := os.ReadFile(filepath.Join(dir, hash+".json"))
+	if err != nil {
+		return meta, nil, fmt.Errorf("read metadata: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return meta, nil, fmt.Errorf("unmarshal metadata: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, hash+".torrent"))
+	if err != nil {
+		return meta, nil, fmt.Errorf("read torrent file: %w", err)
+	}
+
+	return meta, data, nil
+}
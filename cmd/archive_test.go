@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+type fakeExporter struct {
+	client.Interface
+
+	torrents map[string][]byte
+}
+
+func (f *fakeExporter) ExportTorrent(_ context.Context, hash string) ([]byte, error) {
+	data, ok := f.torrents[hash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func TestTorrentArchiver(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+
+	archiver, err := newTorrentArchiver(&fakeExporter{torrents: map[string][]byte{"abc": []byte("d8:announce...e")}}, dir)
+	require.NoError(t, err)
+
+	torrent := config.Torrent{
+		Hash:        "abc",
+		Name:        "Some.Movie",
+		Path:        "/data/movies",
+		Label:       "movies",
+		Tags:        map[string]struct{}{"keep": {}},
+		TrackerName: "tracker.example",
+		TotalBytes:  1024,
+	}
+	require.NoError(t, archiver.archive(context.Background(), torrent))
+
+	meta, data, err := readArchivedTorrent(dir, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "d8:announce...e", string(data))
+	assert.Equal(t, "Some.Movie", meta.Name)
+	assert.Equal(t, "/data/movies", meta.SavePath)
+	assert.Equal(t, "movies", meta.Label)
+	assert.Equal(t, []string{"keep"}, meta.Tags)
+	assert.Equal(t, int64(1024), meta.Size)
+	assert.False(t, meta.ArchivedAt.IsZero())
+
+	// failed exports are reported, clean keeps the torrent
+	require.Error(t, archiver.archive(context.Background(), config.Torrent{Hash: "missing"}))
+	_, _, err = readArchivedTorrent(dir, "missing")
+	require.Error(t, err)
+}
+
+func TestNewTorrentArchiver(t *testing.T) {
+	archiver, err := newTorrentArchiver(nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, archiver)
+
+	// clients which cannot export torrents are rejected
+	_, err = newTorrentArchiver(&client.Deluge{}, "/archive")
+	require.Error(t, err)
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
 	"os"
 	"slices"
 	"strings"
@@ -13,13 +17,31 @@ import (
 	"github.com/autobrr/tqm/pkg/config"
 	"github.com/autobrr/tqm/pkg/evaluate"
 	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
 	"github.com/autobrr/tqm/pkg/logger"
-	"github.com/autobrr/tqm/pkg/notification"
 	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
 
+const (
+	// defaultFreeSpaceScore removes the torrents seeding the longest first
+	defaultFreeSpaceScore = "-SeedingDays"
+	// defaultFreeInodesScore removes the torrents with the most files first
+	defaultFreeInodesScore = "-len(Files)"
+	// bypassFiltersReason is the removal reason of torrents removed with --bypass-filters
+	bypassFiltersReason = "listed for removal"
+)
+
+var (
+	flagMaxRemovals      int
+	flagMaxRemovedBytes  string
+	flagFreeSpaceTarget  string
+	flagFreeInodesTarget int64
+	flagBypassFilters    bool
+)
+
 var cleanCmd = &cobra.Command{
 	Use:   "clean [CLIENT]",
 	Short: "Check torrent client for torrents to remove",
@@ -38,8 +60,28 @@ var cleanCmd = &cobra.Command{
 
 		// set log
 		log := logger.GetLogger("clean")
+		runOutcome.track()
+
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
 
-		noti := notification.NewDiscordSender(log, config.Config.Notifications)
+		// the filters are only bypassed for the listed torrents
+		var bypassed map[string]bool
+		if flagBypassFilters {
+			if len(hashes) == 0 {
+				log.Fatal("Bypassing the filters requires the torrents to be given with --hash or --hashes-file")
+			}
+
+			bypassed = make(map[string]bool, len(hashes))
+			for _, h := range hashes {
+				bypassed[h] = true
+			}
+		}
 
 		// retrieve client object
 		clientName := args[0]
@@ -49,21 +91,21 @@ var cleanCmd = &cobra.Command{
 		}
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
+		if err := tqm.ValidateClientEnabled(clientConfig); err != nil {
 			log.WithError(err).Fatal("Failed validating client is enabled")
 		}
 
 		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
+		clientType, err := tqm.ClientString("type", clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed determining client type")
 		}
 
 		// retrieve client free space path
-		clientFreeSpacePath, _ := getClientConfigString("free_space_path", clientConfig)
+		clientFreeSpacePath, _ := tqm.ClientString("free_space_path", clientConfig)
 
 		// retrieve client filters
-		clientFilter, err := getClientFilter(clientConfig)
+		clientFilter, err := tqm.ClientFilter(clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed retrieving client filter")
 		}
@@ -75,6 +117,14 @@ var cleanCmd = &cobra.Command{
 			}
 		}
 
+		caps, err := resolveRemovalCaps(clientFilter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading removal caps")
+		}
+		if caps.cooldowns, err = loadRemovalCooldowns(cooldownPath(clientName), clientFilter); err != nil {
+			log.WithError(err).Fatal("Failed loading cooldowns")
+		}
+
 		// compile client filters
 		exp, err := expression.Compile(clientFilter)
 		if err != nil {
@@ -97,31 +147,37 @@ var cleanCmd = &cobra.Command{
 		}
 
 		// get free disk space (can/will be used by filters)
-		switch *clientType {
-		case "qbittorrent":
-			// For qBittorrent, we can get free space without a path
+		switch {
+		case tqm.ReportsFreeSpace(c):
+			// For qBittorrent (or with a free_space_provider), we can get free space without a path
 			space, err := c.GetCurrentFreeSpace(ctx, "")
-			if err != nil {
+			if err != nil && caps.freeSpaceTarget > 0 {
+				// the target would never be reached without the free space, removing without a bound
+				log.WithError(err).Fatal("Failed retrieving free-space required by the free space target")
+			} else if err != nil {
 				log.WithError(err).Error("Failed retrieving free-space")
 			} else {
 				log.Infof("Retrieved free-space: %v (%.2f GB)",
-					humanize.IBytes(uint64(space)), c.GetFreeSpace())
+					formatting.Bytes(uint64(space)), c.GetFreeSpace())
 			}
 
-		case "deluge":
+		case *clientType == "deluge":
+			if clientFreeSpacePath == nil && caps.freeSpaceTarget > 0 {
+				log.Fatal("Deluge requires free_space_path to be configured in order to use a free space target")
+			}
 			if clientFreeSpacePath != nil {
 				space, err := c.GetCurrentFreeSpace(ctx, *clientFreeSpacePath)
 				if err != nil {
 					log.WithError(err).Errorf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
-					os.Exit(1)
+					os.Exit(exitFatal)
 				} else {
 					log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
-						humanize.IBytes(uint64(space)), c.GetFreeSpace())
+						formatting.Bytes(uint64(space)), c.GetFreeSpace())
 				}
 			} else {
 				if filterUsesFreeSpace(clientFilter) {
 					log.Error("Deluge requires free_space_path to be configured in order to retrieve free space information")
-					os.Exit(1)
+					os.Exit(exitFatal)
 				}
 			}
 		}
@@ -134,6 +190,14 @@ var cleanCmd = &cobra.Command{
 			log.Infof("Retrieved %d torrents", len(torrents))
 		}
 
+		// get free inodes (can/will be used by filters and the free inodes target)
+		if caps.freeInodes, err = loadFreeInodes(log, clientConfig, "clean", torrents); err != nil {
+			if caps.freeInodesTarget > 0 {
+				log.WithError(err).Fatal("Failed retrieving free inodes required by the free inodes target")
+			}
+			log.WithError(err).Debug("Failed retrieving free inodes")
+		}
+
 		// create map of files associated to torrents (via hash)
 		tfm := torrentfilemap.New(torrents)
 		log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
@@ -141,7 +205,7 @@ var cleanCmd = &cobra.Command{
 		var hfm hardlinkfilemap.HardlinkFileMapI
 		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "clean", true) {
 			// download path mapping
-			clientDownloadPathMapping, err := getClientDownloadPathMapping(clientConfig)
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "clean", tqm.PathMappingHardlinks)
 			if err != nil {
 				log.WithError(err).Fatal("Failed loading client download path mappings")
 			} else if clientDownloadPathMapping != nil {
@@ -152,7 +216,7 @@ var cleanCmd = &cobra.Command{
 			// create map of paths associated to underlying file ids
 			start := time.Now()
 			hfm = hardlinkfilemap.New(torrents, clientDownloadPathMapping)
-			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), time.Since(start))
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
 
 			// add HardlinkedOutsideClient field to torrents
 			for h, t := range torrents {
@@ -165,10 +229,104 @@ var cleanCmd = &cobra.Command{
 			hfm = hardlinkfilemap.NewNoopHardlinkFileMap()
 		}
 
+		// content extracted next to the archives of removed torrents
+		extractedPathMapping, err := tqm.DownloadPathMapping(clientConfig, "clean", tqm.PathMappingHardlinks)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+		extracted, err := newExtractedArchives(clientConfig, clientFilter, tfm, extractedPathMapping)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client orphan settings")
+		}
+		kept, err := newKeptFiles(clientFilter, tfm, extractedPathMapping)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading keep_files")
+		}
+
+		archiver, err := newCleanArchiver(c, clientFilter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed initializing archive")
+		}
+
+		tagger := newDryRunTagger(log, c, clientFilter)
+
+		// the library is summarized after the removals
+		library := maps.Clone(torrents)
+
+		// scope to the targeted torrents (the full list is still required to map cross-seeds and hardlinks)
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		// the dry-run tag is only updated on the evaluated torrents
+		evaluated := maps.Clone(torrents)
+
+		// exempt torrents are kept regardless of the filters (and untagged by the dry-run tag)
+		if err := exemptTorrents(log, exemptionsPath(), torrents, time.Now()); err != nil {
+			log.WithError(err).Fatal("Failed loading exemptions")
+		}
+
+		if flagSample > 0 {
+			if err := runCleanSample(ctx, log, c, torrents, tfm, hfm, clientFilter); err != nil {
+				log.WithError(err).Fatal("Failed evaluating torrent sample")
+			}
+			return
+		}
+
+		// look up the torrents of the tracker APIs supporting it in batches instead of one request per torrent
+		if !flagBypassFilters && exp.Uses("IsUnregistered") {
+			if found, requests := config.LookupUnregistered(ctx, torrents); requests > 0 {
+				log.Infof("Looked up %d torrents with %d bulk tracker API request(s)", found, requests)
+			}
+		}
+
+		if flagInteractive {
+			candidates, err := reviewCleanCandidates(ctx, c, torrents, tfm, hfm, clientFilter)
+			if err != nil {
+				log.WithError(err).Fatal("Failed evaluating removal candidates")
+			} else if len(candidates) == 0 {
+				log.Info("No torrents to remove")
+				return
+			}
+
+			approved, aborted, err := runReview(candidates)
+			if err != nil {
+				log.WithError(err).Fatal("Failed reviewing removal candidates")
+			} else if aborted {
+				log.Info("Review aborted, no torrents removed")
+				return
+			} else if len(approved) == 0 {
+				log.Info("No torrents approved for removal")
+				return
+			}
+
+			log.Infof("Approved %d of %d removal candidates", len(approved), len(candidates))
+			torrents, _ = scopeTorrentsToHashes(torrents, approved)
+		}
+
+		// persist the decisions so an interrupted run can be resumed
+		fingerprint, err := newJournalFingerprint(clientName, clientFilter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed fingerprinting clean run")
+		}
+
+		journal, err := openCleanJournal(log, cleanJournalPath(clientName), fingerprint, flagResume)
+		if err != nil {
+			log.WithError(err).Fatal("Failed opening clean journal")
+		}
+
 		// remove torrents that are not ignored and match remove criteria
-		if err := removeEligibleTorrents(ctx, log, c, torrents, tfm, hfm, clientFilter, noti, clientName, startTime); err != nil {
+		removed, err := removeEligibleTorrents(ctx, log, c, torrents, tfm, hfm, clientFilter, bypassed, caps, extracted,
+			kept, journal, archiver, tagger, noti, clientName, startTime)
+		if err != nil {
 			log.WithError(err).Fatal("Failed removing eligible torrents...")
 		}
+		journal.finish()
+
+		for _, h := range removed {
+			delete(library, h)
+			delete(evaluated, h)
+		}
+		tagger.apply(ctx, log, evaluated)
+		summarizeLibrary(log, noti, clientName, library, startTime)
 	},
 }
 
@@ -176,6 +334,166 @@ func init() {
 	rootCmd.AddCommand(cleanCmd)
 
 	cleanCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	cleanCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	cleanCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+	cleanCmd.Flags().IntVar(&flagSample, "sample", 0, "Only evaluate this many random torrents and print the predicted actions, without taking any action")
+	cleanCmd.Flags().BoolVar(&flagInteractive, "interactive", false, "Review the removal candidates in the terminal and only remove the approved ones")
+	cleanCmd.Flags().BoolVar(&flagBypassFilters, "bypass-filters", false, "Remove exactly the torrents given with --hash or --hashes-file without evaluating the ignore and remove filters")
+	cleanCmd.Flags().BoolVar(&flagResume, "resume", false, "Continue the interrupted clean run of the client, reusing the decisions it already took")
+	cleanCmd.MarkFlagsMutuallyExclusive("sample", "interactive", "bypass-filters")
+	cleanCmd.MarkFlagsMutuallyExclusive("sample", "interactive", "resume")
+	cleanCmd.Flags().IntVar(&flagMaxRemovals, "max-removals", 0, "Maximum number of torrents to remove in this run, overrides the filter's clean.max_removals")
+	cleanCmd.Flags().StringVar(&flagMaxRemovedBytes, "max-removed-bytes", "", "Maximum size of the torrents to remove in this run (e.g. 500GB), overrides the filter's clean.max_removed_bytes")
+	cleanCmd.Flags().StringVar(&flagReport, "report", "", "Write every decision (kept, ignored, removed and the matched expression) to this JSON file, also in dry-run")
+	cleanCmd.Flags().StringVar(&flagArchiveDir, "archive-dir", "", "Export the .torrent file and metadata of removed torrents to this directory first, overrides the filter's clean.archive_dir")
+	cleanCmd.Flags().StringVar(&flagFreeSpaceTarget, "free-space-target", "", "Only remove the lowest scoring torrents until the free space reaches this size (e.g. 500GiB), overrides the filter's clean.free_space_target")
+	cleanCmd.Flags().StringVar(&flagDryRunTag, "dry-run-tag", "", "Tag the torrents which would be removed in dry-run (and untag them again on the next run), overrides the filter's clean.dry_run_tag")
+	cleanCmd.Flags().Int64Var(&flagFreeInodesTarget, "free-inodes-target", 0, "Only remove the lowest scoring torrents until the free inodes of the filesystem reach this number, overrides the filter's clean.free_inodes_target")
+}
+
+// newCleanArchiver returns the archiver for the torrents removed by clean, nil when archiving is not configured
+func newCleanArchiver(c client.Interface, filter *config.FilterConfiguration) (*torrentArchiver, error) {
+	dir := filter.Clean.ArchiveDir
+	if flagArchiveDir != "" {
+		dir = flagArchiveDir
+	}
+
+	return newTorrentArchiver(c, dir)
+}
+
+// removalCaps limit the removals of a clean run, zero values are unlimited
+type removalCaps struct {
+	maxRemovals     int
+	maxRemovedBytes int64
+	// freeSpaceTarget stops the removals once the free space reaches it, torrents are then removed in score order
+	freeSpaceTarget int64
+	// freeInodesTarget stops the removals once the free inodes reach it, torrents are then removed in score order
+	freeInodesTarget int64
+	score            *expression.RankExpression
+	// cooldowns limit the removals of remove expressions across runs
+	cooldowns *removalCooldowns
+	// pacing interleaves the removals of the trackers and pauses them after each group
+	pacing *removalPacing
+	// freeInodes are the free inodes when the run started, required by the free inodes target
+	freeInodes int64
+}
+
+// resolveRemovalCaps returns the removal caps of filter, overridden by --max-removals, --max-removed-bytes,
+// --free-space-target and --free-inodes-target
+func resolveRemovalCaps(filter *config.FilterConfiguration) (removalCaps, error) {
+	var caps removalCaps
+
+	if filter.Clean.MaxRemovals != nil {
+		caps.maxRemovals = *filter.Clean.MaxRemovals
+	}
+	if flagMaxRemovals != 0 {
+		caps.maxRemovals = flagMaxRemovals
+	}
+	if caps.maxRemovals < 0 {
+		return caps, fmt.Errorf("invalid max removals: %d (must not be negative)", caps.maxRemovals)
+	}
+
+	maxRemovedBytes := filter.Clean.MaxRemovedBytes
+	if flagMaxRemovedBytes != "" {
+		maxRemovedBytes = flagMaxRemovedBytes
+	}
+	if maxRemovedBytes != "" {
+		b, err := humanize.ParseBytes(maxRemovedBytes)
+		if err != nil {
+			return caps, fmt.Errorf("invalid max removed bytes: %q: %w", maxRemovedBytes, err)
+		}
+		caps.maxRemovedBytes = int64(b)
+	}
+
+	var err error
+	if caps.pacing, err = newRemovalPacing(filter); err != nil {
+		return caps, err
+	}
+
+	caps.freeInodesTarget = filter.Clean.FreeInodesTarget
+	if flagFreeInodesTarget != 0 {
+		caps.freeInodesTarget = flagFreeInodesTarget
+	}
+	if caps.freeInodesTarget < 0 {
+		return caps, fmt.Errorf("invalid free inodes target: %d (must not be negative)", caps.freeInodesTarget)
+	}
+
+	freeSpaceTarget := filter.Clean.FreeSpaceTarget
+	if flagFreeSpaceTarget != "" {
+		freeSpaceTarget = flagFreeSpaceTarget
+	}
+	if freeSpaceTarget != "" {
+		b, err := humanize.ParseBytes(freeSpaceTarget)
+		if err != nil {
+			return caps, fmt.Errorf("invalid free space target: %q: %w", freeSpaceTarget, err)
+		}
+		caps.freeSpaceTarget = int64(b)
+	}
+
+	if caps.freeSpaceTarget == 0 && caps.freeInodesTarget == 0 {
+		return caps, nil
+	}
+
+	// when inodes are the constraint, the torrents with the most files free the most inodes
+	scoreText := filter.Clean.Score
+	switch {
+	case scoreText != "":
+	case caps.freeInodesTarget > 0:
+		scoreText = defaultFreeInodesScore
+	default:
+		scoreText = defaultFreeSpaceScore
+	}
+
+	caps.score, err = expression.CompileRank(scoreText)
+	if err != nil {
+		return caps, fmt.Errorf("compile score: %w", err)
+	}
+
+	return caps, nil
+}
+
+// allows reports whether a torrent of size bytes may be removed after removed torrents totalling removedBytes
+func (rc removalCaps) allows(removed int, removedBytes int64, size int64) bool {
+	if rc.maxRemovals > 0 && removed >= rc.maxRemovals {
+		return false
+	}
+	if rc.maxRemovedBytes > 0 && removedBytes+size > rc.maxRemovedBytes {
+		return false
+	}
+	return true
+}
+
+// reached reports whether a free space or free inodes target is set and all targets are reached with freeSpace bytes
+// and freeInodes inodes available
+func (rc removalCaps) reached(freeSpace int64, freeInodes int64) bool {
+	if rc.freeSpaceTarget == 0 && rc.freeInodesTarget == 0 {
+		return false
+	}
+
+	return freeSpace >= rc.freeSpaceTarget && freeInodes >= rc.freeInodesTarget
+}
+
+// order returns the hashes of torrents in the order they are considered for removal, lowest score first when a free
+// space or free inodes target is set, interleaving the trackers otherwise when a pacing is set
+func (rc removalCaps) order(ctx context.Context, torrents map[string]config.Torrent) ([]string, error) {
+	hashes := slices.Sorted(maps.Keys(torrents))
+	if rc.score == nil {
+		return rc.pacing.interleave(hashes, torrents), nil
+	}
+
+	scores := make(map[string]float64, len(torrents))
+	for h, t := range torrents {
+		score, err := rc.score.Rank(ctx, &t)
+		if err != nil {
+			return nil, fmt.Errorf("score torrent %q: %w", t.Name, err)
+		}
+		scores[h] = score
+	}
+
+	slices.SortStableFunc(hashes, func(a, b string) int {
+		return cmp.Compare(scores[a], scores[b])
+	})
+	return hashes, nil
 }
 
 // filterUsesFreeSpace checks if any filter conditions use FreeSpaceGB or FreeSpaceSet
@@ -192,6 +510,18 @@ func filterUsesFreeSpace(filter *config.FilterConfiguration) bool {
 	if slices.ContainsFunc(filter.Remove, checkExpression) {
 		return true
 	}
+	if slices.ContainsFunc(filter.Pause, checkExpression) {
+		return true
+	}
+	if slices.ContainsFunc(filter.Resume, checkExpression) {
+		return true
+	}
+	if slices.ContainsFunc(filter.Recheck, checkExpression) {
+		return true
+	}
+	if slices.ContainsFunc(filter.Reannounce, checkExpression) {
+		return true
+	}
 
 	// Check label expressions
 	for _, label := range filter.Label {
@@ -200,6 +530,13 @@ func filterUsesFreeSpace(filter *config.FilterConfiguration) bool {
 		}
 	}
 
+	// Check move expressions
+	for _, move := range filter.Move {
+		if slices.ContainsFunc(move.Update, checkExpression) {
+			return true
+		}
+	}
+
 	// Check tag expressions
 	for _, tag := range filter.Tag {
 		if slices.ContainsFunc(tag.Update, checkExpression) {
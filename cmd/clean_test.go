@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+func TestResolveRemovalCaps(t *testing.T) {
+	maxRemovals := 10
+	negative := -1
+
+	tests := []struct {
+		name      string
+		filter    config.FilterConfiguration
+		flagCount int
+		flagBytes string
+		flagSpace string
+		want      removalCaps
+		wantErr   bool
+	}{
+		{
+			name: "unlimited",
+		},
+		{
+			name:   "filter",
+			filter: config.FilterConfiguration{Clean: config.CleanConfig{MaxRemovals: &maxRemovals, MaxRemovedBytes: "1GiB"}},
+			want:   removalCaps{maxRemovals: 10, maxRemovedBytes: 1 << 30},
+		},
+		{
+			name:      "flags override filter",
+			filter:    config.FilterConfiguration{Clean: config.CleanConfig{MaxRemovals: &maxRemovals, MaxRemovedBytes: "1GiB"}},
+			flagCount: 3,
+			flagBytes: "500MB",
+			want:      removalCaps{maxRemovals: 3, maxRemovedBytes: 500_000_000},
+		},
+		{
+			name:      "invalid free space target",
+			flagSpace: "plenty",
+			wantErr:   true,
+		},
+		{
+			name:    "invalid score",
+			filter:  config.FilterConfiguration{Clean: config.CleanConfig{FreeSpaceTarget: "1TiB", Score: "Name"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative removals",
+			filter:  config.FilterConfiguration{Clean: config.CleanConfig{MaxRemovals: &negative}},
+			wantErr: true,
+		},
+		{
+			name:      "invalid bytes",
+			flagBytes: "lots",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagMaxRemovals, flagMaxRemovedBytes, flagFreeSpaceTarget = tt.flagCount, tt.flagBytes, tt.flagSpace
+			t.Cleanup(func() { flagMaxRemovals, flagMaxRemovedBytes, flagFreeSpaceTarget = 0, "", "" })
+
+			caps, err := resolveRemovalCaps(&tt.filter)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, caps)
+		})
+	}
+}
+
+func TestRemovalCapsAllows(t *testing.T) {
+	assert.True(t, removalCaps{}.allows(1000, 1<<40, 1<<30))
+
+	caps := removalCaps{maxRemovals: 2, maxRemovedBytes: 100}
+	assert.True(t, caps.allows(0, 0, 100))
+	assert.True(t, caps.allows(1, 40, 60))
+	assert.False(t, caps.allows(1, 50, 60))
+	assert.False(t, caps.allows(2, 0, 1))
+}
+
+func TestRemovalCapsFreeSpaceTarget(t *testing.T) {
+	filter := config.FilterConfiguration{Clean: config.CleanConfig{FreeSpaceTarget: "1KiB"}}
+	caps, err := resolveRemovalCaps(&filter)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1024, caps.freeSpaceTarget)
+	assert.Equal(t, defaultFreeSpaceScore, caps.score.Text)
+
+	assert.False(t, caps.reached(1023, 0))
+	assert.True(t, caps.reached(1024, 0))
+	assert.False(t, removalCaps{}.reached(1<<40, 1<<40))
+
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", SeedingDays: 10},
+		"b": {Hash: "b", SeedingDays: 100},
+		"c": {Hash: "c", SeedingDays: 1},
+	}
+
+	order, err := caps.order(context.Background(), torrents)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a", "c"}, order)
+
+	order, err = removalCaps{}.order(context.Background(), torrents)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+}
+
+func TestRemovalCapsFreeInodesTarget(t *testing.T) {
+	filter := config.FilterConfiguration{Clean: config.CleanConfig{FreeInodesTarget: 1000}}
+	caps, err := resolveRemovalCaps(&filter)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1000, caps.freeInodesTarget)
+	assert.Equal(t, defaultFreeInodesScore, caps.score.Text)
+
+	assert.False(t, caps.reached(1<<40, 999))
+	assert.True(t, caps.reached(0, 1000))
+
+	// both targets have to be reached
+	caps.freeSpaceTarget = 1024
+	assert.False(t, caps.reached(1023, 1000))
+	assert.False(t, caps.reached(1024, 999))
+	assert.True(t, caps.reached(1024, 1000))
+
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", Files: []string{"1", "2"}},
+		"b": {Hash: "b", Files: []string{"1"}},
+		"c": {Hash: "c", Files: []string{"1", "2", "3"}},
+	}
+
+	order, err := caps.order(context.Background(), torrents)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "a", "b"}, order)
+
+	_, err = resolveRemovalCaps(&config.FilterConfiguration{Clean: config.CleanConfig{FreeInodesTarget: -1}})
+	assert.Error(t, err)
+}
+
+// filterClient matches the ignore and remove filters by hash
+type filterClient struct {
+	client.Interface
+
+	ignored map[string]bool
+	removed map[string]bool
+}
+
+func (c *filterClient) GetFreeSpace() float64 {
+	return 0
+}
+
+func (c *filterClient) ShouldIgnore(_ context.Context, t *config.Torrent) (bool, string, error) {
+	return c.ignored[t.Hash], "ignored", nil
+}
+
+func (c *filterClient) ShouldRemoveWithReason(_ context.Context, t *config.Torrent) (bool, string, error) {
+	return c.removed[t.Hash], "removed", nil
+}
+
+// hardlinkedTorrents reports the files of the torrents by hash as hardlinked outside of the client
+type hardlinkedTorrents struct {
+	hardlinkfilemap.HardlinkFileMapI
+
+	hashes map[string]bool
+}
+
+func (h hardlinkedTorrents) IsTorrentUnique(t config.Torrent) bool { return !h.hashes[t.Hash] }
+func (h hardlinkedTorrents) NoInstances(t config.Torrent) bool     { return !h.hashes[t.Hash] }
+func (h hardlinkedTorrents) RemoveByTorrent(config.Torrent)        {}
+
+// silentSender drops the notifications of clean
+type silentSender struct {
+	notificationRecorder
+}
+
+func (s *silentSender) CanSend() bool { return false }
+
+func TestRemoveEligibleTorrents_Bypassed(t *testing.T) {
+	prevDryRun, prevConfig := flagDryRun, config.Config
+	t.Cleanup(func() {
+		flagDryRun, config.Config = prevDryRun, prevConfig
+		report = reportRecorder{}
+	})
+	flagDryRun = true
+	config.Config = &config.Configuration{}
+
+	torrent := func(hash string, files ...string) config.Torrent {
+		return config.Torrent{Hash: hash, Name: hash, Files: files, Downloaded: true,
+			RegistrationState: config.RegisteredState}
+	}
+
+	library := map[string]config.Torrent{
+		"ignored":     torrent("ignored", "/data/ignored.mkv"),
+		"listed":      torrent("listed", "/data/listed.mkv"),
+		"cross-seed":  torrent("cross-seed", "/data/shared.mkv"),
+		"seeding":     torrent("seeding", "/data/shared.mkv"),
+		"hardlinked":  torrent("hardlinked", "/data/hardlinked.mkv"),
+		"exempt":      torrent("exempt", "/data/exempt.mkv"),
+		"not-matched": torrent("not-matched", "/data/not-matched.mkv"),
+	}
+
+	// every torrent is ignored, except not-matched which does not match the remove filters either
+	c := &filterClient{
+		ignored: map[string]bool{"ignored": true, "listed": true, "cross-seed": true, "hardlinked": true,
+			"exempt": true},
+		removed: map[string]bool{},
+	}
+	bypassed := map[string]bool{"listed": true, "cross-seed": true, "hardlinked": true, "exempt": true}
+
+	exemptions := t.TempDir() + "/exemptions.json"
+	require.NoError(t, saveExemptions(exemptions, map[string]exemption{"exempt": {Hash: "exempt", Added: time.Now()}}))
+
+	// seeding is not evaluated, it only shares its files with cross-seed
+	torrents := maps.Clone(library)
+	delete(torrents, "seeding")
+
+	report.start()
+	log := logrus.NewEntry(logrus.New())
+	require.NoError(t, exemptTorrents(log, exemptions, torrents, time.Now()))
+
+	_, err := removeEligibleTorrents(context.Background(), log, c, torrents, torrentfilemap.New(library),
+		hardlinkedTorrents{hashes: map[string]bool{"hardlinked": true}}, nil, bypassed, removalCaps{}, nil, nil, nil,
+		nil, nil, &silentSender{}, "qbt", time.Now())
+	require.NoError(t, err)
+
+	decisions := make(map[string]reportDecision)
+	for _, d := range report.decisions {
+		decisions[d.Hash] = d
+	}
+
+	assert.Equal(t, reportRemoved, decisions["listed"].Decision)
+	assert.Equal(t, bypassFiltersReason, decisions["listed"].Expression)
+
+	// the filters are evaluated for the torrents not listed
+	assert.Equal(t, reportIgnored, decisions["ignored"].Decision)
+	assert.Equal(t, reportKept, decisions["not-matched"].Decision)
+
+	// the exemptions, cross-seed and hardlink safety checks apply to the listed torrents
+	assert.Equal(t, reportIgnored, decisions["exempt"].Decision)
+	assert.Equal(t, reportKept, decisions["cross-seed"].Decision)
+	assert.Equal(t, "not unique (file overlap)", decisions["cross-seed"].Detail)
+	assert.Equal(t, reportKept, decisions["hardlinked"].Decision)
+	assert.Equal(t, "not unique (hardlinked)", decisions["hardlinked"].Detail)
+}
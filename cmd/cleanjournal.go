@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// cleanJournalFile is the state file of the clean runs of a client, removed once a run completes
+const cleanJournalFile = "clean-resume.%s.jsonl"
+
+// cleanJournalMaxAge is the age above which the decisions of an interrupted run are no longer resumed, the torrents
+// and their tracker status have likely changed since
+const cleanJournalMaxAge = 24 * time.Hour
+
+// decisions of the torrents evaluated by clean
+const (
+	journalIgnore  = "ignore"
+	journalKeep    = "keep"
+	journalRemove  = "remove"
+	journalRemoved = "removed"
+)
+
+var flagResume bool
+
+// journalEntry is the decision of clean for a torrent
+type journalEntry struct {
+	Hash   string `json:"hash"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// journalFingerprint identifies the run a journal belongs to, it is the first line of the journal
+type journalFingerprint struct {
+	Client  string    `json:"client"`
+	Filter  string    `json:"filter"`
+	Started time.Time `json:"started"`
+}
+
+// newJournalFingerprint returns the fingerprint of a clean run of client with filter starting now
+func newJournalFingerprint(client string, filter *config.FilterConfiguration) (journalFingerprint, error) {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return journalFingerprint{}, fmt.Errorf("encode filter: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return journalFingerprint{Client: client, Filter: hex.EncodeToString(sum[:]), Started: time.Now()}, nil
+}
+
+// matches reports whether a journal with fingerprint o can be resumed by the run with fingerprint fp
+func (fp journalFingerprint) matches(o journalFingerprint) bool {
+	return o.Client == fp.Client && o.Filter == fp.Filter && fp.Started.Sub(o.Started) < cleanJournalMaxAge
+}
+
+// cleanJournal persists the decisions of a clean run, so an interrupted run can be resumed without evaluating the
+// torrents again, journal methods are no-ops on nil
+type cleanJournal struct {
+	log       *logrus.Entry
+	path      string
+	f         *os.File
+	decisions map[string]journalEntry
+}
+
+// cleanJournalPath returns the path of the journal of the clean runs of client
+func cleanJournalPath(client string) string {
+	return config.StatePath(fmt.Sprintf(cleanJournalFile, client))
+}
+
+// openCleanJournal returns the journal at path, loading the decisions of the interrupted run when resume is set. The
+// journal of a run of another client or filter, or older than cleanJournalMaxAge, is discarded. Decisions are not
+// persisted in dry-run.
+func openCleanJournal(log *logrus.Entry, path string, fp journalFingerprint, resume bool) (*cleanJournal, error) {
+	j := &cleanJournal{
+		log:       log,
+		path:      path,
+		decisions: make(map[string]journalEntry),
+	}
+
+	var (
+		resumed   bool
+		truncated bool
+	)
+	if resume {
+		var err error
+		if resumed, truncated, err = j.load(fp); err != nil {
+			return nil, err
+		}
+
+		if len(j.decisions) == 0 {
+			log.Info("No interrupted clean run to resume")
+		} else {
+			log.Infof("Resuming interrupted clean run, %d torrent(s) already evaluated", len(j.decisions))
+		}
+	}
+
+	if flagDryRun {
+		return j, nil
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resumed {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(j.path, flags, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open clean journal: %w", err)
+	}
+	j.f = f
+
+	switch {
+	case !resumed:
+		// a new journal starts with the fingerprint of the run
+		data, err := json.Marshal(fp)
+		if err != nil {
+			return nil, fmt.Errorf("encode clean journal fingerprint: %w", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			return nil, fmt.Errorf("write clean journal: %w", err)
+		}
+	case truncated:
+		// terminate the truncated line, the decisions of the resumed run would be lost otherwise
+		if _, err := f.WriteString("\n"); err != nil {
+			return nil, fmt.Errorf("write clean journal: %w", err)
+		}
+	}
+
+	return j, nil
+}
+
+// load reads the decisions of the journal, the last decision of a torrent wins. It reports whether the journal can be
+// resumed by the run with fingerprint fp, and whether its last line is truncated, which happens when the run was
+// interrupted while writing it.
+func (j *cleanJournal) load(fp journalFingerprint) (bool, bool, error) {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, fmt.Errorf("read clean journal: %w", err)
+	}
+
+	first, rest, _ := bytes.Cut(data, []byte("\n"))
+
+	var journalFP journalFingerprint
+	if err := json.Unmarshal(first, &journalFP); err != nil || !fp.matches(journalFP) {
+		j.log.Warnf("Discarding clean journal of another client, filter or a run older than %s: %q",
+			cleanJournalMaxAge, j.path)
+		return false, false, nil
+	}
+
+	for line := range bytes.Lines(rest) {
+		var e journalEntry
+		if err := json.Unmarshal(line, &e); err != nil || e.Hash == "" {
+			j.log.Debugf("Skipping invalid clean journal line: %q", line)
+			continue
+		}
+		j.decisions[e.Hash] = e
+	}
+
+	return true, len(data) > 0 && data[len(data)-1] != '\n', nil
+}
+
+// decision returns the decision of the interrupted run for the torrent with hash
+func (j *cleanJournal) decision(hash string) (journalEntry, bool) {
+	if j == nil {
+		return journalEntry{}, false
+	}
+
+	e, ok := j.decisions[hash]
+	return e, ok
+}
+
+// record persists the decision for the torrent with hash
+func (j *cleanJournal) record(hash string, action string, reason string) {
+	if j == nil || j.f == nil {
+		return
+	}
+
+	data, err := json.Marshal(journalEntry{Hash: hash, Action: action, Reason: reason})
+	if err != nil {
+		j.log.WithError(err).Warn("Failed encoding clean journal entry")
+		return
+	}
+
+	if _, err := j.f.Write(append(data, '\n')); err != nil {
+		j.log.WithError(err).Warnf("Failed writing clean journal: %q", j.path)
+	}
+}
+
+// finish removes the journal of the completed run
+func (j *cleanJournal) finish() {
+	if j == nil || j.f == nil {
+		return
+	}
+
+	if err := j.f.Close(); err != nil {
+		j.log.WithError(err).Warnf("Failed closing clean journal: %q", j.path)
+	}
+
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		j.log.WithError(err).Warnf("Failed removing clean journal: %q", j.path)
+	}
+}
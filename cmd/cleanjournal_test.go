@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+func TestCleanJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clean-resume.qbt.jsonl")
+	log := logger.GetLogger("test")
+	fp, err := newJournalFingerprint("qbt", &config.FilterConfiguration{})
+	require.NoError(t, err)
+
+	// interrupted run
+	journal, err := openCleanJournal(log, path, fp, false)
+	require.NoError(t, err)
+	journal.record("a", journalIgnore, "")
+	journal.record("b", journalKeep, "")
+	journal.record("c", journalRemove, "unregistered")
+	journal.record("d", journalRemove, "ratio")
+	journal.record("d", journalRemoved, "ratio")
+
+	// simulate a line truncated by the interruption
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"hash":"e","act`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	resumed, err := openCleanJournal(log, path, fp, true)
+	require.NoError(t, err)
+
+	for hash, want := range map[string]journalEntry{
+		"a": {Hash: "a", Action: journalIgnore},
+		"b": {Hash: "b", Action: journalKeep},
+		"c": {Hash: "c", Action: journalRemove, Reason: "unregistered"},
+		"d": {Hash: "d", Action: journalRemoved, Reason: "ratio"},
+	} {
+		got, ok := resumed.decision(hash)
+		assert.True(t, ok, hash)
+		assert.Equal(t, want, got)
+	}
+
+	_, ok := resumed.decision("e")
+	assert.False(t, ok)
+
+	// decisions of the resumed run are appended after the truncated line
+	resumed.record("f", journalKeep, "")
+	reloaded, err := openCleanJournal(log, path, fp, true)
+	require.NoError(t, err)
+	_, ok = reloaded.decision("f")
+	assert.True(t, ok)
+	require.NoError(t, reloaded.f.Close())
+
+	// the journal is removed once the run completes
+	resumed.finish()
+	assert.NoFileExists(t, path)
+
+	// a fresh run does not reuse the decisions
+	fresh, err := openCleanJournal(log, path, fp, false)
+	require.NoError(t, err)
+	_, ok = fresh.decision("a")
+	assert.False(t, ok)
+	fresh.finish()
+
+	// disabled
+	var disabled *cleanJournal
+	disabled.record("a", journalKeep, "")
+	_, ok = disabled.decision("a")
+	assert.False(t, ok)
+	disabled.finish()
+}
+
+func TestCleanJournal_Fingerprint(t *testing.T) {
+	log := logger.GetLogger("test")
+
+	fp, err := newJournalFingerprint("qbt", &config.FilterConfiguration{Remove: []string{"Ratio > 2"}})
+	require.NoError(t, err)
+
+	changedFilter, err := newJournalFingerprint("qbt", &config.FilterConfiguration{Remove: []string{"Ratio > 3"}})
+	require.NoError(t, err)
+	otherClient, err := newJournalFingerprint("deluge", &config.FilterConfiguration{Remove: []string{"Ratio > 2"}})
+	require.NoError(t, err)
+	later := fp
+	later.Started = fp.Started.Add(cleanJournalMaxAge)
+
+	tests := []struct {
+		name     string
+		fp       journalFingerprint
+		expected bool
+	}{
+		{name: "same_run", fp: fp, expected: true},
+		{name: "changed_filter", fp: changedFilter},
+		{name: "other_client", fp: otherClient},
+		{name: "too_old", fp: later},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "clean-resume.qbt.jsonl")
+
+			journal, err := openCleanJournal(log, path, fp, false)
+			require.NoError(t, err)
+			journal.record("a", journalRemove, "ratio")
+			require.NoError(t, journal.f.Close())
+
+			resumed, err := openCleanJournal(log, path, tt.fp, true)
+			require.NoError(t, err)
+			_, ok := resumed.decision("a")
+			assert.Equal(t, tt.expected, ok)
+
+			// a discarded journal is replaced by the journal of the new run
+			resumed.record("b", journalKeep, "")
+			require.NoError(t, resumed.f.Close())
+			reloaded, err := openCleanJournal(log, path, tt.fp, true)
+			require.NoError(t, err)
+			_, ok = reloaded.decision("a")
+			assert.Equal(t, tt.expected, ok)
+			_, ok = reloaded.decision("b")
+			assert.True(t, ok)
+			reloaded.finish()
+		})
+	}
+
+	// journals written before fingerprints were recorded are discarded
+	path := filepath.Join(t.TempDir(), "clean-resume.qbt.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"hash":"a","action":"remove","reason":"ratio"}`+"\n"), 0600))
+	resumed, err := openCleanJournal(log, path, fp, true)
+	require.NoError(t, err)
+	_, ok := resumed.decision("a")
+	assert.False(t, ok)
+	resumed.finish()
+}
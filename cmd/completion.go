@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// registerCompletions completes the client name argument and the --filter and --client flags of cmd and its
+// subcommands with the names configured in the config file
+func registerCompletions(cmd *cobra.Command) {
+	if strings.Contains(cmd.Use, "[CLIENT]") && cmd.ValidArgsFunction == nil {
+		cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeConfigNames("clients", toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+
+	if cmd.Flags().Lookup("filter") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("filter", func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			names := completeConfigNames("filters", toComplete)
+			for _, name := range config.BuiltinFilterNames() {
+				if builtin := config.BuiltinFilterPrefix + name; strings.HasPrefix(builtin, toComplete) {
+					names = append(names, builtin)
+				}
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+
+	if cmd.Flags().Lookup("client") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("client", func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completeConfigNames("clients", toComplete), cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub)
+	}
+}
+
+// completeConfigNames returns the names of a config section starting with toComplete, the config is not initialized
+// to keep completion silent
+func completeConfigNames(section string, toComplete string) []string {
+	path := resolveConfigFile()
+	if config.IsRemote(path) {
+		path = config.RemoteCachePath(flagConfigFolder, flagConfigFile)
+	}
+
+	names, err := config.SectionKeys(path, section)
+	if err != nil {
+		return nil
+	}
+
+	return slices.DeleteFunc(names, func(name string) bool {
+		return !strings.HasPrefix(name, toComplete)
+	})
+}
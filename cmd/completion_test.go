@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteConfigNames(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"),
+		[]byte("clients:\n  qbt:\n    enabled: true\n  qbt-4k:\n    enabled: true\n  deluge:\n    enabled: true\nfilters:\n  default: {}\n"), 0600))
+
+	prevFolder, prevFile := flagConfigFolder, flagConfigFile
+	t.Cleanup(func() { flagConfigFolder, flagConfigFile = prevFolder, prevFile })
+	flagConfigFolder, flagConfigFile = dir, "config.yaml"
+
+	assert.Equal(t, []string{"deluge", "qbt", "qbt-4k"}, completeConfigNames("clients", ""))
+	assert.Equal(t, []string{"qbt", "qbt-4k"}, completeConfigNames("clients", "qb"))
+	assert.Equal(t, []string{"default"}, completeConfigNames("filters", ""))
+
+	flagConfigFile = "missing.yaml"
+	assert.Empty(t, completeConfigNames("clients", ""))
+}
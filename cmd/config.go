@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate legacy configuration keys to the current schema",
+	Long: `This command rewrites legacy keys and filter shapes of the configuration file to the current schema.
+A backup of the original configuration is written next to it, use --dry-run to only show what would change.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// the configuration is not loaded, it may not be valid before migrating
+		initLogging()
+
+		// set log
+		log := logger.GetLogger("config")
+
+		if config.IsRemote(flagConfigFile) {
+			log.Fatalf("Migrating a config fetched from a url is not supported, migrate it at its source: %q", flagConfigFile)
+		}
+
+		data, err := os.ReadFile(flagConfigFile)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed reading config: %q", flagConfigFile)
+		}
+
+		migrated, changes, err := config.Migrate(data)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed migrating config: %q", flagConfigFile)
+		}
+
+		if len(changes) == 0 {
+			log.Infof("Config is up to date: %q", flagConfigFile)
+			return
+		}
+
+		for _, change := range changes {
+			if flagDryRun {
+				log.Infof("[DRY-RUN] Would have %s", change)
+			} else {
+				log.Infof("Config change: %s", change)
+			}
+		}
+
+		if flagDryRun {
+			return
+		}
+
+		info, err := os.Stat(flagConfigFile)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed retrieving config file info: %q", flagConfigFile)
+		}
+
+		backupFile := fmt.Sprintf("%s.%s.bak", flagConfigFile, time.Now().Format("20060102-150405"))
+		if err := os.WriteFile(backupFile, data, info.Mode().Perm()); err != nil {
+			log.WithError(err).Fatalf("Failed writing config backup: %q", backupFile)
+		}
+		log.Infof("Backed up config to %q", backupFile)
+
+		if err := os.WriteFile(flagConfigFile, migrated, info.Mode().Perm()); err != nil {
+			log.WithError(err).Fatalf("Failed writing migrated config: %q", flagConfigFile)
+		}
+
+		log.Infof("Migrated config %q (%d change(s))", flagConfigFile, len(changes))
+	},
+}
+
+var (
+	flagConfigInitClientType   string
+	flagConfigInitClientName   string
+	flagConfigInitURL          string
+	flagConfigInitHost         string
+	flagConfigInitPort         int
+	flagConfigInitUser         string
+	flagConfigInitPassword     string
+	flagConfigInitDownloadPath string
+	flagConfigInitForce        bool
+)
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate a starter configuration file",
+	Long: `This command writes a starter configuration with a single client, a default filter and commented examples of
+trackers and notifications. When run in a terminal, the client settings not given as flags are asked for.`,
+	Example: `  tqm config init
+  tqm config init --client-type qbittorrent --url http://localhost:8080 --user admin --password secret --download-path /data/torrents`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// the log file is written to the config folder, which may not exist yet
+		if err := os.MkdirAll(flagConfigFolder, 0700); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed creating config folder %q: %v\n", flagConfigFolder, err)
+			os.Exit(exitFatal)
+		}
+
+		// the configuration is not loaded, it does not exist yet
+		initLogging()
+
+		// set log
+		log := logger.GetLogger("config")
+
+		if config.IsRemote(flagConfigFile) {
+			log.Fatalf("Generating a config fetched from a url is not supported: %q", flagConfigFile)
+		}
+
+		if _, err := os.Stat(flagConfigFile); err == nil && !flagConfigInitForce && !flagDryRun {
+			log.Fatalf("Config already exists, use --force to overwrite it: %q", flagConfigFile)
+		}
+
+		opts := config.ScaffoldOptions{
+			ClientName:   flagConfigInitClientName,
+			ClientType:   flagConfigInitClientType,
+			URL:          flagConfigInitURL,
+			Host:         flagConfigInitHost,
+			Port:         flagConfigInitPort,
+			User:         flagConfigInitUser,
+			Password:     flagConfigInitPassword,
+			DownloadPath: flagConfigInitDownloadPath,
+		}
+
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			if err := promptScaffoldOptions(cmd, os.Stdin, os.Stdout, &opts); err != nil {
+				log.WithError(err).Fatal("Failed reading config settings")
+			}
+		}
+
+		opts.ClientType = strings.ToLower(opts.ClientType)
+		if opts.ClientName == "" {
+			opts.ClientName = defaultScaffoldClientName(opts.ClientType)
+		}
+
+		data, err := config.Scaffold(opts)
+		if err != nil {
+			log.WithError(err).Fatal("Failed generating config")
+		}
+
+		if flagDryRun {
+			fmt.Print(string(data))
+			return
+		}
+
+		if err := os.MkdirAll(filepath.Dir(flagConfigFile), 0700); err != nil {
+			log.WithError(err).Fatalf("Failed creating config directory: %q", filepath.Dir(flagConfigFile))
+		}
+
+		if err := os.WriteFile(flagConfigFile, data, 0600); err != nil {
+			log.WithError(err).Fatalf("Failed writing config: %q", flagConfigFile)
+		}
+
+		log.Infof("Generated config %q, review the default filter before running clean without --dry-run", flagConfigFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configMigrateCmd, configInitCmd)
+
+	configInitCmd.Flags().StringVar(&flagConfigInitClientType, "client-type", config.ClientTypeQBittorrent,
+		fmt.Sprintf("Client type (%s, %s)", config.ClientTypeQBittorrent, config.ClientTypeDeluge))
+	configInitCmd.Flags().StringVar(&flagConfigInitClientName, "client-name", "", "Client name used on the command line (default: qbt or deluge)")
+	configInitCmd.Flags().StringVar(&flagConfigInitURL, "url", "http://localhost:8080", "qBittorrent WebUI url")
+	configInitCmd.Flags().StringVar(&flagConfigInitHost, "host", "localhost", "Deluge daemon host")
+	configInitCmd.Flags().IntVar(&flagConfigInitPort, "port", 58846, "Deluge daemon port")
+	configInitCmd.Flags().StringVar(&flagConfigInitUser, "user", "", "Client user")
+	configInitCmd.Flags().StringVar(&flagConfigInitPassword, "password", "", "Client password")
+	configInitCmd.Flags().StringVar(&flagConfigInitDownloadPath, "download-path", "/downloads", "Client download path, as seen by tqm")
+	configInitCmd.Flags().BoolVar(&flagConfigInitForce, "force", false, "Overwrite an existing config")
+}
+
+// promptScaffoldOptions asks for the settings of opts which were not given as flags, keeping the current value on an empty answer
+func promptScaffoldOptions(cmd *cobra.Command, in io.Reader, out io.Writer, opts *config.ScaffoldOptions) error {
+	r := bufio.NewReader(in)
+	changed := cmd.Flags().Changed
+
+	ask := func(flag string, label string, value *string) error {
+		if changed(flag) {
+			return nil
+		}
+		answer, err := promptValue(r, out, label, *value)
+		if err != nil {
+			return err
+		}
+		*value = answer
+		return nil
+	}
+
+	if err := ask("client-type", fmt.Sprintf("Client type (%s, %s)", config.ClientTypeQBittorrent, config.ClientTypeDeluge),
+		&opts.ClientType); err != nil {
+		return err
+	}
+	opts.ClientType = strings.ToLower(opts.ClientType)
+
+	if opts.ClientName == "" {
+		opts.ClientName = defaultScaffoldClientName(opts.ClientType)
+	}
+	if err := ask("client-name", "Client name", &opts.ClientName); err != nil {
+		return err
+	}
+
+	if opts.ClientType == config.ClientTypeDeluge {
+		if err := ask("host", "Deluge host", &opts.Host); err != nil {
+			return err
+		}
+
+		port := strconv.Itoa(opts.Port)
+		if err := ask("port", "Deluge port", &port); err != nil {
+			return err
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return fmt.Errorf("invalid port: %q", port)
+		}
+		opts.Port = p
+	} else if err := ask("url", "qBittorrent url", &opts.URL); err != nil {
+		return err
+	}
+
+	if err := ask("user", "User", &opts.User); err != nil {
+		return err
+	}
+	// the password is not echoed when prompted on a terminal
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) && !changed("password") {
+		password, err := promptPassword(int(f.Fd()), out, "Password", opts.Password)
+		if err != nil {
+			return err
+		}
+		opts.Password = password
+	} else if err := ask("password", "Password", &opts.Password); err != nil {
+		return err
+	}
+
+	return ask("download-path", "Download path", &opts.DownloadPath)
+}
+
+// promptValue reads a line from r after printing label, def is returned for an empty line
+func promptValue(r *bufio.Reader, out io.Writer, label string, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", label)
+	}
+
+	answer, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// promptPassword reads a password from the terminal fd without echoing it after printing label, def is returned for an
+// empty answer
+func promptPassword(fd int, out io.Writer, label string, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [unchanged]: ", label)
+	} else {
+		fmt.Fprintf(out, "%s: ", label)
+	}
+
+	answer, err := term.ReadPassword(fd)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	if len(answer) == 0 {
+		return def, nil
+	}
+	return string(answer), nil
+}
+
+// defaultScaffoldClientName returns the client name used in the examples of the README
+func defaultScaffoldClientName(clientType string) string {
+	if clientType == config.ClientTypeQBittorrent {
+		return "qbt"
+	}
+	return clientType
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// cooldownStateFile records the removals of the remove expressions with a cooldown of a client
+const cooldownStateFile = "cooldown.%s.json"
+
+// defaultCooldownWindow is the window of cooldowns without one
+const defaultCooldownWindow = 24 * time.Hour
+
+// cooldownRule is the cooldown of a remove expression, zero values are unlimited
+type cooldownRule struct {
+	maxRemovals     int
+	maxRemovedBytes int64
+	window          time.Duration
+}
+
+// cooldownRemoval is a removal counted by the cooldown of its remove expression
+type cooldownRemoval struct {
+	Time  time.Time `json:"time"`
+	Bytes int64     `json:"bytes"`
+}
+
+// removalCooldowns limit the removals of remove expressions over a rolling window. The removals are persisted after
+// each removal (not in dry-run), methods are no-ops on nil.
+type removalCooldowns struct {
+	path     string
+	rules    map[string]cooldownRule
+	removals map[string][]cooldownRemoval
+}
+
+// cooldownPath returns the path of the cooldown state of client
+func cooldownPath(client string) string {
+	return config.StatePath(fmt.Sprintf(cooldownStateFile, client))
+}
+
+// loadRemovalCooldowns returns the cooldowns of filter with the removals recorded at path, nil when filter has no
+// cooldowns
+func loadRemovalCooldowns(path string, filter *config.FilterConfiguration) (*removalCooldowns, error) {
+	if len(filter.Clean.Cooldowns) == 0 {
+		return nil, nil
+	}
+
+	policy, err := expression.PolicyExpression(filter.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+
+	rc := &removalCooldowns{
+		path:     path,
+		rules:    make(map[string]cooldownRule),
+		removals: make(map[string][]cooldownRemoval),
+	}
+
+	for _, c := range filter.Clean.Cooldowns {
+		remove := c.Remove
+		switch {
+		case remove == "policy" && policy != "":
+			remove = policy
+		case !slices.Contains(filter.Remove, remove):
+			return nil, fmt.Errorf("cooldown of unknown remove expression: %q", c.Remove)
+		}
+
+		rule := cooldownRule{maxRemovals: c.MaxRemovals, window: c.Window}
+		if rule.maxRemovals < 0 {
+			return nil, fmt.Errorf("invalid max removals of cooldown %q: %d (must not be negative)", c.Remove,
+				rule.maxRemovals)
+		}
+		if rule.window < 0 {
+			return nil, fmt.Errorf("invalid window of cooldown %q: %s (must not be negative)", c.Remove, rule.window)
+		} else if rule.window == 0 {
+			rule.window = defaultCooldownWindow
+		}
+		if c.MaxRemovedBytes != "" {
+			b, err := humanize.ParseBytes(c.MaxRemovedBytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max removed bytes of cooldown %q: %q: %w", c.Remove, c.MaxRemovedBytes,
+					err)
+			}
+			rule.maxRemovedBytes = int64(b)
+		}
+
+		// the first cooldown of an expression wins, e.g. the one of a filter over the one of the filter it extends
+		if _, ok := rc.rules[remove]; !ok {
+			rc.rules[remove] = rule
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rc, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read cooldown state: %w", err)
+	}
+
+	var removals map[string][]cooldownRemoval
+	if err := json.Unmarshal(data, &removals); err != nil {
+		return nil, fmt.Errorf("decode cooldown state: %w", err)
+	}
+
+	// the removals of expressions without a cooldown are dropped
+	for remove, r := range removals {
+		if _, ok := rc.rules[remove]; ok {
+			rc.removals[remove] = r
+		}
+	}
+
+	return rc, nil
+}
+
+// allows reports whether the cooldown of the remove expression reason allows removing a torrent of size bytes at now
+func (rc *removalCooldowns) allows(reason string, size int64, now time.Time) bool {
+	if rc == nil {
+		return true
+	}
+
+	rule, ok := rc.rules[reason]
+	if !ok {
+		return true
+	}
+
+	var (
+		removed      int
+		removedBytes int64
+	)
+	for _, r := range rc.removals[reason] {
+		if now.Sub(r.Time) < rule.window {
+			removed++
+			removedBytes += r.Bytes
+		}
+	}
+
+	if rule.maxRemovals > 0 && removed >= rule.maxRemovals {
+		return false
+	}
+	if rule.maxRemovedBytes > 0 && removedBytes+size > rule.maxRemovedBytes {
+		return false
+	}
+	return true
+}
+
+// record counts the removal of a torrent of size bytes by the remove expression reason at now, the removals outside
+// of the window of their cooldown are dropped
+func (rc *removalCooldowns) record(reason string, size int64, now time.Time) error {
+	if rc == nil {
+		return nil
+	}
+
+	rule, ok := rc.rules[reason]
+	if !ok {
+		return nil
+	}
+
+	rc.removals[reason] = append(slices.DeleteFunc(rc.removals[reason], func(r cooldownRemoval) bool {
+		return now.Sub(r.Time) >= rule.window
+	}), cooldownRemoval{Time: now, Bytes: size})
+
+	// dry-runs count their removals without persisting them
+	if flagDryRun {
+		return nil
+	}
+
+	data, err := json.Marshal(rc.removals)
+	if err != nil {
+		return fmt.Errorf("encode cooldown state: %w", err)
+	}
+
+	if err := paths.WriteFileAtomic(rc.path, data); err != nil {
+		return fmt.Errorf("write cooldown state: %w", err)
+	}
+	return nil
+}
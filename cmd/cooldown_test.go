@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestRemovalCooldowns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cooldown.qbt.json")
+	threshold := 1.0
+	filter := &config.FilterConfiguration{
+		Remove: []string{"IsUnregistered()", "Ratio > 2"},
+		Policy: config.PolicyConfig{
+			KeepThreshold: &threshold,
+			Criteria:      []config.PolicyCriterion{{Criterion: "ratio", Weight: 2, Max: 1}},
+		},
+		Clean: config.CleanConfig{Cooldowns: []config.CooldownConfig{
+			{Remove: "IsUnregistered()", MaxRemovals: 2},
+			{Remove: "policy", MaxRemovedBytes: "10GiB", Window: 48 * time.Hour},
+		}},
+	}
+	policy := "2 * min(Ratio / 1, 1) < 1"
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	rc, err := loadRemovalCooldowns(path, filter)
+	require.NoError(t, err)
+
+	// expressions without a cooldown are unlimited
+	assert.True(t, rc.allows("Ratio > 2", 100<<30, now))
+
+	require.NoError(t, rc.record("IsUnregistered()", 1<<30, now.Add(-25*time.Hour)))
+	require.NoError(t, rc.record("IsUnregistered()", 1<<30, now.Add(-time.Hour)))
+	assert.True(t, rc.allows("IsUnregistered()", 1<<30, now), "removal outside of the window counted")
+	require.NoError(t, rc.record("IsUnregistered()", 1<<30, now))
+	assert.False(t, rc.allows("IsUnregistered()", 1<<30, now))
+
+	require.NoError(t, rc.record(policy, 6<<30, now.Add(-30*time.Hour)))
+	assert.True(t, rc.allows(policy, 4<<30, now))
+	assert.False(t, rc.allows(policy, 5<<30, now))
+
+	// the removals are persisted across runs
+	rc, err = loadRemovalCooldowns(path, filter)
+	require.NoError(t, err)
+	assert.False(t, rc.allows("IsUnregistered()", 1<<30, now))
+	assert.True(t, rc.allows("IsUnregistered()", 1<<30, now.Add(23*time.Hour)))
+	assert.False(t, rc.allows(policy, 5<<30, now))
+
+	var nilCooldowns *removalCooldowns
+	assert.True(t, nilCooldowns.allows("IsUnregistered()", 1<<30, now))
+	assert.NoError(t, nilCooldowns.record("IsUnregistered()", 1<<30, now))
+}
+
+func TestLoadRemovalCooldowns_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cooldown.qbt.json")
+
+	tests := []struct {
+		name     string
+		cooldown config.CooldownConfig
+	}{
+		{name: "unknown expression", cooldown: config.CooldownConfig{Remove: "Ratio > 3", MaxRemovals: 1}},
+		{name: "policy disabled", cooldown: config.CooldownConfig{Remove: "policy", MaxRemovals: 1}},
+		{name: "negative removals", cooldown: config.CooldownConfig{Remove: "Ratio > 2", MaxRemovals: -1}},
+		{name: "invalid bytes", cooldown: config.CooldownConfig{Remove: "Ratio > 2", MaxRemovedBytes: "lots"}},
+		{name: "negative window", cooldown: config.CooldownConfig{Remove: "Ratio > 2", Window: -time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadRemovalCooldowns(path, &config.FilterConfiguration{
+				Remove: []string{"Ratio > 2"},
+				Clean:  config.CleanConfig{Cooldowns: []config.CooldownConfig{tt.cooldown}},
+			})
+			assert.Error(t, err)
+		})
+	}
+
+	rc, err := loadRemovalCooldowns(path, &config.FilterConfiguration{Remove: []string{"Ratio > 2"}})
+	require.NoError(t, err)
+	assert.Nil(t, rc)
+}
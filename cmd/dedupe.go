@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+// defaultDedupeRank prefers copies on private trackers
+const defaultDedupeRank = "IsPrivate ? 1 : 0"
+
+var (
+	flagDedupeRank   string
+	flagDedupeRemove bool
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [CLIENT]",
+	Short: "Find torrents whose payload is duplicated by a better ranked torrent",
+	Long: `This command reports torrents whose files are all part of another, completed torrent that is ranked higher by the
+rank expression (--rank, the filter's dedupe.rank or "` + defaultDedupeRank + `"). With --remove the duplicates are removed,
+keeping their data for the better ranked torrent. Torrents matching the ignore filters are never removed.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		start := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("dedupe")
+
+		noti := newNotificationSender(log)
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		rankText := defaultDedupeRank
+		switch {
+		case flagDedupeRank != "":
+			rankText = flagDedupeRank
+		case clientFilter.Dedupe.Rank != "":
+			rankText = clientFilter.Dedupe.Rank
+		}
+
+		rank, err := expression.CompileRank(rankText)
+		if err != nil {
+			log.WithError(err).Fatal("Failed compiling rank expression")
+		}
+		log.Infof("Ranking torrents by: %s", rank.Text)
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// files are compared by path, and by their underlying file when hardlinks are mapped
+		var hfm hardlinkfilemap.HardlinkFileMapI = hardlinkfilemap.NewNoopHardlinkFileMap()
+		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "dedupe", true) {
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "dedupe", tqm.PathMappingHardlinks)
+			if err != nil {
+				log.WithError(err).Fatal("Failed loading client download path mappings")
+			}
+
+			start := time.Now()
+			hfm = hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
+		}
+
+		fileKey := func(path string) string {
+			if id, ok := hfm.FileID(path); ok {
+				return "id:" + id
+			}
+			return path
+		}
+
+		duplicates, err := findDuplicates(torrents, fileKey, func(t *config.Torrent) (float64, error) {
+			return rank.Rank(ctx, t)
+		})
+		if err != nil {
+			log.WithError(err).Fatal("Failed finding duplicates")
+		}
+
+		var (
+			removed int
+			fields  []notification.Field
+		)
+
+		for _, d := range duplicates {
+			t := d.Torrent
+
+			log.Info("-----")
+			log.Infof("Duplicate: %q (rank: %v, tracker: %s)", t.Name, d.Rank, t.TrackerName)
+			log.Infof("Kept by: %q (rank: %v, tracker: %s)", d.KeptBy.Name, d.KeptByRank, d.KeptBy.TrackerName)
+
+			if !flagDedupeRemove {
+				continue
+			}
+
+			if ignored, reason, err := c.ShouldIgnore(ctx, &t); err != nil {
+				log.WithError(err).Errorf("Failed checking ignore filters for torrent: %q", t.Name)
+				continue
+			} else if ignored {
+				log.Infof("Not removing ignored torrent (reason: %s)", reason)
+				continue
+			}
+
+			reason := fmt.Sprintf("duplicate of %s (%s)", d.KeptBy.Name, d.KeptBy.TrackerName)
+			if flagDryRun {
+				log.Warn("Dry-run enabled, skipping remove...")
+			} else if ok, err := c.RemoveTorrent(ctx, &t, false); err != nil || !ok {
+				log.WithError(err).Errorf("Failed removing torrent: %q", t.Name)
+				continue
+			} else {
+				// the data is kept, it is still used by the torrent it duplicates
+				log.Info("Removed (kept data on disk)")
+				history.Record(history.Entry{Client: clientName, Action: history.ActionRemove, Hash: t.Hash, Name: t.Name,
+					Tracker: t.TrackerName, Reason: reason, Detail: "kept data on disk"})
+			}
+
+			removed++
+			fields = append(fields, noti.BuildField(notification.ActionClean, notification.BuildOptions{
+				Torrent:       t,
+				RemovalReason: reason,
+			}))
+		}
+
+		log.Info("-----")
+		if !flagDedupeRemove {
+			log.Infof("Found %d duplicate torrent(s), use --remove to remove them", len(duplicates))
+			return
+		}
+		log.Infof("Removed %d of %d duplicate torrent(s)", removed, len(duplicates))
+
+		if !noti.CanSend() {
+			log.Debug("Notifications disabled, skipping...")
+			return
+		}
+
+		sendErr := noti.Send(
+			"Torrent Dedupe",
+			fmt.Sprintf("Removed **%d** duplicate torrent(s)", removed),
+			clientName,
+			time.Since(start),
+			fields,
+			flagDryRun,
+		)
+		if sendErr != nil {
+			log.WithError(sendErr).Error("Failed sending notification")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	dedupeCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	dedupeCmd.Flags().StringVar(&flagDedupeRank, "rank", "", "Expression ranking torrents sharing the same payload, higher ranks are kept")
+	dedupeCmd.Flags().BoolVar(&flagDedupeRemove, "remove", false, "Remove duplicates (keeping their data) instead of only reporting them")
+}
+
+type duplicate struct {
+	Torrent config.Torrent
+	Rank    float64
+	// KeptBy is the best ranked torrent containing all files of Torrent
+	KeptBy     config.Torrent
+	KeptByRank float64
+}
+
+// findDuplicates returns the torrents (sorted by name) whose files are all part of a completed torrent with a higher rank.
+// fileKey identifies the file of a path, so hardlinks can be treated as the same file.
+func findDuplicates(torrents map[string]config.Torrent, fileKey func(path string) string,
+	rank func(t *config.Torrent) (float64, error)) ([]duplicate, error) {
+	ranks := make(map[string]float64, len(torrents))
+	index := make(map[string]map[string]struct{})
+
+	for h, t := range torrents {
+		r, err := rank(&t)
+		if err != nil {
+			return nil, fmt.Errorf("rank torrent: %q: %w", t.Name, err)
+		}
+		ranks[h] = r
+
+		for _, f := range t.Files {
+			key := fileKey(f)
+			if index[key] == nil {
+				index[key] = make(map[string]struct{})
+			}
+			index[key][h] = struct{}{}
+		}
+	}
+
+	var duplicates []duplicate
+	for h, t := range torrents {
+		if len(t.Files) == 0 {
+			continue
+		}
+
+		// torrents containing every file of t
+		var covering map[string]struct{}
+		for _, f := range t.Files {
+			hashes := index[fileKey(f)]
+			if covering == nil {
+				covering = make(map[string]struct{}, len(hashes))
+				for other := range hashes {
+					if other != h {
+						covering[other] = struct{}{}
+					}
+				}
+				continue
+			}
+
+			for other := range covering {
+				if _, ok := hashes[other]; !ok {
+					delete(covering, other)
+				}
+			}
+		}
+
+		best := ""
+		for other := range covering {
+			if !torrents[other].Downloaded || ranks[other] <= ranks[h] {
+				continue
+			}
+			if best == "" || ranks[other] > ranks[best] || (ranks[other] == ranks[best] && other < best) {
+				best = other
+			}
+		}
+
+		if best != "" {
+			duplicates = append(duplicates, duplicate{Torrent: t, Rank: ranks[h], KeptBy: torrents[best], KeptByRank: ranks[best]})
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Torrent.Name != duplicates[j].Torrent.Name {
+			return duplicates[i].Torrent.Name < duplicates[j].Torrent.Name
+		}
+		return duplicates[i].Torrent.Hash < duplicates[j].Torrent.Hash
+	})
+
+	return duplicates, nil
+}
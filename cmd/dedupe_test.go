@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"public":  {Hash: "public", Name: "public", Downloaded: true, Files: []string{"/data/a.mkv"}},
+		"private": {Hash: "private", Name: "private", Downloaded: true, IsPrivate: true, Files: []string{"/data/a.mkv", "/data/a.nfo"}},
+		// hardlinked copy of a.mkv, identified by the same file key
+		"linked": {Hash: "linked", Name: "linked", Downloaded: true, Files: []string{"/links/a.mkv"}},
+		// only partly covered by private
+		"partial": {Hash: "partial", Name: "partial", Downloaded: true, Files: []string{"/data/a.mkv", "/data/b.mkv"}},
+		// covered by a torrent still downloading
+		"other":   {Hash: "other", Name: "other", Downloaded: true, Files: []string{"/data/c.mkv"}},
+		"pending": {Hash: "pending", Name: "pending", IsPrivate: true, Files: []string{"/data/c.mkv"}},
+	}
+
+	fileKey := func(path string) string {
+		if path == "/links/a.mkv" {
+			return "/data/a.mkv"
+		}
+		return path
+	}
+	rank := func(t *config.Torrent) (float64, error) {
+		if t.IsPrivate {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	duplicates, err := findDuplicates(torrents, fileKey, rank)
+	require.NoError(t, err)
+
+	var got []string
+	for _, d := range duplicates {
+		assert.Equal(t, "private", d.KeptBy.Hash)
+		got = append(got, d.Torrent.Hash)
+	}
+	assert.Equal(t, []string{"linked", "public"}, got)
+}
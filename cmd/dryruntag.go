@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+var flagDryRunTag string
+
+// dryRunTagger tags the torrents clean would remove in dry-run, so they can be reviewed in the client. The tag is
+// removed again from the torrents which are no longer removal candidates on the next run (and from all torrents when
+// not in dry-run).
+type dryRunTagger struct {
+	c   client.TagInterface
+	tag string
+	// marked are the hashes of the torrents clean would remove in this run
+	marked map[string]struct{}
+}
+
+// newDryRunTagger returns the tagger of the clean.dry_run_tag of filter (overridden by --dry-run-tag), nil when no tag
+// is configured or c has no tags
+func newDryRunTagger(log *logrus.Entry, c client.Interface, filter *config.FilterConfiguration) *dryRunTagger {
+	tag := filter.Clean.DryRunTag
+	if flagDryRunTag != "" {
+		tag = flagDryRunTag
+	}
+	if tag == "" {
+		return nil
+	}
+
+	ct, ok := c.(client.TagInterface)
+	if !ok {
+		log.Warnf("%v, skipping", client.Unsupported(c, "Dry-run tag"))
+		return nil
+	}
+
+	return &dryRunTagger{c: ct, tag: tag, marked: make(map[string]struct{})}
+}
+
+// mark records that the torrent with hash would be removed
+func (d *dryRunTagger) mark(hash string) {
+	if d == nil || !flagDryRun {
+		return
+	}
+
+	d.marked[hash] = struct{}{}
+}
+
+// apply tags the marked torrents and removes the tag from the others of torrents
+func (d *dryRunTagger) apply(ctx context.Context, log *logrus.Entry, torrents map[string]config.Torrent) {
+	if d == nil {
+		return
+	}
+
+	var tagged, untagged int
+	for _, h := range slices.Sorted(maps.Keys(torrents)) {
+		t := torrents[h]
+		_, marked := d.marked[h]
+		has := t.HasAnyTag(d.tag)
+
+		switch {
+		case marked && !has:
+			if err := d.c.AddTags(ctx, h, []string{d.tag}); err != nil {
+				log.WithError(err).Errorf("Failed adding dry-run tag %q to torrent: %q", d.tag, t.Name)
+				continue
+			}
+			tagged++
+		case !marked && has:
+			if err := d.c.RemoveTags(ctx, h, []string{d.tag}); err != nil {
+				log.WithError(err).Errorf("Failed removing dry-run tag %q from torrent: %q", d.tag, t.Name)
+				continue
+			}
+			untagged++
+		}
+	}
+
+	log.Infof("Dry-run tag %q: tagged %d torrents, removed it from %d torrents (%d tagged in total)", d.tag, tagged,
+		untagged, len(d.marked))
+}
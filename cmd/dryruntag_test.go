@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// tagRecorder records the tags added to and removed from torrents
+type tagRecorder struct {
+	client.TagInterface
+
+	added   []string
+	removed []string
+}
+
+func (r *tagRecorder) AddTags(_ context.Context, hash string, _ []string) error {
+	r.added = append(r.added, hash)
+	return nil
+}
+
+func (r *tagRecorder) RemoveTags(_ context.Context, hash string, _ []string) error {
+	r.removed = append(r.removed, hash)
+	return nil
+}
+
+func TestDryRunTaggerApply(t *testing.T) {
+	tagged := map[string]struct{}{"tqm:would-remove": {}}
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a"},
+		"b": {Hash: "b", Tags: tagged},
+		"c": {Hash: "c", Tags: tagged},
+		"d": {Hash: "d"},
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		{
+			name:        "dry-run tags the candidates and untags the others",
+			dryRun:      true,
+			wantAdded:   []string{"a"},
+			wantRemoved: []string{"c"},
+		},
+		{
+			name:        "removing untags all torrents",
+			wantRemoved: []string{"b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevDryRun := flagDryRun
+			flagDryRun = tt.dryRun
+			t.Cleanup(func() { flagDryRun = prevDryRun })
+
+			log := logrus.NewEntry(logrus.New())
+			r := &tagRecorder{}
+			d := newDryRunTagger(log, r, &config.FilterConfiguration{Clean: config.CleanConfig{DryRunTag: "tqm:would-remove"}})
+			require.NotNil(t, d)
+
+			d.mark("a")
+			d.mark("b")
+			d.apply(context.Background(), log, torrents)
+
+			assert.Equal(t, tt.wantAdded, r.added)
+			assert.Equal(t, tt.wantRemoved, r.removed)
+		})
+	}
+}
+
+func TestNewDryRunTagger_Unsupported(t *testing.T) {
+	// clients without tags skip the dry-run tag instead of failing the run
+	filter := &config.FilterConfiguration{Clean: config.CleanConfig{DryRunTag: "tqm:would-remove"}}
+	assert.Nil(t, newDryRunTagger(logrus.NewEntry(logrus.New()), &client.Deluge{}, filter))
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// exemptionsStateFile lists the torrents kept by clean regardless of its filters
+const exemptionsStateFile = "exemptions.json"
+
+var (
+	flagExemptFor    string
+	flagExemptReason string
+)
+
+// exemptionsPath returns the path of the exemptions
+func exemptionsPath() string {
+	return config.StatePath(exemptionsStateFile)
+}
+
+// exemption keeps a torrent from being removed by clean
+type exemption struct {
+	Hash   string    `json:"hash"`
+	Reason string    `json:"reason,omitempty"`
+	Added  time.Time `json:"added"`
+	// Expires is when clean may remove the torrent again, zero for never
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// String describes until when and why the torrent is exempt
+func (e exemption) String() string {
+	s := "exempt"
+	if !e.Expires.IsZero() {
+		s += " until " + e.Expires.Local().Format(time.DateTime)
+	}
+	if e.Reason != "" {
+		s += ": " + e.Reason
+	}
+	return s
+}
+
+var exemptCmd = &cobra.Command{
+	Use:   "exempt",
+	Short: "Keep torrents from being removed by clean for a while",
+	Long: `Exempt torrents are kept by clean (and the clean step of run) regardless of its filters, and reported as ignored, until
+their exemption expires. Exemptions are kept in exemptions.json next to the config file, this is easier than editing the
+ignore expressions for temporary cases, e.g. a torrent kept to rebuild the ratio of a tracker.`,
+}
+
+var exemptAddCmd = &cobra.Command{
+	Use:   "add [HASH]...",
+	Short: "Exempt torrents from clean",
+	Example: `  tqm exempt add 0123456789abcdef0123456789abcdef01234567 --for 30d --reason "rebuilding ratio"
+  tqm exempt add 0123456789abcdef0123456789abcdef01234567`,
+
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("exempt")
+
+		hashes, err := normalizeHashes(args)
+		if err != nil {
+			log.WithError(err).Fatal("Failed parsing torrent hashes")
+		}
+
+		var duration time.Duration
+		if flagExemptFor != "" {
+			if duration, err = parseExemptionDuration(flagExemptFor); err != nil {
+				log.WithError(err).Fatal("Failed parsing --for")
+			}
+		}
+
+		now := time.Now()
+		path := exemptionsPath()
+		exemptions, err := loadExemptions(path, now)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading exemptions")
+		}
+
+		for _, hash := range hashes {
+			e := exemption{Hash: hash, Reason: flagExemptReason, Added: now}
+			if duration > 0 {
+				e.Expires = now.Add(duration)
+			}
+			if _, ok := exemptions[hash]; ok {
+				log.Infof("Updating exemption of %s: %s", hash, e)
+			} else {
+				log.Infof("Exempting %s: %s", hash, e)
+			}
+			exemptions[hash] = e
+		}
+
+		if err := saveExemptions(path, exemptions); err != nil {
+			log.WithError(err).Fatal("Failed saving exemptions")
+		}
+	},
+}
+
+var exemptListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the exempt torrents",
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("exempt")
+
+		exemptions, err := loadExemptions(exemptionsPath(), time.Now())
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading exemptions")
+		}
+
+		list := sortedExemptions(exemptions)
+		if flagOutput == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(list); err != nil {
+				log.WithError(err).Fatal("Failed encoding exemptions")
+			}
+			return
+		}
+
+		if err := writeExemptionsTable(os.Stdout, list); err != nil {
+			log.WithError(err).Fatal("Failed writing exemptions")
+		}
+	},
+}
+
+var exemptRemoveCmd = &cobra.Command{
+	Use:   "remove [HASH]...",
+	Short: "Remove the exemption of torrents",
+
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("exempt")
+
+		hashes, err := normalizeHashes(args)
+		if err != nil {
+			log.WithError(err).Fatal("Failed parsing torrent hashes")
+		}
+
+		path := exemptionsPath()
+		exemptions, err := loadExemptions(path, time.Now())
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading exemptions")
+		}
+
+		for _, hash := range hashes {
+			if _, ok := exemptions[hash]; !ok {
+				log.Warnf("Torrent is not exempt: %s", hash)
+				continue
+			}
+			delete(exemptions, hash)
+			log.Infof("Removed exemption of %s", hash)
+		}
+
+		if err := saveExemptions(path, exemptions); err != nil {
+			log.WithError(err).Fatal("Failed saving exemptions")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exemptCmd)
+	exemptCmd.AddCommand(exemptAddCmd, exemptListCmd, exemptRemoveCmd)
+
+	exemptAddCmd.Flags().StringVar(&flagExemptFor, "for", "", "How long the torrents are exempt, e.g. 30d, 2w or 12h (default: until removed)")
+	exemptAddCmd.Flags().StringVar(&flagExemptReason, "reason", "", "Why the torrents are exempt, shown by list and in the clean report")
+}
+
+// normalizeHashes validates hashes and returns them in lowercase, without duplicates
+func normalizeHashes(hashes []string) ([]string, error) {
+	normalized := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		hash, err := normalizeHash(h)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, hash)
+	}
+
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// parseExemptionDuration parses a duration of time.ParseDuration, or a number of days (d) or weeks (w)
+func parseExemptionDuration(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	var (
+		d   time.Duration
+		err error
+	)
+	if unit > 0 {
+		var n float64
+		n, err = strconv.ParseFloat(s[:len(s)-1], 64)
+		d = time.Duration(n * float64(unit))
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration: %q (e.g. 30d, 2w or 12h)", s)
+	}
+	return d, nil
+}
+
+// loadExemptions returns the exemptions recorded at path by hash, without the ones expired at now
+func loadExemptions(path string, now time.Time) (map[string]exemption, error) {
+	exemptions := make(map[string]exemption)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return exemptions, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read exemptions: %w", err)
+	}
+
+	var list []exemption
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decode exemptions: %w", err)
+	}
+
+	for _, e := range list {
+		if e.Expires.IsZero() || now.Before(e.Expires) {
+			exemptions[e.Hash] = e
+		}
+	}
+	return exemptions, nil
+}
+
+func saveExemptions(path string, exemptions map[string]exemption) error {
+	data, err := json.MarshalIndent(sortedExemptions(exemptions), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode exemptions: %w", err)
+	}
+
+	if err := paths.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("write exemptions: %w", err)
+	}
+	return nil
+}
+
+// sortedExemptions returns the exemptions ordered by hash
+func sortedExemptions(exemptions map[string]exemption) []exemption {
+	list := make([]exemption, 0, len(exemptions))
+	for _, hash := range slices.Sorted(maps.Keys(exemptions)) {
+		list = append(list, exemptions[hash])
+	}
+	return list
+}
+
+func writeExemptionsTable(w io.Writer, exemptions []exemption) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "HASH\tADDED\tEXPIRES\tREASON\t")
+	for _, e := range exemptions {
+		expires := "never"
+		if !e.Expires.IsZero() {
+			expires = e.Expires.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", e.Hash, e.Added.Local().Format(time.DateTime), expires, e.Reason)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "%d exempt torrent(s)\n", len(exemptions))
+
+	return tw.Flush()
+}
+
+// exemptTorrents drops the exempt torrents from the torrents evaluated by clean and reports them as ignored
+func exemptTorrents(log *logrus.Entry, path string, torrents map[string]config.Torrent, now time.Time) error {
+	exemptions, err := loadExemptions(path, now)
+	if err != nil {
+		return err
+	}
+
+	var exempt int
+	for h, t := range torrents {
+		e, ok := exemptions[strings.ToLower(t.Hash)]
+		if !ok {
+			continue
+		}
+
+		log.Debugf("Not removing %s: %s (%s)", h, t.Name, e)
+		report.torrent(reportIgnored, t, "", e.String())
+		delete(torrents, h)
+		exempt++
+	}
+
+	if exempt > 0 {
+		log.Infof("Exempt torrents: %d", exempt)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+func TestParseExemptionDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "2w", want: 14 * 24 * time.Hour},
+		{in: "1.5d", want: 36 * time.Hour},
+		{in: "12h", want: 12 * time.Hour},
+		{in: "0d", wantErr: true},
+		{in: "-1d", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseExemptionDuration(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExemptTorrents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), exemptionsStateFile)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	// no exemptions yet
+	torrents := map[string]config.Torrent{"aaa": {Hash: "AAA", Name: "Kept"}}
+	require.NoError(t, exemptTorrents(logger.GetLogger("test"), path, torrents, now))
+	assert.Len(t, torrents, 1)
+
+	require.NoError(t, saveExemptions(path, map[string]exemption{
+		"aaa": {Hash: "aaa", Reason: "rebuilding ratio", Added: now, Expires: now.Add(time.Hour)},
+		"bbb": {Hash: "bbb", Added: now},
+		"ccc": {Hash: "ccc", Added: now.Add(-48 * time.Hour), Expires: now.Add(-24 * time.Hour)},
+	}))
+
+	exemptions, err := loadExemptions(path, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaa", "bbb"}, slices.Sorted(maps.Keys(exemptions)), "expired exemptions are dropped")
+
+	torrents = map[string]config.Torrent{
+		"aaa": {Hash: "AAA", Name: "Exempt"},
+		"bbb": {Hash: "bbb", Name: "Exempt forever"},
+		"ccc": {Hash: "ccc", Name: "Expired"},
+	}
+	require.NoError(t, exemptTorrents(logger.GetLogger("test"), path, torrents, now))
+	assert.Equal(t, []string{"ccc"}, slices.Sorted(maps.Keys(torrents)))
+
+	// the exemption ends once it expires
+	torrents = map[string]config.Torrent{"aaa": {Hash: "aaa"}}
+	require.NoError(t, exemptTorrents(logger.GetLogger("test"), path, torrents, now.Add(time.Hour)))
+	assert.Len(t, torrents, 1)
+}
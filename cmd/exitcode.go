@@ -0,0 +1,46 @@
+package cmd
+
+import "sync/atomic"
+
+// Exit codes of tqm, commands acting on torrents exit with exitPartialFailure or exitNothingDone depending on their outcome
+const (
+	exitOK             = 0
+	exitFatal          = 1
+	exitPartialFailure = 2
+	exitNothingDone    = 3
+)
+
+// runOutcome is the outcome of the executed command
+var runOutcome outcome
+
+// outcome counts the actions performed (or that would be performed in dry-run) and the failures of a command
+type outcome struct {
+	tracked  atomic.Bool
+	actions  atomic.Int64
+	failures atomic.Int64
+}
+
+// track makes the exit code reflect the outcome, it is called by commands acting on torrents
+func (o *outcome) track() {
+	o.tracked.Store(true)
+}
+
+// record adds done actions and failed actions or evaluations to the outcome
+func (o *outcome) record(done int, failed int) {
+	o.actions.Add(int64(done))
+	o.failures.Add(int64(failed))
+}
+
+// exitCode returns exitPartialFailure when anything failed and exitNothingDone when no action was performed
+func (o *outcome) exitCode() int {
+	switch {
+	case !o.tracked.Load():
+		return exitOK
+	case o.failures.Load() > 0:
+		return exitPartialFailure
+	case o.actions.Load() == 0:
+		return exitNothingDone
+	default:
+		return exitOK
+	}
+}
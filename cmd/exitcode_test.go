@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcome_ExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		tracked  bool
+		done     int
+		failed   int
+		expected int
+	}{
+		{name: "untracked", done: 0, failed: 1, expected: exitOK},
+		{name: "actions", tracked: true, done: 3, expected: exitOK},
+		{name: "nothing_done", tracked: true, expected: exitNothingDone},
+		{name: "partial_failure", tracked: true, done: 3, failed: 1, expected: exitPartialFailure},
+		{name: "only_failures", tracked: true, failed: 2, expected: exitPartialFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o outcome
+			if tt.tracked {
+				o.track()
+			}
+			o.record(tt.done, tt.failed)
+
+			assert.Equal(t, tt.expected, o.exitCode())
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [CLIENT] [HASH]",
+	Short: "Explain how the filter evaluates a single torrent",
+	Long: `This command prints every field of a single torrent and the result of each ignore, remove, pause, resume, reannounce,
+recheck, label, move and tag expression of the client's filter (or the one given with --filter) for it, including why expressions were not evaluated.`,
+
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("explain")
+
+		hash, err := normalizeHash(args[1])
+		if err != nil {
+			log.WithError(err).Fatalf("Invalid torrent hash: %q", args[1])
+		}
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		exp, err := expression.Compile(clientFilter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed compiling filter")
+		}
+
+		if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+			log.WithError(err).Error("Failed retrieving free-space")
+		}
+
+		// all torrents are needed to detect hardlinks
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		}
+
+		target, missing := scopeTorrentsToHashes(torrents, []string{hash})
+		if len(missing) > 0 {
+			log.Fatalf("Torrent not found in client: %s", hash)
+		}
+		t := target[hash]
+
+		clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "explain", tqm.PathMappingHardlinks)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+		t.HardlinkedOutsideClient = hardlinkfilemap.New(torrents, clientDownloadPathMapping).HardlinkedOutsideClient(t)
+
+		result := explainTorrent(ctx, exp, &t)
+
+		if flagOutput == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(result); err != nil {
+				log.WithError(err).Fatal("Failed encoding explanation")
+			}
+			return
+		}
+
+		if err := writeExplanation(os.Stdout, result); err != nil {
+			log.WithError(err).Fatal("Failed writing explanation")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+}
+
+type explanation struct {
+	Hash   string         `json:"hash"`
+	Name   string         `json:"name"`
+	Fields []explainField `json:"fields"`
+	Rules  []explainRule  `json:"rules"`
+}
+
+type explainField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type explainRule struct {
+	Section string `json:"section"`
+	// Name is the label or tag of label and tag rules
+	Name    string `json:"name,omitempty"`
+	Matched bool   `json:"matched"`
+	// Note explains the outcome, e.g. why the command skips the torrent regardless of the result
+	Note        string                  `json:"note,omitempty"`
+	Expressions []expression.Evaluation `json:"expressions"`
+}
+
+// explainTorrent evaluates every expression of exp against t, mirroring how the commands evaluate them
+func explainTorrent(ctx context.Context, exp *expression.Expressions, t *config.Torrent) explanation {
+	e := explanation{
+		Hash:   t.Hash,
+		Name:   t.Name,
+		Fields: explainFields(ctx, t),
+	}
+
+	// sections without expressions are left out
+	single := func(section string, expressions []expression.CompiledExpression, note string) {
+		if len(expressions) == 0 {
+			return
+		}
+
+		evaluations, matched := expression.ExplainSingleMatch(ctx, t, expressions)
+		e.Rules = append(e.Rules, explainRule{Section: section, Matched: matched, Note: note, Expressions: evaluations})
+	}
+
+	// ignore
+	evaluations, ignored := expression.ExplainSingleMatch(ctx, t, exp.Ignores)
+	if len(evaluations) > 0 {
+		rule := explainRule{Section: "ignore", Matched: ignored, Expressions: evaluations}
+		if ignored && config.Config.BypassIgnoreIfUnregistered && t.IsUnregistered(ctx) {
+			rule.Note = "bypassed, the torrent is unregistered and bypassIgnoreIfUnregistered is enabled"
+			ignored = false
+		}
+		e.Rules = append(e.Rules, rule)
+	}
+
+	skippedNote := func(note string) string {
+		if ignored {
+			return "skipped, the torrent is ignored"
+		}
+		return note
+	}
+
+	single("remove", exp.Removes, skippedNote(""))
+
+	pauseNote, resumeNote := "", "skipped, the torrent is not paused"
+	if t.IsPaused() {
+		pauseNote, resumeNote = "skipped, the torrent is already paused", ""
+	}
+	single("pause", exp.Pauses, skippedNote(pauseNote))
+	single("resume", exp.Resumes, skippedNote(resumeNote))
+
+	reannounceNote := ""
+	if t.IsPaused() {
+		reannounceNote = "skipped, the torrent is paused"
+	}
+	single("reannounce", exp.Reannounces, skippedNote(reannounceNote))
+
+	recheckNote := ""
+	if t.IsChecking() {
+		recheckNote = "skipped, the torrent is already being checked"
+	}
+	single("recheck", exp.Rechecks, skippedNote(recheckNote))
+	single("prune_files", exp.PruneFiles, skippedNote(""))
+
+	// the first label whose update expressions all match is applied
+	labelMatched := false
+	for _, label := range exp.Labels {
+		rule := explainRule{Section: "label", Name: label.Name}
+
+		if labelMatched {
+			rule.Note = "not evaluated, an earlier label matched"
+			for _, u := range label.Updates {
+				rule.Expressions = append(rule.Expressions, expression.Evaluation{Expression: u.Text, Reason: "an earlier label matched"})
+			}
+			e.Rules = append(e.Rules, rule)
+			continue
+		}
+
+		rule.Expressions, rule.Matched = expression.ExplainAllMatch(ctx, t, label.Updates)
+		if rule.Matched {
+			labelMatched = true
+			if label.Name == t.Label {
+				rule.Note = "the torrent already has this label"
+			} else {
+				rule.Note = "the torrent would be relabeled"
+			}
+		}
+		e.Rules = append(e.Rules, rule)
+	}
+
+	// the first move rule whose update expressions all match is applied
+	moveMatched := false
+	for _, move := range exp.Moves {
+		rule := explainRule{Section: "move", Name: move.Path}
+
+		if moveMatched {
+			rule.Note = "not evaluated, an earlier move rule matched"
+			for _, u := range move.Updates {
+				rule.Expressions = append(rule.Expressions, expression.Evaluation{Expression: u.Text, Reason: "an earlier move rule matched"})
+			}
+			e.Rules = append(e.Rules, rule)
+			continue
+		}
+
+		rule.Expressions, rule.Matched = expression.ExplainAllMatch(ctx, t, move.Updates)
+		if rule.Matched {
+			moveMatched = true
+			switch {
+			case samePath(move.Path, t.Path):
+				rule.Note = "the torrent is already in this path"
+			case t.IsChecking() || t.IsMoving():
+				rule.Note = "skipped, the torrent is being checked or moved"
+			default:
+				rule.Note = "the torrent would be moved"
+			}
+		}
+		e.Rules = append(e.Rules, rule)
+	}
+
+	// every tag rule is evaluated
+	for _, tag := range exp.Tags {
+		rule := explainRule{Section: "tag", Name: tag.Name}
+		rule.Expressions, rule.Matched = expression.ExplainAllMatch(ctx, t, tag.Updates)
+
+		_, hasTag := t.Tags[tag.Name]
+		switch {
+		case hasTag && !rule.Matched && (tag.Mode == expression.TagModeRemove || tag.Mode == expression.TagModeFull):
+			rule.Note = "the tag would be removed"
+		case !hasTag && rule.Matched && (tag.Mode == expression.TagModeAdd || tag.Mode == expression.TagModeFull):
+			rule.Note = "the tag would be added"
+		}
+		e.Rules = append(e.Rules, rule)
+	}
+
+	return e
+}
+
+// explainFields returns the value of every field and helper available to filters
+func explainFields(ctx context.Context, t *config.Torrent) []explainField {
+	var fields []explainField
+
+	t.LoadTrackerSeedTimeRequired(ctx)
+	v := reflect.ValueOf(*t)
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() || sf.Name == "APIDividerPrinted" || sf.Name == "RegistrationState" {
+			continue
+		}
+
+		var value string
+		switch sf.Name {
+		case "FreeSpaceGB":
+			if t.FreeSpaceGB == nil {
+				continue
+			}
+			fields = append(fields, explainField{Name: "FreeSpaceGB()", Value: strconv.FormatFloat(t.FreeSpaceGB(), 'f', 2, 64)})
+			continue
+		case "TrackerStatusSince":
+			if t.TrackerStatusSince.IsZero() {
+				continue
+			}
+			value = t.TrackerStatusSince.Format(time.RFC3339)
+		case "Tags":
+			value = strings.Join(t.TagsSlice(), ", ")
+		case "Files":
+			value = fmt.Sprintf("%d file(s)", len(t.Files))
+		default:
+			value = formatExplainValue(v.Field(i))
+		}
+
+		fields = append(fields, explainField{Name: sf.Name, Value: value})
+	}
+
+	for _, helper := range []struct {
+		name  string
+		value bool
+	}{
+		{"IsUnregistered()", t.IsUnregistered(ctx)},
+		{"IsTrackerDown()", t.IsTrackerDown()},
+		{"IsFreeleech()", t.IsFreeleech(ctx)},
+		{"IsPublicTracker()", t.IsPublicTracker()},
+		{"IsPaused()", t.IsPaused()},
+		{"IsChecking()", t.IsChecking()},
+		{"IsMoving()", t.IsMoving()},
+		{"HasMissingFiles()", t.HasMissingFiles()},
+	} {
+		fields = append(fields, explainField{Name: helper.name, Value: strconv.FormatBool(helper.value)})
+	}
+
+	return fields
+}
+
+func formatExplainValue(v reflect.Value) string {
+	if v.Kind() != reflect.Map {
+		return fmt.Sprint(v.Interface())
+	}
+
+	entries := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries = append(entries, fmt.Sprintf("%v=%v", iter.Key().Interface(), iter.Value().Interface()))
+	}
+	sort.Strings(entries)
+
+	return strings.Join(entries, ", ")
+}
+
+func writeExplanation(w io.Writer, e explanation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Torrent: %s (%s)\n\n", e.Name, e.Hash)
+	fmt.Fprintln(tw, "FIELD\tVALUE\t")
+	for _, f := range e.Fields {
+		fmt.Fprintf(tw, "%s\t%s\t\n", f.Name, f.Value)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "RULE\tEXPRESSION\tRESULT\t")
+	for _, r := range e.Rules {
+		name := r.Section
+		if r.Name != "" {
+			name = fmt.Sprintf("%s (%s)", r.Section, r.Name)
+		}
+
+		summary := fmt.Sprintf("matched: %t", r.Matched)
+		if r.Note != "" {
+			summary += ", " + r.Note
+		}
+		fmt.Fprintf(tw, "%s\t\t%s\t\n", name, summary)
+
+		for _, ev := range r.Expressions {
+			var result string
+			switch {
+			case ev.Error != "":
+				result = "error: " + ev.Error
+			case !ev.Evaluated:
+				result = "not evaluated, " + ev.Reason
+			default:
+				result = strconv.FormatBool(ev.Result)
+			}
+			fmt.Fprintf(tw, "\t%s\t%s\t\n", ev.Expression, result)
+		}
+	}
+
+	return tw.Flush()
+}
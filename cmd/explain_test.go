@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+)
+
+func TestExplainTorrent(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	filter := &config.FilterConfiguration{
+		Ignore: []string{"Ratio < 1.0", `Label == "keep"`, "SeedingDays < 1.0"},
+		Remove: []string{"Ratio > 2.0"},
+	}
+	filter.Label = append(filter.Label,
+		struct {
+			Name   string
+			Update []string
+		}{Name: "keep", Update: []string{`Label == "keep"`}},
+		struct {
+			Name   string
+			Update []string
+		}{Name: "second", Update: []string{"Ratio > 1.0"}})
+
+	exp, err := expression.Compile(filter)
+	require.NoError(t, err)
+
+	torrent := &config.Torrent{Hash: "a", Name: "a", Label: "keep", Ratio: 3, Tags: map[string]struct{}{}}
+	e := explainTorrent(context.Background(), exp, torrent)
+
+	require.Len(t, e.Rules, 4)
+
+	ignore := e.Rules[0]
+	assert.Equal(t, "ignore", ignore.Section)
+	assert.True(t, ignore.Matched)
+	assert.Equal(t, []expression.Evaluation{
+		{Expression: "Ratio < 1.0", Evaluated: true, Result: false},
+		{Expression: `Label == "keep"`, Evaluated: true, Result: true},
+		{Expression: "SeedingDays < 1.0", Reason: "an earlier expression matched"},
+	}, ignore.Expressions)
+
+	remove := e.Rules[1]
+	assert.Equal(t, "remove", remove.Section)
+	assert.True(t, remove.Matched)
+	assert.Equal(t, "skipped, the torrent is ignored", remove.Note)
+
+	assert.Equal(t, "keep", e.Rules[2].Name)
+	assert.True(t, e.Rules[2].Matched)
+	assert.Equal(t, "the torrent already has this label", e.Rules[2].Note)
+
+	assert.Equal(t, "second", e.Rules[3].Name)
+	assert.False(t, e.Rules[3].Matched)
+	assert.False(t, e.Rules[3].Expressions[0].Evaluated)
+
+	assert.Contains(t, e.Fields, explainField{Name: "Ratio", Value: "3"})
+	assert.Contains(t, e.Fields, explainField{Name: "IsPaused()", Value: "false"})
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/schema"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+var flagExportFile string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [CLIENT]",
+	Short: "Export the torrent client's queue including filter results",
+	Long: `This command writes all torrents of a torrent client's queue, as seen by filters, to JSON or CSV.
+Every torrent includes whether it is hardlinked outside the client and the results of the configured filters.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("export")
+
+		// the export is written as JSON unless csv is given
+		if flagOutput == outputJSONLines {
+			log.Fatalf("Unsupported output format: %q (supported: %s, %s)", flagOutput, outputJSON, outputCSV)
+		}
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, _, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+			log.WithError(err).Error("Failed retrieving free-space")
+		}
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// download path mapping
+		clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "export", tqm.PathMappingHardlinks)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+
+		// create map of paths associated to underlying file ids
+		start := time.Now()
+		hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+		log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		exported := make([]schema.Torrent, 0, len(torrents))
+		for _, t := range torrents {
+			t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+			exported = append(exported, exportTorrent(ctx, log, c, t))
+		}
+
+		sort.Slice(exported, func(i, j int) bool {
+			if exported[i].Name != exported[j].Name {
+				return exported[i].Name < exported[j].Name
+			}
+			return exported[i].Hash < exported[j].Hash
+		})
+
+		// write export
+		var w io.Writer = os.Stdout
+		if flagExportFile != "" {
+			f, err := os.Create(flagExportFile)
+			if err != nil {
+				log.WithError(err).Fatalf("Failed creating export file: %q", flagExportFile)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if flagOutput == outputCSV {
+			err = writeExportCSV(w, exported)
+		} else {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(schema.Export{SchemaVersion: schema.Version, Torrents: exported})
+		}
+
+		if err != nil {
+			log.WithError(err).Fatal("Failed writing export")
+		}
+
+		if flagExportFile != "" {
+			log.Infof("Exported %d torrent(s) to %q", len(exported), flagExportFile)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	exportCmd.Flags().StringVar(&flagExportFile, "file", "", "File to write to instead of stdout")
+	exportCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	exportCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// exportTorrent evaluates the configured filters against t, failing filters are logged and reported as not matching
+func exportTorrent(ctx context.Context, log *logrus.Entry, c client.Interface, t config.Torrent) schema.Torrent {
+	var (
+		m   schema.Match
+		err error
+	)
+
+	m.Unregistered = t.IsUnregistered(ctx)
+	m.TrackerDown = t.IsTrackerDown()
+	m.PublicTracker = t.IsPublicTracker()
+
+	if m.Ignore, m.IgnoreReason, err = c.ShouldIgnore(ctx, &t); err != nil {
+		log.WithError(err).Errorf("Failed checking ignore filters for torrent: %q", t.Name)
+		m.Ignore, m.IgnoreReason = false, ""
+	}
+
+	if m.Remove, m.RemoveReason, err = c.ShouldRemoveWithReason(ctx, &t); err != nil {
+		log.WithError(err).Errorf("Failed checking remove filters for torrent: %q", t.Name)
+	}
+
+	if m.Pause, err = c.CheckTorrentPause(ctx, &t); err != nil {
+		log.WithError(err).Errorf("Failed checking pause filters for torrent: %q", t.Name)
+	}
+
+	if m.Resume, err = c.CheckTorrentResume(ctx, &t); err != nil {
+		log.WithError(err).Errorf("Failed checking resume filters for torrent: %q", t.Name)
+	}
+
+	if label, ok, err := c.ShouldRelabel(ctx, &t); err != nil {
+		log.WithError(err).Errorf("Failed checking label filters for torrent: %q", t.Name)
+	} else if ok {
+		m.Label = label
+	}
+
+	return schema.NewTorrent(t, m)
+}
+
+var exportCSVHeader = []string{
+	"Hash", "Name", "Path", "TotalBytes", "State", "Downloaded", "Seeding", "Ratio", "AddedDays", "SeedingDays",
+	"LastActivityDays", "Label", "Tags", "Seeds", "Peers", "IsPrivate", "TrackerName", "TrackerHost", "TrackerStatus",
+	"HardlinkedOutsideClient", "Unregistered", "TrackerDown", "PublicTracker", "Ignore", "IgnoreReason", "Remove",
+	"RemoveReason", "Pause", "Resume", "NewLabel",
+}
+
+func writeExportCSV(w io.Writer, torrents []schema.Torrent) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	formatFloat := func(f float32) string {
+		return strconv.FormatFloat(float64(f), 'f', 3, 32)
+	}
+
+	for _, t := range torrents {
+		record := []string{
+			t.Hash, t.Name, t.Path, strconv.FormatInt(t.TotalBytes, 10), t.State,
+			strconv.FormatBool(t.Downloaded), strconv.FormatBool(t.Seeding), formatFloat(t.Ratio),
+			formatFloat(t.AddedDays), formatFloat(t.SeedingDays), formatFloat(t.LastActivityDays), t.Label,
+			strings.Join(t.Tags, ";"), strconv.FormatInt(t.Seeds, 10), strconv.FormatInt(t.Peers, 10),
+			strconv.FormatBool(t.IsPrivate), t.TrackerName, t.TrackerHost, t.TrackerStatus,
+			strconv.FormatBool(t.HardlinkedOutsideClient), strconv.FormatBool(t.Match.Unregistered),
+			strconv.FormatBool(t.Match.TrackerDown), strconv.FormatBool(t.Match.PublicTracker),
+			strconv.FormatBool(t.Match.Ignore), t.Match.IgnoreReason, strconv.FormatBool(t.Match.Remove),
+			t.Match.RemoveReason, strconv.FormatBool(t.Match.Pause), strconv.FormatBool(t.Match.Resume), t.Match.Label,
+		}
+
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("write record: %v: %w", t.Hash, err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
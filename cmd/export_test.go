@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/schema"
+)
+
+func TestWriteExportCSV(t *testing.T) {
+	torrents := []schema.Torrent{
+		schema.NewTorrent(config.Torrent{
+			Hash:                    "0123456789abcdef0123456789abcdef01234567",
+			Name:                    "Some, Release",
+			Ratio:                   1.5,
+			Tags:                    map[string]struct{}{"b": {}, "a": {}},
+			TrackerName:             "tracker.com",
+			HardlinkedOutsideClient: true,
+		}, schema.Match{
+			Remove:       true,
+			RemoveReason: "IsUnregistered()",
+			Label:        "sorted",
+		}),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeExportCSV(&buf, torrents))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, exportCSVHeader, records[0])
+
+	row := make(map[string]string, len(records[0]))
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+
+	assert.Equal(t, "Some, Release", row["Name"])
+	assert.Equal(t, "1.500", row["Ratio"])
+	assert.Equal(t, "a;b", row["Tags"])
+	assert.Equal(t, "true", row["HardlinkedOutsideClient"])
+	assert.Equal(t, "true", row["Remove"])
+	assert.Equal(t, "IsUnregistered()", row["RemoveReason"])
+	assert.Equal(t, "sorted", row["NewLabel"])
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/paths"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+// extractedArchiveRoots returns the local paths of the folders of torrents containing RAR archives
+func extractedArchiveRoots(torrents map[string]config.Torrent, downloadPathMapping map[string]string) []string {
+	seen := make(map[string]struct{})
+	for _, t := range torrents {
+		for _, dir := range t.ArchiveDirs() {
+			seen[paths.MapPath(dir, downloadPathMapping)] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// extractedArchives removes the content extracted next to the archives of the torrents removed by clean
+type extractedArchives struct {
+	tfm                 *torrentfilemap.TorrentFileMap
+	downloadPathMapping map[string]string
+}
+
+// newExtractedArchives returns nil unless the extracted_archives policy of the filter (or client) is remove
+func newExtractedArchives(clientConfig map[string]any, filter *config.FilterConfiguration, tfm *torrentfilemap.TorrentFileMap,
+	downloadPathMapping map[string]string) (*extractedArchives, error) {
+	clientOrphan, err := config.ClientOrphanConfig(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Orphan.Merge(clientOrphan).WithDefaults().ExtractedArchives != config.ExtractedArchivesRemove {
+		return nil, nil
+	}
+
+	return &extractedArchives{tfm: tfm, downloadPathMapping: downloadPathMapping}, nil
+}
+
+// remove deletes the archive folders of t which no longer contain files of other torrents, except the files matching
+// the keep_files patterns of kept (when set). t must have been removed from the torrent file map
+func (e *extractedArchives) remove(log *logrus.Entry, t config.Torrent, kept *keptFiles) {
+	if e == nil {
+		return
+	}
+
+	for _, dir := range t.ArchiveDirs() {
+		if e.tfm.HasPathUnder(dir) {
+			log.Debugf("Archive folder still contains files of other torrents, keeping extracted content: %q", dir)
+			continue
+		}
+
+		localDir := paths.MapPath(dir, e.downloadPathMapping)
+		if flagDryRun {
+			log.Warnf("Dry-run enabled, skipping removal of extracted content: %q", localDir)
+			continue
+		}
+
+		if kept != nil {
+			kept.removeDir(log, t, localDir)
+			continue
+		}
+
+		if err := os.RemoveAll(localDir); err != nil {
+			log.WithError(err).Errorf("Failed removing extracted content: %q", localDir)
+			continue
+		}
+		log.Infof("Removed extracted content: %q", localDir)
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+func TestExtractedArchivesRemove(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"Movie/movie.mkv", "Shared/extracted.mkv", "Shared/shared.mkv"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), nil, 0600))
+	}
+
+	removed := config.Torrent{Hash: "a", Path: "/client", Files: []string{"/client/Movie/movie.rar", "/client/Shared/shared.rar"}}
+	other := config.Torrent{Hash: "b", Path: "/client", Files: []string{"/client/Shared/shared.mkv"}}
+	tfm := torrentfilemap.New(map[string]config.Torrent{"b": other})
+
+	assert.Equal(t, []string{dir + "/Movie", dir + "/Shared"},
+		extractedArchiveRoots(map[string]config.Torrent{"a": removed, "b": other}, map[string]string{"/client": dir}))
+
+	extracted := &extractedArchives{tfm: tfm, downloadPathMapping: map[string]string{"/client": dir}}
+	extracted.remove(logger.GetLogger("test"), removed, nil)
+
+	assert.NoDirExists(t, filepath.Join(dir, "Movie"))
+	// the folder still contains files of another torrent
+	assert.FileExists(t, filepath.Join(dir, "Shared", "extracted.mkv"))
+
+	// disabled
+	var disabled *extractedArchives
+	disabled.remove(logger.GetLogger("test"), removed, nil)
+}
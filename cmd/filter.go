@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Work with filters",
+}
+
+var filterTestCmd = &cobra.Command{
+	Use:   "test [CLIENT]",
+	Short: "Evaluate a filter against the torrent client's queue without performing any action",
+	Long: `This command evaluates the client's filter (or the one given with --filter) against all torrents of a torrent client
+and prints which torrents would be ignored, removed, paused, resumed, rechecked, reannounced, relabeled or moved, together with the expression that matched.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("filter")
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		exp, err := expression.Compile(clientFilter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed compiling filter")
+		}
+
+		if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+			log.WithError(err).Error("Failed retrieving free-space")
+		}
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// hardlinks are always mapped, so filters using HardlinkedOutsideClient are evaluated correctly
+		clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "filter", tqm.PathMappingHardlinks)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+		hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		var matches []filterMatch
+		for _, t := range torrents {
+			t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+
+			m, err := testFilter(ctx, exp, &t)
+			if err != nil {
+				log.WithError(err).Errorf("Failed evaluating filter for torrent: %q", t.Name)
+				continue
+			}
+			matches = append(matches, m...)
+		}
+
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].Name != matches[j].Name {
+				return matches[i].Name < matches[j].Name
+			}
+			return matches[i].Hash < matches[j].Hash
+		})
+
+		if flagOutput == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(matches); err != nil {
+				log.WithError(err).Fatal("Failed encoding filter results")
+			}
+			return
+		}
+
+		if err := writeFilterMatchesTable(os.Stdout, matches); err != nil {
+			log.WithError(err).Fatal("Failed writing filter results")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+	filterCmd.AddCommand(filterTestCmd)
+
+	filterTestCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	filterTestCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	filterTestCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+type filterMatch struct {
+	Hash       string `json:"hash"`
+	Name       string `json:"name"`
+	Action     string `json:"action"`
+	Expression string `json:"expression"`
+	Label      string `json:"label,omitempty"`
+	Path       string `json:"path,omitempty"`
+}
+
+// testFilter returns the actions the commands would take for t, mirroring their rules: ignored torrents are neither
+// removed, paused, resumed, rechecked nor reannounced (unless unregistered with bypassIgnoreIfUnregistered), relabeling and moving ignore ignore rules
+func testFilter(ctx context.Context, exp *expression.Expressions, t *config.Torrent) ([]filterMatch, error) {
+	var matches []filterMatch
+	add := func(action string, expr string, label string) {
+		matches = append(matches, filterMatch{Hash: t.Hash, Name: t.Name, Action: action, Expression: expr, Label: label})
+	}
+	joinTexts := func(expressions []expression.CompiledExpression) string {
+		texts := make([]string, 0, len(expressions))
+		for _, e := range expressions {
+			texts = append(texts, e.Text)
+		}
+		return strings.Join(texts, " && ")
+	}
+
+	ignored, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, exp.Ignores)
+	if err != nil {
+		return nil, fmt.Errorf("check ignore expression: %w", err)
+	}
+
+	switch {
+	case ignored && config.Config.BypassIgnoreIfUnregistered && t.IsUnregistered(ctx):
+		add("ignore-bypassed", reason, "")
+		ignored = false
+	case ignored:
+		add("ignore", reason, "")
+	}
+
+	if !ignored {
+		remove, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, exp.Removes)
+		if err != nil {
+			return nil, fmt.Errorf("check remove expression: %w", err)
+		} else if remove {
+			add("remove", reason, "")
+		}
+
+		if t.IsPaused() {
+			resume, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, exp.Resumes)
+			if err != nil {
+				return nil, fmt.Errorf("check resume expression: %w", err)
+			} else if resume {
+				add("resume", reason, "")
+			}
+		} else {
+			pause, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, exp.Pauses)
+			if err != nil {
+				return nil, fmt.Errorf("check pause expression: %w", err)
+			} else if pause {
+				add("pause", reason, "")
+			}
+
+			reannounce, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, exp.Reannounces)
+			if err != nil {
+				return nil, fmt.Errorf("check reannounce expression: %w", err)
+			} else if reannounce {
+				add("reannounce", reason, "")
+			}
+		}
+
+		if !t.IsChecking() {
+			recheck, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, exp.Rechecks)
+			if err != nil {
+				return nil, fmt.Errorf("check recheck expression: %w", err)
+			} else if recheck {
+				add("recheck", reason, "")
+			}
+		}
+	}
+
+	// the first label whose update expressions all match is applied
+	for _, label := range exp.Labels {
+		match, err := expression.CheckTorrentAllMatch(ctx, t, label.Updates)
+		if err != nil {
+			return nil, fmt.Errorf("check label expression: %w", err)
+		} else if !match {
+			continue
+		}
+
+		if label.Name != t.Label {
+			add("relabel", joinTexts(label.Updates), label.Name)
+		}
+		break
+	}
+
+	// the first move rule whose update expressions all match is applied
+	if !t.IsChecking() && !t.IsMoving() {
+		for _, move := range exp.Moves {
+			match, err := expression.CheckTorrentAllMatch(ctx, t, move.Updates)
+			if err != nil {
+				return nil, fmt.Errorf("check move expression: %w", err)
+			} else if !match {
+				continue
+			}
+
+			if !samePath(move.Path, t.Path) {
+				matches = append(matches, filterMatch{Hash: t.Hash, Name: t.Name, Action: "move", Expression: joinTexts(move.Updates), Path: move.Path})
+			}
+			break
+		}
+	}
+
+	return matches, nil
+}
+
+func writeFilterMatchesTable(w io.Writer, matches []filterMatch) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tHASH\tACTION\tEXPRESSION\t")
+	for _, m := range matches {
+		action := m.Action
+		if m.Label != "" {
+			action = fmt.Sprintf("%s (%s)", action, m.Label)
+		}
+		if m.Path != "" {
+			action = fmt.Sprintf("%s (%s)", action, m.Path)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", m.Name, m.Hash, action, m.Expression)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+)
+
+func TestTestFilter(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	filter := &config.FilterConfiguration{
+		Ignore:     []string{`Label == "keep"`},
+		Remove:     []string{"Ratio > 2.0"},
+		Pause:      []string{"SeedingDays > 7.0"},
+		Resume:     []string{"Ratio < 1.0"},
+		Recheck:    []string{`Label == "restored"`},
+		Reannounce: []string{"TrackerStatusCode == 4"},
+	}
+	filter.Label = append(filter.Label, struct {
+		Name   string
+		Update []string
+	}{Name: "sorted", Update: []string{"Ratio > 1.0", `Label != "keep"`}})
+	filter.Move = append(filter.Move, struct {
+		Path   string
+		Update []string
+	}{Path: "/archive", Update: []string{"AddedDays > 30"}})
+
+	exp, err := expression.Compile(filter)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		torrent  config.Torrent
+		expected []filterMatch
+	}{
+		{
+			name:    "ignored_torrent_is_not_removed",
+			torrent: config.Torrent{Hash: "a", Name: "a", Label: "keep", Ratio: 3},
+			expected: []filterMatch{
+				{Hash: "a", Name: "a", Action: "ignore", Expression: `Label == "keep"`},
+			},
+		},
+		{
+			name:    "removed_paused_and_relabeled",
+			torrent: config.Torrent{Hash: "b", Name: "b", Ratio: 3, SeedingDays: 10},
+			expected: []filterMatch{
+				{Hash: "b", Name: "b", Action: "remove", Expression: "Ratio > 2.0"},
+				{Hash: "b", Name: "b", Action: "pause", Expression: "SeedingDays > 7.0"},
+				{Hash: "b", Name: "b", Action: "relabel", Expression: `Ratio > 1.0 && Label != "keep"`, Label: "sorted"},
+			},
+		},
+		{
+			name:    "paused_torrent_is_resumed",
+			torrent: config.Torrent{Hash: "c", Name: "c", State: "stoppedUP", Ratio: 0.5, SeedingDays: 10},
+			expected: []filterMatch{
+				{Hash: "c", Name: "c", Action: "resume", Expression: "Ratio < 1.0"},
+			},
+		},
+		{
+			name:    "restored_torrent_is_rechecked",
+			torrent: config.Torrent{Hash: "e", Name: "e", Label: "restored"},
+			expected: []filterMatch{
+				{Hash: "e", Name: "e", Action: "recheck", Expression: `Label == "restored"`},
+			},
+		},
+		{
+			name:    "checking_torrent_is_not_rechecked",
+			torrent: config.Torrent{Hash: "f", Name: "f", Label: "restored", State: "checkingUP"},
+		},
+		{
+			name:    "not_working_tracker_is_reannounced",
+			torrent: config.Torrent{Hash: "g", Name: "g", TrackerStatusCode: config.TrackerStatusCodeNotWorking},
+			expected: []filterMatch{
+				{Hash: "g", Name: "g", Action: "reannounce", Expression: "TrackerStatusCode == 4"},
+			},
+		},
+		{
+			name:    "old_torrent_is_moved",
+			torrent: config.Torrent{Hash: "h", Name: "h", Path: "/downloads", AddedDays: 40},
+			expected: []filterMatch{
+				{Hash: "h", Name: "h", Action: "move", Expression: "AddedDays > 30", Path: "/archive"},
+			},
+		},
+		{
+			name:    "already_moved",
+			torrent: config.Torrent{Hash: "i", Name: "i", Path: "/archive/", AddedDays: 40},
+		},
+		{
+			name:    "already_labeled",
+			torrent: config.Torrent{Hash: "d", Name: "d", Label: "sorted", Ratio: 1.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := testFilter(context.Background(), exp, &tt.torrent)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+}
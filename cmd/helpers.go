@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,7 +20,10 @@ import (
 
 	"github.com/autobrr/tqm/pkg/client"
 	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/hooks"
 	"github.com/autobrr/tqm/pkg/notification"
 	"github.com/autobrr/tqm/pkg/torrentfilemap"
 )
@@ -28,6 +37,10 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 		errorRetaggedTorrents int
 
 		fields []notification.Field
+
+		// number of torrents every tag would be added to and removed from, summarized in dry-run
+		tagsAdded   = make(map[string]int)
+		tagsRemoved = make(map[string]int)
 	)
 
 	// iterate torrents
@@ -37,6 +50,8 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 		if err != nil {
 			// error while determining whether to evaluate tag rules
 			log.WithError(err).Errorf("Failed evaluating tag rules for: %+v", t)
+			report.torrent(reportFailed, t, "", err.Error())
+			runOutcome.record(0, 1)
 			continue
 		}
 
@@ -46,6 +61,7 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 		if !shouldTakeAction {
 			// torrent did not meet any tag rule conditions
 			log.Tracef("No tag actions for %s: %s", h, t.Name)
+			report.torrent(reportKept, t, "", "no tag changes")
 			ignoredTorrents++
 			continue
 		}
@@ -67,11 +83,6 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 
 		limitKb := t.UpLimit
 
-		// retag
-		if !t.APIDividerPrinted {
-			log.Info("-----")
-		}
-
 		// Convert final tags map to slice for display and API calls
 		finalTagsSlice := make([]string, 0, len(finalTags))
 		for tag := range finalTags {
@@ -92,9 +103,23 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 			}
 		}
 
-		log.Infof("Actions for: %q - %s", t.Name, strings.Join(actionLogs, " | "))
-		log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
-			"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
+		if flagDryRun {
+			// a concise diff per torrent is enough to validate tag rules
+			log.Infof("%s: %s", t.Name, retagDiff(t, retagInfo))
+			for tag := range retagInfo.Add {
+				tagsAdded[tag]++
+			}
+			for tag := range retagInfo.Remove {
+				tagsRemoved[tag]++
+			}
+		} else {
+			if !t.APIDividerPrinted {
+				log.Info("-----")
+			}
+			log.Infof("Actions for: %q - %s", t.Name, strings.Join(actionLogs, " | "))
+			log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
+				"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
+		}
 
 		actionTaken := false
 		actionFailed := false
@@ -159,13 +184,21 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 			}
 
 			if actionFailed {
+				report.torrent(reportFailed, t, "", retagDiff(t, retagInfo))
 				errorRetaggedTorrents++
 			} else if actionTaken {
 				log.Info("Actions applied successfully.")
+				history.Record(history.Entry{Client: client, Action: history.ActionRetag, Hash: t.Hash, Name: t.Name,
+					Tracker: t.TrackerName, Detail: strings.Join(actionLogs, " | ")})
 			}
+		}
 
-		} else {
-			log.Warn("Dry-run enabled, skipping actions...")
+		// keep the torrent up to date for the steps of a pipeline following the retag
+		if !actionFailed && (actionTaken || flagDryRun) {
+			report.torrent(reportRetagged, t, "", retagDiff(t, retagInfo))
+			t.Tags = finalTags
+			t.UpLimit = limitKb
+			torrents[h] = t
 		}
 
 		// don't check for shouldTakeAction again as it can't be false
@@ -183,6 +216,12 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 	log.Info("-----")
 	log.Infof("Ignored torrents: %d", ignoredTorrents)
 	log.Infof("Retagged torrents: %d, %d failures", retaggedTorrents, errorRetaggedTorrents)
+	if flagDryRun {
+		for _, line := range retagSummary(tagsAdded, tagsRemoved) {
+			log.Info(line)
+		}
+	}
+	runOutcome.record(retaggedTorrents, errorRetaggedTorrents)
 
 	if !noti.CanSend() {
 		log.Debug("Notifications disabled, skipping...")
@@ -204,8 +243,253 @@ func retagEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.TagI
 	return nil
 }
 
+// retagDiff describes the changes of a retag concisely, e.g. +tag1 -tag2 upLimit 5000→unlimited (in KiB/s)
+func retagDiff(t config.Torrent, info client.RetagInfo) string {
+	var parts []string
+	for _, tag := range slices.Sorted(maps.Keys(info.Add)) {
+		parts = append(parts, "+"+tag)
+	}
+	for _, tag := range slices.Sorted(maps.Keys(info.Remove)) {
+		parts = append(parts, "-"+tag)
+	}
+
+	if info.UploadKb != nil {
+		current := t.UpLimit / 1024
+		if t.UpLimit <= 0 {
+			current = -1
+		}
+		parts = append(parts, fmt.Sprintf("upLimit %s→%s", formatUploadLimit(current), formatUploadLimit(*info.UploadKb)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatUploadLimit formats an upload limit in KiB/s, -1 is unlimited
+func formatUploadLimit(kb int64) string {
+	if kb < 0 {
+		return "unlimited"
+	}
+	return strconv.FormatInt(kb, 10)
+}
+
+// retagSummary returns a line per tag with the number of torrents it would be added to and removed from
+func retagSummary(added map[string]int, removed map[string]int) []string {
+	tags := make(map[string]struct{}, len(added)+len(removed))
+	for tag := range added {
+		tags[tag] = struct{}{}
+	}
+	for tag := range removed {
+		tags[tag] = struct{}{}
+	}
+
+	lines := make([]string, 0, len(tags))
+	for _, tag := range slices.Sorted(maps.Keys(tags)) {
+		lines = append(lines, fmt.Sprintf("Tag %q: +%d -%d", tag, added[tag], removed[tag]))
+	}
+	return lines
+}
+
+// set share limits of torrents that meet seed limit filters
+func setShareLimitsForEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.ShareLimitInterface, torrents map[string]config.Torrent, noti notification.Sender, client string, startTime time.Time) error {
+	// vars
+	var (
+		ignoredTorrents    int
+		limitedTorrents    int
+		errorLimitTorrents int
+
+		fields []notification.Field
+	)
+
+	// iterate torrents
+	for h, t := range torrents {
+		// should we set share limits?
+		limits, err := c.ShouldSetShareLimits(ctx, &t)
+		if err != nil {
+			// error while determining whether to set share limits
+			log.WithError(err).Errorf("Failed evaluating seed limit rules for: %+v", t)
+			runOutcome.record(0, 1)
+			continue
+		} else if limits == nil {
+			// torrent did not meet any seed limit rule or already has the limits applied
+			log.Tracef("No seed limit actions for %s: %s", h, t.Name)
+			ignoredTorrents++
+			continue
+		}
+
+		// set share limits
+		if !t.APIDividerPrinted {
+			log.Info("-----")
+		}
+
+		log.Infof("Setting share limits for: %q - %s (ratio: %.2f / seeding time: %d min / inactive seeding time: %d min)",
+			t.Name, limits.Name, limits.RatioLimit, limits.SeedingTimeLimit, limits.InactiveSeedingTimeLimit)
+		log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
+			"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
+
+		if !flagDryRun {
+			if err := c.SetShareLimits(ctx, t.Hash, *limits); err != nil {
+				log.WithError(err).Errorf("Failed setting share limits for torrent: %+v", t)
+				errorLimitTorrents++
+				continue
+			}
+
+			log.Info("Set share limits")
+		} else {
+			log.Warn("Dry-run enabled, skipping share limits...")
+		}
+
+		fields = append(fields, noti.BuildField(notification.ActionShareLimit, notification.BuildOptions{
+			Torrent:               t,
+			NewRatioLimit:         limits.RatioLimit,
+			NewSeedingTimeLimit:   limits.SeedingTimeLimit,
+			NewShareLimitRuleName: limits.Name,
+		}))
+		limitedTorrents++
+	}
+
+	// show result
+	log.Info("-----")
+	log.Infof("Ignored torrents: %d", ignoredTorrents)
+	log.Infof("Share limited torrents: %d, %d failures", limitedTorrents, errorLimitTorrents)
+	runOutcome.record(limitedTorrents, errorLimitTorrents)
+
+	if !noti.CanSend() {
+		log.Debug("Notifications disabled, skipping...")
+		return nil
+	}
+
+	sendErr := noti.Send(
+		"Torrent Share Limits",
+		fmt.Sprintf("Set share limits for **%d** torrent(s)", limitedTorrents),
+		client,
+		time.Since(startTime),
+		fields,
+		flagDryRun,
+	)
+	if sendErr != nil {
+		log.WithError(sendErr).Error("Failed sending notification")
+	}
+
+	return nil
+}
+
+// normalizeHash validates an info hash (e.g. from --hash) and returns it lowercased
+func normalizeHash(hash string) (string, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if !isValidHash(hash) {
+		return "", fmt.Errorf("invalid torrent hash: %q", hash)
+	}
+
+	return hash, nil
+}
+
+// readHashes reads info hashes from r, one per line. Empty lines and lines starting with # are skipped.
+func readHashes(r io.Reader) ([]string, error) {
+	var hashes []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, err := normalizeHash(line)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read hashes: %w", err)
+	}
+
+	return hashes, nil
+}
+
+// resolveTargetHashes combines the hashes given via --hash and --hashes-file ("-" for stdin)
+func resolveTargetHashes() ([]string, error) {
+	var hashes []string
+
+	if flagHash != "" {
+		hash, err := normalizeHash(flagHash)
+		if err != nil {
+			return nil, err
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	if flagHashesFile != "" {
+		var r io.Reader = os.Stdin
+		if flagHashesFile != "-" {
+			f, err := os.Open(flagHashesFile)
+			if err != nil {
+				return nil, fmt.Errorf("open hashes file: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		fileHashes, err := readHashes(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fileHashes) == 0 {
+			return nil, fmt.Errorf("no hashes found in: %q", flagHashesFile)
+		}
+
+		hashes = append(hashes, fileHashes...)
+	}
+
+	slices.Sort(hashes)
+	return slices.Compact(hashes), nil
+}
+
+// scopeTorrentsToHashes narrows torrents down to the torrents matching hashes, also returning the hashes not found
+func scopeTorrentsToHashes(torrents map[string]config.Torrent, hashes []string) (map[string]config.Torrent, []string) {
+	var (
+		scoped  = make(map[string]config.Torrent, len(hashes))
+		missing []string
+	)
+
+	for _, hash := range hashes {
+		t, ok := torrents[hash]
+		if !ok {
+			missing = append(missing, hash)
+			continue
+		}
+
+		scoped[hash] = t
+	}
+
+	return scoped, missing
+}
+
+// scopeTorrents narrows torrents down to the targeted hashes (if any), exiting when none of them were found
+func scopeTorrents(log *logrus.Entry, torrents map[string]config.Torrent, hashes []string) map[string]config.Torrent {
+	if len(hashes) == 0 {
+		return torrents
+	}
+
+	scoped, missing := scopeTorrentsToHashes(torrents, hashes)
+	for _, hash := range missing {
+		log.Warnf("Torrent not found in client: %s", hash)
+	}
+
+	if len(scoped) == 0 {
+		log.Fatal("None of the targeted torrents were found in client")
+	}
+
+	log.Infof("Scoped to %d of %d torrents", len(scoped), len(torrents))
+	return scoped
+}
+
 // relabel torrent that meet required filters
-func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent, tfm *torrentfilemap.TorrentFileMap, noti notification.Sender, client string, startTime time.Time) error {
+func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent, tfm *torrentfilemap.TorrentFileMap, relabelCrossSeeds bool, noti notification.Sender, client string, startTime time.Time) error {
 	// vars
 	var (
 		ignoredTorrents      int
@@ -223,25 +507,30 @@ func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.In
 		if err != nil {
 			// error while determining whether to relabel torrent
 			log.WithError(err).Errorf("Failed determining whether to relabel: %+v", t)
+			report.torrent(reportFailed, t, "", err.Error())
+			runOutcome.record(0, 1)
 			continue
 		} else if !relabel {
 			// torrent did not meet the relabel filters
 			log.Tracef("Not relabeling %s: %s", h, t.Name)
+			report.torrent(reportKept, t, "", "no label rule matched")
 			ignoredTorrents++
 			continue
 		} else if label == t.Label {
 			// torrent already has the correct label
 			log.Tracef("Torrent already has correct label: %s", t.Name)
+			report.torrent(reportKept, t, "", fmt.Sprintf("already labeled %s", label))
 			ignoredTorrents++
 			continue
 		}
 
 		hardlink := false
 		if !tfm.IsUnique(t) {
-			if !flagExperimentalRelabelForCrossSeeds {
+			if !relabelCrossSeeds {
 				// torrent file is not unique, files are contained within another torrent
 				// so we cannot safely change the label in-case of auto move
 				nonUniqueTorrents++
+				report.torrent(reportKept, t, "", fmt.Sprintf("not unique, not relabeled to %s", label))
 				log.Warnf("Skipping non unique torrent | Name: %s / Label: %s / Tags: %s / Tracker: %s", t.Name, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName)
 				continue
 			}
@@ -265,11 +554,14 @@ func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.In
 		if !flagDryRun {
 			if err := c.SetTorrentLabel(ctx, t.Hash, label, hardlink); err != nil {
 				log.WithError(err).Fatalf("Failed relabeling torrent: %+v", t)
+				report.torrent(reportFailed, t, "", err.Error())
 				errorRelabelTorrents++
 				continue
 			}
 
 			log.Info("Relabeled")
+			history.Record(history.Entry{Client: client, Action: history.ActionRelabel, Hash: t.Hash, Name: t.Name,
+				Tracker: t.TrackerName, Detail: fmt.Sprintf("label: %s → %s", t.Label, label)})
 			time.Sleep(5 * time.Second)
 		} else {
 			log.Warn("Dry-run enabled, skipping relabel...")
@@ -279,7 +571,12 @@ func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.In
 			Torrent:  t,
 			NewLabel: label,
 		}))
+		report.torrent(reportRelabeled, t, "", fmt.Sprintf("label: %s → %s", t.Label, label))
 		relabeledTorrents++
+
+		// keep the torrent up to date for the steps of a pipeline following the relabel
+		t.Label = label
+		torrents[h] = t
 	}
 
 	// show result
@@ -289,6 +586,7 @@ func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.In
 		log.Infof("Non-unique torrents: %d", nonUniqueTorrents)
 	}
 	log.Infof("Relabeled torrents: %d, %d failures", relabeledTorrents, errorRelabelTorrents)
+	runOutcome.record(relabeledTorrents, errorRelabelTorrents)
 
 	if !noti.CanSend() {
 		log.Debug("Notifications disabled, skipping...")
@@ -310,16 +608,34 @@ func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.In
 	return nil
 }
 
-// remove torrents that meet remove filters
-func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent, tfm *torrentfilemap.TorrentFileMap, hfm hardlinkfilemap.HardlinkFileMapI, filter *config.FilterConfiguration, noti notification.Sender, client string, startTime time.Time) error {
+// remove torrents that meet remove filters, returning the hashes of the removed torrents (none in dry-run). The ignore
+// and remove filters are not evaluated for the bypassed hashes (--bypass-filters), they are removed as listed
+func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent, tfm *torrentfilemap.TorrentFileMap, hfm hardlinkfilemap.HardlinkFileMapI, filter *config.FilterConfiguration, bypassed map[string]bool, caps removalCaps, extracted *extractedArchives, kept *keptFiles, journal *cleanJournal, archiver *torrentArchiver, tagger *dryRunTagger, noti notification.Sender, client string, startTime time.Time) ([]string, error) {
 	// vars
 	var (
 		ignoredTorrents     int
 		hardRemoveTorrents  int
 		errorRemoveTorrents int
+		cappedTorrents      int
+		cooldownTorrents    int
+		vetoedTorrents      int
 		removedTorrentBytes int64
+		freedBytes          int64
+		freedInodes         int64
+		removedHashes       []string
 	)
 
+	// the free space is tracked locally as it is not updated in dry-run
+	freeSpace := int64(c.GetFreeSpace() * humanize.GiByte)
+	if caps.freeSpaceTarget > 0 {
+		log.Infof("Removing torrents until the free space reaches %s (currently %s), lowest score first: %s",
+			formatting.Bytes(uint64(caps.freeSpaceTarget)), formatting.Bytes(uint64(freeSpace)), caps.score.Text)
+	}
+	if caps.freeInodesTarget > 0 {
+		log.Infof("Removing torrents until the free inodes reach %d (currently %d), lowest score first: %s",
+			caps.freeInodesTarget, caps.freeInodes, caps.score.Text)
+	}
+
 	deleteData := true
 	if filter != nil && filter.DeleteData != nil {
 		deleteData = *filter.DeleteData
@@ -329,6 +645,35 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 
 	// helper function to remove torrent
 	removeTorrent := func(ctx context.Context, h string, t *config.Torrent, reason string, isHardlinked bool, isUnique bool, isNotUniqueUnregistered bool) bool {
+		// If complete, use total size; otherwise use downloaded bytes (fallback to total if needed).
+		sizeBytes := t.DownloadedBytes
+		sizeEstimated := true
+		if t.Downloaded && t.TotalBytes > 0 {
+			sizeBytes = t.TotalBytes
+			sizeEstimated = false
+		} else if sizeBytes == 0 && t.TotalBytes > 0 {
+			sizeBytes = t.TotalBytes
+			sizeEstimated = false
+		}
+
+		// keep the torrent once the removal caps are reached
+		if !caps.allows(hardRemoveTorrents, removedTorrentBytes, sizeBytes) || caps.reached(freeSpace+freedBytes, caps.freeInodes+freedInodes) {
+			log.Debugf("Removal cap reached, keeping torrent: %q", t.Name)
+			report.torrent(reportKept, *t, reason, "removal cap reached")
+			cappedTorrents++
+			delete(torrents, h)
+			return false
+		}
+
+		// keep the torrent once the cooldown of its remove expression is reached, it is removed by a later run
+		if !caps.cooldowns.allows(reason, sizeBytes, time.Now()) {
+			log.Debugf("Cooldown of the remove expression reached, keeping torrent: %q", t.Name)
+			report.torrent(reportKept, *t, reason, "cooldown reached")
+			cooldownTorrents++
+			delete(torrents, h)
+			return false
+		}
+
 		// Log removal details
 		if !t.APIDividerPrinted {
 			log.Info("-----")
@@ -343,17 +688,7 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 			logMsg = "removing: %q - %s"
 		}
 
-		// If complete, use total size; otherwise use downloaded bytes (fallback to total if needed).
-		sizeBytes := t.DownloadedBytes
-		sizeEstimated := true
-		if t.Downloaded && t.TotalBytes > 0 {
-			sizeBytes = t.TotalBytes
-			sizeEstimated = false
-		} else if sizeBytes == 0 && t.TotalBytes > 0 {
-			sizeBytes = t.TotalBytes
-			sizeEstimated = false
-		}
-		sizeStr := humanize.IBytes(uint64(sizeBytes))
+		sizeStr := formatting.Bytes(uint64(sizeBytes))
 		if sizeEstimated {
 			sizeStr += " (ESTIMATE)"
 		}
@@ -370,9 +705,6 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 		log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
 			"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
 
-		// update the hardlink map before removing the torrent
-		hfm.RemoveByTorrent(*t)
-
 		// Determine whether to delete data
 		localDeleteData := deleteData
 
@@ -381,38 +713,89 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 			localDeleteData = false
 		}
 
+		// keep the torrent when a pre_remove hook vetoes its removal
+		if vetoed, vetoReason := hooks.Veto(ctx, config.Config.Hooks.PreRemove, hooks.Event{Action: hooks.ActionRemove,
+			Client: client, Hash: t.Hash, Name: t.Name, Path: t.Path, Tracker: t.TrackerName, Label: t.Label,
+			Reason: reason, Size: sizeBytes, DataDeleted: localDeleteData, DryRun: flagDryRun}); vetoed {
+			log.Infof("Removal vetoed by pre_remove hook, keeping torrent: %q (%s)", t.Name, vetoReason)
+			report.torrent(reportKept, *t, reason, fmt.Sprintf("vetoed by pre_remove hook: %s", vetoReason))
+			vetoedTorrents++
+			delete(torrents, h)
+			return false
+		}
+
+		// keep the torrent when it could not be archived, it could not be re-added otherwise
+		if archiver != nil && !flagDryRun {
+			if err := archiver.archive(ctx, *t); err != nil {
+				log.WithError(err).Errorf("Failed archiving torrent, skipping removal: %q", t.Name)
+				report.torrent(reportFailed, *t, reason, fmt.Sprintf("archive: %v", err))
+				delete(torrents, h)
+				errorRemoveTorrents++
+				return false
+			}
+			log.Debugf("Archived torrent to %q", archiver.dir)
+		}
+
+		// update the hardlink map before removing the torrent
+		hfm.RemoveByTorrent(*t)
+
+		// the files matching keep_files are kept, the client then only removes the torrent
+		reclaimed, reclaimedFiles := sizeBytes, int64(len(t.Files))
+		keepFiles := localDeleteData && kept != nil
+
 		if !flagDryRun {
 			// Do remove
-			removed, err := c.RemoveTorrent(ctx, t, localDeleteData)
+			removed, err := c.RemoveTorrent(ctx, t, localDeleteData && !keepFiles)
 			if err != nil {
 				log.WithError(err).Errorf("Failed removing torrent: %+v", t)
+				report.torrent(reportFailed, *t, reason, err.Error())
 				// don't remove from torrents file map, but prevent further operations on this torrent
 				delete(torrents, h)
 				errorRemoveTorrents++
 				return false
 			} else if !removed {
 				log.Error("Failed removing torrent...")
+				report.torrent(reportFailed, *t, reason, "not removed by the client")
 				// don't remove from torrents file map, but prevent further operations on this torrent
 				delete(torrents, h)
 				errorRemoveTorrents++
 				return false
 			} else {
-				if localDeleteData {
+				entry := history.Entry{Client: client, Action: history.ActionRemove, Hash: t.Hash, Name: t.Name,
+					Tracker: t.TrackerName, Reason: reason}
+				if keepFiles {
+					reclaimed, reclaimedFiles = kept.remove(log, *t)
+					log.Info("Removed with data (kept files matching keep_files)")
+					entry.Reclaimed = reclaimed
+					entry.Detail = "kept files matching keep_files"
+				} else if localDeleteData {
 					log.Info("Removed with data")
+					entry.Reclaimed = sizeBytes
 				} else {
 					log.Info("Removed (kept data on disk)")
+					entry.Detail = "kept data on disk"
 				}
+				history.Record(entry)
+				hooks.Run(ctx, config.Config.Hooks.PostRemove, hooks.Event{Action: hooks.ActionRemove, Client: client,
+					Hash: t.Hash, Name: t.Name, Path: t.Path, Tracker: t.TrackerName, Label: t.Label, Reason: reason,
+					Size: sizeBytes, DataDeleted: localDeleteData})
+				removedHashes = append(removedHashes, h)
+				journal.record(h, journalRemoved, reason)
 
 				// increase free space if we removed data
 				if localDeleteData && t.FreeSpaceSet {
-					log.Tracef("Increasing free space by: %s", humanize.IBytes(uint64(sizeBytes)))
-					c.AddFreeSpace(sizeBytes)
+					log.Tracef("Increasing free space by: %s", formatting.Bytes(uint64(reclaimed)))
+					c.AddFreeSpace(reclaimed)
 					log.Tracef("New free space: %.2f GB", c.GetFreeSpace())
 				}
 
 				time.Sleep(1 * time.Second)
 			}
 		} else {
+			if keepFiles {
+				reclaimed, reclaimedFiles = kept.remove(log, *t)
+			}
+			tagger.mark(h)
 			log.Warnf("Dry-run enabled, skipping remove (would delete data: %t)...", localDeleteData)
 		}
 
@@ -420,14 +803,29 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 			Torrent:       *t,
 			RemovalReason: reason,
 		}))
+		report.torrent(reportRemoved, *t, reason, removalDetail(localDeleteData, keepFiles))
+
+		if err := caps.cooldowns.record(reason, sizeBytes, time.Now()); err != nil {
+			log.WithError(err).Warn("Failed recording removal for the cooldown of its remove expression")
+		}
+		caps.pacing.removed(ctx, log, t.TrackerName)
 
 		// increased hard removed counters
 		removedTorrentBytes += sizeBytes
 		hardRemoveTorrents++
+		if localDeleteData {
+			freedBytes += reclaimed
+			freedInodes += reclaimedFiles
+		}
 
 		// remove the torrent from the torrent maps
 		tfm.Remove(*t)
 		delete(torrents, h)
+
+		// remove the content extracted next to its archives along with its data
+		if localDeleteData {
+			extracted.remove(log, *t, kept)
+		}
 		return true
 	}
 
@@ -435,37 +833,80 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 	hardlinkedCandidates := make(map[string]config.Torrent)
 	fileOverlapCandidates := make(map[string]config.Torrent)
 	candidateReasons := make(map[string]string)
-	for h, t := range torrents {
-		// should we ignore this torrent?
-		ignore, reason, err := c.ShouldIgnore(ctx, &t)
-		if err != nil {
-			// error while determining whether to ignore torrent
-			log.WithError(err).Errorf("Failed determining whether to ignore: %+v", t)
-			delete(torrents, h)
-			continue
-		} else if ignore && !(config.Config.BypassIgnoreIfUnregistered && t.IsUnregistered(ctx)) {
-			// torrent met ignore filter
-			if reason != "" {
-				log.Tracef("Ignoring torrent %s: %s (reason: %s)", h, t.Name, reason)
-			} else {
-				log.Tracef("Ignoring torrent %s: %s", h, t.Name)
+	order, err := caps.order(ctx, torrents)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range order {
+		t := torrents[h]
+
+		reason := bypassFiltersReason
+		if decision, ok := journal.decision(h); ok {
+			// evaluated by the interrupted run
+			switch decision.Action {
+			case journalIgnore:
+				log.Tracef("Ignoring torrent %s: %s (resumed)", h, t.Name)
+				report.torrent(reportIgnored, t, decision.Reason, "resumed")
+				delete(torrents, h)
+				ignoredTorrents++
+				continue
+			case journalKeep:
+				log.Tracef("Not removing %s: %s (resumed)", h, t.Name)
+				report.torrent(reportKept, t, "", "resumed")
+				continue
+			}
+			reason = decision.Reason
+		} else if !bypassed[h] {
+			var (
+				ignore bool
+				err    error
+			)
+
+			// should we ignore this torrent?
+			ignore, reason, err = c.ShouldIgnore(ctx, &t)
+			if err != nil {
+				// error while determining whether to ignore torrent
+				log.WithError(err).Errorf("Failed determining whether to ignore: %+v", t)
+				report.torrent(reportFailed, t, "", err.Error())
+				runOutcome.record(0, 1)
+				delete(torrents, h)
+				continue
+			} else if ignore && !(config.Config.BypassIgnoreIfUnregistered && t.IsUnregistered(ctx)) {
+				// torrent met ignore filter
+				if reason != "" {
+					log.Tracef("Ignoring torrent %s: %s (reason: %s)", h, t.Name, reason)
+				} else {
+					log.Tracef("Ignoring torrent %s: %s", h, t.Name)
+				}
+				delete(torrents, h)
+				ignoredTorrents++
+				journal.record(h, journalIgnore, reason)
+				report.torrent(reportIgnored, t, reason, "")
+				continue
+			}
+
+			// should we remove this torrent?
+			var remove bool
+			remove, reason, err = c.ShouldRemoveWithReason(ctx, &t)
+			if err != nil {
+				log.WithError(err).Errorf("Failed determining whether to remove: %+v", t)
+				report.torrent(reportFailed, t, "", err.Error())
+				runOutcome.record(0, 1)
+				// dont do any further operations on this torrent, but keep in the torrent file map
+				delete(torrents, h)
+				continue
+			} else if !remove {
+				// torrent did not meet the remove filters
+				log.Tracef("Not removing %s: %s", h, t.Name)
+				journal.record(h, journalKeep, "")
+				report.torrent(reportKept, t, "", "no remove filter matched")
+				continue
 			}
-			delete(torrents, h)
-			ignoredTorrents++
-			continue
 		}
 
-		// should we remove this torrent?
-		remove, reason, err := c.ShouldRemoveWithReason(ctx, &t)
-		if err != nil {
-			log.WithError(err).Errorf("Failed determining whether to remove: %+v", t)
-			// dont do any further operations on this torrent, but keep in the torrent file map
-			delete(torrents, h)
-			continue
-		} else if !remove {
-			// torrent did not meet the remove filters
-			log.Tracef("Not removing %s: %s", h, t.Name)
-			continue
+		// torrents are removed with the same reason when the run is resumed
+		if _, ok := journal.decision(h); !ok {
+			journal.record(h, journalRemove, reason)
 		}
 
 		// torrent meets the remove filters
@@ -536,11 +977,17 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 	removedCandidates := 0
 	removedFileOverlapCandidates := 0
 	removedHardlinkedCandidates := 0
-	for h, t := range fileOverlapCandidates {
+	fileOverlapOrder, err := caps.order(ctx, fileOverlapCandidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range fileOverlapOrder {
+		t := fileOverlapCandidates[h]
 		noInstances := tfm.NoInstances(t) && hfm.NoInstances(t)
 
 		if !noInstances {
 			log.Tracef("%s still not unique", t.Name)
+			report.torrent(reportKept, t, candidateReasons[h], "not unique (file overlap)")
 			continue
 		}
 
@@ -552,11 +999,17 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 	}
 
 	// Process hardlinked candidates - these can be removed with data deletion
-	for h, t := range hardlinkedCandidates {
+	hardlinkedOrder, err := caps.order(ctx, hardlinkedCandidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hardlinkedOrder {
+		t := hardlinkedCandidates[h]
 		noInstances := tfm.NoInstances(t) && hfm.NoInstances(t)
 
 		if !noInstances {
 			log.Tracef("%s still not unique", t.Name)
+			report.torrent(reportKept, t, candidateReasons[h], "not unique (hardlinked)")
 			continue
 		}
 
@@ -567,7 +1020,7 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 		}
 	}
 
-	reclaimedSpace := humanize.IBytes(uint64(removedTorrentBytes))
+	reclaimedSpace := formatting.Bytes(uint64(removedTorrentBytes))
 
 	// show result
 	log.Info("-----")
@@ -588,14 +1041,28 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 	log.WithField("reclaimed_space", reclaimedSpace).
 		Infof("Removed torrents: %d total (%d unique, %d hardlinked, %d file overlap)", hardRemoveTorrents, uniqueRemoved, removedHardlinkedCandidates, removedFileOverlapCandidates)
 
+	// Show torrents kept because of the removal caps if any
+	if cappedTorrents > 0 {
+		log.Warnf("Removal caps or free space target reached, kept %d torrent(s) matching the remove filters", cappedTorrents)
+	}
+	if cooldownTorrents > 0 {
+		log.Warnf("Cooldowns reached, kept %d torrent(s) matching the remove filters", cooldownTorrents)
+	}
+
+	// Show torrents kept by pre_remove hooks if any
+	if vetoedTorrents > 0 {
+		log.Warnf("Kept %d torrent(s) vetoed by pre_remove hooks", vetoedTorrents)
+	}
+
 	// Show failures if any
 	if errorRemoveTorrents > 0 {
 		log.Infof("Failures: %d torrents failed to remove", errorRemoveTorrents)
 	}
+	runOutcome.record(hardRemoveTorrents, errorRemoveTorrents)
 
 	if !noti.CanSend() {
 		log.Debug("Notifications disabled, skipping...")
-		return nil
+		return removedHashes, nil
 	}
 
 	sendErr := noti.Send(
@@ -609,5 +1076,5 @@ func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Int
 	if sendErr != nil {
 		log.WithError(sendErr).Error("Failed sending notification")
 	}
-	return nil
+	return removedHashes, nil
 }
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestReadHashes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "one_per_line",
+			input:    "0123456789abcdef0123456789abcdef01234567\nfedcba9876543210fedcba9876543210fedcba98\n",
+			expected: []string{"0123456789abcdef0123456789abcdef01234567", "fedcba9876543210fedcba9876543210fedcba98"},
+		},
+		{
+			name:     "skips_blank_lines_and_comments",
+			input:    "# from cross-seed\n\n  0123456789ABCDEF0123456789ABCDEF01234567  \n",
+			expected: []string{"0123456789abcdef0123456789abcdef01234567"},
+		},
+		{
+			name:    "invalid_hash",
+			input:   "not-a-hash\n",
+			wantErr: true,
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, err := readHashes(strings.NewReader(tt.input))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hashes)
+		})
+	}
+}
+
+func TestScopeTorrentsToHashes(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", Name: "torrent a"},
+		"b": {Hash: "b", Name: "torrent b"},
+		"c": {Hash: "c", Name: "torrent c"},
+	}
+
+	scoped, missing := scopeTorrentsToHashes(torrents, []string{"a", "c", "d"})
+
+	assert.Equal(t, map[string]config.Torrent{
+		"a": {Hash: "a", Name: "torrent a"},
+		"c": {Hash: "c", Name: "torrent c"},
+	}, scoped)
+	assert.Equal(t, []string{"d"}, missing)
+}
+
+func TestRetagDiff(t *testing.T) {
+	limit := func(kb int64) *int64 { return &kb }
+
+	tests := []struct {
+		name     string
+		torrent  config.Torrent
+		info     client.RetagInfo
+		expected string
+	}{
+		{
+			name:    "tags",
+			torrent: config.Torrent{},
+			info: client.RetagInfo{
+				Add:    map[string]struct{}{"b": {}, "a": {}},
+				Remove: map[string]struct{}{"c": {}},
+			},
+			expected: "+a +b -c",
+		},
+		{
+			name:     "limit to unlimited",
+			torrent:  config.Torrent{UpLimit: 5000 * 1024},
+			info:     client.RetagInfo{Add: map[string]struct{}{"slow": {}}, UploadKb: limit(-1)},
+			expected: "+slow upLimit 5000→unlimited",
+		},
+		{
+			name:     "unlimited to limit",
+			torrent:  config.Torrent{UpLimit: -1},
+			info:     client.RetagInfo{UploadKb: limit(100)},
+			expected: "upLimit unlimited→100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retagDiff(tt.torrent, tt.info))
+		})
+	}
+}
+
+func TestRetagSummary(t *testing.T) {
+	lines := retagSummary(map[string]int{"b": 2, "a": 1}, map[string]int{"b": 1, "c": 3})
+
+	assert.Equal(t, []string{`Tag "a": +1 -0`, `Tag "b": +2 -1`, `Tag "c": +0 -3`}, lines)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+var (
+	flagHistoryClient string
+	flagHistoryAction string
+	flagHistorySince  time.Duration
+	flagHistorySearch string
+	flagHistoryLimit  int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the actions taken by previous runs",
+	Long: `This command prints the torrents removed, relabeled and retagged and the orphans deleted by previous runs (dry runs are not recorded),
+including when, why (the matched expression) and how much space was reclaimed. The history is kept in history.jsonl next to the config file.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("history")
+
+		q := history.Query{
+			Client: flagHistoryClient,
+			Action: history.Action(flagHistoryAction),
+			Search: flagHistorySearch,
+		}
+		switch q.Action {
+		case "", history.ActionRemove, history.ActionRelabel, history.ActionRetag, history.ActionOrphan,
+			history.ActionPruneFiles:
+		default:
+			log.Fatalf("Unsupported action: %q (supported: remove, relabel, retag, orphan, prune-files)", flagHistoryAction)
+		}
+		if flagHistorySince > 0 {
+			q.Since = time.Now().Add(-flagHistorySince)
+		}
+
+		entries, err := history.Read(history.Path(), q)
+		if err != nil {
+			log.WithError(err).Fatal("Failed reading history")
+		}
+
+		// keep the most recent entries
+		if flagHistoryLimit > 0 && len(entries) > flagHistoryLimit {
+			entries = entries[len(entries)-flagHistoryLimit:]
+		}
+
+		if flagOutput == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(entries); err != nil {
+				log.WithError(err).Fatal("Failed encoding history")
+			}
+			return
+		}
+
+		if err := writeHistoryTable(os.Stdout, entries); err != nil {
+			log.WithError(err).Fatal("Failed writing history")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&flagHistoryClient, "client", "", "Only show actions of this client")
+	historyCmd.Flags().StringVar(&flagHistoryAction, "action", "", "Only show this action (remove, relabel, retag, orphan, prune-files)")
+	historyCmd.Flags().DurationVar(&flagHistorySince, "since", 0, "Only show actions taken within this duration, e.g. 24h")
+	historyCmd.Flags().StringVar(&flagHistorySearch, "search", "", "Only show actions whose name, hash, tracker or reason contains this text")
+	historyCmd.Flags().IntVar(&flagHistoryLimit, "limit", 0, "Only show the most recent actions (0 for all)")
+}
+
+func writeHistoryTable(w io.Writer, entries []history.Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	var reclaimed int64
+	fmt.Fprintln(tw, "TIME\tCLIENT\tACTION\tNAME\tREASON\tRECLAIMED\t")
+	for _, e := range entries {
+		reason := e.Reason
+		if e.Detail != "" {
+			if reason != "" {
+				reason += ", "
+			}
+			reason += e.Detail
+		}
+
+		size := ""
+		if e.Reclaimed > 0 {
+			size = formatting.Bytes(uint64(e.Reclaimed))
+			reclaimed += e.Reclaimed
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", e.Time.Local().Format(time.DateTime), e.Client, e.Action, e.Name,
+			reason, size)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "%d action(s), %s reclaimed\n", len(entries), formatting.Bytes(uint64(reclaimed)))
+
+	return tw.Flush()
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/inventory"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+var flagInventoryLimit int
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Snapshot the files of the download path and compare snapshots over time",
+	Long: `Snapshots list every file of the download path of a client with its size and the torrents owning it, so snapshots taken
+over time show where disk space went, including files not found by the orphan command (e.g. files growing in place).
+Snapshots are kept compressed in inventory/<client> next to the config file, serve takes them every inventory.interval.`,
+}
+
+var inventorySnapshotCmd = &cobra.Command{
+	Use:   "snapshot [CLIENT]",
+	Short: "Take a snapshot of the files of the client's download path",
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("inventory")
+
+		// load client object
+		clientName := args[0]
+		c, _, clientConfig, err := loadClient(ctx, clientName, "")
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		downloadPath, err := tqm.ClientString("download_path", clientConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed determining client download path")
+		} else if downloadPath == nil || *downloadPath == "" {
+			log.Fatal("Client download path must be set")
+		}
+
+		mapping, err := tqm.DownloadPathMapping(clientConfig, "inventory", "orphan")
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		}
+		log.Infof("Retrieved %d torrents", len(torrents))
+
+		snapshot := inventory.Take(clientName, *downloadPath, torrents, mapping, time.Now())
+
+		dir := inventory.Dir(clientName)
+		path, err := inventory.Save(dir, snapshot)
+		if err != nil {
+			log.WithError(err).Fatal("Failed saving inventory snapshot")
+		}
+
+		var orphans int
+		for _, e := range snapshot.Entries {
+			if len(e.Torrents) == 0 {
+				orphans++
+			}
+		}
+		log.Infof("Saved snapshot of %d files (%s, %d not belonging to any torrent) to %q", len(snapshot.Entries),
+			formatting.Bytes(uint64(snapshot.Size())), orphans, path)
+
+		keep := config.Config.Inventory.WithDefaults().Keep
+		if removed, err := inventory.Prune(dir, keep); err != nil {
+			log.WithError(err).Warn("Failed removing old inventory snapshots")
+		} else if removed > 0 {
+			log.Infof("Removed %d old snapshot(s), keeping the newest %d", removed, keep)
+		}
+	},
+}
+
+var inventoryListCmd = &cobra.Command{
+	Use:   "list [CLIENT]",
+	Short: "List the snapshots of a client",
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("inventory")
+
+		snapshots, err := inventory.List(inventory.Dir(args[0]))
+		if err != nil {
+			log.WithError(err).Fatal("Failed listing inventory snapshots")
+		}
+
+		for _, s := range snapshots {
+			fmt.Println(filepath.Base(s))
+		}
+	},
+}
+
+var inventoryDiffCmd = &cobra.Command{
+	Use:   "diff [CLIENT] [OLD] [NEW]",
+	Short: "Compare two snapshots of a client",
+	Long: `This command compares two snapshots of a client, by default the two newest ones, and prints the change of size of
+every torrent and of the files not belonging to any torrent, followed by the largest files added, removed and resized.
+Snapshots are given by their name (see inventory list) or path, NEW defaults to the newest snapshot.`,
+
+	Args: cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("inventory")
+
+		oldPath, newPath, err := inventoryDiffPaths(inventory.Dir(args[0]), args[1:])
+		if err != nil {
+			log.WithError(err).Fatal("Failed selecting snapshots")
+		}
+
+		before, err := inventory.Load(oldPath)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading snapshot: %q", oldPath)
+		}
+		after, err := inventory.Load(newPath)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading snapshot: %q", newPath)
+		}
+
+		diff := inventory.Compare(before, after)
+
+		if flagOutput == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(diff); err != nil {
+				log.WithError(err).Fatal("Failed encoding inventory diff")
+			}
+			return
+		}
+
+		if err := writeInventoryDiff(os.Stdout, before, after, diff, flagInventoryLimit); err != nil {
+			log.WithError(err).Fatal("Failed writing inventory diff")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.AddCommand(inventorySnapshotCmd, inventoryListCmd, inventoryDiffCmd)
+
+	inventoryDiffCmd.Flags().IntVar(&flagInventoryLimit, "limit", 10, "Number of owners and files listed per section (0 for all)")
+}
+
+// inventoryDiffPaths returns the paths of the snapshots of dir to compare, args are the old and new snapshot given by
+// name or path, the newest snapshots are used for the ones not given
+func inventoryDiffPaths(dir string, args []string) (string, string, error) {
+	snapshots, err := inventory.List(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("list snapshots: %w", err)
+	}
+
+	resolve := func(arg string) string {
+		if _, err := os.Stat(arg); err == nil {
+			return arg
+		}
+		return filepath.Join(dir, arg)
+	}
+
+	switch len(args) {
+	case 2:
+		return resolve(args[0]), resolve(args[1]), nil
+	case 1:
+		if len(snapshots) == 0 {
+			return "", "", errors.New("no snapshots taken yet")
+		}
+		return resolve(args[0]), snapshots[len(snapshots)-1], nil
+	default:
+		if len(snapshots) < 2 {
+			return "", "", fmt.Errorf("%d snapshot(s) taken, at least 2 are needed", len(snapshots))
+		}
+		return snapshots[len(snapshots)-2], snapshots[len(snapshots)-1], nil
+	}
+}
+
+func writeInventoryDiff(w io.Writer, before *inventory.Snapshot, after *inventory.Snapshot, diff inventory.Diff,
+	limit int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Inventory of %s from %s to %s\n", after.Client, before.Taken.Local().Format(time.DateTime),
+		after.Taken.Local().Format(time.DateTime))
+	fmt.Fprintf(tw, "Total: %s → %s (%s)\n", formatting.Bytes(uint64(diff.OldSize)), formatting.Bytes(uint64(diff.NewSize)),
+		signedBytes(diff.NewSize-diff.OldSize))
+
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "%d owner(s) changed\n", len(diff.Owners))
+	if len(diff.Owners) > 0 {
+		fmt.Fprintln(tw, "OWNER\tOLD\tNEW\tCHANGE\t")
+		for _, o := range limited(diff.Owners, limit) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", o.Owner, formatting.Bytes(uint64(o.OldSize)),
+				formatting.Bytes(uint64(o.NewSize)), signedBytes(o.Delta()))
+		}
+	}
+
+	for _, section := range []struct {
+		name    string
+		changes []inventory.FileChange
+	}{
+		{"added", diff.Added},
+		{"removed", diff.Removed},
+		{"resized", diff.Resized},
+	} {
+		var delta int64
+		for _, c := range section.changes {
+			delta += c.Delta()
+		}
+
+		fmt.Fprintln(tw)
+		fmt.Fprintf(tw, "%d file(s) %s (%s)\n", len(section.changes), section.name, signedBytes(delta))
+		if len(section.changes) == 0 {
+			continue
+		}
+
+		fmt.Fprintln(tw, "PATH\tOWNER\tCHANGE\t")
+		for _, c := range limited(section.changes, limit) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\n", c.Path, c.Owner, signedBytes(c.Delta()))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// limited returns the first limit elements of s, all of them when limit is 0
+func limited[T any](s []T, limit int) []T {
+	if limit > 0 && len(s) > limit {
+		return s[:limit]
+	}
+	return s
+}
+
+// signedBytes formats a change of size with its sign
+func signedBytes(delta int64) string {
+	if delta < 0 {
+		return "-" + formatting.Bytes(uint64(-delta))
+	}
+	return "+" + formatting.Bytes(uint64(delta))
+}
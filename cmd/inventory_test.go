@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/inventory"
+)
+
+func TestInventoryDiffPaths(t *testing.T) {
+	dir := t.TempDir()
+
+	_, _, err := inventoryDiffPaths(dir, nil)
+	assert.ErrorContains(t, err, "0 snapshot(s) taken")
+
+	start := time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC)
+	var saved []string
+	for i := range 3 {
+		p, err := inventory.Save(dir, &inventory.Snapshot{Taken: start.Add(time.Duration(i) * 24 * time.Hour)})
+		require.NoError(t, err)
+		saved = append(saved, p)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantOld string
+		wantNew string
+	}{
+		{name: "newest two", wantOld: saved[1], wantNew: saved[2]},
+		{name: "old by name", args: []string{filepath.Base(saved[0])}, wantOld: saved[0], wantNew: saved[2]},
+		{name: "both by path", args: []string{saved[0], saved[1]}, wantOld: saved[0], wantNew: saved[1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldPath, newPath, err := inventoryDiffPaths(dir, tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOld, oldPath)
+			assert.Equal(t, tt.wantNew, newPath)
+		})
+	}
+}
+
+func TestWriteInventoryDiff(t *testing.T) {
+	before := &inventory.Snapshot{Client: "qbt", Entries: []inventory.Entry{{Path: "/log.txt", Size: 1024}}}
+	after := &inventory.Snapshot{Client: "qbt", Entries: []inventory.Entry{
+		{Path: "/log.txt", Size: 4096},
+		{Path: "/a.mkv", Size: 2048},
+		{Path: "/b.mkv", Size: 1024},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeInventoryDiff(&buf, before, after, inventory.Compare(before, after), 1))
+
+	out := buf.String()
+	assert.Contains(t, out, "Total: 1.0 KiB → 7.0 KiB (+6.0 KiB)")
+	assert.Contains(t, out, "2 file(s) added (+3.0 KiB)")
+	assert.Contains(t, out, "/a.mkv")
+	assert.NotContains(t, out, "/b.mkv", "files beyond the limit are not listed")
+	assert.Contains(t, out, "0 file(s) removed (+0 B)")
+}
+
+func TestRunArgs_Subcommand(t *testing.T) {
+	args := runArgs("inventory snapshot", "qbt", runRequest{})
+	assert.Equal(t, []string{"inventory", "snapshot", "qbt"}, args[:3])
+}
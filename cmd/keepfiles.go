@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/paths"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+// filePatterns match the files of torrents case-insensitively. Patterns containing a slash match the trailing folders
+// of the path relative to the save path of the torrent (e.g. sample/* matches movie/sample/a.mkv), others the file
+// name, patterns starting with ! exclude files again and the last matching pattern wins.
+type filePatterns []string
+
+// newFilePatterns validates the patterns of the setting
+func newFilePatterns(setting string, patterns []string) (filePatterns, error) {
+	parsed := make(filePatterns, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if _, err := path.Match(strings.TrimPrefix(p, "!"), ""); err != nil || strings.TrimPrefix(p, "!") == "" {
+			return nil, fmt.Errorf("invalid %s pattern: %q", setting, p)
+		}
+		parsed = append(parsed, p)
+	}
+
+	return parsed, nil
+}
+
+// match reports whether the file at rel, relative to the save path of its torrent, matches the patterns
+func (p filePatterns) match(rel string) bool {
+	rel = strings.ToLower(strings.ReplaceAll(rel, `\`, "/"))
+
+	matched := false
+	for _, pattern := range p {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		if matchPathSuffix(pattern, rel) {
+			matched = !negated
+		}
+	}
+
+	return matched
+}
+
+// matchPathSuffix reports whether pattern matches the name of the file at rel, or with a slash any trailing part of rel
+// starting at a folder
+func matchPathSuffix(pattern string, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	for {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+
+		_, rest, found := strings.Cut(rel, "/")
+		if !found {
+			return false
+		}
+		rel = rest
+	}
+}
+
+// torrentRelativePath returns the path of the file of t relative to its save path, the file name when the file is
+// outside of it
+func torrentRelativePath(t config.Torrent, file string) string {
+	file = strings.ReplaceAll(file, `\`, "/")
+	if root := strings.TrimSuffix(strings.ReplaceAll(t.Path, `\`, "/"), "/"); root != "" {
+		if rel, ok := strings.CutPrefix(file, root+"/"); ok {
+			return rel
+		}
+	}
+	return path.Base(file)
+}
+
+// keptFiles deletes the data of the torrents removed by clean except the files matching the keep_files patterns
+type keptFiles struct {
+	patterns            filePatterns
+	tfm                 *torrentfilemap.TorrentFileMap
+	downloadPathMapping map[string]string
+}
+
+// newKeptFiles returns nil unless the filter sets clean.keep_files
+func newKeptFiles(filter *config.FilterConfiguration, tfm *torrentfilemap.TorrentFileMap,
+	downloadPathMapping map[string]string) (*keptFiles, error) {
+	if filter == nil || len(filter.Clean.KeepFiles) == 0 {
+		return nil, nil
+	}
+
+	patterns, err := newFilePatterns("keep_files", filter.Clean.KeepFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keptFiles{patterns: patterns, tfm: tfm, downloadPathMapping: downloadPathMapping}, nil
+}
+
+// keeps reports whether the file of t is kept
+func (k *keptFiles) keeps(t config.Torrent, file string) bool {
+	return k.patterns.match(torrentRelativePath(t, file))
+}
+
+// remove deletes the files of t which are not kept and do not belong to other torrents, pruning the folders left
+// empty, and returns the bytes and number of files deleted
+func (k *keptFiles) remove(log *logrus.Entry, t config.Torrent) (int64, int64) {
+	var (
+		freed, deleted int64
+		kept           int
+		dirs           []string
+	)
+
+	root := filepath.Clean(paths.MapPath(t.Path, k.downloadPathMapping))
+
+	for _, f := range t.Files {
+		if k.keeps(t, f) {
+			log.Debugf("Keeping file: %q", f)
+			kept++
+			continue
+		}
+
+		if !k.tfm.IsUnique(config.Torrent{Hash: t.Hash, Files: []string{f}}) {
+			log.Debugf("File belongs to other torrents, keeping: %q", f)
+			kept++
+			continue
+		}
+
+		localFile := paths.MapPath(f, k.downloadPathMapping)
+		info, err := os.Stat(localFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.WithError(err).Errorf("Failed checking file: %q", localFile)
+			}
+			continue
+		}
+
+		if flagDryRun {
+			log.Debugf("Dry-run enabled, skipping removal of file: %q", localFile)
+		} else if err := os.Remove(localFile); err != nil {
+			log.WithError(err).Errorf("Failed removing file: %q", localFile)
+			continue
+		}
+
+		freed += info.Size()
+		deleted++
+		dirs = append(dirs, filepath.Dir(localFile))
+	}
+
+	if flagDryRun {
+		log.Warnf("Dry-run enabled, would keep %d file(s) and delete %d", kept, deleted)
+		return freed, deleted
+	}
+
+	pruneEmptyDirs(log, root, dirs)
+	log.Infof("Kept %d file(s), deleted %d", kept, deleted)
+	return freed, deleted
+}
+
+// removeDir deletes the files below localDir, a local folder of t not tracked by the client (e.g. extracted archives),
+// which are not kept, pruning the folders left empty
+func (k *keptFiles) removeDir(log *logrus.Entry, t config.Torrent, localDir string) {
+	var (
+		kept, deleted int
+		dirs          []string
+	)
+
+	root := filepath.Clean(paths.MapPath(t.Path, k.downloadPathMapping))
+
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			rel = d.Name()
+		}
+
+		if k.patterns.match(filepath.ToSlash(rel)) {
+			log.Debugf("Keeping file: %q", p)
+			kept++
+			return nil
+		}
+
+		if err := os.Remove(p); err != nil {
+			log.WithError(err).Errorf("Failed removing file: %q", p)
+			return nil
+		}
+		deleted++
+		dirs = append(dirs, filepath.Dir(p))
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Errorf("Failed removing extracted content: %q", localDir)
+	}
+
+	pruneEmptyDirs(log, root, dirs)
+	log.Infof("Kept %d file(s) of extracted content, deleted %d: %q", kept, deleted, localDir)
+}
+
+// pruneEmptyDirs removes the dirs and their parents below root which are empty
+func pruneEmptyDirs(log *logrus.Entry, root string, dirs []string) {
+	for _, dir := range dirs {
+		for dir != root && paths.InSubtree(dir, []string{root}) {
+			if empty, err := paths.IsDirEmpty(dir); err != nil || !empty {
+				break
+			}
+
+			if err := os.Remove(dir); err != nil {
+				log.WithError(err).Errorf("Failed removing empty folder: %q", dir)
+				break
+			}
+			log.Debugf("Removed empty folder: %q", dir)
+			dir = filepath.Dir(dir)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+func TestKeptFilesKeeps(t *testing.T) {
+	kept, err := newKeptFiles(&config.FilterConfiguration{Clean: config.CleanConfig{
+		KeepFiles: []string{"*.MKV", "!*sample*", "Extras/*.srt"},
+	}}, nil, nil)
+	require.NoError(t, err)
+
+	torrent := config.Torrent{Path: "/downloads"}
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "/downloads/Movie/movie.mkv", want: true},
+		{file: "/downloads/Movie/Sample/movie-sample.mkv", want: false},
+		{file: "/downloads/Movie/movie.nfo", want: false},
+		{file: "/downloads/Extras/movie.srt", want: true},
+		{file: "/downloads/Movie/Extras/movie.srt", want: true},
+		{file: "/downloads/Movie/Extras/Deleted/movie.srt", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			assert.Equal(t, tt.want, kept.keeps(torrent, tt.file))
+		})
+	}
+
+	// disabled
+	disabled, err := newKeptFiles(&config.FilterConfiguration{}, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, disabled)
+
+	_, err = newKeptFiles(&config.FilterConfiguration{Clean: config.CleanConfig{KeepFiles: []string{"[mkv"}}}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestKeptFilesRemove(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"Movie/movie.mkv", "Movie/movie.nfo", "Movie/Proof/proof.jpg", "Movie/shared.nfo"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("data"), 0600))
+	}
+
+	removed := config.Torrent{Hash: "a", Path: "/client", Files: []string{"/client/Movie/movie.mkv",
+		"/client/Movie/movie.nfo", "/client/Movie/Proof/proof.jpg", "/client/Movie/shared.nfo"}}
+	other := config.Torrent{Hash: "b", Path: "/client", Files: []string{"/client/Movie/shared.nfo"}}
+	tfm := torrentfilemap.New(map[string]config.Torrent{"a": removed, "b": other})
+
+	kept, err := newKeptFiles(&config.FilterConfiguration{Clean: config.CleanConfig{KeepFiles: []string{"*.mkv"}}}, tfm,
+		map[string]string{"/client": dir})
+	require.NoError(t, err)
+
+	freed, deleted := kept.remove(logger.GetLogger("test"), removed)
+	assert.Equal(t, int64(8), freed)
+	assert.Equal(t, int64(2), deleted)
+
+	assert.FileExists(t, filepath.Join(dir, "Movie", "movie.mkv"))
+	assert.NoFileExists(t, filepath.Join(dir, "Movie", "movie.nfo"))
+	assert.NoDirExists(t, filepath.Join(dir, "Movie", "Proof"))
+	// the file still belongs to another torrent
+	assert.FileExists(t, filepath.Join(dir, "Movie", "shared.nfo"))
+}
+
+func TestKeptFilesExtractedArchives(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"Movie/movie.rar", "Movie/movie.r00", "Movie/movie.mkv", "Movie/movie.nfo",
+		"Movie/Subs/movie.srt"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("data"), 0600))
+	}
+
+	// movie.mkv, movie.nfo and Subs were extracted from the archives of the torrent
+	removed := config.Torrent{Hash: "a", Path: "/client", Files: []string{"/client/Movie/movie.rar",
+		"/client/Movie/movie.r00"}}
+	tfm := torrentfilemap.New(map[string]config.Torrent{"a": removed})
+	mapping := map[string]string{"/client": dir}
+
+	kept, err := newKeptFiles(&config.FilterConfiguration{Clean: config.CleanConfig{KeepFiles: []string{"*.mkv", "*.srt"}}},
+		tfm, mapping)
+	require.NoError(t, err)
+
+	// the order of clean: the files of the torrent, then the content extracted next to its archives
+	log := logger.GetLogger("test")
+	kept.remove(log, removed)
+	tfm.Remove(removed)
+	(&extractedArchives{tfm: tfm, downloadPathMapping: mapping}).remove(log, removed, kept)
+
+	assert.NoFileExists(t, filepath.Join(dir, "Movie", "movie.rar"))
+	assert.NoFileExists(t, filepath.Join(dir, "Movie", "movie.nfo"))
+	assert.FileExists(t, filepath.Join(dir, "Movie", "movie.mkv"))
+	assert.FileExists(t, filepath.Join(dir, "Movie", "Subs", "movie.srt"))
+
+	// without keep_files the extracted content is removed entirely
+	(&extractedArchives{tfm: tfm, downloadPathMapping: mapping}).remove(log, removed, nil)
+	assert.NoDirExists(t, filepath.Join(dir, "Movie"))
+}
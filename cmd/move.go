@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move [CLIENT]",
+	Short: "Check torrent client for torrents to move",
+	Long: `This command can be used to move the data of torrents to the path of the first move rule of the client's filter
+they match, e.g. completed torrents older than 30 days to an archive disk. The data is moved by the client.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		start := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("move")
+		runOutcome.track()
+
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		if len(clientFilter.Move) == 0 {
+			log.Warn("No move rules configured in the filter's move section")
+		}
+
+		// get free disk space (can/will be used by filters)
+		if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+			log.WithError(err).Error("Failed retrieving free-space")
+		}
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// create map of files associated to torrents (via hash)
+		tfm := torrentfilemap.New(torrents)
+		log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
+
+		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "move", true) {
+			// download path mapping
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "move", tqm.PathMappingHardlinks)
+			if err != nil {
+				log.WithError(err).Fatal("Failed loading client download path mappings")
+			} else if clientDownloadPathMapping != nil {
+				log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
+					clientDownloadPathMapping)
+			}
+
+			// create map of paths associated to underlying file ids
+			start := time.Now()
+			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
+
+			// add HardlinkedOutsideClient field to torrents
+			for h, t := range torrents {
+				t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+				torrents[h] = t
+			}
+		}
+
+		// scope to the targeted torrents (the full list is still required to map cross-seeds and hardlinks)
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		var (
+			moves  = make(map[string][]string)
+			moved  int
+			fields []notification.Field
+		)
+
+		// iterate through torrents
+		for _, t := range torrents {
+			// moving data that is being checked or moved already could corrupt it
+			if t.IsChecking() || t.IsMoving() {
+				log.Tracef("Torrent is being checked or moved: %q", t.Name)
+				continue
+			}
+
+			// check if torrent should be moved
+			path, move, err := c.ShouldMove(ctx, &t)
+			if err != nil {
+				log.WithError(err).Errorf("Failed checking move rules for torrent: %q", t.Name)
+				runOutcome.record(0, 1)
+				continue
+			} else if !move || samePath(path, t.Path) {
+				continue
+			}
+
+			if !tfm.IsUnique(t) {
+				// torrent file is not unique, files are contained within another torrent
+				// so moving its data would leave the other torrent without it
+				log.Warnf("Skipping non unique torrent | Name: %s / Label: %s / Tags: %s / Tracker: %s", t.Name, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName)
+				continue
+			}
+
+			log.Info("-----")
+			log.Infof("Moving torrent: %q", t.Name)
+			log.Infof("Ratio: %.3f / Seed days: %.3f / Label: %s / Tags: %s / Tracker: %s", t.Ratio, t.SeedingDays,
+				t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName)
+			log.Infof("Path: %s -> %s", t.Path, path)
+
+			moves[path] = append(moves[path], t.Hash)
+			moved++
+			fields = append(fields, noti.BuildField(notification.ActionMove, notification.BuildOptions{
+				Torrent: t,
+				NewPath: path,
+			}))
+		}
+
+		paths := make([]string, 0, len(moves))
+		for path := range moves {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		// move torrents if not dry run
+		failed := 0
+		if !flagDryRun {
+			for _, path := range paths {
+				if err := c.MoveTorrents(ctx, moves[path], path); err != nil {
+					log.WithError(err).Errorf("Failed moving %d torrent(s) to: %q", len(moves[path]), path)
+					failed += len(moves[path])
+					continue
+				}
+				log.Infof("Moving %d torrent(s) to: %q", len(moves[path]), path)
+			}
+
+			runOutcome.record(moved-failed, failed)
+			if moved == 0 {
+				log.Info("No torrents to move")
+			} else {
+				log.Infof("Started moving %d torrent(s), %d failed", moved-failed, failed)
+			}
+		} else {
+			runOutcome.record(moved, 0)
+			if moved > 0 {
+				log.Infof("[DRY-RUN] Would move %d torrent(s) to %d path(s)", moved, len(paths))
+			} else {
+				log.Info("[DRY-RUN] No torrents would be moved")
+			}
+		}
+
+		if !noti.CanSend() {
+			log.Debug("Notifications disabled, skipping...")
+			return
+		}
+
+		description := fmt.Sprintf("Moved **%d** torrent(s)", moved-failed)
+		if failed > 0 {
+			description += fmt.Sprintf(", **%d** failed", failed)
+		}
+
+		sendErr := noti.Send(
+			"Torrent Move",
+			description,
+			clientName,
+			time.Since(start),
+			fields,
+			flagDryRun,
+		)
+		if sendErr != nil {
+			log.WithError(sendErr).Error("Failed sending notification")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+
+	moveCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	moveCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	moveCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// samePath reports whether a and b refer to the same directory, ignoring trailing separators
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
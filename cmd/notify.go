@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+)
+
+var (
+	flagNotifyAction string
+	flagNotifySample bool
+)
+
+// notifyTestMessage is the notification sent by a command for an action, with a description reporting count entries
+type notifyTestMessage struct {
+	action      notification.Action
+	title       string
+	description func(count int) string
+}
+
+// notifyTestMessages are the notifications of the actions, as sent by their commands
+var notifyTestMessages = map[string]notifyTestMessage{
+	"clean": {notification.ActionClean, "Torrent Cleanup", func(n int) string {
+		return fmt.Sprintf("Removed **%d** torrent(s) | Total reclaimed **%s**", n,
+			formatting.Bytes(uint64(n)*sampleTorrentSize))
+	}},
+	"retag": {notification.ActionRetag, "Torrent Retag", func(n int) string {
+		return fmt.Sprintf("Retagged **%d** torrent(s)", n)
+	}},
+	"relabel": {notification.ActionRelabel, "Torrent Relabel", func(n int) string {
+		return fmt.Sprintf("Relabeled **%d** torrent(s)", n)
+	}},
+	"share-limit": {notification.ActionShareLimit, "Torrent Share Limits", func(n int) string {
+		return fmt.Sprintf("Set share limits for **%d** torrent(s)", n)
+	}},
+	"move": {notification.ActionMove, "Torrent Move", func(n int) string {
+		return fmt.Sprintf("Moved **%d** torrent(s)", n)
+	}},
+	"pause": {notification.ActionPause, "Torrent Pause", func(n int) string {
+		return fmt.Sprintf("Paused **%d** torrent(s)", n)
+	}},
+	"resume": {notification.ActionResume, "Torrent Resume", func(n int) string {
+		return fmt.Sprintf("Resumed **%d** torrent(s)", n)
+	}},
+	"recheck": {notification.ActionRecheck, "Torrent Recheck", func(n int) string {
+		return fmt.Sprintf("Rechecked **%d** torrent(s)", n)
+	}},
+	"reannounce": {notification.ActionReannounce, "Torrent Reannounce", func(n int) string {
+		return fmt.Sprintf("Reannounced **%d** torrent(s)", n)
+	}},
+	"prune-files": {notification.ActionPruneFiles, "Torrent Files Pruned", func(n int) string {
+		return fmt.Sprintf("Pruned the files of **%d** torrent(s) | Total reclaimed **%s**", n,
+			formatting.Bytes(uint64(n)*sampleTorrentSize/10))
+	}},
+	"orphan": {notification.ActionOrphan, "Orphans", func(n int) string {
+		return fmt.Sprintf("Removed **%d** orphaned files and **0** orphaned folders | Total reclaimed **%s**", n,
+			formatting.Bytes(uint64(n)*sampleTorrentSize))
+	}},
+}
+
+// sampleTorrentSize is the size of the synthetic torrents of test notifications
+const sampleTorrentSize = 4 * humanize.GiByte
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Work with notifications",
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification through every configured notification sender",
+	Long: `This command sends the notification of an action (clean by default) through every configured notification sender,
+so webhooks and formatting can be verified without waiting for a real run. With --sample, the notification lists synthetic
+torrents as a real run would. Delta mode and skip_empty_run are ignored, so the test notification is always sent.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("notify")
+
+		msg, ok := notifyTestMessages[flagNotifyAction]
+		if !ok {
+			log.Fatalf("Unsupported action: %q (supported: %s)", flagNotifyAction,
+				strings.Join(slices.Sorted(maps.Keys(notifyTestMessages)), ", "))
+		}
+
+		// the test notification is not part of the deltas of the real runs and sent even without entries
+		cfg := config.Config.Notifications
+		cfg.Delta = false
+		cfg.SkipEmptyRun = false
+		if flagNotifySample && !cfg.Detailed {
+			log.Info("Detailed notifications are disabled, the sample torrents are only counted " +
+				"(set notifications.detailed to list them)")
+		}
+
+		senders := []notification.Sender{notification.NewDiscordSender(log, cfg)}
+
+		var sent, failed int
+		for _, noti := range senders {
+			if !noti.CanSend() {
+				log.Debugf("Notification sender %s not configured, skipping...", noti.Name())
+				continue
+			}
+
+			if err := sendTestNotification(noti, msg, flagNotifySample); err != nil {
+				log.WithError(err).Errorf("Failed sending test notification through %s", noti.Name())
+				failed++
+				continue
+			}
+
+			log.Infof("Sent test notification through %s", noti.Name())
+			sent++
+		}
+
+		switch {
+		case sent == 0 && failed == 0:
+			log.Fatal("No notification sender configured")
+		case failed > 0:
+			log.Fatalf("Failed sending test notification through %d sender(s)", failed)
+		}
+	},
+}
+
+// sendTestNotification sends msg through noti, listing synthetic torrents when sample is set
+func sendTestNotification(noti notification.Sender, msg notifyTestMessage, sample bool) error {
+	var fields []notification.Field
+	if sample {
+		for _, opts := range sampleBuildOptions(msg.action) {
+			fields = append(fields, noti.BuildField(msg.action, opts))
+		}
+	}
+
+	return noti.Send(msg.title+" (Test)", msg.description(len(fields)), "test", 42*time.Second, fields, flagDryRun)
+}
+
+// sampleBuildOptions returns the synthetic torrents of a test notification for action
+func sampleBuildOptions(action notification.Action) []notification.BuildOptions {
+	torrents := []config.Torrent{
+		{
+			Hash: "0123456789abcdef0123456789abcdef01234567", Name: "Some.Movie.2020.1080p.BluRay.x264-GRP",
+			TotalBytes: sampleTorrentSize, Ratio: 2.35, SeedingDays: 45.2, Label: "movies",
+			Tags: map[string]struct{}{"tqm": {}, "cross-seed": {}}, TrackerName: "tracker.example", TrackerStatus: "Working",
+		},
+		{
+			Hash: "89abcdef0123456789abcdef0123456789abcdef", Name: "Some.Show.S01E01.720p.WEB.h264-GRP",
+			TotalBytes: sampleTorrentSize, Ratio: 0.41, SeedingDays: 3.1, Label: "tv",
+			TrackerName: "other.example", TrackerStatus: "Unregistered torrent",
+		},
+	}
+
+	opts := make([]notification.BuildOptions, 0, len(torrents))
+	for i, t := range torrents {
+		o := notification.BuildOptions{Torrent: t}
+		switch action {
+		case notification.ActionClean, notification.ActionPruneFiles:
+			o.RemovalReason = "IsUnregistered() || (Ratio > 2 && SeedingDays > 30)"
+		case notification.ActionRetag:
+			o.NewTags = []string{"tqm", "low-seed"}
+			o.NewUpLimit = 5000
+		case notification.ActionRelabel:
+			o.NewLabel = t.Label + "-archive"
+		case notification.ActionMove:
+			o.NewPath = "/mnt/archive/" + t.Label
+		case notification.ActionShareLimit:
+			o.NewShareLimitRuleName = "slow-seed"
+			o.NewRatioLimit = 3
+			o.NewSeedingTimeLimit = 43200
+		case notification.ActionOrphan:
+			o.Orphan = fmt.Sprintf("/mnt/downloads/%s/%s.mkv", t.Label, t.Name)
+			o.OrphanSize = t.TotalBytes
+			o.IsFile = true
+		}
+		opts = append(opts, o)
+
+		// the first torrent is cross-seeded, so the grouping of copies is shown as well
+		if i == 0 && action != notification.ActionOrphan {
+			copyOpts := o
+			copyOpts.Torrent.Hash = "fedcba9876543210fedcba9876543210fedcba98"
+			copyOpts.Torrent.TrackerName = "another.example"
+			opts = append(opts, copyOpts)
+		}
+	}
+	return opts
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+
+	notifyTestCmd.Flags().StringVar(&flagNotifyAction, "action", "clean", "Action whose notification is sent (clean, retag, relabel, share-limit, move, pause, resume, recheck, reannounce, prune-files or orphan)")
+	notifyTestCmd.Flags().BoolVar(&flagNotifySample, "sample", false, "List synthetic torrents in the notification, as a real run would")
+}
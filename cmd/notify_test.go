@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/notification"
+)
+
+// notificationRecorder records the notifications sent through it
+type notificationRecorder struct {
+	notification.Sender
+	titles       []string
+	descriptions []string
+	fields       [][]notification.Field
+}
+
+func (r *notificationRecorder) BuildField(action notification.Action, opts notification.BuildOptions) notification.Field {
+	if action == notification.ActionOrphan {
+		return notification.Field{Name: opts.Orphan}
+	}
+	return notification.Field{Name: opts.Torrent.Name}
+}
+
+func (r *notificationRecorder) Send(title string, description string, _ string, _ time.Duration,
+	fields []notification.Field, _ bool) error {
+	r.titles = append(r.titles, title)
+	r.descriptions = append(r.descriptions, description)
+	r.fields = append(r.fields, fields)
+	return nil
+}
+
+func TestSendTestNotification(t *testing.T) {
+	for action, msg := range notifyTestMessages {
+		t.Run(action, func(t *testing.T) {
+			noti := &notificationRecorder{}
+
+			require.NoError(t, sendTestNotification(noti, msg, false))
+			require.NoError(t, sendTestNotification(noti, msg, true))
+
+			assert.Equal(t, []string{msg.title + " (Test)", msg.title + " (Test)"}, noti.titles)
+			assert.Empty(t, noti.fields[0])
+			assert.NotEmpty(t, noti.fields[1])
+			for _, f := range noti.fields[1] {
+				assert.NotEmpty(t, f.Name)
+			}
+			assert.Contains(t, noti.descriptions[0], "**0**")
+			assert.Contains(t, noti.descriptions[1], fmt.Sprintf("**%d**", len(noti.fields[1])))
+		})
+	}
+}
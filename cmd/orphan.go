@@ -1,23 +1,33 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/autobrr/tqm/pkg/client"
 	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/hooks"
 	"github.com/autobrr/tqm/pkg/logger"
 	"github.com/autobrr/tqm/pkg/notification"
 	"github.com/autobrr/tqm/pkg/paths"
 	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
 
@@ -39,8 +49,9 @@ var orphanCmd = &cobra.Command{
 
 		// set log
 		log := logger.GetLogger("orphan")
+		runOutcome.track()
 
-		noti := notification.NewDiscordSender(log, config.Config.Notifications)
+		noti := newNotificationSender(log)
 
 		// retrieve client object
 		clientName := args[0]
@@ -50,33 +61,16 @@ var orphanCmd = &cobra.Command{
 		}
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
+		if err := tqm.ValidateClientEnabled(clientConfig); err != nil {
 			log.WithError(err).Fatal("Failed validating client is enabled")
 		}
 
 		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
+		clientType, err := tqm.ClientString("type", clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed determining client type")
 		}
 
-		// retrieve client download path
-		clientDownloadPath, err := getClientConfigString("download_path", clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed determining client download path")
-		} else if clientDownloadPath == nil || *clientDownloadPath == "" {
-			log.Fatal("Client download path must be set...")
-		}
-
-		// retrieve client download path mapping
-		clientDownloadPathMapping, err := getClientDownloadPathMapping(clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed loading client download path mappings")
-		} else if clientDownloadPathMapping != nil {
-			log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
-				clientDownloadPathMapping)
-		}
-
 		// load client object
 		c, err := client.NewClient(*clientType, clientName, nil)
 		if err != nil {
@@ -100,222 +94,440 @@ var orphanCmd = &cobra.Command{
 			log.Infof("Retrieved %d torrents", len(torrents))
 		}
 
-		// create map of files associated with torrents (via hash)
-		tfm := torrentfilemap.New(torrents)
-		log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
+		filter, err := tqm.ClientFilter(clientConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving client filter")
+		}
 
-		// get all paths in client download location
-		localDownloadPaths, _ := paths.InFolder(*clientDownloadPath, true, true,
-			nil)
-		log.Tracef("Retrieved %d paths from: %q", len(localDownloadPaths), *clientDownloadPath)
+		clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "orphan")
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
 
-		// sort paths into their respective maps
-		localFilePaths := make(map[string]int64)
-		localFolderPaths := make(map[string]int64)
+		if err := removeOrphans(ctx, log, c, clientName, clientConfig, filter, clientDownloadPathMapping, torrents, noti,
+			start); err != nil {
+			log.WithError(err).Fatal("Failed removing orphans")
+		}
+	},
+}
 
-		for _, p := range localDownloadPaths {
-			if p.IsDir {
-				if strings.EqualFold(p.RealPath, *clientDownloadPath) {
-					// ignore root download path
-					continue
-				}
+// removeOrphans removes the files and empty folders of the client's download path not belonging to any of torrents,
+// with the orphan settings of filter and the paths of torrents mapped with clientDownloadPathMapping
+func removeOrphans(ctx context.Context, log *logrus.Entry, c client.Interface, clientName string, clientConfig map[string]any,
+	filter *config.FilterConfiguration, clientDownloadPathMapping map[string]string, torrents map[string]config.Torrent,
+	noti notification.Sender, start time.Time) error {
+	// retrieve client download path
+	clientDownloadPath, err := tqm.ClientString("download_path", clientConfig)
+	if err != nil {
+		return fmt.Errorf("determine client download path: %w", err)
+	} else if clientDownloadPath == nil || *clientDownloadPath == "" {
+		return errors.New("client download path must be set")
+	}
 
-				localFolderPaths[p.RealPath] = p.Size
-			} else {
-				localFilePaths[p.RealPath] = p.Size
-			}
+	if clientDownloadPathMapping != nil {
+		log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
+			clientDownloadPathMapping)
+	}
+
+	// create map of files associated with torrents (via hash)
+	tfm := torrentfilemap.New(torrents)
+	log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
+
+	if filter == nil {
+		return errors.New("defined filter is empty")
+	}
+
+	// settings of the client take precedence over the ones of its filter
+	clientOrphan, err := config.ClientOrphanConfig(clientConfig)
+	if err != nil {
+		return fmt.Errorf("load client orphan settings: %w", err)
+	}
+	orphanConfig := filter.Orphan.Merge(clientOrphan).WithDefaults()
+
+	// internal directories of the client (e.g. incomplete downloads) are never orphaned
+	if sc, ok := c.(client.StateDirInterface); ok {
+		stateDirs, err := sc.StateDirs(ctx)
+		if err != nil {
+			return fmt.Errorf("retrieve client state directories: %w", err)
+		}
+
+		for _, dir := range stateDirs {
+			localDir := paths.MapPath(dir, clientDownloadPathMapping)
+			log.Debugf("Ignoring client state directory: %q", localDir)
+			orphanConfig.IgnorePaths = append(orphanConfig.IgnorePaths, localDir)
 		}
+	}
 
-		log.Infof("Retrieved paths from %q: %d files / %d folders", *clientDownloadPath, len(localFilePaths),
-			len(localFolderPaths))
+	// folders of torrents tagged with the protect tag are kept entirely, e.g. including extracted archives
+	protectedRoots := protectedDataRoots(torrents, orphanConfig.ProtectTag, clientDownloadPathMapping)
+	if len(protectedRoots) > 0 {
+		log.Debugf("Protecting %d paths of torrents tagged %q", len(protectedRoots), orphanConfig.ProtectTag)
+	}
 
-		const (
-			maxWorkers = 10
-			batchSize  = 50
-		)
+	// content extracted next to the archives of torrents is kept while the torrents exist
+	var extractedRoots []string
+	if orphanConfig.ExtractedArchives != config.ExtractedArchivesOrphan {
+		extractedRoots = extractedArchiveRoots(torrents, clientDownloadPathMapping)
+		log.Debugf("Keeping the content of %d archive folders", len(extractedRoots))
+	}
 
-		var (
-			wg                    sync.WaitGroup
-			mu                    sync.Mutex
-			removeFailures        atomic.Uint32
-			removedLocalFiles     atomic.Uint32
-			ignoredLocalFiles     atomic.Uint32
-			removedLocalFilesSize atomic.Uint64
-			fields                []notification.Field
-		)
+	// get all paths in client download location
+	localDownloadPaths, _ := paths.InFolder(*clientDownloadPath, true, true,
+		nil)
+	log.Tracef("Retrieved %d paths from: %q", len(localDownloadPaths), *clientDownloadPath)
+
+	// sort paths into their respective maps
+	localFilePaths := make(map[string]int64)
+	localFolderPaths := make(map[string]int64)
+	var excludedPaths int
+
+	for _, p := range localDownloadPaths {
+		if paths.InExcludedDir(p.RealPath, *clientDownloadPath, orphanConfig.ExcludeDirs) {
+			log.Tracef("Path is in an excluded directory, skipping: %q", p.RealPath)
+			excludedPaths++
+			continue
+		}
 
-		filter, err := getClientFilter(clientConfig)
+		if p.IsDir {
+			if strings.EqualFold(p.RealPath, *clientDownloadPath) {
+				// ignore root download path
+				continue
+			}
+
+			localFolderPaths[p.RealPath] = p.Size
+		} else {
+			localFilePaths[p.RealPath] = p.Size
+		}
+	}
+
+	log.Infof("Retrieved paths from %q: %d files / %d folders", *clientDownloadPath, len(localFilePaths),
+		len(localFolderPaths))
+	if excludedPaths > 0 {
+		log.Infof("Skipped %d paths in excluded directories (%s)", excludedPaths, strings.Join(orphanConfig.ExcludeDirs, ", "))
+	}
+
+	const batchSize = 50
+	maxWorkers := paths.StatConcurrency()
+
+	var (
+		wg                    sync.WaitGroup
+		mu                    sync.Mutex
+		removeFailures        atomic.Uint32
+		transientSkips        atomic.Uint32
+		removedLocalFiles     atomic.Uint32
+		ignoredLocalFiles     atomic.Uint32
+		inodeMatchedFiles     atomic.Uint32
+		vetoedOrphans         atomic.Uint32
+		removedLocalFilesSize atomic.Uint64
+		fields                []notification.Field
+	)
+
+	gracePeriod := orphanConfig.GracePeriod
+	log.Debugf("Using grace period: %v", gracePeriod)
+	log.Debugf("Ignoring paths: %s", strings.Join(orphanConfig.IgnorePaths, ", "))
+
+	// retries for transient filesystem errors (e.g. NFS/CIFS mounts)
+	retries, retryDelay := *orphanConfig.Retries, orphanConfig.RetryDelay
+	log.Debugf("Using %d retries with %v delay for transient filesystem errors", retries, retryDelay)
+
+	// files whose paths match no torrent file are checked again by device and inode, e.g. to catch bind mounts
+	var hfm hardlinkfilemap.HardlinkFileMapI = hardlinkfilemap.NewNoopHardlinkFileMap()
+	if *orphanConfig.InodeCheck {
+		hardlinkPathMapping, err := tqm.DownloadPathMapping(clientConfig, tqm.PathMappingHardlinks)
 		if err != nil {
-			log.WithError(err).Fatal("Failed to get client filter")
+			return fmt.Errorf("load client download path mappings: %w", err)
 		}
 
-		if filter == nil {
-			log.Fatal("Defined filter is empty")
+		start := time.Now()
+		hfm = hardlinkfilemap.New(torrents, hardlinkPathMapping)
+		log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s for the inode check", hfm.Length(),
+			formatting.Duration(time.Since(start)))
+	}
+
+	processInBatches(localFilePaths, maxWorkers, batchSize, func(localPath string, localPathSize int64) {
+		defer wg.Done()
+
+		if tfm.HasPath(localPath, clientDownloadPathMapping) {
+			return
 		}
 
-		gracePeriod := 10 * time.Minute
-		if filter.Orphan.GracePeriod > 0 {
-			gracePeriod = filter.Orphan.GracePeriod
+		if paths.IsIgnored(localPath, orphanConfig.IgnorePaths) {
+			mu.Lock()
+			log.Debugf("File matches a path in the ignore list, skipping removal: %q", localPath)
+			mu.Unlock()
+			report.path(reportKept, localPath, localPathSize, "matches the ignore list")
+			ignoredLocalFiles.Add(1)
+			return
 		}
-		log.Debugf("Using grace period: %v", gracePeriod)
 
-		processInBatches(localFilePaths, maxWorkers, batchSize, func(localPath string, localPathSize int64) {
-			defer wg.Done()
+		if paths.InSubtree(localPath, protectedRoots) {
+			mu.Lock()
+			log.Debugf("File belongs to a torrent tagged %q, skipping removal: %q", orphanConfig.ProtectTag, localPath)
+			mu.Unlock()
+			report.path(reportKept, localPath, localPathSize, fmt.Sprintf("belongs to a torrent tagged %s", orphanConfig.ProtectTag))
+			ignoredLocalFiles.Add(1)
+			return
+		}
 
-			if tfm.HasPath(localPath, clientDownloadPathMapping) {
-				return
-			}
+		if paths.InSubtree(localPath, extractedRoots) {
+			mu.Lock()
+			log.Debugf("File is next to the archives of a torrent, skipping removal: %q", localPath)
+			mu.Unlock()
+			report.path(reportKept, localPath, localPathSize, "next to the archives of a torrent")
+			ignoredLocalFiles.Add(1)
+			return
+		}
 
-			if paths.IsIgnored(localPath, filter.Orphan.IgnorePaths) {
-				mu.Lock()
-				log.Debugf("File matches a path in the ignore list, skipping removal: %q", localPath)
-				mu.Unlock()
-				ignoredLocalFiles.Add(1)
-				return
-			}
+		if hfm.ContainsLocalPath(localPath) {
+			mu.Lock()
+			log.Warnf("File matches no torrent path but is the underlying file of a torrent file, skipping removal "+
+				"(check the download path mapping): %q", localPath)
+			mu.Unlock()
+			report.path(reportKept, localPath, localPathSize, "underlying file of a torrent file")
+			inodeMatchedFiles.Add(1)
+			return
+		}
 
-			// check file modification time for grace period
-			fileInfo, err := os.Stat(localPath)
-			if err != nil {
-				mu.Lock()
-				log.WithError(err).Warnf("Could not stat file, skipping removal check: %q", localPath)
-				mu.Unlock()
-				return
+		// check file modification time for grace period
+		var fileInfo os.FileInfo
+		err := paths.RetryTransient(retries, retryDelay, func() (err error) {
+			fileInfo, err = os.Stat(localPath)
+			return err
+		})
+		if err != nil {
+			mu.Lock()
+			log.WithError(err).Warnf("Could not stat file, skipping removal check: %q", localPath)
+			mu.Unlock()
+			report.path(reportFailed, localPath, localPathSize, err.Error())
+			if paths.IsTransientError(err) {
+				transientSkips.Add(1)
 			}
+			return
+		}
 
-			if time.Since(fileInfo.ModTime()) < gracePeriod {
-				mu.Lock()
-				log.Warnf("File is recently modified (within %v), skipping removal due to grace period: %q", gracePeriod, localPath)
-				mu.Unlock()
-				return
-			}
+		if time.Since(fileInfo.ModTime()) < gracePeriod {
+			mu.Lock()
+			log.Warnf("File is recently modified (within %v), skipping removal due to grace period: %q", gracePeriod, localPath)
+			mu.Unlock()
+			report.path(reportKept, localPath, localPathSize, "within the grace period")
+			return
+		}
 
+		if vetoed, reason := hooks.Veto(ctx, config.Config.Hooks.PreOrphan, hooks.Event{Action: hooks.ActionOrphan,
+			Client: clientName, Name: localPath, Path: localPath, Size: localPathSize, DataDeleted: true,
+			DryRun: flagDryRun}); vetoed {
 			mu.Lock()
-			log.Info("-----")
-			log.Infof("Removing orphan (outside grace period): %q", localPath)
+			log.Warnf("Removal vetoed by pre_orphan hook, skipping: %q (%s)", localPath, reason)
 			mu.Unlock()
+			report.path(reportKept, localPath, localPathSize, fmt.Sprintf("vetoed by pre_orphan hook: %s", reason))
+			vetoedOrphans.Add(1)
+			return
+		}
 
-			removed := true
+		mu.Lock()
+		log.Info("-----")
+		log.Infof("Removing orphan (outside grace period): %q", localPath)
+		mu.Unlock()
 
-			if flagDryRun {
+		removed := true
+
+		if flagDryRun {
+			mu.Lock()
+			log.Warn("Dry-run enabled, skipping remove...")
+			mu.Unlock()
+		} else {
+			if err := paths.RetryTransient(retries, retryDelay, func() error {
+				return os.Remove(localPath)
+			}); err != nil {
 				mu.Lock()
-				log.Warn("Dry-run enabled, skipping remove...")
-				mu.Unlock()
-			} else {
-				if err := os.Remove(localPath); err != nil {
-					mu.Lock()
+				if paths.IsTransientError(err) {
+					log.WithError(err).Warnf("Skipping orphan due to transient filesystem error...")
+					transientSkips.Add(1)
+				} else {
 					log.WithError(err).Errorf("Failed removing orphan...")
-					mu.Unlock()
 					removeFailures.Add(1)
-					removed = false
-				} else {
-					mu.Lock()
-					log.Info("Removed")
-					mu.Unlock()
 				}
-			}
-
-			if removed {
-				removedLocalFilesSize.Add(uint64(localPathSize))
-				removedLocalFiles.Add(1)
-
+				mu.Unlock()
+				report.path(reportFailed, localPath, localPathSize, err.Error())
+				removed = false
+			} else {
 				mu.Lock()
-				fields = append(fields, noti.BuildField(notification.ActionOrphan, notification.BuildOptions{
-					Orphan:     localPath,
-					OrphanSize: localPathSize,
-					IsFile:     true,
-				}))
+				log.Info("Removed")
 				mu.Unlock()
+				history.Record(history.Entry{Client: clientName, Action: history.ActionOrphan, Name: localPath,
+					Reclaimed: localPathSize})
+				hooks.Run(ctx, config.Config.Hooks.PostOrphan, hooks.Event{Action: hooks.ActionOrphan, Client: clientName,
+					Name: localPath, Path: localPath, Size: localPathSize, DataDeleted: true})
 			}
-		}, &wg)
+		}
 
-		wg.Wait()
+		if removed {
+			report.path(reportRemoved, localPath, localPathSize, "orphaned file")
+			removedLocalFilesSize.Add(uint64(localPathSize))
+			removedLocalFiles.Add(1)
 
-		var ignoredLocalFolders uint32
-		orphanFolderPaths := make([]string, 0, len(localFolderPaths))
-		for localPath := range localFolderPaths {
-			if tfm.HasPath(localPath, clientDownloadPathMapping) {
-				continue
-			}
+			mu.Lock()
+			fields = append(fields, noti.BuildField(notification.ActionOrphan, notification.BuildOptions{
+				Orphan:     localPath,
+				OrphanSize: localPathSize,
+				IsFile:     true,
+			}))
+			mu.Unlock()
+		}
+	}, &wg)
 
-			if paths.IsIgnored(localPath, filter.Orphan.IgnorePaths) {
-				log.Debugf("Folder matches a path in the ignore list, skipping removal: %q", localPath)
-				ignoredLocalFolders++
-				continue
-			}
+	wg.Wait()
 
-			orphanFolderPaths = append(orphanFolderPaths, localPath)
+	var ignoredLocalFolders uint32
+	orphanFolderPaths := make([]string, 0, len(localFolderPaths))
+	for localPath := range localFolderPaths {
+		if tfm.HasPath(localPath, clientDownloadPathMapping) {
+			continue
 		}
 
-		// Sort orphan folders by path length (depth) in descending order
-		// This ensures deepest directories are processed first
-		sort.Slice(orphanFolderPaths, func(i, j int) bool {
-			return len(orphanFolderPaths[i]) > len(orphanFolderPaths[j])
-		})
+		if paths.IsIgnored(localPath, orphanConfig.IgnorePaths) {
+			log.Debugf("Folder matches a path in the ignore list, skipping removal: %q", localPath)
+			report.path(reportKept, localPath, 0, "matches the ignore list")
+			ignoredLocalFolders++
+			continue
+		}
 
-		log.Debugf("Processing %d potential orphan folders, sorted by depth", len(orphanFolderPaths))
+		if paths.InSubtree(localPath, protectedRoots) {
+			log.Debugf("Folder belongs to a torrent tagged %q, skipping removal: %q", orphanConfig.ProtectTag, localPath)
+			report.path(reportKept, localPath, 0, fmt.Sprintf("belongs to a torrent tagged %s", orphanConfig.ProtectTag))
+			ignoredLocalFolders++
+			continue
+		}
 
-		var removedLocalFolders uint32
-		for _, localPath := range orphanFolderPaths {
-			log.Info("-----")
-			log.Infof("Checking orphan folder: %q", localPath)
+		if paths.InSubtree(localPath, extractedRoots) {
+			log.Debugf("Folder is next to the archives of a torrent, skipping removal: %q", localPath)
+			report.path(reportKept, localPath, 0, "next to the archives of a torrent")
+			ignoredLocalFolders++
+			continue
+		}
+
+		orphanFolderPaths = append(orphanFolderPaths, localPath)
+	}
 
-			removed := false
+	// Sort orphan folders by path length (depth) in descending order
+	// This ensures deepest directories are processed first
+	sort.Slice(orphanFolderPaths, func(i, j int) bool {
+		return len(orphanFolderPaths[i]) > len(orphanFolderPaths[j])
+	})
 
-			empty, err := paths.IsDirEmpty(localPath)
-			if err != nil {
-				log.WithError(err).Warnf("Could not check if directory is empty, skipping removal: %q", localPath)
-			} else if !empty {
-				log.Warnf("Orphan directory is not empty, skipping removal: %q", localPath)
+	log.Debugf("Processing %d potential orphan folders, sorted by depth", len(orphanFolderPaths))
+
+	var removedLocalFolders uint32
+	for _, localPath := range orphanFolderPaths {
+		log.Info("-----")
+		log.Infof("Checking orphan folder: %q", localPath)
+
+		removed := false
+
+		empty, err := paths.IsDirEmpty(localPath)
+		if err != nil {
+			log.WithError(err).Warnf("Could not check if directory is empty, skipping removal: %q", localPath)
+			report.path(reportFailed, localPath, 0, err.Error())
+		} else if !empty {
+			log.Warnf("Orphan directory is not empty, skipping removal: %q", localPath)
+			report.path(reportKept, localPath, 0, "folder not empty")
+		} else if vetoed, reason := hooks.Veto(ctx, config.Config.Hooks.PreOrphan, hooks.Event{Action: hooks.ActionOrphan,
+			Client: clientName, Name: localPath, Path: localPath, DataDeleted: true, DryRun: flagDryRun}); vetoed {
+			log.Warnf("Removal vetoed by pre_orphan hook, skipping: %q (%s)", localPath, reason)
+			report.path(reportKept, localPath, 0, fmt.Sprintf("vetoed by pre_orphan hook: %s", reason))
+			vetoedOrphans.Add(1)
+		} else {
+			log.Infof("Attempting to remove empty orphan directory: %q", localPath)
+			if flagDryRun {
+				log.Warn("Dry-run enabled, skipping remove...")
+				removed = true
 			} else {
-				log.Infof("Attempting to remove empty orphan directory: %q", localPath)
-				if flagDryRun {
-					log.Warn("Dry-run enabled, skipping remove...")
-					removed = true
-				} else {
-					if err := os.Remove(localPath); err != nil {
+				if err := paths.RetryTransient(retries, retryDelay, func() error {
+					return os.Remove(localPath)
+				}); err != nil {
+					if paths.IsTransientError(err) {
+						log.WithError(err).Warnf("Skipping empty orphan directory due to transient filesystem error...")
+						transientSkips.Add(1)
+					} else {
 						log.WithError(err).Errorf("Failed removing empty orphan directory...")
 						removeFailures.Add(1)
-					} else {
-						log.Info("Removed empty orphan directory")
-						removed = true
 					}
+					report.path(reportFailed, localPath, 0, err.Error())
+				} else {
+					log.Info("Removed empty orphan directory")
+					history.Record(history.Entry{Client: clientName, Action: history.ActionOrphan, Name: localPath,
+						Detail: "empty folder"})
+					hooks.Run(ctx, config.Config.Hooks.PostOrphan, hooks.Event{Action: hooks.ActionOrphan, Client: clientName,
+						Name: localPath, Path: localPath, DataDeleted: true})
+					removed = true
 				}
 			}
+		}
 
-			if removed {
-				fields = append(fields, noti.BuildField(notification.ActionOrphan, notification.BuildOptions{
-					Orphan:     localPath,
-					OrphanSize: 0,
-					IsFile:     false,
-				}))
-				removedLocalFolders++
-			}
+		if removed {
+			report.path(reportRemoved, localPath, 0, "empty orphaned folder")
+			fields = append(fields, noti.BuildField(notification.ActionOrphan, notification.BuildOptions{
+				Orphan:     localPath,
+				OrphanSize: 0,
+				IsFile:     false,
+			}))
+			removedLocalFolders++
 		}
+	}
 
-		log.Info("-----")
-		log.WithField("reclaimed_space", humanize.IBytes(removedLocalFilesSize.Load())).
-			Infof("Removed orphans: %d files, %d folders and %d failures. Ignored %d files and %d folders",
-				removedLocalFiles.Load(), removedLocalFolders, removeFailures.Load(), ignoredLocalFiles.Load(), ignoredLocalFolders)
+	log.Info("-----")
+	log.WithField("reclaimed_space", formatting.Bytes(removedLocalFilesSize.Load())).
+		Infof("Removed orphans: %d files, %d folders and %d failures (%d skipped due to transient errors). Ignored %d files and %d folders",
+			removedLocalFiles.Load(), removedLocalFolders, removeFailures.Load(), transientSkips.Load(), ignoredLocalFiles.Load(), ignoredLocalFolders)
+	runOutcome.record(int(removedLocalFiles.Load()+removedLocalFolders), int(removeFailures.Load()+transientSkips.Load()))
+	if n := vetoedOrphans.Load(); n > 0 {
+		log.Warnf("Kept %d orphans vetoed by pre_orphan hooks", n)
+	}
+	if n := inodeMatchedFiles.Load(); n > 0 {
+		log.Warnf("Kept %d files only matched to torrents by the inode check, check the download path mapping", n)
+	}
 
-		if !noti.CanSend() {
-			log.Debug("Notifications disabled, skipping...")
-			return
+	if !noti.CanSend() {
+		log.Debug("Notifications disabled, skipping...")
+		return nil
+	}
+
+	sendErr := noti.Send(
+		"Orphans",
+		fmt.Sprintf("Removed **%d** orphaned files and **%d** orphaned folders | Total reclaimed **%s**",
+			removedLocalFiles.Load(), removedLocalFolders, formatting.Bytes(removedLocalFilesSize.Load())),
+		clientName,
+		time.Since(start),
+		fields,
+		flagDryRun,
+	)
+	if sendErr != nil {
+		log.WithError(sendErr).Error("Failed sending notification")
+	}
+
+	return nil
+}
+
+// protectedDataRoots returns the local paths of the content of the torrents tagged with tag: the top folder of
+// multi-file torrents and the file of single-file torrents
+func protectedDataRoots(torrents map[string]config.Torrent, tag string, downloadPathMapping map[string]string) []string {
+	seen := make(map[string]struct{})
+	for _, t := range torrents {
+		if _, ok := t.Tags[tag]; !ok {
+			continue
 		}
 
-		sendErr := noti.Send(
-			"Orphans",
-			fmt.Sprintf("Removed **%d** orphaned files and **%d** orphaned folders | Total reclaimed **%s**",
-				removedLocalFiles.Load(), removedLocalFolders, humanize.IBytes(removedLocalFilesSize.Load())),
-			clientName,
-			time.Since(start),
-			fields,
-			flagDryRun,
-		)
-		if sendErr != nil {
-			log.WithError(sendErr).Error("Failed sending notification")
+		for _, f := range t.Files {
+			root := f
+			if rel, err := filepath.Rel(t.Path, f); err == nil && !strings.HasPrefix(rel, "..") {
+				root = filepath.Join(t.Path, strings.Split(rel, string(filepath.Separator))[0])
+			}
+
+			seen[paths.MapPath(root, downloadPathMapping)] = struct{}{}
 		}
-	},
+	}
+
+	return slices.Sorted(maps.Keys(seen))
 }
 
 // processInBatches processes a map in batches using a worker pool
@@ -360,4 +572,6 @@ func processInBatches(items map[string]int64, maxWorkers int, batchSize int,
 
 func init() {
 	rootCmd.AddCommand(orphanCmd)
+
+	orphanCmd.Flags().StringVar(&flagReport, "report", "", "Write every decision (kept, ignored, removed and the matched expression) to this JSON file, also in dry-run")
 }
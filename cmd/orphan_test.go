@@ -358,3 +358,16 @@ func TestMain(m *testing.M) {
 	exitCode := m.Run()
 	os.Exit(exitCode)
 }
+
+func TestProtectedDataRoots(t *testing.T) {
+	protected := map[string]struct{}{"protect-data": {}}
+	torrents := map[string]config.Torrent{
+		"pack": {Path: "/data/movies", Tags: protected, Files: []string{
+			"/data/movies/Movie.2024/movie.rar", "/data/movies/Movie.2024/Subs/movie.srt"}},
+		"single": {Path: "/data/tv", Tags: protected, Files: []string{"/data/tv/Show.S01E01.mkv"}},
+		"other":  {Path: "/data/movies", Files: []string{"/data/movies/Other.2024/other.mkv"}},
+	}
+
+	roots := protectedDataRoots(torrents, "protect-data", map[string]string{"/data": "/mnt/data"})
+	assert.Equal(t, []string{"/mnt/data/movies/Movie.2024", "/mnt/data/tv/Show.S01E01.mkv"}, roots)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/schema"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	// outputJSONLines prints every action as a JSON line as soon as it is taken
+	outputJSONLines = "jsonl"
+	// outputCSV is only supported by export
+	outputCSV = "csv"
+)
+
+// validateOutput checks --output, table is accepted for the former per-command flags
+func validateOutput() error {
+	switch flagOutput {
+	case outputText, outputJSON, outputJSONLines:
+		return nil
+	case "table":
+		flagOutput = outputText
+		return nil
+	case outputCSV:
+		if logCommand == "export" {
+			return nil
+		}
+		return fmt.Errorf("unsupported output format: %q (only supported by export)", flagOutput)
+	default:
+		return fmt.Errorf("unsupported output format: %q (supported: %s, %s, %s)", flagOutput, outputText, outputJSON,
+			outputJSONLines)
+	}
+}
+
+// outputRecorder collects the actions of the command, it is only used by commands taking actions
+type outputRecorder struct {
+	mu      sync.Mutex
+	used    bool
+	started time.Time
+	actions []schema.Action
+	// lines receives every action as a JSON line as soon as it is recorded, when set
+	lines io.Writer
+}
+
+var results outputRecorder
+
+func (r *outputRecorder) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.used {
+		r.used = true
+		r.started = time.Now()
+	}
+}
+
+// streamTo writes every action recorded from now on as a JSON line to w
+func (r *outputRecorder) streamTo(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = w
+}
+
+func (r *outputRecorder) add(action notification.Action, options notification.BuildOptions) {
+	a := schema.Action{Action: action.String()}
+
+	switch action {
+	case notification.ActionOrphan:
+		a.Path = options.Orphan
+		a.Size = options.OrphanSize
+	default:
+		t := options.Torrent
+		a.Hash, a.Name, a.Tracker, a.Size = t.Hash, t.Name, t.TrackerName, t.TotalBytes
+		a.Reason = options.RemovalReason
+		a.Label = options.NewLabel
+		a.Path = options.NewPath
+	}
+
+	switch action {
+	case notification.ActionRetag:
+		a.Tags = options.NewTags
+		limit := options.NewUpLimit
+		a.UploadLimit = &limit
+	case notification.ActionShareLimit:
+		a.ShareLimit = options.NewShareLimitRuleName
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions = append(r.actions, a)
+
+	if r.lines != nil {
+		if err := json.NewEncoder(r.lines).Encode(schema.ActionLine{SchemaVersion: schema.Version, Action: a}); err != nil {
+			log.WithError(err).Error("Failed writing action")
+		}
+	}
+}
+
+// outputSender records the fields built for notifications as actions of the output document
+type outputSender struct {
+	notification.Sender
+}
+
+func (s outputSender) BuildField(action notification.Action, options notification.BuildOptions) notification.Field {
+	results.add(action, options)
+	return s.Sender.BuildField(action, options)
+}
+
+// newNotificationSender returns the notification sender of a command, which also records its actions with --output json
+// or jsonl
+func newNotificationSender(log *logrus.Entry) notification.Sender {
+	noti := notification.NewDiscordSender(log, config.Config.Notifications)
+	switch flagOutput {
+	case outputJSON:
+	case outputJSONLines:
+		results.streamTo(os.Stdout)
+	default:
+		// the actions of a forced dry-run are summarized at the end of the run
+		if !safeModeForced {
+			return noti
+		}
+	}
+
+	results.start()
+	return outputSender{Sender: noti}
+}
+
+// writeOutputDocument writes the actions recorded by cmd with --output json, commands without actions write nothing
+func writeOutputDocument(w io.Writer, cmd *cobra.Command) error {
+	results.mu.Lock()
+	defer results.mu.Unlock()
+
+	if flagOutput != outputJSON || !results.used {
+		return nil
+	}
+
+	doc := schema.Run{
+		SchemaVersion: schema.Version,
+		Command:       strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "),
+		DryRun:        flagDryRun,
+		Started:       results.started,
+		Duration:      time.Since(results.started).Seconds(),
+		Actions:       results.actions,
+	}
+	if args := cmd.Flags().Args(); len(args) > 0 {
+		doc.Client = args[0]
+	}
+	if doc.Actions == nil {
+		doc.Actions = []schema.Action{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
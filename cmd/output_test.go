@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/schema"
+)
+
+type nopSender struct{}
+
+func (nopSender) CanSend() bool { return false }
+func (nopSender) Send(string, string, string, time.Duration, []notification.Field, bool) error {
+	return nil
+}
+func (nopSender) BuildField(notification.Action, notification.BuildOptions) notification.Field {
+	return notification.Field{}
+}
+func (nopSender) Name() string { return "nop" }
+
+func TestWriteOutputDocument(t *testing.T) {
+	prevOutput, prevDryRun := flagOutput, flagDryRun
+	t.Cleanup(func() {
+		flagOutput, flagDryRun = prevOutput, prevDryRun
+		results = outputRecorder{}
+	})
+	flagOutput, flagDryRun = outputJSON, true
+
+	cmd := &cobra.Command{Use: "clean"}
+	rootCmd.AddCommand(cmd)
+	t.Cleanup(func() { rootCmd.RemoveCommand(cmd) })
+	require.NoError(t, cmd.Flags().Parse([]string{"qbt"}))
+
+	// commands not taking actions write nothing
+	var buf bytes.Buffer
+	require.NoError(t, writeOutputDocument(&buf, cmd))
+	assert.Empty(t, buf.String())
+
+	results.start()
+	noti := outputSender{Sender: nopSender{}}
+	noti.BuildField(notification.ActionClean, notification.BuildOptions{
+		Torrent:       config.Torrent{Hash: "abc", Name: "Some.Torrent", TrackerName: "tracker.example", TotalBytes: 1024},
+		RemovalReason: "IsUnregistered()",
+	})
+	noti.BuildField(notification.ActionOrphan, notification.BuildOptions{Orphan: "/data/orphan.mkv", OrphanSize: 2048, IsFile: true})
+
+	require.NoError(t, writeOutputDocument(&buf, cmd))
+
+	var doc schema.Run
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, schema.Version, doc.SchemaVersion)
+	assert.Equal(t, "clean", doc.Command)
+	assert.Equal(t, "qbt", doc.Client)
+	assert.True(t, doc.DryRun)
+	assert.Equal(t, []schema.Action{
+		{Action: "remove", Hash: "abc", Name: "Some.Torrent", Tracker: "tracker.example", Reason: "IsUnregistered()", Size: 1024},
+		{Action: "orphan", Path: "/data/orphan.mkv", Size: 2048},
+	}, doc.Actions)
+}
+
+func TestOutputRecorderStreamTo(t *testing.T) {
+	var (
+		r   outputRecorder
+		buf bytes.Buffer
+	)
+	r.streamTo(&buf)
+
+	r.add(notification.ActionClean, notification.BuildOptions{Torrent: config.Torrent{Hash: "abc", Name: "Some.Torrent"}})
+	assert.Equal(t, `{"schema_version":1,"action":"remove","hash":"abc","name":"Some.Torrent"}`+"\n", buf.String())
+
+	r.add(notification.ActionOrphan, notification.BuildOptions{Orphan: "/data/orphan.mkv"})
+	assert.Len(t, bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")), 2)
+	assert.Len(t, r.actions, 2)
+}
+
+func TestValidateOutput(t *testing.T) {
+	prevOutput, prevCommand := flagOutput, logCommand
+	t.Cleanup(func() { flagOutput, logCommand = prevOutput, prevCommand })
+
+	tests := []struct {
+		name    string
+		command string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{name: "text", command: "clean", output: outputText, want: outputText},
+		{name: "jsonl", command: "clean", output: outputJSONLines, want: outputJSONLines},
+		{name: "former table", command: "stats", output: "table", want: outputText},
+		{name: "csv of export", command: "export", output: outputCSV, want: outputCSV},
+		{name: "csv of other commands", command: "stats", output: outputCSV, wantErr: true},
+		{name: "unknown", command: "clean", output: "yaml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagOutput, logCommand = tt.output, tt.command
+
+			err := validateOutput()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, flagOutput)
+		})
+	}
+}
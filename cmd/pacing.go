@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// anyTracker is the tracker of the pacing applying to the trackers without a pacing of their own
+const anyTracker = "*"
+
+// trackerPace pauses the removals of a tracker after each group
+type trackerPace struct {
+	tracker   string
+	groupSize int
+	pause     time.Duration
+}
+
+// removalPacing interleaves the removals of the trackers and pauses the removals of a tracker after each group,
+// methods are no-ops on nil
+type removalPacing struct {
+	paces  []trackerPace
+	counts map[string]int
+}
+
+// newRemovalPacing returns the pacing of filter, nil when filter has no pacing
+func newRemovalPacing(filter *config.FilterConfiguration) (*removalPacing, error) {
+	if len(filter.Clean.Pacing) == 0 {
+		return nil, nil
+	}
+
+	rp := &removalPacing{counts: make(map[string]int)}
+	for _, p := range filter.Clean.Pacing {
+		pace := trackerPace{tracker: strings.ToLower(strings.TrimSpace(p.Tracker)), groupSize: p.GroupSize, pause: p.Pause}
+		if pace.tracker == "" {
+			return nil, fmt.Errorf("pacing without tracker (use %s for all trackers)", anyTracker)
+		}
+		if pace.groupSize < 0 {
+			return nil, fmt.Errorf("invalid group size of pacing %q: %d (must not be negative)", p.Tracker, pace.groupSize)
+		} else if pace.groupSize == 0 {
+			pace.groupSize = 1
+		}
+		if pace.pause < 0 {
+			return nil, fmt.Errorf("invalid pause of pacing %q: %s (must not be negative)", p.Tracker, pace.pause)
+		}
+		rp.paces = append(rp.paces, pace)
+	}
+
+	return rp, nil
+}
+
+// pace returns the pacing of tracker, the first pacing matching the tracker or one of its parent domains wins
+func (rp *removalPacing) pace(tracker string) (trackerPace, bool) {
+	tracker = strings.ToLower(tracker)
+
+	var fallback *trackerPace
+	for i, p := range rp.paces {
+		if p.tracker == anyTracker {
+			if fallback == nil {
+				fallback = &rp.paces[i]
+			}
+			continue
+		}
+		if tracker == p.tracker || strings.HasSuffix(tracker, "."+p.tracker) {
+			return p, true
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+	return trackerPace{}, false
+}
+
+// interleave orders hashes round-robin by the tracker of their torrent, keeping the order of the hashes of each
+// tracker
+func (rp *removalPacing) interleave(hashes []string, torrents map[string]config.Torrent) []string {
+	if rp == nil {
+		return hashes
+	}
+
+	byTracker := make(map[string][]string)
+	for _, h := range hashes {
+		tracker := torrents[h].TrackerName
+		byTracker[tracker] = append(byTracker[tracker], h)
+	}
+
+	trackers := make([]string, 0, len(byTracker))
+	for tracker := range byTracker {
+		trackers = append(trackers, tracker)
+	}
+	slices.Sort(trackers)
+
+	ordered := make([]string, 0, len(hashes))
+	for i := 0; len(ordered) < len(hashes); i++ {
+		for _, tracker := range trackers {
+			if i < len(byTracker[tracker]) {
+				ordered = append(ordered, byTracker[tracker][i])
+			}
+		}
+	}
+	return ordered
+}
+
+// removed counts a removal of tracker and pauses once a group of removals of the tracker is complete, unless ctx is
+// done. The pause is only logged in dry-run.
+func (rp *removalPacing) removed(ctx context.Context, log *logrus.Entry, tracker string) {
+	if rp == nil {
+		return
+	}
+
+	pace, ok := rp.pace(tracker)
+	if !ok || pace.pause == 0 {
+		return
+	}
+
+	rp.counts[tracker]++
+	if rp.counts[tracker]%pace.groupSize != 0 {
+		return
+	}
+
+	if flagDryRun {
+		log.Infof("Dry-run enabled, skipping pause of %s after %d removal(s) from tracker %s", pace.pause,
+			pace.groupSize, tracker)
+		return
+	}
+
+	log.Infof("Pausing %s after %d removal(s) from tracker %s", pace.pause, pace.groupSize, tracker)
+	select {
+	case <-ctx.Done():
+	case <-time.After(pace.pause):
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestRemovalPacing_Interleave(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a1": {TrackerName: "a.org"},
+		"a2": {TrackerName: "a.org"},
+		"a3": {TrackerName: "a.org"},
+		"b1": {TrackerName: "b.org"},
+		"c1": {TrackerName: "c.org"},
+		"c2": {TrackerName: "c.org"},
+	}
+	hashes := []string{"a1", "a2", "a3", "b1", "c1", "c2"}
+
+	rp, err := newRemovalPacing(&config.FilterConfiguration{Clean: config.CleanConfig{
+		Pacing: []config.PacingConfig{{Tracker: "*", Pause: time.Minute}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2", "c2", "a3"}, rp.interleave(hashes, torrents))
+
+	var none *removalPacing
+	assert.Equal(t, hashes, none.interleave(hashes, torrents))
+}
+
+func TestRemovalPacing_Pace(t *testing.T) {
+	rp, err := newRemovalPacing(&config.FilterConfiguration{Clean: config.CleanConfig{
+		Pacing: []config.PacingConfig{
+			{Tracker: "*", GroupSize: 20, Pause: time.Second},
+			{Tracker: "Beyond-HD.me", Pause: time.Minute},
+		},
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		tracker string
+		want    trackerPace
+	}{
+		{tracker: "beyond-hd.me", want: trackerPace{tracker: "beyond-hd.me", groupSize: 1, pause: time.Minute}},
+		{tracker: "tracker.beyond-hd.me", want: trackerPace{tracker: "beyond-hd.me", groupSize: 1, pause: time.Minute}},
+		{tracker: "notbeyond-hd.me", want: trackerPace{tracker: "*", groupSize: 20, pause: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tracker, func(t *testing.T) {
+			pace, ok := rp.pace(tt.tracker)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, pace)
+		})
+	}
+}
+
+func TestRemovalPacing_Removed(t *testing.T) {
+	log := logrus.NewEntry(logrus.New())
+	rp, err := newRemovalPacing(&config.FilterConfiguration{Clean: config.CleanConfig{
+		Pacing: []config.PacingConfig{{Tracker: "a.org", GroupSize: 2, Pause: 50 * time.Millisecond}},
+	}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	rp.removed(context.Background(), log, "a.org")
+	rp.removed(context.Background(), log, "b.org")
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "paused before the group was complete")
+
+	rp.removed(context.Background(), log, "a.org")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "not paused after the group")
+
+	// no pause in dry-run
+	flagDryRun = true
+	t.Cleanup(func() { flagDryRun = false })
+	start = time.Now()
+	rp.removed(context.Background(), log, "a.org")
+	rp.removed(context.Background(), log, "a.org")
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestNewRemovalPacing_Invalid(t *testing.T) {
+	for _, pacing := range []config.PacingConfig{
+		{Pause: time.Minute},
+		{Tracker: "a.org", GroupSize: -1},
+		{Tracker: "a.org", Pause: -time.Minute},
+	} {
+		_, err := newRemovalPacing(&config.FilterConfiguration{Clean: config.CleanConfig{
+			Pacing: []config.PacingConfig{pacing},
+		}})
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/runtime"
+)
+
+// recoverPanic handles a panic of the executed command: a diagnostic bundle is written to the config dir
+// and a failure notification is sent, before exiting with a non-zero exit code
+func recoverPanic(start time.Time) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	stack := debug.Stack()
+	log := logger.GetLogger("panic")
+	log.Errorf("Unexpected failure: %v", r)
+
+	bundlePath, err := writeDiagnosticBundle(flagConfigFolder, r, stack)
+	if err != nil {
+		log.WithError(err).Error("Failed writing diagnostic bundle")
+		fmt.Fprintf(os.Stderr, "panic: %v\n\n%s\n", r, stack)
+	} else {
+		log.Errorf("Wrote diagnostic bundle to %q, please attach it when reporting this issue", bundlePath)
+	}
+
+	// the config is not available when failing early
+	if config.Config != nil {
+		noti := newNotificationSender(log)
+		if noti.CanSend() {
+			fields := []notification.Field{{Name: "Error", Value: fmt.Sprint(r)}}
+			if bundlePath != "" {
+				fields = append(fields, notification.Field{Name: "Diagnostic bundle", Value: bundlePath})
+			}
+
+			if err := noti.Send("Failure", fmt.Sprintf("`%s` failed unexpectedly", strings.Join(os.Args[1:], " ")),
+				"", time.Since(start), fields, false); err != nil {
+				log.WithError(err).Error("Failed sending notification")
+			}
+		}
+	}
+
+	os.Exit(exitFatal)
+}
+
+// writeDiagnosticBundle writes a zip containing the panic and stack trace, the sanitized config and recent log lines
+func writeDiagnosticBundle(dir string, r any, stack []byte) (string, error) {
+	bundlePath := filepath.Join(dir, fmt.Sprintf("tqm-crash-%s.zip", time.Now().Format("20060102-150405")))
+
+	f, err := os.OpenFile(bundlePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	// panic
+	var b strings.Builder
+	fmt.Fprintf(&b, "Version: %s (%s@%s)\n", runtime.Version, runtime.GitCommit, runtime.Timestamp)
+	fmt.Fprintf(&b, "Command: %s\n", strings.Join(os.Args[1:], " "))
+	fmt.Fprintf(&b, "Time:    %s\n\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "panic: %v\n\n%s", r, stack)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"panic.txt", b.String()},
+		{"config.yaml", sanitizedConfig()},
+		{"tqm.log", config.RedactURLs(strings.Join(logger.RecentLines(), "\n")) + "\n"},
+	}
+
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return "", fmt.Errorf("create %s: %w", file.name, err)
+		}
+
+		if _, err := w.Write([]byte(file.content)); err != nil {
+			return "", fmt.Errorf("write %s: %w", file.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("close: %w", err)
+	}
+
+	return bundlePath, nil
+}
+
+// sanitizedConfig returns the config file with credentials redacted, or why it could not be included
+func sanitizedConfig() string {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Sprintf("# failed reading config %q: %v\n", configFile, err)
+	}
+
+	sanitized, err := config.Sanitize(data)
+	if err != nil {
+		// never include an unsanitized config
+		return fmt.Sprintf("# failed sanitizing config %q: %v\n", configFile, err)
+	}
+
+	return string(sanitized)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDiagnosticBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	prevConfigFile := configFile
+	configFile = filepath.Join(dir, "config.yaml")
+	t.Cleanup(func() { configFile = prevConfigFile })
+
+	require.NoError(t, os.WriteFile(configFile, []byte("clients:\n  qbt:\n    password: hunter2\n"), 0600))
+
+	bundlePath, err := writeDiagnosticBundle(dir, "boom", []byte("goroutine 1 [running]:"))
+	require.NoError(t, err)
+
+	zr, err := zip.OpenReader(bundlePath)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		contents[f.Name] = string(b)
+	}
+
+	require.Contains(t, contents, "panic.txt")
+	assert.Contains(t, contents["panic.txt"], "panic: boom")
+	assert.Contains(t, contents["panic.txt"], "goroutine 1 [running]:")
+	assert.Contains(t, contents["config.yaml"], "password: REDACTED")
+	assert.NotContains(t, contents["config.yaml"], "hunter2")
+	assert.Contains(t, contents, "tqm.log")
+}
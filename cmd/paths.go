@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+var (
+	flagPathsCount   int
+	flagPathsMapping string
+)
+
+var pathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "Inspect how paths reported by the torrent client map to local paths",
+}
+
+var pathsCheckCmd = &cobra.Command{
+	Use:   "check [CLIENT]",
+	Short: "Check that the download path mapping resolves torrent files to existing local paths",
+	Long: `This command applies the client's download_path_mapping (or the download_path_mappings entry given with --mapping)
+to the files of a few random torrents and reports the rule used and whether the resulting local path exists.
+Misconfigured mappings make every file look orphaned, so run this before the first orphan run.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("paths")
+
+		if flagPathsCount < 1 {
+			log.Fatalf("Invalid number of torrents: %d (must be at least 1)", flagPathsCount)
+		}
+
+		// load client object
+		clientName := args[0]
+		c, _, clientConfig, err := loadClient(ctx, clientName, "")
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		var keys []string
+		if flagPathsMapping != "" {
+			keys = append(keys, flagPathsMapping)
+		}
+		mapping, err := tqm.DownloadPathMapping(clientConfig, keys...)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		} else if len(mapping) == 0 {
+			log.Warn("No download path mapping configured, client paths are used as local paths")
+		}
+
+		if downloadPath, err := tqm.ClientString("download_path", clientConfig); err == nil && *downloadPath != "" {
+			if _, err := os.Stat(*downloadPath); err != nil {
+				log.WithError(err).Warnf("Client download_path is not accessible: %q", *downloadPath)
+			}
+		}
+
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// torrents without files can not be checked
+		for h, t := range torrents {
+			if len(t.Files) == 0 {
+				delete(torrents, h)
+			}
+		}
+		sampled := sampleTorrents(torrents, flagPathsCount, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+
+		checks := checkPathMapping(sampled, mapping, func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		})
+
+		if err := writePathChecks(os.Stdout, checks); err != nil {
+			log.WithError(err).Fatal("Failed writing path checks")
+		}
+
+		var missing int
+		for _, check := range checks {
+			if !check.Exists {
+				missing++
+			}
+		}
+
+		if missing > 0 {
+			log.Errorf("%d of %d local paths do not exist, check the download path mapping", missing, len(checks))
+			os.Exit(exitFatal)
+		}
+		log.Infof("All %d local paths exist", len(checks))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pathsCmd)
+	pathsCmd.AddCommand(pathsCheckCmd)
+
+	pathsCheckCmd.Flags().IntVar(&flagPathsCount, "count", 5, "Number of random torrents to check")
+	pathsCheckCmd.Flags().StringVar(&flagPathsMapping, "mapping", "", "download_path_mappings entry to check instead of download_path_mapping (e.g. orphan, hardlinks)")
+}
+
+// pathCheck is the local path a torrent file was mapped to
+type pathCheck struct {
+	Name       string `json:"name"`
+	ClientPath string `json:"client_path"`
+	// Rule is the mapping rule applied, empty when no rule matched
+	Rule      string `json:"rule,omitempty"`
+	LocalPath string `json:"local_path"`
+	Exists    bool   `json:"exists"`
+}
+
+// checkPathMapping maps the first file of every torrent (sorted by name) and checks whether the local path exists
+func checkPathMapping(torrents map[string]config.Torrent, mapping map[string]string, exists func(path string) bool) []pathCheck {
+	checks := make([]pathCheck, 0, len(torrents))
+	for _, t := range torrents {
+		if len(t.Files) == 0 {
+			continue
+		}
+
+		check := pathCheck{Name: t.Name, ClientPath: t.Files[0], LocalPath: t.Files[0]}
+		if from, to, ok := paths.MatchPathMapping(check.ClientPath, mapping); ok {
+			check.Rule = from + " → " + to
+			check.LocalPath = paths.MapPath(check.ClientPath, mapping)
+		}
+		check.Exists = exists(check.LocalPath)
+
+		checks = append(checks, check)
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	return checks
+}
+
+func writePathChecks(w io.Writer, checks []pathCheck) error {
+	if flagOutput == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(checks)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TORRENT\tCLIENT PATH\tRULE\tLOCAL PATH\tEXISTS\t")
+	for _, check := range checks {
+		rule := check.Rule
+		if rule == "" {
+			rule = "(none)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t\n", check.Name, check.ClientPath, rule, check.LocalPath, check.Exists)
+	}
+
+	return tw.Flush()
+}
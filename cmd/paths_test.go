@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestCheckPathMapping(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {Name: "a", Files: []string{"/downloads/tv/a.mkv"}},
+		"b": {Name: "b", Files: []string{"/downloads/movies/b.mkv"}},
+		"c": {Name: "c", Files: []string{"/other/c.mkv"}},
+	}
+	mapping := map[string]string{
+		"/downloads":    "/mnt/downloads",
+		"/downloads/tv": "/mnt/tv",
+	}
+	existing := map[string]bool{"/mnt/tv/a.mkv": true, "/mnt/downloads/movies/b.mkv": true}
+
+	checks := checkPathMapping(torrents, mapping, func(path string) bool { return existing[path] })
+
+	assert.Equal(t, []pathCheck{
+		{Name: "a", ClientPath: "/downloads/tv/a.mkv", Rule: "/downloads/tv → /mnt/tv", LocalPath: "/mnt/tv/a.mkv", Exists: true},
+		{Name: "b", ClientPath: "/downloads/movies/b.mkv", Rule: "/downloads → /mnt/downloads", LocalPath: "/mnt/downloads/movies/b.mkv", Exists: true},
+		{Name: "c", ClientPath: "/other/c.mkv", LocalPath: "/other/c.mkv"},
+	}, checks)
+}
@@ -1,23 +1,53 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/autobrr/tqm/pkg/client"
 	"github.com/autobrr/tqm/pkg/config"
 	"github.com/autobrr/tqm/pkg/evaluate"
 	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
 	"github.com/autobrr/tqm/pkg/logger"
 	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/tqm"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
 
+// pauseAction is the pause or resume command, they only differ in the torrents they apply to and the client call
+type pauseAction struct {
+	// name is the command, its log and the MapHardlinksFor and download path mapping key
+	name      string
+	gerund    string
+	past      string
+	title     string
+	titlePast string
+	// paused is whether the action applies to paused torrents (resume) or to the others (pause)
+	paused       bool
+	notification notification.Action
+	check        func(c client.Interface, ctx context.Context, t *config.Torrent) (bool, error)
+	apply        func(c client.Interface, ctx context.Context, hashes []string) error
+}
+
+var pauseTorrents = pauseAction{
+	name:         "pause",
+	gerund:       "Pausing",
+	past:         "paused",
+	title:        "Pause",
+	titlePast:    "Paused",
+	notification: notification.ActionPause,
+	check:        client.Interface.CheckTorrentPause,
+	apply:        client.Interface.PauseTorrents,
+}
+
 var pauseCmd = &cobra.Command{
 	Use:   "pause [CLIENT]",
 	Short: "Check torrent client for torrents to pause",
@@ -25,210 +55,261 @@ var pauseCmd = &cobra.Command{
 
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := cmd.Context()
-		start := time.Now()
-
-		// init core
-		if !initialized {
-			initCore(true)
-			initialized = true
-		}
-
-		// set log
-		log := logger.GetLogger("pause")
-
-		noti := notification.NewDiscordSender(log, config.Config.Notifications)
-
-		// retrieve client object
-		clientName := args[0]
-		clientConfig, ok := config.Config.Clients[clientName]
-		if !ok {
-			log.Fatalf("No client configuration found for: %q", clientName)
-		}
-
-		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
-			log.WithError(err).Fatal("Failed validating client is enabled")
-		}
-
-		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed determining client type")
-		}
-
-		// retrieve client free space path (needed for Deluge free space check)
-		clientFreeSpacePath, _ := getClientConfigString("free_space_path", clientConfig)
+		runPauseAction(cmd, args, pauseTorrents)
+	},
+}
 
-		// retrieve client filters
-		clientFilter, err := getClientFilter(clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving client filter")
-		}
+func init() {
+	rootCmd.AddCommand(pauseCmd)
 
-		if flagFilterName != "" {
-			clientFilter, err = getFilter(flagFilterName)
-			if err != nil {
-				log.WithError(err).Fatal("Failed retrieving specified filter")
-			}
-		}
+	pauseCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	pauseCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	pauseCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
 
-		// compile client filters
-		exp, err := expression.Compile(clientFilter)
+// runPauseAction runs the pause or resume command against the client of args
+func runPauseAction(cmd *cobra.Command, args []string, action pauseAction) {
+	ctx := cmd.Context()
+	start := time.Now()
+
+	// init core
+	if !initialized {
+		initCore(true)
+		initialized = true
+	}
+
+	// set log
+	log := logger.GetLogger(action.name)
+	runOutcome.track()
+
+	noti := newNotificationSender(log)
+
+	// resolve targeted torrent hashes
+	hashes, err := resolveTargetHashes()
+	if err != nil {
+		log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+	}
+
+	// retrieve client object
+	clientName := args[0]
+	clientConfig, ok := config.Config.Clients[clientName]
+	if !ok {
+		log.Fatalf("No client configuration found for: %q", clientName)
+	}
+
+	// validate client is enabled
+	if err := tqm.ValidateClientEnabled(clientConfig); err != nil {
+		log.WithError(err).Fatal("Failed validating client is enabled")
+	}
+
+	// retrieve client type
+	clientType, err := tqm.ClientString("type", clientConfig)
+	if err != nil {
+		log.WithError(err).Fatal("Failed determining client type")
+	}
+
+	// retrieve client free space path (needed for Deluge free space check)
+	clientFreeSpacePath, _ := tqm.ClientString("free_space_path", clientConfig)
+
+	// retrieve client filters
+	clientFilter, err := tqm.ClientFilter(clientConfig)
+	if err != nil {
+		log.WithError(err).Fatal("Failed retrieving client filter")
+	}
+
+	if flagFilterName != "" {
+		clientFilter, err = getFilter(flagFilterName)
 		if err != nil {
-			log.WithError(err).Fatal("Failed compiling client filters")
+			log.WithError(err).Fatal("Failed retrieving specified filter")
 		}
-
-		// load client object
-		c, err := client.NewClient(*clientType, clientName, exp)
+	}
+
+	// compile client filters
+	exp, err := expression.Compile(clientFilter)
+	if err != nil {
+		log.WithError(err).Fatal("Failed compiling client filters")
+	}
+
+	// load client object
+	c, err := client.NewClient(*clientType, clientName, exp)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
+	}
+
+	log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+	// connect to client
+	if err := c.Connect(ctx); err != nil {
+		log.WithError(err).Fatal("Failed connecting")
+	} else {
+		log.Debugf("Connected to client")
+	}
+
+	// get free disk space (can/will be used by filters)
+	switch {
+	case tqm.ReportsFreeSpace(c):
+		space, err := c.GetCurrentFreeSpace(ctx, "")
 		if err != nil {
-			log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
-		}
-
-		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
-
-		// connect to client
-		if err := c.Connect(ctx); err != nil {
-			log.WithError(err).Fatal("Failed connecting")
+			log.WithError(err).Error("Failed retrieving free-space")
 		} else {
-			log.Debugf("Connected to client")
+			log.Infof("Retrieved free-space: %v (%.2f GB)",
+				formatting.Bytes(uint64(space)), c.GetFreeSpace())
 		}
 
-		// get free disk space (can/will be used by filters)
-		switch *clientType {
-		case "qbittorrent":
-			space, err := c.GetCurrentFreeSpace(ctx, "")
+	case *clientType == "deluge":
+		if clientFreeSpacePath != nil {
+			space, err := c.GetCurrentFreeSpace(ctx, *clientFreeSpacePath)
 			if err != nil {
-				log.WithError(err).Error("Failed retrieving free-space")
+				log.WithError(err).Errorf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
+				os.Exit(exitFatal)
 			} else {
-				log.Infof("Retrieved free-space: %v (%.2f GB)",
-					humanize.IBytes(uint64(space)), c.GetFreeSpace())
+				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
+					formatting.Bytes(uint64(space)), c.GetFreeSpace())
 			}
-
-		case "deluge":
-			if clientFreeSpacePath != nil {
-				space, err := c.GetCurrentFreeSpace(ctx, *clientFreeSpacePath)
-				if err != nil {
-					log.WithError(err).Errorf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
-					os.Exit(1)
-				} else {
-					log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
-						humanize.IBytes(uint64(space)), c.GetFreeSpace())
-				}
-			} else {
-				if filterUsesFreeSpace(clientFilter) {
-					log.Error("Deluge requires free_space_path to be configured in order to retrieve free space information")
-					os.Exit(1)
-				}
+		} else {
+			if filterUsesFreeSpace(clientFilter) {
+				log.Error("Deluge requires free_space_path to be configured in order to retrieve free space information")
+				os.Exit(exitFatal)
 			}
 		}
-
-		// retrieve torrents
-		torrents, err := c.GetTorrents(ctx)
+	}
+
+	// retrieve torrents
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil {
+		log.WithError(err).Fatal("Failed retrieving torrents")
+	} else {
+		log.Infof("Retrieved %d torrents", len(torrents))
+	}
+
+	if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, action.name, true) {
+		// download path mapping
+		clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, action.name, tqm.PathMappingHardlinks)
 		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving torrents")
-		} else {
-			log.Infof("Retrieved %d torrents", len(torrents))
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		} else if clientDownloadPathMapping != nil {
+			log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
+				clientDownloadPathMapping)
 		}
 
-		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "pause", true) {
-			// download path mapping
-			clientDownloadPathMapping, err := getClientDownloadPathMapping(clientConfig)
-			if err != nil {
-				log.WithError(err).Fatal("Failed loading client download path mappings")
-			} else if clientDownloadPathMapping != nil {
-				log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
-					clientDownloadPathMapping)
-			}
-
-			// create map of paths associated to underlying file ids
-			start := time.Now()
-			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
-			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), time.Since(start))
+		// create map of paths associated to underlying file ids
+		start := time.Now()
+		hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+		log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
 
-			// add HardlinkedOutsideClient field to torrents
-			for h, t := range torrents {
-				t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
-				torrents[h] = t
-			}
-		} else {
-			log.Warnf("Not mapping hardlinks for client %q", clientName)
-			log.Warnf("If your setup involves multiple torrents sharing the same underlying file using hardlinks, or you are using the 'HardlinkedOutsideClient' field in your filters, you should add 'pause' to the 'MapHardlinksFor' field in your filter configuration")
+		// add HardlinkedOutsideClient field to torrents
+		for h, t := range torrents {
+			t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+			torrents[h] = t
 		}
+	} else {
+		log.Warnf("Not mapping hardlinks for client %q", clientName)
+		log.Warnf("If your setup involves multiple torrents sharing the same underlying file using hardlinks, or you are using the 'HardlinkedOutsideClient' field in your filters, you should add '%s' to the 'MapHardlinksFor' field in your filter configuration", action.name)
+	}
+
+	// scope to the targeted torrents
+	torrents = scopeTorrents(log, torrents, hashes)
+
+	selected, fields := selectPauseTorrents(ctx, log, c, torrents, action, noti)
+	runOutcome.record(len(selected), 0)
+
+	if err := applyPauseAction(ctx, log, c, action, selected); err != nil {
+		log.WithError(err).Fatalf("Failed %s torrents: %v", strings.ToLower(action.gerund), err)
+	}
+
+	if !noti.CanSend() {
+		log.Debug("Notifications disabled, skipping...")
+		return
+	}
+
+	sendErr := noti.Send(
+		"Torrent "+action.title,
+		fmt.Sprintf("%s **%d** torrent(s)", action.titlePast, len(selected)),
+		clientName,
+		time.Since(start),
+		fields,
+		flagDryRun,
+	)
+	if sendErr != nil {
+		log.WithError(sendErr).Error("Failed sending notification")
+	}
+}
 
-		var (
-			pauseList []string
-			fields    []notification.Field
-		)
-
-		// iterate through torrents
-		for _, t := range torrents {
-			// check if torrent should be ignored
-			if ignored, reason, err := c.ShouldIgnore(ctx, &t); err != nil {
-				log.WithError(err).Errorf("Failed checking ignore filters for torrent: %q", t.Name)
-				continue
-			} else if ignored {
-				if reason != "" {
-					log.Debugf("Ignoring torrent: %q (reason: %s)", t.Name, reason)
-				} else {
-					log.Debugf("Ignoring torrent: %q", t.Name)
-				}
-				continue
-			}
-
-			// check if torrent should be paused
-			if paused, err := c.CheckTorrentPause(ctx, &t); err != nil {
-				log.WithError(err).Errorf("Failed checking pause filters for torrent: %q", t.Name)
-				continue
-			} else if paused {
-				log.Infof("Adding torrent to pause list: %q", t.Name)
-				pauseList = append(pauseList, t.Hash)
-				fields = append(fields, noti.BuildField(notification.ActionPause, notification.BuildOptions{
-					Torrent: t,
-				}))
+// selectPauseTorrents returns the hashes of the torrents the filters of action apply to, with their notification fields
+func selectPauseTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
+	action pauseAction, noti notification.Sender) ([]string, []notification.Field) {
+	var (
+		hashes []string
+		fields []notification.Field
+	)
+
+	// iterate through torrents
+	for _, t := range torrents {
+		if t.IsPaused() != action.paused {
+			if action.paused {
+				log.Tracef("Torrent not paused: %q", t.Name)
+			} else {
+				log.Tracef("Torrent already paused: %q", t.Name)
 			}
+			continue
 		}
 
-		// pause torrents if not dry run
-		if !flagDryRun {
-			if len(pauseList) > 0 {
-				log.Infof("Pausing %d torrent(s)...", len(pauseList))
-				if err := c.PauseTorrents(ctx, pauseList); err != nil {
-					log.WithError(err).Fatalf("Failed pausing torrents: %v", err)
-				}
-				log.Infof("Successfully paused %d torrent(s)", len(pauseList))
+		// check if torrent should be ignored
+		if ignored, reason, err := c.ShouldIgnore(ctx, &t); err != nil {
+			log.WithError(err).Errorf("Failed checking ignore filters for torrent: %q", t.Name)
+			runOutcome.record(0, 1)
+			continue
+		} else if ignored {
+			if reason != "" {
+				log.Debugf("Ignoring torrent: %q (reason: %s)", t.Name, reason)
 			} else {
-				log.Info("No torrents to pause")
-			}
-		} else {
-			if len(pauseList) > 0 {
-				log.Infof("[DRY-RUN] Would pause %d torrent(s)", len(pauseList))
-			} else {
-				log.Info("[DRY-RUN] No torrents would be paused")
+				log.Debugf("Ignoring torrent: %q", t.Name)
 			}
+			continue
 		}
 
-		if !noti.CanSend() {
-			log.Debug("Notifications disabled, skipping...")
-			return
+		// check if the action applies to the torrent
+		if match, err := action.check(c, ctx, &t); err != nil {
+			log.WithError(err).Errorf("Failed checking %s filters for torrent: %q", action.name, t.Name)
+			runOutcome.record(0, 1)
+			continue
+		} else if match {
+			if !t.APIDividerPrinted {
+				log.Info("-----")
+			}
+			log.Infof("Adding torrent to %s list: %q", action.name, t.Name)
+			log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
+				"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
+			hashes = append(hashes, t.Hash)
+			fields = append(fields, noti.BuildField(action.notification, notification.BuildOptions{
+				Torrent: t,
+			}))
 		}
+	}
 
-		sendErr := noti.Send(
-			"Torrent Pause",
-			fmt.Sprintf("Paused **%d** torrent(s)", len(pauseList)),
-			clientName,
-			time.Since(start),
-			fields,
-			flagDryRun,
-		)
-		if sendErr != nil {
-			log.WithError(sendErr).Error("Failed sending notification")
-		}
-	},
+	return hashes, fields
 }
 
-func init() {
-	rootCmd.AddCommand(pauseCmd)
-
-	pauseCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+// applyPauseAction pauses or resumes the torrents of hashes, unless in dry-run
+func applyPauseAction(ctx context.Context, log *logrus.Entry, c client.Interface, action pauseAction, hashes []string) error {
+	if flagDryRun {
+		if len(hashes) > 0 {
+			log.Infof("[DRY-RUN] Would %s %d torrent(s)", action.name, len(hashes))
+		} else {
+			log.Infof("[DRY-RUN] No torrents would be %s", action.past)
+		}
+		return nil
+	}
+
+	if len(hashes) == 0 {
+		log.Infof("No torrents to %s", action.name)
+		return nil
+	}
+
+	log.Infof("%s %d torrent(s)...", action.gerund, len(hashes))
+	if err := action.apply(c, ctx, hashes); err != nil {
+		return err
+	}
+	log.Infof("Successfully %s %d torrent(s)", action.past, len(hashes))
+	return nil
 }
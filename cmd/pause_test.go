@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// pauseClient matches the pause and resume filters by hash and records the torrents paused and resumed
+type pauseClient struct {
+	filterClient
+
+	matched map[string]bool
+	err     error
+	paused  []string
+	resumed []string
+}
+
+func (c *pauseClient) CheckTorrentPause(_ context.Context, t *config.Torrent) (bool, error) {
+	return c.matched[t.Hash], nil
+}
+
+func (c *pauseClient) CheckTorrentResume(_ context.Context, t *config.Torrent) (bool, error) {
+	return c.matched[t.Hash], nil
+}
+
+func (c *pauseClient) PauseTorrents(_ context.Context, hashes []string) error {
+	c.paused = append(c.paused, hashes...)
+	return c.err
+}
+
+func (c *pauseClient) ResumeTorrents(_ context.Context, hashes []string) error {
+	c.resumed = append(c.resumed, hashes...)
+	return c.err
+}
+
+func TestSelectPauseTorrents(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"seeding":         {Hash: "seeding", Name: "Seeding", State: "uploading"},
+		"seeding-ignored": {Hash: "seeding-ignored", Name: "Seeding.Ignored", State: "uploading"},
+		"seeding-kept":    {Hash: "seeding-kept", Name: "Seeding.Kept", State: "stalledUP"},
+		"paused":          {Hash: "paused", Name: "Paused", State: "pausedUP"},
+		"stopped":         {Hash: "stopped", Name: "Stopped", State: "stoppedDL"},
+		"paused-ignored":  {Hash: "paused-ignored", Name: "Paused.Ignored", State: "pausedUP"},
+	}
+
+	c := &pauseClient{
+		filterClient: filterClient{ignored: map[string]bool{"seeding-ignored": true, "paused-ignored": true}},
+		matched: map[string]bool{"seeding": true, "seeding-ignored": true, "paused": true, "stopped": true,
+			"paused-ignored": true},
+	}
+
+	tests := []struct {
+		name     string
+		action   pauseAction
+		expected []string
+	}{
+		{name: "pause", action: pauseTorrents, expected: []string{"seeding"}},
+		{name: "resume", action: resumeTorrents, expected: []string{"paused", "stopped"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, fields := selectPauseTorrents(context.Background(), logrus.NewEntry(logrus.New()), c, torrents,
+				tt.action, &silentSender{})
+			slices.Sort(hashes)
+			assert.Equal(t, tt.expected, hashes)
+			assert.Len(t, fields, len(tt.expected))
+		})
+	}
+}
+
+func TestApplyPauseAction(t *testing.T) {
+	prevDryRun := flagDryRun
+	t.Cleanup(func() { flagDryRun = prevDryRun })
+
+	log := logrus.NewEntry(logrus.New())
+	ctx := context.Background()
+
+	// nothing is paused in dry-run
+	flagDryRun = true
+	c := &pauseClient{}
+	require.NoError(t, applyPauseAction(ctx, log, c, pauseTorrents, []string{"a"}))
+	assert.Empty(t, c.paused)
+
+	flagDryRun = false
+	require.NoError(t, applyPauseAction(ctx, log, c, pauseTorrents, []string{"a", "b"}))
+	require.NoError(t, applyPauseAction(ctx, log, c, resumeTorrents, []string{"c"}))
+	require.NoError(t, applyPauseAction(ctx, log, c, resumeTorrents, nil))
+	assert.Equal(t, []string{"a", "b"}, c.paused)
+	assert.Equal(t, []string{"c"}, c.resumed)
+
+	c = &pauseClient{err: errors.New("connection refused")}
+	assert.EqualError(t, applyPauseAction(ctx, log, c, resumeTorrents, []string{"a"}), "connection refused")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+var flagPruneCategoriesExclude []string
+
+var pruneCategoriesCmd = &cobra.Command{
+	Use:   "prune-categories [CLIENT]",
+	Short: "Delete categories without torrents and data (only qbit)",
+	Long: `This command can be used to delete categories of a torrent client that no torrent is assigned to and whose
+save path contains no files. Categories managed by other applications (e.g. *arr apps) can be kept with --exclude.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("prune-categories")
+		runOutcome.track()
+
+		// load client object
+		clientName := args[0]
+		c, _, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		if !supports(log, c, c.Capabilities().Categories, "Pruning categories") {
+			return
+		}
+		cc := c.(client.CategoryInterface)
+
+		log.Infof("Initialized client %q, type: %s", clientName, cc.Type())
+
+		if err := cc.LoadLabelPathMap(ctx); err != nil {
+			log.WithError(err).Fatal("Failed loading categories")
+		}
+
+		torrents, err := cc.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d categories and %d torrents", len(cc.LabelPathMap()), len(torrents))
+		}
+
+		// save paths are reported by the client and have to be mapped to where tqm sees them
+		clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "prune-categories")
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+
+		var empty []string
+		for _, category := range unusedCategories(cc.LabelPathMap(), torrents, flagPruneCategoriesExclude) {
+			path := paths.MapPath(cc.LabelPathMap()[category], clientDownloadPathMapping)
+
+			hasData, err := pathHasFiles(path)
+			if err != nil {
+				log.WithError(err).Warnf("Skipping category %q, failed checking its save path: %q", category, path)
+				runOutcome.record(0, 1)
+				continue
+			} else if hasData {
+				log.Debugf("Skipping category %q, its save path contains files: %q", category, path)
+				continue
+			}
+
+			log.Infof("Empty category: %q (save path: %q)", category, path)
+			empty = append(empty, category)
+		}
+
+		runOutcome.record(len(empty), 0)
+		if len(empty) == 0 {
+			log.Info("No empty categories found")
+			return
+		}
+
+		if flagDryRun {
+			log.Infof("[DRY-RUN] Would delete %d empty categories", len(empty))
+			return
+		}
+
+		if err := cc.DeleteCategories(ctx, empty); err != nil {
+			log.WithError(err).Fatal("Failed deleting empty categories")
+		}
+
+		log.Infof("Deleted %d empty categories: %s", len(empty), strings.Join(empty, ", "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCategoriesCmd)
+
+	pruneCategoriesCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	pruneCategoriesCmd.Flags().StringSliceVar(&flagPruneCategoriesExclude, "exclude", nil, "Categories to keep, supports glob patterns (e.g. 'sonarr*', can be repeated)")
+}
+
+// unusedCategories returns the sorted categories no torrent is assigned to, except for those matching exclude
+func unusedCategories(categories map[string]string, torrents map[string]config.Torrent, exclude []string) []string {
+	used := make(map[string]struct{})
+	for _, t := range torrents {
+		used[t.Label] = struct{}{}
+	}
+
+	var unused []string
+	for category := range categories {
+		if _, ok := used[category]; ok {
+			continue
+		}
+
+		if slices.ContainsFunc(exclude, func(pattern string) bool {
+			match, _ := filepath.Match(pattern, category)
+			return match
+		}) {
+			continue
+		}
+
+		unused = append(unused, category)
+	}
+
+	slices.Sort(unused)
+	return unused
+}
+
+// pathHasFiles reports whether path contains any file, a missing path has none. Failing to read part of path is an
+// error, as files could be missed
+func pathHasFiles(path string) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	hasFiles := false
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			hasFiles = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("walk %s: %w", path, err)
+	}
+
+	return hasFiles, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestUnusedCategories(t *testing.T) {
+	categories := map[string]string{
+		"movies":       "/downloads/movies",
+		"tv":           "/downloads/tv",
+		"old":          "/downloads/old",
+		"sonarr-4k":    "/downloads/sonarr-4k",
+		"radarr-anime": "/downloads/radarr-anime",
+	}
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", Label: "movies"},
+		"b": {Hash: "b", Label: "tv"},
+		"c": {Hash: "c"},
+	}
+
+	assert.Equal(t, []string{"old", "radarr-anime", "sonarr-4k"}, unusedCategories(categories, torrents, nil))
+	assert.Equal(t, []string{"old"}, unusedCategories(categories, torrents, []string{"sonarr*", "radarr-anime"}))
+}
+
+func TestPathHasFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	hasFiles, err := pathHasFiles(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.False(t, hasFiles)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "empty", "sub"), 0o755))
+	hasFiles, err = pathHasFiles(filepath.Join(dir, "empty"))
+	require.NoError(t, err)
+	assert.False(t, hasFiles)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty", "sub", "file.mkv"), []byte("data"), 0o644))
+	hasFiles, err = pathHasFiles(filepath.Join(dir, "empty"))
+	require.NoError(t, err)
+	assert.True(t, hasFiles)
+
+	// an unreadable folder could contain files
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "unreadable", "sub"), 0o755))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "unreadable", "sub"), 0))
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(dir, "unreadable", "sub"), 0o755) })
+	_, err = pathHasFiles(filepath.Join(dir, "unreadable"))
+	assert.Error(t, err)
+}
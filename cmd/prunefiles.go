@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/paths"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+var flagPruneDelete bool
+
+var pruneFilesCmd = &cobra.Command{
+	Use:   "prune-files [CLIENT]",
+	Short: "Skip the download of unwanted files of torrents (only qbit)",
+	Long: `This command marks the files of torrents matching the prune_files patterns of the filter (e.g. samples or screens)
+as "do not download", reclaiming space without removing the torrents. With --delete, unwanted files already downloaded
+are deleted as well, unless they share a piece with a wanted file or belong to another torrent.`,
+	Example: `  tqm prune-files qbt --dry-run
+  tqm prune-files qbt --delete`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		startTime := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("prune-files")
+		runOutcome.track()
+
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		if !supports(log, c, c.Capabilities().Files, "Pruning files") {
+			return
+		}
+		fc := c.(client.FileInterface)
+
+		if len(clientFilter.PruneFiles.Patterns) == 0 {
+			log.Fatal("No unwanted files configured in the prune_files section of the filter")
+		}
+
+		patterns, err := newFilePatterns("prune_files", clientFilter.PruneFiles.Patterns)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading prune_files")
+		}
+
+		downloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "prune-files", tqm.PathMappingHardlinks)
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading client download path mappings")
+		}
+
+		log.Infof("Initialized client %q, type: %s", clientName, fc.Type())
+
+		// retrieve torrents, all of them are needed to keep the files shared with cross-seeds
+		torrents, err := fc.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		tfm := torrentfilemap.New(torrents)
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		pruner := &filePruner{fc: fc, patterns: patterns, tfm: tfm, downloadPathMapping: downloadPathMapping}
+
+		var (
+			pruned, failed int
+			freed          int64
+			fields         []notification.Field
+		)
+
+		for _, t := range torrents {
+			ok, detail, bytes, err := pruner.prune(ctx, log, t)
+			if err != nil {
+				log.WithError(err).Errorf("Failed pruning files of torrent: %q", t.Name)
+				report.torrent(reportFailed, t, "", err.Error())
+				failed++
+				continue
+			} else if !ok {
+				continue
+			}
+
+			if !flagDryRun {
+				history.Record(history.Entry{Client: clientName, Action: history.ActionPruneFiles, Hash: t.Hash,
+					Name: t.Name, Tracker: t.TrackerName, Detail: detail, Reclaimed: bytes})
+			}
+
+			report.torrent(reportPruned, t, "", detail)
+			fields = append(fields, noti.BuildField(notification.ActionPruneFiles, notification.BuildOptions{
+				Torrent:       t,
+				RemovalReason: detail,
+			}))
+			freed += bytes
+			pruned++
+		}
+
+		log.Info("-----")
+		log.WithField("reclaimed_space", formatting.Bytes(uint64(freed))).
+			Infof("Pruned torrents: %d, %d failures", pruned, failed)
+		runOutcome.record(pruned, failed)
+
+		if !noti.CanSend() {
+			log.Debug("Notifications disabled, skipping...")
+			return
+		}
+
+		sendErr := noti.Send(
+			"Torrent Files Pruned",
+			fmt.Sprintf("Pruned the files of **%d** torrent(s) | Total reclaimed **%s**", pruned,
+				formatting.Bytes(uint64(freed))),
+			clientName,
+			time.Since(startTime),
+			fields,
+			flagDryRun,
+		)
+		if sendErr != nil {
+			log.WithError(sendErr).Error("Failed sending notification")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneFilesCmd)
+
+	pruneFilesCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	pruneFilesCmd.Flags().BoolVar(&flagPruneDelete, "delete", false, "Also delete unwanted files which were already downloaded")
+	pruneFilesCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	pruneFilesCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+	pruneFilesCmd.Flags().StringVar(&flagReport, "report", "", "Write every decision (kept, ignored, removed and the matched expression) to this JSON file, also in dry-run")
+}
+
+// filePruner skips, and with --delete deletes, the unwanted files of torrents
+type filePruner struct {
+	fc                  client.FileInterface
+	patterns            filePatterns
+	tfm                 *torrentfilemap.TorrentFileMap
+	downloadPathMapping map[string]string
+}
+
+// prune prunes the unwanted files of t, returning whether any file was pruned, a description and the bytes deleted
+func (p *filePruner) prune(ctx context.Context, log *logrus.Entry, t config.Torrent) (bool, string, int64, error) {
+	ignore, reason, err := p.fc.ShouldIgnore(ctx, &t)
+	if err != nil {
+		return false, "", 0, err
+	} else if ignore {
+		log.Tracef("Ignoring torrent: %q", t.Name)
+		report.torrent(reportIgnored, t, reason, "")
+		return false, "", 0, nil
+	}
+
+	if ok, err := p.fc.ShouldPruneFiles(ctx, &t); err != nil {
+		return false, "", 0, err
+	} else if !ok {
+		log.Tracef("Not pruning files of torrent: %q", t.Name)
+		report.torrent(reportKept, t, "", "no prune_files expression matched")
+		return false, "", 0, nil
+	}
+
+	files, err := p.fc.GetTorrentFiles(ctx, t.Hash)
+	if err != nil {
+		return false, "", 0, err
+	}
+
+	plan := planPruneFiles(files, p.patterns, flagPruneDelete)
+	if plan.all {
+		log.Warnf("All files match the prune_files patterns, keeping torrent: %q", t.Name)
+		report.torrent(reportKept, t, "", "all files are unwanted")
+		return false, "", 0, nil
+	}
+
+	// files shared with other torrents are still needed by them
+	deletes := make([]client.TorrentFile, 0, len(plan.delete))
+	for _, f := range plan.delete {
+		clientPath := filepath.Join(t.Path, f.Name)
+		if !p.tfm.IsUnique(config.Torrent{Hash: t.Hash, Files: []string{clientPath}}) {
+			log.Debugf("File belongs to other torrents, keeping: %q", clientPath)
+			continue
+		}
+		deletes = append(deletes, f)
+	}
+
+	if len(plan.skip) == 0 && len(deletes) == 0 {
+		log.Tracef("No unwanted files to prune: %q", t.Name)
+		report.torrent(reportKept, t, "", "no unwanted files to prune")
+		return false, "", 0, nil
+	}
+
+	if !t.APIDividerPrinted {
+		log.Info("-----")
+	}
+	log.Infof("Pruning files of: %q - skipping %d file(s), deleting %d", t.Name, len(plan.skip), len(deletes))
+	if plan.pieceShared > 0 {
+		log.Infof("Keeping %d unwanted file(s) sharing a piece with a wanted file on disk", plan.pieceShared)
+	}
+
+	var detail []string
+	if len(plan.skip) > 0 {
+		detail = append(detail, fmt.Sprintf("skipped %d file(s)", len(plan.skip)))
+	}
+
+	if flagDryRun {
+		for _, f := range plan.skip {
+			log.Infof("Would skip: %q", f.Name)
+		}
+		var bytes int64
+		for _, f := range deletes {
+			log.Infof("Would delete: %q (%s)", f.Name, formatting.Bytes(uint64(f.Size)))
+			bytes += f.Size
+		}
+		if len(deletes) > 0 {
+			detail = append(detail, fmt.Sprintf("deleted %d file(s)", len(deletes)))
+		}
+		log.Warn("Dry-run enabled, skipping pruning...")
+		return true, strings.Join(detail, ", "), bytes, nil
+	}
+
+	// the files are skipped first, the client would download them again otherwise
+	if len(plan.skip) > 0 {
+		indexes := make([]int, 0, len(plan.skip))
+		for _, f := range plan.skip {
+			indexes = append(indexes, f.Index)
+		}
+		if err := p.fc.SkipFiles(ctx, t.Hash, indexes); err != nil {
+			return false, "", 0, err
+		}
+		log.Infof("Skipped %d file(s)", len(indexes))
+	}
+
+	var (
+		bytes   int64
+		deleted int
+	)
+	for _, f := range deletes {
+		localPath := paths.MapPath(filepath.Join(t.Path, f.Name), p.downloadPathMapping)
+		info, err := os.Stat(localPath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			log.WithError(err).Errorf("Failed checking file: %q", localPath)
+			continue
+		}
+
+		if err := os.Remove(localPath); err != nil {
+			log.WithError(err).Errorf("Failed deleting file: %q", localPath)
+			continue
+		}
+		log.Infof("Deleted: %q (%s)", localPath, formatting.Bytes(uint64(info.Size())))
+		bytes += info.Size()
+		deleted++
+	}
+	if deleted > 0 {
+		detail = append(detail, fmt.Sprintf("deleted %d file(s)", deleted))
+	}
+
+	return true, strings.Join(detail, ", "), bytes, nil
+}
+
+// prunePlan are the unwanted files of a torrent
+type prunePlan struct {
+	// skip are the unwanted files still downloaded by the client
+	skip []client.TorrentFile
+	// delete are the unwanted files with data on disk which share no piece with a wanted file
+	delete []client.TorrentFile
+	// pieceShared is the number of unwanted files with data on disk kept as they share a piece with a wanted file
+	pieceShared int
+	// all is set when every file is unwanted, the torrent is then left alone
+	all bool
+}
+
+// planPruneFiles returns the unwanted files matching patterns, the files to delete are only planned with deleteFiles
+func planPruneFiles(files []client.TorrentFile, patterns filePatterns, deleteFiles bool) prunePlan {
+	var (
+		plan             prunePlan
+		unwanted, wanted []client.TorrentFile
+	)
+
+	for _, f := range files {
+		if patterns.match(f.Name) {
+			unwanted = append(unwanted, f)
+		} else {
+			wanted = append(wanted, f)
+		}
+	}
+
+	if len(unwanted) == 0 {
+		return plan
+	} else if len(wanted) == 0 {
+		plan.all = true
+		return plan
+	}
+
+	for _, f := range unwanted {
+		if !f.Skipped {
+			plan.skip = append(plan.skip, f)
+		}
+
+		if !deleteFiles || f.Progress <= 0 {
+			continue
+		}
+
+		// the data of pieces shared with a wanted file is still needed to seed the wanted file
+		shared := false
+		for _, w := range wanted {
+			if f.FirstPiece <= w.LastPiece && w.FirstPiece <= f.LastPiece {
+				shared = true
+				break
+			}
+		}
+
+		if shared {
+			plan.pieceShared++
+			continue
+		}
+		plan.delete = append(plan.delete, f)
+	}
+
+	return plan
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+)
+
+func TestPlanPruneFiles(t *testing.T) {
+	patterns, err := newFilePatterns("prune_files", []string{"*sample*", "screens/*", "*.nfo"})
+	require.NoError(t, err)
+
+	movie := client.TorrentFile{Index: 0, Name: "Movie/movie.mkv", Progress: 1, FirstPiece: 0, LastPiece: 99}
+	sample := client.TorrentFile{Index: 1, Name: "Movie/Sample/sample.mkv", Progress: 1, FirstPiece: 100, LastPiece: 104}
+	// shares its first piece with the movie
+	nfo := client.TorrentFile{Index: 2, Name: "Movie/movie.nfo", Progress: 1, FirstPiece: 99, LastPiece: 99}
+	screen := client.TorrentFile{Index: 3, Name: "Screens/1.png", Progress: 0, FirstPiece: 105, LastPiece: 105}
+	skipped := client.TorrentFile{Index: 4, Name: "Screens/2.png", Progress: 1, Skipped: true, FirstPiece: 106, LastPiece: 106}
+
+	tests := []struct {
+		name        string
+		files       []client.TorrentFile
+		deleteFiles bool
+		want        prunePlan
+	}{
+		{
+			name:  "skip only",
+			files: []client.TorrentFile{movie, sample, nfo, screen, skipped},
+			want:  prunePlan{skip: []client.TorrentFile{sample, nfo, screen}},
+		},
+		{
+			name:        "delete downloaded files not sharing a piece",
+			files:       []client.TorrentFile{movie, sample, nfo, screen, skipped},
+			deleteFiles: true,
+			want: prunePlan{skip: []client.TorrentFile{sample, nfo, screen},
+				delete: []client.TorrentFile{sample, skipped}, pieceShared: 1},
+		},
+		{
+			name:  "no unwanted files",
+			files: []client.TorrentFile{movie},
+			want:  prunePlan{},
+		},
+		{
+			name:  "all files unwanted",
+			files: []client.TorrentFile{sample, nfo},
+			want:  prunePlan{all: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, planPruneFiles(tt.files, patterns, tt.deleteFiles))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+var flagPruneTagsKeepFilterTags bool
+
+var pruneTagsCmd = &cobra.Command{
+	Use:   "prune-tags [CLIENT]",
+	Short: "Delete tags not applied to any torrent (only qbit)",
+	Long: `This command can be used to delete tags of a torrent client that are not applied to any torrent,
+e.g. tags left behind by retag rules that were changed or removed.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("prune-tags")
+		runOutcome.track()
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, _, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		if !supports(log, c, c.Capabilities().Tags, "Pruning tags") {
+			return
+		}
+		ct := c.(client.TagInterface)
+
+		log.Infof("Initialized client %q, type: %s", clientName, ct.Type())
+
+		tags, err := ct.GetTags(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving tags")
+		}
+
+		torrents, err := ct.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d tags and %d torrents", len(tags), len(torrents))
+		}
+
+		// tags of the filter's tag rules are kept, they would be recreated by the next retag run anyway
+		var keep []string
+		if flagPruneTagsKeepFilterTags {
+			for _, tag := range clientFilter.Tag {
+				keep = append(keep, tag.Name)
+			}
+		}
+
+		unused := unusedTags(tags, torrents, keep)
+		runOutcome.record(len(unused), 0)
+		if len(unused) == 0 {
+			log.Info("No unused tags found")
+			return
+		}
+
+		for _, tag := range unused {
+			log.Infof("Unused tag: %q", tag)
+		}
+
+		if flagDryRun {
+			log.Infof("[DRY-RUN] Would delete %d unused tag(s)", len(unused))
+			return
+		}
+
+		if err := ct.DeleteTags(ctx, unused); err != nil {
+			log.WithError(err).Fatal("Failed deleting unused tags")
+		}
+
+		log.Infof("Deleted %d unused tag(s): %s", len(unused), strings.Join(unused, ", "))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneTagsCmd)
+
+	pruneTagsCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	pruneTagsCmd.Flags().BoolVar(&flagPruneTagsKeepFilterTags, "keep-filter-tags", true, "Keep tags of the filter's tag rules even when not applied to any torrent")
+}
+
+// unusedTags returns the sorted tags not applied to any of torrents, except for those in keep
+func unusedTags(tags []string, torrents map[string]config.Torrent, keep []string) []string {
+	used := make(map[string]struct{})
+	for _, t := range torrents {
+		for tag := range t.Tags {
+			used[tag] = struct{}{}
+		}
+	}
+
+	var unused []string
+	for _, tag := range tags {
+		if _, ok := used[tag]; ok || slices.Contains(keep, tag) {
+			continue
+		}
+		unused = append(unused, tag)
+	}
+
+	slices.Sort(unused)
+	return unused
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestUnusedTags(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", Tags: map[string]struct{}{"low-seed": {}}},
+		"b": {Hash: "b", Tags: map[string]struct{}{"low-seed": {}, "permaseed": {}}},
+		"c": {Hash: "c"},
+	}
+
+	tags := []string{"stale", "permaseed", "low-seed", "old", "unregistered"}
+
+	assert.Equal(t, []string{"old", "stale", "unregistered"}, unusedTags(tags, torrents, nil))
+	assert.Equal(t, []string{"old", "stale"}, unusedTags(tags, torrents, []string{"unregistered"}))
+	assert.Empty(t, unusedTags([]string{"low-seed"}, torrents, nil))
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+var (
+	flagReannounceAttempts int
+	flagReannounceInterval time.Duration
+)
+
+var reannounceCmd = &cobra.Command{
+	Use:   "reannounce [CLIENT]",
+	Short: "Check torrent client for torrents to force a reannounce of",
+	Long: `This command can be used to force a reannounce of torrents matching the reannounce expressions of the client's filter,
+e.g. torrents stuck with a not working tracker. Torrents still matching after --interval are reannounced again, up to --attempts times.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		start := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("reannounce")
+		runOutcome.track()
+
+		if flagReannounceAttempts < 1 {
+			log.Fatalf("Invalid number of attempts: %d (must be at least 1)", flagReannounceAttempts)
+		}
+
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		if len(clientFilter.Reannounce) == 0 {
+			log.Warn("No reannounce expressions configured in the filter's reannounce section")
+		}
+
+		// get free disk space (can/will be used by filters)
+		if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+			log.WithError(err).Error("Failed retrieving free-space")
+		}
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "reannounce", true) {
+			// download path mapping
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "reannounce", tqm.PathMappingHardlinks)
+			if err != nil {
+				log.WithError(err).Fatal("Failed loading client download path mappings")
+			} else if clientDownloadPathMapping != nil {
+				log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
+					clientDownloadPathMapping)
+			}
+
+			// create map of paths associated to underlying file ids
+			start := time.Now()
+			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
+
+			// add HardlinkedOutsideClient field to torrents
+			for h, t := range torrents {
+				t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+				torrents[h] = t
+			}
+		}
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		var (
+			reannounceList []string
+			fields         []notification.Field
+		)
+
+		// iterate through torrents
+		for _, t := range torrents {
+			// paused torrents do not announce
+			if t.IsPaused() {
+				log.Tracef("Torrent paused: %q", t.Name)
+				continue
+			}
+
+			// check if torrent should be ignored
+			if ignored, reason, err := c.ShouldIgnore(ctx, &t); err != nil {
+				log.WithError(err).Errorf("Failed checking ignore filters for torrent: %q", t.Name)
+				runOutcome.record(0, 1)
+				continue
+			} else if ignored {
+				if reason != "" {
+					log.Debugf("Ignoring torrent: %q (reason: %s)", t.Name, reason)
+				} else {
+					log.Debugf("Ignoring torrent: %q", t.Name)
+				}
+				continue
+			}
+
+			// check if torrent should be reannounced
+			if reannounce, err := c.CheckTorrentReannounce(ctx, &t); err != nil {
+				log.WithError(err).Errorf("Failed checking reannounce filters for torrent: %q", t.Name)
+				runOutcome.record(0, 1)
+				continue
+			} else if reannounce {
+				if !t.APIDividerPrinted {
+					log.Info("-----")
+				}
+				log.Infof("Adding torrent to reannounce list: %q", t.Name)
+				log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
+					"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
+				reannounceList = append(reannounceList, t.Hash)
+				fields = append(fields, noti.BuildField(notification.ActionReannounce, notification.BuildOptions{
+					Torrent: t,
+				}))
+			}
+		}
+
+		var remaining []string
+
+		// reannounce torrents if not dry run
+		if !flagDryRun {
+			if len(reannounceList) > 0 {
+				remaining, err = reannounceWithRetries(ctx, log, c, torrents, reannounceList, flagReannounceAttempts, flagReannounceInterval)
+				if err != nil {
+					log.WithError(err).Fatal("Failed reannouncing torrents")
+				}
+
+				runOutcome.record(len(reannounceList)-len(remaining), len(remaining))
+				if len(remaining) > 0 {
+					log.Warnf("%d of %d torrent(s) still match the reannounce filters after %d attempt(s)",
+						len(remaining), len(reannounceList), flagReannounceAttempts)
+				} else {
+					log.Infof("Successfully reannounced %d torrent(s)", len(reannounceList))
+				}
+			} else {
+				log.Info("No torrents to reannounce")
+			}
+		} else {
+			runOutcome.record(len(reannounceList), 0)
+			if len(reannounceList) > 0 {
+				log.Infof("[DRY-RUN] Would reannounce %d torrent(s)", len(reannounceList))
+			} else {
+				log.Info("[DRY-RUN] No torrents would be reannounced")
+			}
+		}
+
+		if !noti.CanSend() {
+			log.Debug("Notifications disabled, skipping...")
+			return
+		}
+
+		description := fmt.Sprintf("Reannounced **%d** torrent(s)", len(reannounceList))
+		if len(remaining) > 0 {
+			description += fmt.Sprintf(", **%d** still matching after %d attempt(s)", len(remaining), flagReannounceAttempts)
+		}
+
+		sendErr := noti.Send(
+			"Torrent Reannounce",
+			description,
+			clientName,
+			time.Since(start),
+			fields,
+			flagDryRun,
+		)
+		if sendErr != nil {
+			log.WithError(sendErr).Error("Failed sending notification")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reannounceCmd)
+
+	reannounceCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	reannounceCmd.Flags().IntVar(&flagReannounceAttempts, "attempts", 3, "Maximum number of times a torrent is reannounced")
+	reannounceCmd.Flags().DurationVar(&flagReannounceInterval, "interval", 15*time.Second, "Time to wait after reannouncing before checking torrents again")
+	reannounceCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	reannounceCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// reannounceWithRetries reannounces hashes until they no longer match the reannounce filters or attempts are exhausted,
+// waiting interval after every reannounce. It returns the hashes still matching after the last attempt.
+func reannounceWithRetries(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
+	hashes []string, attempts int, interval time.Duration) ([]string, error) {
+	pending := hashes
+
+	for attempt := 1; attempt <= attempts && len(pending) > 0; attempt++ {
+		log.Infof("Reannouncing %d torrent(s) (attempt %d/%d)...", len(pending), attempt, attempts)
+		if err := c.ReannounceTorrents(ctx, pending); err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		refreshed, err := c.GetTorrentsByHashes(ctx, pending)
+		if err != nil {
+			return nil, fmt.Errorf("retrieve reannounced torrents: %w", err)
+		}
+
+		var still []string
+		for _, hash := range pending {
+			t, ok := refreshed[hash]
+			if !ok {
+				log.Warnf("Torrent no longer found in client: %s", hash)
+				continue
+			}
+			t.HardlinkedOutsideClient = torrents[hash].HardlinkedOutsideClient
+
+			match, err := c.CheckTorrentReannounce(ctx, &t)
+			if err != nil {
+				log.WithError(err).Errorf("Failed checking reannounce filters for torrent: %q", t.Name)
+				runOutcome.record(0, 1)
+				continue
+			} else if match {
+				log.Debugf("Torrent still matches reannounce filters: %q (tracker status: %q)", t.Name, t.TrackerStatus)
+				still = append(still, hash)
+				continue
+			}
+
+			log.Infof("Torrent no longer matches reannounce filters: %q (tracker status: %q)", t.Name, t.TrackerStatus)
+		}
+
+		pending = still
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// reannounceClient records the reannounces, a torrent stops matching the reannounce filters once it was reannounced
+// as often as its entry in working
+type reannounceClient struct {
+	client.Interface
+
+	working map[string]int
+	err     error
+
+	calls     [][]string
+	announced map[string]int
+	at        []time.Time
+}
+
+func (c *reannounceClient) ReannounceTorrents(_ context.Context, hashes []string) error {
+	c.calls = append(c.calls, hashes)
+	c.at = append(c.at, time.Now())
+	if c.err != nil {
+		return c.err
+	}
+
+	for _, h := range hashes {
+		c.announced[h]++
+	}
+	return nil
+}
+
+func (c *reannounceClient) GetTorrentsByHashes(_ context.Context, hashes []string) (map[string]config.Torrent, error) {
+	torrents := make(map[string]config.Torrent)
+	for _, h := range hashes {
+		if _, ok := c.working[h]; ok {
+			torrents[h] = config.Torrent{Hash: h, Name: h}
+		}
+	}
+	return torrents, nil
+}
+
+func (c *reannounceClient) CheckTorrentReannounce(_ context.Context, t *config.Torrent) (bool, error) {
+	return c.announced[t.Hash] < c.working[t.Hash], nil
+}
+
+func TestReannounceWithRetries(t *testing.T) {
+	log := logrus.NewEntry(logrus.New())
+
+	tests := []struct {
+		name          string
+		working       map[string]int
+		hashes        []string
+		attempts      int
+		err           error
+		expectedCalls [][]string
+		expected      []string
+		expectedErr   bool
+	}{
+		{
+			name:          "working_after_first_attempt",
+			working:       map[string]int{"a": 1, "b": 1},
+			hashes:        []string{"a", "b"},
+			attempts:      3,
+			expectedCalls: [][]string{{"a", "b"}},
+		},
+		{
+			name:          "retries_only_still_matching",
+			working:       map[string]int{"a": 1, "b": 3},
+			hashes:        []string{"a", "b"},
+			attempts:      3,
+			expectedCalls: [][]string{{"a", "b"}, {"b"}, {"b"}},
+		},
+		{
+			name:          "gives_up_after_attempts",
+			working:       map[string]int{"a": 1, "b": 5},
+			hashes:        []string{"a", "b"},
+			attempts:      2,
+			expectedCalls: [][]string{{"a", "b"}, {"b"}},
+			expected:      []string{"b"},
+		},
+		{
+			name:          "removed_from_client",
+			working:       map[string]int{"a": 5},
+			hashes:        []string{"a", "gone"},
+			attempts:      2,
+			expectedCalls: [][]string{{"a", "gone"}, {"a"}},
+			expected:      []string{"a"},
+		},
+		{
+			name:          "reannounce_fails",
+			working:       map[string]int{"a": 1},
+			hashes:        []string{"a"},
+			attempts:      3,
+			err:           errors.New("client down"),
+			expectedCalls: [][]string{{"a"}},
+			expectedErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &reannounceClient{working: tt.working, err: tt.err, announced: make(map[string]int)}
+
+			pending, err := reannounceWithRetries(context.Background(), log, c, nil, tt.hashes, tt.attempts,
+				time.Millisecond)
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, pending)
+			}
+			assert.Equal(t, tt.expectedCalls, c.calls)
+		})
+	}
+}
+
+func TestReannounceWithRetries_Interval(t *testing.T) {
+	log := logrus.NewEntry(logrus.New())
+	const interval = 20 * time.Millisecond
+
+	// every reannounce is followed by the interval before the torrents are checked again
+	c := &reannounceClient{working: map[string]int{"a": 5}, announced: make(map[string]int)}
+	start := time.Now()
+	pending, err := reannounceWithRetries(context.Background(), log, c, nil, []string{"a"}, 3, interval)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, pending)
+	assert.GreaterOrEqual(t, time.Since(start), 3*interval)
+
+	require.Len(t, c.at, 3)
+	for i := 1; i < len(c.at); i++ {
+		assert.GreaterOrEqual(t, c.at[i].Sub(c.at[i-1]), interval)
+	}
+
+	// a cancelled context stops waiting
+	ctx, cancel := context.WithTimeout(context.Background(), interval)
+	defer cancel()
+	c = &reannounceClient{working: map[string]int{"a": 5}, announced: make(map[string]int)}
+	_, err = reannounceWithRetries(ctx, log, c, nil, []string{"a"}, 3, time.Hour)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, c.calls, 1)
+}
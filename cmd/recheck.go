@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+var flagRecheckExpressions []string
+
+var recheckCmd = &cobra.Command{
+	Use:   "recheck [CLIENT]",
+	Short: "Check torrent client for torrents to force a recheck of",
+	Long: `This command can be used to force a recheck of torrents matching the recheck expressions of the client's filter
+(or the ones given with --expression), e.g. after moving data or restoring it from a backup.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		start := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("recheck")
+		runOutcome.track()
+
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// retrieve client object
+		clientName := args[0]
+		clientConfig, ok := config.Config.Clients[clientName]
+		if !ok {
+			log.Fatalf("No client configuration found for: %q", clientName)
+		}
+
+		// validate client is enabled
+		if err := tqm.ValidateClientEnabled(clientConfig); err != nil {
+			log.WithError(err).Fatal("Failed validating client is enabled")
+		}
+
+		// retrieve client type
+		clientType, err := tqm.ClientString("type", clientConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed determining client type")
+		}
+
+		// retrieve client filters
+		clientFilter, err := tqm.ClientFilter(clientConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving client filter")
+		}
+
+		if flagFilterName != "" {
+			clientFilter, err = getFilter(flagFilterName)
+			if err != nil {
+				log.WithError(err).Fatal("Failed retrieving specified filter")
+			}
+		}
+
+		// expressions given on the command line replace the filter's recheck expressions
+		if len(flagRecheckExpressions) > 0 {
+			filter := *clientFilter
+			filter.Recheck = flagRecheckExpressions
+			clientFilter = &filter
+		}
+
+		if len(clientFilter.Recheck) == 0 {
+			log.Fatal("No recheck expressions configured, add them to the filter's recheck section or use --expression")
+		}
+
+		// compile client filters
+		exp, err := expression.Compile(clientFilter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed compiling client filters")
+		}
+
+		// load client object
+		c, err := client.NewClient(*clientType, clientName, exp)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
+		}
+
+		if !supports(log, c, c.Capabilities().Recheck, "Rechecking") {
+			return
+		}
+		rc := c.(client.RecheckInterface)
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		// connect to client
+		if err := c.Connect(ctx); err != nil {
+			log.WithError(err).Fatal("Failed connecting")
+		} else {
+			log.Debugf("Connected to client")
+		}
+
+		// get free disk space (can/will be used by filters)
+		if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+			log.WithError(err).Error("Failed retrieving free-space")
+		}
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "recheck", true) {
+			// download path mapping
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "recheck", tqm.PathMappingHardlinks)
+			if err != nil {
+				log.WithError(err).Fatal("Failed loading client download path mappings")
+			} else if clientDownloadPathMapping != nil {
+				log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
+					clientDownloadPathMapping)
+			}
+
+			// create map of paths associated to underlying file ids
+			start := time.Now()
+			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
+
+			// add HardlinkedOutsideClient field to torrents
+			for h, t := range torrents {
+				t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+				torrents[h] = t
+			}
+		} else {
+			log.Warnf("Not mapping hardlinks for client %q", clientName)
+			log.Warnf("If you are using the 'HardlinkedOutsideClient' field in your filters, you should add 'recheck' to the 'MapHardlinksFor' field in your filter configuration")
+		}
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		recheckList, fields := selectRecheckTorrents(ctx, log, rc, torrents, noti)
+
+		runOutcome.record(len(recheckList), 0)
+
+		// recheck torrents if not dry run
+		if !flagDryRun {
+			if len(recheckList) > 0 {
+				log.Infof("Rechecking %d torrent(s)...", len(recheckList))
+				if err := rc.RecheckTorrents(ctx, recheckList); err != nil {
+					log.WithError(err).Fatalf("Failed rechecking torrents: %v", err)
+				}
+				log.Infof("Successfully triggered recheck of %d torrent(s)", len(recheckList))
+			} else {
+				log.Info("No torrents to recheck")
+			}
+		} else {
+			if len(recheckList) > 0 {
+				log.Infof("[DRY-RUN] Would recheck %d torrent(s)", len(recheckList))
+			} else {
+				log.Info("[DRY-RUN] No torrents would be rechecked")
+			}
+		}
+
+		if !noti.CanSend() {
+			log.Debug("Notifications disabled, skipping...")
+			return
+		}
+
+		sendErr := noti.Send(
+			"Torrent Recheck",
+			fmt.Sprintf("Rechecked **%d** torrent(s)", len(recheckList)),
+			clientName,
+			time.Since(start),
+			fields,
+			flagDryRun,
+		)
+		if sendErr != nil {
+			log.WithError(sendErr).Error("Failed sending notification")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recheckCmd)
+
+	recheckCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	recheckCmd.Flags().StringArrayVar(&flagRecheckExpressions, "expression", nil, "Recheck expression to use instead of the filter's recheck expressions (can be repeated)")
+	recheckCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	recheckCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// selectRecheckTorrents returns the hashes of the torrents the recheck filters apply to, with their notification fields
+func selectRecheckTorrents(ctx context.Context, log *logrus.Entry, c client.RecheckInterface,
+	torrents map[string]config.Torrent, noti notification.Sender) ([]string, []notification.Field) {
+	var (
+		hashes []string
+		fields []notification.Field
+	)
+
+	// iterate through torrents
+	for _, t := range torrents {
+		// torrents being checked already are skipped
+		if t.IsChecking() {
+			log.Tracef("Torrent already checking: %q", t.Name)
+			continue
+		}
+
+		// check if torrent should be ignored
+		if ignored, reason, err := c.ShouldIgnore(ctx, &t); err != nil {
+			log.WithError(err).Errorf("Failed checking ignore filters for torrent: %q", t.Name)
+			runOutcome.record(0, 1)
+			continue
+		} else if ignored {
+			if reason != "" {
+				log.Debugf("Ignoring torrent: %q (reason: %s)", t.Name, reason)
+			} else {
+				log.Debugf("Ignoring torrent: %q", t.Name)
+			}
+			continue
+		}
+
+		// check if torrent should be rechecked
+		if recheck, err := c.CheckTorrentRecheck(ctx, &t); err != nil {
+			log.WithError(err).Errorf("Failed checking recheck filters for torrent: %q", t.Name)
+			runOutcome.record(0, 1)
+			continue
+		} else if recheck {
+			if !t.APIDividerPrinted {
+				log.Info("-----")
+			}
+			log.Infof("Adding torrent to recheck list: %q", t.Name)
+			log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tags: %s / Tracker: %s / "+
+				"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, strings.Join(t.TagsSlice(), ", "), t.TrackerName, t.TrackerStatus)
+			hashes = append(hashes, t.Hash)
+			fields = append(fields, noti.BuildField(notification.ActionRecheck, notification.BuildOptions{
+				Torrent: t,
+			}))
+		}
+	}
+
+	return hashes, fields
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// recheckClient matches the recheck filters by hash
+type recheckClient struct {
+	filterClient
+
+	matched map[string]bool
+	failed  map[string]bool
+}
+
+func (c *recheckClient) CheckTorrentRecheck(_ context.Context, t *config.Torrent) (bool, error) {
+	if c.failed[t.Hash] {
+		return false, errors.New("evaluation failed")
+	}
+	return c.matched[t.Hash], nil
+}
+
+func (c *recheckClient) RecheckTorrents(context.Context, []string) error {
+	return nil
+}
+
+func TestSelectRecheckTorrents(t *testing.T) {
+	t.Cleanup(func() { runOutcome = outcome{} })
+
+	torrents := map[string]config.Torrent{
+		"matched":          {Hash: "matched", Name: "Matched", State: "stalledUP"},
+		"matched-paused":   {Hash: "matched-paused", Name: "Matched.Paused", State: "pausedUP"},
+		"unmatched":        {Hash: "unmatched", Name: "Unmatched", State: "uploading"},
+		"ignored":          {Hash: "ignored", Name: "Ignored", State: "uploading"},
+		"checking":         {Hash: "checking", Name: "Checking", State: "checkingUP"},
+		"queued-checking":  {Hash: "queued-checking", Name: "Queued.Checking", State: "queuedForChecking"},
+		"checking-resume":  {Hash: "checking-resume", Name: "Checking.Resume", State: "checkingResumeData"},
+		"failed-filtering": {Hash: "failed-filtering", Name: "Failed.Filtering", State: "uploading"},
+	}
+
+	c := &recheckClient{
+		filterClient: filterClient{ignored: map[string]bool{"ignored": true}},
+		matched: map[string]bool{"matched": true, "matched-paused": true, "ignored": true, "checking": true,
+			"queued-checking": true, "checking-resume": true},
+		failed: map[string]bool{"failed-filtering": true},
+	}
+
+	hashes, fields := selectRecheckTorrents(context.Background(), logrus.NewEntry(logrus.New()), c, torrents,
+		&silentSender{})
+	slices.Sort(hashes)
+
+	// torrents being checked already and ignored torrents are not rechecked
+	assert.Equal(t, []string{"matched", "matched-paused"}, hashes)
+	assert.Len(t, fields, 2)
+
+	// the failed evaluation is recorded
+	assert.EqualValues(t, 1, runOutcome.failures.Load())
+}
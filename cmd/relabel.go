@@ -3,17 +3,17 @@ package cmd
 import (
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/autobrr/tqm/pkg/client"
 	"github.com/autobrr/tqm/pkg/config"
 	"github.com/autobrr/tqm/pkg/evaluate"
 	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
 	"github.com/autobrr/tqm/pkg/logger"
-	"github.com/autobrr/tqm/pkg/notification"
 	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
 
@@ -35,8 +35,15 @@ var relabelCmd = &cobra.Command{
 
 		// set log
 		log := logger.GetLogger("relabel")
+		runOutcome.track()
 
-		noti := notification.NewDiscordSender(log, config.Config.Notifications)
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
 
 		// retrieve client object
 		clientName := args[0]
@@ -46,21 +53,21 @@ var relabelCmd = &cobra.Command{
 		}
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
+		if err := tqm.ValidateClientEnabled(clientConfig); err != nil {
 			log.WithError(err).Fatal("Failed validating client is enabled")
 		}
 
 		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
+		clientType, err := tqm.ClientString("type", clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed determining client type")
 		}
 
 		// retrieve client free space path
-		clientFreeSpacePath, _ := getClientConfigString("free_space_path", clientConfig)
+		clientFreeSpacePath, _ := tqm.ClientString("free_space_path", clientConfig)
 
 		// retrieve client filters
-		clientFilter, err := getClientFilter(clientConfig)
+		clientFilter, err := tqm.ClientFilter(clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed retrieving client filter")
 		}
@@ -100,16 +107,16 @@ var relabelCmd = &cobra.Command{
 				log.WithError(err).Errorf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
 			} else {
 				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
-					humanize.IBytes(uint64(space)), c.GetFreeSpace())
+					formatting.Bytes(uint64(space)), c.GetFreeSpace())
 			}
-		} else if *clientType == "qbittorrent" {
-			// For qBittorrent, we can get free space without a path
+		} else if tqm.ReportsFreeSpace(c) {
+			// For qBittorrent (or with a free_space_provider), we can get free space without a path
 			space, err := c.GetCurrentFreeSpace(ctx, "")
 			if err != nil {
 				log.WithError(err).Error("Failed retrieving free-space")
 			} else {
 				log.Infof("Retrieved free-space: %v (%.2f GB)",
-					humanize.IBytes(uint64(space)), c.GetFreeSpace())
+					formatting.Bytes(uint64(space)), c.GetFreeSpace())
 			}
 		}
 
@@ -132,7 +139,7 @@ var relabelCmd = &cobra.Command{
 
 		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "relabel", true) {
 			// download path mapping
-			clientDownloadPathMapping, err := getClientDownloadPathMapping(clientConfig)
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "relabel", tqm.PathMappingHardlinks)
 			if err != nil {
 				log.WithError(err).Fatal("Failed loading client download path mappings")
 			} else if clientDownloadPathMapping != nil {
@@ -143,7 +150,7 @@ var relabelCmd = &cobra.Command{
 			// create map of paths associated to underlying file ids
 			start := time.Now()
 			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
-			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), time.Since(start))
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
 
 			// add HardlinkedOutsideClient field to torrents
 			for h, t := range torrents {
@@ -155,8 +162,16 @@ var relabelCmd = &cobra.Command{
 			log.Warnf("If your setup involves multiple torrents sharing the same underlying file using hardlinks, or you are using the 'HardlinkedOutsideClient' field in your filters, you should add 'relabel' to the 'MapHardlinksFor' field in your filter configuration")
 		}
 
+		// scope to the targeted torrents (the full list is still required to map cross-seeds and hardlinks)
+		torrents = scopeTorrents(log, torrents, hashes)
+
 		// relabel torrents that meet the filter criteria
-		if err := relabelEligibleTorrents(ctx, log, c, torrents, tfm, noti, clientName, startTime); err != nil {
+		relabelCrossSeeds, err := relabelCrossSeedsEnabled(log, c, clientConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed determining whether to relabel cross-seeds")
+		}
+
+		if err := relabelEligibleTorrents(ctx, log, c, torrents, tfm, relabelCrossSeeds, noti, clientName, startTime); err != nil {
 			log.WithError(err).Fatal("Failed relabeling eligible torrents...")
 		}
 	},
@@ -166,4 +181,7 @@ func init() {
 	rootCmd.AddCommand(relabelCmd)
 
 	relabelCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	relabelCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	relabelCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+	relabelCmd.Flags().StringVar(&flagReport, "report", "", "Write every decision (kept, ignored, removed and the matched expression) to this JSON file, also in dry-run")
 }
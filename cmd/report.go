@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// decisions listed in the report
+const (
+	reportIgnored   = "ignored"
+	reportKept      = "kept"
+	reportRemoved   = "removed"
+	reportRetagged  = "retagged"
+	reportRelabeled = "relabeled"
+	reportPruned    = "pruned"
+	reportFailed    = "failed"
+)
+
+var flagReport string
+
+// reportDocument is written to --report, listing every decision of the command (taken or proposed in dry-run)
+type reportDocument struct {
+	Command   string           `json:"command"`
+	Client    string           `json:"client,omitempty"`
+	DryRun    bool             `json:"dry_run"`
+	Started   time.Time        `json:"started"`
+	Duration  float64          `json:"duration_seconds"`
+	Decisions []reportDecision `json:"decisions"`
+}
+
+type reportDecision struct {
+	Decision string `json:"decision"`
+	Hash     string `json:"hash,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Tracker  string `json:"tracker,omitempty"`
+	Path     string `json:"path,omitempty"`
+	// Size of the orphan in bytes
+	Size int64 `json:"size,omitempty"`
+	// Expression is the ignore or remove expression which matched
+	Expression string `json:"expression,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// reportRecorder collects the decisions of the command with --report, recording is a no-op otherwise
+type reportRecorder struct {
+	mu        sync.Mutex
+	used      bool
+	started   time.Time
+	decisions []reportDecision
+}
+
+var report reportRecorder
+
+func (r *reportRecorder) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.used {
+		r.used = true
+		r.started = time.Now()
+	}
+}
+
+func (r *reportRecorder) add(d reportDecision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.used {
+		r.decisions = append(r.decisions, d)
+	}
+}
+
+// torrent records the decision for t, expression is the matched ignore or remove expression
+func (r *reportRecorder) torrent(decision string, t config.Torrent, expression string, detail string) {
+	r.add(reportDecision{Decision: decision, Hash: t.Hash, Name: t.Name, Tracker: t.TrackerName, Expression: expression,
+		Detail: detail})
+}
+
+// path records the decision for the orphan at path
+func (r *reportRecorder) path(decision string, path string, size int64, detail string) {
+	r.add(reportDecision{Decision: decision, Path: path, Size: size, Detail: detail})
+}
+
+// removalDetail describes what happened to the data of a removed torrent
+func removalDetail(deleteData bool, keepFiles bool) string {
+	switch {
+	case keepFiles:
+		return "deleted data, kept files matching keep_files"
+	case deleteData:
+		return "deleted data"
+	default:
+		return "kept data on disk"
+	}
+}
+
+// writeReport writes the decisions recorded by cmd to --report
+func writeReport(cmd *cobra.Command) error {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	if flagReport == "" || !report.used {
+		return nil
+	}
+
+	doc := reportDocument{
+		Command:   strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "),
+		DryRun:    flagDryRun,
+		Started:   report.started,
+		Duration:  time.Since(report.started).Seconds(),
+		Decisions: report.decisions,
+	}
+	if args := cmd.Flags().Args(); len(args) > 0 {
+		doc.Client = args[0]
+	}
+	if doc.Decisions == nil {
+		doc.Decisions = []reportDecision{}
+	}
+
+	// sorted so the reports of two runs can be diffed
+	slices.SortStableFunc(doc.Decisions, func(a, b reportDecision) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Hash, b.Hash), cmp.Compare(a.Path, b.Path))
+	})
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	return paths.WriteFileAtomic(flagReport, buf.Bytes())
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestWriteReport(t *testing.T) {
+	prevReport, prevDryRun := flagReport, flagDryRun
+	t.Cleanup(func() {
+		flagReport, flagDryRun = prevReport, prevDryRun
+		report = reportRecorder{}
+	})
+	flagReport, flagDryRun = filepath.Join(t.TempDir(), "run.json"), true
+
+	cmd := &cobra.Command{Use: "clean"}
+	rootCmd.AddCommand(cmd)
+	t.Cleanup(func() { rootCmd.RemoveCommand(cmd) })
+	require.NoError(t, cmd.Flags().Parse([]string{"qbt"}))
+
+	// nothing is recorded before the report is started
+	report.torrent(reportKept, config.Torrent{Hash: "xyz", Name: "Skipped"}, "", "")
+
+	report.start()
+	report.torrent(reportRemoved, config.Torrent{Hash: "b", Name: "B.Torrent", TrackerName: "tracker.example"},
+		"Ratio > 2", removalDetail(true, false))
+	report.torrent(reportIgnored, config.Torrent{Hash: "a", Name: "A.Torrent"}, `"keep" in Tags`, "")
+	report.path(reportKept, "/data/orphan.mkv", 2048, "within the grace period")
+
+	require.NoError(t, writeReport(cmd))
+
+	data, err := os.ReadFile(flagReport)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Ratio > 2"`)
+
+	var doc reportDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "clean", doc.Command)
+	assert.Equal(t, "qbt", doc.Client)
+	assert.True(t, doc.DryRun)
+	assert.Equal(t, []reportDecision{
+		{Decision: "kept", Path: "/data/orphan.mkv", Size: 2048, Detail: "within the grace period"},
+		{Decision: "ignored", Hash: "a", Name: "A.Torrent", Expression: `"keep" in Tags`},
+		{Decision: "removed", Hash: "b", Name: "B.Torrent", Tracker: "tracker.example", Expression: "Ratio > 2",
+			Detail: "deleted data"},
+	}, doc.Decisions)
+}
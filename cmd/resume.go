@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/notification"
+)
+
+var resumeTorrents = pauseAction{
+	name:         "resume",
+	gerund:       "Resuming",
+	past:         "resumed",
+	title:        "Resume",
+	titlePast:    "Resumed",
+	paused:       true,
+	notification: notification.ActionResume,
+	check:        client.Interface.CheckTorrentResume,
+	apply:        client.Interface.ResumeTorrents,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [CLIENT]",
+	Short: "Check torrent client for paused torrents to resume",
+	Long:  `This command can be used to check a torrent client's queue for paused torrents to resume based on its configured filters.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runPauseAction(cmd, args, resumeTorrents)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+
+	resumeCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	resumeCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	resumeCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
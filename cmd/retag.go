@@ -3,16 +3,16 @@ package cmd
 import (
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/autobrr/tqm/pkg/client"
 	"github.com/autobrr/tqm/pkg/config"
 	"github.com/autobrr/tqm/pkg/evaluate"
 	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
 	"github.com/autobrr/tqm/pkg/logger"
-	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/tqm"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
 
@@ -34,8 +34,15 @@ var retagCmd = &cobra.Command{
 
 		// set log
 		log := logger.GetLogger("retag")
+		runOutcome.track()
 
-		noti := notification.NewDiscordSender(log, config.Config.Notifications)
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
 
 		// retrieve client object
 		clientName := args[0]
@@ -45,25 +52,21 @@ var retagCmd = &cobra.Command{
 		}
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
+		if err := tqm.ValidateClientEnabled(clientConfig); err != nil {
 			log.WithError(err).Fatal("Failed validating client is enabled")
 		}
 
 		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
+		clientType, err := tqm.ClientString("type", clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed determining client type")
 		}
 
-		if *clientType != "qbittorrent" {
-			log.Fatalf("Retagging is currently only supported for qbittorrent")
-		}
-
 		// retrieve client free space path
-		clientFreeSpacePath, _ := getClientConfigString("free_space_path", clientConfig)
+		clientFreeSpacePath, _ := tqm.ClientString("free_space_path", clientConfig)
 
 		// retrieve client filters
-		clientFilter, err := getClientFilter(clientConfig)
+		clientFilter, err := tqm.ClientFilter(clientConfig)
 		if err != nil {
 			log.WithError(err).Fatal("Failed retrieving client filter")
 		}
@@ -83,16 +86,15 @@ var retagCmd = &cobra.Command{
 
 		// load client object
 		c, err := client.NewClient(*clientType, clientName, exp)
-
-		ct, ok := c.(client.TagInterface)
-		if !ok {
-			log.Fatalf("Retagging is currently only supported for qbittorrent")
-		}
-
 		if err != nil {
 			log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
 		}
 
+		if !supports(log, c, c.Capabilities().Tags, "Retagging") {
+			return
+		}
+		ct := c.(client.TagInterface)
+
 		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, ct.Type(), tracker.Loaded())
 
 		// connect to client
@@ -109,30 +111,37 @@ var retagCmd = &cobra.Command{
 				log.WithError(err).Errorf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
 			} else {
 				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
-					humanize.IBytes(uint64(space)), ct.GetFreeSpace())
+					formatting.Bytes(uint64(space)), ct.GetFreeSpace())
 			}
-		} else if *clientType == "qbittorrent" {
-			// For qBittorrent, we can get free space without a path
+		} else if tqm.ReportsFreeSpace(ct) {
+			// For qBittorrent (or with a free_space_provider), we can get free space without a path
 			space, err := ct.GetCurrentFreeSpace(ctx, "")
 			if err != nil {
 				log.WithError(err).Error("Failed retrieving free-space")
 			} else {
 				log.Infof("Retrieved free-space: %v (%.2f GB)",
-					humanize.IBytes(uint64(space)), ct.GetFreeSpace())
+					formatting.Bytes(uint64(space)), ct.GetFreeSpace())
 			}
 		}
 
-		// retrieve torrents
-		torrents, err := ct.GetTorrents(ctx)
+		mapHardlinks := evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "retag", true)
+
+		// retrieve torrents (hardlink mapping requires the full torrent list)
+		var torrents map[string]config.Torrent
+		if len(hashes) > 0 && !mapHardlinks {
+			torrents, err = ct.GetTorrentsByHashes(ctx, hashes)
+		} else {
+			torrents, err = ct.GetTorrents(ctx)
+		}
 		if err != nil {
 			log.WithError(err).Fatal("Failed retrieving torrents")
 		} else {
 			log.Infof("Retrieved %d torrents", len(torrents))
 		}
 
-		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "retag", true) {
+		if mapHardlinks {
 			// download path mapping
-			clientDownloadPathMapping, err := getClientDownloadPathMapping(clientConfig)
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, "retag", tqm.PathMappingHardlinks)
 			if err != nil {
 				log.WithError(err).Fatal("Failed loading client download path mappings")
 			} else if clientDownloadPathMapping != nil {
@@ -143,7 +152,7 @@ var retagCmd = &cobra.Command{
 			// create map of paths associated to underlying file ids
 			start := time.Now()
 			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
-			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), time.Since(start))
+			log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
 
 			// add HardlinkedOutsideClient field to torrents
 			for h, t := range torrents {
@@ -155,6 +164,16 @@ var retagCmd = &cobra.Command{
 			log.Warnf("If your setup involves multiple torrents sharing the same underlying file using hardlinks, or you are using the 'HardlinkedOutsideClient' field in your filters, you should add 'retag' to the 'MapHardlinksFor' field in your filter configuration")
 		}
 
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		if flagSample > 0 {
+			if err := runRetagSample(ctx, log, ct, exp, torrents); err != nil {
+				log.WithError(err).Fatal("Failed evaluating torrent sample")
+			}
+			return
+		}
+
 		// Verify tags exist on client if configured to create upfront
 		if qbtClient, ok := ct.(*client.QBittorrent); ok && qbtClient.CreateTagsUpfront {
 			var tagList []string
@@ -172,6 +191,14 @@ var retagCmd = &cobra.Command{
 		if err := retagEligibleTorrents(ctx, log, ct, torrents, noti, clientName, startTime); err != nil {
 			log.WithError(err).Fatal("Failed retagging eligible torrents...")
 		}
+
+		// set share limits of torrents that meet the seed limit criteria
+		if len(exp.SeedLimits) > 0 && supports(log, ct, ct.Capabilities().ShareLimits, "Seed limits") {
+			cs := ct.(client.ShareLimitInterface)
+			if err := setShareLimitsForEligibleTorrents(ctx, log, cs, torrents, noti, clientName, startTime); err != nil {
+				log.WithError(err).Fatal("Failed setting share limits for eligible torrents...")
+			}
+		}
 	},
 }
 
@@ -179,4 +206,8 @@ func init() {
 	rootCmd.AddCommand(retagCmd)
 
 	retagCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	retagCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	retagCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+	retagCmd.Flags().IntVar(&flagSample, "sample", 0, "Only evaluate this many random torrents and print the predicted actions, without taking any action")
+	retagCmd.Flags().StringVar(&flagReport, "report", "", "Write every decision (kept, ignored, removed and the matched expression) to this JSON file, also in dry-run")
 }
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+// flagInteractive lets clean present the removal candidates for review before removing any of them
+var flagInteractive bool
+
+// reviewCandidate is a torrent clean would remove, shown in the review
+type reviewCandidate struct {
+	Hash     string
+	Name     string
+	Tracker  string
+	Reason   string
+	Action   string
+	Size     int64
+	Ratio    float32
+	AgeDays  float32
+	Approved bool
+}
+
+type reviewSort int
+
+const (
+	reviewSortSize reviewSort = iota
+	reviewSortRatio
+	reviewSortAge
+)
+
+func (s reviewSort) String() string {
+	switch s {
+	case reviewSortRatio:
+		return "ratio"
+	case reviewSortAge:
+		return "age"
+	default:
+		return "size"
+	}
+}
+
+// reviewCleanCandidates returns the torrents clean would remove, using the same decisions as --sample
+func reviewCleanCandidates(ctx context.Context, c client.Interface, torrents map[string]config.Torrent,
+	tfm *torrentfilemap.TorrentFileMap, hfm hardlinkfilemap.HardlinkFileMapI, filter *config.FilterConfiguration) ([]reviewCandidate, error) {
+	deleteData := true
+	if filter != nil && filter.DeleteData != nil {
+		deleteData = *filter.DeleteData
+	}
+
+	var candidates []reviewCandidate
+	for _, t := range torrents {
+		p, err := predictClean(ctx, c, &t, tfm, hfm, deleteData)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate torrent: %q: %w", t.Name, err)
+		}
+		if len(p.Actions) == 0 || !strings.HasPrefix(p.Actions[0], "remove") {
+			continue
+		}
+
+		size := t.TotalBytes
+		if !t.Downloaded && t.DownloadedBytes > 0 {
+			size = t.DownloadedBytes
+		}
+
+		candidates = append(candidates, reviewCandidate{
+			Hash:    t.Hash,
+			Name:    t.Name,
+			Tracker: t.TrackerName,
+			Reason:  strings.Join(p.Rules, "; "),
+			Action:  p.Actions[0],
+			Size:    size,
+			Ratio:   t.Ratio,
+			AgeDays: t.AddedDays,
+		})
+	}
+
+	return candidates, nil
+}
+
+// reviewModel is the state of the review, kept apart from the terminal so it can be tested
+type reviewModel struct {
+	items   []reviewCandidate
+	cursor  int
+	offset  int
+	sortBy  reviewSort
+	reverse bool
+}
+
+func newReviewModel(candidates []reviewCandidate) *reviewModel {
+	m := &reviewModel{items: candidates}
+	m.sort()
+	m.cursor = 0
+	return m
+}
+
+func (m *reviewModel) sort() {
+	var selected string
+	if m.cursor < len(m.items) {
+		selected = m.items[m.cursor].Hash
+	}
+
+	key := func(c reviewCandidate) float64 {
+		switch m.sortBy {
+		case reviewSortRatio:
+			return float64(c.Ratio)
+		case reviewSortAge:
+			return float64(c.AgeDays)
+		default:
+			return float64(c.Size)
+		}
+	}
+
+	// descending unless reversed
+	sort.SliceStable(m.items, func(i, j int) bool {
+		if m.reverse {
+			return key(m.items[i]) < key(m.items[j])
+		}
+		return key(m.items[i]) > key(m.items[j])
+	})
+
+	// keep the selected torrent selected
+	for i, item := range m.items {
+		if item.Hash == selected {
+			m.cursor = i
+		}
+	}
+}
+
+// handleKey applies a key press, it returns whether the review is done and whether it was aborted
+func (m *reviewModel) handleKey(key string) (done bool, aborted bool) {
+	switch key {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case " ":
+		if m.cursor < len(m.items) {
+			m.items[m.cursor].Approved = !m.items[m.cursor].Approved
+		}
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "y", "n":
+		if m.cursor < len(m.items) {
+			m.items[m.cursor].Approved = key == "y"
+		}
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "a", "d":
+		for i := range m.items {
+			m.items[i].Approved = key == "a"
+		}
+	case "s":
+		m.sortBy = (m.sortBy + 1) % 3
+		m.sort()
+	case "r":
+		m.reverse = !m.reverse
+		m.sort()
+	case "enter":
+		return true, false
+	case "q", "esc", "ctrl+c":
+		return true, true
+	}
+
+	return false, false
+}
+
+// approved returns the hashes of the approved candidates
+func (m *reviewModel) approved() []string {
+	var hashes []string
+	for _, item := range m.items {
+		if item.Approved {
+			hashes = append(hashes, item.Hash)
+		}
+	}
+	return hashes
+}
+
+// render draws the review for a terminal of the given size, lines end with \r\n as the terminal is in raw mode
+func (m *reviewModel) render(w io.Writer, width int, height int) {
+	var (
+		approved     int
+		approvedSize int64
+	)
+	for _, item := range m.items {
+		if item.Approved {
+			approved++
+			approvedSize += item.Size
+		}
+	}
+
+	// header (2 lines), details (3 lines) and help (2 lines) leave the rest for the list
+	rows := max(height-7, 1)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+
+	order := "desc"
+	if m.reverse {
+		order = "asc"
+	}
+
+	line := func(s string) {
+		if width > 0 && len([]rune(s)) > width {
+			s = string([]rune(s)[:width])
+		}
+		fmt.Fprint(w, s, "\x1b[K\r\n")
+	}
+
+	fmt.Fprint(w, "\x1b[H")
+	line(fmt.Sprintf("Review removal candidates: %d approved of %d (%s), sorted by %s (%s)", approved, len(m.items),
+		formatting.Bytes(uint64(approvedSize)), m.sortBy, order))
+	line(fmt.Sprintf("    %-10s %-7s %-7s %-20s %s", "SIZE", "RATIO", "AGE", "TRACKER", "NAME"))
+
+	for i := m.offset; i < m.offset+rows; i++ {
+		if i >= len(m.items) {
+			line("")
+			continue
+		}
+
+		item := m.items[i]
+		pointer, mark := " ", "[ ]"
+		if i == m.cursor {
+			pointer = ">"
+		}
+		if item.Approved {
+			mark = "[x]"
+		}
+		row := fmt.Sprintf("%s%s %-10s %-7.2f %-7s %-20.20s %s", pointer, mark, formatting.Bytes(uint64(item.Size)), item.Ratio,
+			fmt.Sprintf("%.1fd", item.AgeDays), item.Tracker, item.Name)
+		if i == m.cursor {
+			row = "\x1b[7m" + row + "\x1b[0m"
+		}
+		line(row)
+	}
+
+	if m.cursor < len(m.items) {
+		item := m.items[m.cursor]
+		line("")
+		line("Reason: " + item.Reason)
+		line("Action: " + item.Action)
+	}
+
+	line("")
+	fmt.Fprint(w, "↑/↓ move  space toggle  y/n approve/deny  a/d all  s sort  r reverse  enter remove approved  q abort\x1b[K\x1b[J")
+}
+
+// runReview shows the candidates on the terminal until the review is confirmed or aborted and returns the approved hashes
+func runReview(candidates []reviewCandidate) ([]string, bool, error) {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return nil, false, fmt.Errorf("--interactive requires a terminal")
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return nil, false, fmt.Errorf("enable raw mode: %w", err)
+	}
+	// alternate screen without cursor, restored on return
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+		_ = term.Restore(in, state)
+	}()
+
+	m := newReviewModel(candidates)
+	buf := make([]byte, 16)
+	for {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			width, height = 120, 30
+		}
+		m.render(os.Stdout, width, height)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, false, fmt.Errorf("read key: %w", err)
+		}
+
+		if done, aborted := m.handleKey(parseKey(buf[:n])); done {
+			if aborted {
+				return nil, true, nil
+			}
+			return m.approved(), false, nil
+		}
+	}
+}
+
+// parseKey maps the bytes of a key press read in raw mode to the names handled by reviewModel
+func parseKey(b []byte) string {
+	switch string(b) {
+	case "\x1b[A", "\x1bOA":
+		return "up"
+	case "\x1b[B", "\x1bOB":
+		return "down"
+	case "\r", "\n":
+		return "enter"
+	case "\x1b":
+		return "esc"
+	case "\x03":
+		return "ctrl+c"
+	}
+	return strings.ToLower(string(b))
+}
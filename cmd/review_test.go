@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewModel(t *testing.T) {
+	m := newReviewModel([]reviewCandidate{
+		{Hash: "small", Size: 1 << 20, Ratio: 3, AgeDays: 10},
+		{Hash: "large", Size: 1 << 30, Ratio: 1, AgeDays: 30},
+		{Hash: "medium", Size: 1 << 25, Ratio: 2, AgeDays: 20},
+	})
+
+	hashes := func() []string {
+		var h []string
+		for _, item := range m.items {
+			h = append(h, item.Hash)
+		}
+		return h
+	}
+
+	// sorted by size, largest first
+	assert.Equal(t, []string{"large", "medium", "small"}, hashes())
+
+	// approve large, deny medium, toggle small
+	for _, key := range []string{"y", "n", " "} {
+		done, _ := m.handleKey(key)
+		assert.False(t, done)
+	}
+	assert.Equal(t, []string{"large", "small"}, m.approved())
+
+	// sort by ratio keeps the selected torrent selected
+	m.handleKey("s")
+	assert.Equal(t, reviewSortRatio, m.sortBy)
+	assert.Equal(t, []string{"small", "medium", "large"}, hashes())
+	assert.Equal(t, "small", m.items[m.cursor].Hash)
+
+	m.handleKey("r")
+	assert.Equal(t, []string{"large", "medium", "small"}, hashes())
+
+	var buf bytes.Buffer
+	m.render(&buf, 120, 20)
+	assert.Contains(t, buf.String(), "2 approved of 3")
+
+	done, aborted := m.handleKey(parseKey([]byte("\r")))
+	assert.True(t, done)
+	assert.False(t, aborted)
+
+	done, aborted = m.handleKey(parseKey([]byte("\x1b")))
+	assert.True(t, done)
+	assert.True(t, aborted)
+}
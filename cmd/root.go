@@ -1,35 +1,56 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/autobrr/tqm/pkg/client"
 	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
 	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
 	"github.com/autobrr/tqm/pkg/runtime"
+	"github.com/autobrr/tqm/pkg/tqm"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
 
+// configHeaderEnv is the environment variable of the --config-header default
+const configHeaderEnv = "TQM_CONFIG_HEADER"
+
 var (
 	// Global flags
 	flagLogLevel     = 0
 	flagConfigFile   = "config.yaml"
 	flagConfigFolder = config.GetDefaultConfigDirectory("tqm", flagConfigFile)
 	flagLogFile      = "activity.log"
+	flagConfigHeader = os.Getenv(configHeaderEnv)
 
 	flagFilterName                       string
 	flagDryRun                           bool
 	flagExperimentalRelabelForCrossSeeds bool
+	flagHash                             string
+	flagHashesFile                       string
+	flagOutput                           = outputText
+	flagRecordFile                       string
+	flagReplayFile                       string
 
 	// Global vars
 	log         *logrus.Entry
 	initialized bool
+	// configFile is the local config file, which is the cached copy when --config is a url
+	configFile string
+	// logCommand and logClient expand the placeholders of the log file, they are set before running a command
+	logCommand string
+	logClient  string
 )
 
 var rootCmd = &cobra.Command{
@@ -37,59 +58,224 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI torrent queue manager",
 	Long: `A CLI application that can be used to manage your torrent clients.
 `,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		logCommand = strings.ReplaceAll(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "), " ", "-")
+		if len(args) > 0 {
+			logClient = args[0]
+		}
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	defer recoverPanic(time.Now())
+
+	// the tracker API results cached so far are also kept when a command fails
+	logrus.RegisterExitHandler(tracker.FlushCache)
+
+	registerCompletions(rootCmd)
+
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitFatal)
+	}
+
+	if err := writeOutputDocument(os.Stdout, cmd); err != nil {
+		log.WithError(err).Error("Failed writing output")
+	}
+
+	if err := writeReport(cmd); err != nil {
+		log.WithError(err).Errorf("Failed writing report: %q", flagReport)
+	}
+
+	completeFirstRun()
+
+	tracker.FlushCache()
+
+	logExpressionStats()
+
+	// errors of tracker APIs are logged, the affected torrents are treated as registered
+	if n := tracker.APIErrors(); n > 0 {
+		log.Warnf("%d tracker API request(s) failed", n)
+		runOutcome.record(0, int(n))
+	}
+	if n := tracker.APISkipped(); n > 0 {
+		log.Warnf("%d tracker API request(s) skipped after repeated failures", n)
+		runOutcome.record(0, int(n))
+	}
+
+	if code := runOutcome.exitCode(); code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// slowExpressionThreshold is the average evaluation time above which an expression is reported as slow
+const slowExpressionThreshold = time.Millisecond
+
+// logExpressionStats logs how often every expression was evaluated and matched during the run
+func logExpressionStats() {
+	if log == nil || !log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+
+	stats := expression.Stats()
+	if len(stats) == 0 {
+		return
+	}
+
+	log.Debugf("Expression summary (%d evaluated):", len(stats))
+	for _, s := range stats {
+		avg := s.Duration / time.Duration(s.Evaluations)
+
+		var notes []string
+		if s.Matches == 0 {
+			notes = append(notes, "never matched")
+		}
+		if avg > slowExpressionThreshold {
+			notes = append(notes, "slow")
+		}
+
+		line := fmt.Sprintf("[%s] %q matched %d/%d (total: %s, avg: %s)", s.Section, s.Text, s.Matches, s.Evaluations,
+			s.Duration.Round(time.Microsecond), avg.Round(time.Microsecond))
+		if len(notes) > 0 {
+			line += " - " + strings.Join(notes, ", ")
+		}
+		log.Debug(line)
 	}
 }
 
 func init() {
 	// Parse persistent flags
 	rootCmd.PersistentFlags().StringVar(&flagConfigFolder, "config-dir", flagConfigFolder, "Config folder")
-	rootCmd.PersistentFlags().StringVarP(&flagConfigFile, "config", "c", flagConfigFile, "Config file")
-	rootCmd.PersistentFlags().StringVarP(&flagLogFile, "log", "l", flagLogFile, "Log file")
+	rootCmd.PersistentFlags().StringVarP(&flagConfigFile, "config", "c", flagConfigFile, "Config file or http(s) url")
+	rootCmd.PersistentFlags().StringVar(&flagConfigHeader, "config-header", flagConfigHeader, "Header sent when fetching the config from a url, e.g. \"Authorization: Bearer token\" (env: TQM_CONFIG_HEADER)")
+	rootCmd.PersistentFlags().StringVarP(&flagLogFile, "log", "l", flagLogFile, "Log file, {command} and {client} are replaced by the command and client, e.g. activity-{command}-{client}.log")
 	rootCmd.PersistentFlags().CountVarP(&flagLogLevel, "verbose", "v", "Verbose level")
 
 	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Dry run mode")
+	rootCmd.PersistentFlags().BoolVar(&flagIKnowWhatImDoing, "i-know-what-im-doing", false, "Do not force dry-run on the first run of clean, orphan, dedupe, prune-files and run against a client")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", flagOutput, "Output format: text, json to print the results as JSON on stdout, or jsonl to print every action as a JSON line as it is taken (export: json or csv)")
+	rootCmd.PersistentFlags().StringVar(&flagRecordFile, "record", "", "Record the torrents retrieved from the client to this file, without credentials or passkeys, to be replayed with --replay")
+	rootCmd.PersistentFlags().StringVar(&flagReplayFile, "replay", "", "Serve the torrents recorded with --record instead of connecting to the client")
 	rootCmd.PersistentFlags().BoolVar(&flagExperimentalRelabelForCrossSeeds, "experimental-relabel", false, "Enable experimental relabeling for cross-seeded torrents, using hardlinks (only qbit for now")
+	_ = rootCmd.PersistentFlags().MarkDeprecated("experimental-relabel", "set relabel_cross_seeds: true in the client configuration instead")
 
 	// Register commands (pauseCmd added here)
 	// rootCmd.AddCommand(pauseCmd) // This should be done in the init() of the command file itself (e.g., cmd/pause.go)
 }
 
 func initCore(showAppInfo bool) {
-	// Set core variables
-	if !rootCmd.PersistentFlags().Changed("config") {
-		flagConfigFile = filepath.Join(flagConfigFolder, flagConfigFile)
+	initLogging()
+
+	if err := validateOutput(); err != nil {
+		log.WithError(err).Fatal("Invalid output format")
+	}
+
+	// the decisions of the command are collected for --report
+	if flagReport != "" {
+		report.start()
+	}
+
+	// Show App Info
+	if showAppInfo {
+		showUsing()
+	}
+
+	// Fetch remote config
+	if config.IsRemote(flagConfigFile) {
+		cachePath, err := config.FetchRemote(context.Background(), flagConfigFile, flagConfigHeader, flagConfigFolder)
+		switch {
+		case err != nil && cachePath == "":
+			log.WithError(err).Fatalf("Failed fetching config: %q", flagConfigFile)
+		case err != nil:
+			log.WithError(err).Warnf("Failed fetching config: %q, using cached copy: %q", flagConfigFile, cachePath)
+		default:
+			log.Debugf("Fetched config %q to %q", flagConfigFile, cachePath)
+		}
+		configFile = cachePath
 	}
+
+	// Init Config, formatting and trackers
+	if err := tqm.Init(configFile); err != nil {
+		log.WithError(err).Fatal("Failed to initialize")
+	}
+
+	// Init client recording and replay
+	if err := initRecording(); err != nil {
+		log.WithError(err).Fatal("Failed to initialize recording")
+	}
+
+	// the first run of destructive commands against a client is a dry-run
+	enforceSafeMode()
+}
+
+// initRecording records the data retrieved from clients to --record or replays --replay instead of connecting to them
+func initRecording() error {
+	if flagRecordFile != "" && flagReplayFile != "" {
+		return fmt.Errorf("--record and --replay cannot be combined")
+	}
+
+	if flagRecordFile != "" {
+		path, err := filepath.Abs(flagRecordFile)
+		if err != nil {
+			return fmt.Errorf("resolve record file: %w", err)
+		}
+		client.Record(path)
+		log.Infof("Recording client data to: %q", path)
+	}
+
+	if flagReplayFile != "" {
+		path, err := filepath.Abs(flagReplayFile)
+		if err != nil {
+			return fmt.Errorf("resolve replay file: %w", err)
+		}
+		client.Replay(path)
+		log.Warnf("Replaying client data from %q, actions are not sent to the client", path)
+	}
+
+	return nil
+}
+
+// initLogging resolves the config and log file paths and initializes logging
+func initLogging() {
+	// Set core variables
+	flagConfigFile = resolveConfigFile()
+	configFile = flagConfigFile
 	if !rootCmd.PersistentFlags().Changed("log") {
 		flagLogFile = filepath.Join(flagConfigFolder, flagLogFile)
 	}
 
 	// Init Logging
-	if err := logger.Init(flagLogLevel, flagLogFile); err != nil {
+	if err := logger.Init(flagLogLevel, expandLogFile(flagLogFile, logCommand, logClient)); err != nil {
 		log.WithError(err).Fatal("Failed to initialize logging")
 	}
 
 	log = logger.GetLogger("app")
+}
 
-	// Show App Info
-	if showAppInfo {
-		showUsing()
+// expandLogFile replaces the {command} and {client} placeholders of the log file name, all is used for commands
+// without a client
+func expandLogFile(logFile string, command string, client string) string {
+	if command == "" {
+		command = "tqm"
 	}
-
-	// Init Config
-	if err := config.Init(flagConfigFile); err != nil {
-		log.WithError(err).Fatal("Failed to initialize config")
+	if client == "" {
+		client = "all"
 	}
 
-	// Init Trackers
-	if err := tracker.Init(config.Config.Trackers); err != nil {
-		log.WithError(err).Fatal("Failed to initialize trackers")
+	// the values are part of the file name
+	sanitize := strings.NewReplacer("/", "_", "\\", "_", ":", "_")
+
+	dir, name := filepath.Split(logFile)
+	return dir + strings.NewReplacer("{command}", sanitize.Replace(command), "{client}", sanitize.Replace(client)).Replace(name)
+}
+
+// resolveConfigFile returns the config file, relative to the config folder unless --config is set
+func resolveConfigFile() string {
+	if rootCmd.PersistentFlags().Changed("config") {
+		return flagConfigFile
 	}
+	return filepath.Join(flagConfigFolder, flagConfigFile)
 }
 
 func showUsing() {
@@ -101,81 +287,93 @@ func showUsing() {
 	log.Info("------------------")
 }
 
-func validateClientEnabled(clientConfig map[string]any) error {
-	v, ok := clientConfig["enabled"]
-	if !ok {
-		return fmt.Errorf("no enabled setting found in client configuration: %+v", clientConfig)
-	} else {
-		enabled, ok := v.(bool)
-		if !ok || !enabled {
-			return errors.New("client is not enabled")
-		}
+// supports reports whether c has the capability needed by feature, the feature is logged as skipped otherwise, so
+// commands degrade gracefully on clients lacking it
+func supports(log *logrus.Entry, c client.Interface, capable bool, feature string) bool {
+	if !capable {
+		log.Warnf("%v, skipping", client.Unsupported(c, feature))
 	}
-
-	return nil
+	return capable
 }
 
-func getClientConfigString(setting string, clientConfig map[string]any) (*string, error) {
-	v, ok := clientConfig[setting]
-	if !ok {
-		return nil, fmt.Errorf("no %q setting found in client configuration: %+v", setting, clientConfig)
+// relabelCrossSeedsEnabled reports whether non-unique (cross-seeded) torrents should be relabeled using hardlinks
+func relabelCrossSeedsEnabled(log *logrus.Entry, c client.Interface, clientConfig map[string]any) (bool, error) {
+	enabled, err := tqm.ClientBool("relabel_cross_seeds", clientConfig)
+	if err != nil {
+		return false, err
+	}
+
+	// deprecated global flag
+	enabled = enabled || flagExperimentalRelabelForCrossSeeds
+	if !enabled {
+		return false, nil
 	}
 
-	value, ok := v.(string)
-	if !ok {
-		return nil, fmt.Errorf("failed type-asserting %q of client: %#v", setting, v)
+	if !c.Capabilities().LabelPaths {
+		log.Warnf("%v, skipping non-unique torrents", client.Unsupported(c, "Relabeling cross-seeds"))
+		return false, nil
 	}
 
-	return &value, nil
+	return true, nil
 }
 
-func getClientDownloadPathMapping(clientConfig map[string]any) (map[string]string, error) {
-	v, ok := clientConfig["download_path_mapping"]
-	if !ok {
-		return nil, nil
-	}
+func getFilter(filterName string) (*config.FilterConfiguration, error) {
+	return config.GetFilter(filterName)
+}
 
-	tmp, ok := v.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("failed type-asserting download_path_mapping of client: %#v", v)
+// loadClient initializes and connects the client with the given name, compiling the client filter (or filterName, when set)
+func loadClient(ctx context.Context, clientName string, filterName string) (client.Interface, *config.FilterConfiguration, map[string]any, error) {
+	c, err := tqm.Open(ctx, clientName, filterName)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	clientDownloadPathMapping := make(map[string]string)
-	for k, v := range tmp {
-		if vv, ok := v.(string); ok {
-			clientDownloadPathMapping[k] = vv
-		} else {
-			return nil, fmt.Errorf("failed type-asserting download_path_mapping of client for %q: %#v", k, v)
-		}
+	return c.Interface, c.Filter, c.Settings, nil
+}
+
+// loadFreeSpace retrieves the current free space of the client so it can be used by filters
+func loadFreeSpace(ctx context.Context, log *logrus.Entry, c client.Interface, clientConfig map[string]any) error {
+	if loaded, err := tqm.LoadFreeSpace(ctx, c, clientConfig); err != nil || !loaded {
+		return err
 	}
 
-	return clientDownloadPathMapping, nil
+	log.Debugf("Retrieved free-space: %.2f GB", c.GetFreeSpace())
+	return nil
 }
 
-func getClientFilter(clientConfig map[string]any) (*config.FilterConfiguration, error) {
-	v, ok := clientConfig["filter"]
-	if !ok {
-		return nil, fmt.Errorf("no filter setting found in client configuration: %+v", clientConfig)
+// loadFreeInodes retrieves the free inodes of the filesystem of the client's free_space_path (mapped to where tqm sees
+// it) or download_path and sets them on torrents so they can be used by filters
+func loadFreeInodes(log *logrus.Entry, clientConfig map[string]any, command string, torrents map[string]config.Torrent) (int64, error) {
+	var path string
+	if freeSpacePath, _ := tqm.ClientString("free_space_path", clientConfig); freeSpacePath != nil && *freeSpacePath != "" {
+		mapping, err := tqm.DownloadPathMapping(clientConfig, command)
+		if err != nil {
+			return 0, fmt.Errorf("load client download path mappings: %w", err)
+		}
+		path = paths.MapPath(*freeSpacePath, mapping)
+	} else if downloadPath, _ := tqm.ClientString("download_path", clientConfig); downloadPath != nil {
+		path = *downloadPath
 	}
 
-	clientFilterName, ok := v.(string)
-	if !ok {
-		return nil, fmt.Errorf("failed type-asserting filter of client: %#v", v)
+	if path == "" {
+		return 0, errors.New("free_space_path or download_path must be set")
 	}
 
-	clientFilter, ok := config.Config.Filters[clientFilterName]
-	if !ok {
-		return nil, fmt.Errorf("failed finding configuration of filter: %+v", clientFilterName)
+	free, total, err := paths.Inodes(path)
+	if err != nil {
+		return 0, fmt.Errorf("retrieve inodes: %q: %w", path, err)
+	} else if total == 0 {
+		// e.g. btrfs allocates inodes dynamically
+		return 0, fmt.Errorf("filesystem does not report inodes: %q", path)
 	}
 
-	return &clientFilter, nil
-}
+	log.Infof("Retrieved free inodes for %q: %d of %d (%.1f%% free)", path, free, total, float64(free)/float64(total)*100)
 
-func getFilter(filterName string) (*config.FilterConfiguration, error) {
-	clientFilter, ok := config.Config.Filters[filterName]
-	if !ok {
-		return nil, fmt.Errorf("failed finding configuration of filter: %+v", filterName)
+	for h, t := range torrents {
+		t.FreeInodes = int64(free)
+		t.FreeInodesSet = true
+		torrents[h] = t
 	}
 
-	return &clientFilter, nil
+	return int64(free), nil
 }
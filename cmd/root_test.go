@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandLogFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		logFile  string
+		command  string
+		client   string
+		expected string
+	}{
+		{name: "no_placeholders", logFile: "activity.log", command: "clean", client: "qbt", expected: "activity.log"},
+		{name: "command_and_client", logFile: "activity-{command}-{client}.log", command: "clean", client: "qbt", expected: "activity-clean-qbt.log"},
+		{name: "no_client", logFile: "{command}-{client}.log", command: "config-migrate", expected: "config-migrate-all.log"},
+		{name: "sanitized", logFile: "{client}.log", command: "clean", client: "a/b:c", expected: "a_b_c.log"},
+		{name: "directory_kept", logFile: filepath.Join("{command}", "{client}.log"), command: "clean", client: "qbt", expected: filepath.Join("{command}", "qbt.log")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expandLogFile(tt.logFile, tt.command, tt.client))
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+// runSteps are the commands which can be run as steps of a pipeline
+var runSteps = []string{"retag", "relabel", "clean", "orphan"}
+
+var flagRunSteps []string
+
+var runCmd = &cobra.Command{
+	Use:   "run [CLIENT]",
+	Short: "Run multiple commands as a pipeline",
+	Long: `This command runs the given steps (retag, relabel, clean and orphan) in order against a single client connection,
+sharing the retrieved torrents and the hardlink map between the steps and sending a single combined notification.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		startTime := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("run")
+		runOutcome.track()
+
+		steps, err := parseRunSteps(flagRunSteps)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid steps")
+		}
+
+		noti := &pipelineSender{Sender: newNotificationSender(log)}
+
+		// load client object
+		clientName := args[0]
+		c, clientFilter, clientConfig, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers), steps: %s", clientName, c.Type(), tracker.Loaded(),
+			strings.Join(steps, ", "))
+
+		// get free disk space (can/will be used by filters)
+		freeSpaceErr := loadFreeSpace(ctx, log, c, clientConfig)
+		if freeSpaceErr != nil {
+			log.WithError(freeSpaceErr).Error("Failed retrieving free-space")
+		}
+
+		// retrieve torrents once for all steps
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// get free inodes (can/will be used by filters and the free inodes target of clean)
+		freeInodes, freeInodesErr := loadFreeInodes(log, clientConfig, "run", torrents)
+		if freeInodesErr != nil {
+			log.WithError(freeInodesErr).Debug("Failed retrieving free inodes")
+		}
+
+		// map the files and hardlinks of the torrents once for all steps
+		tfm, hfm := mapRunTorrents(log, torrents, clientConfig, clientFilter, steps)
+
+		for _, step := range steps {
+			stepStart := time.Now()
+			stepLog := logger.GetLogger(step)
+
+			// the torrents may have changed during the previous steps, e.g. after a relabel
+			expression.ResetScriptResults()
+
+			log.Info("========================================")
+			log.Infof("Running step: %s", step)
+			log.Info("========================================")
+
+			switch step {
+			case "retag":
+				if !supports(stepLog, c, c.Capabilities().Tags, "Retagging") {
+					break
+				}
+				ct := c.(client.TagInterface)
+
+				if qbtClient, ok := ct.(*client.QBittorrent); ok && qbtClient.CreateTagsUpfront {
+					var tagList []string
+					for _, tag := range clientFilter.Tag {
+						tagList = append(tagList, tag.Name)
+					}
+					if err := ct.CreateTags(ctx, tagList); err != nil {
+						log.WithError(err).Fatal("Failed to create tags on client")
+					}
+				}
+
+				if err := retagEligibleTorrents(ctx, stepLog, ct, torrents, noti, clientName, stepStart); err != nil {
+					log.WithError(err).Fatal("Failed retagging eligible torrents...")
+				}
+
+				if len(clientFilter.SeedLimit) > 0 && supports(stepLog, ct, ct.Capabilities().ShareLimits, "Seed limits") {
+					cs := ct.(client.ShareLimitInterface)
+					if err := setShareLimitsForEligibleTorrents(ctx, stepLog, cs, torrents, noti, clientName, stepStart); err != nil {
+						log.WithError(err).Fatal("Failed setting share limits for eligible torrents...")
+					}
+				}
+
+			case "relabel":
+				if err := c.LoadLabelPathMap(ctx); err != nil {
+					log.WithError(err).Fatal("Failed loading label path map")
+				}
+
+				relabelCrossSeeds, err := relabelCrossSeedsEnabled(stepLog, c, clientConfig)
+				if err != nil {
+					log.WithError(err).Fatal("Failed determining whether to relabel cross-seeds")
+				}
+
+				labels := torrentLabels(torrents)
+				if err := relabelEligibleTorrents(ctx, stepLog, c, torrents, tfm, relabelCrossSeeds, noti, clientName, stepStart); err != nil {
+					log.WithError(err).Fatal("Failed relabeling eligible torrents...")
+				}
+
+				// relabeled torrents may have been moved by the client, so their paths are retrieved again for the
+				// following steps, e.g. orphan would otherwise consider their moved files orphaned
+				refetched, err := refetchRelabeledTorrents(ctx, c, labels, torrents)
+				if err != nil {
+					log.WithError(err).Fatal("Failed retrieving torrents")
+				} else if refetched != nil {
+					torrents = refetched
+					log.Infof("Retrieved %d torrents after relabeling", len(torrents))
+					tfm, hfm = mapRunTorrents(log, torrents, clientConfig, clientFilter, steps)
+				}
+
+			case "clean":
+				caps, err := resolveRemovalCaps(clientFilter)
+				if err != nil {
+					log.WithError(err).Fatal("Failed loading removal caps")
+				}
+				if caps.cooldowns, err = loadRemovalCooldowns(cooldownPath(clientName), clientFilter); err != nil {
+					log.WithError(err).Fatal("Failed loading cooldowns")
+				}
+
+				if caps.freeSpaceTarget > 0 && freeSpaceErr != nil {
+					log.WithError(freeSpaceErr).Fatal("Failed retrieving free-space required by the free space target")
+				}
+				if caps.freeInodesTarget > 0 && freeInodesErr != nil {
+					log.WithError(freeInodesErr).Fatal("Failed retrieving free inodes required by the free inodes target")
+				}
+				caps.freeInodes = freeInodes
+
+				if caps.freeSpaceTarget > 0 && !tqm.ReportsFreeSpace(c) {
+					if path, _ := tqm.ClientString("free_space_path", clientConfig); path == nil {
+						log.Fatal("Deluge requires free_space_path to be configured in order to use a free space target")
+					}
+				}
+
+				cleanHfm := hfm
+				if !evaluate.StringSliceContains(clientFilter.MapHardlinksFor, "clean", true) {
+					cleanHfm = hardlinkfilemap.NewNoopHardlinkFileMap()
+				}
+
+				extractedPathMapping, err := runDownloadPathMapping(clientConfig, step)
+				if err != nil {
+					log.WithError(err).Fatal("Failed loading client download path mappings")
+				}
+				extracted, err := newExtractedArchives(clientConfig, clientFilter, tfm, extractedPathMapping)
+				if err != nil {
+					log.WithError(err).Fatal("Failed loading client orphan settings")
+				}
+				kept, err := newKeptFiles(clientFilter, tfm, extractedPathMapping)
+				if err != nil {
+					log.WithError(err).Fatal("Failed loading keep_files")
+				}
+
+				archiver, err := newCleanArchiver(c, clientFilter)
+				if err != nil {
+					log.WithError(err).Fatal("Failed initializing archive")
+				}
+
+				tagger := newDryRunTagger(stepLog, c, clientFilter)
+
+				evaluated := maps.Clone(torrents)
+				if err := exemptTorrents(stepLog, exemptionsPath(), evaluated, time.Now()); err != nil {
+					log.WithError(err).Fatal("Failed loading exemptions")
+				}
+
+				// look up the torrents of the tracker APIs supporting it in batches instead of one request per torrent
+				exp, err := expression.Compile(clientFilter)
+				if err != nil {
+					log.WithError(err).Fatal("Failed compiling client filters")
+				}
+				if exp.Uses("IsUnregistered") {
+					if found, requests := config.LookupUnregistered(ctx, evaluated); requests > 0 {
+						stepLog.Infof("Looked up %d torrents with %d bulk tracker API request(s)", found, requests)
+					}
+				}
+
+				// the torrents skipped by clean are dropped from the map it is given
+				removed, err := removeEligibleTorrents(ctx, stepLog, c, evaluated, tfm, cleanHfm, clientFilter, nil, caps,
+					extracted, kept, nil, archiver, tagger, noti, clientName, stepStart)
+				if err != nil {
+					log.WithError(err).Fatal("Failed removing eligible torrents...")
+				}
+
+				for _, h := range removed {
+					delete(torrents, h)
+				}
+				tagger.apply(ctx, stepLog, torrents)
+
+			case "orphan":
+				orphanPathMapping, err := tqm.DownloadPathMapping(clientConfig, step, "run")
+				if err != nil {
+					log.WithError(err).Fatal("Failed loading client download path mappings")
+				}
+				if err := removeOrphans(ctx, stepLog, c, clientName, clientConfig, clientFilter, orphanPathMapping, torrents,
+					noti, stepStart); err != nil {
+					log.WithError(err).Fatal("Failed removing orphans")
+				}
+			}
+		}
+
+		if err := noti.flush(clientName, time.Since(startTime)); err != nil {
+			log.WithError(err).Error("Failed sending notification")
+		}
+
+		// sent separately from the notification of the pipeline
+		summarizeLibrary(log, noti.Sender, clientName, torrents, startTime)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	runCmd.Flags().StringSliceVar(&flagRunSteps, "steps", nil, fmt.Sprintf("Steps to run in order (%s)", strings.Join(runSteps, ", ")))
+	_ = runCmd.MarkFlagRequired("steps")
+}
+
+// mapRunTorrents maps the files of torrents, and their hardlinks when one of steps is listed in MapHardlinksFor
+func mapRunTorrents(log *logrus.Entry, torrents map[string]config.Torrent, clientConfig map[string]any,
+	clientFilter *config.FilterConfiguration, steps []string) (*torrentfilemap.TorrentFileMap, hardlinkfilemap.HardlinkFileMapI) {
+	// create map of files associated to torrents (via hash)
+	tfm := torrentfilemap.New(torrents)
+	log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
+
+	var hfm hardlinkfilemap.HardlinkFileMapI = hardlinkfilemap.NewNoopHardlinkFileMap()
+	hardlinkSteps := slices.DeleteFunc(slices.Clone(steps), func(step string) bool {
+		return !evaluate.StringSliceContains(clientFilter.MapHardlinksFor, step, true)
+	})
+	if len(hardlinkSteps) == 0 {
+		return tfm, hfm
+	}
+
+	// the mapping of the first step mapping hardlinks is used for all of them
+	clientDownloadPathMapping, err := runDownloadPathMapping(clientConfig, hardlinkSteps...)
+	if err != nil {
+		log.WithError(err).Fatal("Failed loading client download path mappings")
+	}
+
+	start := time.Now()
+	hfm = hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+	log.Infof("Mapped all torrent file paths to %d unique underlying file IDs in %s", hfm.Length(), formatting.Duration(time.Since(start)))
+
+	// add HardlinkedOutsideClient field to torrents
+	for h, t := range torrents {
+		t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+		torrents[h] = t
+	}
+
+	return tfm, hfm
+}
+
+// torrentLabels returns the labels of torrents by hash
+func torrentLabels(torrents map[string]config.Torrent) map[string]string {
+	labels := make(map[string]string, len(torrents))
+	for h, t := range torrents {
+		labels[h] = t.Label
+	}
+	return labels
+}
+
+// refetchRelabeledTorrents retrieves the torrents again when their labels changed from labels outside dry-run, nil
+// when they are unchanged
+func refetchRelabeledTorrents(ctx context.Context, c client.Interface, labels map[string]string,
+	torrents map[string]config.Torrent) (map[string]config.Torrent, error) {
+	if flagDryRun || maps.Equal(labels, torrentLabels(torrents)) {
+		return nil, nil
+	}
+	return c.GetTorrents(ctx)
+}
+
+// runDownloadPathMapping returns the download path mapping of the first of steps having one, falling back to the
+// mapping of the pipeline
+func runDownloadPathMapping(clientConfig map[string]any, steps ...string) (map[string]string, error) {
+	return tqm.DownloadPathMapping(clientConfig, append(slices.Clone(steps), "run", tqm.PathMappingHardlinks)...)
+}
+
+// parseRunSteps validates the steps of a pipeline
+func parseRunSteps(steps []string) ([]string, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no steps given (supported: %s)", strings.Join(runSteps, ", "))
+	}
+
+	parsed := make([]string, 0, len(steps))
+	for _, step := range steps {
+		step = strings.ToLower(strings.TrimSpace(step))
+		switch {
+		case !slices.Contains(runSteps, step):
+			return nil, fmt.Errorf("unsupported step: %q (supported: %s)", step, strings.Join(runSteps, ", "))
+		case slices.Contains(parsed, step):
+			return nil, fmt.Errorf("duplicate step: %q", step)
+		}
+		parsed = append(parsed, step)
+	}
+
+	return parsed, nil
+}
+
+// pipelineSender collects the notifications of the steps of a pipeline to send them as a single notification
+type pipelineSender struct {
+	notification.Sender
+
+	descriptions []string
+	fields       []notification.Field
+}
+
+func (s *pipelineSender) Send(title string, description string, _ string, _ time.Duration, fields []notification.Field, _ bool) error {
+	s.descriptions = append(s.descriptions, fmt.Sprintf("%s: %s", title, description))
+	s.fields = append(s.fields, fields...)
+	return nil
+}
+
+// flush sends the collected notifications
+func (s *pipelineSender) flush(client string, runTime time.Duration) error {
+	if !s.CanSend() || len(s.descriptions) == 0 {
+		return nil
+	}
+
+	return s.Sender.Send("Torrent Pipeline", strings.Join(s.descriptions, "\n"), client, runTime, s.fields, flagDryRun)
+}
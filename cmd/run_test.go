@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/notification"
+)
+
+func TestParseRunSteps(t *testing.T) {
+	steps, err := parseRunSteps([]string{"Retag", " relabel", "clean", "orphan"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"retag", "relabel", "clean", "orphan"}, steps)
+
+	for _, invalid := range [][]string{nil, {"clean", "move"}, {"clean", "clean"}} {
+		_, err := parseRunSteps(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// recordingSender records the notifications sent
+type recordingSender struct {
+	nopSender
+	sent []string
+}
+
+func (s *recordingSender) CanSend() bool { return true }
+func (s *recordingSender) Send(title string, description string, _ string, _ time.Duration, fields []notification.Field, _ bool) error {
+	s.sent = append(s.sent, title+"\n"+description)
+	return nil
+}
+
+func TestPipelineSender(t *testing.T) {
+	inner := &recordingSender{}
+	noti := &pipelineSender{Sender: inner}
+
+	require.NoError(t, noti.Send("Torrent Retag", "Retagged **2** torrent(s)", "qbt", time.Second,
+		[]notification.Field{{Name: "a"}}, false))
+	require.NoError(t, noti.Send("Torrent Cleanup", "Removed **1** torrent(s)", "qbt", time.Second,
+		[]notification.Field{{Name: "b"}}, false))
+	assert.Empty(t, inner.sent)
+	assert.Len(t, noti.fields, 2)
+
+	require.NoError(t, noti.flush("qbt", time.Minute))
+	assert.Equal(t, []string{"Torrent Pipeline\nTorrent Retag: Retagged **2** torrent(s)\nTorrent Cleanup: Removed **1** torrent(s)"}, inner.sent)
+}
+
+func TestRunDownloadPathMapping(t *testing.T) {
+	clientConfig := map[string]any{
+		"download_path_mappings": map[string]any{
+			"clean":     map[string]any{"/downloads": "/mnt/clean"},
+			"run":       map[string]any{"/downloads": "/mnt/run"},
+			"hardlinks": map[string]any{"/downloads": "/mnt/hardlinks"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		steps    []string
+		expected string
+	}{
+		{name: "step", steps: []string{"clean"}, expected: "/mnt/clean"},
+		{name: "first_step", steps: []string{"retag", "clean"}, expected: "/mnt/clean"},
+		{name: "pipeline", steps: []string{"retag"}, expected: "/mnt/run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, err := runDownloadPathMapping(clientConfig, tt.steps...)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"/downloads": tt.expected}, mapping)
+		})
+	}
+
+	delete(clientConfig["download_path_mappings"].(map[string]any), "run")
+	mapping, err := runDownloadPathMapping(clientConfig, "retag")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/downloads": "/mnt/hardlinks"}, mapping)
+}
+
+// torrentsClient returns torrents and counts how often they were retrieved
+type torrentsClient struct {
+	client.Interface
+
+	torrents map[string]config.Torrent
+	calls    int
+}
+
+func (c *torrentsClient) GetTorrents(context.Context) (map[string]config.Torrent, error) {
+	c.calls++
+	return c.torrents, nil
+}
+
+func TestRefetchRelabeledTorrents(t *testing.T) {
+	prevDryRun := flagDryRun
+	t.Cleanup(func() { flagDryRun = prevDryRun })
+
+	moved := map[string]config.Torrent{"a": {Hash: "a", Label: "movies", Path: "/downloads/movies"}}
+	c := &torrentsClient{torrents: moved}
+	labels := map[string]string{"a": "incoming"}
+	ctx := context.Background()
+
+	// unchanged labels keep the torrents
+	flagDryRun = false
+	torrents, err := refetchRelabeledTorrents(ctx, c, labels, map[string]config.Torrent{"a": {Hash: "a", Label: "incoming"}})
+	require.NoError(t, err)
+	assert.Nil(t, torrents)
+
+	// nothing was moved in dry-run
+	flagDryRun = true
+	torrents, err = refetchRelabeledTorrents(ctx, c, labels, map[string]config.Torrent{"a": {Hash: "a", Label: "movies"}})
+	require.NoError(t, err)
+	assert.Nil(t, torrents)
+	assert.Zero(t, c.calls)
+
+	flagDryRun = false
+	torrents, err = refetchRelabeledTorrents(ctx, c, labels, map[string]config.Torrent{"a": {Hash: "a", Label: "movies"}})
+	require.NoError(t, err)
+	assert.Equal(t, moved, torrents)
+	assert.Equal(t, 1, c.calls)
+}
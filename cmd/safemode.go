@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// firstRunStateFile records when the first run against each client completed
+const firstRunStateFile = "first-run.json"
+
+var (
+	flagIKnowWhatImDoing bool
+
+	// safeModeForced is set when dry-run was forced on the first run against the client
+	safeModeForced bool
+
+	// safeModeCommands remove torrents or delete data, their first run against a client is a dry-run
+	safeModeCommands = []string{"clean", "orphan", "dedupe", "prune-files", "run"}
+)
+
+// enforceSafeMode forces dry-run when a destructive command is run against a client for the first time, unless
+// --i-know-what-im-doing is given
+func enforceSafeMode() {
+	if flagDryRun || flagIKnowWhatImDoing || flagReplayFile != "" || !slices.Contains(safeModeCommands, logCommand) {
+		return
+	}
+	if _, ok := config.Config.Clients[logClient]; !ok {
+		return
+	}
+
+	pending, err := firstRunPending(config.StatePath(firstRunStateFile), history.Path(), logClient)
+	if err != nil {
+		log.WithError(err).Warn("Failed checking for the first run against the client, forcing dry-run")
+	} else if !pending {
+		return
+	}
+
+	log.Warnf("First run against client %q, forcing dry-run to protect it from misconfigured filters", logClient)
+	log.Warn("Review the summary printed at the end, then run again (or pass --i-know-what-im-doing to skip this check)")
+	flagDryRun = true
+	safeModeForced = true
+}
+
+// completeFirstRun records that cmd completed its first run against the client, the following runs are no longer
+// forced into dry-run
+func completeFirstRun() {
+	if flagReplayFile != "" || !slices.Contains(safeModeCommands, logCommand) {
+		return
+	}
+	if config.Config == nil {
+		return
+	}
+	if _, ok := config.Config.Clients[logClient]; !ok {
+		return
+	}
+	if !firstRunCounts() {
+		return
+	}
+
+	if err := recordFirstRun(config.StatePath(firstRunStateFile), logClient, time.Now()); err != nil {
+		log.WithError(err).Warn("Failed recording the first run against the client")
+	}
+
+	if safeModeForced {
+		summarizeSafeMode()
+	}
+}
+
+// firstRunCounts reports whether the run completes the first run against the client: a run acting on the client or
+// the dry-run forced by safe mode, whose summary is the review. A dry-run asked for with --dry-run does not count.
+func firstRunCounts() bool {
+	return !flagDryRun || safeModeForced
+}
+
+// summarizeSafeMode logs the actions the forced dry-run would have taken
+func summarizeSafeMode() {
+	results.mu.Lock()
+	defer results.mu.Unlock()
+
+	log.Warnf("Safe mode: the first run against client %q was a dry-run, %d actions would have been taken",
+		logClient, len(results.actions))
+
+	counts := make(map[string]int)
+	for _, a := range results.actions {
+		counts[a.Action]++
+
+		name := a.Name
+		if name == "" {
+			name = a.Path
+		}
+		if a.Reason != "" {
+			log.Infof("Would %s: %q (%s)", a.Action, name, a.Reason)
+		} else {
+			log.Infof("Would %s: %q", a.Action, name)
+		}
+	}
+
+	for _, action := range slices.Sorted(maps.Keys(counts)) {
+		log.Warnf("Would %s: %d", action, counts[action])
+	}
+}
+
+// firstRunPending reports whether no run against client completed yet, according to the first run state at path and
+// the actions recorded in the history at historyPath (for runs before the first run state was recorded)
+func firstRunPending(path string, historyPath string, client string) (bool, error) {
+	state, err := loadFirstRunState(path)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := state[client]; ok {
+		return false, nil
+	}
+
+	entries, err := history.Read(historyPath, history.Query{Client: client})
+	if err != nil {
+		return false, err
+	}
+	return len(entries) == 0, nil
+}
+
+// recordFirstRun records the first run against client at path, unless one is already recorded
+func recordFirstRun(path string, client string, at time.Time) error {
+	state, err := loadFirstRunState(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := state[client]; ok {
+		return nil
+	}
+
+	state[client] = at
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode first run state: %w", err)
+	}
+
+	if err := paths.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("write first run state: %w", err)
+	}
+	return nil
+}
+
+func loadFirstRunState(path string) (map[string]time.Time, error) {
+	state := map[string]time.Time{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read first run state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode first run state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstRunPending(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, firstRunStateFile)
+	historyPath := filepath.Join(dir, "history.jsonl")
+
+	require.NoError(t, os.WriteFile(historyPath, []byte(`{"time":"2026-01-02T03:04:05Z","client":"deluge","action":"remove","name":"Some.Torrent"}`+"\n"), 0600))
+
+	pending, err := firstRunPending(statePath, historyPath, "qbt")
+	require.NoError(t, err)
+	assert.True(t, pending)
+
+	// actions in the history predate the first run state
+	pending, err = firstRunPending(statePath, historyPath, "deluge")
+	require.NoError(t, err)
+	assert.False(t, pending)
+
+	require.NoError(t, recordFirstRun(statePath, "qbt", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, recordFirstRun(statePath, "qbt", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)))
+
+	pending, err = firstRunPending(statePath, historyPath, "qbt")
+	require.NoError(t, err)
+	assert.False(t, pending)
+
+	state, err := loadFirstRunState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), state["qbt"].UTC())
+}
+
+func TestFirstRunCounts(t *testing.T) {
+	prevDryRun, prevForced := flagDryRun, safeModeForced
+	t.Cleanup(func() { flagDryRun, safeModeForced = prevDryRun, prevForced })
+
+	tests := []struct {
+		name     string
+		dryRun   bool
+		forced   bool
+		expected bool
+	}{
+		{name: "run", expected: true},
+		{name: "forced_dry_run", dryRun: true, forced: true, expected: true},
+		{name: "dry_run", dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagDryRun, safeModeForced = tt.dryRun, tt.forced
+			assert.Equal(t, tt.expected, firstRunCounts())
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+// flagSample is the number of random torrents clean and retag evaluate without taking any action
+var flagSample int
+
+// samplePrediction holds the rules a sampled torrent matched and the actions a full run would take for it
+type samplePrediction struct {
+	Hash    string   `json:"hash"`
+	Name    string   `json:"name"`
+	Rules   []string `json:"rules"`
+	Actions []string `json:"actions"`
+}
+
+// sampleTorrents returns n torrents picked at random from torrents, or all of them when there are not more than n
+func sampleTorrents(torrents map[string]config.Torrent, n int, rnd *rand.Rand) map[string]config.Torrent {
+	if len(torrents) <= n {
+		return torrents
+	}
+
+	hashes := make([]string, 0, len(torrents))
+	for h := range torrents {
+		hashes = append(hashes, h)
+	}
+	// sort first so the sample only depends on rnd
+	sort.Strings(hashes)
+	rnd.Shuffle(len(hashes), func(i, j int) {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	})
+
+	sampled := make(map[string]config.Torrent, n)
+	for _, h := range hashes[:n] {
+		sampled[h] = torrents[h]
+	}
+	return sampled
+}
+
+// runCleanSample prints the rules matched and the actions clean would take for a random sample of torrents
+func runCleanSample(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
+	tfm *torrentfilemap.TorrentFileMap, hfm hardlinkfilemap.HardlinkFileMapI, filter *config.FilterConfiguration) error {
+	deleteData := true
+	if filter != nil && filter.DeleteData != nil {
+		deleteData = *filter.DeleteData
+	}
+
+	sampled := sampleTorrents(torrents, flagSample, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	log.Infof("Evaluating a sample of %d of %d torrents, no actions are taken", len(sampled), len(torrents))
+
+	predictions := make([]samplePrediction, 0, len(sampled))
+	for _, t := range sampled {
+		p, err := predictClean(ctx, c, &t, tfm, hfm, deleteData)
+		if err != nil {
+			return fmt.Errorf("evaluate torrent: %q: %w", t.Name, err)
+		}
+		predictions = append(predictions, p)
+	}
+
+	return writeSamplePredictions(os.Stdout, predictions)
+}
+
+// predictClean mirrors the decisions of removeEligibleTorrents for a single torrent
+func predictClean(ctx context.Context, c client.Interface, t *config.Torrent, tfm *torrentfilemap.TorrentFileMap,
+	hfm hardlinkfilemap.HardlinkFileMapI, deleteData bool) (samplePrediction, error) {
+	p := samplePrediction{Hash: t.Hash, Name: t.Name}
+
+	ignore, reason, err := c.ShouldIgnore(ctx, t)
+	if err != nil {
+		return p, err
+	} else if ignore {
+		if !(config.Config.BypassIgnoreIfUnregistered && t.IsUnregistered(ctx)) {
+			p.Rules = append(p.Rules, "ignore: "+reason)
+			p.Actions = append(p.Actions, "ignore")
+			return p, nil
+		}
+		p.Rules = append(p.Rules, "ignore (bypassed, unregistered): "+reason)
+	}
+
+	remove, reason, err := c.ShouldRemoveWithReason(ctx, t)
+	if err != nil {
+		return p, err
+	} else if !remove {
+		return p, nil
+	}
+	p.Rules = append(p.Rules, "remove: "+reason)
+
+	isHardlinked := !hfm.IsTorrentUnique(*t)
+	isUnique := tfm.IsUnique(*t) && !isHardlinked
+
+	// data of torrents sharing files with other torrents (but not hardlinked) is always kept
+	keepData := !deleteData || (!isUnique && !isHardlinked)
+	action := "remove with data"
+	if keepData {
+		action = "remove (keep data)"
+	}
+
+	switch {
+	case isUnique, t.IsUnregistered(ctx):
+		p.Actions = append(p.Actions, action)
+	case isHardlinked:
+		p.Actions = append(p.Actions, action+" if every hardlinked torrent is removed")
+	default:
+		p.Actions = append(p.Actions, action+" if every torrent sharing its files is removed")
+	}
+
+	return p, nil
+}
+
+// runRetagSample prints the rules matched and the actions retag would take for a random sample of torrents
+func runRetagSample(ctx context.Context, log *logrus.Entry, ct client.TagInterface, exp *expression.Expressions,
+	torrents map[string]config.Torrent) error {
+	sampled := sampleTorrents(torrents, flagSample, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	log.Infof("Evaluating a sample of %d of %d torrents, no actions are taken", len(sampled), len(torrents))
+
+	predictions := make([]samplePrediction, 0, len(sampled))
+	for _, t := range sampled {
+		p, err := predictRetag(ctx, ct, exp, &t)
+		if err != nil {
+			return fmt.Errorf("evaluate torrent: %q: %w", t.Name, err)
+		}
+		predictions = append(predictions, p)
+	}
+
+	return writeSamplePredictions(os.Stdout, predictions)
+}
+
+// predictRetag mirrors the decisions of retagEligibleTorrents and setShareLimitsForEligibleTorrents for a single torrent
+func predictRetag(ctx context.Context, ct client.TagInterface, exp *expression.Expressions, t *config.Torrent) (samplePrediction, error) {
+	p := samplePrediction{Hash: t.Hash, Name: t.Name}
+
+	for _, tag := range exp.Tags {
+		match, err := expression.CheckTorrentAllMatch(ctx, t, tag.Updates)
+		if err != nil {
+			return p, err
+		} else if match {
+			p.Rules = append(p.Rules, "tag: "+tag.Name)
+		}
+	}
+
+	retagInfo, err := ct.ShouldRetag(ctx, t)
+	if err != nil {
+		return p, err
+	}
+
+	for _, tag := range slices.Sorted(maps.Keys(retagInfo.Add)) {
+		p.Actions = append(p.Actions, "add tag "+tag)
+	}
+	for _, tag := range slices.Sorted(maps.Keys(retagInfo.Remove)) {
+		p.Actions = append(p.Actions, "remove tag "+tag)
+	}
+	if retagInfo.UploadKb != nil {
+		p.Actions = append(p.Actions, fmt.Sprintf("set upload limit %d KiB/s", *retagInfo.UploadKb))
+	}
+
+	if cs, ok := ct.(client.ShareLimitInterface); ok && len(exp.SeedLimits) > 0 {
+		limits, err := cs.ShouldSetShareLimits(ctx, t)
+		if err != nil {
+			return p, err
+		} else if limits != nil {
+			p.Rules = append(p.Rules, "seedLimit: "+limits.Name)
+			p.Actions = append(p.Actions, "set share limits "+limits.Name)
+		}
+	}
+
+	return p, nil
+}
+
+func writeSamplePredictions(w io.Writer, predictions []samplePrediction) error {
+	sort.Slice(predictions, func(i, j int) bool {
+		return predictions[i].Name < predictions[j].Name
+	})
+
+	if flagOutput == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(predictions)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tHASH\tMATCHED RULES\tPREDICTED ACTIONS\t")
+	for _, p := range predictions {
+		rules, actions := "-", "none"
+		if len(p.Rules) > 0 {
+			rules = strings.Join(p.Rules, "; ")
+		}
+		if len(p.Actions) > 0 {
+			actions = strings.Join(p.Actions, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", p.Name, p.Hash, rules, actions)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestSampleTorrents(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a"},
+		"b": {Hash: "b"},
+		"c": {Hash: "c"},
+		"d": {Hash: "d"},
+		"e": {Hash: "e"},
+	}
+
+	sampled := sampleTorrents(torrents, 2, rand.New(rand.NewPCG(1, 2)))
+	require.Len(t, sampled, 2)
+	for h, tr := range sampled {
+		assert.Equal(t, torrents[h], tr)
+	}
+
+	// the same seed picks the same torrents
+	assert.Equal(t, sampled, sampleTorrents(torrents, 2, rand.New(rand.NewPCG(1, 2))))
+
+	// all torrents are returned when there are not more than n
+	assert.Equal(t, torrents, sampleTorrents(torrents, 10, rand.New(rand.NewPCG(1, 2))))
+}
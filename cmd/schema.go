@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/schema"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON schema of the exported documents",
+	Long: `Prints the JSON schema of the documents written by the export command and printed with --output json and
+--output jsonl, every document carries the version of its schema as schema_version.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, _ = os.Stdout.Write(schema.JSONSchema())
+	},
+	DisableFlagsInUseLine: true,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,401 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+	"github.com/autobrr/tqm/pkg/tqm"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+const (
+	defaultServeHost = "127.0.0.1"
+	defaultServePort = 7337
+)
+
+var (
+	flagServeHost     string
+	flagServePort     int
+	flagServeGRPCPort int
+
+	// webhookActions are the actions that can be triggered for a single torrent via webhook
+	webhookActions = []string{"retag", "relabel"}
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a HTTP server accepting webhooks and API calls",
+	Long: `This command starts a HTTP server that accepts webhook calls (e.g. from qBittorrent's "run external program on completion" or autobrr)
+to retag/relabel a single torrent as soon as it is added or completed. It listens on 127.0.0.1 unless --host (or
+serve.host) is set.
+
+With serve.api_key set, every request has to send it in the X-API-Key header. It also exposes a small REST API to
+trigger runs of the clean, relabel, retag, pause, resume, recheck, reannounce, move and orphan commands and to query
+their results, so tqm can be driven from dashboards and other tools. Runs are only accepted with an api_key configured.
+
+With a gRPC port, the same is served as a gRPC service streaming the actions of runs as they are taken. Without
+serve.grpc_tls_cert and serve.grpc_tls_key it only listens on localhost, as the api key would be sent in plaintext.
+With inventory.interval set, inventory snapshots of the clients are taken periodically.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("serve")
+
+		if err := runServer(ctx, log, serveAddress(cmd), serveGRPCAddress(cmd)); err != nil {
+			log.WithError(err).Fatal("Failed serving")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&flagServeHost, "host", defaultServeHost, "Host to listen on")
+	serveCmd.Flags().IntVar(&flagServePort, "port", defaultServePort, "Port to listen on")
+	serveCmd.Flags().IntVar(&flagServeGRPCPort, "grpc-port", 0, "Port to serve the gRPC service on (disabled when 0)")
+}
+
+// serveAddress returns the address to listen on, the host and port flags take precedence over the serve config
+func serveAddress(cmd *cobra.Command) string {
+	host := config.Config.Serve.Host
+	if cmd.Flags().Changed("host") || host == "" {
+		host = flagServeHost
+	}
+
+	port := config.Config.Serve.Port
+	if cmd.Flags().Changed("port") || port == 0 {
+		port = flagServePort
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// serveGRPCAddress returns the address to serve the gRPC service on, empty when it is disabled
+func serveGRPCAddress(cmd *cobra.Command) string {
+	port := config.Config.Serve.GRPCPort
+	if cmd.Flags().Changed("grpc-port") {
+		port = flagServeGRPCPort
+	}
+	if port == 0 {
+		return ""
+	}
+
+	host, _, _ := net.SplitHostPort(serveAddress(cmd))
+	if config.Config.Serve.GRPCTLSCert == "" && !isLoopbackHost(host) {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// isLoopbackHost reports whether host only accepts connections from the local machine
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// runServer serves webhooks and API calls on addr, and the gRPC service on grpcAddr when set, until ctx is cancelled
+func runServer(ctx context.Context, log *logrus.Entry, addr string, grpcAddr string) error {
+	s := &server{
+		log:        log,
+		ctx:        ctx,
+		apiKey:     config.Config.Serve.APIKey,
+		runCommand: execCommandRunner,
+	}
+
+	if s.apiKey == "" {
+		log.Warn("No serve api_key configured, runs cannot be triggered and webhooks are accepted without authentication")
+	}
+
+	s.scheduleInventory(config.Config.Inventory.WithDefaults())
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Warn("Failed shutting down server gracefully")
+		}
+	}()
+
+	if grpcAddr != "" {
+		var opts []grpc.ServerOption
+		if cfg := config.Config.Serve; cfg.GRPCTLSCert != "" {
+			creds, err := credentials.NewServerTLSFromFile(cfg.GRPCTLSCert, cfg.GRPCTLSKey)
+			if err != nil {
+				return fmt.Errorf("load grpc tls certificate: %w", err)
+			}
+			opts = append(opts, grpc.Creds(creds))
+		} else {
+			log.Info("No serve grpc_tls_cert configured, the gRPC service is only served on localhost")
+		}
+
+		// listen first, so a port in use fails the command
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return fmt.Errorf("listen grpc: %w", err)
+		}
+
+		go func() {
+			if err := serveGRPC(ctx, log, s, lis, opts...); err != nil {
+				log.WithError(err).Error("Failed serving gRPC")
+			}
+		}()
+	}
+
+	log.Infof("Listening on %s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+type server struct {
+	log    *logrus.Entry
+	ctx    context.Context
+	apiKey string
+
+	// runs are serialized as commands share global state
+	mu sync.Mutex
+
+	runs       runStore
+	runCommand commandRunner
+}
+
+type webhookRequest struct {
+	Hash    string   `json:"hash"`
+	Actions []string `json:"actions"`
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("POST /api/webhook/{client}", s.authenticate(s.handleWebhook))
+	// runs remove torrents and files, they are never accepted without authentication
+	if s.apiKey != "" {
+		mux.HandleFunc("POST /api/run/{command}/{client}", s.authenticate(s.handleRun))
+	}
+	mux.HandleFunc("GET /api/runs", s.authenticate(s.handleListRuns))
+	mux.HandleFunc("GET /api/runs/{id}", s.authenticate(s.handleGetRun))
+	return mux
+}
+
+// authenticate validates the api key (if configured) sent via the X-API-Key header
+func (s *server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey == "" {
+			next(w, r)
+			return
+		}
+
+		if !validAPIKey(r.Header.Get("X-API-Key"), s.apiKey) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	clientName := r.PathValue("client")
+	if _, ok := config.Config.Clients[clientName]; !ok {
+		http.Error(w, fmt.Sprintf("unknown client: %q", clientName), http.StatusNotFound)
+		return
+	}
+
+	req, err := parseWebhookRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.log.Infof("Received webhook for client %q, torrent: %s (actions: %s)", clientName, req.Hash,
+		strings.Join(req.Actions, ", "))
+
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := processTorrentHash(s.ctx, s.log, clientName, req.Hash, req.Actions); err != nil {
+			s.log.WithError(err).Errorf("Failed processing webhook for torrent: %s", req.Hash)
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// validAPIKey compares key to the configured api key in constant time
+func validAPIKey(key string, apiKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1
+}
+
+// parseWebhookRequest reads the torrent hash and actions from a JSON body, form values or query parameters
+func parseWebhookRequest(r *http.Request) (*webhookRequest, error) {
+	req := &webhookRequest{}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, fmt.Errorf("decode body: %w", err)
+		}
+	} else {
+		req.Hash = r.FormValue("hash")
+		if actions := r.FormValue("actions"); actions != "" {
+			req.Actions = strings.Split(actions, ",")
+		}
+	}
+
+	hash, err := normalizeHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	req.Hash = hash
+
+	if len(req.Actions) == 0 {
+		req.Actions = webhookActions
+	}
+
+	for i, action := range req.Actions {
+		req.Actions[i] = strings.ToLower(strings.TrimSpace(action))
+		if !evaluate.StringSliceContains(webhookActions, req.Actions[i], false) {
+			return nil, fmt.Errorf("unsupported action: %q", action)
+		}
+	}
+
+	return req, nil
+}
+
+// isValidHash checks whether hash is a v1 (sha1) or v2 (sha256) info hash
+func isValidHash(hash string) bool {
+	if len(hash) != 40 && len(hash) != 64 {
+		return false
+	}
+
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// processTorrentHash runs the given actions against a single torrent of a client
+func processTorrentHash(ctx context.Context, log *logrus.Entry, clientName string, hash string, actions []string) error {
+	startTime := time.Now()
+
+	// the tracker APIs which failed repeatedly during a previous webhook are called again, scripts are run again,
+	// and the statistics of the expressions compiled for the previous webhook are dropped
+	tracker.ResetBreakers()
+	expression.ResetScriptResults()
+	expression.ResetStats()
+
+	// serve keeps running, the tracker API results cached during the webhook are persisted once it is done
+	defer tracker.FlushCache()
+
+	c, clientFilter, clientConfig, err := loadClient(ctx, clientName, "")
+	if err != nil {
+		return err
+	}
+
+	if err := loadFreeSpace(ctx, log, c, clientConfig); err != nil {
+		log.WithError(err).Warn("Failed retrieving free-space")
+	}
+
+	torrents, err := c.GetTorrents(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieve torrents: %w", err)
+	}
+
+	target, missing := scopeTorrentsToHashes(torrents, []string{hash})
+	if len(missing) > 0 {
+		return fmt.Errorf("torrent not found in client: %s", hash)
+	}
+	t := target[hash]
+
+	for _, action := range actions {
+		if evaluate.StringSliceContains(clientFilter.MapHardlinksFor, action, true) {
+			clientDownloadPathMapping, err := tqm.DownloadPathMapping(clientConfig, action, tqm.PathMappingHardlinks)
+			if err != nil {
+				return fmt.Errorf("load client download path mappings: %w", err)
+			}
+
+			hfm := hardlinkfilemap.New(torrents, clientDownloadPathMapping)
+			t.HardlinkedOutsideClient = hfm.HardlinkedOutsideClient(t)
+			break
+		}
+	}
+
+	noti := notification.NewDiscordSender(log, config.Config.Notifications)
+
+	for _, action := range actions {
+		target := map[string]config.Torrent{hash: t}
+
+		switch action {
+		case "retag":
+			if !supports(log, c, c.Capabilities().Tags, "Retagging") {
+				continue
+			}
+			ct := c.(client.TagInterface)
+
+			if err := retagEligibleTorrents(ctx, log, ct, target, noti, clientName, startTime); err != nil {
+				return fmt.Errorf("retag torrent: %w", err)
+			}
+
+		case "relabel":
+			if err := c.LoadLabelPathMap(ctx); err != nil {
+				return fmt.Errorf("load label path map: %w", err)
+			}
+
+			relabelCrossSeeds, err := relabelCrossSeedsEnabled(log, c, clientConfig)
+			if err != nil {
+				return fmt.Errorf("determine whether to relabel cross-seeds: %w", err)
+			}
+
+			tfm := torrentfilemap.New(torrents)
+			if err := relabelEligibleTorrents(ctx, log, c, target, tfm, relabelCrossSeeds, noti, clientName, startTime); err != nil {
+				return fmt.Errorf("relabel torrent: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
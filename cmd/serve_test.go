@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestServer_API(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{Clients: map[string]map[string]any{"qbt": {}}}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	argsCh := make(chan []string, 1)
+	s := &server{
+		log:    logrus.NewEntry(logrus.New()),
+		ctx:    context.Background(),
+		apiKey: "secret",
+		runCommand: func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (int, error) {
+			fmt.Fprintln(stderr, "done")
+			argsCh <- args
+			return exitPartialFailure, nil
+		},
+	}
+	h := s.routes()
+
+	do := func(method string, target string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("X-API-Key", "secret")
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// health does not require authentication
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/runs", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/run/update/qbt", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/run/clean/unknown", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/runs/last", "").Code)
+
+	rec = do(http.MethodPost, "/api/run/clean/qbt", `{"dry_run": true, "filter": "testing"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	select {
+	case args := <-argsCh:
+		assert.Equal(t, []string{"clean", "qbt"}, args[:2])
+		assert.Contains(t, args, "--dry-run")
+		assert.Equal(t, "testing", args[len(args)-1])
+	case <-time.After(5 * time.Second):
+		t.Fatal("run was not executed")
+	}
+
+	var run apiRun
+	require.Eventually(t, func() bool {
+		rec := do(http.MethodGet, "/api/runs/1", "")
+		if rec.Code != http.StatusOK {
+			return false
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &run))
+		return run.Status != runStatusQueued && run.Status != runStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, runStatusFailed, run.Status)
+	require.NotNil(t, run.ExitCode)
+	assert.Equal(t, exitPartialFailure, *run.ExitCode)
+	assert.Equal(t, "done\n", run.Output)
+
+	var runs []apiRun
+	rec = do(http.MethodGet, "/api/runs", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &runs))
+	require.Len(t, runs, 1)
+	assert.Equal(t, 1, runs[0].ID)
+	assert.Empty(t, runs[0].Output)
+}
+
+func TestServer_RunRequiresAPIKey(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{Clients: map[string]map[string]any{"qbt": {}}}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	s := &server{
+		log: logrus.NewEntry(logrus.New()),
+		ctx: context.Background(),
+		runCommand: func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (int, error) {
+			t.Error("run executed without an api key")
+			return 0, nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/run/clean/qbt", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_Authenticate(t *testing.T) {
+	s := &server{log: logrus.NewEntry(logrus.New()), ctx: context.Background(), apiKey: "secret"}
+	h := s.routes()
+
+	tests := []struct {
+		name   string
+		target string
+		key    string
+		want   int
+	}{
+		{name: "valid key", target: "/api/runs", key: "secret", want: http.StatusOK},
+		{name: "missing key", target: "/api/runs", want: http.StatusUnauthorized},
+		{name: "wrong key", target: "/api/runs", key: "secreT", want: http.StatusUnauthorized},
+		{name: "key prefix", target: "/api/runs", key: "secre", want: http.StatusUnauthorized},
+		{name: "query parameter", target: "/api/runs?apikey=secret", want: http.StatusUnauthorized},
+		{name: "webhook without key", target: "/api/webhook/qbt", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodGet
+			if strings.HasPrefix(tt.target, "/api/webhook") {
+				method = http.MethodPost
+			}
+
+			req := httptest.NewRequest(method, tt.target, nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestServer_Webhook(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{Clients: map[string]map[string]any{"qbt": {}}}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	s := &server{log: logrus.NewEntry(logrus.New()), ctx: context.Background(), apiKey: "secret"}
+	h := s.routes()
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		want   int
+	}{
+		{name: "unknown client", target: "/api/webhook/unknown", body: `{"hash": "` + strings.Repeat("a", 40) + `"}`,
+			want: http.StatusNotFound},
+		{name: "missing hash", target: "/api/webhook/qbt", body: `{}`, want: http.StatusBadRequest},
+		{name: "invalid hash", target: "/api/webhook/qbt", body: `{"hash": "not-a-hash"}`, want: http.StatusBadRequest},
+		{name: "invalid json", target: "/api/webhook/qbt", body: `{"hash":`, want: http.StatusBadRequest},
+		{name: "unsupported action", target: "/api/webhook/qbt",
+			body: `{"hash": "` + strings.Repeat("a", 40) + `", "actions": ["clean"]}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("X-API-Key", "secret")
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestParseWebhookRequest(t *testing.T) {
+	v1 := strings.Repeat("ab", 20)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		target      string
+		want        *webhookRequest
+		wantErr     bool
+	}{
+		{
+			name:        "json with default actions",
+			contentType: "application/json",
+			body:        `{"hash": "` + strings.ToUpper(v1) + `"}`,
+			want:        &webhookRequest{Hash: v1, Actions: []string{"retag", "relabel"}},
+		},
+		{
+			name:        "json with actions",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"hash": "` + v1 + `", "actions": [" Retag "]}`,
+			want:        &webhookRequest{Hash: v1, Actions: []string{"retag"}},
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "hash=" + v1 + "&actions=relabel",
+			want:        &webhookRequest{Hash: v1, Actions: []string{"relabel"}},
+		},
+		{
+			name:   "query parameters",
+			target: "?hash=" + v1 + "&actions=retag,relabel",
+			want:   &webhookRequest{Hash: v1, Actions: []string{"retag", "relabel"}},
+		},
+		{
+			name:   "v2 hash",
+			target: "?hash=" + strings.Repeat("0f", 32),
+			want:   &webhookRequest{Hash: strings.Repeat("0f", 32), Actions: []string{"retag", "relabel"}},
+		},
+		{name: "missing hash", target: "?actions=retag", wantErr: true},
+		{name: "short hash", target: "?hash=" + v1[:39], wantErr: true},
+		{name: "non hex hash", target: "?hash=" + strings.Repeat("zz", 20), wantErr: true},
+		{name: "unsupported action", target: "?hash=" + v1 + "&actions=clean", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/webhook/qbt"+tt.target, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			got, err := parseWebhookRequest(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRunEnv_ConfigHeader(t *testing.T) {
+	prevHeader := flagConfigHeader
+	t.Cleanup(func() { flagConfigHeader = prevHeader })
+
+	t.Setenv(configHeaderEnv, "Authorization: Bearer old")
+	flagConfigHeader = "Authorization: Bearer secret"
+
+	// the header is only passed through the environment, replacing the one tqm was started with
+	assert.NotContains(t, runArgs("clean", "qbt", runRequest{}), flagConfigHeader)
+
+	var headers []string
+	for _, kv := range runEnv() {
+		if strings.HasPrefix(kv, configHeaderEnv+"=") {
+			headers = append(headers, kv)
+		}
+	}
+	assert.Equal(t, []string{configHeaderEnv + "=Authorization: Bearer secret"}, headers)
+}
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/runtime"
+	"github.com/autobrr/tqm/pkg/schema"
+)
+
+const (
+	// maxRuns is the number of runs kept in memory
+	maxRuns = 50
+	// maxRunOutput is the number of bytes of output kept per run
+	maxRunOutput = 64 * 1024
+)
+
+// apiRunCommands are the commands that can be triggered via the API
+var apiRunCommands = []string{"clean", "relabel", "retag", "pause", "resume", "recheck", "reannounce", "move", "orphan"}
+
+type runStatus string
+
+const (
+	runStatusQueued    runStatus = "queued"
+	runStatusRunning   runStatus = "running"
+	runStatusSucceeded runStatus = "succeeded"
+	runStatusFailed    runStatus = "failed"
+)
+
+type apiRun struct {
+	ID         int        `json:"id"`
+	Command    string     `json:"command"`
+	Client     string     `json:"client"`
+	Filter     string     `json:"filter,omitempty"`
+	DryRun     bool       `json:"dry_run"`
+	Status     runStatus  `json:"status"`
+	QueuedAt   time.Time  `json:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ExitCode   *int       `json:"exit_code,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Output     string     `json:"output,omitempty"`
+}
+
+type runRequest struct {
+	DryRun bool   `json:"dry_run"`
+	Filter string `json:"filter"`
+}
+
+// commandRunner runs tqm with args, writing its stdout and stderr to the writers and returning the exit code
+type commandRunner func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (int, error)
+
+// runStore keeps the most recent runs in memory
+type runStore struct {
+	mu     sync.Mutex
+	nextID int
+	runs   []*apiRun
+}
+
+func (rs *runStore) add(run *apiRun) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.nextID++
+	run.ID = rs.nextID
+	rs.runs = append(rs.runs, run)
+	if len(rs.runs) > maxRuns {
+		rs.runs = rs.runs[len(rs.runs)-maxRuns:]
+	}
+}
+
+func (rs *runStore) update(run *apiRun, fn func(run *apiRun)) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	fn(run)
+}
+
+// list returns copies of the runs, newest first
+func (rs *runStore) list() []apiRun {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	runs := make([]apiRun, 0, len(rs.runs))
+	for i := len(rs.runs) - 1; i >= 0; i-- {
+		runs = append(runs, *rs.runs[i])
+	}
+	return runs
+}
+
+func (rs *runStore) get(id int) (apiRun, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, run := range rs.runs {
+		if run.ID == id {
+			return *run, true
+		}
+	}
+	return apiRun{}, false
+}
+
+// tailBuffer keeps the last max bytes written to it, it is written by the stdout and stderr of runs concurrently
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (tb *tailBuffer) Write(p []byte) (int, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.buf = append(tb.buf, p...)
+	if len(tb.buf) > tb.max {
+		tb.buf = tb.buf[len(tb.buf)-tb.max:]
+	}
+	return len(p), nil
+}
+
+func (tb *tailBuffer) String() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	return string(tb.buf)
+}
+
+// execCommandRunner runs the current tqm binary as a child process, so a failing run cannot take down the server
+func execCommandRunner(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return -1, fmt.Errorf("determine executable: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Env = runEnv()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, fmt.Errorf("run: %w", err)
+	}
+
+	return 0, nil
+}
+
+// runEnv returns the environment of the runs, the config header is passed through it instead of the arguments so it
+// is not visible in the process list
+func runEnv() []string {
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, configHeaderEnv+"=")
+	})
+	if flagConfigHeader != "" {
+		env = append(env, configHeaderEnv+"="+flagConfigHeader)
+	}
+	return env
+}
+
+// runArgs builds the arguments for running command (e.g. clean or inventory snapshot) against clientName with the
+// server's global flags
+func runArgs(command string, clientName string, req runRequest) []string {
+	args := append(strings.Fields(command), clientName, "--config", flagConfigFile, "--config-dir", flagConfigFolder,
+		"--log", flagLogFile)
+	if flagLogLevel > 0 {
+		args = append(args, "-"+strings.Repeat("v", flagLogLevel))
+	}
+	if req.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if req.Filter != "" {
+		args = append(args, "--filter", req.Filter)
+	}
+	return args
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"version": runtime.Version,
+	})
+}
+
+func (s *server) handleRun(w http.ResponseWriter, r *http.Request) {
+	command := r.PathValue("command")
+	if !evaluate.StringSliceContains(apiRunCommands, command, false) {
+		http.Error(w, fmt.Sprintf("unsupported command: %q", command), http.StatusBadRequest)
+		return
+	}
+
+	clientName := r.PathValue("client")
+	if _, ok := config.Config.Clients[clientName]; !ok {
+		http.Error(w, fmt.Sprintf("unknown client: %q", clientName), http.StatusNotFound)
+		return
+	}
+
+	req := runRequest{}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("decode body: %v", err), http.StatusBadRequest)
+			return
+		}
+	} else {
+		req.Filter = r.FormValue("filter")
+		if v := r.FormValue("dry_run"); v != "" {
+			dryRun, err := strconv.ParseBool(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid dry_run: %q", v), http.StatusBadRequest)
+				return
+			}
+			req.DryRun = dryRun
+		}
+	}
+
+	queued, _ := s.queueRun(command, clientName, req, nil)
+	writeJSON(w, http.StatusAccepted, queued)
+}
+
+// queueRun queues a run of command against clientName, runs are executed one at a time together with webhooks. With
+// onAction, the run prints its actions as JSON lines which are passed to onAction as they are taken. The returned
+// channel is closed once the run finished.
+func (s *server) queueRun(command string, clientName string, req runRequest, onAction func(schema.Action)) (apiRun, <-chan struct{}) {
+	run := &apiRun{
+		Command:  command,
+		Client:   clientName,
+		Filter:   req.Filter,
+		DryRun:   req.DryRun,
+		Status:   runStatusQueued,
+		QueuedAt: time.Now(),
+	}
+	s.runs.add(run)
+	queued := *run
+
+	s.log.Infof("Queued %s run for client %q (run: %d)", command, clientName, queued.ID)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		// runs are serialized with webhooks, as they operate on the same clients
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.runs.update(run, func(run *apiRun) {
+			now := time.Now()
+			run.Status = runStatusRunning
+			run.StartedAt = &now
+		})
+
+		var (
+			args             = runArgs(command, clientName, req)
+			output           = &tailBuffer{max: maxRunOutput}
+			stdout io.Writer = output
+		)
+		if onAction != nil {
+			args = append(args, "--output", outputJSONLines)
+			stdout = &actionLineWriter{onAction: onAction, other: output}
+		}
+
+		exitCode, err := s.runCommand(s.ctx, args, stdout, output)
+
+		s.runs.update(run, func(run *apiRun) {
+			now := time.Now()
+			run.FinishedAt = &now
+			run.Output = output.String()
+
+			switch {
+			case err != nil:
+				run.Status = runStatusFailed
+				run.Error = err.Error()
+			case exitCode != exitOK && exitCode != exitNothingDone:
+				run.Status = runStatusFailed
+				run.ExitCode = &exitCode
+			default:
+				run.Status = runStatusSucceeded
+				run.ExitCode = &exitCode
+			}
+		})
+
+		switch {
+		case err != nil:
+			s.log.WithError(err).Errorf("Failed %s run for client %q (run: %d)", command, clientName, queued.ID)
+		case exitCode != exitOK && exitCode != exitNothingDone:
+			s.log.Errorf("Failed %s run for client %q (run: %d, exit code: %d)", command, clientName, queued.ID, exitCode)
+		default:
+			s.log.Infof("Finished %s run for client %q (run: %d)", command, clientName, queued.ID)
+		}
+	}()
+
+	return queued, done
+}
+
+// actionLineWriter passes the actions printed as JSON lines by a run to onAction, other lines are written to other
+type actionLineWriter struct {
+	onAction func(schema.Action)
+	other    io.Writer
+	buf      []byte
+}
+
+func (w *actionLineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		line := w.buf[:i+1]
+		var action schema.Action
+		if err := json.Unmarshal(line, &action); err == nil && action.Action != "" {
+			w.onAction(action)
+		} else if _, err := w.other.Write(line); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
+
+func (s *server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	runs := s.runs.list()
+
+	// output is only included when requesting a single run
+	for i := range runs {
+		runs[i].Output = ""
+	}
+
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	var (
+		run apiRun
+		ok  bool
+	)
+
+	if idStr := r.PathValue("id"); idStr == "last" {
+		if runs := s.runs.list(); len(runs) > 0 {
+			run, ok = runs[0], true
+		}
+	} else {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid run id: %q", idStr), http.StatusBadRequest)
+			return
+		}
+		run, ok = s.runs.get(id)
+	}
+
+	if !ok {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, run)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/rpc/tqmv1"
+	"github.com/autobrr/tqm/pkg/runtime"
+	"github.com/autobrr/tqm/pkg/schema"
+)
+
+// grpcService implements the gRPC service on top of the runs of the server, see proto/tqm/v1/tqm.proto
+type grpcService struct {
+	tqmv1.UnimplementedTqmServiceServer
+
+	s *server
+}
+
+// newGRPCServer returns the gRPC server of s, authenticating calls with the api key of s
+func newGRPCServer(s *server, opts ...grpc.ServerOption) *grpc.Server {
+	svc := &grpcService{s: s}
+
+	srv := grpc.NewServer(append(opts,
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (any, error) {
+			if err := svc.authenticate(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			if err := svc.authenticate(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)...)
+	tqmv1.RegisterTqmServiceServer(srv, svc)
+
+	return srv
+}
+
+// serveGRPC serves the gRPC service of s on lis until ctx is cancelled
+func serveGRPC(ctx context.Context, log *logrus.Entry, s *server, lis net.Listener, opts ...grpc.ServerOption) error {
+	srv := newGRPCServer(s, opts...)
+
+	go func() {
+		<-ctx.Done()
+
+		// streaming runs are cut off after the grace period
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(10 * time.Second):
+			log.Warn("Failed shutting down gRPC server gracefully")
+			srv.Stop()
+		}
+	}()
+
+	log.Infof("Listening for gRPC on %s", lis.Addr())
+	return srv.Serve(lis)
+}
+
+// authenticate validates the api key (if configured) sent via the x-api-key metadata, health checks are not
+// authenticated. Runs remove torrents and files, they are refused without an api key
+func (g *grpcService) authenticate(ctx context.Context, method string) error {
+	if method == tqmv1.TqmService_Health_FullMethodName {
+		return nil
+	}
+
+	if g.s.apiKey == "" {
+		if method == tqmv1.TqmService_TriggerRun_FullMethodName {
+			return status.Error(codes.PermissionDenied, "runs require a serve api_key")
+		}
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && validAPIKey(keys[0], g.s.apiKey) {
+		return nil
+	}
+
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+func (g *grpcService) Health(context.Context, *tqmv1.HealthRequest) (*tqmv1.HealthResponse, error) {
+	return &tqmv1.HealthResponse{Version: runtime.Version}, nil
+}
+
+func (g *grpcService) TriggerRun(req *tqmv1.TriggerRunRequest, stream grpc.ServerStreamingServer[tqmv1.TriggerRunResponse]) error {
+	if !evaluate.StringSliceContains(apiRunCommands, req.GetCommand(), false) {
+		return status.Errorf(codes.InvalidArgument, "unsupported command: %q", req.GetCommand())
+	}
+
+	if _, ok := config.Config.Clients[req.GetClient()]; !ok {
+		return status.Errorf(codes.NotFound, "unknown client: %q", req.GetClient())
+	}
+
+	// the run continues when the caller goes away, its actions are then dropped
+	ctx := stream.Context()
+	actions := make(chan schema.Action)
+	queued, done := g.s.queueRun(req.GetCommand(), req.GetClient(), runRequest{DryRun: req.GetDryRun(), Filter: req.GetFilter()},
+		func(a schema.Action) {
+			select {
+			case actions <- a:
+			case <-ctx.Done():
+			}
+		})
+
+	if err := stream.Send(&tqmv1.TriggerRunResponse{Event: &tqmv1.TriggerRunResponse_Run{Run: runToProto(queued)}}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case a := <-actions:
+			if err := stream.Send(&tqmv1.TriggerRunResponse{Event: &tqmv1.TriggerRunResponse_Action{Action: actionToProto(a)}}); err != nil {
+				return err
+			}
+
+		case <-done:
+			run, ok := g.s.runs.get(queued.ID)
+			if !ok {
+				return nil
+			}
+			run.Output = ""
+			return stream.Send(&tqmv1.TriggerRunResponse{Event: &tqmv1.TriggerRunResponse_Run{Run: runToProto(run)}})
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (g *grpcService) ListRuns(context.Context, *tqmv1.ListRunsRequest) (*tqmv1.ListRunsResponse, error) {
+	runs := g.s.runs.list()
+
+	res := &tqmv1.ListRunsResponse{Runs: make([]*tqmv1.Run, 0, len(runs))}
+	for _, run := range runs {
+		// output is only included when requesting a single run
+		run.Output = ""
+		res.Runs = append(res.Runs, runToProto(run))
+	}
+
+	return res, nil
+}
+
+func (g *grpcService) GetRun(_ context.Context, req *tqmv1.GetRunRequest) (*tqmv1.GetRunResponse, error) {
+	var (
+		run apiRun
+		ok  bool
+	)
+
+	if req.GetId() == 0 {
+		if runs := g.s.runs.list(); len(runs) > 0 {
+			run, ok = runs[0], true
+		}
+	} else {
+		run, ok = g.s.runs.get(int(req.GetId()))
+	}
+
+	if !ok {
+		return nil, status.Error(codes.NotFound, "run not found")
+	}
+
+	return &tqmv1.GetRunResponse{Run: runToProto(run)}, nil
+}
+
+func runToProto(run apiRun) *tqmv1.Run {
+	r := &tqmv1.Run{
+		Id:       int64(run.ID),
+		Command:  run.Command,
+		Client:   run.Client,
+		Filter:   run.Filter,
+		DryRun:   run.DryRun,
+		QueuedAt: timestamppb.New(run.QueuedAt),
+		Error:    run.Error,
+		Output:   run.Output,
+	}
+
+	switch run.Status {
+	case runStatusQueued:
+		r.Status = tqmv1.RunStatus_RUN_STATUS_QUEUED
+	case runStatusRunning:
+		r.Status = tqmv1.RunStatus_RUN_STATUS_RUNNING
+	case runStatusSucceeded:
+		r.Status = tqmv1.RunStatus_RUN_STATUS_SUCCEEDED
+	case runStatusFailed:
+		r.Status = tqmv1.RunStatus_RUN_STATUS_FAILED
+	}
+
+	if run.StartedAt != nil {
+		r.StartedAt = timestamppb.New(*run.StartedAt)
+	}
+	if run.FinishedAt != nil {
+		r.FinishedAt = timestamppb.New(*run.FinishedAt)
+	}
+	if run.ExitCode != nil {
+		code := int32(*run.ExitCode)
+		r.ExitCode = &code
+	}
+
+	return r
+}
+
+func actionToProto(a schema.Action) *tqmv1.Action {
+	return &tqmv1.Action{
+		Action:      a.Action,
+		Hash:        a.Hash,
+		Name:        a.Name,
+		Tracker:     a.Tracker,
+		Reason:      a.Reason,
+		Size:        a.Size,
+		Label:       a.Label,
+		Tags:        a.Tags,
+		UploadLimit: a.UploadLimit,
+		Path:        a.Path,
+		ShareLimit:  a.ShareLimit,
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/rpc/tqmv1"
+)
+
+func TestGRPCService(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{Clients: map[string]map[string]any{"qbt": {}}}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	s := &server{
+		log:    logrus.NewEntry(logrus.New()),
+		ctx:    context.Background(),
+		apiKey: "secret",
+		runCommand: func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (int, error) {
+			fmt.Fprintln(stderr, "removing torrents")
+			fmt.Fprintln(stdout, `{"action":"remove","hash":"abc","name":"Some.Torrent","reason":"Ratio > 2"}`)
+			fmt.Fprintln(stdout, `{"action":"remove","hash":"def","name":"Other.Torrent","reason":"Ratio > 2"}`)
+			return exitOK, nil
+		},
+	}
+
+	c := dialGRPC(t, s)
+	ctx := context.Background()
+
+	// health does not require authentication
+	_, err := c.Health(ctx, &tqmv1.HealthRequest{})
+	require.NoError(t, err)
+
+	_, err = c.ListRuns(ctx, &tqmv1.ListRunsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", "secret")
+
+	stream, err := c.TriggerRun(ctx, &tqmv1.TriggerRunRequest{Command: "update", Client: "qbt"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err = c.TriggerRun(ctx, &tqmv1.TriggerRunRequest{Command: "clean", Client: "qbt", DryRun: true})
+	require.NoError(t, err)
+
+	var events []*tqmv1.TriggerRunResponse
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+
+	require.Len(t, events, 4)
+	assert.Equal(t, tqmv1.RunStatus_RUN_STATUS_QUEUED, events[0].GetRun().GetStatus())
+	assert.Equal(t, "abc", events[1].GetAction().GetHash())
+	assert.Equal(t, "Ratio > 2", events[1].GetAction().GetReason())
+	assert.Equal(t, "def", events[2].GetAction().GetHash())
+	assert.Equal(t, tqmv1.RunStatus_RUN_STATUS_SUCCEEDED, events[3].GetRun().GetStatus())
+	assert.Equal(t, int32(exitOK), events[3].GetRun().GetExitCode())
+
+	// the actions are not part of the output of the run
+	res, err := c.GetRun(ctx, &tqmv1.GetRunRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "removing torrents\n", res.GetRun().GetOutput())
+
+	_, err = c.GetRun(ctx, &tqmv1.GetRunRequest{Id: 42})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCService_Authenticate(t *testing.T) {
+	prevConfig := config.Config
+	config.Config = &config.Configuration{Clients: map[string]map[string]any{"qbt": {}}}
+	t.Cleanup(func() { config.Config = prevConfig })
+
+	runCommand := func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) (int, error) {
+		t.Error("run executed without authentication")
+		return exitOK, nil
+	}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		key    string
+		want   codes.Code
+	}{
+		{name: "no api key configured", want: codes.PermissionDenied},
+		{name: "no api key configured with key", key: "secret", want: codes.PermissionDenied},
+		{name: "missing key", apiKey: "secret", want: codes.Unauthenticated},
+		{name: "wrong key", apiKey: "secret", key: "secreT", want: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := dialGRPC(t, &server{log: logrus.NewEntry(logrus.New()), ctx: context.Background(), apiKey: tt.apiKey,
+				runCommand: runCommand})
+
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", tt.key)
+			}
+
+			stream, err := c.TriggerRun(ctx, &tqmv1.TriggerRunRequest{Command: "clean", Client: "qbt"})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "localhost", want: true},
+		{host: "127.0.0.1", want: true},
+		{host: "::1", want: true},
+		{host: "0.0.0.0", want: false},
+		{host: "", want: false},
+		{host: "192.168.1.10", want: false},
+		{host: "tqm.example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, isLoopbackHost(tt.host))
+		})
+	}
+}
+
+// dialGRPC serves the gRPC service of s in memory and returns a client of it
+func dialGRPC(t *testing.T, s *server) tqmv1.TqmServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := newGRPCServer(s)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return tqmv1.NewTqmServiceClient(conn)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/inventory"
+	"github.com/autobrr/tqm/pkg/tqm"
+)
+
+// scheduleInventory takes a snapshot of the inventory of the configured clients every inventory interval until the
+// server is stopped
+func (s *server) scheduleInventory(settings config.InventoryConfig) {
+	if settings.Interval <= 0 {
+		return
+	}
+
+	var clients []string
+	for _, clientName := range inventoryClients(settings) {
+		if _, ok := config.Config.Clients[clientName]; !ok {
+			s.log.Warnf("No client configuration found for inventory client: %q", clientName)
+			continue
+		}
+		clients = append(clients, clientName)
+	}
+	if len(clients) == 0 {
+		s.log.Warn("No clients with a download_path to take inventory snapshots of")
+		return
+	}
+
+	s.log.Infof("Taking inventory snapshots of %d client(s) every %s", len(clients), formatting.Duration(settings.Interval))
+	for _, clientName := range clients {
+		go s.snapshotInventory(clientName, settings.Interval)
+	}
+}
+
+// snapshotInventory queues a snapshot of clientName once interval passed since its newest snapshot (or the last
+// attempt, so failing snapshots are not retried immediately)
+func (s *server) snapshotInventory(clientName string, interval time.Duration) {
+	var attempted time.Time
+	for {
+		latest, err := inventory.Latest(inventory.Dir(clientName))
+		if err != nil {
+			s.log.WithError(err).Warnf("Failed listing inventory snapshots of client %q", clientName)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(time.Until(later(latest, attempted).Add(interval))):
+		}
+
+		attempted = time.Now()
+		_, done := s.queueRun("inventory snapshot", clientName, runRequest{}, nil)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-done:
+		}
+	}
+}
+
+// inventoryClients returns the clients to take inventory snapshots of, by default the enabled clients with a
+// download_path
+func inventoryClients(settings config.InventoryConfig) []string {
+	if len(settings.Clients) > 0 {
+		return settings.Clients
+	}
+
+	var clients []string
+	for _, name := range slices.Sorted(maps.Keys(config.Config.Clients)) {
+		clientConfig := config.Config.Clients[name]
+		if tqm.ValidateClientEnabled(clientConfig) != nil {
+			continue
+		}
+		if downloadPath, _ := tqm.ClientString("download_path", clientConfig); downloadPath != nil && *downloadPath != "" {
+			clients = append(clients, name)
+		}
+	}
+	return clients
+}
+
+func later(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+const defaultServiceName = "tqm"
+
+var (
+	flagServiceName     string
+	flagServiceUser     string
+	flagServiceSchedule string
+	flagServiceUnitDir  string
+	flagServicePrint    bool
+)
+
+// serviceOptions describe the service to install
+type serviceOptions struct {
+	name string
+	// user runs the service, root when empty (systemd only)
+	user string
+	// schedule is a systemd calendar expression, the command is run by a timer when set (systemd only)
+	schedule string
+	// command is the tqm command run by the service
+	command []string
+	// executable and args are the command line of the service, args include the config flags and the command
+	executable string
+	args       []string
+	// env holds the environment variables of the service, e.g. the config header kept out of its command line
+	env []string
+}
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Manage tqm as a system service",
+	Long: `This command installs tqm as a Windows service or a systemd unit, running the serve command (or the command given
+after --) in the background. With --schedule, a systemd timer runs the command periodically instead.`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install [-- COMMAND ARGS...]",
+	Short: "Install and start the service",
+	Example: `  tqm service install
+  tqm service install --name tqm-clean --schedule daily -- run qbt --steps clean,orphan`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		initLogging()
+		log := logger.GetLogger("service")
+
+		opts, err := newServiceOptions(args)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid service")
+		}
+
+		if flagServicePrint {
+			units := systemdUnits(opts)
+			for _, file := range slices.Sorted(maps.Keys(units)) {
+				fmt.Printf("# %s\n%s\n", file, units[file])
+			}
+			return
+		}
+
+		if err := installService(log, opts); err != nil {
+			log.WithError(err).Fatalf("Failed installing service: %q", opts.name)
+		}
+		log.Infof("Installed and started service: %q", opts.name)
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the service",
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		initLogging()
+		log := logger.GetLogger("service")
+
+		if err := uninstallService(log, flagServiceName); err != nil {
+			log.WithError(err).Fatalf("Failed uninstalling service: %q", flagServiceName)
+		}
+		log.Infof("Uninstalled service: %q", flagServiceName)
+	},
+}
+
+var serviceRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the serve command as a service",
+	Long: `This command is run by the service manager. On Windows it reports to the service control manager and stops when the
+service is stopped, elsewhere it behaves like the serve command and stops on SIGINT or SIGTERM.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		log := logger.GetLogger("serve")
+		addr, grpcAddr := serveAddress(cmd), serveGRPCAddress(cmd)
+
+		if err := runService(flagServiceName, func(ctx context.Context) error {
+			return runServer(ctx, log, addr, grpcAddr)
+		}); err != nil {
+			log.WithError(err).Fatal("Failed running service")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceInstallCmd, serviceUninstallCmd, serviceRunCmd)
+
+	serviceCmd.PersistentFlags().StringVar(&flagServiceName, "name", defaultServiceName, "Service name")
+	serviceCmd.PersistentFlags().StringVar(&flagServiceUnitDir, "unit-dir", "/etc/systemd/system", "Directory the systemd units are written to")
+
+	serviceInstallCmd.Flags().StringVar(&flagServiceUser, "user", "", "User running the service (systemd only, default: the user running sudo, otherwise root)")
+	serviceInstallCmd.Flags().StringVar(&flagServiceSchedule, "schedule", "", "Run the command periodically with a systemd timer, e.g. hourly, daily or *-*-* 04:00:00 (systemd only)")
+	serviceInstallCmd.Flags().BoolVar(&flagServicePrint, "print", false, "Print the systemd units instead of installing them")
+
+	serviceRunCmd.Flags().StringVar(&flagServeHost, "host", defaultServeHost, "Host to listen on")
+	serviceRunCmd.Flags().IntVar(&flagServePort, "port", defaultServePort, "Port to listen on")
+}
+
+// newServiceOptions builds the command line of the service, running the serve command unless args are given
+func newServiceOptions(args []string) (serviceOptions, error) {
+	opts := serviceOptions{
+		name:     flagServiceName,
+		user:     flagServiceUser,
+		schedule: flagServiceSchedule,
+	}
+
+	if opts.name == "" || strings.ContainsAny(opts.name, `/\ `) {
+		return opts, fmt.Errorf("invalid service name: %q", opts.name)
+	}
+
+	if opts.schedule != "" && len(args) == 0 {
+		return opts, fmt.Errorf("a scheduled service requires the command to run, e.g. -- clean qbt")
+	}
+
+	if opts.user == "" {
+		opts.user = os.Getenv("SUDO_USER")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return opts, fmt.Errorf("locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	opts.executable = executable
+
+	// the service does not run from the current directory, so the config is passed with absolute paths
+	configDir, err := filepath.Abs(flagConfigFolder)
+	if err != nil {
+		return opts, fmt.Errorf("resolve config folder: %w", err)
+	}
+
+	configFile := resolveConfigFile()
+	if !config.IsRemote(configFile) {
+		if configFile, err = filepath.Abs(configFile); err != nil {
+			return opts, fmt.Errorf("resolve config file: %w", err)
+		}
+	}
+
+	opts.args = []string{"--config-dir", configDir, "--config", configFile}
+	if flagConfigHeader != "" {
+		opts.env = append(opts.env, configHeaderEnv+"="+flagConfigHeader)
+	}
+	if rootCmd.PersistentFlags().Changed("log") {
+		logFile, err := filepath.Abs(flagLogFile)
+		if err != nil {
+			return opts, fmt.Errorf("resolve log file: %w", err)
+		}
+		opts.args = append(opts.args, "--log", logFile)
+	}
+
+	opts.command = args
+	if len(opts.command) == 0 {
+		opts.command = []string{"service", "run", "--name", opts.name}
+	}
+	opts.args = append(opts.args, opts.command...)
+
+	return opts, nil
+}
+
+// systemdUnits returns the content of the systemd units of the service by file name
+func systemdUnits(opts serviceOptions) map[string]string {
+	var b strings.Builder
+
+	description := "tqm"
+	if opts.schedule != "" {
+		description = fmt.Sprintf("tqm %s", strings.Join(opts.command, " "))
+	}
+
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\nAfter=network-online.target\nWants=network-online.target\n\n", description)
+	b.WriteString("[Service]\n")
+	if opts.schedule != "" {
+		// runs performing no action are not failures
+		fmt.Fprintf(&b, "Type=oneshot\nSuccessExitStatus=%d\n", exitNothingDone)
+	} else {
+		b.WriteString("Type=simple\n")
+	}
+	if opts.user != "" {
+		fmt.Fprintf(&b, "User=%s\n", opts.user)
+	}
+	if len(opts.env) > 0 {
+		fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdQuote(filepath.Join(flagServiceUnitDir, serviceEnvFile(opts.name))))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdCommandLine(append([]string{opts.executable}, opts.args...)))
+	if opts.schedule == "" {
+		b.WriteString("Restart=on-failure\nRestartSec=10\n\n[Install]\nWantedBy=multi-user.target\n")
+	}
+
+	units := map[string]string{opts.name + ".service": b.String()}
+
+	// the environment may hold secrets (e.g. the config header), so it is kept out of the world-readable units
+	if len(opts.env) > 0 {
+		var env strings.Builder
+		for _, kv := range opts.env {
+			name, value, _ := strings.Cut(kv, "=")
+			fmt.Fprintf(&env, "%s=\"%s\"\n", name, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+		}
+		units[serviceEnvFile(opts.name)] = env.String()
+	}
+
+	if opts.schedule != "" {
+		units[opts.name+".timer"] = fmt.Sprintf("[Unit]\nDescription=Run %s on a schedule\n\n[Timer]\nOnCalendar=%s\nPersistent=true\n\n"+
+			"[Install]\nWantedBy=timers.target\n", description, opts.schedule)
+	}
+
+	return units
+}
+
+// serviceEnvFile returns the file name of the environment of the service, written next to its units
+func serviceEnvFile(name string) string {
+	return name + ".env"
+}
+
+// systemdCommandLine quotes the arguments and escapes the variables as expected by systemd
+func systemdCommandLine(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, systemdQuote(strings.ReplaceAll(arg, "$", "$$")))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// systemdQuote quotes s when it contains whitespace or quotes and escapes its specifiers as expected by systemd
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s == "" || strings.ContainsAny(s, " \t\"'\\") {
+		s = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	return s
+}
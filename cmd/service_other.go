@@ -0,0 +1,107 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// installService writes the systemd units of the service and enables them
+func installService(log *logrus.Entry, opts serviceOptions) error {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return errors.New("systemctl not found, use --print to write the service definition manually")
+	}
+
+	for file, content := range systemdUnits(opts) {
+		path := filepath.Join(flagServiceUnitDir, file)
+		if file == serviceEnvFile(opts.name) {
+			// only readable by root, systemd reads it before switching to the user of the service
+			if err := paths.WriteFileAtomic(path, []byte(content)); err != nil {
+				return fmt.Errorf("write environment: %w", err)
+			}
+			log.Infof("Wrote service environment: %q", path)
+			continue
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("write unit: %w", err)
+		}
+		log.Infof("Wrote systemd unit: %q", path)
+	}
+
+	// the timer starts a scheduled service, the service itself is not enabled
+	unit := opts.name + ".service"
+	if opts.schedule != "" {
+		unit = opts.name + ".timer"
+	}
+
+	if err := systemctl(log, "daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl(log, "enable", "--now", unit)
+}
+
+// uninstallService disables the systemd units of the service and removes them
+func uninstallService(log *logrus.Entry, name string) error {
+	var removed int
+	for _, file := range []string{name + ".timer", name + ".service"} {
+		path := filepath.Join(flagServiceUnitDir, file)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err := systemctl(log, "disable", "--now", file); err != nil {
+			return err
+		}
+
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove unit: %w", err)
+		}
+		log.Infof("Removed systemd unit: %q", path)
+		removed++
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("no systemd units found in %q", flagServiceUnitDir)
+	}
+
+	envPath := filepath.Join(flagServiceUnitDir, serviceEnvFile(name))
+	if err := os.Remove(envPath); err == nil {
+		log.Infof("Removed service environment: %q", envPath)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove environment: %w", err)
+	}
+
+	return systemctl(log, "daemon-reload")
+}
+
+// runService runs serve until SIGINT or SIGTERM is received, systemd manages the process itself
+func runService(_ string, serve func(ctx context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return serve(ctx)
+}
+
+func systemctl(log *logrus.Entry, args ...string) error {
+	log.Debugf("Running: systemctl %s", strings.Join(args, " "))
+
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
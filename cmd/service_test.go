@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnits(t *testing.T) {
+	args := []string{"--config-dir", "/home/user/.config/tqm", "--config", "/home/user/.config/tqm/config.yaml"}
+
+	t.Run("serve", func(t *testing.T) {
+		opts := serviceOptions{
+			name:       "tqm",
+			user:       "user",
+			command:    []string{"service", "run", "--name", "tqm"},
+			executable: "/usr/local/bin/tqm",
+		}
+		opts.args = append(append([]string{}, args...), opts.command...)
+
+		units := systemdUnits(opts)
+		assert.Len(t, units, 1)
+		assert.Equal(t, `[Unit]
+Description=tqm
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+User=user
+ExecStart=/usr/local/bin/tqm --config-dir /home/user/.config/tqm --config /home/user/.config/tqm/config.yaml service run --name tqm
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`, units["tqm.service"])
+	})
+
+	t.Run("config_header", func(t *testing.T) {
+		opts := serviceOptions{
+			name:       "tqm",
+			command:    []string{"service", "run", "--name", "tqm"},
+			executable: "/usr/local/bin/tqm",
+			env:        []string{"TQM_CONFIG_HEADER=Authorization: Bearer 100%$ecret"},
+		}
+		opts.args = append(append([]string{}, args...), opts.command...)
+
+		// the header is kept out of the command line and the world-readable unit
+		units := systemdUnits(opts)
+		assert.Len(t, units, 2)
+		assert.Contains(t, units["tqm.service"], "\nEnvironmentFile=/etc/systemd/system/tqm.env\nExecStart=")
+		assert.NotContains(t, units["tqm.service"], "config-header")
+		assert.NotContains(t, units["tqm.service"], "ecret")
+		assert.Equal(t, "TQM_CONFIG_HEADER=\"Authorization: Bearer 100%$ecret\"\n", units["tqm.env"])
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		opts := serviceOptions{
+			name:       "tqm-clean",
+			schedule:   "*-*-* 04:00:00",
+			command:    []string{"run", "qbt", "--steps", "clean,orphan"},
+			executable: "/usr/local/bin/tqm",
+		}
+		opts.args = append(append([]string{}, args...), opts.command...)
+
+		units := systemdUnits(opts)
+		assert.Len(t, units, 2)
+		assert.NotContains(t, units["tqm-clean.service"], "EnvironmentFile=")
+		assert.Contains(t, units["tqm-clean.service"], "Type=oneshot\nSuccessExitStatus=3\n")
+		assert.NotContains(t, units["tqm-clean.service"], "[Install]")
+		assert.NotContains(t, units["tqm-clean.service"], "User=")
+		assert.Equal(t, `[Unit]
+Description=Run tqm run qbt --steps clean,orphan on a schedule
+
+[Timer]
+OnCalendar=*-*-* 04:00:00
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, units["tqm-clean.timer"])
+	})
+}
+
+func TestSystemdCommandLine(t *testing.T) {
+	assert.Equal(t, `/opt/tqm/tqm --config "/mnt/my configs/config.yaml" clean qbt`,
+		systemdCommandLine([]string{"/opt/tqm/tqm", "--config", "/mnt/my configs/config.yaml", "clean", "qbt"}))
+	assert.Equal(t, `tqm --filter "" --log /var/log/tqm-%%i.log "say \"hi\""`,
+		systemdCommandLine([]string{"tqm", "--filter", "", "--log", "/var/log/tqm-%i.log", `say "hi"`}))
+	assert.Equal(t, `tqm --config /home/$$USER/config.yaml "$${HOME}/a b"`,
+		systemdCommandLine([]string{"tqm", "--config", "/home/$USER/config.yaml", "${HOME}/a b"}))
+}
+
+func TestNewServiceOptions_ConfigHeader(t *testing.T) {
+	prevHeader := flagConfigHeader
+	t.Cleanup(func() { flagConfigHeader = prevHeader })
+
+	flagConfigHeader = "Authorization: Bearer secret"
+
+	opts, err := newServiceOptions([]string{"clean", "qbt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TQM_CONFIG_HEADER=Authorization: Bearer secret"}, opts.env)
+	assert.NotContains(t, opts.args, flagConfigHeader)
+}
@@ -0,0 +1,160 @@
+//go:build windows
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// installService registers the service with the service control manager and starts it
+func installService(log *logrus.Entry, opts serviceOptions) error {
+	if opts.schedule != "" {
+		return errors.New("scheduled services are only supported with systemd, use the Task Scheduler instead")
+	}
+	if len(opts.command) < 2 || opts.command[0] != "service" || opts.command[1] != "run" {
+		return errors.New("only the serve command can be installed as a Windows service")
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(opts.name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %q already exists", opts.name)
+	}
+
+	s, err := m.CreateService(opts.name, opts.executable, mgr.Config{
+		DisplayName: opts.name,
+		Description: "tqm webhook and API server",
+		StartType:   mgr.StartAutomatic,
+	}, opts.args...)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+	log.Infof("Created Windows service: %q", opts.name)
+
+	if len(opts.env) > 0 {
+		if err := setServiceEnvironment(opts.name, opts.env); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+
+	return nil
+}
+
+// setServiceEnvironment sets the environment variables of the service, which are kept in its registry key
+func setServiceEnvironment(name string, env []string) error {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+name, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("open service registry key: %w", err)
+	}
+	defer k.Close()
+
+	if err := k.SetStringsValue("Environment", env); err != nil {
+		return fmt.Errorf("set service environment: %w", err)
+	}
+	return nil
+}
+
+// uninstallService stops the service and removes it from the service control manager
+func uninstallService(log *logrus.Entry, name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("open service: %w", err)
+	}
+	defer s.Close()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		// wait for the service to stop, so its executable can be replaced right away
+		for deadline := time.Now().Add(30 * time.Second); status.State != svc.Stopped && time.Now().Before(deadline); {
+			time.Sleep(500 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+		log.Infof("Stopped Windows service: %q", name)
+	}
+
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+
+	return nil
+}
+
+// runService runs serve under the service control manager until the service is stopped, or in the foreground when
+// not started as a service
+func runService(name string, serve func(ctx context.Context) error) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return fmt.Errorf("determine whether running as a service: %w", err)
+	}
+
+	if !isService {
+		return serve(context.Background())
+	}
+
+	return svc.Run(name, &windowsService{serve: serve})
+}
+
+type windowsService struct {
+	serve func(ctx context.Context) error
+}
+
+func (s *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serve(ctx)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				log.WithError(err).Error("Service stopped unexpectedly")
+				return true, 1
+			}
+			return false, 0
+
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				changes <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				<-done
+				return false, 0
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [CLIENT]",
+	Short: "Print a summary of the torrent client's queue",
+	Long: `This command prints torrents, total size, average ratio, seeding time and unregistered/tracker down counts of a torrent client's queue,
+grouped by tracker and category.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("stats")
+
+		// load client object
+		clientName := args[0]
+		c, _, _, err := loadClient(ctx, clientName, "")
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		stats := collectStats(ctx, torrents)
+
+		if flagOutput == outputJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(stats); err != nil {
+				log.WithError(err).Fatal("Failed encoding stats")
+			}
+			return
+		}
+
+		if err := writeStatsTable(os.Stdout, stats); err != nil {
+			log.WithError(err).Fatal("Failed writing stats")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+}
+
+type statsGroup struct {
+	Name           string  `json:"name"`
+	Torrents       int     `json:"torrents"`
+	Size           int64   `json:"size"`
+	Ratio          float32 `json:"ratio"`
+	SeedingSeconds int64   `json:"seeding_seconds"`
+	Unregistered   int     `json:"unregistered"`
+	TrackerDown    int     `json:"tracker_down"`
+
+	ratioSum float32
+}
+
+type queueStats struct {
+	Total      *statsGroup   `json:"total"`
+	Trackers   []*statsGroup `json:"trackers"`
+	Categories []*statsGroup `json:"categories"`
+}
+
+// collectStats summarizes torrents grouped by tracker and category, groups are sorted by size
+func collectStats(ctx context.Context, torrents map[string]config.Torrent) *queueStats {
+	total := &statsGroup{Name: "all"}
+	trackers := make(map[string]*statsGroup)
+	categories := make(map[string]*statsGroup)
+
+	group := func(groups map[string]*statsGroup, name string) *statsGroup {
+		if name == "" {
+			name = "(none)"
+		}
+
+		g, ok := groups[name]
+		if !ok {
+			g = &statsGroup{Name: name}
+			groups[name] = g
+		}
+		return g
+	}
+
+	for _, t := range torrents {
+		unregistered := t.IsUnregistered(ctx)
+		trackerDown := t.IsTrackerDown()
+
+		for _, g := range []*statsGroup{total, group(trackers, t.TrackerName), group(categories, t.Label)} {
+			g.Torrents++
+			g.Size += t.TotalBytes
+			g.ratioSum += t.Ratio
+			g.SeedingSeconds += t.SeedingSeconds
+			if unregistered {
+				g.Unregistered++
+			}
+			if trackerDown {
+				g.TrackerDown++
+			}
+		}
+	}
+
+	sorted := func(groups map[string]*statsGroup) []*statsGroup {
+		s := make([]*statsGroup, 0, len(groups))
+		for _, g := range groups {
+			g.Ratio = g.ratioSum / float32(g.Torrents)
+			s = append(s, g)
+		}
+
+		sort.Slice(s, func(i, j int) bool {
+			if s[i].Size != s[j].Size {
+				return s[i].Size > s[j].Size
+			}
+			return s[i].Name < s[j].Name
+		})
+		return s
+	}
+
+	if total.Torrents > 0 {
+		total.Ratio = total.ratioSum / float32(total.Torrents)
+	}
+
+	return &queueStats{
+		Total:      total,
+		Trackers:   sorted(trackers),
+		Categories: sorted(categories),
+	}
+}
+
+func writeStatsTable(w io.Writer, stats *queueStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	section := func(title string, groups []*statsGroup) {
+		fmt.Fprintf(tw, "%s\tTORRENTS\tSIZE\tAVG RATIO\tAVG SEED DAYS\tUNREGISTERED\tTRACKER DOWN\t\n", title)
+		for _, g := range groups {
+			avgSeedDays := float64(g.SeedingSeconds) / float64(g.Torrents) / 60 / 60 / 24
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%.2f\t%.1f\t%d\t%d\t\n", g.Name, g.Torrents,
+				formatting.Bytes(uint64(g.Size)), g.Ratio, avgSeedDays, g.Unregistered, g.TrackerDown)
+		}
+		fmt.Fprintln(tw, "\t\t\t\t\t\t\t")
+	}
+
+	section("TRACKER", stats.Trackers)
+	section("CATEGORY", stats.Categories)
+	if stats.Total.Torrents > 0 {
+		section("TOTAL", []*statsGroup{stats.Total})
+	}
+
+	return tw.Flush()
+}
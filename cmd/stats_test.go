@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestCollectStats(t *testing.T) {
+	config.InitializeTrackerStatuses(nil)
+
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", TrackerName: "tracker1.com", Label: "movies", TotalBytes: 100, Ratio: 1, SeedingSeconds: 10},
+		"b": {Hash: "b", TrackerName: "tracker1.com", Label: "tv", TotalBytes: 300, Ratio: 3, SeedingSeconds: 30,
+			TrackerStatus: "unregistered torrent"},
+		"c": {Hash: "c", TrackerName: "tracker2.com", TotalBytes: 50, Ratio: 2, SeedingSeconds: 20,
+			TrackerStatus: "tracker is down"},
+	}
+
+	stats := collectStats(context.Background(), torrents)
+
+	assert.Equal(t, 3, stats.Total.Torrents)
+	assert.Equal(t, int64(450), stats.Total.Size)
+	assert.InDelta(t, 2.0, stats.Total.Ratio, 0.001)
+	assert.Equal(t, 1, stats.Total.Unregistered)
+	assert.Equal(t, 1, stats.Total.TrackerDown)
+
+	require.Len(t, stats.Trackers, 2)
+	assert.Equal(t, "tracker1.com", stats.Trackers[0].Name)
+	assert.Equal(t, 2, stats.Trackers[0].Torrents)
+	assert.Equal(t, int64(40), stats.Trackers[0].SeedingSeconds)
+	assert.Equal(t, 1, stats.Trackers[0].Unregistered)
+	assert.Equal(t, "tracker2.com", stats.Trackers[1].Name)
+	assert.Equal(t, 1, stats.Trackers[1].TrackerDown)
+
+	require.Len(t, stats.Categories, 3)
+	assert.Equal(t, []string{"tv", "movies", "(none)"},
+		[]string{stats.Categories[0].Name, stats.Categories[1].Name, stats.Categories[2].Name})
+}
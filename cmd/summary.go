@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/notification"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// summaryStateFile keeps when the summary was last notified for every client
+const summaryStateFile = "summary.state.json"
+
+// ageBucket summarizes the torrents added within an age range
+type ageBucket struct {
+	Name         string
+	Torrents     int
+	Size         int64
+	LowRatio     int
+	LowRatioSize int64
+}
+
+// collectAgeBuckets groups torrents by the days since they were added, ageDays are the boundaries of the buckets.
+// Torrents with a ratio below ratio are counted as low ratio.
+func collectAgeBuckets(torrents map[string]config.Torrent, ageDays []int, ratio float64) []ageBucket {
+	bounds := slices.Compact(slices.Sorted(slices.Values(ageDays)))
+
+	buckets := make([]ageBucket, len(bounds)+1)
+	for i := range buckets {
+		switch {
+		case i == 0:
+			buckets[i].Name = fmt.Sprintf("<%dd", bounds[0])
+		case i == len(bounds):
+			buckets[i].Name = fmt.Sprintf("%dd+", bounds[i-1])
+		default:
+			buckets[i].Name = fmt.Sprintf("%d-%dd", bounds[i-1], bounds[i])
+		}
+	}
+
+	for _, t := range torrents {
+		i := sort.Search(len(bounds), func(j int) bool {
+			return float64(t.AddedDays) < float64(bounds[j])
+		})
+
+		buckets[i].Torrents++
+		buckets[i].Size += t.TotalBytes
+		if float64(t.Ratio) < ratio {
+			buckets[i].LowRatio++
+			buckets[i].LowRatioSize += t.TotalBytes
+		}
+	}
+
+	return buckets
+}
+
+func (b ageBucket) String(ratio float64) string {
+	return fmt.Sprintf("%d torrent(s) (%s), %d with ratio below %.2f (%s)", b.Torrents, formatting.Bytes(uint64(b.Size)),
+		b.LowRatio, ratio, formatting.Bytes(uint64(b.LowRatioSize)))
+}
+
+// summarizeLibrary logs the torrents of the client by age and ratio, even when no action was taken, and sends the
+// summary as a notification once per notify interval
+func summarizeLibrary(log *logrus.Entry, noti notification.Sender, client string, torrents map[string]config.Torrent,
+	start time.Time) {
+	settings := config.Config.Summary.WithDefaults()
+	buckets := collectAgeBuckets(torrents, settings.AgeDays, settings.Ratio)
+
+	log.Info("-----")
+	log.Infof("Library summary by age: %d torrent(s)", len(torrents))
+	for _, b := range buckets {
+		log.Infof("Age %s: %s", b.Name, b.String(settings.Ratio))
+	}
+
+	if settings.NotifyInterval <= 0 || flagDryRun || !noti.CanSend() {
+		return
+	}
+
+	statePath := config.StatePath(summaryStateFile)
+	state, err := loadSummaryState(statePath)
+	if err != nil {
+		log.WithError(err).Warn("Failed loading summary state")
+		state = map[string]time.Time{}
+	}
+
+	if last, ok := state[client]; ok && time.Since(last) < settings.NotifyInterval {
+		log.Debugf("Summary notified %s ago, skipping notification...", formatting.Duration(time.Since(last)))
+		return
+	}
+
+	var size int64
+	fields := make([]notification.Field, 0, len(buckets))
+	for _, b := range buckets {
+		size += b.Size
+		fields = append(fields, notification.Field{Name: "Age " + b.Name, Value: b.String(settings.Ratio)})
+	}
+
+	if err := noti.Send("Library Summary", fmt.Sprintf("**%d** torrent(s) | Total **%s**", len(torrents),
+		formatting.Bytes(uint64(size))), client, time.Since(start), fields, false); err != nil {
+		log.WithError(err).Error("Failed sending summary notification")
+		return
+	}
+
+	state[client] = time.Now()
+	if err := saveSummaryState(statePath, state); err != nil {
+		log.WithError(err).Warn("Failed saving summary state")
+	}
+}
+
+// loadSummaryState returns when the summary was last notified by client
+func loadSummaryState(path string) (map[string]time.Time, error) {
+	state := map[string]time.Time{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read summary state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode summary state: %w", err)
+	}
+	return state, nil
+}
+
+func saveSummaryState(path string, state map[string]time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode summary state: %w", err)
+	}
+
+	if err := paths.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("write summary state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestCollectAgeBuckets(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", AddedDays: 1, Ratio: 0.5, TotalBytes: 10},
+		"b": {Hash: "b", AddedDays: 30, Ratio: 2, TotalBytes: 20},
+		"c": {Hash: "c", AddedDays: 200, Ratio: 0.1, TotalBytes: 40},
+		"d": {Hash: "d", AddedDays: 365, Ratio: 1, TotalBytes: 80},
+	}
+
+	buckets := collectAgeBuckets(torrents, []int{180, 30, 90, 30}, 1.0)
+
+	assert.Equal(t, []ageBucket{
+		{Name: "<30d", Torrents: 1, Size: 10, LowRatio: 1, LowRatioSize: 10},
+		{Name: "30-90d", Torrents: 1, Size: 20},
+		{Name: "90-180d"},
+		{Name: "180d+", Torrents: 2, Size: 120, LowRatio: 1, LowRatioSize: 40},
+	}, buckets)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/history"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/notification"
+)
+
+var flagTrackerTagPrefix string
+
+var tagFromTrackerCmd = &cobra.Command{
+	Use:   "tag-from-tracker [CLIENT]",
+	Short: "Tag torrents with the name of their tracker (only qbit)",
+	Long: `This command tags every torrent with its tracker name, optionally prefixed with --prefix (e.g. t:), independent of the
+tag rules of the filter. Tracker tags of torrents whose tracker changed are replaced, so the tags stay in sync.`,
+	Example: `  tqm tag-from-tracker qbt --prefix t:`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		startTime := time.Now()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("tag-from-tracker")
+		runOutcome.track()
+
+		if strings.Contains(flagTrackerTagPrefix, ",") {
+			log.Fatalf("Invalid tag prefix: %q (tags cannot contain commas)", flagTrackerTagPrefix)
+		}
+
+		noti := newNotificationSender(log)
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, _, _, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		if !supports(log, c, c.Capabilities().Tags, "Tagging") {
+			return
+		}
+		ct := c.(client.TagInterface)
+
+		log.Infof("Initialized client %q, type: %s", clientName, ct.Type())
+
+		// retrieve torrents, all of them are needed to know the tracker tags in use
+		torrents, err := ct.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		known := trackerTags(torrents, flagTrackerTagPrefix)
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		var (
+			tagged, failed int
+			fields         []notification.Field
+		)
+
+		for _, t := range torrents {
+			add, remove := trackerTagChanges(t, flagTrackerTagPrefix, known)
+			if len(add) == 0 && len(remove) == 0 {
+				log.Tracef("Tracker tag up to date: %q", t.Name)
+				continue
+			}
+
+			detail := trackerTagDetail(add, remove)
+
+			log.Info("-----")
+			log.Infof("Tracker tags for: %q - %s", t.Name, detail)
+			log.Infof("Tags: %s / Tracker: %s", strings.Join(t.TagsSlice(), ", "), t.TrackerName)
+
+			if flagDryRun {
+				log.Warn("Dry-run enabled, skipping tagging...")
+			} else {
+				if len(add) > 0 {
+					if err := ct.AddTags(ctx, t.Hash, add); err != nil {
+						log.WithError(err).Errorf("Failed adding tags %v to torrent: %q", add, t.Name)
+						failed++
+						continue
+					}
+				}
+
+				if len(remove) > 0 {
+					if err := ct.RemoveTags(ctx, t.Hash, remove); err != nil {
+						log.WithError(err).Errorf("Failed removing tags %v from torrent: %q", remove, t.Name)
+						failed++
+						continue
+					}
+				}
+
+				log.Info("Tagged")
+				history.Record(history.Entry{Client: clientName, Action: history.ActionRetag, Hash: t.Hash, Name: t.Name,
+					Tracker: t.TrackerName, Detail: detail})
+			}
+
+			newTags := t.TagsSlice()
+			newTags = slices.DeleteFunc(newTags, func(tag string) bool { return slices.Contains(remove, tag) })
+			newTags = append(newTags, add...)
+			slices.Sort(newTags)
+
+			fields = append(fields, noti.BuildField(notification.ActionRetag, notification.BuildOptions{
+				Torrent:    t,
+				NewTags:    newTags,
+				NewUpLimit: t.UpLimit,
+			}))
+			tagged++
+		}
+
+		log.Info("-----")
+		log.Infof("Tagged torrents: %d, %d failures", tagged, failed)
+		runOutcome.record(tagged, failed)
+
+		if !noti.CanSend() {
+			log.Debug("Notifications disabled, skipping...")
+			return
+		}
+
+		sendErr := noti.Send(
+			"Torrent Tracker Tags",
+			fmt.Sprintf("Tagged **%d** torrent(s) with their tracker", tagged),
+			clientName,
+			time.Since(startTime),
+			fields,
+			flagDryRun,
+		)
+		if sendErr != nil {
+			log.WithError(sendErr).Error("Failed sending notification")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagFromTrackerCmd)
+
+	tagFromTrackerCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	tagFromTrackerCmd.Flags().StringVar(&flagTrackerTagPrefix, "prefix", "", "Prefix of the tracker tags, e.g. t:")
+	tagFromTrackerCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	tagFromTrackerCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// trackerTag returns the tracker tag of a torrent, empty when its tracker is unknown
+func trackerTag(prefix string, t config.Torrent) string {
+	name := strings.TrimSpace(strings.ReplaceAll(t.TrackerName, ",", ""))
+	if name == "" {
+		return ""
+	}
+	return prefix + name
+}
+
+// trackerTags returns the tracker tags of torrents, which are replaced when the tracker of a torrent changes
+func trackerTags(torrents map[string]config.Torrent, prefix string) map[string]struct{} {
+	tags := make(map[string]struct{})
+	for _, t := range torrents {
+		if tag := trackerTag(prefix, t); tag != "" {
+			tags[tag] = struct{}{}
+		}
+	}
+	return tags
+}
+
+// trackerTagChanges returns the tags to add to and remove from t to only carry the tag of its current tracker.
+// Tags starting with a non-empty prefix, or else the tags of the trackers in known, are considered tracker tags.
+func trackerTagChanges(t config.Torrent, prefix string, known map[string]struct{}) (add []string, remove []string) {
+	want := trackerTag(prefix, t)
+
+	for _, tag := range t.TagsSlice() {
+		if tag == want {
+			continue
+		}
+
+		_, isTrackerTag := known[tag]
+		if isTrackerTag || (prefix != "" && strings.HasPrefix(tag, prefix)) {
+			remove = append(remove, tag)
+		}
+	}
+
+	if _, ok := t.Tags[want]; want != "" && !ok {
+		add = append(add, want)
+	}
+
+	return add, remove
+}
+
+// trackerTagDetail describes the tag changes of a torrent
+func trackerTagDetail(add []string, remove []string) string {
+	var parts []string
+	if len(add) > 0 {
+		parts = append(parts, fmt.Sprintf("adding: [%s]", strings.Join(add, ", ")))
+	}
+	if len(remove) > 0 {
+		parts = append(parts, fmt.Sprintf("removing: [%s]", strings.Join(remove, ", ")))
+	}
+	return strings.Join(parts, " | ")
+}
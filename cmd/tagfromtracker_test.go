@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func tagSet(tags ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		set[tag] = struct{}{}
+	}
+	return set
+}
+
+func TestTrackerTagChanges(t *testing.T) {
+	tests := []struct {
+		name           string
+		torrent        config.Torrent
+		prefix         string
+		known          map[string]struct{}
+		expectedAdd    []string
+		expectedRemove []string
+	}{
+		{
+			name:        "adds missing tag",
+			torrent:     config.Torrent{TrackerName: "tracker.example.com", Tags: tagSet("movies")},
+			prefix:      "t:",
+			expectedAdd: []string{"t:tracker.example.com"},
+		},
+		{
+			name:    "up to date",
+			torrent: config.Torrent{TrackerName: "tracker.example.com", Tags: tagSet("t:tracker.example.com", "movies")},
+			prefix:  "t:",
+		},
+		{
+			name:           "replaces tag of previous tracker with prefix",
+			torrent:        config.Torrent{TrackerName: "new.example.com", Tags: tagSet("t:old.example.com", "movies")},
+			prefix:         "t:",
+			expectedAdd:    []string{"t:new.example.com"},
+			expectedRemove: []string{"t:old.example.com"},
+		},
+		{
+			name:           "replaces known tracker tag without prefix",
+			torrent:        config.Torrent{TrackerName: "new.example.com", Tags: tagSet("old.example.com", "movies")},
+			known:          tagSet("old.example.com", "new.example.com"),
+			expectedAdd:    []string{"new.example.com"},
+			expectedRemove: []string{"old.example.com"},
+		},
+		{
+			name:           "removes tracker tag of torrent without tracker",
+			torrent:        config.Torrent{Tags: tagSet("t:old.example.com")},
+			prefix:         "t:",
+			expectedRemove: []string{"t:old.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := trackerTagChanges(tt.torrent, tt.prefix, tt.known)
+			assert.Equal(t, tt.expectedAdd, add)
+			assert.Equal(t, tt.expectedRemove, remove)
+		})
+	}
+}
+
+func TestTrackerTags(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {TrackerName: "tracker.example.com"},
+		"b": {TrackerName: "other.example.com"},
+		"c": {},
+	}
+
+	assert.Equal(t, tagSet("t:tracker.example.com", "t:other.example.com"), trackerTags(torrents, "t:"))
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+var (
+	flagTrackerName string
+	flagTrackerFile string
+)
+
+var trackerCmd = &cobra.Command{
+	Use:   "tracker",
+	Short: "Inspect the tracker APIs",
+}
+
+var trackerDumpCmd = &cobra.Command{
+	Use:   "dump [CLIENT]",
+	Short: "Export the tracker API verdict of every torrent",
+	Long: `This command checks every torrent of a torrent client's queue against the API of its tracker and writes the verdicts
+to JSON, e.g. to reconcile the registered torrents with the tracker's seeding page. Torrents of trackers without a
+configured API are skipped.`,
+	Example: `  tqm tracker dump qbt --tracker ptp --file ptp.json`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		// init core
+		if !initialized {
+			initCore(true)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("tracker")
+
+		if tracker.Loaded() == 0 {
+			log.Fatal("No tracker APIs configured in the trackers section")
+		}
+
+		// resolve targeted torrent hashes
+		hashes, err := resolveTargetHashes()
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving targeted torrent hashes")
+		}
+
+		// load client object
+		clientName := args[0]
+		c, _, _, err := loadClient(ctx, clientName, flagFilterName)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed loading client: %q", clientName)
+		}
+
+		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
+
+		// retrieve torrents
+		torrents, err := c.GetTorrents(ctx)
+		if err != nil {
+			log.WithError(err).Fatal("Failed retrieving torrents")
+		} else {
+			log.Infof("Retrieved %d torrents", len(torrents))
+		}
+
+		// scope to the targeted torrents
+		torrents = scopeTorrents(log, torrents, hashes)
+
+		verdicts := checkTrackerAPIs(ctx, log, torrents, flagTrackerName, tracker.Get)
+		if len(verdicts) == 0 && flagTrackerName != "" {
+			log.Warnf("No torrents checked by the API of tracker: %q", flagTrackerName)
+		}
+
+		// write verdicts
+		var w io.Writer = os.Stdout
+		if flagTrackerFile != "" {
+			f, err := os.Create(flagTrackerFile)
+			if err != nil {
+				log.WithError(err).Fatalf("Failed creating dump file: %q", flagTrackerFile)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(verdicts); err != nil {
+			log.WithError(err).Fatal("Failed writing dump")
+		}
+
+		var unregistered, failed int
+		for _, v := range verdicts {
+			switch {
+			case v.Error != "":
+				failed++
+			case v.Unregistered:
+				unregistered++
+			}
+		}
+
+		log.Infof("Checked %d torrent(s): %d registered, %d unregistered, %d failed", len(verdicts),
+			len(verdicts)-unregistered-failed, unregistered, failed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trackerCmd)
+	trackerCmd.AddCommand(trackerDumpCmd)
+
+	trackerDumpCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	trackerDumpCmd.Flags().StringVar(&flagTrackerName, "tracker", "", "Only check torrents of this tracker API, e.g. ptp (default: all configured)")
+	trackerDumpCmd.Flags().StringVar(&flagTrackerFile, "file", "", "File to write to instead of stdout")
+	trackerDumpCmd.Flags().StringVar(&flagHash, "hash", "", "Only process the torrent with this info hash")
+	trackerDumpCmd.Flags().StringVar(&flagHashesFile, "hashes-file", "", "Only process torrents with info hashes listed in this file (one per line, - for stdin)")
+}
+
+// trackerVerdict is the result of checking a torrent against the API of its tracker
+type trackerVerdict struct {
+	Hash          string `json:"hash"`
+	Name          string `json:"name"`
+	Tracker       string `json:"tracker"`
+	TrackerStatus string `json:"tracker_status,omitempty"`
+	API           string `json:"api"`
+	Seeding       bool   `json:"seeding"`
+	Unregistered  bool   `json:"unregistered"`
+	Error         string `json:"error,omitempty"`
+}
+
+// checkTrackerAPIs checks the torrents whose tracker has an API, optionally only the API named name, sorted by name
+func checkTrackerAPIs(ctx context.Context, log *logrus.Entry, torrents map[string]config.Torrent, name string,
+	lookup func(host string) tracker.Interface) []trackerVerdict {
+	verdicts := make([]trackerVerdict, 0)
+
+	for _, t := range torrents {
+		host := t.TrackerHost
+		if host == "" {
+			host = t.TrackerName
+		}
+
+		tr := lookup(host)
+		if tr == nil || (name != "" && !strings.EqualFold(tr.Name(), name)) {
+			continue
+		}
+
+		v := trackerVerdict{
+			Hash:          t.Hash,
+			Name:          t.Name,
+			Tracker:       t.TrackerName,
+			TrackerStatus: t.TrackerStatus,
+			API:           tr.Name(),
+			Seeding:       t.Seeding,
+		}
+
+		err, unregistered := tr.IsUnregistered(ctx, &tracker.Torrent{
+			Hash:            t.Hash,
+			Name:            t.Name,
+			TotalBytes:      t.TotalBytes,
+			DownloadedBytes: t.DownloadedBytes,
+			State:           t.State,
+			Downloaded:      t.Downloaded,
+			Seeding:         t.Seeding,
+			TrackerName:     t.TrackerName,
+			TrackerStatus:   t.TrackerStatus,
+			Comment:         t.Comment,
+			// the dividers of the API logs are not needed without the logs of the commands
+			APIDividerPrinted: true,
+		})
+		if err != nil {
+			log.WithError(err).Errorf("Failed checking torrent using %s API: %q", v.API, t.Name)
+			v.Error = err.Error()
+		} else {
+			v.Unregistered = unregistered
+		}
+
+		verdicts = append(verdicts, v)
+	}
+
+	sort.Slice(verdicts, func(i, j int) bool {
+		if verdicts[i].Name != verdicts[j].Name {
+			return verdicts[i].Name < verdicts[j].Name
+		}
+		return verdicts[i].Hash < verdicts[j].Hash
+	})
+
+	return verdicts
+}
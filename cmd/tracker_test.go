@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+type fakeTrackerAPI struct {
+	name         string
+	host         string
+	unregistered map[string]bool
+	err          error
+}
+
+func (f *fakeTrackerAPI) Name() string { return f.name }
+
+func (f *fakeTrackerAPI) Check(host string) bool { return strings.Contains(host, f.host) }
+
+func (f *fakeTrackerAPI) IsUnregistered(_ context.Context, t *tracker.Torrent) (error, bool) {
+	return f.err, f.unregistered[t.Hash]
+}
+
+func (f *fakeTrackerAPI) IsTrackerDown(*tracker.Torrent) (error, bool) { return nil, false }
+
+func TestCheckTrackerAPIs(t *testing.T) {
+	apis := []tracker.Interface{
+		&fakeTrackerAPI{name: "PTP", host: "passthepopcorn.me", unregistered: map[string]bool{"b": true}},
+		&fakeTrackerAPI{name: "BTN", host: "landof.tv", err: errors.New("rate limited")},
+	}
+	lookup := func(host string) tracker.Interface {
+		for _, api := range apis {
+			if api.Check(host) {
+				return api
+			}
+		}
+		return nil
+	}
+
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", Name: "Movie A", TrackerName: "please.passthepopcorn.me", Seeding: true},
+		"b": {Hash: "b", Name: "Movie B", TrackerName: "please.passthepopcorn.me"},
+		"c": {Hash: "c", Name: "Show C", TrackerName: "landof.tv"},
+		"d": {Hash: "d", Name: "Other D", TrackerName: "tracker.example.com"},
+	}
+
+	log := logrus.NewEntry(logrus.New())
+
+	tests := []struct {
+		name     string
+		tracker  string
+		expected []trackerVerdict
+	}{
+		{
+			name:    "all trackers",
+			tracker: "",
+			expected: []trackerVerdict{
+				{Hash: "a", Name: "Movie A", Tracker: "please.passthepopcorn.me", API: "PTP", Seeding: true},
+				{Hash: "b", Name: "Movie B", Tracker: "please.passthepopcorn.me", API: "PTP", Unregistered: true},
+				{Hash: "c", Name: "Show C", Tracker: "landof.tv", API: "BTN", Error: "rate limited"},
+			},
+		},
+		{
+			name:    "single tracker case-insensitive",
+			tracker: "ptp",
+			expected: []trackerVerdict{
+				{Hash: "a", Name: "Movie A", Tracker: "please.passthepopcorn.me", API: "PTP", Seeding: true},
+				{Hash: "b", Name: "Movie B", Tracker: "please.passthepopcorn.me", API: "PTP", Unregistered: true},
+			},
+		},
+		{
+			name:     "unknown tracker",
+			tracker:  "red",
+			expected: []trackerVerdict{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, checkTrackerAPIs(context.Background(), log, torrents, tt.tracker, lookup))
+		})
+	}
+}
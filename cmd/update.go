@@ -1,26 +1,148 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/creativeprojects/go-selfupdate"
+	"github.com/creativeprojects/go-selfupdate/update"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
+	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/runtime"
 )
 
 const repoSlug = "autobrr/tqm"
 
+const (
+	updateChannelStable = "stable"
+	updateChannelBeta   = "beta"
+)
+
+var (
+	flagUpdateRollback bool
+	flagUpdateFile     string
+	flagUpdateChannel  string
+	flagUpdateYes      bool
+)
+
 var updateCmd = &cobra.Command{
-	Use:           "update",
-	Short:         "Update tqm",
-	Long:          `Update tqm to latest version.`,
+	Use:   "update",
+	Short: "Update tqm",
+	Long: `Update tqm to latest version.
+
+The downloaded release is verified against the checksums published with it before the binary is replaced.
+Use --channel beta to include pre-releases and --yes to update without confirmation, e.g. from scripts.
+The previous binary is kept next to the current one, so an update can be reverted with --rollback.
+Use --file to update from a downloaded release archive or binary, e.g. on hosts without internet access.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		release, err := selfupdate.UpdateSelf(cmd.Context(), runtime.Version, selfupdate.ParseSlug(repoSlug))
+		cmdPath, err := selfupdate.ExecutablePath()
+		if err != nil {
+			return fmt.Errorf("could not locate executable: %w", err)
+		}
+
+		switch {
+		case flagUpdateRollback:
+			if err := rollbackBinary(cmdPath); err != nil {
+				return fmt.Errorf("could not rollback binary: %w", err)
+			}
+
+			fmt.Printf("Successfully rolled back to the previous binary, run again to undo\n")
+			return nil
+
+		case flagUpdateFile != "":
+			f, err := os.Open(flagUpdateFile)
+			if err != nil {
+				return fmt.Errorf("could not open update file: %w", err)
+			}
+			defer f.Close()
+
+			if err := updateBinary(f, filepath.Base(flagUpdateFile), cmdPath); err != nil {
+				return fmt.Errorf("could not update binary: %w", err)
+			}
+
+			fmt.Printf("Successfully updated from file: %s\n", flagUpdateFile)
+			return nil
+		}
+
+		if flagUpdateChannel != updateChannelStable && flagUpdateChannel != updateChannelBeta {
+			return fmt.Errorf("unsupported channel: %q (supported: %s, %s)", flagUpdateChannel, updateChannelStable,
+				updateChannelBeta)
+		}
+
+		validator := &releaseChecksumValidator{}
+		updater, err := selfupdate.NewUpdater(selfupdate.Config{
+			Validator:  validator,
+			Prerelease: flagUpdateChannel == updateChannelBeta,
+		})
+		if err != nil {
+			return fmt.Errorf("could not initialize updater: %w", err)
+		}
+
+		// releases without a checksums file are not considered
+		release, found, err := updater.DetectLatest(cmd.Context(), selfupdate.ParseSlug(repoSlug))
 		if err != nil {
+			return fmt.Errorf("could not detect latest release: %w", err)
+		} else if !found {
+			return fmt.Errorf("no %s release with checksums found for %s/%s", flagUpdateChannel, goruntime.GOOS, goruntime.GOARCH)
+		}
+
+		if isUpToDate(runtime.Version, release.Version()) {
+			fmt.Printf("Already running the latest version: %s (latest release: %s)\n", runtime.Version, release.Version())
+			return nil
+		}
+
+		if release.OS != goruntime.GOOS || release.Arch != goruntime.GOARCH {
+			return fmt.Errorf("release asset %q is for %s/%s, expected %s/%s", release.AssetName,
+				release.OS, release.Arch, goruntime.GOOS, goruntime.GOARCH)
+		}
+
+		if !flagUpdateYes {
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return errors.New("not running in a terminal, use --yes to update without confirmation")
+			}
+
+			confirmed, err := confirmUpdate(os.Stdin, os.Stdout, runtime.Version, release.Version())
+			if err != nil {
+				return fmt.Errorf("could not read confirmation: %w", err)
+			} else if !confirmed {
+				fmt.Println("Update cancelled")
+				return nil
+			}
+		}
+
+		asset, err := downloadAsset(cmd, release.AssetURL)
+		if err != nil {
+			return fmt.Errorf("could not download release asset %q: %w", release.AssetName, err)
+		}
+
+		checksums, err := downloadAsset(cmd, release.ValidationAssetURL)
+		if err != nil {
+			return fmt.Errorf("could not download release checksums: %w", err)
+		}
+
+		if err := validator.Validate(release.AssetName, asset, checksums); err != nil {
+			return fmt.Errorf("could not verify release asset %q: %w", release.AssetName, err)
+		}
+
+		if err := updateBinary(bytes.NewReader(asset), release.AssetName, cmdPath); err != nil {
 			return fmt.Errorf("could not update binary: %w", err)
 		}
 
@@ -38,4 +160,181 @@ Flags:
 `)
 
 	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().BoolVar(&flagUpdateRollback, "rollback", false, "Restore the binary replaced by the last update")
+	updateCmd.Flags().StringVar(&flagUpdateFile, "file", "", "Update from a local release archive or binary instead of GitHub")
+	updateCmd.Flags().StringVar(&flagUpdateChannel, "channel", updateChannelStable, "Release channel to update from (stable, beta)")
+	updateCmd.Flags().BoolVarP(&flagUpdateYes, "yes", "y", false, "Update without asking for confirmation")
+	updateCmd.MarkFlagsMutuallyExclusive("rollback", "file")
+}
+
+// releaseChecksumValidator verifies release assets against the checksums file published with every release, which
+// is named after the version (tqm_<version>_checksums.txt) like the assets themselves (tqm_<version>_<os>_<arch>)
+type releaseChecksumValidator struct {
+	selfupdate.ChecksumValidator
+}
+
+func (v *releaseChecksumValidator) GetValidationAssetName(assetName string) string {
+	parts := strings.SplitN(assetName, "_", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s_%s_checksums.txt", parts[0], parts[1])
+}
+
+// isUpToDate reports whether the current version is the latest release or newer, e.g. a dev or pre-release build.
+// Versions which are not semver (e.g. local builds without a version) are never up to date
+func isUpToDate(current string, latest string) bool {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false
+	}
+
+	latestVersion, err := semver.NewVersion(latest)
+	if err != nil {
+		return false
+	}
+
+	return latestVersion.LessThanEqual(currentVersion)
+}
+
+// confirmUpdate asks whether to update from current to latest, anything but y or yes cancels the update
+func confirmUpdate(in io.Reader, out io.Writer, current string, latest string) (bool, error) {
+	fmt.Fprintf(out, "Update tqm from %s to %s? [y/N] ", current, latest)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// previousBinaryPath returns where the binary replaced by an update is kept
+func previousBinaryPath(cmdPath string) string {
+	return cmdPath + ".old"
+}
+
+func downloadAsset(cmd *cobra.Command, assetURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	res, err := httputils.NewRetryableHttpClient(5*time.Minute, nil).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// updateBinary extracts the tqm binary from the release asset (or uses it as is, when not an archive) and replaces the
+// binary at cmdPath with it, after verifying it was built for the current platform. The replaced binary is kept for rollback.
+func updateBinary(asset io.Reader, assetName string, cmdPath string) error {
+	src, err := selfupdate.DecompressCommand(asset, assetName, filepath.Base(cmdPath), goruntime.GOOS, goruntime.GOARCH)
+	if err != nil {
+		return fmt.Errorf("extract binary: %w", err)
+	}
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("extract binary: %w", err)
+	}
+
+	if err := verifyBinaryPlatform(data, goruntime.GOOS, goruntime.GOARCH); err != nil {
+		return err
+	}
+
+	return applyBinary(data, cmdPath)
+}
+
+// rollbackBinary swaps the current binary with the one replaced by the last update
+func rollbackBinary(cmdPath string) error {
+	data, err := os.ReadFile(previousBinaryPath(cmdPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("no previous binary found, tqm has not been updated yet")
+	} else if err != nil {
+		return fmt.Errorf("read previous binary: %w", err)
+	}
+
+	return applyBinary(data, cmdPath)
+}
+
+func applyBinary(data []byte, cmdPath string) error {
+	err := update.Apply(bytes.NewReader(data), update.Options{
+		TargetPath:  cmdPath,
+		OldSavePath: previousBinaryPath(cmdPath),
+	})
+	if err != nil {
+		if rerr := update.RollbackError(err); rerr != nil {
+			return fmt.Errorf("replace binary: %w (restoring %q failed, restore it manually from %q: %v)",
+				err, cmdPath, previousBinaryPath(cmdPath), rerr)
+		}
+		return fmt.Errorf("replace binary: %w", err)
+	}
+
+	return nil
+}
+
+// verifyBinaryPlatform checks whether data is an executable for goos/goarch
+func verifyBinaryPlatform(data []byte, goos string, goarch string) error {
+	var (
+		format string
+		arch   string
+	)
+
+	r := bytes.NewReader(data)
+	if f, err := elf.NewFile(r); err == nil {
+		format = "elf"
+		arch = map[elf.Machine]string{
+			elf.EM_X86_64:  "amd64",
+			elf.EM_386:     "386",
+			elf.EM_AARCH64: "arm64",
+			elf.EM_ARM:     "arm",
+		}[f.Machine]
+	} else if f, err := macho.NewFile(r); err == nil {
+		format = "macho"
+		arch = map[macho.Cpu]string{
+			macho.CpuAmd64: "amd64",
+			macho.Cpu386:   "386",
+			macho.CpuArm64: "arm64",
+			macho.CpuArm:   "arm",
+		}[f.Cpu]
+	} else if f, err := pe.NewFile(r); err == nil {
+		format = "pe"
+		arch = map[uint16]string{
+			pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+			pe.IMAGE_FILE_MACHINE_I386:  "386",
+			pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+			pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+		}[f.Machine]
+	} else {
+		return errors.New("update is not an executable")
+	}
+
+	expectedFormat := "elf"
+	switch goos {
+	case "darwin":
+		expectedFormat = "macho"
+	case "windows":
+		expectedFormat = "pe"
+	}
+
+	if format != expectedFormat || arch != goarch {
+		if arch == "" {
+			arch = "unknown"
+		}
+		return fmt.Errorf("update is a %s executable for %s, expected a %s executable for %s/%s",
+			format, arch, expectedFormat, goos, goarch)
+	}
+
+	return nil
 }
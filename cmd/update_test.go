@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBinaryPlatform(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(executable)
+	require.NoError(t, err)
+
+	otherArch := "arm64"
+	if goruntime.GOARCH == otherArch {
+		otherArch = "amd64"
+	}
+
+	assert.NoError(t, verifyBinaryPlatform(data, goruntime.GOOS, goruntime.GOARCH))
+	assert.Error(t, verifyBinaryPlatform(data, goruntime.GOOS, otherArch))
+	assert.Error(t, verifyBinaryPlatform([]byte("#!/bin/sh\necho tqm\n"), goruntime.GOOS, goruntime.GOARCH))
+}
+
+func TestRollbackBinary(t *testing.T) {
+	cmdPath := filepath.Join(t.TempDir(), "tqm")
+
+	require.Error(t, rollbackBinary(cmdPath), "no previous binary")
+
+	require.NoError(t, os.WriteFile(cmdPath, []byte("v1"), 0755))
+	require.NoError(t, applyBinary([]byte("v2"), cmdPath))
+
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, "v2", read(cmdPath))
+	assert.Equal(t, "v1", read(previousBinaryPath(cmdPath)))
+
+	require.NoError(t, rollbackBinary(cmdPath))
+	assert.Equal(t, "v1", read(cmdPath))
+	assert.Equal(t, "v2", read(previousBinaryPath(cmdPath)))
+}
+
+func TestReleaseChecksumValidator(t *testing.T) {
+	v := &releaseChecksumValidator{}
+
+	assert.Equal(t, "tqm_1.20.0_checksums.txt", v.GetValidationAssetName("tqm_1.20.0_linux_x86_64.tar.gz"))
+	assert.Equal(t, "tqm_1.21.0-beta.1_checksums.txt", v.GetValidationAssetName("tqm_1.21.0-beta.1_windows_x86_64.zip"))
+	assert.Empty(t, v.GetValidationAssetName("tqm.tar.gz"))
+
+	asset := []byte("release archive")
+	sum := sha256.Sum256(asset)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  tqm_1.20.0_linux_x86_64.tar.gz\n" +
+		strings.Repeat("0", 64) + "  tqm_1.20.0_darwin_arm64.tar.gz\n")
+
+	assert.NoError(t, v.Validate("tqm_1.20.0_linux_x86_64.tar.gz", asset, checksums))
+	assert.Error(t, v.Validate("tqm_1.20.0_linux_x86_64.tar.gz", []byte("tampered"), checksums))
+	assert.Error(t, v.Validate("tqm_1.20.0_linux_arm64.tar.gz", asset, checksums), "asset missing from checksums")
+}
+
+func TestConfirmUpdate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "y\n", expected: true},
+		{input: "YES\n", expected: true},
+		{input: "n\n"},
+		{input: "\n"},
+		{input: ""},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		confirmed, err := confirmUpdate(strings.NewReader(tt.input), &out, "1.19.0", "1.20.0")
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, confirmed, "input: %q", tt.input)
+		assert.Contains(t, out.String(), "from 1.19.0 to 1.20.0")
+	}
+}
+
+func TestIsUpToDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		latest   string
+		expected bool
+	}{
+		{name: "latest", current: "1.21.0", latest: "1.21.0", expected: true},
+		{name: "latest_v_prefix", current: "v1.21.0", latest: "1.21.0", expected: true},
+		{name: "older", current: "1.20.3", latest: "1.21.0", expected: false},
+		{name: "newer_release", current: "1.22.0", latest: "1.21.0", expected: true},
+		{name: "newer_pre_release", current: "1.22.0-beta.1", latest: "1.21.0", expected: true},
+		{name: "newer_dev", current: "1.21.1-dev+abc123", latest: "1.21.0", expected: true},
+		{name: "pre_release_of_latest", current: "1.21.0-beta.2", latest: "1.21.0", expected: false},
+		{name: "no_version", current: "", latest: "1.21.0", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isUpToDate(tt.current, tt.latest))
+		})
+	}
+}
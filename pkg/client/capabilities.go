@@ -0,0 +1,73 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities are the optional features supported by a client, so commands can skip the features a client lacks
+// instead of failing
+type Capabilities struct {
+	// Tags can be read and changed (TagInterface)
+	Tags bool
+	// Categories can be managed beyond setting the label of a torrent (CategoryInterface)
+	Categories bool
+	// FreeSpace is reported by the client without a free_space_path
+	FreeSpace bool
+	// LabelPaths are the save paths of the labels, used to relabel torrents with their data
+	LabelPaths bool
+	// SetLocation moves the data of torrents
+	SetLocation bool
+	// ShareLimits of torrents can be set (ShareLimitInterface)
+	ShareLimits bool
+	// Recheck of the data of torrents can be forced (RecheckInterface)
+	Recheck bool
+	// Files of torrents can be listed and skipped (FileInterface)
+	Files bool
+	// Export of the .torrent file of torrents (ExportInterface)
+	Export bool
+}
+
+// String lists the supported capabilities, e.g. for debug logs
+func (c Capabilities) String() string {
+	var supported []string
+	for _, capability := range []struct {
+		name      string
+		supported bool
+	}{
+		{"tags", c.Tags},
+		{"categories", c.Categories},
+		{"free space", c.FreeSpace},
+		{"label paths", c.LabelPaths},
+		{"set location", c.SetLocation},
+		{"share limits", c.ShareLimits},
+		{"recheck", c.Recheck},
+		{"files", c.Files},
+		{"export", c.Export},
+	} {
+		if capability.supported {
+			supported = append(supported, capability.name)
+		}
+	}
+
+	if len(supported) == 0 {
+		return "none"
+	}
+	return strings.Join(supported, ", ")
+}
+
+// UnsupportedError is returned when a feature is not supported by the type of a client
+type UnsupportedError struct {
+	// Feature is the unsupported feature, e.g. Retagging
+	Feature    string
+	ClientType string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is not supported for client type: %s", e.Feature, e.ClientType)
+}
+
+// Unsupported returns the error reporting that feature is not supported by c
+func Unsupported(c Interface, feature string) error {
+	return &UnsupportedError{Feature: feature, ClientType: c.Type()}
+}
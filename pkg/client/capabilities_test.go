@@ -0,0 +1,49 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities_MatchInterfaces(t *testing.T) {
+	for _, c := range []Interface{
+		&QBittorrent{clientType: "qbittorrent"},
+		&Deluge{clientType: "deluge"},
+		&Mock{clientType: "mock"},
+	} {
+		t.Run(c.Type(), func(t *testing.T) {
+			caps := c.Capabilities()
+
+			_, tags := c.(TagInterface)
+			_, categories := c.(CategoryInterface)
+			_, shareLimits := c.(ShareLimitInterface)
+			_, recheck := c.(RecheckInterface)
+			_, files := c.(FileInterface)
+			_, export := c.(ExportInterface)
+
+			assert.Equal(t, tags, caps.Tags, "tags")
+			assert.Equal(t, categories, caps.Categories, "categories")
+			assert.Equal(t, shareLimits, caps.ShareLimits, "share limits")
+			assert.Equal(t, recheck, caps.Recheck, "recheck")
+			assert.Equal(t, files, caps.Files, "files")
+			assert.Equal(t, export, caps.Export, "export")
+		})
+	}
+}
+
+func TestCapabilities_String(t *testing.T) {
+	assert.Equal(t, "none", Capabilities{}.String())
+	assert.Equal(t, "set location", (&Deluge{}).Capabilities().String())
+	assert.Equal(t, "free space, set location",
+		(&Deluge{FreeSpaceProvider: &FreeSpaceProviderConfig{}}).Capabilities().String())
+}
+
+func TestUnsupported(t *testing.T) {
+	err := Unsupported(&Deluge{clientType: "deluge"}, "Retagging")
+
+	var unsupported *UnsupportedError
+	assert.True(t, errors.As(err, &unsupported))
+	assert.Equal(t, "Retagging is not supported for client type: deluge", err.Error())
+}
@@ -0,0 +1,12 @@
+package client
+
+import (
+	"context"
+)
+
+// CategoryInterface is implemented by clients whose categories can be managed
+type CategoryInterface interface {
+	Interface
+
+	DeleteCategories(ctx context.Context, categories []string) error
+}
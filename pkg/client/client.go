@@ -8,11 +8,17 @@ import (
 )
 
 func NewClient(clientType string, clientName string, exp *expression.Expressions) (Interface, error) {
+	if replayPath != "" {
+		return newReplayMock(clientName, exp), nil
+	}
+
 	switch strings.ToLower(clientType) {
 	case "deluge":
 		return NewDeluge(clientName, exp)
 	case "qbittorrent":
 		return NewQBittorrent(clientName, exp)
+	case "mock":
+		return NewMock(clientName, exp)
 	default:
 		break
 	}
@@ -2,8 +2,11 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"path"
+	"strings"
 	"time"
 
 	delugeclient "github.com/autobrr/go-deluge"
@@ -23,6 +26,10 @@ type Deluge struct {
 	Login    *string `validate:"required"`
 	Password *string `validate:"required"`
 	V2       bool
+	// Socket is not supported, the RPC client of deluge only connects over TCP
+	Socket string
+	// FreeSpaceProvider reports the free space instead of the client, free_space_path is then not required
+	FreeSpaceProvider *FreeSpaceProviderConfig `koanf:"free_space_provider"`
 
 	// internal
 	log        *logrus.Entry
@@ -35,8 +42,17 @@ type Deluge struct {
 	freeSpaceGB  float64
 	freeSpaceSet bool
 
+	// nil unless a free_space_provider is configured
+	freeSpaceProvider *freeSpaceProvider
+
 	// internal compiled filters
 	exp *expression.Expressions
+
+	// nil unless a filter uses TrackerStatusStableFor
+	trackerHistory *trackerStatusHistory
+
+	// nil unless the retrieved data is recorded
+	recording *recording
 }
 
 /* Initializer */
@@ -57,6 +73,18 @@ func NewDeluge(name string, exp *expression.Expressions) (Interface, error) {
 	if errs := config.ValidateStruct(tc); errs != nil {
 		return nil, fmt.Errorf("validate config: %v", errs)
 	}
+	if tc.Socket != "" {
+		return nil, errors.New("validate config: socket is only supported by qbittorrent, deluge connects over TCP")
+	}
+
+	tc.trackerHistory = newTrackerStatusHistory(tc.log, name, exp)
+	tc.recording = newRecording(tc.log)
+
+	provider, err := newFreeSpaceProvider(tc.log, tc.FreeSpaceProvider)
+	if err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	tc.freeSpaceProvider = provider
 
 	// init client
 	settings := delugeclient.Settings{
@@ -81,6 +109,13 @@ func (c *Deluge) Type() string {
 	return c.clientType
 }
 
+func (c *Deluge) Capabilities() Capabilities {
+	return Capabilities{
+		FreeSpace:   c.FreeSpaceProvider != nil,
+		SetLocation: true,
+	}
+}
+
 func (c *Deluge) Connect(ctx context.Context) error {
 	var err error
 
@@ -132,16 +167,24 @@ func (c *Deluge) LabelPathMap() map[string]string {
 }
 
 func (c *Deluge) GetTorrents(ctx context.Context) (map[string]config.Torrent, error) {
+	return c.getTorrents(ctx, nil)
+}
+
+func (c *Deluge) GetTorrentsByHashes(ctx context.Context, hashes []string) (map[string]config.Torrent, error) {
+	return c.getTorrents(ctx, hashes)
+}
+
+func (c *Deluge) getTorrents(ctx context.Context, hashes []string) (map[string]config.Torrent, error) {
 	// retrieve torrents from client
 	c.log.Tracef("Retrieving torrents...")
-	ts, err := c.client.TorrentsStatus(ctx, delugeclient.StateUnspecified, nil)
+	ts, err := c.client.TorrentsStatus(ctx, delugeclient.StateUnspecified, hashes)
 	if err != nil {
 		return nil, fmt.Errorf("get torrents: %w", err)
 	}
 	c.log.Tracef("Retrieved %d torrents", len(ts))
 
 	// retrieve torrent labels
-	labels, err := c.client.GetTorrentsLabels(delugeclient.StateUnspecified, nil)
+	labels, err := c.client.GetTorrentsLabels(delugeclient.StateUnspecified, hashes)
 	if err != nil {
 		return nil, fmt.Errorf("get torrent labels: %w", err)
 	}
@@ -190,7 +233,8 @@ func (c *Deluge) GetTorrents(ctx context.Context) (map[string]config.Torrent, er
 			FreeSpaceGB:  c.GetFreeSpace,
 			FreeSpaceSet: c.freeSpaceSet,
 			// tracker
-			TrackerName:   t.TrackerHost,
+			TrackerName:   config.ParseTrackerDomain(t.TrackerHost),
+			TrackerHost:   config.ParseTrackerHost(t.TrackerHost),
 			TrackerStatus: t.TrackerStatus,
 			// Note: Deluge only uses one tracker at a time, so AllTrackerStatuses is not populated
 			AllTrackerStatuses: nil,
@@ -199,6 +243,9 @@ func (c *Deluge) GetTorrents(ctx context.Context) (map[string]config.Torrent, er
 		torrents[h] = torrent
 	}
 
+	c.trackerHistory.apply(torrents, len(hashes) == 0, time.Now())
+	c.recording.recordTorrents(torrents, len(hashes) == 0)
+
 	return torrents, nil
 }
 
@@ -251,19 +298,29 @@ func (c *Deluge) SetTorrentLabel(ctx context.Context, hash string, label string,
 }
 
 func (c *Deluge) GetCurrentFreeSpace(ctx context.Context, path string) (int64, error) {
-	if path == "" {
+	var (
+		space int64
+		err   error
+	)
+
+	switch {
+	case c.freeSpaceProvider != nil:
+		if space, err = c.freeSpaceProvider.FreeSpace(ctx); err != nil {
+			return 0, err
+		}
+	case path == "":
 		return 0, fmt.Errorf("free_space_path is not set for deluge")
-	}
-
-	// get free disk space
-	space, err := c.client.GetFreeSpace(ctx, path)
-	if err != nil {
-		return 0, fmt.Errorf("get free disk space: %v: %w", path, err)
+	default:
+		// get free disk space
+		if space, err = c.client.GetFreeSpace(ctx, path); err != nil {
+			return 0, fmt.Errorf("get free disk space: %v: %w", path, err)
+		}
 	}
 
 	// set internal free size
 	c.freeSpaceGB = float64(space) / humanize.GiByte
 	c.freeSpaceSet = true
+	c.recording.recordFreeSpace(space)
 
 	return space, nil
 }
@@ -322,6 +379,58 @@ func (c *Deluge) ShouldRelabel(ctx context.Context, t *config.Torrent) (string,
 	return "", false, nil
 }
 
+func (c *Deluge) AddTorrent(ctx context.Context, data []byte, opts AddTorrentOptions) error {
+	addOpts := &delugeclient.Options{
+		AddPaused: &opts.Paused,
+	}
+
+	if opts.SavePath != "" {
+		addOpts.DownloadLocation = &opts.SavePath
+	}
+
+	if opts.SkipRecheck {
+		if c.V2 {
+			addOpts.V2.SeedMode = &opts.SkipRecheck
+		} else {
+			c.log.Warn("Skipping recheck is not supported for deluge v1, torrent will be rechecked")
+		}
+	}
+
+	if len(opts.Tags) > 0 {
+		c.log.Warnf("Tags are not supported for deluge, ignoring: %s", strings.Join(opts.Tags, ", "))
+	}
+
+	var (
+		hash string
+		err  error
+	)
+
+	dc := c.client1
+	if c.V2 {
+		dc = &c.client2.Client
+	}
+
+	if isMagnetLink(data) {
+		hash, err = dc.AddTorrentMagnet(ctx, strings.TrimSpace(string(data)), addOpts)
+	} else {
+		hash, err = dc.AddTorrentFile(ctx, "tqm.torrent", base64.StdEncoding.EncodeToString(data), addOpts)
+	}
+
+	if err != nil {
+		return fmt.Errorf("add torrent: %w", err)
+	} else if hash == "" {
+		return fmt.Errorf("add torrent: torrent already exists")
+	}
+
+	if opts.Label != "" {
+		if err := c.client.SetTorrentLabel(ctx, hash, opts.Label); err != nil {
+			return fmt.Errorf("set torrent label: %v: %w", opts.Label, err)
+		}
+	}
+
+	return nil
+}
+
 func (c *Deluge) SetUploadLimit(ctx context.Context, hash string, limit int64) error {
 	var uploadSpeed int
 	if limit == -1 {
@@ -349,6 +458,23 @@ func (c *Deluge) SetUploadLimit(ctx context.Context, hash string, limit int64) e
 	return nil
 }
 
+func (c *Deluge) ShouldMove(ctx context.Context, t *config.Torrent) (string, bool, error) {
+	for _, move := range c.exp.Moves {
+		// check update
+		match, err := expression.CheckTorrentAllMatch(ctx, t, move.Updates)
+		if err != nil {
+			return "", false, fmt.Errorf("check move expression: %v: %w", t.Hash, err)
+		} else if !match {
+			continue
+		}
+
+		// we should move
+		return move.Path, true, nil
+	}
+
+	return "", false, nil
+}
+
 func (c *Deluge) CheckTorrentPause(ctx context.Context, t *config.Torrent) (bool, error) {
 	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Pauses)
 	if err != nil {
@@ -372,3 +498,66 @@ func (c *Deluge) PauseTorrents(ctx context.Context, hashes []string) error {
 
 	return nil
 }
+
+func (c *Deluge) CheckTorrentResume(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Resumes)
+	if err != nil {
+		return false, fmt.Errorf("check resume expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Deluge) ResumeTorrents(ctx context.Context, hashes []string) error {
+	var err error
+	if c.V2 {
+		err = c.client2.ResumeTorrents(ctx, hashes...)
+	} else {
+		err = c.client1.ResumeTorrents(ctx, hashes...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("resume torrents: %v: %w", hashes, err)
+	}
+
+	return nil
+}
+
+func (c *Deluge) CheckTorrentReannounce(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Reannounces)
+	if err != nil {
+		return false, fmt.Errorf("check reannounce expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Deluge) ReannounceTorrents(ctx context.Context, hashes []string) error {
+	var err error
+	if c.V2 {
+		err = c.client2.ForceReannounce(ctx, hashes)
+	} else {
+		err = c.client1.ForceReannounce(ctx, hashes)
+	}
+
+	if err != nil {
+		return fmt.Errorf("reannounce torrents: %v: %w", hashes, err)
+	}
+
+	return nil
+}
+
+func (c *Deluge) MoveTorrents(ctx context.Context, hashes []string, path string) error {
+	var err error
+	if c.V2 {
+		err = c.client2.MoveStorage(ctx, hashes, path)
+	} else {
+		err = c.client1.MoveStorage(ctx, hashes, path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("move torrents: %v: %q: %w", hashes, path, err)
+	}
+
+	return nil
+}
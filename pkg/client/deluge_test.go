@@ -0,0 +1,29 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestNewDeluge_Socket(t *testing.T) {
+	prevK := config.K
+	t.Cleanup(func() { config.K = prevK })
+
+	config.K = koanf.New(config.Delimiter)
+	require.NoError(t, config.K.Load(confmap.Provider(map[string]any{
+		"clients.deluge.host":     "localhost",
+		"clients.deluge.port":     58846,
+		"clients.deluge.login":    "localclient",
+		"clients.deluge.password": "secret",
+		"clients.deluge.socket":   "/run/deluge.sock",
+	}, config.Delimiter), nil))
+
+	_, err := NewDeluge("deluge", nil)
+	assert.ErrorContains(t, err, "socket is only supported by qbittorrent")
+}
@@ -0,0 +1,12 @@
+package client
+
+import (
+	"context"
+)
+
+// ExportInterface is implemented by clients that can export the .torrent file of a torrent
+type ExportInterface interface {
+	Interface
+
+	ExportTorrent(ctx context.Context, hash string) ([]byte, error)
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// TorrentFile is a file of a torrent
+type TorrentFile struct {
+	Index int
+	// Name is the path of the file relative to the save path of the torrent
+	Name     string
+	Size     int64
+	Progress float64
+	// Skipped is set when the file is not downloaded (priority 0)
+	Skipped bool
+	// FirstPiece and LastPiece are the first and last piece holding data of the file
+	FirstPiece int
+	LastPiece  int
+}
+
+// FileInterface is implemented by clients that can skip the download of single files of a torrent
+type FileInterface interface {
+	Interface
+
+	ShouldPruneFiles(ctx context.Context, t *config.Torrent) (bool, error)
+	GetTorrentFiles(ctx context.Context, hash string) ([]TorrentFile, error)
+	SkipFiles(ctx context.Context, hash string, indexes []int) error
+}
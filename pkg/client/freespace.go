@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+)
+
+// FreeSpaceProviderConfig is an HTTP endpoint reporting the free space of the disk of a client, e.g. the quota API of a
+// managed seedbox whose filesystem view is misleading. The endpoint responds with {"free": <bytes>, "total": <bytes>}.
+type FreeSpaceProviderConfig struct {
+	URL     string            `koanf:"url"`
+	Headers map[string]string `koanf:"headers"`
+}
+
+// freeSpaceProvider retrieves the free space from the endpoint of a FreeSpaceProviderConfig
+type freeSpaceProvider struct {
+	log     *logrus.Entry
+	url     string
+	headers map[string]string
+	http    *http.Client
+}
+
+// newFreeSpaceProvider returns the provider of cfg, nil when no provider is configured
+func newFreeSpaceProvider(log *logrus.Entry, cfg *FreeSpaceProviderConfig) (*freeSpaceProvider, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("free_space_provider must set a url")
+	}
+
+	return &freeSpaceProvider{
+		log:     log,
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		http:    httputils.NewRetryableHttpClient(15*time.Second, nil),
+	}, nil
+}
+
+// FreeSpace returns the free bytes reported by the endpoint
+func (p *freeSpaceProvider) FreeSpace(ctx context.Context) (int64, error) {
+	var resp struct {
+		Free  *int64 `json:"free"`
+		Total int64  `json:"total"`
+	}
+
+	if err := httputils.MakeAPIRequest(ctx, p.http, http.MethodGet, p.url, nil, p.headers, &resp); err != nil {
+		return 0, fmt.Errorf("free space provider: %w", err)
+	}
+
+	if resp.Free == nil {
+		return 0, fmt.Errorf("free space provider: response is missing free")
+	}
+
+	p.log.Debugf("Free space provider reported %s free of %s", humanize.IBytes(uint64(*resp.Free)),
+		humanize.IBytes(uint64(resp.Total)))
+	return *resp.Free, nil
+}
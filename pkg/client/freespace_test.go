@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeSpaceProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/disk":
+			_, _ = w.Write([]byte(`{"free": 1073741824, "total": 4294967296}`))
+		default:
+			_, _ = w.Write([]byte(`{"total": 4294967296}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	ctx := context.Background()
+
+	p, err := newFreeSpaceProvider(log, &FreeSpaceProviderConfig{URL: srv.URL + "/disk",
+		Headers: map[string]string{"Authorization": "Bearer secret"}})
+	require.NoError(t, err)
+
+	free, err := p.FreeSpace(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1073741824), free)
+
+	p, err = newFreeSpaceProvider(log, &FreeSpaceProviderConfig{URL: srv.URL + "/quota",
+		Headers: map[string]string{"Authorization": "Bearer secret"}})
+	require.NoError(t, err)
+	_, err = p.FreeSpace(ctx)
+	assert.ErrorContains(t, err, "missing free")
+
+	p, err = newFreeSpaceProvider(log, &FreeSpaceProviderConfig{URL: srv.URL + "/disk"})
+	require.NoError(t, err)
+	_, err = p.FreeSpace(ctx)
+	assert.Error(t, err)
+
+	_, err = newFreeSpaceProvider(log, &FreeSpaceProviderConfig{})
+	assert.Error(t, err)
+
+	p, err = newFreeSpaceProvider(log, nil)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 
 	"github.com/autobrr/tqm/pkg/config"
@@ -8,8 +9,10 @@ import (
 
 type Interface interface {
 	Type() string
+	Capabilities() Capabilities
 	Connect(ctx context.Context) error
 	GetTorrents(ctx context.Context) (map[string]config.Torrent, error)
+	GetTorrentsByHashes(ctx context.Context, hashes []string) (map[string]config.Torrent, error)
 	RemoveTorrent(ctx context.Context, torrent *config.Torrent, deleteData bool) (bool, error)
 	SetTorrentLabel(ctx context.Context, hash string, label string, hardlink bool) error
 	GetCurrentFreeSpace(ctx context.Context, path string) (int64, error)
@@ -19,12 +22,33 @@ type Interface interface {
 	LabelPathMap() map[string]string
 
 	SetUploadLimit(ctx context.Context, hash string, limit int64) error
+	// AddTorrent adds the .torrent file or magnet link in data
+	AddTorrent(ctx context.Context, data []byte, opts AddTorrentOptions) error
 
 	ShouldIgnore(ctx context.Context, t *config.Torrent) (bool, string, error)
 	ShouldRemove(ctx context.Context, t *config.Torrent) (bool, error)
 	ShouldRemoveWithReason(ctx context.Context, t *config.Torrent) (bool, string, error)
 	CheckTorrentPause(ctx context.Context, t *config.Torrent) (bool, error)
+	CheckTorrentResume(ctx context.Context, t *config.Torrent) (bool, error)
+	CheckTorrentReannounce(ctx context.Context, t *config.Torrent) (bool, error)
 	ShouldRelabel(ctx context.Context, t *config.Torrent) (string, bool, error)
+	ShouldMove(ctx context.Context, t *config.Torrent) (string, bool, error)
 
 	PauseTorrents(ctx context.Context, hashes []string) error
+	ResumeTorrents(ctx context.Context, hashes []string) error
+	ReannounceTorrents(ctx context.Context, hashes []string) error
+	MoveTorrents(ctx context.Context, hashes []string, path string) error
+}
+
+type AddTorrentOptions struct {
+	Paused      bool
+	SkipRecheck bool
+	Label       string
+	Tags        []string
+	SavePath    string
+}
+
+// isMagnetLink reports whether the data given to AddTorrent is a magnet link instead of a .torrent file
+func isMagnetLink(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("magnet:?"))
 }
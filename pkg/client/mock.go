@@ -0,0 +1,796 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+/* Struct */
+
+// Mock is a simulated client serving the torrents of a fixture file, e.g. written by the export command. Actions
+// only change the torrents held in memory, neither the fixture nor any data on disk is modified.
+type Mock struct {
+	File *string `validate:"required"`
+	// FreeSpaceGB is reported as the free space of the client, free space is not set when empty
+	FreeSpaceGB   *float64          `koanf:"free_space_gb"`
+	CategoryPaths map[string]string `koanf:"category_paths"`
+
+	// internal
+	log        *logrus.Entry
+	clientType string
+	torrents   map[string]config.Torrent
+	tags       map[string]struct{}
+	// skipped holds the indexes of the files not downloaded by torrent hash
+	skipped map[string]map[int]struct{}
+
+	// set by cmd handler
+	freeSpaceGB  float64
+	freeSpaceSet bool
+
+	// internal compiled filters
+	exp *expression.Expressions
+
+	// nil unless a filter uses TrackerStatusStableFor
+	trackerHistory *trackerStatusHistory
+}
+
+/* Initializer */
+
+func NewMock(name string, exp *expression.Expressions) (TagInterface, error) {
+	tc := Mock{
+		log:        logger.GetLogger(name),
+		clientType: "Mock",
+		exp:        exp,
+	}
+
+	// load config
+	if err := config.K.Unmarshal(fmt.Sprintf("clients%s%s", config.Delimiter, name), &tc); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+
+	// validate config
+	if errs := config.ValidateStruct(tc); errs != nil {
+		return nil, fmt.Errorf("validate config: %v", errs)
+	}
+
+	tc.trackerHistory = newTrackerStatusHistory(tc.log, name, exp)
+
+	return &tc, nil
+}
+
+/* Interface  */
+
+func (c *Mock) Type() string {
+	return c.clientType
+}
+
+// Capabilities of the mock client are those of qbittorrent, except exporting .torrent files which it does not have
+func (c *Mock) Capabilities() Capabilities {
+	return Capabilities{
+		Tags:        true,
+		Categories:  true,
+		FreeSpace:   true,
+		LabelPaths:  true,
+		SetLocation: true,
+		ShareLimits: true,
+		Recheck:     true,
+		Files:       true,
+	}
+}
+
+func (c *Mock) Connect(context.Context) error {
+	// relative fixtures are kept next to the config file
+	file := *c.File
+	if !filepath.IsAbs(file) {
+		file = config.StatePath(file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read fixture: %w", err)
+	}
+
+	fixture, err := parseMockFixture(file, data)
+	if err != nil {
+		return fmt.Errorf("parse fixture: %q: %w", file, err)
+	}
+
+	// the configured free space and category paths take precedence over recorded ones
+	if c.FreeSpaceGB == nil {
+		c.FreeSpaceGB = fixture.FreeSpaceGB
+	}
+	if c.CategoryPaths == nil {
+		c.CategoryPaths = fixture.CategoryPaths
+	}
+
+	c.torrents = make(map[string]config.Torrent, len(fixture.Torrents))
+	c.tags = make(map[string]struct{})
+	for _, t := range fixture.Torrents {
+		if _, ok := c.torrents[t.Hash]; ok {
+			return fmt.Errorf("parse fixture: %q: duplicate hash: %s", file, t.Hash)
+		}
+		c.torrents[t.Hash] = t
+
+		for tag := range t.Tags {
+			c.tags[tag] = struct{}{}
+		}
+	}
+
+	c.log.Debugf("Loaded %d torrents from fixture: %q", len(c.torrents), file)
+	return nil
+}
+
+func (c *Mock) LoadLabelPathMap(context.Context) error {
+	return nil
+}
+
+func (c *Mock) LabelPathMap() map[string]string {
+	return c.CategoryPaths
+}
+
+func (c *Mock) GetTorrents(ctx context.Context) (map[string]config.Torrent, error) {
+	return c.getTorrents(nil), nil
+}
+
+func (c *Mock) GetTorrentsByHashes(ctx context.Context, hashes []string) (map[string]config.Torrent, error) {
+	return c.getTorrents(hashes), nil
+}
+
+func (c *Mock) getTorrents(hashes []string) map[string]config.Torrent {
+	torrents := make(map[string]config.Torrent)
+	for h, t := range c.torrents {
+		if len(hashes) > 0 && !slices.Contains(hashes, h) {
+			continue
+		}
+
+		// the maps of the torrent are copied, so the caller cannot change the torrents held by the client
+		t.Tags = copyTags(t.Tags)
+		t.FreeSpaceGB = c.GetFreeSpace
+		t.FreeSpaceSet = c.freeSpaceSet
+		torrents[h] = t
+	}
+
+	c.trackerHistory.apply(torrents, len(hashes) == 0, time.Now())
+
+	return torrents
+}
+
+func (c *Mock) RemoveTorrent(_ context.Context, torrent *config.Torrent, deleteData bool) (bool, error) {
+	// check if the tracker is down before removing
+	if torrent.IsTrackerDown() {
+		c.log.Debugf("Skipping removal for %s (%s) as tracker %s is down", torrent.Name, torrent.Hash, torrent.TrackerName)
+		return false, nil
+	}
+
+	if _, ok := c.torrents[torrent.Hash]; !ok {
+		return false, fmt.Errorf("delete torrent: %v: not found", torrent.Hash)
+	}
+
+	delete(c.torrents, torrent.Hash)
+	c.log.Debugf("Removed torrent: %s (delete data: %v)", torrent.Hash, deleteData)
+	return true, nil
+}
+
+func (c *Mock) SetTorrentLabel(_ context.Context, hash string, label string, _ bool) error {
+	return c.update(hash, func(t *config.Torrent) {
+		t.Label = label
+	})
+}
+
+func (c *Mock) AddTorrent(context.Context, []byte, AddTorrentOptions) error {
+	return fmt.Errorf("add torrent: not supported by the mock client")
+}
+
+func (c *Mock) SetUploadLimit(_ context.Context, hash string, limit int64) error {
+	return c.update(hash, func(t *config.Torrent) {
+		t.UpLimit = limit
+	})
+}
+
+func (c *Mock) SetShareLimits(_ context.Context, hash string, limits ShareLimits) error {
+	return c.update(hash, func(t *config.Torrent) {
+		t.RatioLimit = limits.RatioLimit
+		t.SeedingTimeLimit = limits.SeedingTimeLimit
+		t.InactiveSeedingTimeLimit = limits.InactiveSeedingTimeLimit
+	})
+}
+
+func (c *Mock) GetCurrentFreeSpace(context.Context, string) (int64, error) {
+	if c.FreeSpaceGB == nil {
+		return 0, nil
+	}
+
+	// set internal free size
+	c.freeSpaceGB = *c.FreeSpaceGB
+	c.freeSpaceSet = true
+
+	return int64(*c.FreeSpaceGB * humanize.GiByte), nil
+}
+
+func (c *Mock) AddFreeSpace(bytes int64) {
+	c.freeSpaceGB += float64(bytes) / humanize.GiByte
+}
+
+func (c *Mock) GetFreeSpace() float64 {
+	return c.freeSpaceGB
+}
+
+/* Filters */
+
+func (c *Mock) ShouldIgnore(ctx context.Context, t *config.Torrent) (bool, string, error) {
+	match, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, c.exp.Ignores)
+	if err != nil {
+		return true, "", fmt.Errorf("check ignore expression: %v: %w", t.Hash, err)
+	}
+
+	return match, reason, nil
+}
+
+func (c *Mock) ShouldRemove(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Removes)
+	if err != nil {
+		return false, fmt.Errorf("check remove expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Mock) ShouldRemoveWithReason(ctx context.Context, t *config.Torrent) (bool, string, error) {
+	match, reason, err := expression.CheckTorrentSingleMatchWithReason(ctx, t, c.exp.Removes)
+	if err != nil {
+		return false, "", fmt.Errorf("check remove expression: %v: %w", t.Hash, err)
+	}
+
+	return match, reason, nil
+}
+
+func (c *Mock) ShouldRelabel(ctx context.Context, t *config.Torrent) (string, bool, error) {
+	for _, label := range c.exp.Labels {
+		// check update
+		match, err := expression.CheckTorrentAllMatch(ctx, t, label.Updates)
+		if err != nil {
+			return "", false, fmt.Errorf("check update expression: %v: %w", t.Hash, err)
+		} else if !match {
+			continue
+		}
+
+		// we should re-label
+		return label.Name, true, nil
+	}
+
+	return "", false, nil
+}
+
+func (c *Mock) ShouldMove(ctx context.Context, t *config.Torrent) (string, bool, error) {
+	for _, move := range c.exp.Moves {
+		// check update
+		match, err := expression.CheckTorrentAllMatch(ctx, t, move.Updates)
+		if err != nil {
+			return "", false, fmt.Errorf("check move expression: %v: %w", t.Hash, err)
+		} else if !match {
+			continue
+		}
+
+		// we should move
+		return move.Path, true, nil
+	}
+
+	return "", false, nil
+}
+
+func (c *Mock) CheckTorrentPause(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Pauses)
+	if err != nil {
+		return false, fmt.Errorf("check pause expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Mock) PauseTorrents(_ context.Context, hashes []string) error {
+	return c.updateAll(hashes, func(t *config.Torrent) {
+		t.State = "pausedUP"
+		if !t.Downloaded {
+			t.State = "pausedDL"
+		}
+		t.Seeding = false
+	})
+}
+
+func (c *Mock) CheckTorrentResume(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Resumes)
+	if err != nil {
+		return false, fmt.Errorf("check resume expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Mock) ResumeTorrents(_ context.Context, hashes []string) error {
+	return c.updateAll(hashes, func(t *config.Torrent) {
+		t.State = "stalledUP"
+		t.Seeding = t.Downloaded
+		if !t.Downloaded {
+			t.State = "stalledDL"
+		}
+	})
+}
+
+func (c *Mock) CheckTorrentReannounce(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Reannounces)
+	if err != nil {
+		return false, fmt.Errorf("check reannounce expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Mock) ReannounceTorrents(_ context.Context, hashes []string) error {
+	return c.updateAll(hashes, func(*config.Torrent) {})
+}
+
+func (c *Mock) MoveTorrents(_ context.Context, hashes []string, path string) error {
+	return c.updateAll(hashes, func(t *config.Torrent) {
+		files := make([]string, 0, len(t.Files))
+		for _, f := range t.Files {
+			if rel, err := filepath.Rel(t.Path, f); err == nil && !strings.HasPrefix(rel, "..") {
+				f = filepath.Join(path, rel)
+			}
+			files = append(files, f)
+		}
+		t.Files = files
+		t.Path = path
+	})
+}
+
+func (c *Mock) CheckTorrentRecheck(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Rechecks)
+	if err != nil {
+		return false, fmt.Errorf("check recheck expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *Mock) RecheckTorrents(_ context.Context, hashes []string) error {
+	return c.updateAll(hashes, func(*config.Torrent) {})
+}
+
+func (c *Mock) ShouldPruneFiles(ctx context.Context, t *config.Torrent) (bool, error) {
+	return checkTorrentPruneFiles(ctx, c.exp, t)
+}
+
+// GetTorrentFiles returns the files of the torrent, every file is simulated to span its own piece
+func (c *Mock) GetTorrentFiles(_ context.Context, hash string) ([]TorrentFile, error) {
+	t, ok := c.torrents[hash]
+	if !ok {
+		return nil, fmt.Errorf("get torrent files: %v: not found", hash)
+	}
+
+	progress := 1.0
+	if !t.Downloaded && t.TotalBytes > 0 {
+		progress = float64(t.DownloadedBytes) / float64(t.TotalBytes)
+	}
+
+	files := make([]TorrentFile, 0, len(t.Files))
+	for i, f := range t.Files {
+		name := strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(f, t.Path)), "/")
+		_, skipped := c.skipped[hash][i]
+
+		files = append(files, TorrentFile{
+			Index:      i,
+			Name:       name,
+			Size:       t.TotalBytes / int64(len(t.Files)),
+			Progress:   progress,
+			Skipped:    skipped,
+			FirstPiece: i,
+			LastPiece:  i,
+		})
+	}
+
+	return files, nil
+}
+
+func (c *Mock) SkipFiles(_ context.Context, hash string, indexes []int) error {
+	if _, ok := c.torrents[hash]; !ok {
+		return fmt.Errorf("skip files: %v: not found", hash)
+	}
+
+	if c.skipped == nil {
+		c.skipped = make(map[string]map[int]struct{})
+	}
+	if c.skipped[hash] == nil {
+		c.skipped[hash] = make(map[int]struct{})
+	}
+	for _, i := range indexes {
+		c.skipped[hash][i] = struct{}{}
+	}
+	return nil
+}
+
+func (c *Mock) ShouldRetag(ctx context.Context, t *config.Torrent) (RetagInfo, error) {
+	return checkTorrentRetag(ctx, c.exp, t)
+}
+
+func (c *Mock) ShouldSetShareLimits(ctx context.Context, t *config.Torrent) (*ShareLimits, error) {
+	return checkTorrentShareLimits(ctx, c.exp, t)
+}
+
+func (c *Mock) AddTags(_ context.Context, hash string, tags []string) error {
+	return c.update(hash, func(t *config.Torrent) {
+		for _, tag := range tags {
+			t.Tags[tag] = struct{}{}
+			c.tags[tag] = struct{}{}
+		}
+	})
+}
+
+func (c *Mock) RemoveTags(_ context.Context, hash string, tags []string) error {
+	return c.update(hash, func(t *config.Torrent) {
+		for _, tag := range tags {
+			delete(t.Tags, tag)
+		}
+	})
+}
+
+func (c *Mock) SetTags(_ context.Context, hash string, tags []string) error {
+	return c.update(hash, func(t *config.Torrent) {
+		t.Tags = make(map[string]struct{}, len(tags))
+		for _, tag := range tags {
+			t.Tags[tag] = struct{}{}
+			c.tags[tag] = struct{}{}
+		}
+	})
+}
+
+func (c *Mock) GetTags(context.Context) ([]string, error) {
+	tags := make([]string, 0, len(c.tags))
+	for tag := range c.tags {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
+
+func (c *Mock) CreateTags(_ context.Context, tags []string) error {
+	for _, tag := range tags {
+		c.tags[tag] = struct{}{}
+	}
+	return nil
+}
+
+func (c *Mock) DeleteTags(_ context.Context, tags []string) error {
+	for _, tag := range tags {
+		delete(c.tags, tag)
+	}
+
+	for h, t := range c.torrents {
+		for _, tag := range tags {
+			delete(t.Tags, tag)
+		}
+		c.torrents[h] = t
+	}
+	return nil
+}
+
+func (c *Mock) DeleteCategories(_ context.Context, categories []string) error {
+	for _, category := range categories {
+		delete(c.CategoryPaths, category)
+	}
+	return nil
+}
+
+// update applies fn to the torrent with hash
+func (c *Mock) update(hash string, fn func(t *config.Torrent)) error {
+	t, ok := c.torrents[hash]
+	if !ok {
+		return fmt.Errorf("update torrent: %v: not found", hash)
+	}
+
+	if t.Tags == nil {
+		t.Tags = make(map[string]struct{})
+	}
+	fn(&t)
+	c.torrents[hash] = t
+
+	return nil
+}
+
+// updateAll applies fn to the torrents with hashes
+func (c *Mock) updateAll(hashes []string, fn func(t *config.Torrent)) error {
+	for _, hash := range hashes {
+		if err := c.update(hash, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyTags(tags map[string]struct{}) map[string]struct{} {
+	copied := make(map[string]struct{}, len(tags))
+	for tag := range tags {
+		copied[tag] = struct{}{}
+	}
+	return copied
+}
+
+/* Fixture */
+
+// mockTorrent is a torrent of a JSON fixture, whose tags are either a list or a map as written by older versions of the
+// export command
+type mockTorrent struct {
+	config.Torrent
+
+	Tags mockTags `json:"Tags"`
+}
+
+type mockTags []string
+
+func (m *mockTags) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*m = nil
+		return nil
+	case bytes.HasPrefix(data, []byte("{")):
+		var tags map[string]struct{}
+		if err := json.Unmarshal(data, &tags); err != nil {
+			return err
+		}
+		*m = make(mockTags, 0, len(tags))
+		for tag := range tags {
+			*m = append(*m, tag)
+		}
+		return nil
+	default:
+		var tags []string
+		if err := json.Unmarshal(data, &tags); err != nil {
+			return err
+		}
+		*m = tags
+		return nil
+	}
+}
+
+// parseMockFixture parses a JSON (list of torrents, export document or recorded fixture) or CSV (header row with
+// torrent field names) fixture, the format is determined by the extension of file
+func parseMockFixture(file string, data []byte) (mockFixture, error) {
+	var (
+		fixture mockFixture
+		err     error
+	)
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		fixture, err = parseMockJSON(data)
+	case ".csv":
+		fixture.Torrents, err = parseMockCSV(data)
+	default:
+		return fixture, fmt.Errorf("unsupported fixture format: %q (supported: .json, .csv)", filepath.Ext(file))
+	}
+	if err != nil {
+		return fixture, err
+	}
+
+	for i := range fixture.Torrents {
+		if err := normalizeMockTorrent(&fixture.Torrents[i]); err != nil {
+			return fixture, fmt.Errorf("torrent %d: %w", i+1, err)
+		}
+	}
+
+	return fixture, nil
+}
+
+func parseMockJSON(data []byte) (mockFixture, error) {
+	var (
+		fixture  mockFixture
+		recorded struct {
+			FreeSpaceGB   *float64          `json:"free_space_gb"`
+			CategoryPaths map[string]string `json:"category_paths"`
+			Torrents      []mockTorrent     `json:"torrents"`
+		}
+	)
+
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &recorded.Torrents); err != nil {
+			return fixture, err
+		}
+	} else if err := json.Unmarshal(trimmed, &recorded); err != nil {
+		return fixture, err
+	}
+
+	fixture.FreeSpaceGB = recorded.FreeSpaceGB
+	fixture.CategoryPaths = recorded.CategoryPaths
+	fixture.Torrents = make([]config.Torrent, 0, len(recorded.Torrents))
+	for _, m := range recorded.Torrents {
+		t := m.Torrent
+		t.Tags = make(map[string]struct{}, len(m.Tags))
+		for _, tag := range m.Tags {
+			t.Tags[tag] = struct{}{}
+		}
+		fixture.Torrents = append(fixture.Torrents, t)
+	}
+
+	return fixture, nil
+}
+
+func parseMockCSV(data []byte) ([]config.Torrent, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	torrents := make([]config.Torrent, 0, len(records)-1)
+	for i, record := range records[1:] {
+		t := config.Torrent{Tags: make(map[string]struct{})}
+		for col, value := range record {
+			if err := setMockField(&t, header[col], strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("row %d: %s: %w", i+2, header[col], err)
+			}
+		}
+		torrents = append(torrents, t)
+	}
+
+	return torrents, nil
+}
+
+// setMockField sets the field of t named by a CSV column, columns of unknown fields (e.g. the filter results of an
+// export) are ignored
+func setMockField(t *config.Torrent, column string, value string) error {
+	var err error
+
+	switch strings.ToLower(strings.TrimSpace(column)) {
+	case "hash":
+		t.Hash = value
+	case "name":
+		t.Name = value
+	case "path":
+		t.Path = value
+	case "files":
+		t.Files = splitMockList(value)
+	case "totalbytes":
+		t.TotalBytes, err = parseMockInt(value)
+	case "downloadedbytes":
+		t.DownloadedBytes, err = parseMockInt(value)
+	case "state":
+		t.State = value
+	case "downloaded":
+		t.Downloaded, err = parseMockBool(value)
+	case "seeding":
+		t.Seeding, err = parseMockBool(value)
+	case "ratio":
+		t.Ratio, err = parseMockFloat(value)
+	case "addedseconds":
+		t.AddedSeconds, err = parseMockInt(value)
+	case "addeddays":
+		t.AddedDays, err = parseMockFloat(value)
+	case "seedingseconds":
+		t.SeedingSeconds, err = parseMockInt(value)
+	case "seedingdays":
+		t.SeedingDays, err = parseMockFloat(value)
+	case "lastactivityseconds":
+		t.LastActivitySeconds, err = parseMockInt(value)
+	case "lastactivitydays":
+		t.LastActivityDays, err = parseMockFloat(value)
+	case "label":
+		t.Label = value
+	case "tags":
+		for _, tag := range splitMockList(value) {
+			t.Tags[tag] = struct{}{}
+		}
+	case "seeds":
+		t.Seeds, err = parseMockInt(value)
+	case "peers":
+		t.Peers, err = parseMockInt(value)
+	case "isprivate":
+		t.IsPrivate, err = parseMockBool(value)
+	case "uplimit":
+		t.UpLimit, err = parseMockInt(value)
+	case "trackername":
+		t.TrackerName = value
+	case "trackerhost":
+		t.TrackerHost = value
+	case "trackerstatus":
+		t.TrackerStatus = value
+	case "comment":
+		t.Comment = value
+	}
+
+	return err
+}
+
+// splitMockList splits a list of a CSV column, separated by semicolons as written by the export command
+func splitMockList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func parseMockInt(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func parseMockFloat(value string) (float32, error) {
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 32)
+	return float32(f), err
+}
+
+func parseMockBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// normalizeMockTorrent validates t and derives the fields not given by the fixture, e.g. AddedHours from AddedDays
+func normalizeMockTorrent(t *config.Torrent) error {
+	t.Hash = strings.ToLower(strings.TrimSpace(t.Hash))
+	if t.Hash == "" {
+		return fmt.Errorf("missing hash")
+	}
+
+	if t.Tags == nil {
+		t.Tags = make(map[string]struct{})
+	}
+
+	if t.DownloadedBytes == 0 && t.Downloaded {
+		t.DownloadedBytes = t.TotalBytes
+	}
+
+	if t.State == "" {
+		t.State = "stalledUP"
+		if !t.Downloaded {
+			t.State = "stalledDL"
+		}
+	}
+
+	t.IsPublic = !t.IsPrivate
+
+	if t.TrackerHost == "" {
+		t.TrackerHost = t.TrackerName
+	}
+
+	t.AddedSeconds, t.AddedHours, t.AddedDays = mockDurations(t.AddedSeconds, t.AddedDays)
+	t.SeedingSeconds, t.SeedingHours, t.SeedingDays = mockDurations(t.SeedingSeconds, t.SeedingDays)
+	t.LastActivitySeconds, t.LastActivityHours, t.LastActivityDays = mockDurations(t.LastActivitySeconds, t.LastActivityDays)
+
+	return nil
+}
+
+// mockDurations returns a duration in seconds, hours and days, given in seconds or else in days
+func mockDurations(seconds int64, days float32) (int64, float32, float32) {
+	if seconds == 0 && days > 0 {
+		seconds = int64(float64(days) * 24 * 60 * 60)
+	}
+	return seconds, float32(seconds) / 60 / 60, float32(seconds) / 60 / 60 / 24
+}
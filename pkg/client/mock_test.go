@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestParseMockFixture(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		expected []config.Torrent
+		wantErr  bool
+	}{
+		{
+			name: "json with tag list",
+			file: "fixture.json",
+			data: `[{"Hash": "ABC", "Name": "Movie", "Downloaded": true, "TotalBytes": 100, "SeedingDays": 2, "Tags": ["a"],
+				"TrackerName": "tracker.example.com", "IsPrivate": true}]`,
+			expected: []config.Torrent{{
+				Hash: "abc", Name: "Movie", Downloaded: true, TotalBytes: 100, DownloadedBytes: 100, State: "stalledUP",
+				SeedingSeconds: 172800, SeedingHours: 48, SeedingDays: 2, Tags: map[string]struct{}{"a": {}},
+				TrackerName: "tracker.example.com", TrackerHost: "tracker.example.com", IsPrivate: true,
+			}},
+		},
+		{
+			name: "json with tag map as written by older exports",
+			file: "fixture.json",
+			data: `[{"Hash": "abc", "Tags": {"a": {}, "b": {}}, "State": "pausedDL"}]`,
+			expected: []config.Torrent{{
+				Hash: "abc", State: "pausedDL", Tags: map[string]struct{}{"a": {}, "b": {}}, IsPublic: true,
+			}},
+		},
+		{
+			name: "json export document",
+			file: "fixture.json",
+			data: `{"schema_version": 1, "torrents": [{"Hash": "abc", "Tags": ["a"], "State": "pausedDL",
+				"Match": {"Remove": true}}]}`,
+			expected: []config.Torrent{{
+				Hash: "abc", State: "pausedDL", Tags: map[string]struct{}{"a": {}}, IsPublic: true,
+			}},
+		},
+		{
+			name: "csv ignoring unknown columns",
+			file: "fixture.CSV",
+			data: "Hash,Name,Tags,Ratio,Downloaded,Remove\nabc,Show,a;b,1.5,false,true\n",
+			expected: []config.Torrent{{
+				Hash: "abc", Name: "Show", Ratio: 1.5, State: "stalledDL", Tags: map[string]struct{}{"a": {}, "b": {}},
+				IsPublic: true,
+			}},
+		},
+		{
+			name:    "missing hash",
+			file:    "fixture.json",
+			data:    `[{"Name": "Movie"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid csv value",
+			file:    "fixture.csv",
+			data:    "Hash,Ratio\nabc,high\n",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported format",
+			file:    "fixture.yaml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture, err := parseMockFixture(tt.file, []byte(tt.data))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fixture.Torrents)
+		})
+	}
+}
+
+func TestMockActions(t *testing.T) {
+	ctx := context.Background()
+	c := &Mock{
+		log: logrus.NewEntry(logrus.New()),
+		torrents: map[string]config.Torrent{
+			"a": {Hash: "a", Path: "/downloads", Files: []string{"/downloads/a.mkv"}, Downloaded: true, Seeding: true,
+				Tags: map[string]struct{}{"old": {}}},
+			"b": {Hash: "b", Downloaded: true, Tags: map[string]struct{}{}},
+		},
+		tags: map[string]struct{}{"old": {}},
+	}
+
+	require.NoError(t, c.AddTags(ctx, "a", []string{"new"}))
+	require.NoError(t, c.RemoveTags(ctx, "a", []string{"old"}))
+	require.NoError(t, c.SetTorrentLabel(ctx, "a", "sorted", false))
+	require.NoError(t, c.PauseTorrents(ctx, []string{"a"}))
+	require.NoError(t, c.MoveTorrents(ctx, []string{"a"}, "/archive"))
+
+	torrents, err := c.GetTorrents(ctx)
+	require.NoError(t, err)
+	a := torrents["a"]
+	assert.Equal(t, map[string]struct{}{"new": {}}, a.Tags)
+	assert.Equal(t, "sorted", a.Label)
+	assert.True(t, a.IsPaused())
+	assert.Equal(t, []string{"/archive/a.mkv"}, a.Files)
+
+	// changes of the caller do not affect the torrents of the client
+	a.Tags["caller"] = struct{}{}
+	assert.NotContains(t, c.torrents["a"].Tags, "caller")
+
+	tags, err := c.GetTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new", "old"}, tags)
+
+	removed, err := c.RemoveTorrent(ctx, &config.Torrent{Hash: "b"}, true)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.NotContains(t, c.torrents, "b")
+
+	assert.Error(t, c.AddTags(ctx, "b", []string{"new"}))
+}
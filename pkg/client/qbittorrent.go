@@ -4,30 +4,45 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	qbit "github.com/autobrr/go-qbittorrent"
 	"github.com/dustin/go-humanize"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/autobrr/tqm/pkg/config"
 	"github.com/autobrr/tqm/pkg/evaluate"
 	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
 )
 
 /* Struct */
 
+// defaultFetchConcurrency is the number of torrents whose details are fetched concurrently
+const defaultFetchConcurrency = 10
+
 type QBittorrent struct {
-	Url                       *string `validate:"required"`
+	Url                       *string `validate:"required_without=Socket"`
+	Socket                    string
 	User                      string
 	Password                  string
 	APIKey                    string `koanf:"api_key"`
 	EnableAutoTmmAfterRelabel bool
 	CreateTagsUpfront         bool `koanf:"create_tags_upfront"`
+	FetchConcurrency          int  `koanf:"fetch_concurrency"`
+	// CategoryPaths override the save paths of categories reported by the WebUI, relative paths are relative to the
+	// default save path
+	CategoryPaths map[string]string `koanf:"category_paths"`
+	// FreeSpaceProvider reports the free space instead of the client
+	FreeSpaceProvider *FreeSpaceProviderConfig `koanf:"free_space_provider"`
 
 	// internal
 	log        *logrus.Entry
@@ -41,8 +56,17 @@ type QBittorrent struct {
 	freeSpaceGB  float64
 	freeSpaceSet bool
 
+	// nil unless a free_space_provider is configured
+	freeSpaceProvider *freeSpaceProvider
+
 	// internal compiled filters
 	exp *expression.Expressions
+
+	// nil unless a filter uses TrackerStatusStableFor
+	trackerHistory *trackerStatusHistory
+
+	// nil unless the retrieved data is recorded
+	recording *recording
 }
 
 /* Initializer */
@@ -65,12 +89,31 @@ func NewQBittorrent(name string, exp *expression.Expressions) (TagInterface, err
 		return nil, fmt.Errorf("validate config: %v", errs)
 	}
 
+	tc.trackerHistory = newTrackerStatusHistory(tc.log, name, exp)
+	tc.recording = newRecording(tc.log)
+
+	provider, err := newFreeSpaceProvider(tc.log, tc.FreeSpaceProvider)
+	if err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	tc.freeSpaceProvider = provider
+
+	// when connecting over a unix socket, the url is only used to build request urls
+	host := "http://localhost"
+	if tc.Url != nil {
+		u, err := parseQbitURL(*tc.Url)
+		if err != nil {
+			return nil, fmt.Errorf("parse url: %w", err)
+		}
+		host = u
+	}
+
 	// init client
 	qbl := logrus.New()
 	qbl.Out = io.Discard
 	//tc.client = qbittorrent.NewClient(strings.TrimSuffix(*tc.Url, "/"), qbl)
 	tc.client = qbit.NewClient(qbit.Config{
-		Host:          *tc.Url,
+		Host:          host,
 		Username:      tc.User,
 		Password:      tc.Password,
 		APIKey:        tc.APIKey,
@@ -80,15 +123,57 @@ func NewQBittorrent(name string, exp *expression.Expressions) (TagInterface, err
 		Log:           nil,
 	})
 
+	if tc.Socket != "" {
+		tc.client.WithHTTPClient(httputils.NewUnixSocketHttpClient(tc.Socket, qbit.DefaultTimeout))
+	}
+
 	return &tc, nil
 }
 
+// parseQbitURL validates the WebUI url, keeping any base path (e.g. https://domain.com/qbt/ behind a reverse proxy)
+// and stripping a trailing /api/v2 which the api client adds itself
+func parseQbitURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q, expected http or https: %q", u.Scheme, raw)
+	}
+
+	if u.Host == "" {
+		return "", fmt.Errorf("missing host: %q", raw)
+	}
+
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v2")
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String(), nil
+}
+
 /* Interface  */
 
 func (c *QBittorrent) Type() string {
 	return c.clientType
 }
 
+func (c *QBittorrent) Capabilities() Capabilities {
+	return Capabilities{
+		Tags:        true,
+		Categories:  true,
+		FreeSpace:   true,
+		LabelPaths:  true,
+		SetLocation: true,
+		ShareLimits: true,
+		Recheck:     true,
+		Files:       true,
+		Export:      true,
+	}
+}
+
 func (c *QBittorrent) Connect(context.Context) error {
 	// login
 	if err := c.client.Login(); err != nil {
@@ -117,164 +202,279 @@ func (c *QBittorrent) LoadLabelPathMap(ctx context.Context) error {
 
 	cats, err := c.client.GetCategoriesCtx(ctx)
 	if err != nil {
-		return fmt.Errorf("get categories: %w", err)
+		if len(c.CategoryPaths) == 0 {
+			return fmt.Errorf("get categories: %w", err)
+		}
+		// older WebUI versions do not report categories in the expected format
+		c.log.WithError(err).Warn("Failed retrieving categories, using the configured category_paths only")
 	}
 
-	c.labelPathMap = make(map[string]string)
-	for _, cat := range cats {
-		if cat.SavePath == "" {
-			c.labelPathMap[cat.Name] = filepath.Join(p.SavePath, cat.Name)
-			continue
+	c.labelPathMap = buildLabelPathMap(p.SavePath, cats, c.CategoryPaths)
+	c.recording.recordCategoryPaths(c.labelPathMap)
+	return nil
+}
+
+// buildLabelPathMap resolves the save path of every category, overrides take precedence over the save paths of cats
+// and categories without a save path are saved in a folder named after them in the default save path
+func buildLabelPathMap(defaultSavePath string, cats map[string]qbit.Category, overrides map[string]string) map[string]string {
+	resolve := func(name string, savePath string) string {
+		switch {
+		case savePath == "":
+			return filepath.Join(defaultSavePath, name)
+		case filepath.IsAbs(savePath):
+			return savePath
+		default:
+			return filepath.Join(defaultSavePath, savePath)
 		}
+	}
 
-		if filepath.IsAbs(cat.SavePath) {
-			c.labelPathMap[cat.Name] = cat.SavePath
-			continue
+	labelPathMap := make(map[string]string, len(cats)+len(overrides))
+	for key, cat := range cats {
+		name := cat.Name
+		if name == "" {
+			name = key
 		}
+		labelPathMap[name] = resolve(name, cat.SavePath)
+	}
 
-		c.labelPathMap[cat.Name] = filepath.Join(p.SavePath, cat.SavePath)
+	for name, savePath := range overrides {
+		labelPathMap[name] = resolve(name, savePath)
 	}
 
-	return nil
+	return labelPathMap
+}
+
+// StateDirs returns the folder of incomplete downloads when enabled, its files are not reported as torrent files
+func (c *QBittorrent) StateDirs(ctx context.Context) ([]string, error) {
+	p, err := c.client.GetAppPreferencesCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get app preferences: %w", err)
+	}
+
+	if !p.TempPathEnabled || p.TempPath == "" {
+		return nil, nil
+	}
+
+	return []string{p.TempPath}, nil
 }
 
 func (c *QBittorrent) LabelPathMap() map[string]string {
 	return c.labelPathMap
 }
 
+func (c *QBittorrent) DeleteCategories(ctx context.Context, categories []string) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	if err := c.client.RemoveCategoriesCtx(ctx, categories); err != nil {
+		return fmt.Errorf("delete categories: %v: %w", categories, err)
+	}
+
+	return nil
+}
+
 func (c *QBittorrent) GetTorrents(ctx context.Context) (map[string]config.Torrent, error) {
+	return c.getTorrents(ctx, qbit.TorrentFilterOptions{IncludeTrackers: true})
+}
+
+func (c *QBittorrent) GetTorrentsByHashes(ctx context.Context, hashes []string) (map[string]config.Torrent, error) {
+	return c.getTorrents(ctx, qbit.TorrentFilterOptions{IncludeTrackers: true, Hashes: hashes})
+}
+
+func (c *QBittorrent) getTorrents(ctx context.Context, opts qbit.TorrentFilterOptions) (map[string]config.Torrent, error) {
 	// retrieve torrents from client
 	c.log.Tracef("Retrieving torrents...")
-	ts, err := c.client.GetTorrentsCtx(ctx, qbit.TorrentFilterOptions{IncludeTrackers: true})
+	ts, err := c.client.GetTorrentsCtx(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("get torrents: %w", err)
 	}
 	c.log.Tracef("Retrieved %d torrents", len(ts))
 
-	// build torrent list
-	torrents := make(map[string]config.Torrent)
+	// build torrent list, fetching the details of multiple torrents concurrently
+	var (
+		mu       sync.Mutex
+		torrents = make(map[string]config.Torrent, len(ts))
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.fetchConcurrency())
+
 	for _, t := range ts {
-		// get additional torrent details
-		//td, err := c.client.Torrent.GetProperties(t.Hash)
-		td, err := c.client.GetTorrentPropertiesCtx(ctx, t.Hash)
-		if err != nil {
-			return nil, fmt.Errorf("get torrent properties: %v: %w", t.Hash, err)
-		}
+		g.Go(func() error {
+			torrent, err := c.buildTorrent(gctx, t)
+			if err != nil {
+				return err
+			}
 
-		tf, err := c.client.GetFilesInformationCtx(ctx, t.Hash)
-		if err != nil {
-			return nil, fmt.Errorf("get torrent files: %v: %w", t.Hash, err)
-		}
+			mu.Lock()
+			torrents[t.Hash] = torrent
+			mu.Unlock()
+			return nil
+		})
+	}
 
-		// parse tracker details
-		trackerName := ""
-		trackerStatus := ""
-		allTrackerStatuses := make(map[string]string)
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 
-		var trackers []qbit.TorrentTracker
+	c.trackerHistory.apply(torrents, len(opts.Hashes) == 0, time.Now())
+	c.recording.recordTorrents(torrents, len(opts.Hashes) == 0)
 
-		trackers = t.Trackers
+	return torrents, nil
+}
 
-		// in qBittorrent v5.1+ we can use includeTrackers to populate trackers, but in older versions we need to fetch trackers per torrent
-		if len(t.Trackers) == 0 {
-			ts, err := c.client.GetTorrentTrackersCtx(ctx, t.Hash)
-			if err != nil {
-				return nil, fmt.Errorf("get torrent trackers: %v: %w", t.Hash, err)
-			}
-			trackers = ts
-		}
+// buildTorrent fetches the details (properties, files and trackers) of t and converts it to a config.Torrent
+func (c *QBittorrent) buildTorrent(ctx context.Context, t qbit.Torrent) (config.Torrent, error) {
+	// get additional torrent details
+	//td, err := c.client.Torrent.GetProperties(t.Hash)
+	td, err := c.client.GetTorrentPropertiesCtx(ctx, t.Hash)
+	if err != nil {
+		return config.Torrent{}, fmt.Errorf("get torrent properties: %v: %w", t.Hash, err)
+	}
 
-		firstTrackerSet := false
-		for _, tr := range trackers {
-			// skip disabled trackers
-			if strings.Contains(tr.Url, "[DHT]") || strings.Contains(tr.Url, "[LSD]") ||
-				strings.Contains(tr.Url, "[PeX]") {
-				continue
-			}
+	tf, err := c.client.GetFilesInformationCtx(ctx, t.Hash)
+	if err != nil {
+		return config.Torrent{}, fmt.Errorf("get torrent files: %v: %w", t.Hash, err)
+	}
 
-			// Store all tracker statuses
-			allTrackerStatuses[tr.Url] = tr.Message
+	// parse tracker details
+	trackerName := ""
+	trackerHost := ""
+	trackerStatus := ""
+	trackerStatusCode := config.TrackerStatusCodeDisabled
+	allTrackerStatuses := make(map[string]string)
+	allTrackerStatusCodes := make(map[string]int)
 
-			// Keep first tracker for backward compatibility
-			if !firstTrackerSet {
-				trackerName = config.ParseTrackerDomain(tr.Url)
-				trackerStatus = tr.Message
-				firstTrackerSet = true
+	var trackers []qbit.TorrentTracker
+
+	trackers = t.Trackers
+
+	// in qBittorrent v5.1+ we can use includeTrackers to populate trackers, but in older versions we need to fetch trackers per torrent
+	if len(t.Trackers) == 0 {
+		ts, err := c.client.GetTorrentTrackersCtx(ctx, t.Hash)
+		if err != nil {
+			return config.Torrent{}, fmt.Errorf("get torrent trackers: %v: %w", t.Hash, err)
+		}
+		trackers = ts
+	}
+
+	dhtEnabled, pexEnabled := false, false
+	firstTrackerSet := false
+	for _, tr := range trackers {
+		// skip peer sources, noting whether they are enabled
+		if strings.Contains(tr.Url, "[DHT]") || strings.Contains(tr.Url, "[LSD]") ||
+			strings.Contains(tr.Url, "[PeX]") {
+			enabled := tr.Status != qbit.TrackerStatusDisabled
+			switch {
+			case strings.Contains(tr.Url, "[DHT]"):
+				dhtEnabled = enabled
+			case strings.Contains(tr.Url, "[PeX]"):
+				pexEnabled = enabled
 			}
+			continue
+		}
+
+		// Store all tracker statuses
+		allTrackerStatuses[tr.Url] = tr.Message
+		allTrackerStatusCodes[tr.Url] = int(tr.Status)
+
+		// Keep first tracker for backward compatibility
+		if !firstTrackerSet {
+			trackerName = config.ParseTrackerDomain(tr.Url)
+			trackerHost = config.ParseTrackerHost(tr.Url)
+			trackerStatus = tr.Message
+			trackerStatusCode = int(tr.Status)
+			firstTrackerSet = true
 		}
+	}
 
-		// added time
-		addedTimeSecs := int64(time.Since(time.Unix(int64(td.AdditionDate), 0)).Seconds())
+	// added time
+	addedTimeSecs := int64(time.Since(time.Unix(int64(td.AdditionDate), 0)).Seconds())
 
-		seedingTime := time.Duration(td.SeedingTime) * time.Second
+	seedingTime := time.Duration(td.SeedingTime) * time.Second
 
-		// last activity time
-		lastActivitySecs := max(
-			int64(time.Since(time.Unix(t.LastActivity, 0)).Seconds()), 0)
+	// last activity time
+	lastActivitySecs := max(
+		int64(time.Since(time.Unix(t.LastActivity, 0)).Seconds()), 0)
 
-		// torrent files
-		var files []string
-		for _, f := range *tf {
-			files = append(files, filepath.Join(td.SavePath, f.Name))
-		}
+	// torrent files
+	var files []string
+	for _, f := range *tf {
+		files = append(files, filepath.Join(td.SavePath, f.Name))
+	}
 
-		// create torrent
-		tags := make(map[string]struct{})
-		if t.Tags != "" {
-			for _, tag := range strings.Split(t.Tags, ", ") {
-				tags[tag] = struct{}{}
-			}
-		}
-		torrent := config.Torrent{
-			Hash:            t.Hash,
-			Name:            t.Name,
-			Path:            td.SavePath,
-			TotalBytes:      t.Size,
-			DownloadedBytes: td.TotalDownloaded,
-			State:           string(t.State),
-			Files:           files,
-			Tags:            tags,
-			Downloaded: !evaluate.StringSliceContains([]string{
-				"downloading",
-				"stalledDL",
-				"queuedDL",
-				"pausedDL",
-				"checkingDL",
-			}, string(t.State), true),
-			Seeding: evaluate.StringSliceContains([]string{
-				"uploading",
-				"stalledUP",
-			}, string(t.State), true),
-			Ratio:               float32(td.ShareRatio),
-			AddedSeconds:        addedTimeSecs,
-			AddedHours:          float32(addedTimeSecs) / 60 / 60,
-			AddedDays:           float32(addedTimeSecs) / 60 / 60 / 24,
-			SeedingSeconds:      int64(seedingTime.Seconds()),
-			SeedingHours:        float32(seedingTime.Seconds()) / 60 / 60,
-			SeedingDays:         float32(seedingTime.Seconds()) / 60 / 60 / 24,
-			LastActivitySeconds: lastActivitySecs,
-			LastActivityHours:   float32(lastActivitySecs) / 60 / 60,
-			LastActivityDays:    float32(lastActivitySecs) / 60 / 60 / 24,
-			UpLimit:             int64(td.UpLimit),
-			Label:               t.Category,
-			Seeds:               int64(td.SeedsTotal),
-			Peers:               int64(td.PeersTotal),
-			IsPrivate:           td.IsPrivate,
-			IsPublic:            !td.IsPrivate,
-			// free space
-			FreeSpaceGB:  c.GetFreeSpace,
-			FreeSpaceSet: c.freeSpaceSet,
-			// tracker
-			TrackerName:        trackerName,
-			TrackerStatus:      trackerStatus,
-			AllTrackerStatuses: allTrackerStatuses,
-			Comment:            td.Comment,
+	// create torrent
+	tags := make(map[string]struct{})
+	if t.Tags != "" {
+		for _, tag := range strings.Split(t.Tags, ", ") {
+			tags[tag] = struct{}{}
 		}
+	}
+	torrent := config.Torrent{
+		Hash:            t.Hash,
+		Name:            t.Name,
+		Path:            td.SavePath,
+		TotalBytes:      t.Size,
+		DownloadedBytes: td.TotalDownloaded,
+		State:           string(t.State),
+		Files:           files,
+		Tags:            tags,
+		Downloaded: !evaluate.StringSliceContains([]string{
+			"downloading",
+			"stalledDL",
+			"queuedDL",
+			"pausedDL",
+			"checkingDL",
+		}, string(t.State), true),
+		Seeding: evaluate.StringSliceContains([]string{
+			"uploading",
+			"stalledUP",
+		}, string(t.State), true),
+		Ratio:               float32(td.ShareRatio),
+		AddedSeconds:        addedTimeSecs,
+		AddedHours:          float32(addedTimeSecs) / 60 / 60,
+		AddedDays:           float32(addedTimeSecs) / 60 / 60 / 24,
+		SeedingSeconds:      int64(seedingTime.Seconds()),
+		SeedingHours:        float32(seedingTime.Seconds()) / 60 / 60,
+		SeedingDays:         float32(seedingTime.Seconds()) / 60 / 60 / 24,
+		LastActivitySeconds: lastActivitySecs,
+		LastActivityHours:   float32(lastActivitySecs) / 60 / 60,
+		LastActivityDays:    float32(lastActivitySecs) / 60 / 60 / 24,
+		UpLimit:             int64(td.UpLimit),
+		// share limits
+		RatioLimit:               t.RatioLimit,
+		SeedingTimeLimit:         t.SeedingTimeLimit,
+		InactiveSeedingTimeLimit: t.InactiveSeedingTimeLimit,
+		Label:                    t.Category,
+		Seeds:                    int64(td.SeedsTotal),
+		Peers:                    int64(td.PeersTotal),
+		IsPrivate:                td.IsPrivate,
+		IsPublic:                 !td.IsPrivate,
+		DHTEnabled:               dhtEnabled,
+		PeXEnabled:               pexEnabled,
+		// free space
+		FreeSpaceGB:  c.GetFreeSpace,
+		FreeSpaceSet: c.freeSpaceSet,
+		// tracker
+		TrackerName:           trackerName,
+		TrackerHost:           trackerHost,
+		TrackerStatus:         trackerStatus,
+		AllTrackerStatuses:    allTrackerStatuses,
+		TrackerStatusCode:     trackerStatusCode,
+		AllTrackerStatusCodes: allTrackerStatusCodes,
+		Comment:               td.Comment,
+	}
+
+	return torrent, nil
+}
 
-		torrents[t.Hash] = torrent
+func (c *QBittorrent) fetchConcurrency() int {
+	if c.FetchConcurrency > 0 {
+		return c.FetchConcurrency
 	}
 
-	return torrents, nil
+	return defaultFetchConcurrency
 }
 
 func (c *QBittorrent) RemoveTorrent(ctx context.Context, torrent *config.Torrent, deleteData bool) (bool, error) {
@@ -367,6 +567,37 @@ func (c *QBittorrent) SetTorrentLabel(ctx context.Context, hash string, label st
 	return nil
 }
 
+func (c *QBittorrent) AddTorrent(ctx context.Context, data []byte, opts AddTorrentOptions) error {
+	addOpts := &qbit.TorrentAddOptions{
+		Paused:        opts.Paused,
+		SkipHashCheck: opts.SkipRecheck,
+		Category:      opts.Label,
+		Tags:          strings.Join(opts.Tags, ","),
+		SavePath:      opts.SavePath,
+	}
+
+	var err error
+	if isMagnetLink(data) {
+		_, err = c.client.AddTorrentFromUrlCtx(ctx, strings.TrimSpace(string(data)), addOpts.Prepare())
+	} else {
+		_, err = c.client.AddTorrentFromMemoryCtx(ctx, data, addOpts.Prepare())
+	}
+	if err != nil {
+		return fmt.Errorf("add torrent: %w", err)
+	}
+
+	return nil
+}
+
+func (c *QBittorrent) ExportTorrent(ctx context.Context, hash string) ([]byte, error) {
+	data, err := c.client.ExportTorrentCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("export torrent %s: %w", hash, err)
+	}
+
+	return data, nil
+}
+
 func (c *QBittorrent) SetUploadLimit(ctx context.Context, hash string, limit int64) error {
 	err := c.client.SetTorrentUploadLimitCtx(ctx, []string{hash}, limit)
 	if err != nil {
@@ -377,18 +608,43 @@ func (c *QBittorrent) SetUploadLimit(ctx context.Context, hash string, limit int
 	return nil
 }
 
-func (c *QBittorrent) GetCurrentFreeSpace(ctx context.Context, path string) (int64, error) {
-	// get current main stats
-	data, err := c.client.SyncMainDataCtx(ctx, 0)
+func (c *QBittorrent) SetShareLimits(ctx context.Context, hash string, limits ShareLimits) error {
+	err := c.client.SetTorrentShareLimitCtx(ctx, []string{hash}, qbit.ShareLimitOptions{
+		RatioLimit:               limits.RatioLimit,
+		SeedingTimeLimit:         limits.SeedingTimeLimit,
+		InactiveSeedingTimeLimit: limits.InactiveSeedingTimeLimit,
+	})
 	if err != nil {
-		return 0, fmt.Errorf("get main data: %w", err)
+		return fmt.Errorf("set share limits for %s: %w", hash, err)
+	}
+
+	c.log.Debugf("Set share limits for torrent %s to ratio: %.2f / seeding time: %d min / inactive seeding time: %d min",
+		hash, limits.RatioLimit, limits.SeedingTimeLimit, limits.InactiveSeedingTimeLimit)
+	return nil
+}
+
+func (c *QBittorrent) GetCurrentFreeSpace(ctx context.Context, path string) (int64, error) {
+	var space int64
+	if c.freeSpaceProvider != nil {
+		var err error
+		if space, err = c.freeSpaceProvider.FreeSpace(ctx); err != nil {
+			return 0, err
+		}
+	} else {
+		// get current main stats
+		data, err := c.client.SyncMainDataCtx(ctx, 0)
+		if err != nil {
+			return 0, fmt.Errorf("get main data: %w", err)
+		}
+		space = data.ServerState.FreeSpaceOnDisk
 	}
 
 	// set internal free size
-	c.freeSpaceGB = float64(data.ServerState.FreeSpaceOnDisk) / humanize.GiByte
+	c.freeSpaceGB = float64(space) / humanize.GiByte
 	c.freeSpaceSet = true
+	c.recording.recordFreeSpace(space)
 
-	return data.ServerState.FreeSpaceOnDisk, nil
+	return space, nil
 }
 
 func (c *QBittorrent) AddFreeSpace(bytes int64) {
@@ -445,6 +701,23 @@ func (c *QBittorrent) ShouldRelabel(ctx context.Context, t *config.Torrent) (str
 	return "", false, nil
 }
 
+func (c *QBittorrent) ShouldMove(ctx context.Context, t *config.Torrent) (string, bool, error) {
+	for _, move := range c.exp.Moves {
+		// check update
+		match, err := expression.CheckTorrentAllMatch(ctx, t, move.Updates)
+		if err != nil {
+			return "", false, fmt.Errorf("check move expression: %v: %w", t.Hash, err)
+		} else if !match {
+			continue
+		}
+
+		// we should move
+		return move.Path, true, nil
+	}
+
+	return "", false, nil
+}
+
 func (c *QBittorrent) CheckTorrentPause(ctx context.Context, t *config.Torrent) (bool, error) {
 	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Pauses)
 	if err != nil {
@@ -461,14 +734,128 @@ func (c *QBittorrent) PauseTorrents(ctx context.Context, hashes []string) error
 	return nil
 }
 
+func (c *QBittorrent) CheckTorrentResume(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Resumes)
+	if err != nil {
+		return false, fmt.Errorf("check resume expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *QBittorrent) ResumeTorrents(ctx context.Context, hashes []string) error {
+	if err := c.client.ResumeCtx(ctx, hashes); err != nil {
+		return fmt.Errorf("resume torrents: %v: %w", hashes, err)
+	}
+	return nil
+}
+
+func (c *QBittorrent) CheckTorrentReannounce(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Reannounces)
+	if err != nil {
+		return false, fmt.Errorf("check reannounce expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *QBittorrent) ReannounceTorrents(ctx context.Context, hashes []string) error {
+	if err := c.client.ReAnnounceTorrentsCtx(ctx, hashes); err != nil {
+		return fmt.Errorf("reannounce torrents: %v: %w", hashes, err)
+	}
+	return nil
+}
+
+func (c *QBittorrent) MoveTorrents(ctx context.Context, hashes []string, path string) error {
+	if err := c.client.SetLocationCtx(ctx, hashes, path); err != nil {
+		return fmt.Errorf("move torrents: %v: %q: %w", hashes, path, err)
+	}
+	return nil
+}
+
+func (c *QBittorrent) CheckTorrentRecheck(ctx context.Context, t *config.Torrent) (bool, error) {
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, c.exp.Rechecks)
+	if err != nil {
+		return false, fmt.Errorf("check recheck expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *QBittorrent) RecheckTorrents(ctx context.Context, hashes []string) error {
+	if err := c.client.RecheckCtx(ctx, hashes); err != nil {
+		return fmt.Errorf("recheck torrents: %v: %w", hashes, err)
+	}
+	return nil
+}
+
+func (c *QBittorrent) ShouldPruneFiles(ctx context.Context, t *config.Torrent) (bool, error) {
+	return checkTorrentPruneFiles(ctx, c.exp, t)
+}
+
+// checkTorrentPruneFiles reports whether the files of t are pruned, all torrents are when exp selects none
+func checkTorrentPruneFiles(ctx context.Context, exp *expression.Expressions, t *config.Torrent) (bool, error) {
+	if len(exp.PruneFiles) == 0 {
+		return true, nil
+	}
+
+	match, err := expression.CheckTorrentSingleMatch(ctx, t, exp.PruneFiles)
+	if err != nil {
+		return false, fmt.Errorf("check prune_files expression: %v: %w", t.Hash, err)
+	}
+
+	return match, nil
+}
+
+func (c *QBittorrent) GetTorrentFiles(ctx context.Context, hash string) ([]TorrentFile, error) {
+	tf, err := c.client.GetFilesInformationCtx(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get torrent files: %v: %w", hash, err)
+	}
+
+	files := make([]TorrentFile, 0, len(*tf))
+	for _, f := range *tf {
+		file := TorrentFile{
+			Index:    f.Index,
+			Name:     f.Name,
+			Size:     f.Size,
+			Progress: float64(f.Progress),
+			Skipped:  f.Priority == 0,
+		}
+		if len(f.PieceRange) == 2 {
+			file.FirstPiece, file.LastPiece = f.PieceRange[0], f.PieceRange[1]
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func (c *QBittorrent) SkipFiles(ctx context.Context, hash string, indexes []int) error {
+	ids := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	if err := c.client.SetFilePriorityCtx(ctx, hash, strings.Join(ids, "|"), 0); err != nil {
+		return fmt.Errorf("skip files: %v: %v: %w", hash, indexes, err)
+	}
+	return nil
+}
+
 func (c *QBittorrent) ShouldRetag(ctx context.Context, t *config.Torrent) (RetagInfo, error) {
+	return checkTorrentRetag(ctx, c.exp, t)
+}
+
+// checkTorrentRetag evaluates the tag rules of exp against t
+func checkTorrentRetag(ctx context.Context, exp *expression.Expressions, t *config.Torrent) (RetagInfo, error) {
 	retagInfo := RetagInfo{
 		Add:    make(map[string]struct{}),
 		Remove: make(map[string]struct{}),
 	}
 	var uploadLimitSet = false
 
-	for _, tagRule := range c.exp.Tags {
+	for _, tagRule := range exp.Tags {
 		// check update
 		match, err := expression.CheckTorrentAllMatch(ctx, t, tagRule.Updates)
 		if err != nil {
@@ -499,6 +886,61 @@ func (c *QBittorrent) ShouldRetag(ctx context.Context, t *config.Torrent) (Retag
 	return retagInfo, nil
 }
 
+func (c *QBittorrent) ShouldSetShareLimits(ctx context.Context, t *config.Torrent) (*ShareLimits, error) {
+	return checkTorrentShareLimits(ctx, c.exp, t)
+}
+
+// checkTorrentShareLimits returns the share limits of the first seed limit rule of exp matching t, nil when none
+// matches or t already has its limits
+func checkTorrentShareLimits(ctx context.Context, exp *expression.Expressions, t *config.Torrent) (*ShareLimits, error) {
+	for _, rule := range exp.SeedLimits {
+		// check update
+		match, err := expression.CheckTorrentAllMatch(ctx, t, rule.Updates)
+		if err != nil {
+			return nil, fmt.Errorf("check update expression for seed limit %s on torrent %v: %w", rule.Name, t.Hash, err)
+		} else if !match {
+			continue
+		}
+
+		// unset limits keep the current torrent value
+		limits := &ShareLimits{
+			Name:                     rule.Name,
+			RatioLimit:               t.RatioLimit,
+			SeedingTimeLimit:         t.SeedingTimeLimit,
+			InactiveSeedingTimeLimit: t.InactiveSeedingTimeLimit,
+		}
+
+		if rule.Ratio != nil {
+			limits.RatioLimit = *rule.Ratio
+		}
+		if rule.SeedingTime != nil {
+			limits.SeedingTimeLimit = durationToLimitMinutes(*rule.SeedingTime)
+		}
+		if rule.InactiveSeedingTime != nil {
+			limits.InactiveSeedingTimeLimit = durationToLimitMinutes(*rule.InactiveSeedingTime)
+		}
+
+		if limits.RatioLimit == t.RatioLimit && limits.SeedingTimeLimit == t.SeedingTimeLimit &&
+			limits.InactiveSeedingTimeLimit == t.InactiveSeedingTimeLimit {
+			// torrent already has the correct limits
+			return nil, nil
+		}
+
+		return limits, nil
+	}
+
+	return nil, nil
+}
+
+// durationToLimitMinutes converts a configured duration to a limit in minutes, negative durations are treated as unlimited
+func durationToLimitMinutes(d time.Duration) int64 {
+	if d < 0 {
+		return ShareLimitUnlimited
+	}
+
+	return int64(d.Minutes())
+}
+
 func (c *QBittorrent) AddTags(ctx context.Context, hash string, tags []string) error {
 	if len(tags) == 0 {
 		return nil
@@ -531,6 +973,15 @@ func (c *QBittorrent) SetTags(ctx context.Context, hash string, tags []string) e
 	return nil
 }
 
+func (c *QBittorrent) GetTags(ctx context.Context) ([]string, error) {
+	tags, err := c.client.GetTagsCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
 func (c *QBittorrent) CreateTags(ctx context.Context, tags []string) error {
 	if len(tags) == 0 {
 		return nil
@@ -1,11 +1,19 @@
 package client
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/autobrr/go-qbittorrent"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/autobrr/tqm/pkg/config"
 )
@@ -301,3 +309,295 @@ func TestTagDeduplication(t *testing.T) {
 		})
 	}
 }
+
+func TestDurationToLimitMinutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected int64
+	}{
+		{name: "zero", duration: 0, expected: 0},
+		{name: "fourteen_days", duration: 336 * time.Hour, expected: 20160},
+		{name: "partial_minute_truncated", duration: 90 * time.Second, expected: 1},
+		{name: "negative_is_unlimited", duration: -1, expected: ShareLimitUnlimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, durationToLimitMinutes(tt.duration))
+		})
+	}
+}
+
+func TestParseQbitURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+		wantErr  bool
+	}{
+		{name: "host_only", url: "http://localhost:8080", expected: "http://localhost:8080"},
+		{name: "trailing_slash", url: "https://qbittorrent.domain.com/", expected: "https://qbittorrent.domain.com"},
+		{name: "base_path", url: "https://domain.com/qbt/", expected: "https://domain.com/qbt"},
+		{name: "nested_base_path", url: "https://domain.com/apps/qbt", expected: "https://domain.com/apps/qbt"},
+		{name: "api_suffix_stripped", url: "https://domain.com/qbt/api/v2/", expected: "https://domain.com/qbt"},
+		{name: "whitespace", url: " http://localhost:8080/ ", expected: "http://localhost:8080"},
+		{name: "missing_scheme", url: "localhost:8080", wantErr: true},
+		{name: "missing_host", url: "http:///qbt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := parseQbitURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, u)
+		})
+	}
+}
+
+func TestBuildLabelPathMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		cats      map[string]qbittorrent.Category
+		overrides map[string]string
+		want      map[string]string
+	}{
+		{
+			name: "save_paths_of_categories",
+			cats: map[string]qbittorrent.Category{
+				"movies": {Name: "movies", SavePath: "/data/movies"},
+				"tv":     {Name: "tv", SavePath: "shows"},
+				"music":  {Name: "music"},
+			},
+			want: map[string]string{
+				"movies": "/data/movies",
+				"tv":     "/downloads/shows",
+				"music":  "/downloads/music",
+			},
+		},
+		{
+			name: "categories_without_name",
+			cats: map[string]qbittorrent.Category{
+				"movies": {},
+			},
+			want: map[string]string{
+				"movies": "/downloads/movies",
+			},
+		},
+		{
+			name: "overrides_take_precedence",
+			cats: map[string]qbittorrent.Category{
+				"movies": {Name: "movies"},
+				"tv":     {Name: "tv", SavePath: "/data/tv"},
+			},
+			overrides: map[string]string{
+				"movies": "/mnt/movies",
+				"tv":     "series",
+				"books":  "/mnt/books",
+			},
+			want: map[string]string{
+				"movies": "/mnt/movies",
+				"tv":     "/downloads/series",
+				"books":  "/mnt/books",
+			},
+		},
+		{
+			name: "overrides_only",
+			overrides: map[string]string{
+				"movies": "/mnt/movies",
+			},
+			want: map[string]string{
+				"movies": "/mnt/movies",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, buildLabelPathMap("/downloads", tt.cats, tt.overrides))
+		})
+	}
+}
+
+func TestQBittorrent_AddTorrent(t *testing.T) {
+	const magnet = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=Some.Torrent"
+
+	tests := []struct {
+		name         string
+		data         []byte
+		opts         AddTorrentOptions
+		status       int
+		expectedForm map[string]string
+		expectedFile string
+		expectedErr  bool
+	}{
+		{
+			name: "file",
+			data: []byte("d8:announce0:e"),
+			opts: AddTorrentOptions{Paused: true, SkipRecheck: true, Label: "movies", Tags: []string{"restored", "tqm"},
+				SavePath: "/downloads/movies"},
+			status: http.StatusOK,
+			expectedForm: map[string]string{"category": "movies", "tags": "restored,tqm", "paused": "true",
+				"stopped": "true", "skip_checking": "true", "savepath": "/downloads/movies", "urls": ""},
+			expectedFile: "d8:announce0:e",
+		},
+		{
+			name:   "magnet",
+			data:   []byte(magnet + "\n"),
+			opts:   AddTorrentOptions{Label: "tv", Tags: []string{"restored"}},
+			status: http.StatusOK,
+			expectedForm: map[string]string{"category": "tv", "tags": "restored", "paused": "false", "urls": magnet,
+				"savepath": ""},
+		},
+		{
+			name:        "client_error",
+			data:        []byte("d8:announce0:e"),
+			status:      http.StatusUnsupportedMediaType,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				form map[string]string
+				file string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v2/auth/login":
+					_, _ = io.WriteString(w, "Ok.")
+				case "/api/v2/torrents/add":
+					if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+						require.NoError(t, r.ParseMultipartForm(1<<20))
+						if f, _, err := r.FormFile("torrents"); err == nil {
+							data, _ := io.ReadAll(f)
+							file = string(data)
+						}
+					} else {
+						require.NoError(t, r.ParseForm())
+					}
+
+					form = make(map[string]string)
+					for key := range tt.expectedForm {
+						form[key] = r.FormValue(key)
+					}
+
+					w.Header().Set("Content-Type", "text/plain")
+					w.WriteHeader(tt.status)
+					_, _ = io.WriteString(w, "Ok.")
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			c := &QBittorrent{
+				log:    logrus.NewEntry(logrus.New()),
+				client: qbittorrent.NewClient(qbittorrent.Config{Host: srv.URL}),
+			}
+
+			err := c.AddTorrent(context.Background(), tt.data, tt.opts)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedForm, form)
+			assert.Equal(t, tt.expectedFile, file)
+		})
+	}
+}
+
+func TestIsMagnetLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected bool
+	}{
+		{name: "magnet", data: "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567", expected: true},
+		{name: "magnet_whitespace", data: "  magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567\n", expected: true},
+		{name: "torrent_file", data: "d8:announce0:e", expected: false},
+		{name: "empty", data: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isMagnetLink([]byte(tt.data)))
+		})
+	}
+}
+
+func TestQBittorrent_GetTorrents(t *testing.T) {
+	hashes := []string{"aaaa", "bbbb", "cccc", "dddd", "eeee"}
+
+	tests := []struct {
+		name        string
+		failHash    string
+		expectedErr bool
+	}{
+		{name: "merges_results"},
+		{name: "one_request_fails", failHash: "cccc", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hash := r.FormValue("hash")
+
+				switch r.URL.Path {
+				case "/api/v2/auth/login":
+					_, _ = io.WriteString(w, "Ok.")
+				case "/api/v2/torrents/info":
+					var infos []string
+					for _, h := range hashes {
+						infos = append(infos, fmt.Sprintf(`{"hash":%q,"name":"name-%s","size":100,"state":"uploading",`+
+							`"trackers":[{"url":"https://tracker.example.com/announce","status":2,"msg":"Working"}]}`, h, h))
+					}
+					_, _ = io.WriteString(w, "["+strings.Join(infos, ",")+"]")
+				case "/api/v2/torrents/properties":
+					if hash == tt.failHash {
+						w.WriteHeader(http.StatusConflict)
+						return
+					}
+					_, _ = fmt.Fprintf(w, `{"save_path":"/downloads/%s","total_downloaded":100}`, hash)
+				case "/api/v2/torrents/files":
+					_, _ = fmt.Fprintf(w, `[{"name":"%s.mkv","size":100}]`, hash)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			c := &QBittorrent{
+				FetchConcurrency: 2,
+				log:              logrus.NewEntry(logrus.New()),
+				client:           qbittorrent.NewClient(qbittorrent.Config{Host: srv.URL}),
+			}
+
+			torrents, err := c.GetTorrents(context.Background())
+			if tt.expectedErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.failHash)
+				assert.Nil(t, torrents)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, torrents, len(hashes))
+			for _, h := range hashes {
+				torrent, ok := torrents[h]
+				require.True(t, ok, "torrent %s missing", h)
+				assert.Equal(t, "name-"+h, torrent.Name)
+				assert.Equal(t, "/downloads/"+h, torrent.Path)
+				assert.Equal(t, []string{"/downloads/" + h + "/" + h + ".mkv"}, torrent.Files)
+				assert.Equal(t, "example.com", torrent.TrackerName)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// RecheckInterface is implemented by clients that can force a recheck of torrent data
+type RecheckInterface interface {
+	Interface
+
+	CheckTorrentRecheck(ctx context.Context, t *config.Torrent) (bool, error)
+	RecheckTorrents(ctx context.Context, hashes []string) error
+}
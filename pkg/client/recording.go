@@ -0,0 +1,164 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+var (
+	// recordPath is the fixture the data retrieved from clients is written to, nothing is recorded when empty
+	recordPath string
+	// replayPath is the fixture served by NewClient instead of connecting to clients, clients are used when empty
+	replayPath string
+)
+
+// Record makes clients write the torrents, free space and category paths they retrieve to path, as a fixture which
+// can be replayed with Replay or served by a mock client
+func Record(path string) {
+	recordPath = path
+}
+
+// Replay makes NewClient return mock clients serving the fixture at path instead of connecting to the torrent clients
+func Replay(path string) {
+	replayPath = path
+}
+
+// newReplayMock returns a mock client serving the fixture replayed for the client name
+func newReplayMock(name string, exp *expression.Expressions) TagInterface {
+	file := replayPath
+	return &Mock{
+		File:       &file,
+		log:        logger.GetLogger(name),
+		clientType: "Mock",
+		exp:        exp,
+	}
+}
+
+// mockFixture is a recorded fixture, the mock client also accepts fixtures only listing torrents
+type mockFixture struct {
+	FreeSpaceGB   *float64          `json:"free_space_gb,omitempty"`
+	CategoryPaths map[string]string `json:"category_paths,omitempty"`
+	Torrents      []config.Torrent  `json:"torrents"`
+}
+
+// recording writes the data retrieved from a client to a fixture, recording methods are no-ops on nil
+type recording struct {
+	log      *logrus.Entry
+	path     string
+	fixture  mockFixture
+	torrents map[string]config.Torrent
+}
+
+// newRecording returns nil unless Record was called
+func newRecording(log *logrus.Entry) *recording {
+	if recordPath == "" {
+		return nil
+	}
+
+	return &recording{
+		log:      log,
+		path:     recordPath,
+		torrents: make(map[string]config.Torrent),
+	}
+}
+
+// recordTorrents records the retrieved torrents, when complete is set they are all torrents of the client and
+// previously recorded torrents are dropped
+func (r *recording) recordTorrents(torrents map[string]config.Torrent, complete bool) {
+	if r == nil {
+		return
+	}
+
+	if complete {
+		r.torrents = make(map[string]config.Torrent, len(torrents))
+	}
+
+	for h, t := range torrents {
+		r.torrents[h] = redactTorrent(t)
+	}
+
+	r.save()
+}
+
+// recordFreeSpace records the retrieved free space in bytes
+func (r *recording) recordFreeSpace(space int64) {
+	if r == nil {
+		return
+	}
+
+	gb := float64(space) / humanize.GiByte
+	r.fixture.FreeSpaceGB = &gb
+	r.save()
+}
+
+// recordCategoryPaths records the save paths of the categories
+func (r *recording) recordCategoryPaths(categoryPaths map[string]string) {
+	if r == nil {
+		return
+	}
+
+	r.fixture.CategoryPaths = maps.Clone(categoryPaths)
+	r.save()
+}
+
+func (r *recording) save() {
+	r.fixture.Torrents = make([]config.Torrent, 0, len(r.torrents))
+	for _, h := range slices.Sorted(maps.Keys(r.torrents)) {
+		r.fixture.Torrents = append(r.fixture.Torrents, r.torrents[h])
+	}
+
+	data, err := json.MarshalIndent(r.fixture, "", "  ")
+	if err != nil {
+		r.log.WithError(err).Warn("Failed encoding recording")
+		return
+	}
+
+	if err := paths.WriteFileAtomic(r.path, data); err != nil {
+		r.log.WithError(err).Warnf("Failed writing recording: %q", r.path)
+	}
+}
+
+// redactTorrent removes the secrets of t, the announce urls of trackers commonly contain a passkey
+func redactTorrent(t config.Torrent) config.Torrent {
+	if len(t.AllTrackerStatuses) > 0 {
+		statuses := make(map[string]string, len(t.AllTrackerStatuses))
+		for u, status := range t.AllTrackerStatuses {
+			statuses[redactTrackerURL(u)] = status
+		}
+		t.AllTrackerStatuses = statuses
+	}
+
+	if len(t.AllTrackerStatusCodes) > 0 {
+		codes := make(map[string]int, len(t.AllTrackerStatusCodes))
+		for u, code := range t.AllTrackerStatusCodes {
+			codes[redactTrackerURL(u)] = code
+		}
+		t.AllTrackerStatusCodes = codes
+	}
+
+	return t
+}
+
+// redactTrackerURL returns the scheme and host of a tracker url, dropping credentials, the path and the query
+func redactTrackerURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		// not an url, keep the host only
+		host, _, _ := strings.Cut(raw, "/")
+		return host
+	}
+
+	return fmt.Sprintf("%s://%s/", u.Scheme, u.Host)
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+const (
+	// ShareLimitGlobal uses the global share limit of the client
+	ShareLimitGlobal = -2
+	// ShareLimitUnlimited disables the share limit
+	ShareLimitUnlimited = -1
+)
+
+type ShareLimits struct {
+	Name                     string
+	RatioLimit               float64
+	SeedingTimeLimit         int64 // minutes
+	InactiveSeedingTimeLimit int64 // minutes
+}
+
+type ShareLimitInterface interface {
+	Interface
+
+	ShouldSetShareLimits(ctx context.Context, t *config.Torrent) (*ShareLimits, error)
+	SetShareLimits(ctx context.Context, hash string, limits ShareLimits) error
+}
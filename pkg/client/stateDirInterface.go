@@ -0,0 +1,12 @@
+package client
+
+import (
+	"context"
+)
+
+// StateDirInterface is implemented by clients whose internal directories (e.g. incomplete downloads) can be retrieved
+type StateDirInterface interface {
+	Interface
+
+	StateDirs(ctx context.Context) ([]string, error)
+}
@@ -19,6 +19,7 @@ type TagInterface interface {
 	AddTags(ctx context.Context, hash string, tags []string) error
 	RemoveTags(ctx context.Context, hash string, tags []string) error
 	SetTags(ctx context.Context, hash string, tags []string) error
+	GetTags(ctx context.Context) ([]string, error)
 	CreateTags(ctx context.Context, tags []string) error
 	DeleteTags(ctx context.Context, tags []string) error
 }
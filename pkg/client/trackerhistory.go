@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// trackerStatusHistorySize is the number of tracker status messages kept per torrent
+const trackerStatusHistorySize = 10
+
+type trackerStatusEntry struct {
+	Status string    `json:"status"`
+	Since  time.Time `json:"since"`
+}
+
+// trackerStatusHistory persists the recent tracker status messages of the torrents of a client,
+// so filters can tell a stable tracker status from a flapping one
+type trackerStatusHistory struct {
+	log  *logrus.Entry
+	path string
+}
+
+// newTrackerStatusHistory returns nil when no filter expression uses TrackerStatusStableFor,
+// trackerStatusHistory methods are no-ops on nil
+func newTrackerStatusHistory(log *logrus.Entry, name string, exp *expression.Expressions) *trackerStatusHistory {
+	if exp == nil || !exp.Uses("TrackerStatusStableFor") {
+		return nil
+	}
+
+	return &trackerStatusHistory{
+		log:  log,
+		path: config.StatePath(fmt.Sprintf("tracker-status.%s.json", name)),
+	}
+}
+
+// apply records the current tracker status of torrents and sets their TrackerStatusSince. When complete is set,
+// torrents are all torrents of the client and the history of torrents no longer in the client is dropped.
+func (h *trackerStatusHistory) apply(torrents map[string]config.Torrent, complete bool, now time.Time) {
+	if h == nil {
+		return
+	}
+
+	history, err := h.load()
+	if err != nil {
+		h.log.WithError(err).Warn("Failed loading tracker status history, starting a new one")
+		history = make(map[string][]trackerStatusEntry)
+	}
+
+	for hash, t := range torrents {
+		entries := history[hash]
+		if len(entries) == 0 || entries[len(entries)-1].Status != t.TrackerStatus {
+			entries = append(entries, trackerStatusEntry{Status: t.TrackerStatus, Since: now})
+			if len(entries) > trackerStatusHistorySize {
+				entries = entries[len(entries)-trackerStatusHistorySize:]
+			}
+			history[hash] = entries
+		}
+
+		t.TrackerStatusSince = entries[len(entries)-1].Since
+		torrents[hash] = t
+	}
+
+	if complete {
+		for hash := range history {
+			if _, ok := torrents[hash]; !ok {
+				delete(history, hash)
+			}
+		}
+	}
+
+	if err := h.save(history); err != nil {
+		h.log.WithError(err).Warn("Failed saving tracker status history")
+	}
+}
+
+func (h *trackerStatusHistory) load() (map[string][]trackerStatusEntry, error) {
+	history := make(map[string][]trackerStatusEntry)
+
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return history, nil
+}
+
+func (h *trackerStatusHistory) save(history map[string][]trackerStatusEntry) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	if err := paths.WriteFileAtomic(h.path, data); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestTrackerStatusHistory_Apply(t *testing.T) {
+	h := &trackerStatusHistory{
+		log:  logrus.NewEntry(logrus.New()),
+		path: filepath.Join(t.TempDir(), "tracker-status.qbt.json"),
+	}
+
+	day1 := time.Now().Add(-48 * time.Hour)
+	day2 := day1.Add(24 * time.Hour)
+
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", TrackerStatus: "unregistered torrent"},
+		"b": {Hash: "b", TrackerStatus: "working"},
+	}
+	h.apply(torrents, true, day1)
+	assert.Equal(t, day1, torrents["a"].TrackerStatusSince)
+
+	// an unchanged status keeps the time it was first seen, a changed one starts over
+	torrents = map[string]config.Torrent{
+		"a": {Hash: "a", TrackerStatus: "unregistered torrent"},
+		"b": {Hash: "b", TrackerStatus: "unregistered torrent"},
+	}
+	h.apply(torrents, true, day2)
+	assert.True(t, torrents["a"].TrackerStatusSince.Equal(day1))
+	assert.True(t, torrents["b"].TrackerStatusSince.Equal(day2))
+
+	a, b := torrents["a"], torrents["b"]
+	assert.True(t, a.TrackerStatusStableFor(47))
+	assert.False(t, b.TrackerStatusStableFor(47))
+
+	// partial lists keep the history of other torrents, complete lists drop it
+	h.apply(map[string]config.Torrent{"a": {Hash: "a", TrackerStatus: "unregistered torrent"}}, false, time.Now())
+	history, err := h.load()
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Len(t, history["b"], 2)
+
+	h.apply(map[string]config.Torrent{"a": {Hash: "a", TrackerStatus: "unregistered torrent"}}, true, time.Now())
+	history, err = h.load()
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// BuiltinFilterPrefix selects one of the filters shipped with tqm, e.g. "builtin:conservative"
+const BuiltinFilterPrefix = "builtin:"
+
+// builtinFilters are optional starting points which can be used as is or extended by a configured filter
+var builtinFilters = map[string]FilterConfiguration{
+	// remove unregistered torrents and public torrents that reached a ratio of 2 after seeding for 2 weeks
+	"conservative": {
+		MapHardlinksFor: []string{"clean"},
+		Ignore: []string{
+			"IsTrackerDown()",
+			"Downloaded == false && !IsUnregistered()",
+			"SeedingHours < 26 && !IsUnregistered()",
+			"HardlinkedOutsideClient == true && !IsUnregistered()",
+		},
+		Remove: []string{
+			"IsUnregistered()",
+			"IsPublicTracker() && Ratio > 2.0 && SeedingDays >= 14.0",
+		},
+	},
+	// remove public torrents early and private torrents once they are well seeded and free space runs low
+	"space-saver": {
+		MapHardlinksFor: []string{"clean"},
+		Ignore: []string{
+			"IsTrackerDown()",
+			"Downloaded == false && !IsUnregistered()",
+			"IsPrivate && SeedingDays < 15.0 && !IsUnregistered()",
+			"HardlinkedOutsideClient == true && !IsUnregistered()",
+		},
+		Remove: []string{
+			"IsUnregistered()",
+			"IsPublicTracker() && (Ratio >= 1.0 || SeedingDays >= 3.0)",
+			"IsPrivate && FreeSpaceSet && FreeSpaceGB() < 100 && (Ratio >= 1.0 || SeedingDays >= 30.0)",
+		},
+	},
+	// never remove registered private torrents before they seeded for 30 days (well above common hit & run rules)
+	"private-tracker-safe": {
+		MapHardlinksFor: []string{"clean"},
+		Ignore: []string{
+			"IsTrackerDown()",
+			"Downloaded == false && !IsUnregistered()",
+			"IsPrivate && SeedingDays < 30.0 && !IsUnregistered()",
+			"HardlinkedOutsideClient == true && !IsUnregistered()",
+		},
+		Remove: []string{
+			"IsUnregistered()",
+			"IsPublicTracker() && SeedingDays >= 7.0",
+		},
+	},
+}
+
+// BuiltinFilterNames returns the names of the filters shipped with tqm (without prefix)
+func BuiltinFilterNames() []string {
+	names := make([]string, 0, len(builtinFilters))
+	for name := range builtinFilters {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// GetFilter returns the configured or builtin filter with the given name, merged with the filters it extends
+func GetFilter(name string) (*FilterConfiguration, error) {
+	return resolveFilter(name, nil)
+}
+
+func resolveFilter(name string, seen []string) (*FilterConfiguration, error) {
+	if slices.Contains(seen, name) {
+		return nil, fmt.Errorf("filter extends itself: %s", strings.Join(append(seen, name), " -> "))
+	}
+
+	var (
+		filter FilterConfiguration
+		ok     bool
+	)
+
+	if builtinName, isBuiltin := strings.CutPrefix(name, BuiltinFilterPrefix); isBuiltin {
+		filter, ok = builtinFilters[builtinName]
+		if !ok {
+			return nil, fmt.Errorf("unknown builtin filter: %q (available: %s)", builtinName,
+				strings.Join(BuiltinFilterNames(), ", "))
+		}
+	} else if filter, ok = Config.Filters[name]; !ok {
+		return nil, fmt.Errorf("failed finding configuration of filter: %+v", name)
+	}
+
+	if filter.Extends == "" {
+		return &filter, nil
+	}
+
+	base, err := resolveFilter(filter.Extends, append(seen, name))
+	if err != nil {
+		return nil, err
+	}
+
+	merged := mergeFilters(filter, *base)
+	return &merged, nil
+}
+
+// mergeFilters extends base with filter, rules of filter are evaluated first and its settings take precedence
+func mergeFilters(filter FilterConfiguration, base FilterConfiguration) FilterConfiguration {
+	merged := FilterConfiguration{
+		MapHardlinksFor: slices.Compact(slices.Sorted(slices.Values(slices.Concat(filter.MapHardlinksFor, base.MapHardlinksFor)))),
+		Ignore:          slices.Concat(filter.Ignore, base.Ignore),
+		Remove:          slices.Concat(filter.Remove, base.Remove),
+		Pause:           slices.Concat(filter.Pause, base.Pause),
+		Resume:          slices.Concat(filter.Resume, base.Resume),
+		Recheck:         slices.Concat(filter.Recheck, base.Recheck),
+		Reannounce:      slices.Concat(filter.Reannounce, base.Reannounce),
+		DeleteData:      base.DeleteData,
+		Orphan:          base.Orphan.Merge(filter.Orphan),
+		Clean:           base.Clean,
+		PruneFiles: PruneFilesConfig{
+			Patterns: base.PruneFiles.Patterns,
+			Update:   slices.Concat(filter.PruneFiles.Update, base.PruneFiles.Update),
+		},
+		Dedupe:    base.Dedupe,
+		Policy:    base.Policy,
+		Label:     slices.Concat(filter.Label, base.Label),
+		Move:      slices.Concat(filter.Move, base.Move),
+		Tag:       slices.Concat(filter.Tag, base.Tag),
+		SeedLimit: slices.Concat(filter.SeedLimit, base.SeedLimit),
+	}
+
+	if filter.DeleteData != nil {
+		merged.DeleteData = filter.DeleteData
+	}
+
+	if filter.Clean.MaxRemovals != nil {
+		merged.Clean.MaxRemovals = filter.Clean.MaxRemovals
+	}
+	if filter.Clean.MaxRemovedBytes != "" {
+		merged.Clean.MaxRemovedBytes = filter.Clean.MaxRemovedBytes
+	}
+	if filter.Clean.FreeSpaceTarget != "" {
+		merged.Clean.FreeSpaceTarget = filter.Clean.FreeSpaceTarget
+	}
+	if filter.Clean.FreeInodesTarget != 0 {
+		merged.Clean.FreeInodesTarget = filter.Clean.FreeInodesTarget
+	}
+	if filter.Clean.Score != "" {
+		merged.Clean.Score = filter.Clean.Score
+	}
+	if filter.Clean.ArchiveDir != "" {
+		merged.Clean.ArchiveDir = filter.Clean.ArchiveDir
+	}
+	if len(filter.Clean.KeepFiles) > 0 {
+		merged.Clean.KeepFiles = filter.Clean.KeepFiles
+	}
+	if filter.Clean.DryRunTag != "" {
+		merged.Clean.DryRunTag = filter.Clean.DryRunTag
+	}
+	merged.Clean.Cooldowns = slices.Concat(filter.Clean.Cooldowns, base.Clean.Cooldowns)
+	merged.Clean.Pacing = slices.Concat(filter.Clean.Pacing, base.Clean.Pacing)
+
+	if len(filter.PruneFiles.Patterns) > 0 {
+		merged.PruneFiles.Patterns = filter.PruneFiles.Patterns
+	}
+
+	if filter.Dedupe.Rank != "" {
+		merged.Dedupe.Rank = filter.Dedupe.Rank
+	}
+
+	if filter.Policy.KeepThreshold != nil {
+		merged.Policy = filter.Policy
+	}
+
+	return merged
+}
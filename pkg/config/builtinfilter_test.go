@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFilter(t *testing.T) {
+	deleteData := false
+	prevConfig := Config
+	Config = &Configuration{
+		Filters: map[string]FilterConfiguration{
+			"custom": {
+				Extends:    "builtin:conservative",
+				Ignore:     []string{`Label == "keep"`},
+				DeleteData: &deleteData,
+			},
+			"nested": {
+				Extends: "custom",
+				Remove:  []string{"Ratio > 10.0"},
+			},
+			"loop-a": {Extends: "loop-b"},
+			"loop-b": {Extends: "loop-a"},
+		},
+	}
+	t.Cleanup(func() { Config = prevConfig })
+
+	t.Run("builtin", func(t *testing.T) {
+		filter, err := GetFilter("builtin:private-tracker-safe")
+		require.NoError(t, err)
+		assert.Equal(t, builtinFilters["private-tracker-safe"].Remove, filter.Remove)
+	})
+
+	t.Run("unknown_builtin", func(t *testing.T) {
+		_, err := GetFilter("builtin:unknown")
+		assert.ErrorContains(t, err, "unknown builtin filter")
+	})
+
+	t.Run("extends_builtin", func(t *testing.T) {
+		filter, err := GetFilter("custom")
+		require.NoError(t, err)
+
+		base := builtinFilters["conservative"]
+		assert.Equal(t, `Label == "keep"`, filter.Ignore[0])
+		assert.Equal(t, base.Ignore, filter.Ignore[1:])
+		assert.Equal(t, base.Remove, filter.Remove)
+		assert.Equal(t, []string{"clean"}, filter.MapHardlinksFor)
+		require.NotNil(t, filter.DeleteData)
+		assert.False(t, *filter.DeleteData)
+	})
+
+	t.Run("extends_nested", func(t *testing.T) {
+		filter, err := GetFilter("nested")
+		require.NoError(t, err)
+		assert.Equal(t, "Ratio > 10.0", filter.Remove[0])
+		assert.Len(t, filter.Remove, len(builtinFilters["conservative"].Remove)+1)
+		assert.Equal(t, `Label == "keep"`, filter.Ignore[0])
+	})
+
+	t.Run("extends_loop", func(t *testing.T) {
+		_, err := GetFilter("loop-a")
+		assert.ErrorContains(t, err, "loop-a -> loop-b -> loop-a")
+	})
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+// LookupUnregistered looks up the torrents of the tracker APIs supporting bulk lookups in batches, so IsUnregistered
+// uses their results instead of querying the API for every torrent. It returns the number of torrents looked up and
+// of requests sent, the torrents without a result are still checked one by one
+func LookupUnregistered(ctx context.Context, torrents map[string]Torrent) (found int, requests int) {
+	var trackers []tracker.Interface
+	groups := make(map[tracker.Interface][]string)
+	for _, h := range slices.Sorted(maps.Keys(torrents)) {
+		t := torrents[h]
+		if t.RegistrationState != NoRegistrationState || t.apiUnregistered != nil {
+			continue
+		}
+
+		tr := t.trackerAPI()
+		if tr == nil || !tracker.SupportsBulk(tr) {
+			continue
+		}
+
+		if _, ok := groups[tr]; !ok {
+			trackers = append(trackers, tr)
+		}
+		groups[tr] = append(groups[tr], h)
+	}
+
+	for _, tr := range trackers {
+		hashes := groups[tr]
+		tts := make([]*tracker.Torrent, 0, len(hashes))
+		for _, h := range hashes {
+			t := torrents[h]
+			tts = append(tts, &tracker.Torrent{
+				Hash:          t.Hash,
+				Name:          t.Name,
+				TrackerName:   t.TrackerName,
+				TrackerStatus: t.TrackerStatus,
+				Comment:       t.Comment,
+			})
+		}
+
+		err, results, n := tracker.IsUnregisteredBulk(ctx, tr, tts)
+		requests += n
+		if err != nil {
+			logAPIError(err, "Error looking up %d torrents using %s API: %v", len(tts), tr.Name(), err)
+		}
+
+		for _, h := range hashes {
+			unregistered, ok := results[strings.ToLower(torrents[h].Hash)]
+			if !ok {
+				continue
+			}
+
+			t := torrents[h]
+			t.apiUnregistered = &unregistered
+			torrents[h] = t
+			found++
+		}
+		log.Debugf("Looked up %d of %d torrents with %d request(s) to %s API", len(results), len(tts), n, tr.Name())
+	}
+
+	return found, requests
+}
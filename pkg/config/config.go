@@ -1,13 +1,17 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
+	"github.com/mitchellh/mapstructure"
 
 	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -21,13 +25,27 @@ type TrackerErrorsConfig struct {
 	PerTrackerUnregisteredStatuses map[string][]string `yaml:"per_tracker_unregistered_statuses" koanf:"per_tracker_unregistered_statuses"`
 }
 
+type FormattingConfig struct {
+	// Units is the unit system used for sizes in logs and notifications: iec (GiB, default) or si (GB)
+	Units string `yaml:"units" koanf:"units"`
+	// Durations is the format used for durations in logs and notifications: default (1h2m3.456s) or human (1h 2m 3s)
+	Durations string `yaml:"durations" koanf:"durations"`
+}
+
 type Configuration struct {
 	Clients                    map[string]map[string]any
 	Filters                    map[string]FilterConfiguration
 	Trackers                   tracker.Config
 	BypassIgnoreIfUnregistered bool
+	StatConcurrency            int                 `yaml:"stat_concurrency" koanf:"stat_concurrency"`
 	TrackerErrors              TrackerErrorsConfig `yaml:"tracker_errors" koanf:"tracker_errors"`
 	Notifications              NotificationsConfig `yaml:"notifications" koanf:"notifications"`
+	Serve                      ServeConfig         `yaml:"serve" koanf:"serve"`
+	Formatting                 FormattingConfig    `yaml:"formatting" koanf:"formatting"`
+	Hooks                      HooksConfig         `yaml:"hooks" koanf:"hooks"`
+	Summary                    SummaryConfig       `yaml:"summary" koanf:"summary"`
+	Inventory                  InventoryConfig     `yaml:"inventory" koanf:"inventory"`
+	StrictConfig               bool                `yaml:"strict_config" koanf:"strict_config"`
 }
 
 /* Vars */
@@ -63,10 +81,22 @@ func Init(configFilePath string) error {
 	}
 
 	// unmarshal config
-	if err := K.Unmarshal("", &Config); err != nil {
+	if err := K.UnmarshalWithConf("", &Config, koanf.UnmarshalConf{
+		DecoderConfig: decoderConfig(&Config, K.Bool("strict_config")),
+	}); err != nil {
+		// decoding errors span multiple lines, flatten them to keep them readable in logs
+		var decodeErr *mapstructure.Error
+		if errors.As(err, &decodeErr) {
+			return fmt.Errorf("unmarshal: %s", strings.Join(decodeErr.Errors, "; "))
+		}
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
+	if err := validateOrphanConfigs(K.Raw(), Config); err != nil {
+		// keep the joined errors on a single line as well
+		return fmt.Errorf("validate: %s", strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+
 	log.Debugf("Parsed TrackerErrors config: %+v", Config.TrackerErrors)
 
 	InitializeTrackerStatuses(Config.TrackerErrors.PerTrackerUnregisteredStatuses)
@@ -74,6 +104,38 @@ func Init(configFilePath string) error {
 	return nil
 }
 
+// SectionKeys returns the sorted keys of a top level section (e.g. clients or filters) of a config file, without
+// initializing the config
+func SectionKeys(configFilePath string, section string) ([]string, error) {
+	k := koanf.New(Delimiter)
+	if err := k.Load(file.Provider(configFilePath), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("load file: %w", err)
+	}
+
+	keys := k.MapKeys(section)
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// decoderConfig returns koanf's default decoder configuration, which fails on unknown keys when strict is set.
+// Clients are free-form maps, so unknown keys within a client are not reported.
+func decoderConfig(result any, strict bool) *mapstructure.DecoderConfig {
+	return &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			mapstructure.TextUnmarshallerHookFunc()),
+		Result:           result,
+		WeaklyTypedInput: true,
+		ErrorUnused:      strict,
+	}
+}
+
+// StatePath returns the path of the state file name, which is kept next to the config file
+func StatePath(name string) string {
+	return filepath.Join(filepath.Dir(cfgPath), name)
+}
+
 func ShowUsing() {
 	log.Infof("Using %s = %q", formatting.LeftJust("CONFIG", " ", 10), cfgPath)
 }
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/knadh/koanf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit_StrictConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:    "unknown_key_ignored_by_default",
+			content: "filters:\n  default:\n    ignroe:\n      - IsTrackerDown()\n",
+		},
+		{
+			name:        "unknown_key_strict",
+			content:     "strict_config: true\nfilters:\n  default:\n    ignroe:\n      - IsTrackerDown()\n",
+			expectedErr: "'Filters[default]' has invalid keys: ignroe",
+		},
+		{
+			name:    "known_keys_strict",
+			content: "strict_config: true\nclients:\n  qbt:\n    anything: goes\nfilters:\n  default:\n    ignore:\n      - IsTrackerDown()\n",
+		},
+		{
+			name:    "squashed_tracker_keys_strict",
+			content: "strict_config: true\ntrackers:\n  bhd:\n    api_key: key\n    cache_ttl: 24h\n    requests_per_second: 0.5\n",
+		},
+	}
+
+	prevK, prevConfig := K, Config
+	t.Cleanup(func() { K, Config = prevK, prevConfig })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			K = koanf.New(Delimiter)
+			Config = nil
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			err := Init(path)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,23 +3,128 @@ package config
 import "time"
 
 type FilterConfiguration struct {
+	// Extends is the name of a filter (or builtin:<name>) whose rules are evaluated after the ones of this filter
+	Extends         string
 	MapHardlinksFor []string
 	Ignore          []string
 	Remove          []string
 	Pause           []string
+	Resume          []string
+	Recheck         []string
+	Reannounce      []string
 	DeleteData      *bool
-	Orphan          struct {
-		GracePeriod time.Duration `yaml:"grace_period" koanf:"grace_period"`
-		IgnorePaths []string      `yaml:"ignore_paths" koanf:"ignore_paths"`
-	} `yaml:"orphan" koanf:"orphan"`
-	Label []struct {
+	Orphan          OrphanConfig     `yaml:"orphan" koanf:"orphan"`
+	Clean           CleanConfig      `yaml:"clean" koanf:"clean"`
+	PruneFiles      PruneFilesConfig `yaml:"prune_files" koanf:"prune_files"`
+	Dedupe          struct {
+		// Rank scores torrents sharing the same payload, the ones ranked lower than another copy are duplicates
+		Rank string
+	} `yaml:"dedupe" koanf:"dedupe"`
+	// Policy removes the torrents whose weighted retention score is below its keep threshold, in addition to Remove
+	Policy PolicyConfig `yaml:"policy" koanf:"policy"`
+	Label  []struct {
 		Name   string
 		Update []string
 	}
+	Move []struct {
+		Path   string
+		Update []string
+	}
 	Tag []struct {
 		Name     string
 		Mode     string
 		UploadKb *int `mapstructure:"uploadKb"`
 		Update   []string
 	}
+	SeedLimit []struct {
+		Name                string
+		Ratio               *float64
+		SeedingTime         *time.Duration `yaml:"seeding_time" koanf:"seeding_time"`
+		InactiveSeedingTime *time.Duration `yaml:"inactive_seeding_time" koanf:"inactive_seeding_time"`
+		Update              []string
+	}
+}
+
+// CleanConfig caps the removals of a single clean run, limiting the damage of a too broad remove expression
+type CleanConfig struct {
+	// MaxRemovals is the maximum number of torrents removed per run (0 for unlimited)
+	MaxRemovals *int `yaml:"max_removals" koanf:"max_removals"`
+	// MaxRemovedBytes is the maximum size of the torrents removed per run, e.g. 500GB or 2TiB (empty for unlimited)
+	MaxRemovedBytes string `yaml:"max_removed_bytes" koanf:"max_removed_bytes"`
+	// FreeSpaceTarget stops removing torrents once the free space reaches it, e.g. 500GiB (empty to remove all matches)
+	FreeSpaceTarget string `yaml:"free_space_target" koanf:"free_space_target"`
+	// FreeInodesTarget stops removing torrents once the free inodes of the filesystem reach it (0 to remove all matches)
+	FreeInodesTarget int64 `yaml:"free_inodes_target" koanf:"free_inodes_target"`
+	// Score orders the removals when a free space or free inodes target is set, torrents with the lowest score are
+	// removed first
+	Score string `yaml:"score" koanf:"score"`
+	// ArchiveDir is where the .torrent file and metadata of torrents are exported to before they are removed
+	// (empty to not archive them)
+	ArchiveDir string `yaml:"archive_dir" koanf:"archive_dir"`
+	// KeepFiles are the patterns of the files kept on disk when the data of a torrent is deleted, e.g. *.mkv (empty to
+	// delete all files)
+	KeepFiles []string `yaml:"keep_files" koanf:"keep_files"`
+	// DryRunTag is added to the torrents which would be removed in dry-run, and removed again from the torrents which
+	// are no longer removal candidates on the next run, e.g. tqm:would-remove (empty to only log them)
+	DryRunTag string `yaml:"dry_run_tag" koanf:"dry_run_tag"`
+	// Cooldowns limit the removals of remove expressions over a rolling window, spreading large purges over several
+	// runs
+	Cooldowns []CooldownConfig `yaml:"cooldowns" koanf:"cooldowns"`
+	// Pacing interleaves the removals of the trackers and pauses them after each group, so each tracker sees a gradual
+	// decline instead of many stopped announces at once
+	Pacing []PacingConfig `yaml:"pacing" koanf:"pacing"`
+}
+
+// PacingConfig pauses the removals of a tracker after each group of removals
+type PacingConfig struct {
+	// Tracker is the domain of the tracker, e.g. beyond-hd.me (* for the trackers without a pacing of their own)
+	Tracker string `yaml:"tracker" koanf:"tracker"`
+	// GroupSize is the number of removals of the tracker between pauses (1 when not set)
+	GroupSize int `yaml:"group_size" koanf:"group_size"`
+	// Pause is the pause after each group of removals of the tracker, e.g. 30s
+	Pause time.Duration `yaml:"pause" koanf:"pause"`
+}
+
+// CooldownConfig limits the removals of a remove expression over a rolling window, the removals are tracked across
+// runs
+type CooldownConfig struct {
+	// Remove is the remove expression the cooldown applies to, exactly as written in remove (policy for the retention
+	// policy)
+	Remove string `yaml:"remove" koanf:"remove"`
+	// MaxRemovals is the maximum number of torrents removed by the expression per window (0 for unlimited)
+	MaxRemovals int `yaml:"max_removals" koanf:"max_removals"`
+	// MaxRemovedBytes is the maximum size of the torrents removed by the expression per window, e.g. 500GiB (empty for
+	// unlimited)
+	MaxRemovedBytes string `yaml:"max_removed_bytes" koanf:"max_removed_bytes"`
+	// Window is the rolling window of the cooldown (24h when not set)
+	Window time.Duration `yaml:"window" koanf:"window"`
+}
+
+// PolicyConfig scores the retention of torrents from weighted criteria, as an alternative to remove expressions. Each
+// criterion adds its weight times its value, scaled to 0-1, to the score of a torrent.
+type PolicyConfig struct {
+	// KeepThreshold is the score torrents need to be kept, torrents scoring lower are removed (nil to disable the policy)
+	KeepThreshold *float64          `yaml:"keep_threshold" koanf:"keep_threshold"`
+	Criteria      []PolicyCriterion `yaml:"criteria" koanf:"criteria"`
+}
+
+// PolicyCriterion is a weighted criterion of the retention policy
+type PolicyCriterion struct {
+	// Criterion is one of age (seeding days), ratio, size (GiB), hardlinked or tracker
+	Criterion string `yaml:"criterion" koanf:"criterion"`
+	// Weight of the criterion, negative weights lower the score
+	Weight float64 `yaml:"weight" koanf:"weight"`
+	// Max is the value of age, ratio and size at which the criterion scores 1, higher values are capped
+	Max float64 `yaml:"max" koanf:"max"`
+	// Trackers score 1 for the tracker criterion, e.g. the trackers important to keep a ratio on
+	Trackers []string `yaml:"trackers" koanf:"trackers"`
+}
+
+// PruneFilesConfig selects the unwanted files of kept torrents, which are skipped (and optionally deleted) by prune-files
+type PruneFilesConfig struct {
+	// Patterns of the unwanted files, e.g. *sample* or screens/* (patterns starting with ! exclude files again)
+	Patterns []string `yaml:"patterns" koanf:"patterns"`
+	// Update selects the torrents whose files are pruned, a torrent is selected when any expression matches (empty
+	// for all torrents)
+	Update []string `yaml:"update" koanf:"update"`
 }
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// HooksConfig lists the commands executed before and after tqm acts on a torrent or file
+type HooksConfig struct {
+	// PreRemove hooks run before clean removes a torrent, a non-zero exit status keeps the torrent
+	PreRemove []HookConfig `yaml:"pre_remove" koanf:"pre_remove"`
+	// PreOrphan hooks run before an orphaned file or folder is deleted, a non-zero exit status keeps it
+	PreOrphan []HookConfig `yaml:"pre_orphan" koanf:"pre_orphan"`
+	// PostRemove hooks run after a torrent was removed by clean
+	PostRemove []HookConfig `yaml:"post_remove" koanf:"post_remove"`
+	// PostOrphan hooks run after an orphaned file or folder was deleted
+	PostOrphan []HookConfig `yaml:"post_orphan" koanf:"post_orphan"`
+}
+
+type HookConfig struct {
+	Command string   `yaml:"command" koanf:"command"`
+	Args    []string `yaml:"args" koanf:"args"`
+	// Timeout defaults to a minute
+	Timeout time.Duration `yaml:"timeout" koanf:"timeout"`
+}
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+const DefaultInventoryKeep = 30
+
+// InventoryConfig configures the snapshots of the files of the download paths of clients
+type InventoryConfig struct {
+	// Interval takes a snapshot of Clients at most once per interval while serving, e.g. 24h (0 to not take them)
+	Interval time.Duration `yaml:"interval" koanf:"interval"`
+	// Clients are the clients snapshotted while serving (default: the enabled clients with a download_path)
+	Clients []string `yaml:"clients" koanf:"clients"`
+	// Keep is the number of snapshots kept per client, older ones are removed
+	Keep int `yaml:"keep" koanf:"keep"`
+}
+
+// WithDefaults returns the inventory settings with unset values replaced by their defaults
+func (i InventoryConfig) WithDefaults() InventoryConfig {
+	if i.Keep <= 0 {
+		i.Keep = DefaultInventoryKeep
+	}
+	return i
+}
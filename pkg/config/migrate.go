@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The migrations only cover keys and shapes of released versions, keys added since the last release have no legacy
+// form to migrate from.
+
+// renamedRootKeys maps legacy top level keys to their current name
+var renamedRootKeys = map[string]string{
+	"bypass_ignore_if_unregistered": "bypassIgnoreIfUnregistered",
+}
+
+// renamedFilterKeys maps legacy filter keys to their current name
+var renamedFilterKeys = map[string]string{
+	"map_hardlinks_for": "MapHardlinksFor",
+	"delete_data":       "DeleteData",
+}
+
+// renamedTagKeys maps legacy keys of tag rules to their current name
+var renamedTagKeys = map[string]string{
+	"upload_kb": "uploadKb",
+}
+
+// Migrate rewrites legacy keys and filter shapes of a yaml configuration to the current schema, comments are kept.
+// It returns the migrated configuration and a description of every change, no changes means data is up to date.
+func Migrate(data []byte) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse: %w", err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+
+	root := doc.Content[0]
+	var changes []string
+
+	changes = append(changes, renameKeys(root, renamedRootKeys, "")...)
+
+	// per tracker unregistered statuses moved into tracker_errors
+	if key, value := mappingValue(root, "per_tracker_unregistered_statuses"); value != nil {
+		_, trackerErrors := mappingValue(root, "tracker_errors")
+		if trackerErrors == nil {
+			trackerErrors = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "tracker_errors"}, trackerErrors)
+		}
+
+		if _, existing := mappingValue(trackerErrors, "per_tracker_unregistered_statuses"); existing == nil {
+			trackerErrors.Content = append(trackerErrors.Content, key, value)
+			changes = append(changes, "moved per_tracker_unregistered_statuses to tracker_errors.per_tracker_unregistered_statuses")
+		} else {
+			changes = append(changes, "removed per_tracker_unregistered_statuses, tracker_errors.per_tracker_unregistered_statuses is already set")
+		}
+		removeMappingKey(root, "per_tracker_unregistered_statuses")
+	}
+
+	// filters
+	if _, filters := mappingValue(root, "filters"); filters != nil && filters.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(filters.Content); i += 2 {
+			name, filter := filters.Content[i].Value, filters.Content[i+1]
+			if filter.Kind != yaml.MappingNode {
+				continue
+			}
+
+			prefix := "filters." + name + "."
+			changes = append(changes, renameKeys(filter, renamedFilterKeys, prefix)...)
+
+			for _, section := range []string{"label", "tag"} {
+				_, rules := mappingValue(filter, section)
+				if rules == nil || rules.Kind != yaml.SequenceNode {
+					continue
+				}
+
+				for j, rule := range rules.Content {
+					if rule.Kind != yaml.MappingNode {
+						continue
+					}
+
+					rulePrefix := fmt.Sprintf("%s%s[%d].", prefix, section, j)
+					if section == "tag" {
+						changes = append(changes, renameKeys(rule, renamedTagKeys, rulePrefix)...)
+					}
+
+					// a single update expression used to be allowed as a string
+					if _, update := mappingValue(rule, "update"); update != nil && update.Kind == yaml.ScalarNode {
+						item := *update
+						*update = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{&item}}
+						changes = append(changes, fmt.Sprintf("converted %supdate to a list", rulePrefix))
+					}
+				}
+			}
+		}
+	}
+
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return buf.Bytes(), changes, nil
+}
+
+// mappingValue returns the key and value nodes of key in mapping, or nil when not present
+func mappingValue(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+func removeMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// renameKeys renames legacy keys of mapping, legacy keys are dropped when the current key is already present
+func renameKeys(mapping *yaml.Node, renames map[string]string, prefix string) []string {
+	var changes []string
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		newName, ok := renames[key.Value]
+		if !ok {
+			continue
+		}
+
+		if existing, _ := mappingValue(mapping, newName); existing != nil {
+			changes = append(changes, fmt.Sprintf("removed %s%s, %s%s is already set", prefix, key.Value, prefix, newName))
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			i -= 2
+			continue
+		}
+
+		changes = append(changes, fmt.Sprintf("renamed %s%s to %s%s", prefix, key.Value, prefix, newName))
+		key.Value = newName
+	}
+
+	return changes
+}
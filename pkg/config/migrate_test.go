@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	legacy := `bypass_ignore_if_unregistered: true
+per_tracker_unregistered_statuses:
+  tracker.com:
+    - gone
+filters:
+  default:
+    # keep comments
+    map_hardlinks_for:
+      - clean
+    delete_data: false
+    tag:
+      - name: slow
+        mode: add
+        upload_kb: 100
+        update: Ratio > 5
+    label:
+      - name: sorted
+        update:
+          - Label == "unsorted"
+`
+
+	migrated, changes, err := Migrate([]byte(legacy))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"renamed bypass_ignore_if_unregistered to bypassIgnoreIfUnregistered",
+		"moved per_tracker_unregistered_statuses to tracker_errors.per_tracker_unregistered_statuses",
+		"renamed filters.default.map_hardlinks_for to filters.default.MapHardlinksFor",
+		"renamed filters.default.delete_data to filters.default.DeleteData",
+		"renamed filters.default.tag[0].upload_kb to filters.default.tag[0].uploadKb",
+		"converted filters.default.tag[0].update to a list",
+	}, changes)
+
+	assert.Equal(t, `bypassIgnoreIfUnregistered: true
+filters:
+  default:
+    # keep comments
+    MapHardlinksFor:
+      - clean
+    DeleteData: false
+    tag:
+      - name: slow
+        mode: add
+        uploadKb: 100
+        update:
+          - Ratio > 5
+    label:
+      - name: sorted
+        update:
+          - Label == "unsorted"
+tracker_errors:
+  per_tracker_unregistered_statuses:
+    tracker.com:
+      - gone
+`, string(migrated))
+
+	// migrating again is a no-op
+	again, changes, err := Migrate(migrated)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, string(migrated), string(again))
+
+	// keys added since the last release are left as is
+	unreleased := "filters:\n  default:\n    seed_limit:\n      - name: short\n        update: Ratio > 1\n"
+	same, changes, err := Migrate([]byte(unreleased))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, unreleased, string(same))
+}
@@ -3,7 +3,11 @@ package config
 type NotificationsConfig struct {
 	Detailed     bool
 	SkipEmptyRun bool `yaml:"skip_empty_run" koanf:"skip_empty_run"`
+	Delta        bool
 	Service      NotificationService
+
+	MaxFields         int `yaml:"max_fields" koanf:"max_fields"`
+	MessagesPerMinute int `yaml:"messages_per_minute" koanf:"messages_per_minute"`
 }
 
 type NotificationService struct {
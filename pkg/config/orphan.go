@@ -0,0 +1,256 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	DefaultOrphanGracePeriod = 10 * time.Minute
+	DefaultOrphanRetries     = 3
+	DefaultOrphanRetryDelay  = 500 * time.Millisecond
+	DefaultOrphanProtectTag  = "protect-data"
+)
+
+// Policies for the content extracted next to the RAR archives of torrents
+const (
+	// ExtractedArchivesOrphan handles extracted content like any other file, it is removed by orphan
+	ExtractedArchivesOrphan = "orphan"
+	// ExtractedArchivesKeep keeps extracted content while the torrent exists
+	ExtractedArchivesKeep = "keep"
+	// ExtractedArchivesRemove keeps extracted content while the torrent exists and clean removes it with the torrent
+	ExtractedArchivesRemove = "remove"
+)
+
+// DefaultOrphanIgnorePaths are always ignored by the orphan command, they match incomplete files whose names differ
+// from the ones reported by the client (qBittorrent's "Append .!qB extension to incomplete files" and Transmission)
+var DefaultOrphanIgnorePaths = []string{"*.!qB", "*.part"}
+
+// DefaultOrphanExcludeDirs are the names of recycle bins, NAS metadata and client state directories (e.g. Deluge's
+// .config/deluge/state) commonly found under download paths, nothing inside them is considered orphaned unless
+// exclude_dirs is set
+var DefaultOrphanExcludeDirs = []string{
+	".RecycleBin", "#recycle", "@Recycle", "$RECYCLE.BIN", ".Trash-*", ".Trashes", "@eaDir", "lost+found", ".config",
+}
+
+// orphanKeys are the settings of OrphanConfig, used to report unknown and misplaced keys
+var orphanKeys = []string{"grace_period", "ignore_paths", "retries", "retry_delay", "inode_check", "exclude_dirs", "protect_tag", "extracted_archives"}
+
+type OrphanConfig struct {
+	// GracePeriod skips files modified within it (default: 10m)
+	GracePeriod time.Duration `yaml:"grace_period" koanf:"grace_period"`
+	// IgnorePaths are absolute path prefixes or, when not absolute, glob patterns matched against file and folder names
+	IgnorePaths []string `yaml:"ignore_paths" koanf:"ignore_paths"`
+	// Retries for transient filesystem errors, e.g. on NFS/CIFS mounts (default: 3)
+	Retries *int `yaml:"retries" koanf:"retries"`
+	// RetryDelay is the delay before the first retry, it doubles after each retry (default: 500ms)
+	RetryDelay time.Duration `yaml:"retry_delay" koanf:"retry_delay"`
+	// InodeCheck skips files whose device and inode belong to a torrent file although their paths differ (default: false)
+	InodeCheck *bool `yaml:"inode_check" koanf:"inode_check"`
+	// ExcludeDirs are glob patterns matched against the names of the folders under the download path, nothing inside
+	// matching folders is considered orphaned (default: DefaultOrphanExcludeDirs, an empty list disables them)
+	ExcludeDirs []string `yaml:"exclude_dirs" koanf:"exclude_dirs"`
+	// ProtectTag protects the folders of the torrents carrying it, including files not belonging to the torrents
+	// (default: protect-data)
+	ProtectTag string `yaml:"protect_tag" koanf:"protect_tag"`
+	// ExtractedArchives is the policy for the content extracted next to the RAR archives of torrents: orphan, keep or
+	// remove (default: orphan)
+	ExtractedArchives string `yaml:"extracted_archives" koanf:"extracted_archives"`
+}
+
+// Merge returns o overridden by the settings set in override, ignore paths of both are kept while exclude dirs are replaced
+func (o OrphanConfig) Merge(override OrphanConfig) OrphanConfig {
+	merged := o
+
+	if override.GracePeriod != 0 {
+		merged.GracePeriod = override.GracePeriod
+	}
+	if override.IgnorePaths != nil {
+		merged.IgnorePaths = slices.Concat(override.IgnorePaths, o.IgnorePaths)
+	}
+	if override.Retries != nil {
+		merged.Retries = override.Retries
+	}
+	if override.RetryDelay != 0 {
+		merged.RetryDelay = override.RetryDelay
+	}
+	if override.InodeCheck != nil {
+		merged.InodeCheck = override.InodeCheck
+	}
+	if override.ExcludeDirs != nil {
+		merged.ExcludeDirs = override.ExcludeDirs
+	}
+	if override.ProtectTag != "" {
+		merged.ProtectTag = override.ProtectTag
+	}
+	if override.ExtractedArchives != "" {
+		merged.ExtractedArchives = override.ExtractedArchives
+	}
+
+	return merged
+}
+
+// WithDefaults returns o with the defaults applied to the settings not set
+func (o OrphanConfig) WithDefaults() OrphanConfig {
+	if o.GracePeriod == 0 {
+		o.GracePeriod = DefaultOrphanGracePeriod
+	}
+	if o.Retries == nil {
+		retries := DefaultOrphanRetries
+		o.Retries = &retries
+	}
+	if o.RetryDelay == 0 {
+		o.RetryDelay = DefaultOrphanRetryDelay
+	}
+	if o.InodeCheck == nil {
+		inodeCheck := false
+		o.InodeCheck = &inodeCheck
+	}
+	if o.ExcludeDirs == nil {
+		o.ExcludeDirs = DefaultOrphanExcludeDirs
+	}
+	if o.ProtectTag == "" {
+		o.ProtectTag = DefaultOrphanProtectTag
+	}
+	if o.ExtractedArchives == "" {
+		o.ExtractedArchives = ExtractedArchivesOrphan
+	}
+	o.IgnorePaths = slices.Concat(o.IgnorePaths, DefaultOrphanIgnorePaths)
+
+	return o
+}
+
+// Validate returns all invalid settings of o
+func (o OrphanConfig) Validate() error {
+	var errs []error
+
+	if o.GracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("grace_period must not be negative: %s", o.GracePeriod))
+	}
+	if o.Retries != nil && *o.Retries < 0 {
+		errs = append(errs, fmt.Errorf("retries must not be negative: %d", *o.Retries))
+	}
+	if o.RetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("retry_delay must not be negative: %s", o.RetryDelay))
+	}
+
+	for _, p := range o.IgnorePaths {
+		switch {
+		case strings.TrimSpace(p) == "":
+			// an empty prefix would ignore every path
+			errs = append(errs, errors.New("ignore_paths must not contain empty entries"))
+		case filepath.IsAbs(p):
+		default:
+			if _, err := filepath.Match(p, ""); err != nil {
+				errs = append(errs, fmt.Errorf("invalid ignore_paths pattern: %q: %w", p, err))
+			}
+		}
+	}
+
+	switch o.ExtractedArchives {
+	case "", ExtractedArchivesOrphan, ExtractedArchivesKeep, ExtractedArchivesRemove:
+	default:
+		errs = append(errs, fmt.Errorf("invalid extracted_archives policy: %q (supported: %s, %s, %s)", o.ExtractedArchives,
+			ExtractedArchivesOrphan, ExtractedArchivesKeep, ExtractedArchivesRemove))
+	}
+
+	for _, p := range o.ExcludeDirs {
+		if strings.TrimSpace(p) == "" || strings.ContainsAny(p, `/\`) {
+			errs = append(errs, fmt.Errorf("invalid exclude_dirs entry: %q (must be a folder name or pattern)", p))
+		} else if _, err := filepath.Match(p, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid exclude_dirs pattern: %q: %w", p, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ClientOrphanConfig returns the orphan settings of a client, which take precedence over the ones of its filter
+func ClientOrphanConfig(clientConfig map[string]any) (OrphanConfig, error) {
+	var o OrphanConfig
+
+	v, ok := clientConfig["orphan"]
+	if !ok || v == nil {
+		return o, nil
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig(&o, true))
+	if err != nil {
+		return o, fmt.Errorf("create decoder: %w", err)
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		var decodeErr *mapstructure.Error
+		if errors.As(err, &decodeErr) {
+			return o, errors.New(strings.Join(decodeErr.Errors, "; "))
+		}
+		return o, err
+	}
+
+	return o, nil
+}
+
+// validateOrphanConfigs validates the orphan settings of all filters and clients. Unknown orphan settings and orphan
+// settings set directly in a filter (e.g. because of a wrong indentation) are reported regardless of strict_config.
+func validateOrphanConfigs(raw map[string]any, cfg *Configuration) error {
+	var errs []error
+
+	filters, _ := raw["filters"].(map[string]any)
+	for _, name := range sortedKeys(filters) {
+		filter, ok := filters[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for key, value := range filter {
+			if slices.Contains(orphanKeys, strings.ToLower(key)) {
+				errs = append(errs, fmt.Errorf("filter %q: %s must be set under orphan (check its indentation)", name, key))
+			}
+
+			orphan, ok := value.(map[string]any)
+			if !strings.EqualFold(key, "orphan") || !ok {
+				continue
+			}
+			for orphanKey := range orphan {
+				if !slices.Contains(orphanKeys, strings.ToLower(orphanKey)) {
+					errs = append(errs, fmt.Errorf("filter %q: unknown orphan setting: %q (supported: %s)", name, orphanKey,
+						strings.Join(orphanKeys, ", ")))
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Filters) {
+		if err := cfg.Filters[name].Orphan.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("filter %q: orphan: %w", name, err))
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Clients) {
+		o, err := ClientOrphanConfig(cfg.Clients[name])
+		if err == nil {
+			err = o.Validate()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("client %q: orphan: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
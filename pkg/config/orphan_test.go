@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit_OrphanValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr []string
+	}{
+		{
+			name:    "valid",
+			content: "filters:\n  default:\n    orphan:\n      grace_period: 1h\n      ignore_paths:\n        - /data/keep\n        - '*.nfo'\n      exclude_dirs:\n        - '#recycle'\n",
+		},
+		{
+			name:        "misindented",
+			content:     "filters:\n  default:\n    orphan:\n    grace_period: 1h\n",
+			expectedErr: []string{`filter "default": grace_period must be set under orphan`},
+		},
+		{
+			name:        "unknown_key",
+			content:     "filters:\n  default:\n    orphan:\n      grace: 1h\n",
+			expectedErr: []string{`filter "default": unknown orphan setting: "grace"`},
+		},
+		{
+			name:    "invalid_values",
+			content: "filters:\n  default:\n    orphan:\n      retries: -1\n      ignore_paths:\n        - ''\n        - '[a'\n      exclude_dirs:\n        - a/b\n      extracted_archives: delete\n",
+			expectedErr: []string{
+				"retries must not be negative: -1",
+				"ignore_paths must not contain empty entries",
+				`invalid ignore_paths pattern: "[a"`,
+				`invalid exclude_dirs entry: "a/b"`,
+				`invalid extracted_archives policy: "delete"`,
+			},
+		},
+		{
+			name:        "client_override",
+			content:     "clients:\n  qbt:\n    orphan:\n      grace_period: -1h\n      grace: 1h\n",
+			expectedErr: []string{`client "qbt": orphan:`, "invalid keys: grace"},
+		},
+	}
+
+	prevK, prevConfig := K, Config
+	t.Cleanup(func() { K, Config = prevK, prevConfig })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			K = koanf.New(Delimiter)
+			Config = nil
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			err := Init(path)
+			if len(tt.expectedErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, expected := range tt.expectedErr {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestOrphanConfig_MergeWithDefaults(t *testing.T) {
+	retries := 5
+	filter := OrphanConfig{GracePeriod: time.Hour, IgnorePaths: []string{"/data/keep"}}
+	client := OrphanConfig{IgnorePaths: []string{"*.nfo"}, Retries: &retries}
+
+	o := filter.Merge(client).WithDefaults()
+
+	assert.Equal(t, time.Hour, o.GracePeriod)
+	assert.Equal(t, 5, *o.Retries)
+	assert.Equal(t, DefaultOrphanRetryDelay, o.RetryDelay)
+	assert.Equal(t, []string{"*.nfo", "/data/keep", "*.!qB", "*.part"}, o.IgnorePaths)
+	assert.Equal(t, DefaultOrphanExcludeDirs, o.ExcludeDirs)
+	assert.Equal(t, DefaultOrphanProtectTag, o.ProtectTag)
+
+	// exclude dirs replace the defaults, an empty list disables them
+	o = filter.Merge(OrphanConfig{ExcludeDirs: []string{}}).WithDefaults()
+	assert.Empty(t, o.ExcludeDirs)
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+)
+
+// publicTrackerDomains holds well known public trackers, subdomains of these are matched as well
+var publicTrackerDomains = []string{
+	// open trackers
+	"opentrackr.org",
+	"openbittorrent.com",
+	"publicbt.com",
+	"stealth.si",
+	"torrent.eu.org",
+	"demonii.com",
+	"coppersurfer.tk",
+	"leechers-paradise.org",
+	"istole.it",
+	"explodie.org",
+	"desync.com",
+	"tiny-vps.com",
+	"dler.org",
+	"moeking.me",
+	"openwebtorrent.com",
+	"webtorrent.dev",
+	"btorrent.xyz",
+
+	// public indexers
+	"rarbg.to",
+	"rarbg.me",
+	"nyaa.tracker.wf",
+	"anidex.moe",
+
+	// linux distributions & archives
+	"archive.org",
+	"torrent.ubuntu.com",
+	"torrent.fedoraproject.org",
+	"linuxtracker.org",
+}
+
+// IsPublicTracker reports whether the torrent is from a public tracker. Torrents flagged as private never are,
+// otherwise the torrent has to be announced to a well known public tracker or have DHT/PeX enabled.
+func (t *Torrent) IsPublicTracker() bool {
+	if t.IsPrivate {
+		return false
+	}
+
+	if t.DHTEnabled || t.PeXEnabled {
+		return true
+	}
+
+	if isPublicTrackerHost(t.TrackerHost) {
+		return true
+	}
+
+	for trackerURL := range t.AllTrackerStatuses {
+		if isPublicTrackerHost(ParseTrackerHost(trackerURL)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isPublicTrackerHost(host string) bool {
+	if host == "" {
+		return false
+	}
+
+	for _, domain := range publicTrackerDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
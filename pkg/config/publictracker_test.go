@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTorrent_IsPublicTracker(t *testing.T) {
+	tests := []struct {
+		name     string
+		torrent  Torrent
+		expected bool
+	}{
+		{
+			name:     "private_flag",
+			torrent:  Torrent{IsPrivate: true, DHTEnabled: true, TrackerHost: "tracker.opentrackr.org"},
+			expected: false,
+		},
+		{
+			name:     "dht_enabled",
+			torrent:  Torrent{DHTEnabled: true, TrackerHost: "tracker.example.com"},
+			expected: true,
+		},
+		{
+			name:     "pex_enabled",
+			torrent:  Torrent{PeXEnabled: true},
+			expected: true,
+		},
+		{
+			name:     "known_public_tracker",
+			torrent:  Torrent{TrackerHost: "tracker.opentrackr.org"},
+			expected: true,
+		},
+		{
+			name: "known_public_tracker_in_secondary_tracker",
+			torrent: Torrent{
+				TrackerHost: "tracker.example.com",
+				AllTrackerStatuses: map[string]string{
+					"https://tracker.example.com/announce": "",
+					"udp://open.stealth.si:80/announce":    "",
+				},
+			},
+			expected: true,
+		},
+		{
+			name:     "lookalike_domain",
+			torrent:  Torrent{TrackerHost: "notopentrackr.org"},
+			expected: false,
+		},
+		{
+			name:     "unknown_tracker_without_peer_sources",
+			torrent:  Torrent{TrackerHost: "tracker.example.com"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.torrent.IsPublicTracker())
+		})
+	}
+}
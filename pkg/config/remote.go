@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+)
+
+// maxRemoteConfigSize limits the size of a config fetched from a url
+const maxRemoteConfigSize = 10 << 20
+
+// IsRemote reports whether path is a http(s) url
+func IsRemote(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// RemoteCachePath returns the file a config fetched from configURL is cached to within cacheDir
+func RemoteCachePath(cacheDir string, configURL string) string {
+	sum := sha256.Sum256([]byte(configURL))
+	return filepath.Join(cacheDir, fmt.Sprintf("config.remote-%s.yaml", hex.EncodeToString(sum[:4])))
+}
+
+// FetchRemote downloads the config at configURL and caches it within cacheDir, returning the cached file.
+// header is an optional "Name: value" header (e.g. for authorization) sent with the request.
+// When the config cannot be fetched or is not valid yaml, the previously cached config is returned along with the error.
+func FetchRemote(ctx context.Context, configURL string, header string, cacheDir string) (string, error) {
+	cachePath := RemoteCachePath(cacheDir, configURL)
+
+	err := fetchRemote(ctx, configURL, header, cachePath)
+	if err == nil {
+		return cachePath, nil
+	}
+
+	if _, statErr := os.Stat(cachePath); statErr != nil {
+		return "", err
+	}
+
+	return cachePath, err
+}
+
+func fetchRemote(ctx context.Context, configURL string, header string, cachePath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	if header != "" {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return errors.New("invalid header, expected format: \"Name: value\"")
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	res, err := httputils.NewRetryableHttpClient(30*time.Second, nil).Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxRemoteConfigSize+1))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	} else if len(data) > maxRemoteConfigSize {
+		return fmt.Errorf("config exceeds %d bytes", maxRemoteConfigSize)
+	}
+
+	// never replace a working cached config with an invalid one
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+
+	// the config contains credentials, so it is written atomically and only readable by the owner
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), cachePath); err != nil {
+		return fmt.Errorf("replace cache: %w", err)
+	}
+
+	return nil
+}
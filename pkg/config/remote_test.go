@@ -0,0 +1,55 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRemote(t *testing.T) {
+	body := "filters:\n  default: {}\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	_, err := FetchRemote(ctx, srv.URL, "", dir)
+	require.Error(t, err, "no cached copy available")
+
+	cachePath, err := FetchRemote(ctx, srv.URL, "Authorization: Bearer secret", dir)
+	require.NoError(t, err)
+	assert.Equal(t, RemoteCachePath(dir, srv.URL), cachePath)
+
+	data, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	// invalid configs do not replace the cached copy
+	body = "filters: [\n"
+	cachePath, err = FetchRemote(ctx, srv.URL, "Authorization: Bearer secret", dir)
+	require.Error(t, err)
+	require.NotEmpty(t, cachePath)
+
+	data, err = os.ReadFile(cachePath)
+	require.NoError(t, err)
+	assert.Equal(t, "filters:\n  default: {}\n", string(data))
+}
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("https://example.com/config.yaml"))
+	assert.True(t, IsRemote("HTTP://example.com/config.yaml"))
+	assert.False(t, IsRemote("/config/config.yaml"))
+	assert.False(t, IsRemote("config.yaml"))
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// urlPattern matches the urls in a value, up to whitespace or quotes
+var urlPattern = regexp.MustCompile("[A-Za-z][A-Za-z0-9+.-]*://[^\\s\"'`]+")
+
+// sensitiveKeyParts are parts of keys whose values are redacted when sharing a configuration
+var sensitiveKeyParts = []string{"password", "passkey", "secret", "token", "key", "webhook", "cookie", "auth"}
+
+// Sanitize returns a yaml configuration with credentials redacted, so it can be shared for diagnostics.
+// Values of sensitive keys (e.g. password, api_key, webhook_url), credentials embedded in urls and the values of url
+// query parameters (e.g. passkeys of announce urls) are replaced.
+func Sanitize(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+
+	sanitizeNode(&doc, false)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func sanitizeNode(node *yaml.Node, sensitive bool) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			sanitizeNode(node.Content[i+1], sensitive || isSensitiveKey(node.Content[i].Value))
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			sanitizeNode(child, sensitive)
+		}
+	case yaml.ScalarNode:
+		if sensitive {
+			if node.Value != "" {
+				node.Value = redacted
+				node.Tag = "!!str"
+				node.Style = 0
+			}
+			return
+		}
+
+		// urls are also redacted inside values, e.g. an announce url compared in an expression
+		node.Value = RedactURLs(node.Value)
+	}
+}
+
+// RedactURLs returns s with the credentials and the values of the query parameters of the urls it contains replaced,
+// e.g. to share log lines which include announce urls
+func RedactURLs(s string) string {
+	return urlPattern.ReplaceAllStringFunc(s, redactURL)
+}
+
+// redactURL replaces the credentials and the values of the query parameters of rawURL
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.User == nil && u.RawQuery == "") {
+		return rawURL
+	}
+
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	u.RawQuery = redactQuery(u.RawQuery)
+	return u.String()
+}
+
+// redactQuery replaces the values of the parameters of a url query, keeping their names and order
+func redactQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		if name, value, ok := strings.Cut(param, "="); ok && value != "" {
+			params[i] = name + "=" + redacted
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+
+	return false
+}
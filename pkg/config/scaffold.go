@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ClientTypeQBittorrent = "qbittorrent"
+	ClientTypeDeluge      = "deluge"
+)
+
+// ScaffoldOptions describe the client of a starter config
+type ScaffoldOptions struct {
+	ClientName string
+	ClientType string
+	// URL, User and Password are used by qbittorrent
+	URL string
+	// Host and Port are used by deluge
+	Host     string
+	Port     int
+	User     string
+	Password string
+	// DownloadPath is where the client stores its downloads, as seen by tqm
+	DownloadPath string
+}
+
+// Scaffold returns a starter config with a single client, a default filter and commented examples of the other sections
+func Scaffold(opts ScaffoldOptions) ([]byte, error) {
+	if opts.ClientName == "" {
+		return nil, fmt.Errorf("no client name given")
+	}
+
+	if opts.ClientType != ClientTypeQBittorrent && opts.ClientType != ClientTypeDeluge {
+		return nil, fmt.Errorf("unsupported client type: %q (supported: %s, %s)", opts.ClientType,
+			ClientTypeQBittorrent, ClientTypeDeluge)
+	}
+
+	var buf bytes.Buffer
+	if err := scaffoldTemplate.Execute(&buf, opts); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// quoteYAML returns s as a YAML scalar, quoted when required
+func quoteYAML(s string) (string, error) {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+var scaffoldTemplate = template.Must(template.New("config").Funcs(template.FuncMap{"yaml": quoteYAML}).Parse(
+	`# tqm configuration, see https://github.com/autobrr/tqm for all options
+clients:
+  {{ yaml .ClientName }}:
+    enabled: true
+    filter: default
+    type: {{ .ClientType }}
+{{- if eq .ClientType "qbittorrent" }}
+    url: {{ yaml .URL }}
+    user: {{ yaml .User }}
+    password: {{ yaml .Password }}
+    # qBittorrent 5.2.0+ supports API key authentication instead of user/password
+    # api_key: qbt_xxxxxxxxxxxxxxxxxxxxxxxxxxxx
+{{- else }}
+    host: {{ yaml .Host }}
+    port: {{ .Port }}
+    login: {{ yaml .User }}
+    password: {{ yaml .Password }}
+    v2: true
+    # required to retrieve the free space, must exist on the deluge host
+    free_space_path: {{ yaml .DownloadPath }}
+{{- end }}
+    download_path: {{ yaml .DownloadPath }}
+    # map the paths reported by the client to the paths seen by tqm, e.g. when the client runs in a container
+    # download_path_mapping:
+    #   /downloads: {{ .DownloadPath }}
+
+filters:
+  default:
+    # if false, removed torrents keep their data on disk (default: true)
+    # DeleteData: false
+    # map hardlinks to keep torrents whose data is still hardlinked elsewhere (e.g. imported by the *arrs)
+    MapHardlinksFor:
+      - clean
+    ignore:
+      - IsTrackerDown()
+      - Downloaded == false && !IsUnregistered()
+      - SeedingHours < 26 && !IsUnregistered()
+      - '"permaseed" in Tags && !IsUnregistered()'
+    remove:
+      - IsUnregistered()
+      # - IsPrivate == false && (Ratio > 2.0 || SeedingDays >= 7.0)
+    # orphan:
+    #   grace_period: 1h
+    #   ignore_paths:
+    #     - {{ .DownloadPath }}/manual
+
+# validate whether torrents were removed from the tracker using the tracker's API
+# trackers:
+#   bhd:
+#     api_key: your-api-key
+#   btn:
+#     api_key: your-api-key
+#   ptp:
+#     api_user: your-api-user
+#     api_key: your-api-key
+#   unit3d:
+#     aither:
+#       api_key: your-api-key
+#       domain: aither.cc
+
+# notifications:
+#   detailed: true
+#   skip_empty_run: true
+#   service:
+#     discord:
+#       webhook_url: https://discord.com/api/webhooks/yourwebhookid/yourwebhooktoken
+`))
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/knadh/koanf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        ScaffoldOptions
+		expected    map[string]any
+		expectedErr bool
+	}{
+		{
+			name: "qbittorrent",
+			opts: ScaffoldOptions{ClientName: "qbt", ClientType: ClientTypeQBittorrent, URL: "http://localhost:8080",
+				User: "admin", Password: "p@ss: #word", DownloadPath: "/data/torrents"},
+			expected: map[string]any{"type": "qbittorrent", "url": "http://localhost:8080", "user": "admin",
+				"password": "p@ss: #word", "download_path": "/data/torrents"},
+		},
+		{
+			name: "deluge",
+			opts: ScaffoldOptions{ClientName: "deluge", ClientType: ClientTypeDeluge, Host: "localhost", Port: 58846,
+				User: "localclient", Password: "secret", DownloadPath: "/data/deluge"},
+			expected: map[string]any{"type": "deluge", "host": "localhost", "port": 58846, "login": "localclient",
+				"free_space_path": "/data/deluge"},
+		},
+		{
+			name:        "unsupported_type",
+			opts:        ScaffoldOptions{ClientName: "rt", ClientType: "rtorrent"},
+			expectedErr: true,
+		},
+	}
+
+	prevK, prevConfig := K, Config
+	t.Cleanup(func() { K, Config = prevK, prevConfig })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Scaffold(tt.opts)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// the scaffold is a valid config, even with strict_config enabled
+			K = koanf.New(Delimiter)
+			Config = nil
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, append([]byte("strict_config: true\n"), data...), 0600))
+			require.NoError(t, Init(path))
+
+			client := Config.Clients[tt.opts.ClientName]
+			for key, value := range tt.expected {
+				assert.EqualValues(t, value, client[key], key)
+			}
+
+			filter, ok := Config.Filters["default"]
+			require.True(t, ok)
+			assert.NotEmpty(t, filter.Remove)
+			assert.Equal(t, []string{"clean"}, filter.MapHardlinksFor)
+		})
+	}
+}
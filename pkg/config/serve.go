@@ -0,0 +1,13 @@
+package config
+
+type ServeConfig struct {
+	Host   string `yaml:"host" koanf:"host"`
+	Port   int    `yaml:"port" koanf:"port"`
+	APIKey string `yaml:"api_key" koanf:"api_key"`
+	// GRPCPort is the port of the gRPC service, which is disabled when unset
+	GRPCPort int `yaml:"grpc_port" koanf:"grpc_port"`
+	// GRPCTLSCert and GRPCTLSKey are the certificate and key files the gRPC service is served with, without them it is
+	// only served on localhost as the api key would be sent in plaintext
+	GRPCTLSCert string `yaml:"grpc_tls_cert" koanf:"grpc_tls_cert"`
+	GRPCTLSKey  string `yaml:"grpc_tls_key" koanf:"grpc_tls_key"`
+}
@@ -0,0 +1,29 @@
+package config
+
+import "time"
+
+// DefaultSummaryAgeDays are the boundaries of the default age buckets, in days
+var DefaultSummaryAgeDays = []int{30, 90, 180}
+
+const DefaultSummaryRatio = 1.0
+
+// SummaryConfig configures the breakdown of the torrents by age and ratio logged at the end of clean and run
+type SummaryConfig struct {
+	// AgeDays are the boundaries of the age buckets in days, e.g. 30, 90, 180 for <30d, 30-90d, 90-180d and 180d+
+	AgeDays []int `yaml:"age_days" koanf:"age_days"`
+	// Ratio is the ratio below which torrents of a bucket are counted as low ratio
+	Ratio float64 `yaml:"ratio" koanf:"ratio"`
+	// NotifyInterval sends the summary as a notification at most once per interval, e.g. 168h (0 to never send it)
+	NotifyInterval time.Duration `yaml:"notify_interval" koanf:"notify_interval"`
+}
+
+// WithDefaults returns the summary settings with unset values replaced by their defaults
+func (s SummaryConfig) WithDefaults() SummaryConfig {
+	if len(s.AgeDays) == 0 {
+		s.AgeDays = DefaultSummaryAgeDays
+	}
+	if s.Ratio == 0 {
+		s.Ratio = DefaultSummaryRatio
+	}
+	return s
+}
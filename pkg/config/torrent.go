@@ -2,18 +2,24 @@ package config
 
 import (
 	"context"
+	"errors"
 	"math"
 	"net"
 	"net/url"
-	"os"
+	"path"
 	stdregexp "regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/bobesa/go-domain-util/domainutil"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/idna"
 
+	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
 	"github.com/autobrr/tqm/pkg/regex"
 	"github.com/autobrr/tqm/pkg/tracker"
 )
@@ -21,12 +27,26 @@ import (
 // urlRegex matches HTTP/HTTPS URLs for stripping before pattern matching.
 var urlRegex = stdregexp.MustCompile(`(?i)https?://\S+`)
 
+// archiveRegex matches the files of RAR archives, including old style volumes (.r00, .r01, ...)
+var archiveRegex = stdregexp.MustCompile(`(?i)\.(rar|r\d{2,3})$`)
+
 // stripURLs removes HTTP/HTTPS URLs from a string to prevent false positives
 // when matching tracker status patterns against URLs that contain status keywords.
 func stripURLs(s string) string {
 	return urlRegex.ReplaceAllString(s, "")
 }
 
+// Tracker status codes as reported by the qBittorrent API, clients without numeric statuses leave them unset
+const (
+	TrackerStatusCodeDisabled     = 0 // DHT, PeX and LSD
+	TrackerStatusCodeNotContacted = 1
+	TrackerStatusCodeWorking      = 2
+	TrackerStatusCodeUpdating     = 3
+	TrackerStatusCodeNotWorking   = 4
+	TrackerStatusCodeTrackerError = 5 // qBittorrent Web API 2.13+
+	TrackerStatusCodeUnreachable  = 6 // qBittorrent Web API 2.13+
+)
+
 type TorrentRegistrationState uint8
 
 const (
@@ -156,20 +176,46 @@ type Torrent struct {
 	Peers               int64               `json:"Peers"`
 	IsPrivate           bool                `json:"IsPrivate"`
 	IsPublic            bool                `json:"IsPublic"`
+	DHTEnabled          bool                `json:"DHTEnabled"`
+	PeXEnabled          bool                `json:"PeXEnabled"`
 	UpLimit             int64               `json:"UpLimit,omitempty"`
 
+	// share limits (-2 = client global limit, -1 = unlimited)
+	RatioLimit               float64 `json:"RatioLimit,omitempty"`
+	SeedingTimeLimit         int64   `json:"SeedingTimeLimit,omitempty"`
+	InactiveSeedingTimeLimit int64   `json:"InactiveSeedingTimeLimit,omitempty"`
+
 	// set by client on GetCurrentFreeSpace
 	FreeSpaceGB  func() float64 `json:"-"`
 	FreeSpaceSet bool           `json:"-"`
 
+	// free inodes of the filesystem of the download path at the start of the run, set by cmd handler
+	FreeInodes    int64 `json:"-"`
+	FreeInodesSet bool  `json:"-"`
+
 	// tracker
 	TrackerName   string `json:"TrackerName"`
+	TrackerHost   string `json:"TrackerHost"`
 	TrackerStatus string `json:"TrackerStatus"`
+	// TrackerStatusCode is the numeric status of the first tracker (see TrackerStatusCode constants)
+	TrackerStatusCode int `json:"TrackerStatusCode"`
 	// AllTrackerStatuses stores status messages from all trackers (key: tracker URL, value: status message)
 	AllTrackerStatuses map[string]string `json:"AllTrackerStatuses,omitempty"`
-	Comment            string            `json:"Comment"`
+	// AllTrackerStatusCodes stores numeric statuses from all trackers (key: tracker URL, value: status code)
+	AllTrackerStatusCodes map[string]int `json:"AllTrackerStatusCodes,omitempty"`
+	Comment               string         `json:"Comment"`
+	// TrackerStatusSince is when TrackerStatus was first seen, only set when a filter uses TrackerStatusStableFor
+	TrackerStatusSince time.Time `json:"-"`
 
 	RegistrationState TorrentRegistrationState `json:"-"`
+	// apiUnregistered is the result of a bulk lookup of the tracker API, nil when the torrent was not part of one
+	apiUnregistered *bool
+	// freeleech is the freeleech status reported by the tracker API, nil until checked
+	freeleech *bool
+	// TrackerSeedTimeRequired is the minimum seed time in hours reported by the tracker API (0 when none or unknown),
+	// only set when a filter uses it
+	TrackerSeedTimeRequired float64 `json:"-"`
+	seedTimeRequiredLoaded  bool
 
 	// set by command
 	HardlinkedOutsideClient bool `json:"-"`
@@ -178,39 +224,78 @@ type Torrent struct {
 	regexPattern *regex.Pattern
 }
 
+// TrackerStatusStableFor reports whether the tracker status has not changed for at least hours,
+// which is never the case while the tracker status history of the torrent is unknown
+func (t *Torrent) TrackerStatusStableFor(hours float64) bool {
+	if t.TrackerStatusSince.IsZero() {
+		return false
+	}
+
+	return time.Since(t.TrackerStatusSince).Hours() >= hours
+}
+
 func (t *Torrent) IsTrackerDown() bool {
 	// If we have multiple tracker statuses, check if ALL are down
 	if len(t.AllTrackerStatuses) > 0 {
-		var downCount int
-
-		for _, status := range t.AllTrackerStatuses {
-			if status == "" {
+		for trackerURL, status := range t.AllTrackerStatuses {
+			if !isTrackerDown(ParseTrackerDomain(trackerURL), status, t.AllTrackerStatusCodes[trackerURL]) {
 				return false
 			}
-
-			// Strip URLs to prevent false positives from URLs containing status keywords
-			statusLower := strings.ToLower(stripURLs(status))
-
-			for _, v := range trackerDownStatuses {
-				if strings.Contains(statusLower, v) {
-					downCount++
-					break
-				}
-			}
 		}
 
-		return downCount == len(t.AllTrackerStatuses)
+		return true
 	}
 
 	// Fallback to single tracker status for backward compatibility
-	if t.TrackerStatus == "" {
+	return isTrackerDown(t.TrackerName, t.TrackerStatus, t.TrackerStatusCode)
+}
+
+// isTrackerDown checks a single tracker's status message and code. A failing status code alone is enough,
+// which catches localized messages, unless the message reports the torrent as unregistered.
+func isTrackerDown(trackerDomain string, status string, code int) bool {
+	if isTrackerDownStatusCode(code) {
+		return !isUnregisteredStatus(trackerDomain, status)
+	}
+
+	if status == "" {
 		return false
 	}
 
 	// Strip URLs to prevent false positives from URLs containing status keywords
-	status := strings.ToLower(stripURLs(t.TrackerStatus))
+	statusLower := strings.ToLower(stripURLs(status))
 	for _, v := range trackerDownStatuses {
-		if strings.Contains(status, v) {
+		if strings.Contains(statusLower, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTrackerDownStatusCode(code int) bool {
+	switch code {
+	case TrackerStatusCodeNotWorking, TrackerStatusCodeTrackerError, TrackerStatusCodeUnreachable:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnregisteredStatus checks status against the unregistered statuses configured for trackerDomain,
+// falling back to the defaults.
+func isUnregisteredStatus(trackerDomain string, status string) bool {
+	if status == "" {
+		return false
+	}
+
+	statusMapToCheck := defaultUnregisteredStatusesMap
+	if specificMap, ok := effectiveUnregisteredStatuses[strings.ToLower(trackerDomain)]; ok {
+		statusMapToCheck = specificMap
+	}
+
+	statusLower := strings.ToLower(status)
+	for unregStatus := range statusMapToCheck {
+		if strings.Contains(statusLower, unregStatus) {
 			return true
 		}
 	}
@@ -295,6 +380,11 @@ func (t *Torrent) IsUnregistered(ctx context.Context) bool {
 		return false
 	}
 
+	// the status messages of some trackers cannot tell removed torrents from tracker errors, only their API can
+	if tr := t.trackerAPI(); tr != nil && tracker.StatusAmbiguous(tr) {
+		return t.isUnregisteredByAPI(ctx, tr)
+	}
+
 	// If we have multiple tracker statuses, check them
 	if len(t.AllTrackerStatuses) > 0 {
 		if t.IsIntermediateStatus() {
@@ -313,21 +403,10 @@ func (t *Torrent) IsUnregistered(ctx context.Context) bool {
 				continue
 			}
 
-			statusLower := strings.ToLower(status)
-			trackerDomain := ParseTrackerDomain(trackerURL)
-			trackerLower := strings.ToLower(trackerDomain)
-
-			statusMapToCheck := defaultUnregisteredStatusesMap
-			if specificMap, ok := effectiveUnregisteredStatuses[trackerLower]; ok {
-				statusMapToCheck = specificMap
-			}
-
-			for unregStatus := range statusMapToCheck {
-				if strings.Contains(statusLower, unregStatus) {
-					// At least one tracker reports unregistered
-					t.RegistrationState = UnregisteredState
-					return true
-				}
+			if isUnregisteredStatus(ParseTrackerDomain(trackerURL), status) {
+				// At least one tracker reports unregistered
+				t.RegistrationState = UnregisteredState
+				return true
 			}
 		}
 
@@ -354,61 +433,153 @@ func (t *Torrent) IsUnregistered(ctx context.Context) bool {
 
 	// check configured unregistered statuses using exact, case-insensitive match.
 	// Use per-tracker list if available, otherwise use defaults.
-	statusLower := strings.ToLower(t.TrackerStatus)
-	trackerLower := strings.ToLower(t.TrackerName)
+	if isUnregisteredStatus(t.TrackerName, t.TrackerStatus) {
+		t.RegistrationState = UnregisteredState
+		return true
+	}
 
-	statusMapToCheck := defaultUnregisteredStatusesMap // Default to the global defaults
-	if specificMap, ok := effectiveUnregisteredStatuses[trackerLower]; ok {
-		statusMapToCheck = specificMap // Override with tracker-specific map if it exists
+	// check tracker api (if available)
+	if tr := t.trackerAPI(); tr != nil {
+		return t.isUnregisteredByAPI(ctx, tr)
 	}
 
-	for status := range statusMapToCheck {
-		if strings.Contains(statusLower, status) {
-			t.RegistrationState = UnregisteredState
-			return true
-		}
+	t.RegistrationState = RegisteredState
+	return false
+}
+
+// trackerAPI returns the tracker API of the torrent, nil when none is configured
+func (t *Torrent) trackerAPI() tracker.Interface {
+	trackerHost := t.TrackerHost
+	if trackerHost == "" {
+		trackerHost = t.TrackerName
 	}
 
-	// check tracker api (if available)
-	if tr := tracker.Get(t.TrackerName); tr != nil {
-		tt := &tracker.Torrent{
-			Hash:              t.Hash,
-			Name:              t.Name,
-			TotalBytes:        t.TotalBytes,
-			DownloadedBytes:   t.DownloadedBytes,
-			State:             t.State,
-			Downloaded:        t.Downloaded,
-			Seeding:           t.Seeding,
-			TrackerName:       t.TrackerName,
-			TrackerStatus:     t.TrackerStatus,
-			Comment:           t.Comment,
-			APIDividerPrinted: t.APIDividerPrinted,
-		}
+	return tracker.Get(trackerHost)
+}
 
-		trackerName := tr.Name()
-		err, ur := tr.IsUnregistered(ctx, tt)
+// isUnregisteredByAPI checks whether tr reports the torrent as unregistered, torrents are not unregistered when the
+// API fails
+func (t *Torrent) isUnregisteredByAPI(ctx context.Context, tr tracker.Interface) bool {
+	tt := &tracker.Torrent{
+		Hash:              t.Hash,
+		Name:              t.Name,
+		TotalBytes:        t.TotalBytes,
+		DownloadedBytes:   t.DownloadedBytes,
+		State:             t.State,
+		Downloaded:        t.Downloaded,
+		Seeding:           t.Seeding,
+		TrackerName:       t.TrackerName,
+		TrackerStatus:     t.TrackerStatus,
+		Comment:           t.Comment,
+		APIDividerPrinted: t.APIDividerPrinted,
+	}
+
+	trackerName := tr.Name()
+	ur := false
+	if t.apiUnregistered != nil {
+		ur = *t.apiUnregistered
+	} else {
+		var err error
+		err, ur = tr.IsUnregistered(ctx, tt)
 		if err != nil {
-			log.Errorf("Error checking unregistered tracker status of %s (hash: %s) using %s API: %v", t.Name, t.Hash, trackerName, err)
+			logAPIError(err, "Error checking unregistered tracker status of %s (hash: %s) using %s API: %v", t.Name,
+				t.Hash, trackerName, err)
 			return false
 		}
 
 		t.APIDividerPrinted = tt.APIDividerPrinted
+	}
 
-		if ur {
-			log.Debugf("%s (hash: %s) confirmed as unregistered by %s API", t.Name, t.Hash, trackerName)
-			t.RegistrationState = UnregisteredState
+	if ur {
+		log.Debugf("%s (hash: %s) confirmed as unregistered by %s API", t.Name, t.Hash, trackerName)
+		t.RegistrationState = UnregisteredState
 
-			return true
-		}
+		return true
+	}
 
-		log.Debugf("%s (hash: %s) not reported as unregistered by %s API", t.Name, t.Hash, trackerName)
-		t.RegistrationState = RegisteredState
+	log.Debugf("%s (hash: %s) not reported as unregistered by %s API", t.Name, t.Hash, trackerName)
+	t.RegistrationState = RegisteredState
+
+	return false
+}
+
+// logAPIError logs and records a failed request to a tracker API, the requests not sent as the API failed repeatedly
+// are only logged at debug level
+func logAPIError(err error, format string, args ...any) {
+	if errors.Is(err, httputils.ErrBreakerOpen) {
+		log.Debugf(format, args...)
+		tracker.RecordAPISkipped()
+		return
+	}
+
+	log.Errorf(format, args...)
+	tracker.RecordAPIError()
+}
+
+// IsFreeleech reports whether the tracker API of the torrent reports it as freeleech (or neutral leech), torrents of
+// trackers without a freeleech aware API never are, neither are they when the API fails
+func (t *Torrent) IsFreeleech(ctx context.Context) bool {
+	if t.freeleech != nil {
+		return *t.freeleech
+	}
 
+	tr := t.trackerAPI()
+	if tr == nil {
 		return false
 	}
 
-	t.RegistrationState = RegisteredState
-	return false
+	err, freeleech, supported := tracker.IsFreeleech(ctx, tr, &tracker.Torrent{
+		Hash:          t.Hash,
+		Name:          t.Name,
+		TrackerName:   t.TrackerName,
+		TrackerStatus: t.TrackerStatus,
+		Comment:       t.Comment,
+	})
+	switch {
+	case !supported:
+	case err != nil:
+		logAPIError(err, "Error checking freeleech status of %s (hash: %s) using %s API: %v", t.Name, t.Hash, tr.Name(), err)
+		return false
+	case freeleech:
+		log.Debugf("%s (hash: %s) reported as freeleech by %s API", t.Name, t.Hash, tr.Name())
+	}
+
+	t.freeleech = &freeleech
+	return freeleech
+}
+
+// LoadTrackerSeedTimeRequired sets TrackerSeedTimeRequired from the tracker API of the torrent, it stays 0 for
+// trackers without an API reporting it and when the API fails
+func (t *Torrent) LoadTrackerSeedTimeRequired(ctx context.Context) {
+	if t.seedTimeRequiredLoaded {
+		return
+	}
+
+	tr := t.trackerAPI()
+	if tr == nil {
+		t.seedTimeRequiredLoaded = true
+		return
+	}
+
+	err, required, supported := tracker.SeedTimeRequired(ctx, tr, &tracker.Torrent{
+		Hash:          t.Hash,
+		Name:          t.Name,
+		TrackerName:   t.TrackerName,
+		TrackerStatus: t.TrackerStatus,
+		Comment:       t.Comment,
+	})
+	switch {
+	case !supported:
+	case err != nil:
+		logAPIError(err, "Error checking required seed time of %s (hash: %s) using %s API: %v", t.Name, t.Hash, tr.Name(),
+			err)
+		return
+	case required > 0:
+		log.Debugf("%s (hash: %s) requires seeding for %s by %s API", t.Name, t.Hash, required, tr.Name())
+	}
+
+	t.TrackerSeedTimeRequired = required.Hours()
+	t.seedTimeRequiredLoaded = true
 }
 
 func (t *Torrent) HasAllTags(tags ...string) bool {
@@ -442,6 +613,51 @@ func (t *Torrent) TagsSlice() []string {
 	return tags
 }
 
+// IsPaused reports whether the torrent is paused (or stopped in qBittorrent 5+)
+func (t *Torrent) IsPaused() bool {
+	switch t.State {
+	case "pausedUP", "pausedDL", "stoppedUP", "stoppedDL", "Paused":
+		return true
+	}
+
+	return false
+}
+
+// IsChecking reports whether the torrent's data is being checked (or queued for checking)
+func (t *Torrent) IsChecking() bool {
+	switch t.State {
+	case "checkingUP", "checkingDL", "checkingResumeData", "queuedForChecking", "Checking":
+		return true
+	}
+
+	return false
+}
+
+// IsMoving reports whether the torrent's data is being moved to another location
+func (t *Torrent) IsMoving() bool {
+	return t.State == "moving" || t.State == "Moving"
+}
+
+// ArchiveDirs returns the folders of the torrent containing RAR archives, where extracted copies usually end up.
+// Archives directly in the save path are left out, as the save path is shared with other torrents.
+func (t *Torrent) ArchiveDirs() []string {
+	var dirs []string
+	for _, f := range t.Files {
+		if !archiveRegex.MatchString(f) {
+			continue
+		}
+
+		dir := path.Dir(strings.ReplaceAll(f, `\`, "/"))
+		if dir == path.Clean(strings.ReplaceAll(t.Path, `\`, "/")) || slices.Contains(dirs, dir) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+	return dirs
+}
+
 func (t *Torrent) HasMissingFiles() bool {
 	if !t.Downloaded {
 		return false
@@ -449,22 +665,19 @@ func (t *Torrent) HasMissingFiles() bool {
 
 	log := logger.GetLogger("torrent")
 
+	files := make([]string, 0, len(t.Files))
 	for _, f := range t.Files {
 		if f == "" {
 			log.Tracef("Skipping empty path for torrent: %s", t.Name)
 			continue
 		}
 
-		if _, err := os.Stat(f); err != nil {
-			if os.IsNotExist(err) {
-				return true
-			}
-			log.Warnf("error checking file '%s' for torrent '%s': %v", f, t.Name, err)
-			continue
-		}
+		files = append(files, f)
 	}
 
-	return false
+	return paths.AnyMissing(files, func(f string, err error) {
+		log.Warnf("error checking file '%s' for torrent '%s': %v", f, t.Name, err)
+	})
 }
 
 func (t *Torrent) Log(n float64) float64 {
@@ -533,30 +746,57 @@ func (t *Torrent) RegexMatchAll(patternsStr string) bool {
 	return match
 }
 
-func ParseTrackerDomain(trackerHost string) string {
+// ParseTrackerHost returns the normalized host of a tracker url (or bare host): lowercased, without port,
+// IPv6 brackets and trailing dot, with internationalized domain names converted to punycode.
+func ParseTrackerHost(trackerURL string) string {
 	// return empty host
-	if trackerHost == "" {
-		return trackerHost
+	if trackerURL == "" {
+		return trackerURL
+	}
+
+	// bare hosts (e.g. from deluge) are parsed as a network path reference
+	raw := trackerURL
+	if !strings.Contains(raw, "://") {
+		if !strings.Contains(raw, ".") {
+			// neither a url nor a host
+			return ""
+		}
+		raw = "//" + raw
 	}
 
 	// parse url components
-	u, err := url.Parse(trackerHost)
+	u, err := url.Parse(raw)
 	if err != nil {
-		logrus.WithError(err).Warnf("Failed parsing tracker host: %q", trackerHost)
-		return trackerHost
+		logrus.WithError(err).Warnf("Failed parsing tracker host: %q", trackerURL)
+		return trackerURL
 	}
 
-	// parse host
-	host := u.Host
-	if strings.Contains(host, ":") {
-		// remove port
-		if h, _, err := net.SplitHostPort(host); err == nil {
-			host = h
-		}
+	// parse host (removes port and IPv6 brackets)
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || net.ParseIP(host) != nil {
+		return host
 	}
 
-	// remove subdomain
+	// convert internationalized domain names
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+
+	return host
+}
+
+// ParseTrackerDomain returns the registrable domain of a tracker url (or bare host), IP addresses are returned as is.
+func ParseTrackerDomain(trackerURL string) string {
+	host := ParseTrackerHost(trackerURL)
+	if host == "" || net.ParseIP(host) != nil {
+		return host
+	}
+
+	// remove subdomain (domainutil returns internationalized domains in unicode form)
 	if domain := domainutil.Domain(host); domain != "" {
+		if ascii, err := idna.Lookup.ToASCII(domain); err == nil {
+			return ascii
+		}
 		return domain
 	}
 
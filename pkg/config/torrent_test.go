@@ -8,6 +8,8 @@ import (
 )
 
 func TestTorrent_IsTrackerDown(t *testing.T) {
+	InitializeTrackerStatuses(nil)
+
 	tests := []struct {
 		name         string
 		torrent      Torrent
@@ -168,6 +170,37 @@ func TestTorrent_IsTrackerDown(t *testing.T) {
 			},
 			expectedDown: true,
 		},
+		{
+			name: "localized_message_with_not_working_status_code",
+			torrent: Torrent{
+				AllTrackerStatuses: map[string]string{
+					"http://tracker1.com/announce": "Zeitüberschreitung der Verbindung",
+				},
+				AllTrackerStatusCodes: map[string]int{
+					"http://tracker1.com/announce": TrackerStatusCodeNotWorking,
+				},
+			},
+			expectedDown: true,
+		},
+		{
+			name: "not_working_status_code_with_unregistered_message",
+			torrent: Torrent{
+				AllTrackerStatuses: map[string]string{
+					"http://tracker1.com/announce": "unregistered torrent",
+				},
+				AllTrackerStatusCodes: map[string]int{
+					"http://tracker1.com/announce": TrackerStatusCodeNotWorking,
+				},
+			},
+			expectedDown: false,
+		},
+		{
+			name: "single_tracker_unreachable_status_code_without_message",
+			torrent: Torrent{
+				TrackerStatusCode: TrackerStatusCodeUnreachable,
+			},
+			expectedDown: true,
+		},
 		{
 			name: "single_tracker_url_containing_down_keyword_not_false_positive",
 			torrent: Torrent{
@@ -550,6 +583,31 @@ func TestTorrent_TagsSlice(t *testing.T) {
 	}
 }
 
+func TestTorrent_IsPaused(t *testing.T) {
+	tests := []struct {
+		state string
+		want  bool
+	}{
+		{state: "pausedUP", want: true},
+		{state: "pausedDL", want: true},
+		{state: "stoppedUP", want: true},
+		{state: "stoppedDL", want: true},
+		{state: "Paused", want: true},
+		{state: "uploading"},
+		{state: "stalledUP"},
+		{state: "downloading"},
+		{state: "Seeding"},
+		{state: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			torrent := &Torrent{State: tt.state}
+			assert.Equal(t, tt.want, torrent.IsPaused())
+		})
+	}
+}
+
 func TestTorrent_HasAllTags(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -669,3 +727,79 @@ func TestTorrent_HasAnyTag(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTrackerHost(t *testing.T) {
+	tests := []struct {
+		name         string
+		trackerURL   string
+		expectedHost string
+		expectedName string
+	}{
+		{
+			name:         "subdomain_with_port",
+			trackerURL:   "https://Tracker.Example.com:8443/announce",
+			expectedHost: "tracker.example.com",
+			expectedName: "example.com",
+		},
+		{
+			name:         "trailing_dot",
+			trackerURL:   "http://tracker.example.co.uk./announce",
+			expectedHost: "tracker.example.co.uk",
+			expectedName: "example.co.uk",
+		},
+		{
+			name:         "ipv6_literal",
+			trackerURL:   "udp://[2001:db8::1]:6969/announce",
+			expectedHost: "2001:db8::1",
+			expectedName: "2001:db8::1",
+		},
+		{
+			name:         "ipv4_literal",
+			trackerURL:   "http://192.168.1.10:8080/announce",
+			expectedHost: "192.168.1.10",
+			expectedName: "192.168.1.10",
+		},
+		{
+			name:         "internationalized_domain",
+			trackerURL:   "https://tracker.bücher.de/announce",
+			expectedHost: "tracker.xn--bcher-kva.de",
+			expectedName: "xn--bcher-kva.de",
+		},
+		{
+			name:         "bare_host",
+			trackerURL:   "tracker.example.com",
+			expectedHost: "tracker.example.com",
+			expectedName: "example.com",
+		},
+		{
+			name:         "empty",
+			trackerURL:   "",
+			expectedHost: "",
+			expectedName: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedHost, ParseTrackerHost(tt.trackerURL))
+			assert.Equal(t, tt.expectedName, ParseTrackerDomain(tt.trackerURL))
+		})
+	}
+}
+
+func TestTorrent_ArchiveDirs(t *testing.T) {
+	torrent := Torrent{
+		Path: "/data/movies",
+		Files: []string{
+			"/data/movies/Movie.2024/movie.rar",
+			"/data/movies/Movie.2024/movie.r00",
+			"/data/movies/Movie.2024/movie.nfo",
+			"/data/movies/Movie.2024/CD2/movie.RAR",
+			"/data/movies/Movie.2024/Sample/sample.mkv",
+			"/data/movies/loose.rar",
+		},
+	}
+
+	assert.Equal(t, []string{"/data/movies/Movie.2024", "/data/movies/Movie.2024/CD2"}, torrent.ArchiveDirs())
+	assert.Empty(t, (&Torrent{Path: "/data", Files: []string{"/data/Show/episode.mkv"}}).ArchiveDirs())
+}
@@ -3,12 +3,18 @@ package expression
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/expr-lang/expr"
+	"github.com/sirupsen/logrus"
 
 	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
 )
 
+var log = logger.GetLogger("expression")
+
 func CheckTorrentSingleMatch(ctx context.Context, t *config.Torrent, expressions []CompiledExpression) (bool, error) {
 	match, _, err := CheckTorrentSingleMatchWithReason(ctx, t, expressions)
 	return match, err
@@ -21,8 +27,12 @@ func CheckTorrentAllMatch(ctx context.Context, t *config.Torrent, expressions []
 
 func CheckTorrentSingleMatchWithReason(ctx context.Context, t *config.Torrent, expressions []CompiledExpression) (bool, string, error) {
 	env := &evalContext{Torrent: t, ctx: ctx}
+	trace := newEvalTrace(t)
+	defer trace.log()
 
 	for _, expression := range expressions {
+		start := time.Now()
+		env.load(expression.Text)
 		result, err := expr.Run(expression.Program, env)
 		if err != nil {
 			return false, "", fmt.Errorf("check expression: %w", err)
@@ -33,6 +43,9 @@ func CheckTorrentSingleMatchWithReason(ctx context.Context, t *config.Torrent, e
 			return false, "", fmt.Errorf("type assert expression result: %w", err)
 		}
 
+		recordEvaluation(expression, expResult, time.Since(start))
+		trace.add(expression, expResult)
+
 		if expResult {
 			return true, expression.Text, nil
 		}
@@ -43,9 +56,13 @@ func CheckTorrentSingleMatchWithReason(ctx context.Context, t *config.Torrent, e
 
 func CheckTorrentAllMatchWithReason(ctx context.Context, t *config.Torrent, expressions []CompiledExpression) (bool, []string, error) {
 	env := &evalContext{Torrent: t, ctx: ctx}
+	trace := newEvalTrace(t)
+	defer trace.log()
 	var failedExpressions []string
 
 	for _, expression := range expressions {
+		start := time.Now()
+		env.load(expression.Text)
 		result, err := expr.Run(expression.Program, env)
 		if err != nil {
 			return false, nil, fmt.Errorf("check expression: %w", err)
@@ -56,6 +73,9 @@ func CheckTorrentAllMatchWithReason(ctx context.Context, t *config.Torrent, expr
 			return false, nil, fmt.Errorf("type assert expression result: %w", err)
 		}
 
+		recordEvaluation(expression, expResult, time.Since(start))
+		trace.add(expression, expResult)
+
 		if !expResult {
 			failedExpressions = append(failedExpressions, expression.Text)
 		}
@@ -67,3 +87,37 @@ func CheckTorrentAllMatchWithReason(ctx context.Context, t *config.Torrent, expr
 
 	return true, nil, nil
 }
+
+// evalTrace collects the results of the expressions evaluated for a torrent to log them as a single trace line
+type evalTrace struct {
+	t       *config.Torrent
+	section string
+	results []string
+}
+
+// newEvalTrace returns nil when trace logging is disabled, evalTrace methods are no-ops on nil
+func newEvalTrace(t *config.Torrent) *evalTrace {
+	if t == nil || !log.Logger.IsLevelEnabled(logrus.TraceLevel) {
+		return nil
+	}
+	return &evalTrace{t: t}
+}
+
+func (et *evalTrace) add(expression CompiledExpression, result bool) {
+	if et == nil {
+		return
+	}
+
+	if et.section == "" {
+		et.section = expression.Section
+	}
+	et.results = append(et.results, fmt.Sprintf("%q → %t", expression.Text, result))
+}
+
+func (et *evalTrace) log() {
+	if et == nil || len(et.results) == 0 {
+		return
+	}
+
+	log.Tracef("Evaluated %s for %q: %s", et.section, et.t.Name, strings.Join(et.results, ", "))
+}
@@ -3,6 +3,7 @@ package expression
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/expr-lang/expr"
 
@@ -15,6 +16,18 @@ type evalContext struct {
 	ctx context.Context
 }
 
+// load sets the fields of the torrent queried from tracker APIs which are used by text, they are only queried for the
+// expressions using them
+func (e *evalContext) load(text string) {
+	if e.Torrent == nil {
+		return
+	}
+
+	if strings.Contains(text, "TrackerSeedTimeRequired") {
+		e.Torrent.LoadTrackerSeedTimeRequired(e.ctx)
+	}
+}
+
 func (e *evalContext) IsUnregistered() bool {
 	if e.Torrent == nil {
 		return false
@@ -22,6 +35,13 @@ func (e *evalContext) IsUnregistered() bool {
 	return e.Torrent.IsUnregistered(e.ctx)
 }
 
+func (e *evalContext) IsFreeleech() bool {
+	if e.Torrent == nil {
+		return false
+	}
+	return e.Torrent.IsFreeleech(e.ctx)
+}
+
 func (e *evalContext) IsTrackerDown() bool {
 	if e.Torrent == nil {
 		return false
@@ -29,6 +49,13 @@ func (e *evalContext) IsTrackerDown() bool {
 	return e.Torrent.IsTrackerDown()
 }
 
+func (e *evalContext) TrackerStatusStableFor(hours float64) bool {
+	if e.Torrent == nil {
+		return false
+	}
+	return e.Torrent.TrackerStatusStableFor(hours)
+}
+
 func (e *evalContext) HasAllTags(tags ...string) bool {
 	if e.Torrent == nil {
 		return false
@@ -43,6 +70,20 @@ func (e *evalContext) HasAnyTag(tags ...string) bool {
 	return e.Torrent.HasAnyTag(tags...)
 }
 
+func (e *evalContext) IsPaused() bool {
+	if e.Torrent == nil {
+		return false
+	}
+	return e.Torrent.IsPaused()
+}
+
+func (e *evalContext) IsPublicTracker() bool {
+	if e.Torrent == nil {
+		return false
+	}
+	return e.Torrent.IsPublicTracker()
+}
+
 func (e *evalContext) HasMissingFiles() bool {
 	if e.Torrent == nil {
 		return false
@@ -95,6 +136,7 @@ func Compile(filter *config.FilterConfiguration) (*Expressions, error) {
 		exp.Ignores = append(exp.Ignores, CompiledExpression{
 			Program: program,
 			Text:    ignoreExpr,
+			Section: "ignore",
 		})
 	}
 
@@ -108,9 +150,19 @@ func Compile(filter *config.FilterConfiguration) (*Expressions, error) {
 		exp.Removes = append(exp.Removes, CompiledExpression{
 			Program: program,
 			Text:    removeExpr,
+			Section: "remove",
 		})
 	}
 
+	// compile the retention policy, removing the torrents scoring below its keep threshold
+	policy, err := compilePolicy(filter.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("compile policy: %w", err)
+	}
+	if policy != nil {
+		exp.Removes = append(exp.Removes, *policy)
+	}
+
 	// compile pauses
 	for _, pauseExpr := range filter.Pause {
 		program, err := expr.Compile(pauseExpr, expr.Env(exprEnv), expr.AsBool())
@@ -121,6 +173,63 @@ func Compile(filter *config.FilterConfiguration) (*Expressions, error) {
 		exp.Pauses = append(exp.Pauses, CompiledExpression{
 			Program: program,
 			Text:    pauseExpr,
+			Section: "pause",
+		})
+	}
+
+	// compile resumes
+	for _, resumeExpr := range filter.Resume {
+		program, err := expr.Compile(resumeExpr, expr.Env(exprEnv), expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("compile resume expression: %q: %w", resumeExpr, err)
+		}
+
+		exp.Resumes = append(exp.Resumes, CompiledExpression{
+			Program: program,
+			Text:    resumeExpr,
+			Section: "resume",
+		})
+	}
+
+	// compile rechecks
+	for _, recheckExpr := range filter.Recheck {
+		program, err := expr.Compile(recheckExpr, expr.Env(exprEnv), expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("compile recheck expression: %q: %w", recheckExpr, err)
+		}
+
+		exp.Rechecks = append(exp.Rechecks, CompiledExpression{
+			Program: program,
+			Text:    recheckExpr,
+			Section: "recheck",
+		})
+	}
+
+	// compile reannounces
+	for _, reannounceExpr := range filter.Reannounce {
+		program, err := expr.Compile(reannounceExpr, expr.Env(exprEnv), expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("compile reannounce expression: %q: %w", reannounceExpr, err)
+		}
+
+		exp.Reannounces = append(exp.Reannounces, CompiledExpression{
+			Program: program,
+			Text:    reannounceExpr,
+			Section: "reannounce",
+		})
+	}
+
+	// compile the torrents selected by prune-files
+	for _, pruneExpr := range filter.PruneFiles.Update {
+		program, err := expr.Compile(pruneExpr, expr.Env(exprEnv), expr.AsBool())
+		if err != nil {
+			return nil, fmt.Errorf("compile prune_files expression: %q: %w", pruneExpr, err)
+		}
+
+		exp.PruneFiles = append(exp.PruneFiles, CompiledExpression{
+			Program: program,
+			Text:    pruneExpr,
+			Section: "prune_files",
 		})
 	}
 
@@ -138,12 +247,38 @@ func Compile(filter *config.FilterConfiguration) (*Expressions, error) {
 			le.Updates = append(le.Updates, CompiledExpression{
 				Program: program,
 				Text:    updateExpr,
+				Section: "label/" + labelExpr.Name,
 			})
 		}
 
 		exp.Labels = append(exp.Labels, le)
 	}
 
+	// compile moves
+	for _, moveExpr := range filter.Move {
+		if moveExpr.Path == "" {
+			return nil, fmt.Errorf("move rule must set a path")
+		}
+
+		me := &MoveExpression{Path: moveExpr.Path}
+
+		// compile updates
+		for _, updateExpr := range moveExpr.Update {
+			program, err := expr.Compile(updateExpr, expr.Env(exprEnv), expr.AsBool())
+			if err != nil {
+				return nil, fmt.Errorf("compile move update expression: %v: %q: %w", moveExpr.Path, updateExpr, err)
+			}
+
+			me.Updates = append(me.Updates, CompiledExpression{
+				Program: program,
+				Text:    updateExpr,
+				Section: "move/" + moveExpr.Path,
+			})
+		}
+
+		exp.Moves = append(exp.Moves, me)
+	}
+
 	// compile tags
 	for _, tagExpr := range filter.Tag {
 		le := &TagExpression{Name: tagExpr.Name, Mode: tagExpr.Mode, UploadKb: tagExpr.UploadKb}
@@ -168,11 +303,42 @@ func Compile(filter *config.FilterConfiguration) (*Expressions, error) {
 			le.Updates = append(le.Updates, CompiledExpression{
 				Program: program,
 				Text:    updateExpr,
+				Section: "tag/" + tagExpr.Name,
 			})
 		}
 
 		exp.Tags = append(exp.Tags, le)
 	}
 
+	// compile seed limits
+	for _, seedLimitExpr := range filter.SeedLimit {
+		se := &SeedLimitExpression{
+			Name:                seedLimitExpr.Name,
+			Ratio:               seedLimitExpr.Ratio,
+			SeedingTime:         seedLimitExpr.SeedingTime,
+			InactiveSeedingTime: seedLimitExpr.InactiveSeedingTime,
+		}
+
+		if se.Ratio == nil && se.SeedingTime == nil && se.InactiveSeedingTime == nil {
+			return nil, fmt.Errorf("seed limit '%s' must set at least one of: ratio, seeding_time, inactive_seeding_time", se.Name)
+		}
+
+		// compile updates
+		for _, updateExpr := range seedLimitExpr.Update {
+			program, err := expr.Compile(updateExpr, expr.Env(exprEnv), expr.AsBool())
+			if err != nil {
+				return nil, fmt.Errorf("compile seed limit update expression: %v: %q: %w", seedLimitExpr.Name, updateExpr, err)
+			}
+
+			se.Updates = append(se.Updates, CompiledExpression{
+				Program: program,
+				Text:    updateExpr,
+				Section: "seedLimit/" + seedLimitExpr.Name,
+			})
+		}
+
+		exp.SeedLimits = append(exp.SeedLimits, se)
+	}
+
 	return exp, nil
 }
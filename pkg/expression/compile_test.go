@@ -0,0 +1,83 @@
+package expression
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+func TestCompile_BuiltinFilters(t *testing.T) {
+	for _, name := range config.BuiltinFilterNames() {
+		t.Run(name, func(t *testing.T) {
+			filter, err := config.GetFilter(config.BuiltinFilterPrefix + name)
+			if assert.NoError(t, err) {
+				_, err = Compile(filter)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheck_TrackerSeedTimeRequired(t *testing.T) {
+	require.NoError(t, tracker.Init(tracker.Config{
+		UNIT3D: map[string]tracker.UNIT3DConfig{
+			"aither": {APIKey: "key", Domain: "aither.cc", SeedTime: 120 * time.Hour},
+		},
+	}, ""))
+	t.Cleanup(func() { _ = tracker.Init(tracker.Config{}, "") })
+
+	exp, err := Compile(&config.FilterConfiguration{
+		Remove: []string{"TrackerSeedTimeRequired > 0 && SeedingHours > TrackerSeedTimeRequired + 12"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		torrent config.Torrent
+		want    bool
+	}{
+		{name: "seeded long enough", torrent: config.Torrent{TrackerName: "aither.cc", SeedingHours: 133}, want: true},
+		{name: "hit and run", torrent: config.Torrent{TrackerName: "aither.cc", SeedingHours: 131}},
+		{name: "tracker without seed time", torrent: config.Torrent{TrackerName: "other.example", SeedingHours: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := CheckTorrentSingleMatch(context.Background(), &tt.torrent, exp.Removes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, match)
+		})
+	}
+}
+
+func TestCheck_IsPaused(t *testing.T) {
+	exp, err := Compile(&config.FilterConfiguration{
+		Pause: []string{"!IsPaused() && Ratio > 2"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		torrent config.Torrent
+		want    bool
+	}{
+		{name: "seeding", torrent: config.Torrent{State: "uploading", Ratio: 3}, want: true},
+		{name: "paused", torrent: config.Torrent{State: "pausedUP", Ratio: 3}},
+		{name: "stopped", torrent: config.Torrent{State: "stoppedUP", Ratio: 3}},
+		{name: "deluge paused", torrent: config.Torrent{State: "Paused", Ratio: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := CheckTorrentSingleMatch(context.Background(), &tt.torrent, exp.Pauses)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, match)
+		})
+	}
+}
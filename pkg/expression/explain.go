@@ -0,0 +1,97 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/expr-lang/expr"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// Evaluation is the result of a single expression evaluated against a torrent
+type Evaluation struct {
+	Expression string `json:"expression"`
+	Evaluated  bool   `json:"evaluated"`
+	Result     bool   `json:"result"`
+	// Reason explains why the expression was not evaluated
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ExplainSingleMatch evaluates expressions the way CheckTorrentSingleMatch does, reporting the result of every expression.
+// Expressions after the first match (or failure) are not evaluated.
+func ExplainSingleMatch(ctx context.Context, t *config.Torrent, expressions []CompiledExpression) ([]Evaluation, bool) {
+	env := &evalContext{Torrent: t, ctx: ctx}
+	evaluations := make([]Evaluation, 0, len(expressions))
+	matched := false
+	reason := ""
+
+	for _, expression := range expressions {
+		if reason != "" {
+			evaluations = append(evaluations, Evaluation{Expression: expression.Text, Reason: reason})
+			continue
+		}
+
+		e := evaluate(env, expression)
+		evaluations = append(evaluations, e)
+
+		switch {
+		case e.Error != "":
+			reason = "an earlier expression failed"
+		case e.Result:
+			matched = true
+			reason = "an earlier expression matched"
+		}
+	}
+
+	return evaluations, matched
+}
+
+// ExplainAllMatch evaluates expressions the way CheckTorrentAllMatch does, reporting the result of every expression.
+// Expressions after a failure are not evaluated.
+func ExplainAllMatch(ctx context.Context, t *config.Torrent, expressions []CompiledExpression) ([]Evaluation, bool) {
+	env := &evalContext{Torrent: t, ctx: ctx}
+	evaluations := make([]Evaluation, 0, len(expressions))
+	matched := true
+	failed := false
+
+	for _, expression := range expressions {
+		if failed {
+			evaluations = append(evaluations, Evaluation{Expression: expression.Text, Reason: "an earlier expression failed"})
+			continue
+		}
+
+		e := evaluate(env, expression)
+		evaluations = append(evaluations, e)
+
+		if e.Error != "" {
+			failed = true
+		}
+		if !e.Result {
+			matched = false
+		}
+	}
+
+	return evaluations, matched
+}
+
+func evaluate(env *evalContext, expression CompiledExpression) Evaluation {
+	e := Evaluation{Expression: expression.Text, Evaluated: true}
+	env.load(expression.Text)
+
+	result, err := expr.Run(expression.Program, env)
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+
+	b, ok := result.(bool)
+	if !ok {
+		e.Error = fmt.Sprintf("expression returned %T instead of bool", result)
+		return e
+	}
+
+	e.Result = b
+	return e
+}
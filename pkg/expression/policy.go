@@ -0,0 +1,101 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// criteria of the retention policy
+const (
+	PolicyAge        = "age"
+	PolicyRatio      = "ratio"
+	PolicySize       = "size"
+	PolicyHardlinked = "hardlinked"
+	PolicyTracker    = "tracker"
+)
+
+// PolicyScore returns the expression scoring the retention of torrents with the criteria of policy
+func PolicyScore(policy config.PolicyConfig) (string, error) {
+	if len(policy.Criteria) == 0 {
+		return "", fmt.Errorf("policy must set at least one criterion")
+	}
+
+	terms := make([]string, 0, len(policy.Criteria))
+	for _, c := range policy.Criteria {
+		var value string
+
+		switch c.Criterion {
+		case PolicyAge, PolicyRatio, PolicySize:
+			if c.Max <= 0 {
+				return "", fmt.Errorf("policy criterion %s must set a max above 0", c.Criterion)
+			}
+
+			field := map[string]string{
+				PolicyAge:   "SeedingDays",
+				PolicyRatio: "Ratio",
+				PolicySize:  "TotalBytes / 1073741824",
+			}[c.Criterion]
+			value = fmt.Sprintf("min(%s / %s, 1)", field, formatFloat(c.Max))
+
+		case PolicyHardlinked:
+			value = "(HardlinkedOutsideClient ? 1 : 0)"
+
+		case PolicyTracker:
+			if len(c.Trackers) == 0 {
+				return "", fmt.Errorf("policy criterion %s must list trackers", c.Criterion)
+			}
+
+			quoted := make([]string, 0, len(c.Trackers))
+			for _, t := range c.Trackers {
+				quoted = append(quoted, strconv.Quote(t))
+			}
+			value = fmt.Sprintf("(TrackerName in [%s] ? 1 : 0)", strings.Join(quoted, ", "))
+
+		default:
+			return "", fmt.Errorf("invalid policy criterion %q, must be one of: %s, %s, %s, %s, %s", c.Criterion,
+				PolicyAge, PolicyRatio, PolicySize, PolicyHardlinked, PolicyTracker)
+		}
+
+		terms = append(terms, fmt.Sprintf("%s * %s", formatFloat(c.Weight), value))
+	}
+
+	return strings.Join(terms, " + "), nil
+}
+
+// PolicyExpression returns the remove expression of policy, empty when the policy is disabled
+func PolicyExpression(policy config.PolicyConfig) (string, error) {
+	if policy.KeepThreshold == nil {
+		return "", nil
+	}
+
+	score, err := PolicyScore(policy)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s < %s", score, formatFloat(*policy.KeepThreshold)), nil
+}
+
+// compilePolicy compiles the remove expression of policy, nil when the policy is disabled
+func compilePolicy(policy config.PolicyConfig) (*CompiledExpression, error) {
+	text, err := PolicyExpression(policy)
+	if err != nil || text == "" {
+		return nil, err
+	}
+
+	program, err := expr.Compile(text, expr.Env(&evalContext{}), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("compile policy expression: %q: %w", text, err)
+	}
+
+	return &CompiledExpression{Program: program, Text: text, Section: "policy"}, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,86 @@
+package expression
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestCompilePolicy(t *testing.T) {
+	threshold := 3.0
+	policy := config.PolicyConfig{
+		KeepThreshold: &threshold,
+		Criteria: []config.PolicyCriterion{
+			{Criterion: PolicyRatio, Weight: 2, Max: 2},
+			{Criterion: PolicyAge, Weight: -1, Max: 30},
+			{Criterion: PolicyTracker, Weight: 3, Trackers: []string{"passthepopcorn.me"}},
+			{Criterion: PolicyHardlinked, Weight: 5},
+			{Criterion: PolicySize, Weight: 1, Max: 100},
+		},
+	}
+
+	exp, err := Compile(&config.FilterConfiguration{Policy: policy})
+	require.NoError(t, err)
+	require.Len(t, exp.Removes, 1)
+	assert.Equal(t, "policy", exp.Removes[0].Section)
+
+	tests := []struct {
+		name       string
+		torrent    config.Torrent
+		wantRemove bool
+	}{
+		{
+			name:       "low ratio seeded long",
+			torrent:    config.Torrent{Ratio: 0.5, SeedingDays: 60},
+			wantRemove: true,
+		},
+		{
+			name:    "important tracker",
+			torrent: config.Torrent{Ratio: 2, SeedingDays: 60, TrackerName: "passthepopcorn.me"},
+		},
+		{
+			name:    "hardlinked",
+			torrent: config.Torrent{SeedingDays: 60, HardlinkedOutsideClient: true},
+		},
+		{
+			name:    "ratio and size",
+			torrent: config.Torrent{Ratio: 4, SeedingDays: 0, TotalBytes: 400 << 30},
+		},
+		{
+			name:       "ratio capped",
+			torrent:    config.Torrent{Ratio: 40, SeedingDays: 30},
+			wantRemove: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remove, err := CheckTorrentSingleMatch(context.Background(), &tt.torrent, exp.Removes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemove, remove)
+		})
+	}
+}
+
+func TestCompilePolicy_Invalid(t *testing.T) {
+	threshold := 1.0
+
+	for _, criteria := range [][]config.PolicyCriterion{
+		nil,
+		{{Criterion: "seeds", Weight: 1}},
+		{{Criterion: PolicyRatio, Weight: 1}},
+		{{Criterion: PolicyTracker, Weight: 1}},
+	} {
+		_, err := Compile(&config.FilterConfiguration{Policy: config.PolicyConfig{KeepThreshold: &threshold, Criteria: criteria}})
+		assert.Error(t, err)
+	}
+
+	// without a keep threshold the policy is disabled
+	exp, err := Compile(&config.FilterConfiguration{Policy: config.PolicyConfig{Criteria: []config.PolicyCriterion{{Criterion: "seeds"}}}})
+	require.NoError(t, err)
+	assert.Empty(t, exp.Removes)
+}
@@ -0,0 +1,42 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// RankExpression scores torrents, torrents with a higher rank are preferred
+type RankExpression struct {
+	Program *vm.Program
+	Text    string
+}
+
+// CompileRank compiles text, which has to evaluate to a number
+func CompileRank(text string) (*RankExpression, error) {
+	program, err := expr.Compile(text, expr.Env(&evalContext{}), expr.AsFloat64())
+	if err != nil {
+		return nil, fmt.Errorf("compile rank expression: %q: %w", text, err)
+	}
+
+	return &RankExpression{Program: program, Text: text}, nil
+}
+
+func (r *RankExpression) Rank(ctx context.Context, t *config.Torrent) (float64, error) {
+	env := &evalContext{Torrent: t, ctx: ctx}
+	env.load(r.Text)
+	result, err := expr.Run(r.Program, env)
+	if err != nil {
+		return 0, fmt.Errorf("run rank expression: %w", err)
+	}
+
+	rank, ok := result.(float64)
+	if !ok {
+		return 0, fmt.Errorf("rank expression returned %T instead of a number", result)
+	}
+	return rank, nil
+}
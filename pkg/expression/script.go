@@ -0,0 +1,149 @@
+package expression
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+const (
+	// scriptsDir holds the scripts available to the Script and ScriptValue functions, it is kept next to the config file
+	scriptsDir    = "scripts"
+	scriptTimeout = 30 * time.Second
+)
+
+// scriptResult is the outcome of a script for a torrent
+type scriptResult struct {
+	matched bool
+	output  string
+	err     error
+}
+
+var (
+	scriptsMu sync.Mutex
+	// scriptResults caches the results per script and torrent hash, as the same script is often used by several
+	// expressions and sections evaluated for a torrent during a run, until ResetScriptResults is called
+	scriptResults = make(map[string]scriptResult)
+	// scriptsPath overrides the default scripts directory, used by tests
+	scriptsPath string
+)
+
+// ResetScriptResults drops the cached results of scripts, so they are run again for the next run, e.g. the next step
+// of a pipeline or the next webhook of serve
+func ResetScriptResults() {
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	clear(scriptResults)
+}
+
+func (e *evalContext) Script(name string) (bool, error) {
+	if e.Torrent == nil {
+		return false, nil
+	}
+
+	r := e.runScript(name)
+	return r.matched, r.err
+}
+
+func (e *evalContext) ScriptValue(name string) (float64, error) {
+	if e.Torrent == nil {
+		return 0, nil
+	}
+
+	r := e.runScript(name)
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	value, err := strconv.ParseFloat(r.output, 64)
+	if err != nil {
+		return 0, fmt.Errorf("script %q returned %q instead of a number", name, r.output)
+	}
+	return value, nil
+}
+
+// runScript runs the script name with the torrent as JSON on stdin. Exit status 0 matches, 1 does not match,
+// anything else is an error.
+func (e *evalContext) runScript(name string) scriptResult {
+	key := name + "/" + e.Hash
+
+	scriptsMu.Lock()
+	r, ok := scriptResults[key]
+	scriptsMu.Unlock()
+	if ok {
+		return r
+	}
+
+	r = executeScript(e.ctx, name, e.Torrent)
+
+	scriptsMu.Lock()
+	scriptResults[key] = r
+	scriptsMu.Unlock()
+
+	return r
+}
+
+func executeScript(ctx context.Context, name string, t *config.Torrent) scriptResult {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return scriptResult{err: fmt.Errorf("invalid script name: %q", name)}
+	}
+
+	dir := scriptsPath
+	if dir == "" {
+		dir = config.StatePath(scriptsDir)
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return scriptResult{err: fmt.Errorf("script %q: %w", name, err)}
+	}
+
+	input, err := json.Marshal(t)
+	if err != nil {
+		return scriptResult{err: fmt.Errorf("marshal torrent: %w", err)}
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, scriptTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		log.Debugf("Script %q for %q: %s", name, t.Name, msg)
+	}
+
+	r := scriptResult{output: strings.TrimSpace(stdout.String())}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		r.matched = true
+	case ctx.Err() == context.DeadlineExceeded:
+		r.err = fmt.Errorf("script %q timed out after %s", name, scriptTimeout)
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		r.matched = false
+	default:
+		r.err = fmt.Errorf("script %q: %w", name, err)
+	}
+
+	return r
+}
@@ -0,0 +1,113 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("scripts are tested with shell scripts")
+	}
+
+	scriptsPath = t.TempDir()
+	t.Cleanup(func() {
+		scriptsPath = ""
+		ResetScriptResults()
+	})
+
+	scripts := map[string]string{
+		// matches torrents of the movies label, reading the torrent from stdin
+		"is-movie": `grep -q '"Label":"movies"'`,
+		"score":    `echo 42.5`,
+		"broken":   `exit 3`,
+		"text":     `echo not-a-number`,
+	}
+	for name, body := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(scriptsPath, name), []byte("#!/bin/sh\n"+body+"\n"), 0700))
+	}
+
+	filter := &config.FilterConfiguration{Remove: []string{`Script("is-movie") && ScriptValue("score") > 40`}}
+	exp, err := Compile(filter)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		torrent       config.Torrent
+		expression    string
+		expectedMatch bool
+		expectedErr   bool
+	}{
+		{name: "match", torrent: config.Torrent{Hash: "a", Label: "movies"}, expectedMatch: true},
+		{name: "no_match", torrent: config.Torrent{Hash: "b", Label: "tv"}},
+		{name: "failing_script", torrent: config.Torrent{Hash: "c"}, expression: `Script("broken")`, expectedErr: true},
+		{name: "missing_script", torrent: config.Torrent{Hash: "c"}, expression: `Script("missing")`, expectedErr: true},
+		{name: "invalid_name", torrent: config.Torrent{Hash: "c"}, expression: `Script("../is-movie")`, expectedErr: true},
+		{name: "not_a_number", torrent: config.Torrent{Hash: "c"}, expression: `ScriptValue("text") > 1`, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expressions := exp.Removes
+			if tt.expression != "" {
+				e, err := Compile(&config.FilterConfiguration{Remove: []string{tt.expression}})
+				require.NoError(t, err)
+				expressions = e.Removes
+			}
+
+			match, err := CheckTorrentSingleMatch(context.Background(), &tt.torrent, expressions)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMatch, match)
+		})
+	}
+}
+
+func TestResetScriptResults(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("scripts are tested with shell scripts")
+	}
+
+	scriptsPath = t.TempDir()
+	t.Cleanup(func() {
+		scriptsPath = ""
+		ResetScriptResults()
+	})
+
+	// the script matches once its marker file exists
+	marker := filepath.Join(scriptsPath, "marker")
+	script := fmt.Sprintf("#!/bin/sh\ntest -e %q\n", marker)
+	require.NoError(t, os.WriteFile(filepath.Join(scriptsPath, "marked"), []byte(script), 0700))
+
+	exp, err := Compile(&config.FilterConfiguration{Remove: []string{`Script("marked")`}})
+	require.NoError(t, err)
+
+	torrent := &config.Torrent{Hash: "a"}
+	match, err := CheckTorrentSingleMatch(context.Background(), torrent, exp.Removes)
+	require.NoError(t, err)
+	assert.False(t, match)
+
+	require.NoError(t, os.WriteFile(marker, nil, 0600))
+
+	// the result is cached during a run
+	match, err = CheckTorrentSingleMatch(context.Background(), torrent, exp.Removes)
+	require.NoError(t, err)
+	assert.False(t, match)
+
+	ResetScriptResults()
+	match, err = CheckTorrentSingleMatch(context.Background(), torrent, exp.Removes)
+	require.NoError(t, err)
+	assert.True(t, match)
+}
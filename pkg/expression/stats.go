@@ -0,0 +1,62 @@
+package expression
+
+import (
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+)
+
+// RuleStats holds how often an expression was evaluated and matched, and the time spent evaluating it
+type RuleStats struct {
+	Section     string
+	Text        string
+	Evaluations int
+	Matches     int
+	Duration    time.Duration
+}
+
+var ruleStats = struct {
+	sync.Mutex
+	rules map[*vm.Program]*RuleStats
+	order []*RuleStats
+}{rules: make(map[*vm.Program]*RuleStats)}
+
+func recordEvaluation(e CompiledExpression, matched bool, d time.Duration) {
+	ruleStats.Lock()
+	defer ruleStats.Unlock()
+
+	s, ok := ruleStats.rules[e.Program]
+	if !ok {
+		s = &RuleStats{Section: e.Section, Text: e.Text}
+		ruleStats.rules[e.Program] = s
+		ruleStats.order = append(ruleStats.order, s)
+	}
+
+	s.Evaluations++
+	if matched {
+		s.Matches++
+	}
+	s.Duration += d
+}
+
+// Stats returns the statistics of every expression evaluated so far, in order of their first evaluation
+func Stats() []RuleStats {
+	ruleStats.Lock()
+	defer ruleStats.Unlock()
+
+	stats := make([]RuleStats, 0, len(ruleStats.order))
+	for _, s := range ruleStats.order {
+		stats = append(stats, *s)
+	}
+	return stats
+}
+
+// ResetStats clears the statistics of all expressions
+func ResetStats() {
+	ruleStats.Lock()
+	defer ruleStats.Unlock()
+
+	ruleStats.rules = make(map[*vm.Program]*RuleStats)
+	ruleStats.order = nil
+}
@@ -0,0 +1,43 @@
+package expression
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestStats(t *testing.T) {
+	ResetStats()
+	t.Cleanup(ResetStats)
+
+	exp, err := Compile(&config.FilterConfiguration{
+		Remove: []string{`Ratio > 2`, `Label == "movies"`},
+	})
+	require.NoError(t, err)
+
+	torrents := []config.Torrent{
+		{Name: "a", Ratio: 3},
+		{Name: "b", Ratio: 1, Label: "movies"},
+		{Name: "c", Ratio: 1},
+	}
+	for _, tr := range torrents {
+		_, err := CheckTorrentSingleMatch(context.Background(), &tr, exp.Removes)
+		require.NoError(t, err)
+	}
+
+	stats := Stats()
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, "remove", stats[0].Section)
+	assert.Equal(t, `Ratio > 2`, stats[0].Text)
+	assert.Equal(t, 3, stats[0].Evaluations)
+	assert.Equal(t, 1, stats[0].Matches)
+
+	assert.Equal(t, `Label == "movies"`, stats[1].Text)
+	assert.Equal(t, 2, stats[1].Evaluations)
+	assert.Equal(t, 1, stats[1].Matches)
+}
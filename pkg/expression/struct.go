@@ -1,6 +1,12 @@
 package expression
 
-import "github.com/expr-lang/expr/vm"
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr/vm"
+)
 
 const (
 	TagModeAdd    = "add"
@@ -11,14 +17,44 @@ const (
 type CompiledExpression struct {
 	Program *vm.Program
 	Text    string
+	// Section is the part of the filter the expression belongs to, e.g. remove or label/<name>
+	Section string
 }
 
 type Expressions struct {
-	Ignores []CompiledExpression
-	Removes []CompiledExpression
-	Pauses  []CompiledExpression
-	Labels  []*LabelExpression
-	Tags    []*TagExpression
+	Ignores     []CompiledExpression
+	Removes     []CompiledExpression
+	Pauses      []CompiledExpression
+	Resumes     []CompiledExpression
+	Rechecks    []CompiledExpression
+	Reannounces []CompiledExpression
+	PruneFiles  []CompiledExpression
+	Labels      []*LabelExpression
+	Moves       []*MoveExpression
+	Tags        []*TagExpression
+
+	SeedLimits []*SeedLimitExpression
+}
+
+// Uses reports whether any expression contains identifier, e.g. a field or function name
+func (e *Expressions) Uses(identifier string) bool {
+	all := slices.Concat(e.Ignores, e.Removes, e.Pauses, e.Resumes, e.Rechecks, e.Reannounces, e.PruneFiles)
+	for _, l := range e.Labels {
+		all = append(all, l.Updates...)
+	}
+	for _, m := range e.Moves {
+		all = append(all, m.Updates...)
+	}
+	for _, t := range e.Tags {
+		all = append(all, t.Updates...)
+	}
+	for _, s := range e.SeedLimits {
+		all = append(all, s.Updates...)
+	}
+
+	return slices.ContainsFunc(all, func(c CompiledExpression) bool {
+		return strings.Contains(c.Text, identifier)
+	})
 }
 
 type LabelExpression struct {
@@ -26,9 +62,22 @@ type LabelExpression struct {
 	Updates []CompiledExpression
 }
 
+type MoveExpression struct {
+	Path    string
+	Updates []CompiledExpression
+}
+
 type TagExpression struct {
 	Name     string
 	Mode     string
 	UploadKb *int
 	Updates  []CompiledExpression
 }
+
+type SeedLimitExpression struct {
+	Name                string
+	Ratio               *float64
+	SeedingTime         *time.Duration
+	InactiveSeedingTime *time.Duration
+	Updates             []CompiledExpression
+}
@@ -0,0 +1,84 @@
+package formatting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	// UnitsIEC formats sizes with binary units (KiB, MiB, GiB)
+	UnitsIEC = "iec"
+	// UnitsSI formats sizes with decimal units (kB, MB, GB), matching most torrent client UIs
+	UnitsSI = "si"
+
+	// DurationsDefault formats durations the Go way, truncated to milliseconds (e.g. 1h2m3.456s)
+	DurationsDefault = "default"
+	// DurationsHuman formats durations truncated to seconds with spaces between units (e.g. 1h 2m 3s)
+	DurationsHuman = "human"
+)
+
+var (
+	units     = UnitsIEC
+	durations = DurationsDefault
+)
+
+// Init sets the unit system and duration format used by Bytes and Duration. Empty values keep the defaults.
+func Init(unitSystem string, durationFormat string) error {
+	switch u := strings.ToLower(unitSystem); u {
+	case "":
+	case UnitsIEC, UnitsSI:
+		units = u
+	default:
+		return fmt.Errorf("unsupported units: %q, expected %q or %q", unitSystem, UnitsIEC, UnitsSI)
+	}
+
+	switch f := strings.ToLower(durationFormat); f {
+	case "":
+	case DurationsDefault, DurationsHuman:
+		durations = f
+	default:
+		return fmt.Errorf("unsupported duration format: %q, expected %q or %q", durationFormat, DurationsDefault,
+			DurationsHuman)
+	}
+
+	return nil
+}
+
+// Bytes formats a size using the configured unit system
+func Bytes(b uint64) string {
+	if units == UnitsSI {
+		return humanize.Bytes(b)
+	}
+
+	return humanize.IBytes(b)
+}
+
+// Duration formats a duration using the configured duration format
+func Duration(d time.Duration) string {
+	if durations != DurationsHuman {
+		return d.Truncate(time.Millisecond).String()
+	}
+
+	d = d.Truncate(time.Second)
+	if d < time.Second {
+		return "0s"
+	}
+
+	var parts []string
+	if h := d / time.Hour; h > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", h))
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", m))
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 {
+		parts = append(parts, fmt.Sprintf("%ds", s))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,37 @@
+package formatting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytes(t *testing.T) {
+	t.Cleanup(func() { units = UnitsIEC })
+
+	require.NoError(t, Init("", ""))
+	assert.Equal(t, "1.5 GiB", Bytes(1610612736))
+
+	require.NoError(t, Init("SI", ""))
+	assert.Equal(t, "1.6 GB", Bytes(1610612736))
+
+	assert.Error(t, Init("bits", ""))
+}
+
+func TestDuration(t *testing.T) {
+	t.Cleanup(func() { durations = DurationsDefault })
+
+	d := time.Hour + 2*time.Minute + 3456*time.Millisecond
+
+	require.NoError(t, Init("", DurationsDefault))
+	assert.Equal(t, "1h2m3.456s", Duration(d))
+
+	require.NoError(t, Init("", DurationsHuman))
+	assert.Equal(t, "1h 2m 3s", Duration(d))
+	assert.Equal(t, "5m", Duration(5*time.Minute))
+	assert.Equal(t, "0s", Duration(500*time.Millisecond))
+
+	assert.Error(t, Init("", "iso8601"))
+}
@@ -2,12 +2,12 @@ package hardlinkfilemap
 
 import (
 	"os"
-	"strings"
 
 	"github.com/scylladb/go-set/strset"
 
 	"github.com/autobrr/tqm/pkg/config"
 	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
 )
 
 func New(torrents map[string]config.Torrent, torrentPathMapping map[string]string) HardlinkFileMapI {
@@ -25,13 +25,7 @@ func New(torrents map[string]config.Torrent, torrentPathMapping map[string]strin
 }
 
 func (t *HardlinkFileMap) considerPathMapping(path string) string {
-	for mapFrom, mapTo := range t.torrentPathMapping {
-		if strings.HasPrefix(path, mapFrom) {
-			return strings.Replace(path, mapFrom, mapTo, 1)
-		}
-	}
-
-	return path
+	return paths.MapPath(path, t.torrentPathMapping)
 }
 
 func (t *HardlinkFileMap) linkInfoByPath(path string) (string, uint64, bool) {
@@ -175,6 +169,24 @@ func (t *HardlinkFileMap) NoInstances(torrent config.Torrent) bool {
 	return true
 }
 
+// FileID returns the identifier of the underlying file of path (as reported by the client), hardlinks of a file share it
+func (t *HardlinkFileMap) FileID(path string) (string, bool) {
+	id, _, ok := t.linkInfoByPath(t.considerPathMapping(path))
+	return id, ok
+}
+
+// ContainsLocalPath reports whether the local file at path (no mapping applied) is the underlying file of a torrent file,
+// e.g. the same file seen through a bind mount
+func (t *HardlinkFileMap) ContainsLocalPath(path string) bool {
+	id, _, ok := t.linkInfoByPath(path)
+	if !ok {
+		return false
+	}
+
+	_, exists := t.hardlinkFileMap[id]
+	return exists
+}
+
 func (t *HardlinkFileMap) Length() int {
 	return len(t.hardlinkFileMap)
 }
@@ -0,0 +1,32 @@
+package hardlinkfilemap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestHardlinkFileMap_ContainsLocalPath(t *testing.T) {
+	dir := t.TempDir()
+	torrentFile := filepath.Join(dir, "torrent.mkv")
+	linked := filepath.Join(dir, "linked.mkv")
+	other := filepath.Join(dir, "other.mkv")
+
+	require.NoError(t, os.WriteFile(torrentFile, []byte("data"), 0600))
+	require.NoError(t, os.Link(torrentFile, linked))
+	require.NoError(t, os.WriteFile(other, []byte("data"), 0600))
+
+	hfm := New(map[string]config.Torrent{
+		"a": {Hash: "a", Downloaded: true, Files: []string{"/downloads/torrent.mkv"}},
+	}, map[string]string{"/downloads": dir})
+
+	assert.True(t, hfm.ContainsLocalPath(torrentFile))
+	assert.True(t, hfm.ContainsLocalPath(linked))
+	assert.False(t, hfm.ContainsLocalPath(other))
+	assert.False(t, hfm.ContainsLocalPath(filepath.Join(dir, "missing.mkv")))
+}
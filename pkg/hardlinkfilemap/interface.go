@@ -8,5 +8,7 @@ type HardlinkFileMapI interface {
 	NoInstances(torrent config.Torrent) bool
 	IsTorrentUnique(torrent config.Torrent) bool
 	HardlinkedOutsideClient(torrent config.Torrent) bool
+	FileID(path string) (string, bool)
+	ContainsLocalPath(path string) bool
 	Length() int
 }
@@ -27,6 +27,14 @@ func (h *noopHardlinkFileMap) HardlinkedOutsideClient(torrent config.Torrent) bo
 	return false
 }
 
+func (h *noopHardlinkFileMap) FileID(path string) (string, bool) {
+	return "", false
+}
+
+func (h *noopHardlinkFileMap) ContainsLocalPath(path string) bool {
+	return false
+}
+
 func (h *noopHardlinkFileMap) Length() int {
 	return 0
 }
@@ -0,0 +1,164 @@
+// Package history keeps a log of the actions taken on torrents and files, so they can be reviewed later
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+type Action string
+
+const (
+	ActionRemove  Action = "remove"
+	ActionRelabel Action = "relabel"
+	ActionRetag   Action = "retag"
+	ActionOrphan  Action = "orphan"
+	// ActionPruneFiles skips and deletes the unwanted files of a torrent
+	ActionPruneFiles Action = "prune-files"
+)
+
+// historyFile is kept next to the config file, one JSON entry per line
+const historyFile = "history.jsonl"
+
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Client string    `json:"client"`
+	Action Action    `json:"action"`
+	Hash   string    `json:"hash,omitempty"`
+	// Name is the name of the torrent or the path of the orphan
+	Name    string `json:"name"`
+	Tracker string `json:"tracker,omitempty"`
+	// Reason is the expression that matched
+	Reason string `json:"reason,omitempty"`
+	// Detail describes the change, e.g. the new label or tags
+	Detail string `json:"detail,omitempty"`
+	// Reclaimed is the disk space freed by the action in bytes
+	Reclaimed int64 `json:"reclaimed,omitempty"`
+}
+
+var (
+	log = logger.GetLogger("history")
+
+	mu sync.Mutex
+	// path overrides the default history file, used by tests
+	path string
+)
+
+// Path returns the path of the history file
+func Path() string {
+	if path != "" {
+		return path
+	}
+	return config.StatePath(historyFile)
+}
+
+// Record appends e to the history file, failures are logged as the action itself succeeded
+func Record(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	if err := appendEntry(Path(), e); err != nil {
+		log.WithError(err).Warnf("Failed recording %s of %q in history", e.Action, e.Name)
+	}
+}
+
+func appendEntry(p string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+
+	return f.Close()
+}
+
+// Query selects history entries, zero values match every entry
+type Query struct {
+	Since  time.Time
+	Client string
+	Action Action
+	// Search matches the name, hash, tracker and reason case-insensitively
+	Search string
+}
+
+func (q Query) Match(e Entry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if q.Client != "" && !strings.EqualFold(q.Client, e.Client) {
+		return false
+	}
+	if q.Action != "" && q.Action != e.Action {
+		return false
+	}
+	if q.Search == "" {
+		return true
+	}
+
+	search := strings.ToLower(q.Search)
+	for _, s := range []string{e.Name, e.Hash, e.Tracker, e.Reason} {
+		if strings.Contains(strings.ToLower(s), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the entries of the history file at p matching q, oldest first. A missing file has no entries.
+func Read(p string, q Query) ([]Entry, error) {
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// e.g. a line cut short by a crash, the remaining entries are still valid
+			log.WithError(err).Warnf("Skipping invalid history line %d", line)
+			continue
+		}
+
+		if q.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRead(t *testing.T) {
+	path = filepath.Join(t.TempDir(), historyFile)
+	t.Cleanup(func() { path = "" })
+
+	now := time.Now()
+	Record(Entry{Time: now.Add(-48 * time.Hour), Client: "qbt", Action: ActionRemove, Name: "Old.Movie", Reason: "IsUnregistered()", Reclaimed: 1024})
+	Record(Entry{Time: now, Client: "qbt", Action: ActionRelabel, Name: "New.Show", Tracker: "beyond-hd.me", Detail: "label: tv → archive"})
+	Record(Entry{Time: now, Client: "deluge", Action: ActionOrphan, Name: "/data/orphan.mkv", Reclaimed: 2048})
+
+	// a line cut short is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"time":`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{name: "all", expected: []string{"Old.Movie", "New.Show", "/data/orphan.mkv"}},
+		{name: "since", query: Query{Since: now.Add(-time.Hour)}, expected: []string{"New.Show", "/data/orphan.mkv"}},
+		{name: "client_and_action", query: Query{Client: "QBT", Action: ActionRemove}, expected: []string{"Old.Movie"}},
+		{name: "search_tracker", query: Query{Search: "BEYOND"}, expected: []string{"New.Show"}},
+		{name: "search_reason", query: Query{Search: "unregistered"}, expected: []string{"Old.Movie"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Read(path, tt.query)
+			require.NoError(t, err)
+
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	entries, err := Read(filepath.Join(t.TempDir(), "missing.jsonl"), Query{})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,133 @@
+// Package hooks executes user configured commands before and after tqm removes a torrent or deletes an orphan,
+// describing the action through environment variables
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+const (
+	ActionRemove = "remove"
+	ActionOrphan = "orphan"
+
+	defaultTimeout = time.Minute
+)
+
+var log = logger.GetLogger("hooks")
+
+// Event describes the action a hook is run for
+type Event struct {
+	Action string
+	Client string
+	Hash   string
+	// Name is the name of the torrent or the path of the orphan
+	Name    string
+	Path    string
+	Tracker string
+	Label   string
+	// Reason is the expression that matched
+	Reason string
+	// Size is the size of the torrent or orphan in bytes
+	Size        int64
+	DataDeleted bool
+	DryRun      bool
+}
+
+// Env returns the environment variables describing e
+func (e Event) Env() []string {
+	return []string{
+		"TQM_ACTION=" + e.Action,
+		"TQM_CLIENT=" + e.Client,
+		"TQM_HASH=" + e.Hash,
+		"TQM_NAME=" + e.Name,
+		"TQM_PATH=" + e.Path,
+		"TQM_TRACKER=" + e.Tracker,
+		"TQM_LABEL=" + e.Label,
+		"TQM_REASON=" + e.Reason,
+		"TQM_SIZE=" + strconv.FormatInt(e.Size, 10),
+		"TQM_DATA_DELETED=" + strconv.FormatBool(e.DataDeleted),
+		"TQM_DRY_RUN=" + strconv.FormatBool(e.DryRun),
+	}
+}
+
+// Run executes hooks one after the other, failures are logged as the action itself succeeded
+func Run(ctx context.Context, hooks []config.HookConfig, e Event) {
+	for _, hook := range hooks {
+		if err := run(ctx, hook, e); err != nil {
+			log.WithError(err).Warnf("Failed running %s hook %q for %q", e.Action, hook.Command, e.Name)
+		}
+	}
+}
+
+// Veto executes hooks one after the other until one of them exits with a non-zero status, vetoing the action.
+// Hooks which cannot be run veto the action as well. The reason is the output of the vetoing hook (or the error).
+func Veto(ctx context.Context, hooks []config.HookConfig, e Event) (bool, string) {
+	for _, hook := range hooks {
+		output, err := execute(ctx, hook, e)
+		if err == nil {
+			continue
+		}
+
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			log.WithError(err).Warnf("Failed running %s veto hook %q for %q, keeping it", e.Action, hook.Command, e.Name)
+		}
+
+		reason := err.Error()
+		if output != "" {
+			reason = output
+		}
+		return true, fmt.Sprintf("%s: %s", hook.Command, reason)
+	}
+
+	return false, ""
+}
+
+func run(ctx context.Context, hook config.HookConfig, e Event) error {
+	_, err := execute(ctx, hook, e)
+	return err
+}
+
+// execute runs hook and returns its trimmed output
+func execute(ctx context.Context, hook config.HookConfig, e Event) (string, error) {
+	if hook.Command == "" {
+		return "", errors.New("no command configured")
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Env = append(os.Environ(), e.Env()...)
+
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if out != "" {
+		log.Debugf("Output of %s hook %q: %s", e.Action, hook.Command, out)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return out, fmt.Errorf("timed out after %s", timeout)
+		}
+		return out, err
+	}
+
+	log.Debugf("Ran %s hook %q for %q in %s", e.Action, hook.Command, e.Name, time.Since(start))
+	return out, nil
+}
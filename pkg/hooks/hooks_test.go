@@ -0,0 +1,133 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are tested with a shell script")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+
+	e := Event{
+		Action:      ActionRemove,
+		Client:      "qbt",
+		Hash:        "abc",
+		Name:        "Some.Movie",
+		Path:        "/data/movies",
+		Reason:      "IsUnregistered()",
+		Size:        1024,
+		DataDeleted: true,
+	}
+
+	tests := []struct {
+		name     string
+		hook     config.HookConfig
+		expected string
+	}{
+		{
+			name:     "env",
+			hook:     config.HookConfig{Command: "sh", Args: []string{"-c", `echo "$TQM_ACTION $TQM_HASH $TQM_NAME $TQM_SIZE $TQM_DATA_DELETED" > ` + out}},
+			expected: "remove abc Some.Movie 1024 true",
+		},
+		{
+			name: "failure_is_not_fatal",
+			hook: config.HookConfig{Command: "sh", Args: []string{"-c", "exit 1"}},
+		},
+		{
+			name: "timeout",
+			hook: config.HookConfig{Command: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond},
+		},
+		{
+			name: "missing_command",
+			hook: config.HookConfig{Command: filepath.Join(dir, "missing")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(out)
+
+			start := time.Now()
+			Run(context.Background(), []config.HookConfig{tt.hook}, e)
+			assert.Less(t, time.Since(start), 5*time.Second)
+
+			if tt.expected == "" {
+				return
+			}
+
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.TrimSpace(string(data)))
+		})
+	}
+}
+
+func TestVeto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are tested with a shell script")
+	}
+
+	e := Event{Action: ActionRemove, Name: "Some.Movie", Tracker: "tracker.example", DryRun: true}
+
+	tests := []struct {
+		name           string
+		hooks          []config.HookConfig
+		expectedVetoed bool
+		expectedReason string
+	}{
+		{
+			name: "no_hooks",
+		},
+		{
+			name: "allowed",
+			hooks: []config.HookConfig{
+				{Command: "sh", Args: []string{"-c", `[ "$TQM_DRY_RUN" = "true" ]`}},
+			},
+		},
+		{
+			name: "vetoed_with_output",
+			hooks: []config.HookConfig{
+				{Command: "true"},
+				{Command: "sh", Args: []string{"-c", `echo "still seeding on $TQM_TRACKER"; exit 1`}},
+			},
+			expectedVetoed: true,
+			expectedReason: "sh: still seeding on tracker.example",
+		},
+		{
+			name:           "vetoed_without_output",
+			hooks:          []config.HookConfig{{Command: "false"}},
+			expectedVetoed: true,
+			expectedReason: "false: exit status 1",
+		},
+		{
+			name:           "failing_to_run_vetoes",
+			hooks:          []config.HookConfig{{Command: "/nonexistent/hook"}},
+			expectedVetoed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vetoed, reason := Veto(context.Background(), tt.hooks, e)
+			assert.Equal(t, tt.expectedVetoed, vetoed)
+			if tt.expectedReason != "" {
+				assert.Equal(t, tt.expectedReason, reason)
+			}
+		})
+	}
+}
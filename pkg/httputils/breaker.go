@@ -0,0 +1,94 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrBreakerOpen is returned for the requests not sent as the previous requests failed repeatedly
+var ErrBreakerOpen = errors.New("not sent after repeated failures of the api")
+
+// Breaker stops sending the requests of a client after maxFailures consecutive requests failed without a response
+// (e.g. timeouts, or server errors still failing after retrying), until it is reset
+type Breaker struct {
+	maxFailures int
+	// onOpen is called once the breaker opened, with the error of the last failed request
+	onOpen func(err error)
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+}
+
+// NewBreaker returns a breaker opening after maxFailures consecutive failures, it never opens when maxFailures is not
+// positive
+func NewBreaker(maxFailures int, onOpen func(err error)) *Breaker {
+	return &Breaker{maxFailures: maxFailures, onOpen: onOpen}
+}
+
+// Wrap makes client fail its requests with ErrBreakerOpen while the breaker is open
+func (b *Breaker) Wrap(client *http.Client) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if b.Open() {
+			return nil, ErrBreakerOpen
+		}
+
+		res, err := next.RoundTrip(req)
+		b.record(err)
+		return res, err
+	})
+	return client
+}
+
+// Open reports whether requests are no longer sent
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// Reset closes the breaker, so requests are sent again
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.open = false
+}
+
+// record counts the consecutive failed requests, cancelled requests do not count as the API did not fail
+func (b *Breaker) record(err error) {
+	if b.maxFailures <= 0 || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	b.mu.Lock()
+	if err == nil {
+		b.failures = 0
+		b.mu.Unlock()
+		return
+	}
+
+	b.failures++
+	opened := !b.open && b.failures >= b.maxFailures
+	if opened {
+		b.open = true
+	}
+	b.mu.Unlock()
+
+	if opened && b.onOpen != nil {
+		b.onOpen(err)
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,75 @@
+package httputils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			// close the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var opened int
+	b := NewBreaker(3, func(err error) { opened++ })
+	client := b.Wrap(&http.Client{})
+
+	get := func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	// a response resets the count of consecutive failures
+	for range 2 {
+		require.Error(t, get(context.Background()))
+	}
+	fail = false
+	require.NoError(t, get(context.Background()))
+	fail = true
+
+	// cancelled requests are not counted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 5 {
+		require.Error(t, get(ctx))
+	}
+	assert.False(t, b.Open())
+
+	for range 3 {
+		assert.NotErrorIs(t, get(context.Background()), ErrBreakerOpen)
+	}
+	assert.True(t, b.Open())
+	assert.ErrorIs(t, get(context.Background()), ErrBreakerOpen)
+	assert.Equal(t, 1, opened)
+
+	b.Reset()
+	fail = false
+	assert.NoError(t, get(context.Background()))
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := NewBreaker(-1, nil)
+	for range 10 {
+		b.record(assert.AnError)
+	}
+	assert.False(t, b.Open())
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"time"
@@ -37,6 +38,27 @@ func NewRetryableHttpClient(timeout time.Duration, rl ratelimit.Limiter) *http.C
 	return retryClient.StandardClient()
 }
 
+// NewUnixSocketHttpClient returns a client sending all requests over the unix socket at socketPath,
+// regardless of the host in the request url
+func NewUnixSocketHttpClient(socketPath string, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
 func URLWithQuery(base string, q url.Values) (string, error) {
 	u, err := url.Parse(base)
 	if err != nil {
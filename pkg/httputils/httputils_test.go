@@ -0,0 +1,49 @@
+package httputils
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUnixSocketHttpClient(t *testing.T) {
+	// the socket path is kept short, unix socket paths are limited to about 100 characters
+	dir, err := os.MkdirTemp("", "tqm")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "webui.sock")
+
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not supported: %v", err)
+	}
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Host+r.URL.Path)
+	})}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	// the host of the url is only sent along, the request goes to the socket
+	client := NewUnixSocketHttpClient(socket, 5*time.Second)
+	res, err := client.Get("http://qbittorrent.invalid/api/v2/app/version")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "qbittorrent.invalid/api/v2/app/version", string(body))
+
+	// requests fail once nothing listens on the socket anymore
+	require.NoError(t, srv.Close())
+	client.CloseIdleConnections()
+	_, err = client.Get("http://qbittorrent.invalid/")
+	assert.Error(t, err)
+}
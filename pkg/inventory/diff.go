@@ -0,0 +1,127 @@
+package inventory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Orphans is the owner of the files not belonging to any torrent
+const Orphans = "(orphans)"
+
+// FileChange is a file added, removed or resized between two snapshots, sizes are 0 when the file does not exist
+type FileChange struct {
+	Path    string `json:"path"`
+	Owner   string `json:"owner"`
+	OldSize int64  `json:"old_size"`
+	NewSize int64  `json:"new_size"`
+}
+
+// Delta is the change of size in bytes
+func (c FileChange) Delta() int64 {
+	return c.NewSize - c.OldSize
+}
+
+// OwnerChange is the change of the size of the files of a torrent (or of the orphans) between two snapshots
+type OwnerChange struct {
+	Owner   string `json:"owner"`
+	OldSize int64  `json:"old_size"`
+	NewSize int64  `json:"new_size"`
+}
+
+// Delta is the change of size in bytes
+func (c OwnerChange) Delta() int64 {
+	return c.NewSize - c.OldSize
+}
+
+// Diff is the change of the inventory between two snapshots, changes are sorted by the size of their delta
+type Diff struct {
+	OldSize int64 `json:"old_size"`
+	NewSize int64 `json:"new_size"`
+	// Owners are the torrents (by name) and orphans whose size changed
+	Owners  []OwnerChange `json:"owners"`
+	Added   []FileChange  `json:"added"`
+	Removed []FileChange  `json:"removed"`
+	Resized []FileChange  `json:"resized"`
+}
+
+// Compare returns the changes from the snapshot before to the one after
+func Compare(before *Snapshot, after *Snapshot) Diff {
+	d := Diff{OldSize: before.Size(), NewSize: after.Size()}
+	owners := make(map[string]*OwnerChange)
+	owner := func(name string) *OwnerChange {
+		o, ok := owners[name]
+		if !ok {
+			o = &OwnerChange{Owner: name}
+			owners[name] = o
+		}
+		return o
+	}
+
+	oldEntries := make(map[string]Entry, len(before.Entries))
+	for _, e := range before.Entries {
+		oldEntries[e.Path] = e
+		owner(before.owner(e)).OldSize += e.Size
+	}
+
+	for _, e := range after.Entries {
+		name := after.owner(e)
+		owner(name).NewSize += e.Size
+
+		oe, existed := oldEntries[e.Path]
+		delete(oldEntries, e.Path)
+		switch {
+		case !existed:
+			d.Added = append(d.Added, FileChange{Path: e.Path, Owner: name, NewSize: e.Size})
+		case oe.Size != e.Size:
+			d.Resized = append(d.Resized, FileChange{Path: e.Path, Owner: name, OldSize: oe.Size, NewSize: e.Size})
+		}
+	}
+
+	for _, e := range oldEntries {
+		d.Removed = append(d.Removed, FileChange{Path: e.Path, Owner: before.owner(e), OldSize: e.Size})
+	}
+
+	for _, o := range owners {
+		if o.Delta() != 0 {
+			d.Owners = append(d.Owners, *o)
+		}
+	}
+
+	slices.SortFunc(d.Owners, func(a, b OwnerChange) int {
+		return cmp.Or(cmp.Compare(abs(b.Delta()), abs(a.Delta())), strings.Compare(a.Owner, b.Owner))
+	})
+	for _, changes := range [][]FileChange{d.Added, d.Removed, d.Resized} {
+		slices.SortFunc(changes, func(a, b FileChange) int {
+			return cmp.Or(cmp.Compare(abs(b.Delta()), abs(a.Delta())), strings.Compare(a.Path, b.Path))
+		})
+	}
+	return d
+}
+
+// owner returns the names of the torrents owning e, or Orphans
+func (s *Snapshot) owner(e Entry) string {
+	if len(e.Torrents) == 0 {
+		return Orphans
+	}
+
+	names := make([]string, 0, len(e.Torrents))
+	for _, hash := range e.Torrents {
+		name := s.Torrents[hash]
+		if name == "" {
+			name = hash
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,195 @@
+// Package inventory snapshots the files of the download path of a client with the torrents owning them, so the disk
+// usage of snapshots taken over time can be compared
+package inventory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+const (
+	// inventoryDir is kept next to the config file, with a folder of snapshots per client
+	inventoryDir = "inventory"
+	// snapshotExt is the extension of the gzip compressed JSON snapshots
+	snapshotExt = ".json.gz"
+	// snapshotTimeFormat names the snapshots by the time they were taken, so they sort chronologically
+	snapshotTimeFormat = "20060102T150405Z"
+)
+
+// Entry is a file of the download path
+type Entry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Torrents are the hashes of the torrents owning the file, none for orphans
+	Torrents []string `json:"torrents,omitempty"`
+}
+
+// Snapshot is the inventory of the download path of a client at a point in time
+type Snapshot struct {
+	Client       string    `json:"client"`
+	Taken        time.Time `json:"taken"`
+	DownloadPath string    `json:"download_path"`
+	// Torrents are the names of the torrents owning files, by hash
+	Torrents map[string]string `json:"torrents"`
+	Entries  []Entry           `json:"entries"`
+}
+
+// Dir returns the folder of the snapshots of client
+func Dir(client string) string {
+	return config.StatePath(filepath.Join(inventoryDir, client))
+}
+
+// Take snapshots the files of downloadPath, the files of torrents are mapped to where tqm sees them with mapping
+func Take(client string, downloadPath string, torrents map[string]config.Torrent, mapping map[string]string,
+	now time.Time) *Snapshot {
+	owners := make(map[string][]string)
+	for hash, t := range torrents {
+		for _, f := range t.Files {
+			p := paths.MapPath(f, mapping)
+			owners[p] = append(owners[p], hash)
+		}
+	}
+
+	s := &Snapshot{
+		Client:       client,
+		Taken:        now.UTC(),
+		DownloadPath: downloadPath,
+		Torrents:     make(map[string]string),
+	}
+
+	files, _ := paths.InFolder(downloadPath, true, false, nil)
+	for _, f := range files {
+		e := Entry{Path: f.RealPath, Size: f.Size, Torrents: owners[f.RealPath]}
+		slices.Sort(e.Torrents)
+		for _, hash := range e.Torrents {
+			s.Torrents[hash] = torrents[hash].Name
+		}
+		s.Entries = append(s.Entries, e)
+	}
+
+	slices.SortFunc(s.Entries, func(a, b Entry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return s
+}
+
+// Size returns the total size of the files of the snapshot
+func (s *Snapshot) Size() int64 {
+	var size int64
+	for _, e := range s.Entries {
+		size += e.Size
+	}
+	return size
+}
+
+// Save writes the snapshot to dir and returns its path
+func Save(dir string, s *Snapshot) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create folder: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("compress: %w", err)
+	}
+
+	p := filepath.Join(dir, s.Taken.UTC().Format(snapshotTimeFormat)+snapshotExt)
+	if err := paths.WriteFileAtomic(p, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("write: %w", err)
+	}
+	return p, nil
+}
+
+// Load reads the snapshot at path
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+	defer zr.Close()
+
+	var s Snapshot
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return &s, nil
+}
+
+// List returns the paths of the snapshots in dir, oldest first
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var snapshots []string
+	for _, e := range entries {
+		if _, ok := snapshotTime(e.Name()); ok && !e.IsDir() {
+			snapshots = append(snapshots, filepath.Join(dir, e.Name()))
+		}
+	}
+	slices.Sort(snapshots)
+	return snapshots, nil
+}
+
+// Latest returns when the newest snapshot of dir was taken, the zero time without snapshots
+func Latest(dir string) (time.Time, error) {
+	snapshots, err := List(dir)
+	if err != nil || len(snapshots) == 0 {
+		return time.Time{}, err
+	}
+
+	taken, _ := snapshotTime(filepath.Base(snapshots[len(snapshots)-1]))
+	return taken, nil
+}
+
+// Prune removes the oldest snapshots of dir, keeping the newest keep snapshots, and returns how many were removed
+func Prune(dir string, keep int) (int, error) {
+	snapshots, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for len(snapshots)-removed > keep {
+		if err := os.Remove(snapshots[removed]); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// snapshotTime parses the time a snapshot was taken from its file name
+func snapshotTime(name string) (time.Time, bool) {
+	stamp, ok := strings.CutSuffix(name, snapshotExt)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	taken, err := time.Parse(snapshotTimeFormat, stamp)
+	return taken, err == nil
+}
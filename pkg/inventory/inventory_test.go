@@ -0,0 +1,102 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestTake(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "movie"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "movie", "movie.mkv"), make([]byte, 100), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "orphan.mkv"), make([]byte, 10), 0644))
+
+	torrents := map[string]config.Torrent{
+		"b": {Hash: "b", Name: "Movie.Cross", Files: []string{"/downloads/movie/movie.mkv"}},
+		"a": {Hash: "a", Name: "Movie", Files: []string{"/downloads/movie/movie.mkv"}},
+		"c": {Hash: "c", Name: "Missing", Files: []string{"/downloads/missing.mkv"}},
+	}
+
+	now := time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC)
+	s := Take("qbt", root, torrents, map[string]string{"/downloads": root}, now)
+
+	assert.Equal(t, "qbt", s.Client)
+	assert.Equal(t, now, s.Taken)
+	assert.Equal(t, []Entry{
+		{Path: filepath.Join(root, "movie", "movie.mkv"), Size: 100, Torrents: []string{"a", "b"}},
+		{Path: filepath.Join(root, "orphan.mkv"), Size: 10},
+	}, s.Entries)
+	assert.Equal(t, map[string]string{"a": "Movie", "b": "Movie.Cross"}, s.Torrents)
+	assert.Equal(t, int64(110), s.Size())
+}
+
+func TestSaveLoadPrune(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "qbt")
+
+	latest, err := Latest(dir)
+	require.NoError(t, err)
+	assert.True(t, latest.IsZero())
+
+	start := time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		_, err := Save(dir, &Snapshot{Client: "qbt", Taken: start.Add(time.Duration(i) * time.Hour),
+			Entries: []Entry{{Path: "/a", Size: int64(i)}}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0600))
+
+	snapshots, err := List(dir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	assert.Equal(t, "20261016T040000Z.json.gz", filepath.Base(snapshots[0]))
+
+	s, err := Load(snapshots[2])
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), s.Size())
+
+	removed, err := Prune(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	latest, err = Latest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(2*time.Hour), latest)
+}
+
+func TestCompare(t *testing.T) {
+	before := &Snapshot{
+		Torrents: map[string]string{"a": "Movie", "b": "Show"},
+		Entries: []Entry{
+			{Path: "/movie.mkv", Size: 100, Torrents: []string{"a"}},
+			{Path: "/show.mkv", Size: 50, Torrents: []string{"b"}},
+			{Path: "/log.txt", Size: 5},
+		},
+	}
+	after := &Snapshot{
+		Torrents: map[string]string{"a": "Movie"},
+		Entries: []Entry{
+			{Path: "/movie.mkv", Size: 100, Torrents: []string{"a"}},
+			{Path: "/log.txt", Size: 500},
+			{Path: "/extracted.mkv", Size: 80},
+		},
+	}
+
+	d := Compare(before, after)
+
+	assert.Equal(t, int64(155), d.OldSize)
+	assert.Equal(t, int64(680), d.NewSize)
+	assert.Equal(t, []OwnerChange{
+		{Owner: Orphans, OldSize: 5, NewSize: 580},
+		{Owner: "Show", OldSize: 50},
+	}, d.Owners)
+	assert.Equal(t, []FileChange{{Path: "/extracted.mkv", Owner: Orphans, NewSize: 80}}, d.Added)
+	assert.Equal(t, []FileChange{{Path: "/show.mkv", Owner: "Show", OldSize: 50}}, d.Removed)
+	assert.Equal(t, []FileChange{{Path: "/log.txt", Owner: Orphans, OldSize: 5, NewSize: 500}}, d.Resized)
+}
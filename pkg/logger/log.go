@@ -52,6 +52,10 @@ func Init(logLevel int, logFilePath string) error {
 	}
 	logrus.AddHook(rotateFileHook)
 
+	// keep recent lines for diagnostic bundles
+	recent = newRecentHook(useLevel, fileLogFormatter)
+	logrus.AddHook(recent)
+
 	// set console formatter
 	logFormatter := &prefixed.TextFormatter{}
 	logFormatter.FullTimestamp = true
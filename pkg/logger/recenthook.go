@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recentLinesMax is the number of log lines kept in memory for diagnostics
+const recentLinesMax = 200
+
+// recentHook keeps the last formatted log lines in a ring buffer
+type recentHook struct {
+	level     logrus.Level
+	formatter logrus.Formatter
+
+	mu    sync.Mutex
+	lines []string
+	next  int
+}
+
+var recent *recentHook
+
+func newRecentHook(level logrus.Level, formatter logrus.Formatter) *recentHook {
+	return &recentHook{
+		level:     level,
+		formatter: formatter,
+		lines:     make([]string, 0, recentLinesMax),
+	}
+}
+
+func (hook *recentHook) Levels() []logrus.Level {
+	return logrus.AllLevels[:hook.level+1]
+}
+
+func (hook *recentHook) Fire(entry *logrus.Entry) error {
+	b, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	line := strings.TrimRight(string(b), "\n")
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if len(hook.lines) < recentLinesMax {
+		hook.lines = append(hook.lines, line)
+		return nil
+	}
+
+	hook.lines[hook.next] = line
+	hook.next = (hook.next + 1) % recentLinesMax
+	return nil
+}
+
+func (hook *recentHook) snapshot() []string {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	lines := make([]string, 0, len(hook.lines))
+	lines = append(lines, hook.lines[hook.next:]...)
+	return append(lines, hook.lines[:hook.next]...)
+}
+
+// RecentLines returns the most recent log lines (oldest first), nil before Init
+func RecentLines() []string {
+	if recent == nil {
+		return nil
+	}
+
+	return recent.snapshot()
+}
@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// deltaStateFile keeps the entries reported by the last run of every action and client
+const deltaStateFile = "notifications.state.json"
+
+func defaultDeltaStatePath() string {
+	return config.StatePath(deltaStateFile)
+}
+
+// deltaState maps "<title>|<client>" to the keys of the fields sent by the last run
+type deltaState map[string][]string
+
+func loadDeltaState(path string) (deltaState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return deltaState{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read delta state: %w", err)
+	}
+
+	state := deltaState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decode delta state: %w", err)
+	}
+	return state, nil
+}
+
+func (s deltaState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encode delta state: %w", err)
+	}
+
+	// write atomically, so an interrupted run never leaves a truncated state behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create delta state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write delta state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write delta state: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// applyDelta drops the fields already reported by the previous run of title for client and returns the remaining
+// ones together with the number of dropped fields. The state is only updated for real runs, dry runs just compare.
+func (d *discordSender) applyDelta(title string, client string, fields []Field, dryRun bool) ([]Field, int) {
+	state, err := loadDeltaState(d.statePath)
+	if err != nil {
+		d.log.WithError(err).Warn("Failed loading notification state, reporting all entries")
+		state = deltaState{}
+	}
+
+	key := title + "|" + client
+	previous := make(map[string]struct{}, len(state[key]))
+	for _, k := range state[key] {
+		previous[k] = struct{}{}
+	}
+
+	var (
+		changed []Field
+		keys    = make([]string, 0, len(fields))
+	)
+	for _, f := range fields {
+		keys = append(keys, deltaKey(f))
+		if _, ok := previous[deltaKey(f)]; !ok {
+			changed = append(changed, f)
+		}
+	}
+
+	if !dryRun {
+		state[key] = keys
+		if err := state.save(d.statePath); err != nil {
+			d.log.WithError(err).Warn("Failed saving notification state")
+		}
+	}
+
+	return changed, len(fields) - len(changed)
+}
+
+// deltaKey identifies the entry of f across runs, the torrent for torrent fields and the path for orphan fields
+func deltaKey(f Field) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Value
+}
+
+// deltaDescription appends the number of unchanged entries to description
+func deltaDescription(description string, unchanged int) string {
+	if unchanged == 0 {
+		return description
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s\n\n**%d** unchanged since the last run not shown", description, unchanged))
+}
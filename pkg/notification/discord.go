@@ -16,16 +16,20 @@ import (
 	"github.com/autobrr/autobrr/pkg/errors"
 	"github.com/autobrr/autobrr/pkg/sharedhttp"
 	"github.com/autobrr/tqm/pkg/config"
-	"github.com/dustin/go-humanize"
+	"github.com/autobrr/tqm/pkg/formatting"
 	"github.com/sirupsen/logrus"
+	"go.uber.org/ratelimit"
 )
 
 const (
 	maxEmbedsPerMessage = 10
 	maxCharactersPerMsg = 6000
 
-	// hardcoded limit of fields to avoid hammering the api
-	maxTotalFields = 250
+	// default limit of detailed fields to avoid hammering the api
+	defaultMaxFields = 250
+
+	// discord allows 30 messages per minute per webhook
+	defaultMessagesPerMinute = 30
 )
 
 type DiscordMessage struct {
@@ -215,6 +219,12 @@ type discordSender struct {
 
 	httpClient  *http.Client
 	rateLimiter *RateLimiter
+
+	// throttles sends proactively, nil when disabled
+	sendLimiter ratelimit.Limiter
+
+	// statePath is the file keeping the entries of the previous runs in delta mode
+	statePath string
 }
 
 func (d *discordSender) Name() string {
@@ -232,6 +242,9 @@ func NewDiscordSender(log *logrus.Entry, config config.NotificationsConfig) Send
 	}
 
 	sender.rateLimiter = NewRateLimiter(sender.log)
+	sender.statePath = defaultDeltaStatePath()
+
+	sender.sendLimiter = newSendLimiter(config.MessagesPerMinute)
 
 	// Start cleanup routine
 	go func() {
@@ -246,6 +259,31 @@ func NewDiscordSender(log *logrus.Entry, config config.NotificationsConfig) Send
 	return sender
 }
 
+// newSendLimiter returns the limiter throttling the sends to messagesPerMinute, 0 for the default and nil when negative
+func newSendLimiter(messagesPerMinute int) ratelimit.Limiter {
+	if messagesPerMinute == 0 {
+		messagesPerMinute = defaultMessagesPerMinute
+	}
+	if messagesPerMinute < 0 {
+		return nil
+	}
+	return ratelimit.New(messagesPerMinute, ratelimit.Per(time.Minute), ratelimit.WithoutSlack)
+}
+
+// limitFields keeps the first maxFields fields (defaultMaxFields when not positive) and mentions the number of
+// dropped fields in the description
+func limitFields(description string, fields []Field, maxFields int) (string, []Field) {
+	if maxFields <= 0 {
+		maxFields = defaultMaxFields
+	}
+	if len(fields) <= maxFields {
+		return description, fields
+	}
+
+	description = fmt.Sprintf("%s\n\n…and **%d** more not shown", description, len(fields)-maxFields)
+	return description, fields[:maxFields]
+}
+
 // Calculate the actual JSON size of an embed
 func (d *discordSender) calculateEmbedSize(embed DiscordEmbed) (int, error) {
 	jsonData, err := json.Marshal(embed)
@@ -266,6 +304,14 @@ func (d *discordSender) Send(title string, description string, client string, ru
 		currentChars int
 	)
 
+	// only report entries that were not reported by the previous run
+	if d.config.Delta {
+		var unchanged int
+		fields, unchanged = d.applyDelta(title, client, fields, dryRun)
+		totalFields = len(fields)
+		description = deltaDescription(description, unchanged)
+	}
+
 	// Add (Dry Run) to title if enabled
 	if dryRun {
 		title = title + " [Dry Run]"
@@ -277,11 +323,20 @@ func (d *discordSender) Send(title string, description string, client string, ru
 		return nil
 	}
 
-	rt := runTime.Truncate(time.Millisecond).String()
+	// merge cross-seeded copies of the same release into a single entry
+	fields = d.groupFields(fields)
+	totalFields = len(fields)
 
-	// only send a summary embed if no fields are present, there are more fields than allowed,
-	// or the config setting "detailed" is set to false
-	if totalFields == 0 || totalFields > maxTotalFields || !d.config.Detailed {
+	rt := formatting.Duration(runTime)
+
+	// only send detailed embeds for the first max_fields fields, the remainder is mentioned in the summary
+	if d.config.Detailed {
+		description, fields = limitFields(description, fields, d.config.MaxFields)
+		totalFields = len(fields)
+	}
+
+	// only send a summary embed if no fields are present or the config setting "detailed" is set to false
+	if totalFields == 0 || !d.config.Detailed {
 		allEmbeds = append(allEmbeds, DiscordEmbed{
 			Title:       title,
 			Description: description,
@@ -397,7 +452,10 @@ func (d *discordSender) sendRequest(jsonData []byte) error {
 	// Discord webhooks use a per-webhook bucket system
 	bucket := d.getBucketFromURL(d.config.Service.Discord.WebhookURL)
 
-	// Wait for rate limit clearance
+	// Throttle proactively and wait for rate limit clearance
+	if d.sendLimiter != nil {
+		d.sendLimiter.Take()
+	}
 	d.rateLimiter.Wait(bucket)
 
 	req, err := http.NewRequest(http.MethodPost, d.config.Service.Discord.WebhookURL, bytes.NewBuffer(jsonData))
@@ -466,12 +524,16 @@ func (d *discordSender) BuildField(action Action, opt BuildOptions) Field {
 		return d.buildRetagField(opt.Torrent, opt.NewTags, opt.NewUpLimit)
 	case ActionRelabel:
 		return d.buildRelabelField(opt.Torrent, opt.NewLabel)
-	case ActionClean:
+	case ActionMove:
+		return d.buildMoveField(opt.Torrent, opt.NewPath)
+	case ActionClean, ActionPruneFiles:
 		return d.buildGenericField(opt.Torrent, opt.RemovalReason)
-	case ActionPause:
+	case ActionPause, ActionResume, ActionRecheck, ActionReannounce:
 		return d.buildGenericField(opt.Torrent, "")
 	case ActionOrphan:
 		return d.buildOrphanField(opt.Orphan, opt.OrphanSize, opt.IsFile)
+	case ActionShareLimit:
+		return d.buildShareLimitField(opt.Torrent, opt.NewShareLimitRuleName, opt.NewRatioLimit, opt.NewSeedingTimeLimit)
 	}
 
 	return Field{}
@@ -527,7 +589,7 @@ func (d *discordSender) buildRetagField(torrent config.Torrent, newTags []string
 	jsonData, _ := json.Marshal(inlineFields)
 
 	return Field{
-		Name:  fmt.Sprintf("%s (%s)", torrent.Name, humanize.IBytes(uint64(torrent.TotalBytes))),
+		Name:  fmt.Sprintf("%s (%s)", torrent.Name, formatting.Bytes(uint64(torrent.TotalBytes))),
 		Value: string(jsonData),
 	}
 }
@@ -550,7 +612,94 @@ func (d *discordSender) buildRelabelField(torrent config.Torrent, newLabel strin
 	jsonData, _ := json.Marshal(inlineFields)
 
 	return Field{
-		Name:  fmt.Sprintf("%s (%s)", torrent.Name, humanize.IBytes(uint64(torrent.TotalBytes))),
+		Name:  fmt.Sprintf("%s (%s)", torrent.Name, formatting.Bytes(uint64(torrent.TotalBytes))),
+		Value: string(jsonData),
+	}
+}
+
+func (d *discordSender) buildMoveField(torrent config.Torrent, newPath string) Field {
+	var inlineFields []DiscordEmbedsField
+
+	inlineFields = append(inlineFields, DiscordEmbedsField{
+		Name:   "Old Path",
+		Value:  escapeDiscordMarkdown(torrent.Path),
+		Inline: true,
+	})
+	inlineFields = append(inlineFields, DiscordEmbedsField{
+		Name:   "New Path",
+		Value:  escapeDiscordMarkdown(newPath),
+		Inline: true,
+	})
+
+	// Serialize to JSON to store in the field value
+	jsonData, _ := json.Marshal(inlineFields)
+
+	return Field{
+		Name:  fmt.Sprintf("%s (%s)", torrent.Name, formatting.Bytes(uint64(torrent.TotalBytes))),
+		Value: string(jsonData),
+	}
+}
+
+func (d *discordSender) buildShareLimitField(torrent config.Torrent, ruleName string, newRatioLimit float64, newSeedingTimeLimit int64) Field {
+	var inlineFields []DiscordEmbedsField
+
+	ratioStr := func(limit float64) string {
+		switch limit {
+		case -2:
+			return "Global"
+		case -1:
+			return "Unlimited"
+		}
+		return fmt.Sprintf("%.2f", limit)
+	}
+
+	seedingTimeStr := func(limit int64) string {
+		switch limit {
+		case -2:
+			return "Global"
+		case -1:
+			return "Unlimited"
+		}
+		return (time.Duration(limit) * time.Minute).String()
+	}
+
+	inlineFields = append(inlineFields, DiscordEmbedsField{
+		Name:   "Rule",
+		Value:  escapeDiscordMarkdown(ruleName),
+		Inline: false,
+	})
+
+	if torrent.RatioLimit != newRatioLimit {
+		inlineFields = append(inlineFields, DiscordEmbedsField{
+			Name:   "Old Ratio Limit",
+			Value:  ratioStr(torrent.RatioLimit),
+			Inline: true,
+		})
+		inlineFields = append(inlineFields, DiscordEmbedsField{
+			Name:   "New Ratio Limit",
+			Value:  ratioStr(newRatioLimit),
+			Inline: true,
+		})
+	}
+
+	if torrent.SeedingTimeLimit != newSeedingTimeLimit {
+		inlineFields = append(inlineFields, DiscordEmbedsField{
+			Name:   "Old Seeding Time Limit",
+			Value:  seedingTimeStr(torrent.SeedingTimeLimit),
+			Inline: true,
+		})
+		inlineFields = append(inlineFields, DiscordEmbedsField{
+			Name:   "New Seeding Time Limit",
+			Value:  seedingTimeStr(newSeedingTimeLimit),
+			Inline: true,
+		})
+	}
+
+	// Serialize to JSON to store in the field value
+	jsonData, _ := json.Marshal(inlineFields)
+
+	return Field{
+		Name:  fmt.Sprintf("%s (%s)", torrent.Name, formatting.Bytes(uint64(torrent.TotalBytes))),
 		Value: string(jsonData),
 	}
 }
@@ -607,8 +756,9 @@ func (d *discordSender) buildGenericField(torrent config.Torrent, reason string)
 	jsonData, _ := json.Marshal(inlineFields)
 
 	return Field{
-		Name:  fmt.Sprintf("%s (%s)", torrent.Name, humanize.IBytes(uint64(torrent.TotalBytes))),
-		Value: string(jsonData),
+		Name:     fmt.Sprintf("%s (%s)", torrent.Name, formatting.Bytes(uint64(torrent.TotalBytes))),
+		Value:    string(jsonData),
+		GroupKey: fmt.Sprintf("%s|%d", NormalizeTorrentName(torrent.Name), torrent.TotalBytes),
 	}
 }
 
@@ -629,7 +779,7 @@ func (d *discordSender) buildOrphanField(orphan string, orphanSize int64, isFile
 	if isFile {
 		inlineFields = append(inlineFields, DiscordEmbedsField{
 			Name:   "Size",
-			Value:  humanize.IBytes(uint64(orphanSize)),
+			Value:  formatting.Bytes(uint64(orphanSize)),
 			Inline: true,
 		})
 	}
@@ -649,6 +799,98 @@ func (d *discordSender) buildOrphanField(orphan string, orphanSize int64, isFile
 	}
 }
 
+// groupFields merges fields sharing a GroupKey into the first field of the group, replacing its tracker
+// details with one line per tracker. Fields without a GroupKey are left as is.
+func (d *discordSender) groupFields(fields []Field) []Field {
+	var (
+		grouped []Field
+		groups  = make(map[string][]int)
+		order   []string
+	)
+
+	for i, f := range fields {
+		if f.GroupKey == "" {
+			continue
+		}
+
+		if _, ok := groups[f.GroupKey]; !ok {
+			order = append(order, f.GroupKey)
+		}
+		groups[f.GroupKey] = append(groups[f.GroupKey], i)
+	}
+
+	merged := make(map[int]Field)
+	skip := make(map[int]struct{})
+	for _, key := range order {
+		idx := groups[key]
+		if len(idx) < 2 {
+			continue
+		}
+
+		base := d.parseFieldValueToInlineFields(fields[idx[0]].Value)
+		inlineFields := make([]DiscordEmbedsField, 0, len(base)+1)
+		for _, f := range base {
+			if f.Name == "Tracker" || f.Name == "Tracker Status" {
+				continue
+			}
+			inlineFields = append(inlineFields, f)
+		}
+
+		var trackers []string
+		for _, i := range idx {
+			var tracker, status string
+			for _, f := range d.parseFieldValueToInlineFields(fields[i].Value) {
+				switch f.Name {
+				case "Tracker":
+					tracker = f.Value
+				case "Tracker Status":
+					status = f.Value
+				}
+			}
+
+			if status != "" {
+				trackers = append(trackers, fmt.Sprintf("%s: %s", tracker, status))
+			} else {
+				trackers = append(trackers, tracker)
+			}
+
+			skip[i] = struct{}{}
+		}
+
+		inlineFields = append(inlineFields, DiscordEmbedsField{
+			Name:   fmt.Sprintf("Trackers (%d)", len(idx)),
+			Value:  strings.Join(trackers, "\n"),
+			Inline: false,
+		})
+
+		jsonData, _ := json.Marshal(inlineFields)
+		merged[idx[0]] = Field{
+			Name:     fields[idx[0]].Name,
+			Value:    string(jsonData),
+			GroupKey: key,
+		}
+	}
+
+	if len(merged) == 0 {
+		return fields
+	}
+
+	for i, f := range fields {
+		if m, ok := merged[i]; ok {
+			grouped = append(grouped, m)
+			continue
+		}
+
+		if _, ok := skip[i]; ok {
+			continue
+		}
+
+		grouped = append(grouped, f)
+	}
+
+	return grouped
+}
+
 func (d *discordSender) buildFooter(progress int, totalFields int, client string, runTime string) string {
 	if totalFields == 0 {
 		return fmt.Sprintf("Client: %s | Started: %s ago", client, runTime)
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+func TestNormalizeTorrentName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "dots", input: "Some.Release.2024.1080p-GRP", expected: "some release 2024 1080p grp"},
+		{name: "spaces", input: "Some Release 2024 1080p-GRP", expected: "some release 2024 1080p grp"},
+		{name: "extension", input: "Some.Release.2024.1080p-GRP.mkv", expected: "some release 2024 1080p grp"},
+		{name: "underscores", input: " some_release_2024_1080p_grp ", expected: "some release 2024 1080p grp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeTorrentName(tt.input))
+		})
+	}
+}
+
+func TestDiscordSender_GroupFields(t *testing.T) {
+	d := &discordSender{log: logrus.NewEntry(logrus.New())}
+
+	torrent := func(name string, trackerName string, trackerStatus string) config.Torrent {
+		return config.Torrent{
+			Name:          name,
+			TotalBytes:    1024,
+			Ratio:         1.5,
+			TrackerName:   trackerName,
+			TrackerStatus: trackerStatus,
+		}
+	}
+
+	fields := []Field{
+		d.buildGenericField(torrent("Some.Release-GRP", "tracker1.com", "unregistered"), "unregistered"),
+		d.BuildField(ActionOrphan, BuildOptions{Orphan: "/downloads/orphan.mkv", OrphanSize: 10, IsFile: true}),
+		d.buildGenericField(torrent("Some Release-GRP", "tracker2.com", ""), "unregistered"),
+		d.buildGenericField(torrent("Other.Release-GRP", "tracker1.com", ""), "ratio"),
+	}
+
+	grouped := d.groupFields(fields)
+	require.Len(t, grouped, 3)
+
+	assert.Equal(t, fields[0].Name, grouped[0].Name)
+	assert.Equal(t, fields[1], grouped[1])
+	assert.Equal(t, fields[3], grouped[2])
+
+	inlineFields := d.parseFieldValueToInlineFields(grouped[0].Value)
+	var trackers *DiscordEmbedsField
+	for _, f := range inlineFields {
+		assert.NotEqual(t, "Tracker", f.Name)
+		assert.NotEqual(t, "Tracker Status", f.Name)
+		if f.Name == "Trackers (2)" {
+			trackers = &f
+		}
+	}
+
+	require.NotNil(t, trackers)
+	assert.Equal(t, "tracker1.com: unregistered\ntracker2.com", trackers.Value)
+}
+
+func TestDiscordSender_ApplyDelta(t *testing.T) {
+	d := &discordSender{
+		log:       logrus.NewEntry(logrus.New()),
+		statePath: filepath.Join(t.TempDir(), deltaStateFile),
+	}
+
+	orphan := func(path string) Field {
+		return d.BuildField(ActionOrphan, BuildOptions{Orphan: path, OrphanSize: 10, IsFile: true})
+	}
+
+	// everything is new on the first run
+	fields, unchanged := d.applyDelta("Orphan", "qbt", []Field{orphan("/downloads/a.mkv"), orphan("/downloads/b.mkv")}, false)
+	assert.Len(t, fields, 2)
+	assert.Zero(t, unchanged)
+
+	// dry runs compare against, but do not update the state
+	fields, unchanged = d.applyDelta("Orphan", "qbt", []Field{orphan("/downloads/a.mkv"), orphan("/downloads/c.mkv")}, true)
+	assert.Equal(t, []Field{orphan("/downloads/c.mkv")}, fields)
+	assert.Equal(t, 1, unchanged)
+
+	fields, unchanged = d.applyDelta("Orphan", "qbt", []Field{orphan("/downloads/b.mkv")}, false)
+	assert.Empty(t, fields)
+	assert.Equal(t, 1, unchanged)
+
+	// a.mkv was not reported by the previous run, other clients are tracked separately
+	fields, _ = d.applyDelta("Orphan", "qbt", []Field{orphan("/downloads/a.mkv")}, false)
+	assert.Len(t, fields, 1)
+	fields, _ = d.applyDelta("Orphan", "deluge", []Field{orphan("/downloads/a.mkv")}, false)
+	assert.Len(t, fields, 1)
+}
+
+func TestLimitFields(t *testing.T) {
+	fields := make([]Field, 5)
+	for i := range fields {
+		fields[i] = Field{Name: fmt.Sprintf("Torrent.%d", i)}
+	}
+
+	tests := []struct {
+		name                string
+		maxFields           int
+		expectedDescription string
+		expectedFields      int
+	}{
+		{name: "below_max", maxFields: 10, expectedDescription: "Removed 5 torrents", expectedFields: 5},
+		{name: "at_max", maxFields: 5, expectedDescription: "Removed 5 torrents", expectedFields: 5},
+		{name: "above_max", maxFields: 2, expectedDescription: "Removed 5 torrents\n\n…and **3** more not shown",
+			expectedFields: 2},
+		{name: "default", maxFields: 0, expectedDescription: "Removed 5 torrents", expectedFields: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, limited := limitFields("Removed 5 torrents", fields, tt.maxFields)
+			assert.Equal(t, tt.expectedDescription, description)
+			assert.Equal(t, fields[:tt.expectedFields], limited)
+		})
+	}
+
+	// the default keeps defaultMaxFields fields
+	many := make([]Field, defaultMaxFields+1)
+	description, limited := limitFields("", many, 0)
+	assert.Len(t, limited, defaultMaxFields)
+	assert.Contains(t, description, "…and **1** more not shown")
+}
+
+func TestNewSendLimiter(t *testing.T) {
+	assert.Nil(t, newSendLimiter(-1))
+	assert.NotNil(t, newSendLimiter(0))
+
+	// sends beyond the first are spaced by a minute divided by messagesPerMinute
+	limiter := newSendLimiter(1200)
+	start := time.Now()
+	for range 3 {
+		limiter.Take()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestDiscordSender_SendMaxFields(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		messages []DiscordMessage
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg DiscordMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+
+		mu.Lock()
+		messages = append(messages, msg)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &discordSender{
+		log: logrus.NewEntry(logrus.New()),
+		config: config.NotificationsConfig{
+			Detailed:          true,
+			MaxFields:         3,
+			MessagesPerMinute: -1,
+			Service:           config.NotificationService{Discord: config.DiscordConfig{WebhookURL: srv.URL}},
+		},
+		httpClient: srv.Client(),
+	}
+	d.rateLimiter = NewRateLimiter(d.log)
+
+	fields := make([]Field, 5)
+	for i := range fields {
+		fields[i] = d.BuildField(ActionOrphan, BuildOptions{Orphan: fmt.Sprintf("/downloads/orphan%d.mkv", i),
+			OrphanSize: 10, IsFile: true})
+	}
+
+	require.NoError(t, d.Send("Orphan", "Removed 5 orphans", "qbt", time.Second, fields, false))
+	require.Len(t, messages, 1)
+
+	// the detailed embeds of the first 3 fields and the summary mentioning the 2 others
+	embeds := messages[0].Embeds
+	require.Len(t, embeds, 4)
+	assert.Equal(t, "Orphan - Summary", embeds[3].Title)
+	assert.Equal(t, "Removed 5 orphans\n\n…and **2** more not shown", embeds[3].Description)
+}
@@ -1,6 +1,8 @@
 package notification
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/autobrr/tqm/pkg/config"
@@ -14,8 +16,44 @@ const (
 	ActionClean
 	ActionPause
 	ActionOrphan
+	ActionShareLimit
+	ActionResume
+	ActionRecheck
+	ActionReannounce
+	ActionMove
+	ActionPruneFiles
 )
 
+// String returns the name of the action used in machine-readable output
+func (a Action) String() string {
+	switch a {
+	case ActionRetag:
+		return "retag"
+	case ActionRelabel:
+		return "relabel"
+	case ActionClean:
+		return "remove"
+	case ActionPause:
+		return "pause"
+	case ActionOrphan:
+		return "orphan"
+	case ActionShareLimit:
+		return "share_limit"
+	case ActionResume:
+		return "resume"
+	case ActionRecheck:
+		return "recheck"
+	case ActionReannounce:
+		return "reannounce"
+	case ActionMove:
+		return "move"
+	case ActionPruneFiles:
+		return "prune_files"
+	default:
+		return "unknown"
+	}
+}
+
 type Sender interface {
 	CanSend() bool
 	Send(title string, description string, client string, runTime time.Duration, fields []Field, dryRun bool) error
@@ -26,6 +64,9 @@ type Sender interface {
 type Field struct {
 	Name  string
 	Value string
+
+	// GroupKey identifies copies of the same release (e.g. cross-seeds), fields sharing a key are sent as a single entry
+	GroupKey string
 }
 
 type BuildOptions struct {
@@ -38,7 +79,26 @@ type BuildOptions struct {
 
 	NewLabel string
 
+	NewPath string
+
+	NewRatioLimit         float64
+	NewSeedingTimeLimit   int64
+	NewShareLimitRuleName string
+
 	Orphan     string
 	OrphanSize int64
 	IsFile     bool
 }
+
+var (
+	torrentNameExtension  = regexp.MustCompile(`(?i)\.(mkv|mp4|avi|m2ts|ts|iso|flac|mp3|zip|rar)$`)
+	torrentNameSeparators = regexp.MustCompile(`[\s._-]+`)
+)
+
+// NormalizeTorrentName lowercases name and unifies separators and file extensions, so differently named
+// cross-seeds of the same release (e.g. "Some.Release-GRP.mkv" and "Some Release-GRP") compare equal
+func NormalizeTorrentName(name string) string {
+	name = torrentNameExtension.ReplaceAllString(strings.TrimSpace(name), "")
+	name = torrentNameSeparators.ReplaceAllString(name, " ")
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !freebsd
+
+package paths
+
+import (
+	"errors"
+)
+
+// Inodes returns the number of free and total inodes of the filesystem containing path
+func Inodes(string) (uint64, uint64, error) {
+	return 0, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd
+
+package paths
+
+import (
+	"syscall"
+)
+
+// Inodes returns the number of free and total inodes of the filesystem containing path
+func Inodes(path string) (free uint64, total uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+
+	return uint64(st.Ffree), uint64(st.Files), nil
+}
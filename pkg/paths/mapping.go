@@ -0,0 +1,110 @@
+package paths
+
+import (
+	"runtime"
+	"strings"
+)
+
+// caseInsensitive reports whether mapping rules ignore case, as the default file systems of Windows and macOS do
+var caseInsensitive = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// MatchPathMapping returns the rule of mapping (client path prefix to local path prefix) that applies to path.
+// Paths are compared without trailing slashes and with forward slashes for Windows paths (ignoring case on Windows and
+// macOS), rules only match whole path components and the longest matching rule wins.
+func MatchPathMapping(path string, mapping map[string]string) (string, string, bool) {
+	p := normalizeMappingPath(path)
+
+	var from string
+	best := -1
+	for prefix := range mapping {
+		np := normalizeMappingPath(prefix)
+		if np == "" || !hasPathPrefix(p, np) {
+			continue
+		}
+
+		// ties between rules that only differ in style are resolved by name, map iteration order is random
+		if len(np) > best || (len(np) == best && prefix < from) {
+			from, best = prefix, len(np)
+		}
+	}
+
+	if best < 0 {
+		return "", "", false
+	}
+	return from, mapping[from], true
+}
+
+// MapPath translates path as reported by the client using mapping, paths no rule applies to are returned unchanged.
+// The remainder of the path uses the separator style of the local path prefix.
+func MapPath(path string, mapping map[string]string) string {
+	from, to, ok := MatchPathMapping(path, mapping)
+	if !ok {
+		return path
+	}
+
+	rest := strings.Trim(normalizeMappingPath(path)[len(normalizeMappingPath(from)):], "/")
+	target := strings.TrimRight(to, `/\`)
+	if target == "" {
+		// mapped to the root
+		target = to[:min(len(to), 1)]
+	}
+	if rest == "" {
+		return target
+	}
+
+	sep := "/"
+	if strings.Contains(target, `\`) && !strings.Contains(target, "/") {
+		sep = `\`
+		rest = strings.ReplaceAll(rest, "/", sep)
+	}
+	if strings.HasSuffix(target, sep) {
+		return target + rest
+	}
+	return target + sep + rest
+}
+
+// InSubtree reports whether path is one of roots or inside one of them, matching whole path components only
+func InSubtree(path string, roots []string) bool {
+	path = normalizeMappingPath(path)
+	for _, root := range roots {
+		if hasPathPrefix(path, normalizeMappingPath(root)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMappingPath converts the backslashes of Windows paths to forward slashes and strips trailing slashes, keeping
+// the root. Backslashes of other paths are part of their file names.
+func normalizeMappingPath(path string) string {
+	if isWindowsPath(path) {
+		path = strings.ReplaceAll(path, `\`, "/")
+	}
+	if trimmed := strings.TrimRight(path, "/"); trimmed != "" || path == "" {
+		return trimmed
+	}
+	return "/"
+}
+
+// isWindowsPath reports whether path starts with a drive letter (D:\) or is a UNC path (\\server\share)
+func isWindowsPath(path string) bool {
+	if strings.HasPrefix(path, `\\`) {
+		return true
+	}
+
+	return len(path) >= 2 && path[1] == ':' && ('a' <= path[0] && path[0] <= 'z' || 'A' <= path[0] && path[0] <= 'Z')
+}
+
+// hasPathPrefix reports whether prefix is path or one of its parent folders, both normalized
+func hasPathPrefix(path string, prefix string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+
+	head := path[:len(prefix)]
+	if caseInsensitive && !strings.EqualFold(head, prefix) || !caseInsensitive && head != prefix {
+		return false
+	}
+
+	return len(path) == len(prefix) || prefix == "/" || path[len(prefix)] == '/'
+}
@@ -0,0 +1,126 @@
+package paths
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapPath(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		mapping         map[string]string
+		caseInsensitive bool
+		expected        string
+	}{
+		{
+			name:     "prefix",
+			path:     "/downloads/tv/show.mkv",
+			mapping:  map[string]string{"/downloads": "/mnt/downloads"},
+			expected: "/mnt/downloads/tv/show.mkv",
+		},
+		{
+			name:     "trailing_slashes",
+			path:     "/downloads/tv/show.mkv",
+			mapping:  map[string]string{"/downloads/": "/mnt/downloads/"},
+			expected: "/mnt/downloads/tv/show.mkv",
+		},
+		{
+			name:     "mixed_trailing_slashes",
+			path:     "/downloads/tv/show.mkv",
+			mapping:  map[string]string{"/downloads": "/mnt/downloads/"},
+			expected: "/mnt/downloads/tv/show.mkv",
+		},
+		{
+			name:     "whole_components_only",
+			path:     "/downloads2/show.mkv",
+			mapping:  map[string]string{"/downloads": "/mnt/downloads"},
+			expected: "/downloads2/show.mkv",
+		},
+		{
+			name:     "exact",
+			path:     "/downloads/",
+			mapping:  map[string]string{"/downloads": "/mnt/downloads"},
+			expected: "/mnt/downloads",
+		},
+		{
+			name:     "longest_rule_wins",
+			path:     "/downloads/tv/show.mkv",
+			mapping:  map[string]string{"/downloads": "/mnt/downloads", "/downloads/tv": "/mnt/tv"},
+			expected: "/mnt/tv/show.mkv",
+		},
+		{
+			name:     "windows_client_to_linux",
+			path:     `D:\Downloads\tv\show.mkv`,
+			mapping:  map[string]string{`D:\Downloads\`: "/mnt/d/downloads"},
+			expected: "/mnt/d/downloads/tv/show.mkv",
+		},
+		{
+			name:     "linux_client_to_windows",
+			path:     "/downloads/tv/show.mkv",
+			mapping:  map[string]string{"/downloads": `\\nas\downloads`},
+			expected: `\\nas\downloads\tv\show.mkv`,
+		},
+		{
+			name:     "backslash_in_linux_file_name",
+			path:     `/downloads/tv/show\part1.mkv`,
+			mapping:  map[string]string{"/downloads": "/mnt/downloads"},
+			expected: `/mnt/downloads/tv/show\part1.mkv`,
+		},
+		{
+			name:     "backslash_in_linux_folder_name",
+			path:     `/downloads\tv/show.mkv`,
+			mapping:  map[string]string{"/downloads/tv": "/mnt/tv"},
+			expected: `/downloads\tv/show.mkv`,
+		},
+		{
+			name:     "windows_unc_client_to_linux",
+			path:     `\\nas\downloads\tv\show.mkv`,
+			mapping:  map[string]string{`\\nas\downloads`: "/mnt/nas"},
+			expected: "/mnt/nas/tv/show.mkv",
+		},
+		{
+			name:     "root",
+			path:     "/tv/show.mkv",
+			mapping:  map[string]string{"/": "/mnt/data/"},
+			expected: "/mnt/data/tv/show.mkv",
+		},
+		{
+			name:     "case_sensitive",
+			path:     "/Downloads/tv/show.mkv",
+			mapping:  map[string]string{"/downloads": "/mnt/downloads"},
+			expected: "/Downloads/tv/show.mkv",
+		},
+		{
+			name:            "case_insensitive",
+			path:            `d:\downloads\tv\show.mkv`,
+			mapping:         map[string]string{`D:\Downloads`: `E:\Downloads`},
+			caseInsensitive: true,
+			expected:        `E:\Downloads\tv\show.mkv`,
+		},
+	}
+
+	prev := caseInsensitive
+	t.Cleanup(func() { caseInsensitive = prev })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caseInsensitive = tt.caseInsensitive
+			assert.Equal(t, tt.expected, MapPath(tt.path, tt.mapping))
+		})
+	}
+}
+
+func TestInSubtree(t *testing.T) {
+	roots := []string{"/downloads/movies/Movie.2024", "/downloads/tv/Show.S01E01.mkv"}
+
+	assert.True(t, InSubtree("/downloads/movies/Movie.2024", roots))
+	assert.True(t, InSubtree("/downloads/movies/Movie.2024/extracted/movie.mkv", roots))
+	assert.True(t, InSubtree("/downloads/tv/Show.S01E01.mkv", roots))
+	assert.False(t, InSubtree("/downloads/movies/Movie.2024.Extras/file.mkv", roots))
+	assert.False(t, InSubtree("/downloads/movies", roots))
+	assert.False(t, InSubtree("/downloads/movies/Movie.2024", nil))
+	assert.False(t, InSubtree(`/downloads/movies\Movie.2024`, roots))
+	assert.True(t, InSubtree(`D:\Downloads\movies\Movie.2024`, []string{"D:/Downloads/movies"}))
+}
@@ -111,13 +111,43 @@ func InFolder(folder string, includeFiles bool, includeFolders bool, acceptFn ca
 	return paths, size
 }
 
-// IsIgnored checks if a path is in the provided ignore list
+// IsIgnored checks if a path is in the provided ignore list. Absolute entries are path prefixes, other entries are
+// glob patterns matched against the name of the file or folder.
 func IsIgnored(path string, ignoreList []string) bool {
 	return slices.ContainsFunc(ignoreList, func(s string) bool {
-		return strings.HasPrefix(path, s)
+		if filepath.IsAbs(s) {
+			return strings.HasPrefix(path, s)
+		}
+
+		match, _ := filepath.Match(s, filepath.Base(path))
+		return match
 	})
 }
 
+// InExcludedDir checks if path, or one of the folders between root and path, has a name matching one of the glob
+// patterns of excludeDirs
+func InExcludedDir(path string, root string, excludeDirs []string) bool {
+	if len(excludeDirs) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if slices.ContainsFunc(excludeDirs, func(pattern string) bool {
+			match, _ := filepath.Match(pattern, name)
+			return match
+		}) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsDirEmpty checks if the provided path is an empty dir
 func IsDirEmpty(path string) (bool, error) {
 	f, err := os.Open(path)
@@ -0,0 +1,33 @@
+package paths
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInExcludedDir(t *testing.T) {
+	excludeDirs := []string{"#recycle", ".Trash-*"}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "file in excluded dir", path: "/downloads/#recycle/movie.mkv", want: true},
+		{name: "nested in excluded dir", path: "/downloads/tv/.Trash-1000/files/episode.mkv", want: true},
+		{name: "excluded dir itself", path: "/downloads/#recycle", want: true},
+		{name: "regular file", path: "/downloads/tv/episode.mkv"},
+		{name: "similar name", path: "/downloads/#recycled/movie.mkv"},
+		{name: "root itself", path: "/downloads"},
+		{name: "outside root", path: "/#recycle/movie.mkv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, InExcludedDir(tt.path, "/downloads", excludeDirs))
+		})
+	}
+
+	assert.False(t, InExcludedDir("/downloads/#recycle/movie.mkv", "/downloads", nil))
+}
@@ -0,0 +1,32 @@
+package paths
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// IsTransientError reports whether err is a filesystem error that commonly clears up on its own on network mounts
+// (e.g. NFS/CIFS), meaning the operation is worth retrying.
+func IsTransientError(err error) bool {
+	return errors.Is(err, syscall.ESTALE) ||
+		errors.Is(err, syscall.EIO) ||
+		errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, syscall.EBUSY) ||
+		errors.Is(err, syscall.ETIMEDOUT)
+}
+
+// RetryTransient runs fn and retries it up to retries times while it fails with a transient error.
+// The delay between attempts doubles after each retry.
+func RetryTransient(retries int, delay time.Duration, fn func() error) error {
+	err := fn()
+	for attempt := 0; attempt < retries && err != nil && IsTransientError(err); attempt++ {
+		log.WithError(err).Debugf("Transient filesystem error, retrying in %v (%d/%d)", delay, attempt+1, retries)
+		time.Sleep(delay)
+		delay *= 2
+
+		err = fn()
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+package paths
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransient(t *testing.T) {
+	tests := []struct {
+		name          string
+		errs          []error
+		retries       int
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name:          "success_first_attempt",
+			errs:          []error{nil},
+			retries:       3,
+			expectedCalls: 1,
+		},
+		{
+			name:          "transient_then_success",
+			errs:          []error{&fs.PathError{Op: "stat", Path: "/mnt/nfs/file", Err: syscall.ESTALE}, nil},
+			retries:       3,
+			expectedCalls: 2,
+		},
+		{
+			name: "transient_exhausted",
+			errs: []error{
+				syscall.EIO,
+				syscall.EIO,
+				syscall.EIO,
+			},
+			retries:       2,
+			expectedCalls: 3,
+			expectedErr:   syscall.EIO,
+		},
+		{
+			name:          "permanent_not_retried",
+			errs:          []error{fs.ErrNotExist},
+			retries:       3,
+			expectedCalls: 1,
+			expectedErr:   fs.ErrNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := RetryTransient(tt.retries, 0, func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
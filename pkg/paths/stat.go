@@ -0,0 +1,75 @@
+package paths
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const DefaultStatConcurrency = 10
+
+var statConcurrency atomic.Int32
+
+func init() {
+	statConcurrency.Store(DefaultStatConcurrency)
+}
+
+// SetStatConcurrency sets the maximum number of concurrent filesystem operations used by the
+// missing files check and the orphan worker pool. Values below 1 are ignored.
+func SetStatConcurrency(n int) {
+	if n < 1 {
+		return
+	}
+
+	statConcurrency.Store(int32(n))
+}
+
+// StatConcurrency returns the maximum number of concurrent filesystem operations
+func StatConcurrency() int {
+	return int(statConcurrency.Load())
+}
+
+// AnyMissing stats paths in parallel (bounded by StatConcurrency) and reports whether any of them do not exist.
+// It stops checking as soon as a missing path is found. Other errors are passed to onError and otherwise ignored.
+func AnyMissing(paths []string, onError func(path string, err error)) bool {
+	var (
+		wg      sync.WaitGroup
+		missing atomic.Bool
+		work    = make(chan string)
+	)
+
+	workers := min(StatConcurrency(), len(paths))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for p := range work {
+				if missing.Load() {
+					continue
+				}
+
+				if _, err := os.Stat(p); err != nil {
+					if os.IsNotExist(err) {
+						missing.Store(true)
+					} else if onError != nil {
+						onError(p, err)
+					}
+				}
+			}
+		}()
+	}
+
+	for _, p := range paths {
+		if missing.Load() {
+			break
+		}
+
+		work <- p
+	}
+
+	close(work)
+	wg.Wait()
+
+	return missing.Load()
+}
@@ -0,0 +1,37 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnyMissing(t *testing.T) {
+	dir := t.TempDir()
+
+	var existing []string
+	for _, name := range []string{"a.mkv", "b.nfo", "c.srt"} {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte("data"), 0644))
+		existing = append(existing, p)
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected bool
+	}{
+		{name: "none", paths: nil, expected: false},
+		{name: "all_exist", paths: existing, expected: false},
+		{name: "one_missing", paths: append(append([]string{}, existing...), filepath.Join(dir, "missing.mkv")), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AnyMissing(tt.paths, nil))
+		})
+	}
+}
@@ -0,0 +1,892 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: tqm/v1/tqm.proto
+
+package tqmv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RunStatus int32
+
+const (
+	RunStatus_RUN_STATUS_UNSPECIFIED RunStatus = 0
+	RunStatus_RUN_STATUS_QUEUED      RunStatus = 1
+	RunStatus_RUN_STATUS_RUNNING     RunStatus = 2
+	RunStatus_RUN_STATUS_SUCCEEDED   RunStatus = 3
+	RunStatus_RUN_STATUS_FAILED      RunStatus = 4
+)
+
+// Enum value maps for RunStatus.
+var (
+	RunStatus_name = map[int32]string{
+		0: "RUN_STATUS_UNSPECIFIED",
+		1: "RUN_STATUS_QUEUED",
+		2: "RUN_STATUS_RUNNING",
+		3: "RUN_STATUS_SUCCEEDED",
+		4: "RUN_STATUS_FAILED",
+	}
+	RunStatus_value = map[string]int32{
+		"RUN_STATUS_UNSPECIFIED": 0,
+		"RUN_STATUS_QUEUED":      1,
+		"RUN_STATUS_RUNNING":     2,
+		"RUN_STATUS_SUCCEEDED":   3,
+		"RUN_STATUS_FAILED":      4,
+	}
+)
+
+func (x RunStatus) Enum() *RunStatus {
+	p := new(RunStatus)
+	*p = x
+	return p
+}
+
+func (x RunStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RunStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_tqm_v1_tqm_proto_enumTypes[0].Descriptor()
+}
+
+func (RunStatus) Type() protoreflect.EnumType {
+	return &file_tqm_v1_tqm_proto_enumTypes[0]
+}
+
+func (x RunStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RunStatus.Descriptor instead.
+func (RunStatus) EnumDescriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{0}
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{0}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HealthResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type TriggerRunRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Command is one of clean, relabel, retag, pause, resume, recheck, reannounce, move or orphan.
+	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Client  string `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	DryRun  bool   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Filter is used instead of the filter of the client when set.
+	Filter        string `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerRunRequest) Reset() {
+	*x = TriggerRunRequest{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerRunRequest) ProtoMessage() {}
+
+func (x *TriggerRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerRunRequest.ProtoReflect.Descriptor instead.
+func (*TriggerRunRequest) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TriggerRunRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *TriggerRunRequest) GetClient() string {
+	if x != nil {
+		return x.Client
+	}
+	return ""
+}
+
+func (x *TriggerRunRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *TriggerRunRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+type Run struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Client        string                 `protobuf:"bytes,3,opt,name=client,proto3" json:"client,omitempty"`
+	Filter        string                 `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	DryRun        bool                   `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Status        RunStatus              `protobuf:"varint,6,opt,name=status,proto3,enum=tqm.v1.RunStatus" json:"status,omitempty"`
+	QueuedAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=queued_at,json=queuedAt,proto3" json:"queued_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	ExitCode      *int32                 `protobuf:"varint,10,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	Error         string                 `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"`
+	Output        string                 `protobuf:"bytes,12,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Run) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Run) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *Run) GetClient() string {
+	if x != nil {
+		return x.Client
+	}
+	return ""
+}
+
+func (x *Run) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *Run) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *Run) GetStatus() RunStatus {
+	if x != nil {
+		return x.Status
+	}
+	return RunStatus_RUN_STATUS_UNSPECIFIED
+}
+
+func (x *Run) GetQueuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.QueuedAt
+	}
+	return nil
+}
+
+func (x *Run) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Run) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Run) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *Run) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Run) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+// Action is an action taken by a run, or proposed in dry-run, as printed by --output json.
+type Action struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Action is e.g. clean, relabel, retag or orphan.
+	Action  string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Hash    string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Name    string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Tracker string `protobuf:"bytes,4,opt,name=tracker,proto3" json:"tracker,omitempty"`
+	Reason  string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Size of the torrent or orphan in bytes.
+	Size          int64    `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	Label         string   `protobuf:"bytes,7,opt,name=label,proto3" json:"label,omitempty"`
+	Tags          []string `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	UploadLimit   *int64   `protobuf:"varint,9,opt,name=upload_limit,json=uploadLimit,proto3,oneof" json:"upload_limit,omitempty"`
+	Path          string   `protobuf:"bytes,10,opt,name=path,proto3" json:"path,omitempty"`
+	ShareLimit    string   `protobuf:"bytes,11,opt,name=share_limit,json=shareLimit,proto3" json:"share_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Action) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Action) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Action) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Action) GetTracker() string {
+	if x != nil {
+		return x.Tracker
+	}
+	return ""
+}
+
+func (x *Action) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Action) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Action) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Action) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Action) GetUploadLimit() int64 {
+	if x != nil && x.UploadLimit != nil {
+		return *x.UploadLimit
+	}
+	return 0
+}
+
+func (x *Action) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Action) GetShareLimit() string {
+	if x != nil {
+		return x.ShareLimit
+	}
+	return ""
+}
+
+type TriggerRunResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*TriggerRunResponse_Run
+	//	*TriggerRunResponse_Action
+	Event         isTriggerRunResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerRunResponse) Reset() {
+	*x = TriggerRunResponse{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerRunResponse) ProtoMessage() {}
+
+func (x *TriggerRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerRunResponse.ProtoReflect.Descriptor instead.
+func (*TriggerRunResponse) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TriggerRunResponse) GetEvent() isTriggerRunResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *TriggerRunResponse) GetRun() *Run {
+	if x != nil {
+		if x, ok := x.Event.(*TriggerRunResponse_Run); ok {
+			return x.Run
+		}
+	}
+	return nil
+}
+
+func (x *TriggerRunResponse) GetAction() *Action {
+	if x != nil {
+		if x, ok := x.Event.(*TriggerRunResponse_Action); ok {
+			return x.Action
+		}
+	}
+	return nil
+}
+
+type isTriggerRunResponse_Event interface {
+	isTriggerRunResponse_Event()
+}
+
+type TriggerRunResponse_Run struct {
+	Run *Run `protobuf:"bytes,1,opt,name=run,proto3,oneof"`
+}
+
+type TriggerRunResponse_Action struct {
+	Action *Action `protobuf:"bytes,2,opt,name=action,proto3,oneof"`
+}
+
+func (*TriggerRunResponse_Run) isTriggerRunResponse_Event() {}
+
+func (*TriggerRunResponse_Action) isTriggerRunResponse_Event() {}
+
+type ListRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsRequest) Reset() {
+	*x = ListRunsRequest{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsRequest) ProtoMessage() {}
+
+func (x *ListRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListRunsRequest) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{6}
+}
+
+type ListRunsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*Run                 `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunsResponse) Reset() {
+	*x = ListRunsResponse{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunsResponse) ProtoMessage() {}
+
+func (x *ListRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListRunsResponse) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListRunsResponse) GetRuns() []*Run {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+type GetRunRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Id of the run, 0 for the most recent run.
+	Id            int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunRequest) Reset() {
+	*x = GetRunRequest{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunRequest) ProtoMessage() {}
+
+func (x *GetRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunRequest.ProtoReflect.Descriptor instead.
+func (*GetRunRequest) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetRunRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetRunResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Run           *Run                   `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunResponse) Reset() {
+	*x = GetRunResponse{}
+	mi := &file_tqm_v1_tqm_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunResponse) ProtoMessage() {}
+
+func (x *GetRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tqm_v1_tqm_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunResponse.ProtoReflect.Descriptor instead.
+func (*GetRunResponse) Descriptor() ([]byte, []int) {
+	return file_tqm_v1_tqm_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetRunResponse) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+var File_tqm_v1_tqm_proto protoreflect.FileDescriptor
+
+const file_tqm_v1_tqm_proto_rawDesc = "" +
+	"\n" +
+	"\x10tqm/v1/tqm.proto\x12\x06tqm.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x0f\n" +
+	"\rHealthRequest\"*\n" +
+	"\x0eHealthResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"v\n" +
+	"\x11TriggerRunRequest\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x16\n" +
+	"\x06client\x18\x02 \x01(\tR\x06client\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\"\xb2\x03\n" +
+	"\x03Run\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\x12\x16\n" +
+	"\x06client\x18\x03 \x01(\tR\x06client\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\x12)\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x11.tqm.v1.RunStatusR\x06status\x127\n" +
+	"\tqueued_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bqueuedAt\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12 \n" +
+	"\texit_code\x18\n" +
+	" \x01(\x05H\x00R\bexitCode\x88\x01\x01\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\x12\x16\n" +
+	"\x06output\x18\f \x01(\tR\x06outputB\f\n" +
+	"\n" +
+	"_exit_code\"\xa6\x02\n" +
+	"\x06Action\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\atracker\x18\x04 \x01(\tR\atracker\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05label\x18\a \x01(\tR\x05label\x12\x12\n" +
+	"\x04tags\x18\b \x03(\tR\x04tags\x12&\n" +
+	"\fupload_limit\x18\t \x01(\x03H\x00R\vuploadLimit\x88\x01\x01\x12\x12\n" +
+	"\x04path\x18\n" +
+	" \x01(\tR\x04path\x12\x1f\n" +
+	"\vshare_limit\x18\v \x01(\tR\n" +
+	"shareLimitB\x0f\n" +
+	"\r_upload_limit\"h\n" +
+	"\x12TriggerRunResponse\x12\x1f\n" +
+	"\x03run\x18\x01 \x01(\v2\v.tqm.v1.RunH\x00R\x03run\x12(\n" +
+	"\x06action\x18\x02 \x01(\v2\x0e.tqm.v1.ActionH\x00R\x06actionB\a\n" +
+	"\x05event\"\x11\n" +
+	"\x0fListRunsRequest\"3\n" +
+	"\x10ListRunsResponse\x12\x1f\n" +
+	"\x04runs\x18\x01 \x03(\v2\v.tqm.v1.RunR\x04runs\"\x1f\n" +
+	"\rGetRunRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"/\n" +
+	"\x0eGetRunResponse\x12\x1d\n" +
+	"\x03run\x18\x01 \x01(\v2\v.tqm.v1.RunR\x03run*\x87\x01\n" +
+	"\tRunStatus\x12\x1a\n" +
+	"\x16RUN_STATUS_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11RUN_STATUS_QUEUED\x10\x01\x12\x16\n" +
+	"\x12RUN_STATUS_RUNNING\x10\x02\x12\x18\n" +
+	"\x14RUN_STATUS_SUCCEEDED\x10\x03\x12\x15\n" +
+	"\x11RUN_STATUS_FAILED\x10\x042\x84\x02\n" +
+	"\n" +
+	"TqmService\x127\n" +
+	"\x06Health\x12\x15.tqm.v1.HealthRequest\x1a\x16.tqm.v1.HealthResponse\x12E\n" +
+	"\n" +
+	"TriggerRun\x12\x19.tqm.v1.TriggerRunRequest\x1a\x1a.tqm.v1.TriggerRunResponse0\x01\x12=\n" +
+	"\bListRuns\x12\x17.tqm.v1.ListRunsRequest\x1a\x18.tqm.v1.ListRunsResponse\x127\n" +
+	"\x06GetRun\x12\x15.tqm.v1.GetRunRequest\x1a\x16.tqm.v1.GetRunResponseB&Z$github.com/autobrr/tqm/pkg/rpc/tqmv1b\x06proto3"
+
+var (
+	file_tqm_v1_tqm_proto_rawDescOnce sync.Once
+	file_tqm_v1_tqm_proto_rawDescData []byte
+)
+
+func file_tqm_v1_tqm_proto_rawDescGZIP() []byte {
+	file_tqm_v1_tqm_proto_rawDescOnce.Do(func() {
+		file_tqm_v1_tqm_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tqm_v1_tqm_proto_rawDesc), len(file_tqm_v1_tqm_proto_rawDesc)))
+	})
+	return file_tqm_v1_tqm_proto_rawDescData
+}
+
+var file_tqm_v1_tqm_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_tqm_v1_tqm_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_tqm_v1_tqm_proto_goTypes = []any{
+	(RunStatus)(0),                // 0: tqm.v1.RunStatus
+	(*HealthRequest)(nil),         // 1: tqm.v1.HealthRequest
+	(*HealthResponse)(nil),        // 2: tqm.v1.HealthResponse
+	(*TriggerRunRequest)(nil),     // 3: tqm.v1.TriggerRunRequest
+	(*Run)(nil),                   // 4: tqm.v1.Run
+	(*Action)(nil),                // 5: tqm.v1.Action
+	(*TriggerRunResponse)(nil),    // 6: tqm.v1.TriggerRunResponse
+	(*ListRunsRequest)(nil),       // 7: tqm.v1.ListRunsRequest
+	(*ListRunsResponse)(nil),      // 8: tqm.v1.ListRunsResponse
+	(*GetRunRequest)(nil),         // 9: tqm.v1.GetRunRequest
+	(*GetRunResponse)(nil),        // 10: tqm.v1.GetRunResponse
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_tqm_v1_tqm_proto_depIdxs = []int32{
+	0,  // 0: tqm.v1.Run.status:type_name -> tqm.v1.RunStatus
+	11, // 1: tqm.v1.Run.queued_at:type_name -> google.protobuf.Timestamp
+	11, // 2: tqm.v1.Run.started_at:type_name -> google.protobuf.Timestamp
+	11, // 3: tqm.v1.Run.finished_at:type_name -> google.protobuf.Timestamp
+	4,  // 4: tqm.v1.TriggerRunResponse.run:type_name -> tqm.v1.Run
+	5,  // 5: tqm.v1.TriggerRunResponse.action:type_name -> tqm.v1.Action
+	4,  // 6: tqm.v1.ListRunsResponse.runs:type_name -> tqm.v1.Run
+	4,  // 7: tqm.v1.GetRunResponse.run:type_name -> tqm.v1.Run
+	1,  // 8: tqm.v1.TqmService.Health:input_type -> tqm.v1.HealthRequest
+	3,  // 9: tqm.v1.TqmService.TriggerRun:input_type -> tqm.v1.TriggerRunRequest
+	7,  // 10: tqm.v1.TqmService.ListRuns:input_type -> tqm.v1.ListRunsRequest
+	9,  // 11: tqm.v1.TqmService.GetRun:input_type -> tqm.v1.GetRunRequest
+	2,  // 12: tqm.v1.TqmService.Health:output_type -> tqm.v1.HealthResponse
+	6,  // 13: tqm.v1.TqmService.TriggerRun:output_type -> tqm.v1.TriggerRunResponse
+	8,  // 14: tqm.v1.TqmService.ListRuns:output_type -> tqm.v1.ListRunsResponse
+	10, // 15: tqm.v1.TqmService.GetRun:output_type -> tqm.v1.GetRunResponse
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_tqm_v1_tqm_proto_init() }
+func file_tqm_v1_tqm_proto_init() {
+	if File_tqm_v1_tqm_proto != nil {
+		return
+	}
+	file_tqm_v1_tqm_proto_msgTypes[3].OneofWrappers = []any{}
+	file_tqm_v1_tqm_proto_msgTypes[4].OneofWrappers = []any{}
+	file_tqm_v1_tqm_proto_msgTypes[5].OneofWrappers = []any{
+		(*TriggerRunResponse_Run)(nil),
+		(*TriggerRunResponse_Action)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tqm_v1_tqm_proto_rawDesc), len(file_tqm_v1_tqm_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tqm_v1_tqm_proto_goTypes,
+		DependencyIndexes: file_tqm_v1_tqm_proto_depIdxs,
+		EnumInfos:         file_tqm_v1_tqm_proto_enumTypes,
+		MessageInfos:      file_tqm_v1_tqm_proto_msgTypes,
+	}.Build()
+	File_tqm_v1_tqm_proto = out.File
+	file_tqm_v1_tqm_proto_goTypes = nil
+	file_tqm_v1_tqm_proto_depIdxs = nil
+}
@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: tqm/v1/tqm.proto
+
+package tqmv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TqmService_Health_FullMethodName     = "/tqm.v1.TqmService/Health"
+	TqmService_TriggerRun_FullMethodName = "/tqm.v1.TqmService/TriggerRun"
+	TqmService_ListRuns_FullMethodName   = "/tqm.v1.TqmService/ListRuns"
+	TqmService_GetRun_FullMethodName     = "/tqm.v1.TqmService/GetRun"
+)
+
+// TqmServiceClient is the client API for TqmService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TqmService triggers runs of tqm commands and streams the actions they take. When an api_key is configured in the
+// serve section, it must be sent in the x-api-key metadata of every call except Health.
+type TqmServiceClient interface {
+	// Health returns the version of the server.
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	// TriggerRun queues a run of a command against a client and streams its actions as they are taken. The first event
+	// is the queued run, the last one the finished run with its status and exit code.
+	TriggerRun(ctx context.Context, in *TriggerRunRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TriggerRunResponse], error)
+	// ListRuns lists the most recent runs, newest first, without their output.
+	ListRuns(ctx context.Context, in *ListRunsRequest, opts ...grpc.CallOption) (*ListRunsResponse, error)
+	// GetRun returns a single run including the last 64 KiB of its log output.
+	GetRun(ctx context.Context, in *GetRunRequest, opts ...grpc.CallOption) (*GetRunResponse, error)
+}
+
+type tqmServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTqmServiceClient(cc grpc.ClientConnInterface) TqmServiceClient {
+	return &tqmServiceClient{cc}
+}
+
+func (c *tqmServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, TqmService_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tqmServiceClient) TriggerRun(ctx context.Context, in *TriggerRunRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TriggerRunResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TqmService_ServiceDesc.Streams[0], TqmService_TriggerRun_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TriggerRunRequest, TriggerRunResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TqmService_TriggerRunClient = grpc.ServerStreamingClient[TriggerRunResponse]
+
+func (c *tqmServiceClient) ListRuns(ctx context.Context, in *ListRunsRequest, opts ...grpc.CallOption) (*ListRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRunsResponse)
+	err := c.cc.Invoke(ctx, TqmService_ListRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tqmServiceClient) GetRun(ctx context.Context, in *GetRunRequest, opts ...grpc.CallOption) (*GetRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRunResponse)
+	err := c.cc.Invoke(ctx, TqmService_GetRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TqmServiceServer is the server API for TqmService service.
+// All implementations must embed UnimplementedTqmServiceServer
+// for forward compatibility.
+//
+// TqmService triggers runs of tqm commands and streams the actions they take. When an api_key is configured in the
+// serve section, it must be sent in the x-api-key metadata of every call except Health.
+type TqmServiceServer interface {
+	// Health returns the version of the server.
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	// TriggerRun queues a run of a command against a client and streams its actions as they are taken. The first event
+	// is the queued run, the last one the finished run with its status and exit code.
+	TriggerRun(*TriggerRunRequest, grpc.ServerStreamingServer[TriggerRunResponse]) error
+	// ListRuns lists the most recent runs, newest first, without their output.
+	ListRuns(context.Context, *ListRunsRequest) (*ListRunsResponse, error)
+	// GetRun returns a single run including the last 64 KiB of its log output.
+	GetRun(context.Context, *GetRunRequest) (*GetRunResponse, error)
+	mustEmbedUnimplementedTqmServiceServer()
+}
+
+// UnimplementedTqmServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTqmServiceServer struct{}
+
+func (UnimplementedTqmServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedTqmServiceServer) TriggerRun(*TriggerRunRequest, grpc.ServerStreamingServer[TriggerRunResponse]) error {
+	return status.Error(codes.Unimplemented, "method TriggerRun not implemented")
+}
+func (UnimplementedTqmServiceServer) ListRuns(context.Context, *ListRunsRequest) (*ListRunsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRuns not implemented")
+}
+func (UnimplementedTqmServiceServer) GetRun(context.Context, *GetRunRequest) (*GetRunResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRun not implemented")
+}
+func (UnimplementedTqmServiceServer) mustEmbedUnimplementedTqmServiceServer() {}
+func (UnimplementedTqmServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeTqmServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TqmServiceServer will
+// result in compilation errors.
+type UnsafeTqmServiceServer interface {
+	mustEmbedUnimplementedTqmServiceServer()
+}
+
+func RegisterTqmServiceServer(s grpc.ServiceRegistrar, srv TqmServiceServer) {
+	// If the following call panics, it indicates UnimplementedTqmServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TqmService_ServiceDesc, srv)
+}
+
+func _TqmService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TqmServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TqmService_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TqmServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TqmService_TriggerRun_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TriggerRunRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TqmServiceServer).TriggerRun(m, &grpc.GenericServerStream[TriggerRunRequest, TriggerRunResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TqmService_TriggerRunServer = grpc.ServerStreamingServer[TriggerRunResponse]
+
+func _TqmService_ListRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TqmServiceServer).ListRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TqmService_ListRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TqmServiceServer).ListRuns(ctx, req.(*ListRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TqmService_GetRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TqmServiceServer).GetRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TqmService_GetRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TqmServiceServer).GetRun(ctx, req.(*GetRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TqmService_ServiceDesc is the grpc.ServiceDesc for TqmService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TqmService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tqm.v1.TqmService",
+	HandlerType: (*TqmServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _TqmService_Health_Handler,
+		},
+		{
+			MethodName: "ListRuns",
+			Handler:    _TqmService_ListRuns_Handler,
+		},
+		{
+			MethodName: "GetRun",
+			Handler:    _TqmService_GetRun_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TriggerRun",
+			Handler:       _TqmService_TriggerRun_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tqm/v1/tqm.proto",
+}
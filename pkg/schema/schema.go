@@ -0,0 +1,190 @@
+// Package schema defines the versioned JSON documents tqm writes for external tooling (exports and the actions of
+// runs), decoupled from the internal structs so dashboards and scripts keep working when those change. Fields are
+// only added within a version, renaming or removing one bumps Version.
+package schema
+
+import (
+	_ "embed"
+	"slices"
+	"time"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// Version is the version of the documents, written as their schema_version
+const Version = 1
+
+// jsonSchema is the JSON schema of the documents of Version
+//
+//go:embed tqm.v1.schema.json
+var jsonSchema []byte
+
+// JSONSchema returns the JSON schema of the documents of Version
+func JSONSchema() []byte {
+	return slices.Clone(jsonSchema)
+}
+
+// Export is the document written by the export command
+type Export struct {
+	SchemaVersion int       `json:"schema_version"`
+	Torrents      []Torrent `json:"torrents"`
+}
+
+// Torrent is a torrent as seen by filters, with the results of the helpers and configured filters
+type Torrent struct {
+	Hash            string   `json:"Hash"`
+	Name            string   `json:"Name"`
+	Path            string   `json:"Path"`
+	TotalBytes      int64    `json:"TotalBytes"`
+	DownloadedBytes int64    `json:"DownloadedBytes"`
+	State           string   `json:"State"`
+	Files           []string `json:"Files"`
+	// Tags are sorted
+	Tags                []string `json:"Tags"`
+	Downloaded          bool     `json:"Downloaded"`
+	Seeding             bool     `json:"Seeding"`
+	Ratio               float32  `json:"Ratio"`
+	AddedSeconds        int64    `json:"AddedSeconds"`
+	AddedHours          float32  `json:"AddedHours"`
+	AddedDays           float32  `json:"AddedDays"`
+	SeedingSeconds      int64    `json:"SeedingSeconds"`
+	SeedingHours        float32  `json:"SeedingHours"`
+	SeedingDays         float32  `json:"SeedingDays"`
+	LastActivitySeconds int64    `json:"LastActivitySeconds"`
+	LastActivityHours   float32  `json:"LastActivityHours"`
+	LastActivityDays    float32  `json:"LastActivityDays"`
+	Label               string   `json:"Label"`
+	Seeds               int64    `json:"Seeds"`
+	Peers               int64    `json:"Peers"`
+	IsPrivate           bool     `json:"IsPrivate"`
+	IsPublic            bool     `json:"IsPublic"`
+	DHTEnabled          bool     `json:"DHTEnabled"`
+	PeXEnabled          bool     `json:"PeXEnabled"`
+	UpLimit             int64    `json:"UpLimit"`
+
+	// share limits (-2 = client global limit, -1 = unlimited, 0 when unknown)
+	RatioLimit               float64 `json:"RatioLimit"`
+	SeedingTimeLimit         int64   `json:"SeedingTimeLimit"`
+	InactiveSeedingTimeLimit int64   `json:"InactiveSeedingTimeLimit"`
+
+	TrackerName   string `json:"TrackerName"`
+	TrackerHost   string `json:"TrackerHost"`
+	TrackerStatus string `json:"TrackerStatus"`
+	// TrackerStatusCode is the numeric status of the first tracker (qBittorrent only)
+	TrackerStatusCode int `json:"TrackerStatusCode"`
+	// AllTrackerStatuses are the status messages of all trackers, by tracker URL
+	AllTrackerStatuses map[string]string `json:"AllTrackerStatuses"`
+	// AllTrackerStatusCodes are the numeric statuses of all trackers, by tracker URL
+	AllTrackerStatusCodes map[string]int `json:"AllTrackerStatusCodes"`
+	Comment               string         `json:"Comment"`
+
+	HardlinkedOutsideClient bool  `json:"HardlinkedOutsideClient"`
+	Match                   Match `json:"Match"`
+}
+
+// Match holds the results of the helpers and configured filters for a torrent
+type Match struct {
+	Unregistered  bool   `json:"Unregistered"`
+	TrackerDown   bool   `json:"TrackerDown"`
+	PublicTracker bool   `json:"PublicTracker"`
+	Ignore        bool   `json:"Ignore"`
+	IgnoreReason  string `json:"IgnoreReason,omitempty"`
+	Remove        bool   `json:"Remove"`
+	RemoveReason  string `json:"RemoveReason,omitempty"`
+	Pause         bool   `json:"Pause"`
+	Resume        bool   `json:"Resume"`
+	// Label is the label set by the label filters, empty when unchanged
+	Label string `json:"Label,omitempty"`
+}
+
+// NewTorrent returns t as a torrent of the documents, m being the results of its filters
+func NewTorrent(t config.Torrent, m Match) Torrent {
+	statuses := t.AllTrackerStatuses
+	if statuses == nil {
+		statuses = map[string]string{}
+	}
+	statusCodes := t.AllTrackerStatusCodes
+	if statusCodes == nil {
+		statusCodes = map[string]int{}
+	}
+	files := t.Files
+	if files == nil {
+		files = []string{}
+	}
+
+	return Torrent{
+		Hash:                     t.Hash,
+		Name:                     t.Name,
+		Path:                     t.Path,
+		TotalBytes:               t.TotalBytes,
+		DownloadedBytes:          t.DownloadedBytes,
+		State:                    t.State,
+		Files:                    files,
+		Tags:                     t.TagsSlice(),
+		Downloaded:               t.Downloaded,
+		Seeding:                  t.Seeding,
+		Ratio:                    t.Ratio,
+		AddedSeconds:             t.AddedSeconds,
+		AddedHours:               t.AddedHours,
+		AddedDays:                t.AddedDays,
+		SeedingSeconds:           t.SeedingSeconds,
+		SeedingHours:             t.SeedingHours,
+		SeedingDays:              t.SeedingDays,
+		LastActivitySeconds:      t.LastActivitySeconds,
+		LastActivityHours:        t.LastActivityHours,
+		LastActivityDays:         t.LastActivityDays,
+		Label:                    t.Label,
+		Seeds:                    t.Seeds,
+		Peers:                    t.Peers,
+		IsPrivate:                t.IsPrivate,
+		IsPublic:                 t.IsPublic,
+		DHTEnabled:               t.DHTEnabled,
+		PeXEnabled:               t.PeXEnabled,
+		UpLimit:                  t.UpLimit,
+		RatioLimit:               t.RatioLimit,
+		SeedingTimeLimit:         t.SeedingTimeLimit,
+		InactiveSeedingTimeLimit: t.InactiveSeedingTimeLimit,
+		TrackerName:              t.TrackerName,
+		TrackerHost:              t.TrackerHost,
+		TrackerStatus:            t.TrackerStatus,
+		TrackerStatusCode:        t.TrackerStatusCode,
+		AllTrackerStatuses:       statuses,
+		AllTrackerStatusCodes:    statusCodes,
+		Comment:                  t.Comment,
+		HardlinkedOutsideClient:  t.HardlinkedOutsideClient,
+		Match:                    m,
+	}
+}
+
+// Run is the document printed with --output json, listing the actions taken (or proposed in dry-run)
+type Run struct {
+	SchemaVersion int       `json:"schema_version"`
+	Command       string    `json:"command"`
+	Client        string    `json:"client,omitempty"`
+	DryRun        bool      `json:"dry_run"`
+	Started       time.Time `json:"started"`
+	Duration      float64   `json:"duration_seconds"`
+	Actions       []Action  `json:"actions"`
+}
+
+// Action is an action taken on a torrent or orphan
+type Action struct {
+	Action  string `json:"action"`
+	Hash    string `json:"hash,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Tracker string `json:"tracker,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	// Size of the torrent or orphan in bytes
+	Size        int64    `json:"size,omitempty"`
+	Label       string   `json:"label,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	UploadLimit *int64   `json:"upload_limit,omitempty"`
+	Path        string   `json:"path,omitempty"`
+	ShareLimit  string   `json:"share_limit,omitempty"`
+}
+
+// ActionLine is an action printed as a JSON line with --output jsonl
+type ActionLine struct {
+	SchemaVersion int `json:"schema_version"`
+	Action
+}
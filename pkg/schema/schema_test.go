@@ -0,0 +1,108 @@
+package schema
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/config"
+)
+
+// definition is the part of a definition of the JSON schema checked against the documents
+type definition struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// keys returns the sorted keys of v marshalled as a JSON object
+func keys(t *testing.T, v any) []string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	var m map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &m))
+
+	var k []string
+	for key := range m {
+		k = append(k, key)
+	}
+	slices.Sort(k)
+	return k
+}
+
+func TestJSONSchema(t *testing.T) {
+	var s struct {
+		Defs map[string]definition `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(JSONSchema(), &s))
+
+	limit := int64(1024)
+	tests := []struct {
+		def string
+		// full has every field set, empty the optional ones unset
+		full  any
+		empty any
+	}{
+		{
+			def:   "export",
+			full:  Export{SchemaVersion: Version, Torrents: []Torrent{}},
+			empty: Export{SchemaVersion: Version},
+		},
+		{
+			def:   "torrent",
+			full:  NewTorrent(config.Torrent{}, Match{}),
+			empty: NewTorrent(config.Torrent{}, Match{}),
+		},
+		{
+			def:   "match",
+			full:  Match{IgnoreReason: "a", RemoveReason: "b", Label: "c"},
+			empty: Match{},
+		},
+		{
+			def:   "run",
+			full:  Run{SchemaVersion: Version, Client: "qbt", Started: time.Now(), Actions: []Action{}},
+			empty: Run{SchemaVersion: Version},
+		},
+		{
+			def: "action",
+			full: Action{Action: "retag", Hash: "abc", Name: "a", Tracker: "b", Reason: "c", Size: 1, Label: "d",
+				Tags: []string{"e"}, UploadLimit: &limit, Path: "f", ShareLimit: "g"},
+			empty: Action{Action: "remove"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			def, ok := s.Defs[tt.def]
+			require.True(t, ok, "missing definition")
+
+			properties := make([]string, 0, len(def.Properties))
+			for p := range def.Properties {
+				properties = append(properties, p)
+			}
+			slices.Sort(properties)
+			assert.Equal(t, properties, keys(t, tt.full), "properties do not match the fields")
+
+			required := slices.Sorted(slices.Values(def.Required))
+			assert.Equal(t, required, keys(t, tt.empty), "required properties do not match the non optional fields")
+		})
+	}
+}
+
+func TestNewTorrent(t *testing.T) {
+	torrent := NewTorrent(config.Torrent{
+		Hash: "abc",
+		Tags: map[string]struct{}{"b": {}, "a": {}},
+	}, Match{Remove: true})
+
+	assert.Equal(t, []string{"a", "b"}, torrent.Tags)
+	assert.NotNil(t, torrent.Files)
+	assert.NotNil(t, torrent.AllTrackerStatuses)
+	assert.True(t, torrent.Match.Remove)
+}
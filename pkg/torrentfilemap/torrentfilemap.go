@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/paths"
 )
 
 func New(torrents map[string]config.Torrent) *TorrentFileMap {
@@ -131,10 +132,22 @@ func (t *TorrentFileMap) hasPathDirect(path string) bool {
 // hasPathWithMapping checks if a path exists using torrent path mappings
 func (t *TorrentFileMap) hasPathWithMapping(path string, torrentPathMapping map[string]string) bool {
 	for torrentPath := range t.torrentFileMap {
-		for mapFrom, mapTo := range torrentPathMapping {
-			if strings.Contains(strings.Replace(torrentPath, mapFrom, mapTo, 1), path) {
-				return true
-			}
+		if strings.Contains(paths.MapPath(torrentPath, torrentPathMapping), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPathUnder reports whether a file of a torrent is inside dir
+func (t *TorrentFileMap) HasPathUnder(dir string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	roots := []string{dir}
+	for torrentPath := range t.torrentFileMap {
+		if paths.InSubtree(torrentPath, roots) {
+			return true
 		}
 	}
 	return false
@@ -0,0 +1,207 @@
+package tqm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/autobrr/tqm/pkg/client"
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/expression"
+)
+
+// PathMappingHardlinks is the download_path_mappings key of the mapping used to detect hardlinks
+const PathMappingHardlinks = "hardlinks"
+
+// Client is a connected client of the config together with the filter its expressions were compiled from
+type Client struct {
+	client.Interface
+
+	Name string
+	// Filter is the filter of the client, or the one given to Open
+	Filter *config.FilterConfiguration
+	// Settings are the settings of the client in the config (e.g. type, download_path_mapping)
+	Settings map[string]any
+}
+
+// Open initializes and connects the client with the given name, compiling the client filter (or filterName, when set)
+func Open(ctx context.Context, name string, filterName string) (*Client, error) {
+	settings, ok := config.Config.Clients[name]
+	if !ok {
+		return nil, fmt.Errorf("no client configuration found for: %q", name)
+	}
+
+	if err := ValidateClientEnabled(settings); err != nil {
+		return nil, fmt.Errorf("validate client enabled: %w", err)
+	}
+
+	clientType, err := ClientString("type", settings)
+	if err != nil {
+		return nil, fmt.Errorf("determine client type: %w", err)
+	}
+
+	filter, err := ClientFilter(settings)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve client filter: %w", err)
+	}
+
+	if filterName != "" {
+		filter, err = config.GetFilter(filterName)
+		if err != nil {
+			return nil, fmt.Errorf("retrieve specified filter: %w", err)
+		}
+	}
+
+	exp, err := expression.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("compile client filters: %w", err)
+	}
+
+	c, err := client.NewClient(*clientType, name, exp)
+	if err != nil {
+		return nil, fmt.Errorf("initialize client: %q: %w", name, err)
+	}
+
+	if err := c.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+
+	return &Client{Interface: c, Name: name, Filter: filter, Settings: settings}, nil
+}
+
+// DownloadPathMapping returns the download path mapping of the client for the given download_path_mappings keys
+func (c *Client) DownloadPathMapping(keys ...string) (map[string]string, error) {
+	return DownloadPathMapping(c.Settings, keys...)
+}
+
+// LoadFreeSpace retrieves the current free space of the client so it can be used by filters (see GetFreeSpace) and
+// reports whether it was retrieved, clients needing a free_space_path without one are skipped
+func (c *Client) LoadFreeSpace(ctx context.Context) (bool, error) {
+	return LoadFreeSpace(ctx, c.Interface, c.Settings)
+}
+
+// ValidateClientEnabled returns an error unless the client settings enable the client
+func ValidateClientEnabled(settings map[string]any) error {
+	v, ok := settings["enabled"]
+	if !ok {
+		return fmt.Errorf("no enabled setting found in client configuration: %+v", settings)
+	} else {
+		enabled, ok := v.(bool)
+		if !ok || !enabled {
+			return errors.New("client is not enabled")
+		}
+	}
+
+	return nil
+}
+
+// ClientString returns the string setting of the client settings, it is an error when it is not set
+func ClientString(setting string, settings map[string]any) (*string, error) {
+	v, ok := settings[setting]
+	if !ok {
+		return nil, fmt.Errorf("no %q setting found in client configuration: %+v", setting, settings)
+	}
+
+	value, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("failed type-asserting %q of client: %#v", setting, v)
+	}
+
+	return &value, nil
+}
+
+// ClientBool returns the bool setting of the client settings, false when it is not set
+func ClientBool(setting string, settings map[string]any) (bool, error) {
+	v, ok := settings[setting]
+	if !ok {
+		return false, nil
+	}
+
+	value, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("failed type-asserting %q of client: %#v", setting, v)
+	}
+
+	return value, nil
+}
+
+// ClientFilter returns the filter set for the client settings
+func ClientFilter(settings map[string]any) (*config.FilterConfiguration, error) {
+	v, ok := settings["filter"]
+	if !ok {
+		return nil, fmt.Errorf("no filter setting found in client configuration: %+v", settings)
+	}
+
+	clientFilterName, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("failed type-asserting filter of client: %#v", v)
+	}
+
+	return config.GetFilter(clientFilterName)
+}
+
+// DownloadPathMapping returns the download path mapping of the client settings for the given download_path_mappings
+// keys, the first key set wins (e.g. the command, then the purpose). download_path_mapping is used when none is set.
+func DownloadPathMapping(settings map[string]any, keys ...string) (map[string]string, error) {
+	if v, ok := settings["download_path_mappings"]; ok {
+		mappings, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed type-asserting download_path_mappings of client: %#v", v)
+		}
+
+		for _, key := range keys {
+			if mapping, ok := mappings[key]; ok {
+				return parseDownloadPathMapping("download_path_mappings."+key, mapping)
+			}
+		}
+	}
+
+	v, ok := settings["download_path_mapping"]
+	if !ok {
+		return nil, nil
+	}
+
+	return parseDownloadPathMapping("download_path_mapping", v)
+}
+
+func parseDownloadPathMapping(setting string, v any) (map[string]string, error) {
+	tmp, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed type-asserting %s of client: %#v", setting, v)
+	}
+
+	clientDownloadPathMapping := make(map[string]string)
+	for k, v := range tmp {
+		if vv, ok := v.(string); ok {
+			clientDownloadPathMapping[k] = vv
+		} else {
+			return nil, fmt.Errorf("failed type-asserting %s of client for %q: %#v", setting, k, v)
+		}
+	}
+
+	return clientDownloadPathMapping, nil
+}
+
+// LoadFreeSpace retrieves the current free space of c so it can be used by filters and reports whether it was
+// retrieved, clients needing a free_space_path without one are skipped
+func LoadFreeSpace(ctx context.Context, c client.Interface, settings map[string]any) (bool, error) {
+	clientFreeSpacePath, _ := ClientString("free_space_path", settings)
+
+	path := ""
+	if clientFreeSpacePath != nil {
+		path = *clientFreeSpacePath
+	} else if !ReportsFreeSpace(c) {
+		return false, nil
+	}
+
+	if _, err := c.GetCurrentFreeSpace(ctx, path); err != nil {
+		return false, fmt.Errorf("retrieve free-space: %q: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// ReportsFreeSpace returns whether the client can retrieve its free space without a free_space_path
+func ReportsFreeSpace(c client.Interface) bool {
+	return c.Capabilities().FreeSpace
+}
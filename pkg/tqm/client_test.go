@@ -0,0 +1,41 @@
+package tqm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadPathMapping(t *testing.T) {
+	clientConfig := map[string]any{
+		"download_path_mapping": map[string]any{"/downloads": "/mnt/downloads"},
+		"download_path_mappings": map[string]any{
+			"orphan":    map[string]any{"/downloads": "/mnt/ro/downloads"},
+			"hardlinks": map[string]any{"/downloads": "/mnt/hardlinks/downloads"},
+			"clean":     map[string]any{"/downloads": "/mnt/clean/downloads"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected string
+	}{
+		{name: "command", keys: []string{"orphan"}, expected: "/mnt/ro/downloads"},
+		{name: "command_before_purpose", keys: []string{"clean", PathMappingHardlinks}, expected: "/mnt/clean/downloads"},
+		{name: "purpose", keys: []string{"relabel", PathMappingHardlinks}, expected: "/mnt/hardlinks/downloads"},
+		{name: "default", keys: []string{"prune-categories"}, expected: "/mnt/downloads"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, err := DownloadPathMapping(clientConfig, tt.keys...)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"/downloads": tt.expected}, mapping)
+		})
+	}
+
+	_, err := DownloadPathMapping(map[string]any{"download_path_mappings": map[string]any{"orphan": "/mnt"}}, "orphan")
+	assert.ErrorContains(t, err, "download_path_mappings.orphan")
+}
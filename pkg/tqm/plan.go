@@ -0,0 +1,131 @@
+package tqm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/evaluate"
+	"github.com/autobrr/tqm/pkg/expression"
+	"github.com/autobrr/tqm/pkg/hardlinkfilemap"
+	"github.com/autobrr/tqm/pkg/torrentfilemap"
+)
+
+// actions of the decisions planned for torrents
+const (
+	ActionIgnore = "ignore"
+	ActionKeep   = "keep"
+	ActionRemove = "remove"
+)
+
+// Decision is what clean would do with a torrent
+type Decision struct {
+	Torrent config.Torrent
+	// Action is ActionIgnore, ActionKeep or ActionRemove
+	Action string
+	// Reason is the ignore or remove expression which matched
+	Reason string
+	// DeleteData is set when the data of the removed torrent would be deleted
+	DeleteData bool
+	// Detail explains why a torrent matching the remove filters is kept (e.g. not unique)
+	Detail string
+}
+
+// PlanClean evaluates the ignore and remove filters of the client for torrents like the clean command and returns the
+// decision for every torrent, without acting on them. Torrents sharing files with torrents which are kept are kept as
+// well, unless they are unregistered. The removal caps, free space targets and hooks of clean are not applied.
+func (c *Client) PlanClean(ctx context.Context, torrents map[string]config.Torrent) ([]Decision, error) {
+	// scripts are run again for every plan
+	expression.ResetScriptResults()
+
+	deleteData := true
+	if c.Filter != nil && c.Filter.DeleteData != nil {
+		deleteData = *c.Filter.DeleteData
+	}
+
+	tfm := torrentfilemap.New(torrents)
+	var hfm hardlinkfilemap.HardlinkFileMapI = hardlinkfilemap.NewNoopHardlinkFileMap()
+	if c.Filter != nil && evaluate.StringSliceContains(c.Filter.MapHardlinksFor, "clean", true) {
+		mapping, err := c.DownloadPathMapping("clean", PathMappingHardlinks)
+		if err != nil {
+			return nil, fmt.Errorf("load download path mapping: %w", err)
+		}
+		hfm = hardlinkfilemap.New(torrents, mapping)
+	}
+
+	type candidate struct {
+		Decision
+		hardlinked bool
+	}
+
+	var (
+		decisions  = make([]Decision, 0, len(torrents))
+		candidates []candidate
+	)
+
+	planRemoval := func(d Decision, hardlinked bool, unique bool) {
+		d.Action = ActionRemove
+		// the data of torrents overlapping with other torrents is kept
+		d.DeleteData = deleteData && (unique || hardlinked)
+		tfm.Remove(d.Torrent)
+		hfm.RemoveByTorrent(d.Torrent)
+		decisions = append(decisions, d)
+	}
+
+	for _, h := range slices.Sorted(maps.Keys(torrents)) {
+		t := torrents[h]
+
+		ignore, reason, err := c.ShouldIgnore(ctx, &t)
+		if err != nil {
+			return nil, fmt.Errorf("check ignore filters: %q: %w", t.Name, err)
+		} else if ignore && !(config.Config.BypassIgnoreIfUnregistered && t.IsUnregistered(ctx)) {
+			decisions = append(decisions, Decision{Torrent: t, Action: ActionIgnore, Reason: reason})
+			continue
+		}
+
+		remove, reason, err := c.ShouldRemoveWithReason(ctx, &t)
+		if err != nil {
+			return nil, fmt.Errorf("check remove filters: %q: %w", t.Name, err)
+		} else if !remove {
+			decisions = append(decisions, Decision{Torrent: t, Action: ActionKeep, Detail: "no remove filter matched"})
+			continue
+		}
+
+		d := Decision{Torrent: t, Reason: reason}
+		hardlinked := !hfm.IsTorrentUnique(t)
+		switch {
+		case tfm.IsUnique(t) && !hardlinked:
+			planRemoval(d, false, true)
+		case t.IsUnregistered(ctx):
+			// unregistered torrents are removed regardless of the torrents sharing their files
+			planRemoval(d, hardlinked, false)
+		default:
+			candidates = append(candidates, candidate{Decision: d, hardlinked: hardlinked})
+		}
+	}
+
+	// the torrents sharing files are removed once none of the torrents sharing them is kept
+	for _, cand := range candidates {
+		tfm.Remove(cand.Torrent)
+		hfm.RemoveByTorrent(cand.Torrent)
+	}
+
+	for _, cand := range candidates {
+		if tfm.NoInstances(cand.Torrent) && hfm.NoInstances(cand.Torrent) {
+			planRemoval(cand.Decision, cand.hardlinked, false)
+			continue
+		}
+
+		d := cand.Decision
+		d.Action = ActionKeep
+		d.Detail = "not unique (file overlap)"
+		if cand.hardlinked {
+			d.Detail = "not unique (hardlinked)"
+		}
+		decisions = append(decisions, d)
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,68 @@
+package tqm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanClean(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`
+clients:
+  mock:
+    enabled: true
+    type: mock
+    filter: default
+    file: torrents.json
+filters:
+  default:
+    ignore:
+      - IsPrivate
+    remove:
+      - Ratio > 2
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "torrents.json"), []byte(`[
+  {"Hash": "a", "Name": "private", "Ratio": 3, "IsPrivate": true, "Files": ["/d/a.mkv"]},
+  {"Hash": "b", "Name": "unique", "Ratio": 3, "Files": ["/d/b.mkv"]},
+  {"Hash": "c", "Name": "low ratio", "Ratio": 1, "Files": ["/d/c.mkv"]},
+  {"Hash": "d", "Name": "shared with kept", "Ratio": 3, "Files": ["/d/c.mkv"]},
+  {"Hash": "e", "Name": "shared 1", "Ratio": 3, "Files": ["/d/e.mkv"]},
+  {"Hash": "f", "Name": "shared 2", "Ratio": 3, "Files": ["/d/e.mkv"]}
+]`), 0600))
+
+	require.NoError(t, Init(filepath.Join(dir, "config.yaml")))
+
+	ctx := context.Background()
+	c, err := Open(ctx, "mock", "")
+	require.NoError(t, err)
+
+	torrents, err := c.GetTorrents(ctx)
+	require.NoError(t, err)
+
+	decisions, err := c.PlanClean(ctx, torrents)
+	require.NoError(t, err)
+
+	type planned struct {
+		action     string
+		deleteData bool
+		detail     string
+	}
+	got := make(map[string]planned, len(decisions))
+	for _, d := range decisions {
+		got[d.Torrent.Hash] = planned{action: d.Action, deleteData: d.DeleteData, detail: d.Detail}
+	}
+
+	assert.Equal(t, map[string]planned{
+		"a": {action: ActionIgnore},
+		"b": {action: ActionRemove, deleteData: true},
+		"c": {action: ActionKeep, detail: "no remove filter matched"},
+		"d": {action: ActionKeep, detail: "not unique (file overlap)"},
+		"e": {action: ActionRemove},
+		"f": {action: ActionRemove},
+	}, got)
+}
@@ -0,0 +1,49 @@
+// Package tqm is the engine of tqm as a Go library, so other tools (e.g. autobrr plugins or dashboards) can load a tqm
+// config, connect to its clients and evaluate the filters without running the CLI.
+//
+//	if err := tqm.Init("/config/config.yaml"); err != nil {
+//		return err
+//	}
+//
+//	c, err := tqm.Open(ctx, "qbt", "")
+//	if err != nil {
+//		return err
+//	}
+//
+//	torrents, err := c.GetTorrents(ctx)
+//	if err != nil {
+//		return err
+//	}
+//
+//	decisions, err := c.PlanClean(ctx, torrents)
+//
+// The configuration is global to the process, like for the CLI, so Init must be called once before opening clients.
+package tqm
+
+import (
+	"fmt"
+
+	"github.com/autobrr/tqm/pkg/config"
+	"github.com/autobrr/tqm/pkg/formatting"
+	"github.com/autobrr/tqm/pkg/paths"
+	"github.com/autobrr/tqm/pkg/tracker"
+)
+
+// Init loads the config file and initializes the formatting, filesystem concurrency and tracker APIs it configures
+func Init(configFile string) error {
+	if err := config.Init(configFile); err != nil {
+		return fmt.Errorf("initialize config: %w", err)
+	}
+
+	if err := formatting.Init(config.Config.Formatting.Units, config.Config.Formatting.Durations); err != nil {
+		return fmt.Errorf("initialize formatting: %w", err)
+	}
+
+	paths.SetStatConcurrency(config.Config.StatConcurrency)
+
+	if err := tracker.Init(config.Config.Trackers, config.StatePath(tracker.CacheFile)); err != nil {
+		return fmt.Errorf("initialize trackers: %w", err)
+	}
+
+	return nil
+}
@@ -5,13 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -19,6 +20,9 @@ import (
 
 type BHDConfig struct {
 	Key string `koanf:"api_key"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
 }
 
 type BHD struct {
@@ -32,7 +36,7 @@ func NewBHD(c BHDConfig) *BHD {
 	l := logger.GetLogger("bhd-api")
 	return &BHD{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack)),
+		http: c.httpClient(15*time.Second, l),
 		headers: map[string]string{
 			"Content-Type": "application/json",
 			"Accept":       "application/json",
@@ -49,74 +53,121 @@ func (c *BHD) Check(host string) bool {
 	return strings.Contains(host, "beyond-hd.me")
 }
 
+// bhdResult is a torrent found by the search API of BHD
+type bhdResult struct {
+	Name      string  `json:"name"`
+	InfoHash  string  `json:"info_hash"`
+	Freeleech apiFlag `json:"freeleech"`
+}
+
 func (c *BHD) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
-	type request struct {
-		Hash   string `json:"info_hash"`
-		Action string `json:"action"`
+	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		c.log.Info("-----")
+		torrent.APIDividerPrinted = true
 	}
 
-	type result struct {
-		Name     string `json:"name"`
-		InfoHash string `json:"info_hash"`
+	c.log.Tracef("Querying BHD API for torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+
+	err, results := c.search(ctx, torrent.Hash)
+	if err != nil {
+		return err, false
 	}
 
-	type response struct {
-		StatusCode   int      `json:"status_code"`
-		Page         int      `json:"page"`
-		Results      []result `json:"results"`
-		TotalPages   int      `json:"total_pages"`
-		TotalResults int      `json:"total_results"`
-		Success      bool     `json:"success"`
+	return nil, len(results) < 1
+}
+
+// IsUnregisteredBulk searches the torrents with a comma separated list of info hashes. The torrents found are
+// registered, the missing ones are left to IsUnregistered as a search the API only partly supports is not told apart
+// from removed torrents
+func (c *BHD) IsUnregisteredBulk(ctx context.Context, torrents []*Torrent) (error, map[string]bool) {
+	c.log.Tracef("Querying BHD API for %d torrents", len(torrents))
+
+	requested := hashSet(torrents)
+	err, results := c.search(ctx, strings.Join(slices.Sorted(maps.Keys(requested)), ","))
+	if err != nil {
+		return err, nil
 	}
 
-	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
-		c.log.Info("-----")
-		torrent.APIDividerPrinted = true
+	found := make(map[string]bool, len(results))
+	for _, r := range results {
+		if hash := strings.ToLower(r.InfoHash); requested[hash] {
+			found[hash] = false
+		}
 	}
+	return nil, found
+}
 
-	c.log.Tracef("Querying BHD API for torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+func (c *BHD) IsFreeleech(ctx context.Context, torrent *Torrent) (error, bool) {
+	c.log.Tracef("Querying BHD API for freeleech status of torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
 
-	// Helper function to sanitize errors that might contain the API key
-	sanitizeError := func(err error) error {
-		if err == nil {
-			return nil
-		}
-		errorMsg := err.Error()
-		if c.cfg.Key != "" && strings.Contains(errorMsg, c.cfg.Key) {
-			// Replace the API key with a placeholder
-			sanitized := strings.ReplaceAll(errorMsg, c.cfg.Key, "[API_KEY_REDACTED]")
-			return fmt.Errorf("%s", sanitized)
-		}
-		return err
+	err, results := c.search(ctx, torrent.Hash)
+	if err != nil {
+		return err, false
+	}
+
+	return nil, len(results) > 0 && bool(results[0].Freeleech)
+}
+
+// search returns the torrents with the info hash, or with any of a comma separated list of info hashes
+func (c *BHD) search(ctx context.Context, hash string) (error, []bhdResult) {
+	type request struct {
+		Hash   string `json:"info_hash"`
+		Action string `json:"action"`
+	}
+
+	type response struct {
+		StatusCode   int         `json:"status_code"`
+		Page         int         `json:"page"`
+		Results      []bhdResult `json:"results"`
+		TotalPages   int         `json:"total_pages"`
+		TotalResults int         `json:"total_results"`
+		Success      bool        `json:"success"`
 	}
 
 	requestURL, err := url.JoinPath("https://beyond-hd.me/api/torrents", c.cfg.Key)
 	if err != nil {
-		return fmt.Errorf("creating request URL: %w", sanitizeError(err)), false
+		return fmt.Errorf("creating request URL: %w", c.sanitizeError(err)), nil
 	}
 
 	payload := &request{
-		Hash:   torrent.Hash,
+		Hash:   hash,
 		Action: "search",
 	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshalling request: %w", sanitizeError(err)), false
+		return fmt.Errorf("marshalling request: %w", c.sanitizeError(err)), nil
 	}
 
 	var resp *response
 	err = httputils.MakeAPIRequest(ctx, c.http, http.MethodPost, requestURL, bytes.NewReader(body), c.headers, &resp)
 	if err != nil {
-		return fmt.Errorf("making api request: %w", sanitizeError(err)), false
+		return fmt.Errorf("making api request: %w", c.sanitizeError(err)), nil
 	}
 
 	// verify API response structure
 	if !resp.Success || resp.StatusCode == 0 || resp.Page == 0 {
-		return fmt.Errorf("API error"), false
+		return fmt.Errorf("API error"), nil
 	}
 
-	return nil, resp.TotalResults < 1
+	if resp.TotalResults < 1 {
+		return nil, nil
+	}
+	return nil, resp.Results
+}
+
+// sanitizeError removes the API key from errors that might contain it
+func (c *BHD) sanitizeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	errorMsg := err.Error()
+	if c.cfg.Key != "" && strings.Contains(errorMsg, c.cfg.Key) {
+		// Replace the API key with a placeholder
+		sanitized := strings.ReplaceAll(errorMsg, c.cfg.Key, "[API_KEY_REDACTED]")
+		return fmt.Errorf("%s", sanitized)
+	}
+	return err
 }
 
 func (c *BHD) IsTrackerDown(_ *Torrent) (error, bool) {
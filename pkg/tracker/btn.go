@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -21,6 +20,9 @@ var torrentIDRegex = regexp.MustCompile(`https?://[^/]*broadcasthe\.net/torrents
 
 type BTNConfig struct {
 	Key string `koanf:"api_key"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
 }
 
 type BTN struct {
@@ -34,7 +36,7 @@ func NewBTN(c BTNConfig) *BTN {
 	l := logger.GetLogger("btn-api")
 	return &BTN{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack)),
+		http: c.httpClient(15*time.Second, l),
 		headers: map[string]string{
 			"Content-Type": "application/json",
 			"Accept":       "application/json",
@@ -48,7 +50,7 @@ func (c *BTN) Name() string {
 }
 
 func (c *BTN) Check(host string) bool {
-	return strings.EqualFold(host, "landof.tv")
+	return matchesDomain(host, "landof.tv")
 }
 
 // extractTorrentID extracts the torrent ID from the torrent comment field
@@ -0,0 +1,86 @@
+package tracker
+
+import (
+	"context"
+	"strings"
+)
+
+// bulkSize is the number of torrents looked up per request by the trackers supporting bulk lookups
+const bulkSize = 100
+
+// bulkChecker is implemented by the trackers whose API can look up many torrents per request
+type bulkChecker interface {
+	// IsUnregisteredBulk returns the results of the torrents the API could decide, by lowercase info hash, the
+	// other torrents are left to IsUnregistered
+	IsUnregisteredBulk(ctx context.Context, torrents []*Torrent) (error, map[string]bool)
+}
+
+// SupportsBulk reports whether tr can look up many torrents per request
+func SupportsBulk(tr Interface) bool {
+	if c, ok := tr.(*cached); ok {
+		tr = c.Interface
+	}
+
+	_, ok := tr.(bulkChecker)
+	return ok
+}
+
+// IsUnregisteredBulk looks up the torrents of tr in batches of bulkSize, the results are keyed by lowercase info hash
+// and only hold the torrents the API could decide. The results found before a failed batch are returned with its error
+func IsUnregisteredBulk(ctx context.Context, tr Interface, torrents []*Torrent) (err error, results map[string]bool,
+	requests int) {
+	results = make(map[string]bool, len(torrents))
+
+	var rc *cached
+	if c, ok := tr.(*cached); ok {
+		rc = c
+		tr = c.Interface
+	}
+
+	bc, ok := tr.(bulkChecker)
+	if !ok {
+		return nil, results, 0
+	}
+
+	pending := make([]*Torrent, 0, len(torrents))
+	for _, t := range torrents {
+		if t.Hash == "" {
+			continue
+		}
+		if rc != nil {
+			if unregistered, ok := rc.cache.get(rc.key, t.Hash, t.TrackerStatus, rc.ttl); ok {
+				results[strings.ToLower(t.Hash)] = unregistered
+				continue
+			}
+		}
+		pending = append(pending, t)
+	}
+
+	for start := 0; start < len(pending); start += bulkSize {
+		batch := pending[start:min(start+bulkSize, len(pending))]
+
+		requests++
+		err, found := bc.IsUnregisteredBulk(ctx, batch)
+		if err != nil {
+			return err, results, requests
+		}
+
+		for hash, unregistered := range found {
+			results[strings.ToLower(hash)] = unregistered
+		}
+		if rc != nil {
+			rc.cache.setBulk(rc.key, batch, results, rc.ttl)
+		}
+	}
+
+	return nil, results, requests
+}
+
+// hashSet returns the lowercase info hashes of torrents
+func hashSet(torrents []*Torrent) map[string]bool {
+	hashes := make(map[string]bool, len(torrents))
+	for _, t := range torrents {
+		hashes[strings.ToLower(t.Hash)] = true
+	}
+	return hashes
+}
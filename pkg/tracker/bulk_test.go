@@ -0,0 +1,91 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+type bulkTracker struct {
+	countingTracker
+	batches []int
+}
+
+// IsUnregisteredBulk reports the torrents with an even hash as registered, and leaves the others undecided
+func (b *bulkTracker) IsUnregisteredBulk(_ context.Context, torrents []*Torrent) (error, map[string]bool) {
+	b.batches = append(b.batches, len(torrents))
+
+	found := make(map[string]bool)
+	for _, t := range torrents {
+		var n int
+		_, _ = fmt.Sscanf(t.Hash, "H%d", &n)
+		if n%2 == 0 {
+			found[strings.ToLower(t.Hash)] = false
+		}
+	}
+	return nil, found
+}
+
+func TestIsUnregisteredBulk(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, SupportsBulk(&countingTracker{}))
+
+	torrents := make([]*Torrent, 250)
+	for i := range torrents {
+		torrents[i] = &Torrent{Hash: fmt.Sprintf("H%d", i), TrackerStatus: "Working"}
+	}
+
+	inner := &bulkTracker{}
+	tr := newCached(inner, "bulk", newResultCache(filepath.Join(t.TempDir(), CacheFile)), time.Hour)
+	assert.True(t, SupportsBulk(tr))
+
+	err, results, requests := IsUnregisteredBulk(ctx, tr, torrents)
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []int{100, 100, 50}, inner.batches)
+	assert.Len(t, results, 125)
+	assert.Contains(t, results, "h2")
+	assert.NotContains(t, results, "h1", "undecided torrents have no result")
+
+	// the decided torrents are cached, only the undecided ones are looked up again
+	err, results, requests = IsUnregisteredBulk(ctx, tr, torrents)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []int{100, 100, 50, 100, 25}, inner.batches)
+	assert.Len(t, results, 125)
+}
+
+func TestUNIT3D_IsUnregisteredBulk(t *testing.T) {
+	var infoHash string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/torrents/filter", r.URL.Path)
+		infoHash = r.URL.Query().Get("infoHash")
+		_, _ = w.Write([]byte(`{"data": [
+			{"attributes": {"info_hash": "AAAA"}},
+			{"attributes": {"info_hash": "ffff"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	c := &UNIT3D{
+		cfg:  UNIT3DConfig{Domain: strings.TrimPrefix(server.URL, "https://")},
+		http: server.Client(),
+		log:  logger.GetLogger("unit3d-api"),
+	}
+
+	err, found := c.IsUnregisteredBulk(context.Background(), []*Torrent{{Hash: "bbbb"}, {Hash: "aaaa"}})
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa,bbbb", infoHash)
+	assert.Equal(t, map[string]bool{"aaaa": false}, found, "missing and unrequested torrents have no result")
+}
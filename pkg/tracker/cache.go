@@ -0,0 +1,198 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/logger"
+	"github.com/autobrr/tqm/pkg/paths"
+)
+
+// CacheFile is the state file of the cached tracker API results, kept next to the config file
+const CacheFile = "tracker-cache.json"
+
+// CacheConfig caches the API results of a tracker across runs
+type CacheConfig struct {
+	// CacheTTL is how long the API results are cached, e.g. 24h (0 to not cache them)
+	CacheTTL time.Duration `koanf:"cache_ttl"`
+}
+
+// cacheEntry is the API result for a torrent, valid as long as the status reported by the tracker is unchanged
+type cacheEntry struct {
+	Time          time.Time `json:"time"`
+	TrackerStatus string    `json:"tracker_status,omitempty"`
+	Unregistered  bool      `json:"unregistered"`
+}
+
+// resultCache persists the API results of the trackers with a cache TTL, per tracker and info hash
+type resultCache struct {
+	mu      sync.Mutex
+	path    string
+	log     *logrus.Entry
+	loaded  bool
+	dirty   bool
+	entries map[string]map[string]cacheEntry
+}
+
+func newResultCache(path string) *resultCache {
+	return &resultCache{
+		path:    path,
+		log:     logger.GetLogger("tracker-cache"),
+		entries: make(map[string]map[string]cacheEntry),
+	}
+}
+
+// get returns the result of the torrent with hash cached for tracker, unless it is older than ttl or the status
+// reported by the tracker changed since
+func (rc *resultCache) get(tracker string, hash string, status string, ttl time.Duration) (bool, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.load()
+
+	e, ok := rc.entries[tracker][strings.ToLower(hash)]
+	if !ok || time.Since(e.Time) >= ttl || e.TrackerStatus != status {
+		return false, false
+	}
+	return e.Unregistered, true
+}
+
+// set caches the result of the torrent with hash for tracker, the entries older than ttl are dropped. The cache is
+// persisted by flush
+func (rc *resultCache) set(tracker string, hash string, status string, unregistered bool, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entries := rc.expire(tracker, ttl)
+	entries[strings.ToLower(hash)] = cacheEntry{Time: time.Now(), TrackerStatus: status, Unregistered: unregistered}
+	rc.dirty = true
+}
+
+// setBulk caches the results of a bulk lookup of torrents for tracker, the torrents without a result are not cached
+func (rc *resultCache) setBulk(tracker string, torrents []*Torrent, results map[string]bool, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entries := rc.expire(tracker, ttl)
+	for _, t := range torrents {
+		hash := strings.ToLower(t.Hash)
+		if unregistered, ok := results[hash]; ok {
+			entries[hash] = cacheEntry{Time: time.Now(), TrackerStatus: t.TrackerStatus, Unregistered: unregistered}
+			rc.dirty = true
+		}
+	}
+}
+
+// flush persists the cache when results were cached since it was last persisted
+func (rc *resultCache) flush() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if !rc.dirty {
+		return
+	}
+
+	if err := rc.save(); err != nil {
+		rc.log.WithError(err).Warn("Failed saving tracker API cache")
+		return
+	}
+	rc.dirty = false
+}
+
+// expire drops the entries of tracker older than ttl and returns its remaining entries
+func (rc *resultCache) expire(tracker string, ttl time.Duration) map[string]cacheEntry {
+	rc.load()
+
+	entries, ok := rc.entries[tracker]
+	if !ok {
+		entries = make(map[string]cacheEntry)
+		rc.entries[tracker] = entries
+	}
+
+	for h, e := range entries {
+		if time.Since(e.Time) >= ttl {
+			delete(entries, h)
+		}
+	}
+	return entries
+}
+
+// load reads the cache file once, a missing or invalid file starts an empty cache
+func (rc *resultCache) load() {
+	if rc.loaded {
+		return
+	}
+	rc.loaded = true
+
+	data, err := os.ReadFile(rc.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
+		rc.log.WithError(err).Warn("Failed reading tracker API cache")
+		return
+	}
+
+	if err := json.Unmarshal(data, &rc.entries); err != nil {
+		rc.log.WithError(err).Warn("Failed decoding tracker API cache, starting with an empty cache")
+		rc.entries = make(map[string]map[string]cacheEntry)
+	}
+}
+
+func (rc *resultCache) save() error {
+	data, err := json.Marshal(rc.entries)
+	if err != nil {
+		return fmt.Errorf("encode tracker api cache: %w", err)
+	}
+
+	if err := paths.WriteFileAtomic(rc.path, data); err != nil {
+		return fmt.Errorf("write tracker api cache: %w", err)
+	}
+	return nil
+}
+
+// cached caches the results of the IsUnregistered checks of a tracker for its cache TTL, so repeated runs only query
+// the API for new torrents and the ones whose tracker status changed
+type cached struct {
+	Interface
+	// key identifies the tracker in the cache, e.g. bhd or unit3d/aither
+	key   string
+	cache *resultCache
+	ttl   time.Duration
+}
+
+func newCached(tr Interface, key string, cache *resultCache, ttl time.Duration) Interface {
+	if ttl <= 0 || cache == nil {
+		return tr
+	}
+	return &cached{Interface: tr, key: key, cache: cache, ttl: ttl}
+}
+
+func (c *cached) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	if torrent.Hash == "" {
+		return c.Interface.IsUnregistered(ctx, torrent)
+	}
+
+	if unregistered, ok := c.cache.get(c.key, torrent.Hash, torrent.TrackerStatus, c.ttl); ok {
+		return nil, unregistered
+	}
+
+	err, unregistered := c.Interface.IsUnregistered(ctx, torrent)
+	if err != nil {
+		return err, unregistered
+	}
+
+	c.cache.set(c.key, torrent.Hash, torrent.TrackerStatus, unregistered, c.ttl)
+	return nil, unregistered
+}
+
+func (c *cached) StatusAmbiguous() bool {
+	return StatusAmbiguous(c.Interface)
+}
@@ -0,0 +1,86 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTracker struct {
+	calls        int
+	unregistered bool
+	err          error
+}
+
+func (c *countingTracker) Name() string           { return "counting" }
+func (c *countingTracker) Check(host string) bool { return host == "counting.example" }
+func (c *countingTracker) StatusAmbiguous() bool  { return true }
+
+func (c *countingTracker) IsUnregistered(_ context.Context, _ *Torrent) (error, bool) {
+	c.calls++
+	return c.err, c.unregistered
+}
+
+func (c *countingTracker) IsTrackerDown(_ *Torrent) (error, bool) {
+	return nil, false
+}
+
+func TestCached_IsUnregistered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CacheFile)
+	ctx := context.Background()
+
+	inner := &countingTracker{unregistered: true}
+	cache := newResultCache(path)
+	tr := newCached(inner, "counting", cache, time.Hour)
+	assert.True(t, StatusAmbiguous(tr))
+
+	torrent := &Torrent{Hash: "ABCDEF", TrackerStatus: "Unregistered torrent"}
+
+	err, unregistered := tr.IsUnregistered(ctx, torrent)
+	require.NoError(t, err)
+	assert.True(t, unregistered)
+
+	err, unregistered = tr.IsUnregistered(ctx, torrent)
+	require.NoError(t, err)
+	assert.True(t, unregistered)
+	assert.Equal(t, 1, inner.calls, "cached result not used")
+
+	// the results are persisted once the run is done
+	assert.NoFileExists(t, path)
+	cache.flush()
+	assert.FileExists(t, path)
+
+	// the results are persisted across runs
+	tr = newCached(inner, "counting", newResultCache(path), time.Hour)
+	_, _ = tr.IsUnregistered(ctx, &Torrent{Hash: "abcdef", TrackerStatus: "Unregistered torrent"})
+	assert.Equal(t, 1, inner.calls, "persisted result not used")
+
+	// a changed tracker status queries the API again
+	inner.unregistered = false
+	err, unregistered = tr.IsUnregistered(ctx, &Torrent{Hash: "abcdef", TrackerStatus: "Working"})
+	require.NoError(t, err)
+	assert.False(t, unregistered)
+	assert.Equal(t, 2, inner.calls)
+
+	// failures are not cached
+	inner.err = errors.New("api down")
+	err, _ = tr.IsUnregistered(ctx, &Torrent{Hash: "012345"})
+	assert.Error(t, err)
+	inner.err = nil
+	err, _ = tr.IsUnregistered(ctx, &Torrent{Hash: "012345"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, inner.calls)
+
+	// expired results query the API again
+	tr = newCached(inner, "counting", newResultCache(path), time.Nanosecond)
+	_, _ = tr.IsUnregistered(ctx, &Torrent{Hash: "abcdef", TrackerStatus: "Working"})
+	assert.Equal(t, 5, inner.calls)
+
+	// trackers without a cache TTL are not wrapped
+	assert.Same(t, inner, newCached(inner, "counting", newResultCache(path), 0))
+}
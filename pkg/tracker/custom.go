@@ -0,0 +1,206 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+const defaultCustomTimeout = 15 * time.Second
+
+type CustomConfig struct {
+	// Domains are the tracker domains checked by the custom tracker, subdomains included
+	Domains []string `koanf:"domains"`
+	// Command is run with the torrent as JSON on stdin and prints the response as JSON on stdout
+	Command string   `koanf:"command"`
+	Args    []string `koanf:"args"`
+	// URL is sent the torrent as JSON in a POST request and answers with the response as JSON
+	URL     string            `koanf:"url"`
+	Headers map[string]string `koanf:"headers"`
+	// Timeout of the command or request (15s when not set)
+	Timeout time.Duration `koanf:"timeout"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
+}
+
+func (c CustomConfig) validate() error {
+	switch {
+	case len(c.Domains) == 0:
+		return errors.New("no domains configured")
+	case c.Command == "" && c.URL == "":
+		return errors.New("no command or url configured")
+	case c.Command != "" && c.URL != "":
+		return errors.New("command and url are mutually exclusive")
+	case c.Timeout < 0:
+		return fmt.Errorf("invalid timeout: %s (must not be negative)", c.Timeout)
+	}
+	return nil
+}
+
+// customRequest is the torrent checked by a custom tracker
+type customRequest struct {
+	Hash          string `json:"hash"`
+	Name          string `json:"name"`
+	Comment       string `json:"comment"`
+	Tracker       string `json:"tracker"`
+	TrackerStatus string `json:"tracker_status"`
+}
+
+// customResponse is the answer of a custom tracker, the torrent is unregistered when registered is false
+type customResponse struct {
+	Registered *bool  `json:"registered"`
+	Reason     string `json:"reason"`
+	// SeedTimeRequired is the minimum seed time of the torrent in hours, e.g. of its hit and run (0 when none)
+	SeedTimeRequired float64 `json:"seed_time_required"`
+}
+
+// Custom checks the torrents of niche trackers with a user-provided command or HTTP endpoint
+type Custom struct {
+	name    string
+	cfg     CustomConfig
+	http    *http.Client
+	timeout time.Duration
+	log     *logrus.Entry
+}
+
+func NewCustom(name string, c CustomConfig) *Custom {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultCustomTimeout
+	}
+
+	l := logger.GetLogger(fmt.Sprintf("%s-api", strings.ToLower(name)))
+
+	return &Custom{
+		name:    name,
+		cfg:     c,
+		http:    c.httpClient(timeout, l),
+		timeout: timeout,
+		log:     l,
+	}
+}
+
+func (c *Custom) Name() string {
+	return c.name
+}
+
+func (c *Custom) Check(host string) bool {
+	for _, domain := range c.cfg.Domains {
+		if matchesDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Custom) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		c.log.Info("-----")
+		torrent.APIDividerPrinted = true
+	}
+
+	c.log.Tracef("Querying %s for torrent: %s (hash: %s)", c.name, torrent.Name, torrent.Hash)
+
+	resp, err := c.query(ctx, torrent)
+	if err != nil {
+		return err, false
+	}
+
+	if resp.Registered == nil {
+		return errors.New("response without registered"), false
+	}
+
+	if !*resp.Registered && resp.Reason != "" {
+		c.log.Debugf("%s reported %s as unregistered: %s", c.name, torrent.Name, resp.Reason)
+	}
+	return nil, !*resp.Registered
+}
+
+func (c *Custom) SeedTimeRequired(ctx context.Context, torrent *Torrent) (error, time.Duration) {
+	c.log.Tracef("Querying %s for seed time of torrent: %s (hash: %s)", c.name, torrent.Name, torrent.Hash)
+
+	resp, err := c.query(ctx, torrent)
+	if err != nil {
+		return err, 0
+	}
+
+	if resp.SeedTimeRequired < 0 {
+		return fmt.Errorf("invalid seed_time_required: %v (must not be negative)", resp.SeedTimeRequired), 0
+	}
+	return nil, time.Duration(resp.SeedTimeRequired * float64(time.Hour))
+}
+
+// query sends the torrent to the command or url and returns its response
+func (c *Custom) query(ctx context.Context, torrent *Torrent) (*customResponse, error) {
+	input, err := json.Marshal(customRequest{
+		Hash:          torrent.Hash,
+		Name:          torrent.Name,
+		Comment:       torrent.Comment,
+		Tracker:       torrent.TrackerName,
+		TrackerStatus: torrent.TrackerStatus,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	var resp customResponse
+	if c.cfg.Command != "" {
+		err = c.runCommand(ctx, input, &resp)
+	} else {
+		headers := map[string]string{
+			"Content-Type": "application/json",
+			"Accept":       "application/json",
+		}
+		for k, v := range c.cfg.Headers {
+			headers[k] = v
+		}
+		err = httputils.MakeAPIRequest(ctx, c.http, http.MethodPost, c.cfg.URL, bytes.NewReader(input), headers, &resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// runCommand runs the command with input on stdin and decodes its output into resp
+func (c *Custom) runCommand(ctx context.Context, input []byte, resp *customResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.cfg.Command, c.cfg.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("command timed out after %s", c.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("running command: %w: %s", err, msg)
+		}
+		return fmt.Errorf("running command: %w", err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return fmt.Errorf("decoding command output: %w", err)
+	}
+	return nil
+}
+
+func (c *Custom) IsTrackerDown(_ *Torrent) (error, bool) {
+	return nil, false
+}
@@ -0,0 +1,136 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustom_Check(t *testing.T) {
+	c := NewCustom("niche", CustomConfig{Domains: []string{"niche.example", "niche-mirror.example"}, URL: "http://localhost"})
+
+	assert.Equal(t, "niche", c.Name())
+	assert.True(t, c.Check("tracker.niche.example"))
+	assert.True(t, c.Check("niche-mirror.example"))
+	assert.False(t, c.Check("notniche.example"))
+}
+
+func TestCustom_IsUnregisteredURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req customRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch req.Hash {
+		case "registered":
+			_, _ = w.Write([]byte(`{"registered": true}`))
+		case "unregistered":
+			_, _ = w.Write([]byte(`{"registered": false, "reason": "nuked"}`))
+		default:
+			_, _ = w.Write([]byte(`{"reason": "unknown"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		hash        string
+		apiKey      string
+		wantUnreg   bool
+		expectError bool
+	}{
+		{name: "registered", hash: "registered", apiKey: "secret"},
+		{name: "unregistered", hash: "unregistered", apiKey: "secret", wantUnreg: true},
+		{name: "missing registered", hash: "other", apiKey: "secret", expectError: true},
+		{name: "unauthorized", hash: "registered", apiKey: "wrong", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCustom("niche", CustomConfig{
+				Domains: []string{"niche.example"},
+				URL:     server.URL,
+				Headers: map[string]string{"X-Api-Key": tt.apiKey},
+			})
+
+			err, unreg := c.IsUnregistered(context.Background(), &Torrent{Hash: tt.hash, Name: "Some.Torrent"})
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, unreg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUnreg, unreg)
+		})
+	}
+}
+
+func TestCustom_IsUnregisteredCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+
+	script := filepath.Join(t.TempDir(), "check.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+if grep -q '"hash":"unregistered"'; then
+	echo '{"registered": false, "reason": "removed"}'
+else
+	echo '{"registered": true}'
+fi
+`), 0700))
+
+	c := NewCustom("niche", CustomConfig{Domains: []string{"niche.example"}, Command: script})
+
+	err, unreg := c.IsUnregistered(context.Background(), &Torrent{Hash: "unregistered"})
+	require.NoError(t, err)
+	assert.True(t, unreg)
+
+	err, unreg = c.IsUnregistered(context.Background(), &Torrent{Hash: "registered"})
+	require.NoError(t, err)
+	assert.False(t, unreg)
+
+	c = NewCustom("niche", CustomConfig{Domains: []string{"niche.example"}, Command: "false"})
+	err, _ = c.IsUnregistered(context.Background(), &Torrent{Hash: "registered"})
+	assert.Error(t, err)
+}
+
+func TestCustomConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CustomConfig
+		wantErr string
+	}{
+		{name: "valid", cfg: CustomConfig{Domains: []string{"niche.example"}, Command: "check"}},
+		{name: "no domains", cfg: CustomConfig{Command: "check"}, wantErr: "no domains"},
+		{name: "no checker", cfg: CustomConfig{Domains: []string{"niche.example"}}, wantErr: "no command or url"},
+		{name: "both checkers", cfg: CustomConfig{Domains: []string{"niche.example"}, Command: "check", URL: "http://localhost"},
+			wantErr: "mutually exclusive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+type FLConfig struct {
+	Username string `koanf:"username"`
+	Passkey  string `koanf:"passkey"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
+}
+
+type FL struct {
+	cfg     FLConfig
+	http    *http.Client
+	headers map[string]string
+	log     *logrus.Entry
+}
+
+func NewFL(c FLConfig) *FL {
+	l := logger.GetLogger("fl-api")
+	return &FL{
+		cfg:  c,
+		http: c.httpClient(15*time.Second, l),
+		headers: map[string]string{
+			"Accept": "application/json",
+		},
+		log: l,
+	}
+}
+
+func (c *FL) Name() string {
+	return "FL"
+}
+
+func (c *FL) Check(host string) bool {
+	return matchesDomain(host, "filelist.io") || matchesDomain(host, "flro.org")
+}
+
+func (c *FL) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	type result struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		InfoHash string `json:"info_hash"`
+	}
+
+	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		c.log.Info("-----")
+		torrent.APIDividerPrinted = true
+	}
+
+	c.log.Tracef("Querying FL API for torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+
+	requestURL, err := httputils.URLWithQuery("https://filelist.io/api.php", url.Values{
+		"username": []string{c.cfg.Username},
+		"passkey":  []string{c.cfg.Passkey},
+		"action":   []string{"search-torrents"},
+		"type":     []string{"hash"},
+		"query":    []string{strings.ToLower(torrent.Hash)},
+	})
+	if err != nil {
+		return fmt.Errorf("creating request URL: %w", err), false
+	}
+
+	// errors (e.g. invalid credentials) are answered with an object instead of a list and fail decoding
+	var resp []result
+	err = httputils.MakeAPIRequest(ctx, c.http, http.MethodGet, requestURL, nil, c.headers, &resp)
+	if err != nil {
+		return fmt.Errorf("making api request: %w", err), false
+	}
+
+	// the torrent is unregistered when the search by its hash returns nothing
+	return nil, len(resp) == 0
+}
+
+func (c *FL) IsTrackerDown(_ *Torrent) (error, bool) {
+	return nil, false
+}
@@ -0,0 +1,76 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFL_IsUnregistered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("username") != "user" || q.Get("passkey") != "passkey" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error": "Invalid passkey"}`))
+			return
+		}
+
+		assert.Equal(t, "search-torrents", q.Get("action"))
+		assert.Equal(t, "hash", q.Get("type"))
+
+		switch q.Get("query") {
+		case "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa":
+			_, _ = w.Write([]byte(`[{"id": 1, "name": "Some.Movie", "info_hash": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		passkey     string
+		hash        string
+		wantUnreg   bool
+		expectError bool
+	}{
+		{
+			name:    "registered",
+			passkey: "passkey",
+			hash:    "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+		},
+		{
+			name:      "unregistered",
+			passkey:   "passkey",
+			hash:      "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			wantUnreg: true,
+		},
+		{
+			name:        "invalid passkey",
+			passkey:     "wrong",
+			hash:        "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fl := NewFL(FLConfig{Username: "user", Passkey: tt.passkey})
+			fl.http = &http.Client{Transport: &redirectTransport{server: server}}
+
+			err, unreg := fl.IsUnregistered(context.Background(), &Torrent{Hash: tt.hash, Name: "Some.Movie"})
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, unreg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUnreg, unreg)
+		})
+	}
+}
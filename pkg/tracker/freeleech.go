@@ -0,0 +1,90 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+)
+
+// freeleechChecker is implemented by the trackers whose API reports the freeleech status of torrents
+type freeleechChecker interface {
+	IsFreeleech(ctx context.Context, torrent *Torrent) (error, bool)
+}
+
+// IsFreeleech checks whether tr reports the torrent as freeleech (or neutral leech), supported is false when the API
+// of tr does not report the freeleech status. Freeleech is often temporary, so it is never cached across runs
+func IsFreeleech(ctx context.Context, tr Interface, torrent *Torrent) (err error, freeleech bool, supported bool) {
+	if c, ok := tr.(*cached); ok {
+		tr = c.Interface
+	}
+
+	fc, ok := tr.(freeleechChecker)
+	if !ok {
+		return nil, false, false
+	}
+
+	err, freeleech = fc.IsFreeleech(ctx, torrent)
+	return err, freeleech, true
+}
+
+// apiFlag decodes the flags reported by tracker APIs as booleans, numbers or numeric strings (e.g. the freeTorrent
+// field of Gazelle, 0 = normal, 1 = freeleech, 2 = neutral leech), any non-zero value is set
+type apiFlag bool
+
+func (f *apiFlag) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(bytes.TrimSpace(data)), `"`)
+	switch strings.ToLower(s) {
+	case "", "null", "false":
+		*f = false
+		return nil
+	case "true":
+		*f = true
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid flag: %s", data)
+	}
+	*f = n != 0
+	return nil
+}
+
+// isGazelleFreeleech checks the freeTorrent field of the torrent with the ajax API of a Gazelle tracker at apiURL
+func isGazelleFreeleech(ctx context.Context, client *http.Client, apiURL string, headers map[string]string,
+	torrent *Torrent) (error, bool) {
+	type response struct {
+		Status   string `json:"status"`
+		Error    string `json:"error"`
+		Response struct {
+			Torrent struct {
+				FreeTorrent apiFlag `json:"freeTorrent"`
+			} `json:"torrent"`
+		} `json:"response"`
+	}
+
+	requestURL, err := httputils.URLWithQuery(apiURL, url.Values{
+		"action": []string{"torrent"},
+		"hash":   []string{torrent.Hash},
+	})
+	if err != nil {
+		return fmt.Errorf("creating request URL: %w", err), false
+	}
+
+	var resp *response
+	err = httputils.MakeAPIRequest(ctx, client, http.MethodGet, requestURL, nil, headers, &resp)
+	if err != nil {
+		return fmt.Errorf("making api request: %w", err), false
+	}
+
+	if resp.Status != "success" {
+		return fmt.Errorf("api error: %s", resp.Error), false
+	}
+	return nil, bool(resp.Response.Torrent.FreeTorrent)
+}
@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type freeleechTracker struct {
+	countingTracker
+	freeleech bool
+}
+
+func (f *freeleechTracker) IsFreeleech(_ context.Context, _ *Torrent) (error, bool) {
+	return nil, f.freeleech
+}
+
+func TestIsFreeleech(t *testing.T) {
+	ctx := context.Background()
+	cache := newResultCache(filepath.Join(t.TempDir(), CacheFile))
+
+	err, freeleech, supported := IsFreeleech(ctx, &countingTracker{}, &Torrent{})
+	require.NoError(t, err)
+	assert.False(t, supported)
+	assert.False(t, freeleech)
+
+	// the cache wrapper does not hide the freeleech status
+	tr := newCached(&freeleechTracker{freeleech: true}, "freeleech", cache, time.Hour)
+	err, freeleech, supported = IsFreeleech(ctx, tr, &Torrent{Hash: "abc"})
+	require.NoError(t, err)
+	assert.True(t, supported)
+	assert.True(t, freeleech)
+}
+
+func TestAPIFlag_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data string
+		want apiFlag
+	}{
+		{data: `true`, want: true},
+		{data: `false`},
+		{data: `null`},
+		{data: `0`},
+		{data: `1`, want: true},
+		{data: `"0"`},
+		{data: `"1"`, want: true},
+		{data: `"2"`, want: true},
+		{data: `""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			var f apiFlag
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &f))
+			assert.Equal(t, tt.want, f)
+		})
+	}
+
+	var f apiFlag
+	assert.Error(t, json.Unmarshal([]byte(`"yes"`), &f))
+}
+
+func TestIsGazelleFreeleech(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("hash") {
+		case "free":
+			_, _ = w.Write([]byte(`{"status": "success", "response": {"torrent": {"freeTorrent": true}}}`))
+		case "neutral":
+			_, _ = w.Write([]byte(`{"status": "success", "response": {"torrent": {"freeTorrent": "2"}}}`))
+		case "normal":
+			_, _ = w.Write([]byte(`{"status": "success", "response": {"torrent": {"freeTorrent": "0"}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "failure", "error": "bad hash parameter"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		hash        string
+		want        bool
+		expectError bool
+	}{
+		{hash: "free", want: true},
+		{hash: "neutral", want: true},
+		{hash: "normal"},
+		{hash: "missing", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hash, func(t *testing.T) {
+			err, freeleech := isGazelleFreeleech(context.Background(), server.Client(), server.URL, nil,
+				&Torrent{Hash: tt.hash})
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, freeleech)
+		})
+	}
+}
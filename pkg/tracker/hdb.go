@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -19,6 +18,9 @@ import (
 type HDBConfig struct {
 	Username string `koanf:"username"`
 	Passkey  string `koanf:"passkey"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
 }
 
 type HDB struct {
@@ -32,7 +34,7 @@ func NewHDB(c HDBConfig) *HDB {
 	l := logger.GetLogger("hdb-api")
 	return &HDB{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack)),
+		http: c.httpClient(15*time.Second, l),
 		headers: map[string]string{
 			"Content-Type": "application/json",
 			"Accept":       "application/json",
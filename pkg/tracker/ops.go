@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -17,6 +16,9 @@ import (
 
 type OPSConfig struct {
 	Key string `koanf:"api_key"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
 }
 
 type OPS struct {
@@ -30,7 +32,7 @@ func NewOPS(c OPSConfig) *OPS {
 	l := logger.GetLogger("ops-api")
 	return &OPS{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack)),
+		http: c.httpClient(15*time.Second, l),
 		headers: map[string]string{
 			"Accept":        "application/json",
 			"Authorization": "token " + c.Key,
@@ -78,6 +80,12 @@ func (c *OPS) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool
 	return nil, resp.Status == "failure" && resp.Error == "bad parameters"
 }
 
+func (c *OPS) IsFreeleech(ctx context.Context, torrent *Torrent) (error, bool) {
+	c.log.Tracef("Querying OPS API for freeleech status of torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+
+	return isGazelleFreeleech(ctx, c.http, "https://orpheus.network/ajax.php", c.headers, torrent)
+}
+
 func (c *OPS) IsTrackerDown(_ *Torrent) (error, bool) {
 	return nil, false
 }
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -26,6 +25,9 @@ const (
 type PTPConfig struct {
 	User string `koanf:"api_user"`
 	Key  string `koanf:"api_key"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
 }
 
 type PTP struct {
@@ -43,7 +45,7 @@ func NewPTP(c PTPConfig) *PTP {
 	l := logger.GetLogger("ptp-api")
 	return &PTP{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack)),
+		http: c.httpClient(15*time.Second, l),
 		headers: map[string]string{
 			"Accept":  "application/json",
 			"ApiUser": c.User,
@@ -0,0 +1,92 @@
+package tracker
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/ratelimit"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+)
+
+// defaultMaxFailures is the number of consecutive failed requests after which an API is no longer called
+const defaultMaxFailures = 5
+
+var (
+	// breakers stop the requests to the APIs failing repeatedly
+	breakers   []*httputils.Breaker
+	breakersMu sync.Mutex
+)
+
+// RateLimitConfig limits the requests to the API of a tracker, zero values keep the default of a request per second
+// without burst
+type RateLimitConfig struct {
+	// RequestsPerSecond is the rate of the requests, e.g. 0.5 for a request every 2 seconds
+	RequestsPerSecond float64 `koanf:"requests_per_second"`
+	// Burst is the number of requests sent at once after the API was idle for a while
+	Burst int `koanf:"burst"`
+	// MaxFailures is the number of consecutive requests failing without a response (e.g. timeouts or server errors)
+	// after which the API is no longer called for the rest of the run (default: 5, -1 to keep calling it)
+	MaxFailures int `koanf:"max_failures"`
+}
+
+func (c RateLimitConfig) validate() error {
+	if c.RequestsPerSecond < 0 {
+		return fmt.Errorf("invalid requests_per_second: %v (must not be negative)", c.RequestsPerSecond)
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("invalid burst: %d (must not be negative)", c.Burst)
+	}
+	if c.MaxFailures < -1 {
+		return fmt.Errorf("invalid max_failures: %d (must be -1 or more)", c.MaxFailures)
+	}
+	return nil
+}
+
+// limiter returns the limiter of the requests to the API
+func (c RateLimitConfig) limiter() ratelimit.Limiter {
+	rps := c.RequestsPerSecond
+	if rps == 0 {
+		rps = 1
+	}
+
+	slack := ratelimit.WithoutSlack
+	if c.Burst > 0 {
+		slack = ratelimit.WithSlack(c.Burst)
+	}
+
+	return ratelimit.New(1, ratelimit.Per(time.Duration(float64(time.Second)/rps)), slack)
+}
+
+// httpClient returns the client of the requests to the API, which stops sending them after MaxFailures consecutive
+// failures until ResetBreakers is called
+func (c RateLimitConfig) httpClient(timeout time.Duration, log *logrus.Entry) *http.Client {
+	maxFailures := c.MaxFailures
+	if maxFailures == 0 {
+		maxFailures = defaultMaxFailures
+	}
+
+	b := httputils.NewBreaker(maxFailures, func(err error) {
+		log.WithError(err).Warnf("%d consecutive API requests failed, not calling the API for the rest of the run "+
+			"(the affected torrents are treated as registered)", maxFailures)
+	})
+
+	breakersMu.Lock()
+	breakers = append(breakers, b)
+	breakersMu.Unlock()
+
+	return b.Wrap(httputils.NewRetryableHttpClient(timeout, c.limiter()))
+}
+
+// ResetBreakers calls the APIs no longer called after repeated failures again, e.g. for the next webhook of serve
+func ResetBreakers() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	for _, b := range breakers {
+		b.Reset()
+	}
+}
@@ -0,0 +1,49 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitConfig_Limiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RateLimitConfig
+		takes   int
+		minTime time.Duration
+	}{
+		{name: "requests per second", cfg: RateLimitConfig{RequestsPerSecond: 50}, takes: 4, minTime: 60 * time.Millisecond},
+		{name: "fractional rate", cfg: RateLimitConfig{RequestsPerSecond: 12.5}, takes: 2, minTime: 80 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := tt.cfg.limiter()
+			rl.Take()
+
+			start := time.Now()
+			for range tt.takes {
+				rl.Take()
+			}
+			assert.GreaterOrEqual(t, time.Since(start), tt.minTime)
+		})
+	}
+}
+
+func TestInit_InvalidRateLimit(t *testing.T) {
+	t.Cleanup(func() { trackers = nil })
+
+	err := Init(Config{BHD: BHDConfig{Key: "key", RateLimitConfig: RateLimitConfig{RequestsPerSecond: -1}}}, "")
+	assert.ErrorContains(t, err, "bhd: invalid requests_per_second")
+
+	err = Init(Config{UNIT3D: map[string]UNIT3DConfig{"aither": {RateLimitConfig: RateLimitConfig{Burst: -1}}}}, "")
+	assert.ErrorContains(t, err, "unit3d/aither: invalid burst")
+
+	err = Init(Config{BHD: BHDConfig{Key: "key", RateLimitConfig: RateLimitConfig{MaxFailures: -2}}}, "")
+	assert.ErrorContains(t, err, "bhd: invalid max_failures")
+
+	assert.NoError(t, Init(Config{BHD: BHDConfig{Key: "key", RateLimitConfig: RateLimitConfig{RequestsPerSecond: 0.5, Burst: 2}}}, ""))
+	assert.Equal(t, 1, Loaded())
+}
@@ -0,0 +1,29 @@
+package tracker
+
+import (
+	"context"
+	"time"
+)
+
+// seedTimeChecker is implemented by the trackers reporting the minimum seed time of torrents, e.g. their hit and run
+// rules
+type seedTimeChecker interface {
+	SeedTimeRequired(ctx context.Context, torrent *Torrent) (error, time.Duration)
+}
+
+// SeedTimeRequired returns the minimum seed time tr requires for the torrent (0 when none), supported is false when
+// tr does not report it. The requirement depends on the progress of the user, so it is never cached across runs
+func SeedTimeRequired(ctx context.Context, tr Interface, torrent *Torrent) (err error, required time.Duration,
+	supported bool) {
+	if c, ok := tr.(*cached); ok {
+		tr = c.Interface
+	}
+
+	sc, ok := tr.(seedTimeChecker)
+	if !ok {
+		return nil, 0, false
+	}
+
+	err, required = sc.SeedTimeRequired(ctx, torrent)
+	return err, required, true
+}
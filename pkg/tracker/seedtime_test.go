@@ -0,0 +1,70 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedTimeRequired(t *testing.T) {
+	ctx := context.Background()
+	cache := newResultCache(filepath.Join(t.TempDir(), CacheFile))
+
+	err, required, supported := SeedTimeRequired(ctx, &countingTracker{}, &Torrent{})
+	require.NoError(t, err)
+	assert.False(t, supported)
+	assert.Zero(t, required)
+
+	// the cache wrapper does not hide the required seed time
+	tr := newCached(NewUNIT3D("aither", UNIT3DConfig{Domain: "aither.cc", SeedTime: 168 * time.Hour}), "unit3d/aither",
+		cache, time.Hour)
+	err, required, supported = SeedTimeRequired(ctx, tr, &Torrent{Hash: "abc"})
+	require.NoError(t, err)
+	assert.True(t, supported)
+	assert.Equal(t, 168*time.Hour, required)
+}
+
+func TestCustom_SeedTimeRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("case") {
+		case "hnr":
+			_, _ = w.Write([]byte(`{"registered": true, "seed_time_required": 72.5}`))
+		case "negative":
+			_, _ = w.Write([]byte(`{"registered": true, "seed_time_required": -1}`))
+		default:
+			_, _ = w.Write([]byte(`{"registered": true}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		want        time.Duration
+		expectError bool
+	}{
+		{name: "hnr", want: 72*time.Hour + 30*time.Minute},
+		{name: "none"},
+		{name: "negative", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCustom("niche", CustomConfig{Domains: []string{"niche.example"}, URL: server.URL + "?case=" + tt.name})
+
+			err, required := c.SeedTimeRequired(context.Background(), &Torrent{Hash: "abc"})
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, required)
+		})
+	}
+}
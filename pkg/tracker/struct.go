@@ -8,6 +8,16 @@ type Config struct {
 	RED    REDConfig
 	OPS    OPSConfig
 	UNIT3D map[string]UNIT3DConfig
+
+	// TorrentLeech is configured under torrentleech
+	TorrentLeech TLConfig
+	// FileList is configured under filelist
+	FileList FLConfig
+	// Custom trackers are checked with a command or HTTP endpoint, by name
+	Custom map[string]CustomConfig
+	// Aliases are extra announce domains of the trackers (and their subdomains), by tracker, e.g. bhd, unit3d/aither
+	// or custom/niche
+	Aliases map[string][]string
 }
 
 type Torrent struct {
@@ -0,0 +1,104 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/autobrr/tqm/pkg/httputils"
+	"github.com/autobrr/tqm/pkg/logger"
+)
+
+type TLConfig struct {
+	// Cookie is the cookie header of a logged in session, e.g. tluid=123; tlpass=abc
+	Cookie string `koanf:"cookie"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
+}
+
+// TL checks TorrentLeech torrents with the JSON search of the site. Its tracker reports removed torrents with generic
+// messages which are easily mistaken for the tracker being down, so torrents are only checked with the API.
+type TL struct {
+	cfg     TLConfig
+	http    *http.Client
+	headers map[string]string
+	log     *logrus.Entry
+}
+
+func NewTL(c TLConfig) *TL {
+	l := logger.GetLogger("tl-api")
+	return &TL{
+		cfg:  c,
+		http: c.httpClient(15*time.Second, l),
+		headers: map[string]string{
+			"Accept": "application/json",
+			"Cookie": c.Cookie,
+		},
+		log: l,
+	}
+}
+
+func (c *TL) Name() string {
+	return "TL"
+}
+
+func (c *TL) Check(host string) bool {
+	return matchesDomain(host, "torrentleech.org") || matchesDomain(host, "tleechreload.org")
+}
+
+// StatusAmbiguous reports that the status messages of the tracker cannot tell removed torrents from tracker errors
+func (c *TL) StatusAmbiguous() bool {
+	return true
+}
+
+func (c *TL) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	type result struct {
+		Name     string `json:"name"`
+		Filename string `json:"filename"`
+	}
+
+	type response struct {
+		NumFound    *int     `json:"numFound"`
+		TorrentList []result `json:"torrentList"`
+	}
+
+	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		c.log.Info("-----")
+		torrent.APIDividerPrinted = true
+	}
+
+	c.log.Tracef("Querying TL API for torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+
+	// the search does not support info hashes, torrents are searched by name and matched by their exact name instead
+	query := strings.NewReplacer(".", " ", "_", " ").Replace(torrent.Name)
+	requestURL := "https://www.torrentleech.org/torrents/browse/list/query/" + url.PathEscape(query)
+
+	var resp *response
+	err := httputils.MakeAPIRequest(ctx, c.http, http.MethodGet, requestURL, nil, c.headers, &resp)
+	if err != nil {
+		return fmt.Errorf("making api request: %w", err), false
+	}
+
+	// an expired session is answered with the login page or an empty object, not with an error
+	if resp == nil || resp.NumFound == nil {
+		return fmt.Errorf("unexpected response, is the cookie still valid?"), false
+	}
+
+	for _, r := range resp.TorrentList {
+		if strings.EqualFold(r.Name, torrent.Name) || strings.EqualFold(strings.TrimSuffix(r.Filename, ".torrent"), torrent.Name) {
+			return nil, false
+		}
+	}
+
+	return nil, true
+}
+
+func (c *TL) IsTrackerDown(_ *Torrent) (error, bool) {
+	return nil, false
+}
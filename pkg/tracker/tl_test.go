@@ -0,0 +1,83 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTL_Check(t *testing.T) {
+	tl := NewTL(TLConfig{Cookie: "tluid=1; tlpass=abc"})
+
+	assert.True(t, tl.Check("tracker.torrentleech.org"))
+	assert.True(t, tl.Check("tracker.tleechreload.org"))
+	assert.False(t, tl.Check("nottorrentleech.org"))
+	assert.True(t, StatusAmbiguous(tl))
+}
+
+func TestTL_IsUnregistered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Cookie") != "tluid=1; tlpass=abc" {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/torrents/browse/list/query/Some Movie 2020 1080p BluRay x264-GRP":
+			_, _ = w.Write([]byte(`{"numFound": 2, "torrentList": [
+				{"name": "Some.Movie.2020.1080p.BluRay.x264-OTHER", "filename": "Some.Movie.2020.1080p.BluRay.x264-OTHER.torrent"},
+				{"name": "Some Movie 2020", "filename": "Some.Movie.2020.1080p.BluRay.x264-GRP.torrent"}
+			]}`))
+		default:
+			_, _ = w.Write([]byte(`{"numFound": 0, "torrentList": []}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		cookie      string
+		torrent     string
+		wantUnreg   bool
+		expectError bool
+	}{
+		{
+			name:    "listed by filename",
+			cookie:  "tluid=1; tlpass=abc",
+			torrent: "Some.Movie.2020.1080p.BluRay.x264-GRP",
+		},
+		{
+			name:      "not listed",
+			cookie:    "tluid=1; tlpass=abc",
+			torrent:   "Removed.Movie.2020.1080p.BluRay.x264-GRP",
+			wantUnreg: true,
+		},
+		{
+			name:        "expired session",
+			cookie:      "tluid=1; tlpass=expired",
+			torrent:     "Removed.Movie.2020.1080p.BluRay.x264-GRP",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := NewTL(TLConfig{Cookie: tt.cookie})
+			tl.http = &http.Client{Transport: &redirectTransport{server: server}}
+
+			err, unreg := tl.IsUnregistered(context.Background(), &Torrent{Name: tt.torrent})
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.False(t, unreg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUnreg, unreg)
+		})
+	}
+}
@@ -1,34 +1,136 @@
 package tracker
 
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
 var (
-	trackers []Interface
+	trackers []loadedTracker
+
+	// results caches the API results of the trackers with a cache TTL, nil when they are not cached
+	results *resultCache
+
+	// apiErrors counts the failed requests to tracker APIs
+	apiErrors atomic.Int64
+	// apiSkipped counts the requests not sent to tracker APIs failing repeatedly
+	apiSkipped atomic.Int64
 )
 
-func Init(cfg Config) error {
-	trackers = make([]Interface, 0)
+// loadedTracker is a tracker with an API and the extra announce domains of its torrents
+type loadedTracker struct {
+	api     Interface
+	aliases []string
+}
+
+// check reports whether the torrents announcing to host belong to the tracker
+func (l loadedTracker) check(host string) bool {
+	return l.api.Check(host) || slices.ContainsFunc(l.aliases, func(alias string) bool {
+		return matchesDomain(host, alias)
+	})
+}
+
+// Init loads the trackers with an API configured in cfg, their results are cached in the file at cachePath for their
+// cache TTL (empty to not cache them)
+func Init(cfg Config, cachePath string) error {
+	// the results cached with the previous configuration are kept
+	FlushCache()
+
+	trackers = make([]loadedTracker, 0)
+	results = nil
+
+	breakersMu.Lock()
+	breakers = nil
+	breakersMu.Unlock()
+
+	limits := map[string]RateLimitConfig{
+		"bhd":          cfg.BHD.RateLimitConfig,
+		"btn":          cfg.BTN.RateLimitConfig,
+		"ptp":          cfg.PTP.RateLimitConfig,
+		"hdb":          cfg.HDB.RateLimitConfig,
+		"red":          cfg.RED.RateLimitConfig,
+		"ops":          cfg.OPS.RateLimitConfig,
+		"torrentleech": cfg.TorrentLeech.RateLimitConfig,
+		"filelist":     cfg.FileList.RateLimitConfig,
+	}
+	for name, unit3dCfg := range cfg.UNIT3D {
+		limits["unit3d/"+name] = unit3dCfg.RateLimitConfig
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Custom)) {
+		if err := cfg.Custom[name].validate(); err != nil {
+			return fmt.Errorf("custom/%s: %w", name, err)
+		}
+		limits["custom/"+name] = cfg.Custom[name].RateLimitConfig
+	}
+	for _, name := range slices.Sorted(maps.Keys(limits)) {
+		if err := limits[name].validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := validateAliases(cfg.Aliases, limits); err != nil {
+		return err
+	}
+
+	if cachePath != "" {
+		results = newResultCache(cachePath)
+	}
+
+	// load adds the tracker identified by key, e.g. bhd or unit3d/aither
+	load := func(key string, tr Interface, ttl time.Duration) {
+		trackers = append(trackers, loadedTracker{api: newCached(tr, key, results, ttl), aliases: cfg.Aliases[key]})
+	}
 
-	// load trackers
+	// load trackers, custom trackers first so they can take over the domains of builtin trackers
+	for _, name := range slices.Sorted(maps.Keys(cfg.Custom)) {
+		customCfg := cfg.Custom[name]
+		load("custom/"+name, NewCustom(name, customCfg), customCfg.CacheTTL)
+	}
 	if cfg.BHD.Key != "" {
-		trackers = append(trackers, NewBHD(cfg.BHD))
+		load("bhd", NewBHD(cfg.BHD), cfg.BHD.CacheTTL)
 	}
 	if cfg.BTN.Key != "" {
-		trackers = append(trackers, NewBTN(cfg.BTN))
+		load("btn", NewBTN(cfg.BTN), cfg.BTN.CacheTTL)
 	}
 	if cfg.PTP.User != "" && cfg.PTP.Key != "" {
-		trackers = append(trackers, NewPTP(cfg.PTP))
+		load("ptp", NewPTP(cfg.PTP), cfg.PTP.CacheTTL)
 	}
 	if cfg.RED.Key != "" {
-		trackers = append(trackers, NewRED(cfg.RED))
+		load("red", NewRED(cfg.RED), cfg.RED.CacheTTL)
 	}
 	if cfg.OPS.Key != "" {
-		trackers = append(trackers, NewOPS(cfg.OPS))
+		load("ops", NewOPS(cfg.OPS), cfg.OPS.CacheTTL)
 	}
 	if cfg.HDB.Username != "" && cfg.HDB.Passkey != "" {
-		trackers = append(trackers, NewHDB(cfg.HDB))
+		load("hdb", NewHDB(cfg.HDB), cfg.HDB.CacheTTL)
+	}
+	if cfg.TorrentLeech.Cookie != "" {
+		load("torrentleech", NewTL(cfg.TorrentLeech), cfg.TorrentLeech.CacheTTL)
+	}
+	if cfg.FileList.Username != "" && cfg.FileList.Passkey != "" {
+		load("filelist", NewFL(cfg.FileList), cfg.FileList.CacheTTL)
 	}
 	for name, unit3dCfg := range cfg.UNIT3D {
 		if unit3dCfg.APIKey != "" && unit3dCfg.Domain != "" {
-			trackers = append(trackers, NewUNIT3D(name, unit3dCfg))
+			load("unit3d/"+name, NewUNIT3D(name, unit3dCfg), unit3dCfg.CacheTTL)
+		}
+	}
+	return nil
+}
+
+// validateAliases checks that the aliases are domains of known trackers
+func validateAliases(aliases map[string][]string, known map[string]RateLimitConfig) error {
+	for _, key := range slices.Sorted(maps.Keys(aliases)) {
+		if _, ok := known[key]; !ok {
+			return fmt.Errorf("aliases of unknown tracker: %q (e.g. bhd, unit3d/<name> or custom/<name>)", key)
+		}
+		for _, alias := range aliases[key] {
+			if strings.TrimSpace(alias) == "" || strings.ContainsAny(alias, "/:") {
+				return fmt.Errorf("invalid alias of %s: %q (must be a domain, e.g. tracker.example.org)", key, alias)
+			}
 		}
 	}
 	return nil
@@ -37,8 +139,8 @@ func Init(cfg Config) error {
 func Get(host string) Interface {
 	// find tracker for this host
 	for _, tracker := range trackers {
-		if tracker.Check(host) {
-			return tracker
+		if tracker.check(host) {
+			return tracker.api
 		}
 	}
 
@@ -46,6 +148,48 @@ func Get(host string) Interface {
 	return nil
 }
 
+// StatusAmbiguous reports whether the status messages of tr cannot tell removed torrents from tracker errors, its
+// torrents are then only checked with its API
+func StatusAmbiguous(tr Interface) bool {
+	a, ok := tr.(interface{ StatusAmbiguous() bool })
+	return ok && a.StatusAmbiguous()
+}
+
 func Loaded() int {
 	return len(trackers)
 }
+
+// FlushCache persists the API results cached since the cache was last persisted, it is called once a run or webhook
+// is done
+func FlushCache() {
+	if results != nil {
+		results.flush()
+	}
+}
+
+// RecordAPIError counts a failed request to a tracker API
+func RecordAPIError() {
+	apiErrors.Add(1)
+}
+
+// APIErrors returns the number of failed requests to tracker APIs
+func APIErrors() int64 {
+	return apiErrors.Load()
+}
+
+// RecordAPISkipped counts a request not sent to a tracker API failing repeatedly
+func RecordAPISkipped() {
+	apiSkipped.Add(1)
+}
+
+// APISkipped returns the number of requests not sent to tracker APIs failing repeatedly
+func APISkipped() int64 {
+	return apiSkipped.Load()
+}
+
+// matchesDomain checks whether host is domain or one of its subdomains
+func matchesDomain(host string, domain string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
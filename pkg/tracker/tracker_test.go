@@ -0,0 +1,55 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet_Aliases(t *testing.T) {
+	t.Cleanup(func() { trackers = nil })
+
+	require.NoError(t, Init(Config{
+		BHD:    BHDConfig{Key: "key"},
+		UNIT3D: map[string]UNIT3DConfig{"aither": {APIKey: "key", Domain: "aither.cc"}},
+		Aliases: map[string][]string{
+			"bhd":           {"bhd-announce.example"},
+			"unit3d/aither": {"aither.example"},
+		},
+	}, ""))
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "tracker.beyond-hd.me", want: "BHD"},
+		{host: "bhd-announce.example", want: "BHD"},
+		{host: "tracker3.bhd-announce.example", want: "BHD"},
+		{host: "aither.example", want: "UNIT3D"},
+		{host: "notaither.example"},
+		{host: "other.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			tr := Get(tt.host)
+			if tt.want == "" {
+				assert.Nil(t, tr)
+				return
+			}
+			require.NotNil(t, tr)
+			assert.Equal(t, tt.want, tr.Name())
+		})
+	}
+}
+
+func TestInit_InvalidAliases(t *testing.T) {
+	t.Cleanup(func() { trackers = nil })
+
+	err := Init(Config{Aliases: map[string][]string{"unit3d/missing": {"missing.example"}}}, "")
+	assert.ErrorContains(t, err, `aliases of unknown tracker: "unit3d/missing"`)
+
+	err = Init(Config{Aliases: map[string][]string{"bhd": {"https://bhd.example/announce"}}}, "")
+	assert.ErrorContains(t, err, "invalid alias of bhd")
+}
@@ -3,13 +3,16 @@ package tracker
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/autobrr/tqm/pkg/httputils"
 	"github.com/autobrr/tqm/pkg/logger"
@@ -18,6 +21,11 @@ import (
 type UNIT3DConfig struct {
 	APIKey string `koanf:"api_key"`
 	Domain string `koanf:"domain"`
+	// SeedTime is the minimum seed time of the hit and run rules of the site, e.g. 168h (the API does not report it)
+	SeedTime time.Duration `koanf:"seed_time"`
+
+	CacheConfig     `koanf:",squash"`
+	RateLimitConfig `koanf:",squash"`
 }
 
 type UNIT3D struct {
@@ -33,7 +41,7 @@ func NewUNIT3D(name string, c UNIT3DConfig) Interface {
 
 	return &UNIT3D{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack)),
+		http: c.httpClient(15*time.Second, l),
 		headers: map[string]string{
 			"Authorization": fmt.Sprintf("Bearer %s", c.APIKey),
 			"Accept":        "application/json",
@@ -47,7 +55,7 @@ func (c *UNIT3D) Name() string {
 }
 
 func (c *UNIT3D) Check(host string) bool {
-	return strings.EqualFold(host, c.cfg.Domain)
+	return matchesDomain(host, c.cfg.Domain)
 }
 
 // extractTorrentID extracts the torrent ID from the comment field
@@ -68,29 +76,106 @@ func (c *UNIT3D) extractTorrentID(comment string) (string, error) {
 	return matches[1], nil
 }
 
+// unit3dAttributes are the attributes of a torrent reported by the API of UNIT3D
+type unit3dAttributes struct {
+	InfoHash string `json:"info_hash"`
+	// Freeleech is the download discount, e.g. 100%
+	Freeleech string `json:"freeleech"`
+}
+
 func (c *UNIT3D) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		c.log.Info("-----")
+		torrent.APIDividerPrinted = true
+	}
+
+	c.log.Tracef("Querying UNIT3D API for torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+
+	err, attrs := c.fetch(ctx, torrent)
+	if err != nil || attrs == nil {
+		return err, false
+	}
+
+	// compare hash
+	if strings.EqualFold(attrs.InfoHash, torrent.Hash) {
+		// torrent exists and hash matches
+		return nil, false
+	}
+
+	// if we get here, the torrent ID exists but hash doesn't match
+	c.log.Debugf("Torrent ID exists but hash mismatch. Expected: %s, Got: %s",
+		torrent.Hash, attrs.InfoHash)
+	return nil, true
+}
+
+// IsUnregisteredBulk filters the torrents of the site by a comma separated list of info hashes. The torrents found are
+// registered, the missing ones are left to IsUnregistered, which looks them up by the torrent ID of their comment
+func (c *UNIT3D) IsUnregisteredBulk(ctx context.Context, torrents []*Torrent) (error, map[string]bool) {
 	type data struct {
-		Attributes struct {
-			InfoHash string `json:"info_hash"`
-		} `json:"attributes"`
+		Attributes unit3dAttributes `json:"attributes"`
 	}
 
 	type response struct {
-		Data    data   `json:"data"`
-		Message string `json:"message"`
-		Status  int    `json:"status"`
+		Data []data `json:"data"`
 	}
 
-	if c.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
-		c.log.Info("-----")
-		torrent.APIDividerPrinted = true
+	c.log.Tracef("Querying UNIT3D API for %d torrents", len(torrents))
+
+	requested := hashSet(torrents)
+	requestURL, err := httputils.URLWithQuery(fmt.Sprintf("https://%s/api/torrents/filter", c.cfg.Domain), url.Values{
+		"infoHash": []string{strings.Join(slices.Sorted(maps.Keys(requested)), ",")},
+		"perPage":  []string{strconv.Itoa(bulkSize)},
+	})
+	if err != nil {
+		return fmt.Errorf("creating request URL: %w", err), nil
 	}
 
-	c.log.Tracef("Querying UNIT3D API for torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+	var resp *response
+	err = httputils.MakeAPIRequest(ctx, c.http, http.MethodGet, requestURL, nil, c.headers, &resp)
+	if err != nil {
+		return fmt.Errorf("making api request: %w", err), nil
+	}
+
+	found := make(map[string]bool, len(resp.Data))
+	for _, d := range resp.Data {
+		if hash := strings.ToLower(d.Attributes.InfoHash); requested[hash] {
+			found[hash] = false
+		}
+	}
+	return nil, found
+}
+
+func (c *UNIT3D) IsFreeleech(ctx context.Context, torrent *Torrent) (error, bool) {
+	c.log.Tracef("Querying UNIT3D API for freeleech status of torrent: %s (hash: %s)", torrent.Name, torrent.Hash)
+
+	err, attrs := c.fetch(ctx, torrent)
+	if err != nil || attrs == nil || !strings.EqualFold(attrs.InfoHash, torrent.Hash) {
+		return err, false
+	}
+
+	return nil, strings.TrimSpace(strings.TrimSuffix(attrs.Freeleech, "%")) == "100"
+}
+
+// SeedTimeRequired returns the configured seed time of the site, as the API does not report the hit and run rules
+func (c *UNIT3D) SeedTimeRequired(_ context.Context, _ *Torrent) (error, time.Duration) {
+	return nil, c.cfg.SeedTime
+}
+
+// fetch returns the attributes of the torrent, nil when its comment has no torrent ID
+func (c *UNIT3D) fetch(ctx context.Context, torrent *Torrent) (error, *unit3dAttributes) {
+	type data struct {
+		Attributes unit3dAttributes `json:"attributes"`
+	}
+
+	type response struct {
+		Data    data   `json:"data"`
+		Message string `json:"message"`
+		Status  int    `json:"status"`
+	}
 
 	torrentID, err := c.extractTorrentID(torrent.Comment)
 	if err != nil {
-		return nil, false
+		return nil, nil
 	}
 
 	requestURL := fmt.Sprintf("https://%s/api/torrents/%s", c.cfg.Domain, torrentID)
@@ -98,19 +183,10 @@ func (c *UNIT3D) IsUnregistered(ctx context.Context, torrent *Torrent) (error, b
 	var resp *response
 	err = httputils.MakeAPIRequest(ctx, c.http, http.MethodGet, requestURL, nil, c.headers, &resp)
 	if err != nil {
-		return fmt.Errorf("making api request: %w", err), false
+		return fmt.Errorf("making api request: %w", err), nil
 	}
 
-	// compare hash
-	if strings.EqualFold(resp.Data.Attributes.InfoHash, torrent.Hash) {
-		// torrent exists and hash matches
-		return nil, false
-	}
-
-	// if we get here, the torrent ID exists but hash doesn't match
-	c.log.Debugf("Torrent ID exists but hash mismatch. Expected: %s, Got: %s",
-		torrent.Hash, resp.Data.Attributes.InfoHash)
-	return nil, true
+	return nil, &resp.Data.Attributes
 }
 
 func (c *UNIT3D) IsTrackerDown(_ *Torrent) (error, bool) {